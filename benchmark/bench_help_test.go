@@ -0,0 +1,77 @@
+//nolint:testpackage // using package name 'benchmark' to access unexported fields for testing
+package benchmark
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// Category: help rendering
+
+func buildHelpApp() *snap.App {
+	app := snap.New("bench", "bench help rendering").Version("1.0.0")
+	app.IO().WithOut(io.Discard)
+	app.IntFlag("port", "Port to listen on").Default(8080).Short('p').Back().
+		StringFlag("host", "Host to bind").Default("localhost").Back().
+		BoolFlag("verbose", "Verbose output").Short('v').Back().
+		DurationSliceFlag("backoff", "Retry backoff").Back()
+	app.FlagGroup("output").
+		StringFlag("format", "Output format").Back().
+		BoolFlag("color", "Colorize output").Back().
+		MutuallyExclusive().
+		EndGroup()
+	for _, name := range []string{"serve", "build", "deploy", "status", "logs", "config"} {
+		app.Command(name, "The "+name+" command").
+			StringFlag("target", "Target").Back().
+			BoolFlag("force", "Force").Back()
+	}
+	return app
+}
+
+// BenchmarkHelpApp measures repeated app-level help; after the first call the
+// rendered output is served from the cache.
+func BenchmarkHelpApp(b *testing.B) {
+	app := buildHelpApp()
+	ctx := context.Background()
+	args := []string{"--help"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := app.RunWithArgs(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkHelpCommand measures command help, rendered into a pooled buffer on every call.
+func BenchmarkHelpCommand(b *testing.B) {
+	app := buildHelpApp()
+	ctx := context.Background()
+	args := []string{"serve", "--help"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := app.RunWithArgs(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParserWithHelpFlags ensures the parse hot path stays allocation-free
+// on an app with help-related registrations.
+func BenchmarkParserWithHelpFlags(b *testing.B) {
+	app := buildHelpApp()
+	parser := snap.NewParser(app)
+	args := []string{"--port", "9090", "-v", "serve", "--target", "prod"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := parser.Parse(args)
+		if err != nil || result == nil {
+			b.Fatal(err)
+		}
+	}
+}
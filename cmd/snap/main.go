@@ -0,0 +1,130 @@
+// Command snap scaffolds new command-line applications built on go-snap:
+//
+//	go install github.com/dzonerzy/go-snap/cmd/snap@latest
+//	snap new mytool --module github.com/me/mytool
+//
+// Options not given as flags are asked for when stdin is a terminal; --yes
+// takes the defaults instead.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func main() {
+	app := snap.New("snap", "Scaffold command-line applications built on go-snap")
+
+	app.Command("new", "Create a new CLI project").
+		StringArg("name", "Name of the executable, e.g. mytool").Back().
+		StringFlag("module", "Go module path (default: example.com/NAME)").Placeholder("PATH").Back().
+		StringFlag("description", "One-line description shown in help").Placeholder("TEXT").Back().
+		StringFlag("dir", "Directory to create (default: ./NAME)").Placeholder("DIR").Back().
+		BoolFlag("config", "Bind a configuration struct to flags and environment variables").Default(true).Back().
+		BoolFlag("middleware", "Add recovery and logging middleware").Default(true).Back().
+		BoolFlag("force", "Write into a directory that is not empty").Back().
+		BoolFlag("yes", "Do not ask; use the defaults for options not given").Short('y').Back().
+		Action(newProject)
+
+	app.RunAndExit()
+}
+
+// newProject gathers the project options and writes the files
+func newProject(ctx *snap.Context) error {
+	p := project{
+		Name:        ctx.MustArgString("name", ""),
+		Module:      ctx.MustString("module", ""),
+		Description: ctx.MustString("description", ""),
+		Config:      ctx.MustBool("config", true),
+		Middleware:  ctx.MustBool("middleware", true),
+	}
+	dir := ctx.MustString("dir", "")
+
+	if !ctx.MustBool("yes", false) && ctx.IO().IsInteractive() {
+		q := &questionnaire{in: bufio.NewReader(ctx.Stdin()), out: ctx.Stdout()}
+		if p.Name == "" {
+			p.Name = q.ask("Executable name", "")
+		}
+		if !ctx.IsSet("module") {
+			p.Module = q.ask("Module path", p.defaultModule())
+		}
+		if !ctx.IsSet("description") {
+			p.Description = q.ask("Description", p.defaultDescription())
+		}
+		if !ctx.IsSet("config") {
+			p.Config = q.confirm("Bind a configuration struct", p.Config)
+		}
+		if !ctx.IsSet("middleware") {
+			p.Middleware = q.confirm("Add recovery and logging middleware", p.Middleware)
+		}
+		if q.err != nil {
+			return q.err
+		}
+	}
+	if err := p.normalize(); err != nil {
+		return err
+	}
+	if dir == "" {
+		dir = p.Name
+	}
+
+	files, err := p.write(dir, ctx.MustBool("force", false))
+	if err != nil {
+		return err
+	}
+	out := ctx.Stdout()
+	for _, file := range files {
+		fmt.Fprintln(out, "created", file)
+	}
+	fmt.Fprintf(out, "\nNext steps:\n  cd %s\n  go mod tidy\n  make build && ./%s --help\n", dir, p.Name)
+	return nil
+}
+
+// questionnaire asks for options on the terminal; the first read error
+// stops further questions
+type questionnaire struct {
+	in  *bufio.Reader
+	out io.Writer
+	err error
+}
+
+// ask prints question with its default and returns the answer, or def for
+// an empty one
+func (q *questionnaire) ask(question, def string) string {
+	if q.err != nil {
+		return def
+	}
+	if def != "" {
+		fmt.Fprintf(q.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(q.out, "%s: ", question)
+	}
+	line, err := q.in.ReadString('\n')
+	if err != nil && line == "" {
+		q.err = fmt.Errorf("reading answer: %w", err)
+		return def
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// confirm asks a yes/no question
+func (q *questionnaire) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	switch strings.ToLower(q.ask(question+" ("+hint+")", "")) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
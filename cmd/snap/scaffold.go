@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates are parsed once; each file is named after its output path
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// projectFiles maps the generated files to their templates, in creation order
+var projectFiles = []struct{ path, template string }{
+	{"go.mod", "go.mod.tmpl"},
+	{"main.go", "main.go.tmpl"},
+	{"Makefile", "Makefile.tmpl"},
+	{"README.md", "README.md.tmpl"},
+	{".gitignore", "gitignore.tmpl"},
+}
+
+// namePattern restricts executable names to what works as a file name,
+// module path element and environment variable prefix
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// project holds the options of a scaffolded CLI
+type project struct {
+	Name        string // Executable name
+	Module      string // Go module path
+	Description string
+	Config      bool // Bind a configuration struct (snap.Config)
+	Middleware  bool // Add recovery and logging middleware
+}
+
+// defaultModule is the module path used when none is given
+func (p *project) defaultModule() string {
+	return "example.com/" + p.Name
+}
+
+// defaultDescription is the description used when none is given
+func (p *project) defaultDescription() string {
+	return "The " + p.Name + " command-line tool"
+}
+
+// EnvPrefix is the prefix of the environment variables read by the project
+func (p *project) EnvPrefix() string {
+	return strings.ToUpper(strings.ReplaceAll(p.Name, "-", "_"))
+}
+
+// Summary is the description on one line, for the package comment
+func (p *project) Summary() string {
+	return strings.Join(strings.Fields(p.Description), " ")
+}
+
+// normalize checks the name and fills in the defaults
+func (p *project) normalize() error {
+	if p.Name == "" {
+		return errors.New("missing executable name: snap new NAME")
+	}
+	if !namePattern.MatchString(p.Name) {
+		return fmt.Errorf("invalid executable name %q: use lower-case letters, digits, - and _", p.Name)
+	}
+	if p.Module == "" {
+		p.Module = p.defaultModule()
+	}
+	if p.Description == "" {
+		p.Description = p.defaultDescription()
+	}
+	return nil
+}
+
+// render executes the templates, formatting Go sources
+func (p *project) render() (map[string][]byte, error) {
+	out := make(map[string][]byte, len(projectFiles))
+	for _, file := range projectFiles {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, file.template, p); err != nil {
+			return nil, err
+		}
+		data := buf.Bytes()
+		if strings.HasSuffix(file.path, ".go") {
+			formatted, err := format.Source(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file.path, err)
+			}
+			data = formatted
+		}
+		out[file.path] = data
+	}
+	return out, nil
+}
+
+// write renders the project into dir, which must be missing or empty unless
+// force is set, and returns the paths written
+func (p *project) write(dir string, force bool) ([]string, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 && !force {
+		return nil, fmt.Errorf("%s is not empty; use --force to write into it", dir)
+	}
+	files, err := p.render()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gosec // project directories are world-readable
+		return nil, err
+	}
+	written := make([]string, 0, len(projectFiles))
+	for _, file := range projectFiles {
+		path := filepath.Join(dir, file.path)
+		if err := os.WriteFile(path, files[file.path], 0o644); err != nil { //nolint:gosec // source files
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
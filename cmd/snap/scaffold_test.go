@@ -0,0 +1,102 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectWrite(t *testing.T) {
+	for _, opts := range []struct{ config, middleware bool }{{true, true}, {true, false}, {false, true}, {false, false}} {
+		p := project{Name: "my-tool", Config: opts.config, Middleware: opts.middleware}
+		if err := p.normalize(); err != nil {
+			t.Fatal(err)
+		}
+		dir := filepath.Join(t.TempDir(), "my-tool")
+		written, err := p.write(dir, false)
+		if err != nil {
+			t.Fatalf("%+v: %v", opts, err)
+		}
+		if len(written) != len(projectFiles) {
+			t.Fatalf("wrote %v", written)
+		}
+
+		src, err := os.ReadFile(filepath.Join(dir, "main.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0); err != nil {
+			t.Fatalf("%+v: generated main.go does not parse: %v", opts, err)
+		}
+		if got := strings.Contains(string(src), "MY_TOOL_GREETING"); got != opts.config {
+			t.Errorf("%+v: env prefix in main.go = %v", opts, got)
+		}
+		if got := strings.Contains(string(src), "middleware.Recovery()"); got != opts.middleware {
+			t.Errorf("%+v: middleware in main.go = %v", opts, got)
+		}
+
+		mod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if !strings.HasPrefix(string(mod), "module example.com/my-tool\n") ||
+			!strings.Contains(string(mod), "\ngo 1.22.5\n") {
+			t.Errorf("go.mod = %q", mod)
+		}
+	}
+}
+
+func TestProjectDescriptionQuoting(t *testing.T) {
+	description := "Say \"hi\" to C:\\Users\nand more"
+	for _, config := range []bool{true, false} {
+		p := project{Name: "tool", Description: description, Config: config}
+		if err := p.normalize(); err != nil {
+			t.Fatal(err)
+		}
+		files, err := p.render()
+		if err != nil {
+			t.Fatalf("config %v: %v", config, err)
+		}
+		src := files["main.go"]
+		if _, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0); err != nil {
+			t.Fatalf("config %v: generated main.go does not parse: %v\n%s", config, err, src)
+		}
+		if !strings.Contains(string(src), `"Say \"hi\" to C:\\Users\nand more"`) ||
+			!strings.HasPrefix(string(src), "// Command tool: Say \"hi\" to C:\\Users and more.\n") {
+			t.Errorf("config %v: main.go:\n%s", config, src)
+		}
+	}
+}
+
+func TestProjectWriteRefusesNonEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := project{Name: "tool"}
+	if err := p.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.write(dir, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected a --force hint, got %v", err)
+	}
+	if _, err := p.write(dir, true); err != nil {
+		t.Fatalf("force: %v", err)
+	}
+}
+
+func TestProjectNormalize(t *testing.T) {
+	for _, name := range []string{"", "My Tool", "../x", "1tool"} {
+		p := project{Name: name}
+		if err := p.normalize(); err == nil {
+			t.Errorf("name %q: expected an error", name)
+		}
+	}
+	p := project{Name: "tool", Module: "github.com/me/tool"}
+	if err := p.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Module != "github.com/me/tool" || p.Description != "The tool command-line tool" {
+		t.Errorf("normalize = %+v", p)
+	}
+}
@@ -13,7 +13,8 @@ func main() {
 	// Output group: exactly one format
 	app.FlagGroup("output").
 		ExactlyOne().
-		Description("Choose exactly one output format").
+		DefaultFlag("table").
+		Description("Choose one output format (table when none is given)").
 		BoolFlag("json", "JSON output").Short('j').Back().
 		BoolFlag("yaml", "YAML output").Short('y').Back().
 		BoolFlag("table", "Table output").Short('t').Back().
@@ -27,13 +28,13 @@ func main() {
 		EndGroup()
 
 	app.Command("run", "Execute with chosen options").Action(func(ctx *snap.Context) error {
-		// Decide on format
+		// Exactly one format is set; --table is selected when none is given
 		switch {
 		case ctx.MustBool("json", false):
 			fmt.Fprintln(ctx.Stdout(), `{"status":"ok"}`)
 		case ctx.MustBool("yaml", false):
 			fmt.Fprintln(ctx.Stdout(), "status: ok")
-		case ctx.MustBool("table", false):
+		default:
 			fmt.Fprintln(ctx.Stdout(), "STATUS\n ok")
 		}
 		if c, ok := ctx.String("cert"); ok {
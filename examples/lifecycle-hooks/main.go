@@ -100,12 +100,12 @@ func main() {
 		Wrap("go").
 		BeforeExec(func(ctx *snap.Context, args []string) ([]string, error) {
 			fmt.Println("🧪 [BeforeExec] Starting test suite...")
-			ctx.Set("start_time", time.Now())
+			snap.CtxSet(ctx, "start_time", time.Now())
 			return append([]string{"test", "-v", "./..."}, args...), nil
 		}).
 		Passthrough().
 		AfterExec(func(ctx *snap.Context, result *snap.ExecResult) error {
-			startTime := ctx.Get("start_time").(time.Time)
+			startTime := snap.CtxMustGet(ctx, "start_time", time.Now())
 			duration := time.Since(startTime)
 
 			fmt.Printf("\n📊 [AfterExec] Test Results:\n")
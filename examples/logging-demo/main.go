@@ -14,11 +14,14 @@ import (
 //   go run ./examples/logging-demo deploy --format symbols
 //   go run ./examples/logging-demo deploy --format tagged
 //   go run ./examples/logging-demo deploy --format custom
-//   go run ./examples/logging-demo deploy --timestamp
+//   go run ./examples/logging-demo deploy -v     # debug messages
+//   go run ./examples/logging-demo deploy -vv    # debug messages with timestamps
+//   go run ./examples/logging-demo deploy -q     # warnings and errors only
 
 func main() {
 	app := snap.New("logging-demo", "Demonstrates structured logging with various formats").
-		Version("1.0.0")
+		Version("1.0.0").
+		VerbosityFlags() // -v, -vv (timestamps) and -q set the logger level
 
 	// Global flag for log format
 	app.StringFlag("format", "Log format (circles, symbols, tagged, plain, custom)").
@@ -26,10 +29,6 @@ func main() {
 		Global().
 		Back()
 
-	app.BoolFlag("timestamp", "Include timestamps in log output").
-		Global().
-		Back()
-
 	// Deploy command - demonstrates all log levels
 	app.Command("deploy", "Deploy application with detailed logging").
 		StringFlag("env", "Target environment").Default("production").Back().
@@ -122,23 +121,23 @@ func main() {
 				{"Plain", snapio.LogFormatPlain},
 			}
 
+			// Each format renders through its own copy, leaving the app logger untouched
 			for _, f := range formats {
-				ctx.App.Logger().WithFormat(f.format)
-				ctx.LogInfo("=== %s ===", f.name)
-				ctx.LogDebug("This is a debug message")
-				ctx.LogInfo("This is an info message")
-				ctx.LogSuccess("This is a success message")
-				ctx.LogWarning("This is a warning message")
-				ctx.LogError("This is an error message")
-				ctx.LogInfo("") // Empty line for separation
+				logger := ctx.Logger().Clone(nil).WithFormat(f.format)
+				logger.Info("=== %s ===", f.name)
+				logger.Debug("This is a debug message")
+				logger.Info("This is an info message")
+				logger.Success("This is a success message")
+				logger.Warning("This is a warning message")
+				logger.Error("This is an error message")
+				logger.Info("") // Empty line for separation
 			}
 
 			// Custom format example
-			ctx.App.Logger().WithFormat(snapio.LogFormatCustom).
-				WithTemplate("[{{.Level}}] {{.Time}} - {{.Message}}")
-			ctx.LogInfo("=== Custom Template ===")
-			ctx.LogInfo("Custom formatted message with timestamp")
-			ctx.LogSuccess("Another custom message")
+			custom := ctx.Logger().Clone(nil).WithTemplate("[{{.Level}}] {{.Time}} - {{.Message}}")
+			custom.Info("=== Custom Template ===")
+			custom.Info("Custom formatted message with timestamp")
+			custom.Success("Another custom message")
 
 			return nil
 		})
@@ -146,7 +145,7 @@ func main() {
 	// Timestamp demo
 	app.Command("with-timestamps", "Demonstrate timestamp logging").
 		Action(func(ctx *snap.Context) error {
-			ctx.App.Logger().WithTimestamp(true)
+			ctx.Logger().WithTimestamp(true)
 
 			ctx.LogInfo("Starting task 1...")
 			time.Sleep(500 * time.Millisecond)
@@ -161,7 +160,7 @@ func main() {
 			ctx.LogError("Task 3 failed")
 
 			// Custom time format
-			ctx.App.Logger().WithTimeFormat("15:04:05.000")
+			ctx.Logger().WithTimeFormat("15:04:05.000")
 			ctx.LogInfo("Using millisecond precision timestamps")
 
 			return nil
@@ -170,26 +169,22 @@ func main() {
 	app.RunAndExit()
 }
 
-// configureLogger applies user-specified format preferences
+// configureLogger applies the user-specified format; levels and timestamps
+// come from VerbosityFlags
 func configureLogger(ctx *snap.Context) {
 	format, _ := ctx.GlobalString("format")
-	timestamp, _ := ctx.GlobalBool("timestamp")
 
 	switch format {
 	case "circles":
-		ctx.App.Logger().WithFormat(snapio.LogFormatCircles)
+		ctx.Logger().WithFormat(snapio.LogFormatCircles)
 	case "symbols":
-		ctx.App.Logger().WithFormat(snapio.LogFormatSymbols)
+		ctx.Logger().WithFormat(snapio.LogFormatSymbols)
 	case "tagged":
-		ctx.App.Logger().WithFormat(snapio.LogFormatTagged)
+		ctx.Logger().WithFormat(snapio.LogFormatTagged)
 	case "plain":
-		ctx.App.Logger().WithFormat(snapio.LogFormatPlain)
+		ctx.Logger().WithFormat(snapio.LogFormatPlain)
 	case "custom":
-		ctx.App.Logger().WithFormat(snapio.LogFormatCustom).
+		ctx.Logger().WithFormat(snapio.LogFormatCustom).
 			WithTemplate("[{{.Level}}] {{.Message}}")
 	}
-
-	if timestamp {
-		ctx.App.Logger().WithTimestamp(true)
-	}
 }
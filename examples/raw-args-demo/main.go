@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/dzonerzy/go-snap/snap"
@@ -28,7 +27,7 @@ func main() {
 		Before(func(ctx *snap.Context) error {
 			// Log the complete invocation for audit trail
 			timestamp := time.Now().Format(time.RFC3339)
-			rawCmd := strings.Join(ctx.RawArgs(), " ")
+			rawCmd := snap.QuoteArgs(ctx.RawArgs())
 
 			fmt.Printf("[AUDIT] %s | User invoked: %s %s\n",
 				timestamp, ctx.AppName(), rawCmd)
@@ -126,7 +125,7 @@ func main() {
 			raw := ctx.RawArgs()
 
 			fmt.Println("🔄 Proxy Mode: Forwarding to external tool")
-			fmt.Printf("\nWould execute: external-tool %s\n", strings.Join(raw, " "))
+			fmt.Printf("\nWould execute: external-tool %s\n", snap.QuoteArgs(raw))
 			fmt.Printf("\n(In real usage, you would exec.Command(\"external-tool\", raw...))\n")
 
 			return nil
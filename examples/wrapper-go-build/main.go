@@ -2,8 +2,6 @@ package main
 
 import (
 	"path/filepath"
-	"strconv"
-	"strings"
 
 	"github.com/dzonerzy/go-snap/snap"
 )
@@ -31,18 +29,14 @@ func main() {
 	app.Command("log", "toolexec logger").
 		WrapDynamic().
 		ForwardUnknownFlags(). // forward all tool flags (e.g., --V, -importcfg)
-		// Optional: allow-list certain tools only
-		// .AllowTools("asm","compile","vet","link")
+		// Only run the tools a build needs; skip vet and anything else
+		AllowTools("asm", "cgo", "compile", "link", "pack", "buildid").
+		OnDisallowedTool(snap.DisallowedToolSkip).
 		TransformArgs(func(ctx *snap.Context, in []string) ([]string, error) {
 			if len(ctx.Args()) > 0 {
 				tool := ctx.Args()[0]
 				base := filepath.Base(tool)
-				// quote args for clarity
-				q := make([]string, len(in))
-				for i, a := range in {
-					q[i] = strconv.Quote(a)
-				}
-				ctx.Stderr().Write([]byte("[toolexec] " + base + " " + strings.Join(q, " ") + "\n"))
+				ctx.Stderr().Write([]byte("[toolexec] " + base + " " + snap.QuoteArgs(in) + "\n"))
 			}
 			return in, nil
 		}).
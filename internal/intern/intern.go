@@ -3,6 +3,7 @@
 package intern
 
 import (
+	"strings"
 	"sync"
 	"unsafe"
 )
@@ -43,11 +44,22 @@ func (si *StringInterner) Intern(s string) string {
 		return interned
 	}
 
-	// Store and return the string
+	// Store a copy: s may view a caller's buffer (InternBytes), which the
+	// table would otherwise keep alive and see change under its key
+	s = strings.Clone(s)
 	si.strings[s] = s
 	return s
 }
 
+// LookupBytes returns the interned string equal to b without adding new
+// strings, so untrusted input cannot grow the table
+func (si *StringInterner) LookupBytes(b []byte) (string, bool) {
+	si.mutex.RLock()
+	defer si.mutex.RUnlock()
+	interned, exists := si.strings[bytesToString(b)]
+	return interned, exists
+}
+
 // InternBytes interns a byte slice as string without extra allocation
 func (si *StringInterner) InternBytes(b []byte) string {
 	// Convert bytes to string without allocation for lookup
@@ -146,6 +158,11 @@ func InternBytes(b []byte) string {
 	return GlobalInterner.InternBytes(b)
 }
 
+// LookupBytes looks b up in the global interner without interning it
+func LookupBytes(b []byte) (string, bool) {
+	return GlobalInterner.LookupBytes(b)
+}
+
 // InternByte interns a single byte using the global interner
 //
 //nolint:revive // keep name for public API symmetry with Intern/InternBytes
@@ -221,6 +221,46 @@ func NewIntSlicePool(defaultCap int) *IntSlicePool {
 	}
 }
 
+// FloatSlicePool provides efficient pooling for float64 slices
+type FloatSlicePool struct {
+	*Pool[[]float64]
+}
+
+// NewFloatSlicePool creates a new float64 slice pool
+func NewFloatSlicePool(defaultCap int) *FloatSlicePool {
+	return &FloatSlicePool{
+		Pool: NewPoolWithReset(
+			func() *[]float64 {
+				slice := make([]float64, 0, defaultCap)
+				return &slice
+			},
+			func(slice *[]float64) {
+				*slice = (*slice)[:0] // Reset length but keep capacity
+			},
+		),
+	}
+}
+
+// DurationSlicePool provides efficient pooling for time.Duration slices
+type DurationSlicePool struct {
+	*Pool[[]time.Duration]
+}
+
+// NewDurationSlicePool creates a new time.Duration slice pool
+func NewDurationSlicePool(defaultCap int) *DurationSlicePool {
+	return &DurationSlicePool{
+		Pool: NewPoolWithReset(
+			func() *[]time.Duration {
+				slice := make([]time.Duration, 0, defaultCap)
+				return &slice
+			},
+			func(slice *[]time.Duration) {
+				*slice = (*slice)[:0] // Reset length but keep capacity
+			},
+		),
+	}
+}
+
 // ParseResultPool provides specialized pooling for ParseResult objects
 type ParseResultPool struct {
 	*Pool[ParseResult]
@@ -230,6 +270,8 @@ type ParseResultPool struct {
 type ParseResult struct {
 	// Typed maps to avoid interface{} boxing allocations
 	IntFlags      map[string]int
+	Int64Flags    map[string]int64  // Int64Flag and Int32Flag values
+	Uint64Flags   map[string]uint64 // Uint64Flag and UintFlag values
 	StringFlags   map[string]string
 	BoolFlags     map[string]bool
 	DurationFlags map[string]time.Duration
@@ -237,18 +279,26 @@ type ParseResult struct {
 	EnumFlags     map[string]string
 
 	// Slice storage using offsets into global buffers
-	StringSliceOffsets map[string]SliceOffset
-	IntSliceOffsets    map[string]SliceOffset
+	StringSliceOffsets   map[string]SliceOffset
+	IntSliceOffsets      map[string]SliceOffset
+	FloatSliceOffsets    map[string]SliceOffset
+	DurationSliceOffsets map[string]SliceOffset
+	EnumSliceOffsets     map[string]SliceOffset
 
 	// Global flag typed maps
-	GlobalIntFlags           map[string]int
-	GlobalStringFlags        map[string]string
-	GlobalBoolFlags          map[string]bool
-	GlobalDurationFlags      map[string]time.Duration
-	GlobalFloatFlags         map[string]float64
-	GlobalEnumFlags          map[string]string
-	GlobalStringSliceOffsets map[string]SliceOffset
-	GlobalIntSliceOffsets    map[string]SliceOffset
+	GlobalIntFlags             map[string]int
+	GlobalInt64Flags           map[string]int64
+	GlobalUint64Flags          map[string]uint64
+	GlobalStringFlags          map[string]string
+	GlobalBoolFlags            map[string]bool
+	GlobalDurationFlags        map[string]time.Duration
+	GlobalFloatFlags           map[string]float64
+	GlobalEnumFlags            map[string]string
+	GlobalStringSliceOffsets   map[string]SliceOffset
+	GlobalIntSliceOffsets      map[string]SliceOffset
+	GlobalFloatSliceOffsets    map[string]SliceOffset
+	GlobalDurationSliceOffsets map[string]SliceOffset
+	GlobalEnumSliceOffsets     map[string]SliceOffset
 
 	// Positional argument typed maps (by argument name)
 	ArgStrings      map[string]string
@@ -276,23 +326,33 @@ func NewParseResultPool() *ParseResultPool {
 			func() *ParseResult {
 				return &ParseResult{
 					// Typed maps to avoid interface{} boxing
-					IntFlags:           make(map[string]int, 8),
-					StringFlags:        make(map[string]string, 8),
-					BoolFlags:          make(map[string]bool, 8),
-					DurationFlags:      make(map[string]time.Duration, 4),
-					FloatFlags:         make(map[string]float64, 4),
-					EnumFlags:          make(map[string]string, 4),
-					StringSliceOffsets: make(map[string]SliceOffset, 4),
-					IntSliceOffsets:    make(map[string]SliceOffset, 4),
-
-					GlobalIntFlags:           make(map[string]int, 4),
-					GlobalStringFlags:        make(map[string]string, 4),
-					GlobalBoolFlags:          make(map[string]bool, 4),
-					GlobalDurationFlags:      make(map[string]time.Duration, 2),
-					GlobalFloatFlags:         make(map[string]float64, 2),
-					GlobalEnumFlags:          make(map[string]string, 2),
-					GlobalStringSliceOffsets: make(map[string]SliceOffset, 2),
-					GlobalIntSliceOffsets:    make(map[string]SliceOffset, 2),
+					IntFlags:             make(map[string]int, 8),
+					Int64Flags:           make(map[string]int64, 2),
+					Uint64Flags:          make(map[string]uint64, 2),
+					StringFlags:          make(map[string]string, 8),
+					BoolFlags:            make(map[string]bool, 8),
+					DurationFlags:        make(map[string]time.Duration, 4),
+					FloatFlags:           make(map[string]float64, 4),
+					EnumFlags:            make(map[string]string, 4),
+					StringSliceOffsets:   make(map[string]SliceOffset, 4),
+					IntSliceOffsets:      make(map[string]SliceOffset, 4),
+					FloatSliceOffsets:    make(map[string]SliceOffset, 2),
+					DurationSliceOffsets: make(map[string]SliceOffset, 2),
+					EnumSliceOffsets:     make(map[string]SliceOffset, 2),
+
+					GlobalIntFlags:             make(map[string]int, 4),
+					GlobalInt64Flags:           make(map[string]int64, 2),
+					GlobalUint64Flags:          make(map[string]uint64, 2),
+					GlobalStringFlags:          make(map[string]string, 4),
+					GlobalBoolFlags:            make(map[string]bool, 4),
+					GlobalDurationFlags:        make(map[string]time.Duration, 2),
+					GlobalFloatFlags:           make(map[string]float64, 2),
+					GlobalEnumFlags:            make(map[string]string, 2),
+					GlobalStringSliceOffsets:   make(map[string]SliceOffset, 2),
+					GlobalIntSliceOffsets:      make(map[string]SliceOffset, 2),
+					GlobalFloatSliceOffsets:    make(map[string]SliceOffset, 2),
+					GlobalDurationSliceOffsets: make(map[string]SliceOffset, 2),
+					GlobalEnumSliceOffsets:     make(map[string]SliceOffset, 2),
 
 					// Positional arguments
 					ArgStrings:      make(map[string]string, 4),
@@ -310,6 +370,8 @@ func NewParseResultPool() *ParseResultPool {
 			func(result *ParseResult) {
 				// Clear all maps without reallocating
 				clearMap(result.IntFlags)
+				clearMap(result.Int64Flags)
+				clearMap(result.Uint64Flags)
 				clearMap(result.StringFlags)
 				clearMap(result.BoolFlags)
 				clearMap(result.DurationFlags)
@@ -317,8 +379,13 @@ func NewParseResultPool() *ParseResultPool {
 				clearMap(result.EnumFlags)
 				clearMap(result.StringSliceOffsets)
 				clearMap(result.IntSliceOffsets)
+				clearMap(result.FloatSliceOffsets)
+				clearMap(result.DurationSliceOffsets)
+				clearMap(result.EnumSliceOffsets)
 
 				clearMap(result.GlobalIntFlags)
+				clearMap(result.GlobalInt64Flags)
+				clearMap(result.GlobalUint64Flags)
 				clearMap(result.GlobalStringFlags)
 				clearMap(result.GlobalBoolFlags)
 				clearMap(result.GlobalDurationFlags)
@@ -326,6 +393,9 @@ func NewParseResultPool() *ParseResultPool {
 				clearMap(result.GlobalEnumFlags)
 				clearMap(result.GlobalStringSliceOffsets)
 				clearMap(result.GlobalIntSliceOffsets)
+				clearMap(result.GlobalFloatSliceOffsets)
+				clearMap(result.GlobalDurationSliceOffsets)
+				clearMap(result.GlobalEnumSliceOffsets)
 
 				// Clear positional argument maps
 				clearMap(result.ArgStrings)
@@ -345,8 +415,8 @@ func NewParseResultPool() *ParseResultPool {
 
 // clearMap efficiently clears a map without reallocating
 func clearMap[K comparable, V any](m map[K]V) {
-	for k := range m {
-		delete(m, k)
+	if len(m) > 0 {
+		clear(m)
 	}
 }
 
@@ -361,6 +431,12 @@ var (
 	// Global int slice pool for numeric flag values
 	GlobalIntSlicePool = NewIntSlicePool(16)
 
+	// Global float64 slice pool for float slice flag values
+	GlobalFloatSlicePool = NewFloatSlicePool(8)
+
+	// Global duration slice pool for duration slice flag values
+	GlobalDurationSlicePool = NewDurationSlicePool(8)
+
 	// Global ParseResult pool for parser results
 	GlobalParseResultPool = NewParseResultPool()
 )
@@ -420,6 +496,26 @@ func PutIntSlice(slice *[]int) {
 	GlobalIntSlicePool.Put(slice)
 }
 
+// GetFloatSlice retrieves a float64 slice for float slice CLI values
+func GetFloatSlice() *[]float64 {
+	return GlobalFloatSlicePool.Get()
+}
+
+// PutFloatSlice returns a float64 slice to the global pool
+func PutFloatSlice(slice *[]float64) {
+	GlobalFloatSlicePool.Put(slice)
+}
+
+// GetDurationSlice retrieves a time.Duration slice for duration slice CLI values
+func GetDurationSlice() *[]time.Duration {
+	return GlobalDurationSlicePool.Get()
+}
+
+// PutDurationSlice returns a time.Duration slice to the global pool
+func PutDurationSlice(slice *[]time.Duration) {
+	GlobalDurationSlicePool.Put(slice)
+}
+
 // GetParseResult retrieves a ParseResult for CLI parsing
 func GetParseResult() *ParseResult {
 	return GlobalParseResultPool.Get()
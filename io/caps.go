@@ -0,0 +1,186 @@
+package snapio
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// unicodeMode overrides SupportsUnicode detection
+const (
+	unicodeAuto = iota
+	unicodeOn
+	unicodeOff
+)
+
+// ForceUnicode reports Unicode and emoji support regardless of environment.
+func (m *IOManager) ForceUnicode() *IOManager { m.unicode = unicodeOn; return m }
+
+// NoUnicode restricts output helpers and log prefixes to ASCII.
+func (m *IOManager) NoUnicode() *IOManager { m.unicode = unicodeOff; return m }
+
+// SupportsUnicode reports whether the terminal can render non-ASCII symbols
+// and emoji. It is false for TERM=dumb, the Linux console, non-UTF-8 locales
+// and legacy Windows consoles (Windows Terminal, ConEmu and VS Code qualify).
+func (m *IOManager) SupportsUnicode() bool {
+	switch m.unicode {
+	case unicodeOn:
+		return true
+	case unicodeOff:
+		return false
+	}
+
+	if goos() == "windows" {
+		return os.Getenv("WT_SESSION") != "" || os.Getenv("WT_PROFILE_ID") != "" ||
+			os.Getenv("ConEmuANSI") == "ON" || os.Getenv("TERM_PROGRAM") == "vscode"
+	}
+	if term := os.Getenv("TERM"); term == "dumb" || term == "linux" {
+		return false
+	}
+	// The first locale variable set decides (LC_ALL overrides LC_CTYPE overrides LANG)
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := strings.ToLower(os.Getenv(name)); locale != "" {
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	// No locale: trust GUI terminal emulators (macOS Terminal, iTerm2, ...)
+	return os.Getenv("TERM_PROGRAM") != ""
+}
+
+// SupportsHyperlinks reports whether stdout is a terminal that renders OSC 8
+// hyperlinks. FORCE_HYPERLINK=1 (or 0) overrides detection.
+func (m *IOManager) SupportsHyperlinks() bool {
+	if v, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		return v != "0"
+	}
+	if !m.IsTTY() || os.Getenv("CI") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("KONSOLE_VERSION") != "" || os.Getenv("DOMTERM") != "" {
+		return true
+	}
+	if vte := atoi(os.Getenv("VTE_VERSION")); vte >= 5000 {
+		return true // GNOME Terminal, Tilix, ...
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty":
+		return true
+	}
+	switch os.Getenv("TERM") {
+	case "xterm-kitty", "alacritty", "foot", "xterm-ghostty":
+		return true
+	}
+	return false
+}
+
+// Link renders text as a clickable OSC 8 hyperlink to url when supported;
+// otherwise it returns "text (url)", or just url when text is empty or equal to it.
+func (m *IOManager) Link(text, url string) string {
+	if m.SupportsHyperlinks() {
+		if text == "" {
+			text = url
+		}
+		return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+	}
+	if text == "" || text == url {
+		return url
+	}
+	return text + " (" + url + ")"
+}
+
+// Emoji returns emoji when the terminal can render it, fallback otherwise:
+//
+//	fmt.Fprintln(io.Out(), io.Emoji("✅", "[ok]"), "done")
+func (m *IOManager) Emoji(emoji, fallback string) string {
+	if m.SupportsUnicode() {
+		return emoji
+	}
+	return fallback
+}
+
+// Capabilities is a snapshot of what the terminal supports, taken by
+// IOManager.Capabilities. Libraries layering on snapio can branch on it once
+// instead of calling each predicate, and its String form is meant for bug
+// reports.
+type Capabilities struct {
+	OS              string            `json:"os"`               // GOOS/GOARCH
+	StdinTTY        bool              `json:"stdin_tty"`        // See IsPiped
+	StdoutTTY       bool              `json:"stdout_tty"`       // See IsTTY
+	Interactive     bool              `json:"interactive"`      // See IsInteractive
+	ColorLevel      int               `json:"color_level"`      // See ColorLevel; 0 when color is off
+	Unicode         bool              `json:"unicode"`          // See SupportsUnicode
+	Hyperlinks      bool              `json:"hyperlinks"`       // See SupportsHyperlinks
+	Width           int               `json:"width"`            // See Width
+	Height          int               `json:"height"`           // See Height
+	VirtualTerminal bool              `json:"virtual_terminal"` // ANSI processing on (always true outside Windows)
+	LegacyConsole   bool              `json:"legacy_console"`   // See LegacyConsoleActive
+	Env             map[string]string `json:"env,omitempty"`    // Set variables that affect detection
+}
+
+// capabilityEnv lists the environment variables that influence detection
+var capabilityEnv = []string{
+	"TERM", "TERM_PROGRAM", "COLORTERM", "NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE",
+	"FORCE_HYPERLINK", "LANG", "LC_ALL", "LC_CTYPE", "CI", "COLUMNS", "LINES", "WT_SESSION", "VTE_VERSION",
+}
+
+// Capabilities detects every terminal capability at once
+func (m *IOManager) Capabilities() Capabilities {
+	caps := Capabilities{
+		OS:              runtime.GOOS + "/" + runtime.GOARCH,
+		StdinTTY:        !m.IsPiped(),
+		StdoutTTY:       m.IsTTY(),
+		Interactive:     m.IsInteractive(),
+		ColorLevel:      m.ColorLevel(),
+		Unicode:         m.SupportsUnicode(),
+		Hyperlinks:      m.SupportsHyperlinks(),
+		Width:           m.Width(),
+		Height:          m.Height(),
+		VirtualTerminal: m.p.vtEnabled(),
+		LegacyConsole:   m.legacyActive,
+	}
+	for _, name := range capabilityEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			if caps.Env == nil {
+				caps.Env = make(map[string]string)
+			}
+			caps.Env[name] = value
+		}
+	}
+	return caps
+}
+
+// colorLevelNames describes ColorLevel values
+var colorLevelNames = [...]string{"none", "16 colors", "256 colors", "truecolor"}
+
+// String renders the capabilities as "name: value" lines
+func (c Capabilities) String() string {
+	level := fmt.Sprint(c.ColorLevel)
+	if c.ColorLevel >= 0 && c.ColorLevel < len(colorLevelNames) {
+		level = colorLevelNames[c.ColorLevel]
+	}
+	var b strings.Builder
+	line := func(name string, value any) {
+		b.WriteString(strings.TrimRight(fmt.Sprintf("%-18s %v", name+":", value), " ") + "\n")
+	}
+	line("os", c.OS)
+	line("stdin tty", c.StdinTTY)
+	line("stdout tty", c.StdoutTTY)
+	line("interactive", c.Interactive)
+	line("color", level)
+	line("unicode", c.Unicode)
+	line("hyperlinks", c.Hyperlinks)
+	line("size", fmt.Sprintf("%dx%d", c.Width, c.Height))
+	line("virtual terminal", c.VirtualTerminal)
+	line("legacy console", c.LegacyConsole)
+	names := make([]string, 0, len(c.Env))
+	for name := range c.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		line("$"+name, c.Env[name])
+	}
+	return b.String()
+}
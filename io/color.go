@@ -144,9 +144,9 @@ func (s *Style) Underline() *Style     { s.underline = true; return s }
 func (s *Style) Inverse() *Style       { s.inverse = true; return s }
 
 // Sprint returns a styled string if color is supported; otherwise it returns
-// the text unchanged.
+// the text unchanged. A nil style leaves the text plain.
 func (s *Style) Sprint(io *IOManager, text string) string {
-	if !io.SupportsColor() {
+	if s == nil || !io.SupportsColor() {
 		return text
 	}
 	seq := s.ansiPrefix(io)
@@ -257,9 +257,31 @@ func itoa(n int) string {
 	return string(buf[i:])
 }
 
-// Theme provides semantic colors
+// Theme provides semantic colors and the named styles used by help output,
+// error messages and the logger. A nil style renders plain text.
 type Theme struct {
 	Primary, Success, Warning, Error, Info, Debug, Muted ColorSpec
+
+	HeadingStyle *Style // Help section headers ("Usage:", "Flags:")
+	FlagStyle    *Style // Flag names in help
+	ErrorStyle   *Style // Error messages and error log lines
+	SuccessStyle *Style // Success log lines
+}
+
+// Built-in theme names accepted by ThemeByName
+const (
+	ThemeNameDefault      = "default"
+	ThemeNameMonochrome   = "monochrome"
+	ThemeNameHighContrast = "high-contrast"
+)
+
+// withStyles derives the named styles from the semantic colors
+func (t Theme) withStyles() Theme {
+	t.HeadingStyle = NewStyle().Bold().Fg(t.Primary)
+	t.FlagStyle = NewStyle().Fg(t.Info)
+	t.ErrorStyle = NewStyle().Bold().Fg(t.Error)
+	t.SuccessStyle = NewStyle().Fg(t.Success)
+	return t
 }
 
 // DefaultTheme16 returns a theme using basic 16 colors (ANSI colors 0-15).
@@ -273,7 +295,7 @@ func DefaultTheme16() Theme {
 		Info:    BrightCyan,
 		Debug:   BrightMagenta, // Best we can do with 16 colors
 		Muted:   BrightBlack,   // Gray
-	}
+	}.withStyles()
 }
 
 // DefaultTheme256 returns a theme using 256-color palette.
@@ -287,7 +309,7 @@ func DefaultTheme256() Theme {
 		Info:    BrightCyan,
 		Debug:   LightPurple, // Indexed(141) - proper light purple
 		Muted:   BrightBlack,
-	}
+	}.withStyles()
 }
 
 // DefaultThemeTruecolor returns a theme using 24-bit RGB colors.
@@ -301,7 +323,7 @@ func DefaultThemeTruecolor() Theme {
 		Info:    TrueBrightCyan,   // RGB(139, 233, 253)
 		Debug:   TrueLightPurple,  // RGB(189, 147, 249)
 		Muted:   TrueGray,         // RGB(128, 128, 128)
-	}
+	}.withStyles()
 }
 
 // DefaultTheme returns the appropriate default theme based on IOManager's color level.
@@ -320,6 +342,49 @@ func DefaultTheme(io *IOManager) Theme {
 	}
 }
 
+// MonochromeTheme returns a theme without colors: structure is conveyed with
+// bold and underline only, for users who find colored output distracting.
+func MonochromeTheme() Theme {
+	return Theme{
+		HeadingStyle: NewStyle().Bold().Underline(),
+		FlagStyle:    NewStyle().Bold(),
+		ErrorStyle:   NewStyle().Bold(),
+	}
+}
+
+// HighContrastTheme returns a theme of bold, bright basic colors that stays
+// legible on both dark and light backgrounds and with limited color vision.
+func HighContrastTheme() Theme {
+	return Theme{
+		Primary:      BrightWhite,
+		Success:      BrightGreen,
+		Warning:      BrightYellow,
+		Error:        BrightRed,
+		Info:         BrightCyan,
+		Debug:        BrightMagenta,
+		Muted:        White,
+		HeadingStyle: NewStyle().Bold().Underline().Fg(BrightWhite),
+		FlagStyle:    NewStyle().Bold().Fg(BrightYellow),
+		ErrorStyle:   NewStyle().Bold().Fg(BrightWhite).Bg(Red),
+		SuccessStyle: NewStyle().Bold().Fg(BrightGreen),
+	}
+}
+
+// ThemeByName returns a built-in theme ("default", "monochrome",
+// "high-contrast"), e.g. to back a --theme flag or environment variable.
+func ThemeByName(name string, io *IOManager) (Theme, bool) {
+	switch name {
+	case ThemeNameDefault:
+		return DefaultTheme(io), true
+	case ThemeNameMonochrome:
+		return MonochromeTheme(), true
+	case ThemeNameHighContrast:
+		return HighContrastTheme(), true
+	default:
+		return Theme{}, false
+	}
+}
+
 // TruecolorTheme is an alias for DefaultThemeTruecolor for backward compatibility.
 // Deprecated: Use DefaultThemeTruecolor() or DefaultTheme(io) instead.
 func TruecolorTheme() Theme {
@@ -0,0 +1,102 @@
+package snapio
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// groupThreshold is the smallest magnitude that gets thousands separators;
+// four-digit values such as ports and years read better without them
+const groupThreshold = 10000
+
+// FormatDuration formats d like time.Duration.String without trailing zero
+// units ("1h" instead of "1h0m0s", "2m" instead of "2m0s"); the result
+// still parses with time.ParseDuration
+func FormatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// FormatNumber formats n with comma thousands separators when |n| >= 10000
+// ("1,000,000"); smaller values are returned as is
+func FormatNumber(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if n > -groupThreshold && n < groupThreshold {
+		return s
+	}
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	return sign + groupDigits(s)
+}
+
+// FormatUint is FormatNumber for unsigned values
+func FormatUint(n uint64) string {
+	s := strconv.FormatUint(n, 10)
+	if n < groupThreshold {
+		return s
+	}
+	return groupDigits(s)
+}
+
+// FormatFloat formats f in plain decimal notation (never "1e+06"), grouping
+// the integer part like FormatNumber
+func FormatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if (f > -groupThreshold && f < groupThreshold) || math.IsInf(f, 0) || math.IsNaN(f) {
+		return s
+	}
+	sign := ""
+	if f < 0 {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	s = groupDigits(whole)
+	if hasFrac {
+		s += "." + frac
+	}
+	return sign + s
+}
+
+// FormatBytes formats a byte count with binary units ("512B", "1.5KiB", "64MiB")
+func FormatBytes(n int64) string {
+	const units = "KMGTPE"
+	if n < 1024 && n > -1024 {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	value := float64(n)
+	unit := -1
+	for (value >= 1024 || value <= -1024) && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	// One decimal, dropped when it is zero ("2KiB", not "2.0KiB")
+	s := strconv.FormatFloat(value, 'f', 1, 64)
+	s = strings.TrimSuffix(s, ".0")
+	return s + string(units[unit]) + "iB"
+}
+
+// groupDigits inserts a comma every three digits from the right
+func groupDigits(digits string) string {
+	var b strings.Builder
+	b.Grow(len(digits) + len(digits)/3)
+	lead := len(digits) % 3
+	if lead == 0 {
+		lead = 3
+	}
+	b.WriteString(digits[:lead])
+	for i := lead; i < len(digits); i += 3 {
+		b.WriteByte(',')
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
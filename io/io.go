@@ -13,6 +13,8 @@ type platformIO interface {
 	enableVirtualTerminal() bool
 	vtEnabled() bool
 	colorCapabilityLevel() int // Returns detected color level: 0=none, 1=16, 2=256, 3=truecolor
+	makeRaw(*os.File) (restore func() error, err error)
+	legacyConsole(*os.File) (stdio.Writer, bool) // ANSI-translating writer for consoles without VT
 }
 
 // newPlatformIO is provided by platform files
@@ -27,6 +29,9 @@ type IOManager struct {
 	noColor            bool
 	forceColorLevel    int
 	hasForceColorLevel bool
+	unicode            int // unicodeAuto, unicodeOn or unicodeOff
+	legacyColors       bool
+	legacyActive       bool
 
 	p platformIO
 }
@@ -37,6 +42,13 @@ func New() *IOManager {
 	return m
 }
 
+// Clone returns an independent copy of the manager, e.g. to change writers
+// or color settings for one command without affecting the original.
+func (m *IOManager) Clone() *IOManager {
+	c := *m
+	return &c
+}
+
 // WithIn sets the input reader used by the manager and returns the manager for chaining.
 func (m *IOManager) WithIn(r stdio.Reader) *IOManager { m.in = r; return m }
 
@@ -96,16 +108,22 @@ func (m *IOManager) Height() int {
 func (m *IOManager) IsPiped() bool      { return !m.p.isTerminal(os.Stdin) }
 func (m *IOManager) IsRedirected() bool { return !m.p.isTerminal(os.Stdout) }
 
-// SupportsColor determines ANSI color capability (0=none,1=16,2=256,3=16m via truecolor)
+// SupportsColor determines ANSI color capability (0=none,1=16,2=256,3=16m via truecolor).
+// It honors NO_COLOR (https://no-color.org) and the CLICOLOR/CLICOLOR_FORCE
+// convention: CLICOLOR_FORCE (or FORCE_COLOR) forces color even when piped,
+// CLICOLOR=0 disables it.
 func (m *IOManager) SupportsColor() bool {
 	if m.noColor || os.Getenv("NO_COLOR") != "" {
 		return false
 	}
-	if m.forceColor || os.Getenv("FORCE_COLOR") != "" {
+	if m.forceColor || os.Getenv("FORCE_COLOR") != "" || envEnabled("CLICOLOR_FORCE") {
 		return true
 	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return false
+	}
 	if goos() == "windows" {
-		return m.p.vtEnabled()
+		return m.p.vtEnabled() || m.legacyActive
 	}
 	// Unix: TTY and TERM not dumb
 	if !m.IsTTY() {
@@ -124,6 +142,10 @@ func (m *IOManager) ColorLevel() int {
 	if !m.SupportsColor() {
 		return 0
 	}
+	// Console attributes only offer 16 colors
+	if m.legacyActive && !m.p.vtEnabled() {
+		return 1
+	}
 	// Check for explicit truecolor/24bit environment variable
 	colorterm := os.Getenv("COLORTERM")
 	if colorterm == "truecolor" || colorterm == "24bit" {
@@ -175,8 +197,17 @@ func (m *IOManager) ColorLevel() int {
 	return 1
 }
 
-// EnableVirtualTerminal tries to enable ANSI processing on Windows consoles
-func (m *IOManager) EnableVirtualTerminal() bool { return m.p.enableVirtualTerminal() }
+// EnableVirtualTerminal tries to enable ANSI processing on Windows consoles.
+// When that fails and LegacyConsoleColors was set, colors are translated to
+// console attributes instead (see LegacyConsoleActive); the result still
+// reports whether VT processing itself is on.
+func (m *IOManager) EnableVirtualTerminal() bool {
+	if m.p.enableVirtualTerminal() {
+		return true
+	}
+	m.enableLegacyConsole()
+	return false
+}
 
 // ANSI helpers
 
@@ -202,6 +233,13 @@ func (m *IOManager) Italic(s string) string { return m.Colorize(s, "3") }
 func (m *IOManager) Underline(s string) string { return m.Colorize(s, "4") }
 
 // helpers
+
+// envEnabled reports whether name is set to anything but "" or "0"
+func envEnabled(name string) bool {
+	v := os.Getenv(name)
+	return v != "" && v != "0"
+}
+
 func contains(s, sub string) bool {
 	if len(sub) == 0 {
 		return true
@@ -3,6 +3,7 @@
 package snapio
 
 import (
+	stdio "io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -56,6 +57,8 @@ func (u *unixPlatform) termSize(f *os.File) (int, int, bool) {
 func (u *unixPlatform) enableVirtualTerminal() bool { return true }
 func (u *unixPlatform) vtEnabled() bool             { return true }
 
+func (u *unixPlatform) makeRaw(f *os.File) (func() error, error) { return makeRawTermios(f) }
+
 // detectColorCapability queries the terminal for its actual color capability
 func (u *unixPlatform) detectColorCapability() int {
 	u.colorCapOnce.Do(func() {
@@ -106,3 +109,6 @@ func (u *unixPlatform) colorCapabilityLevel() int {
 	}
 	return 0 // No color
 }
+
+// legacyConsole is Windows-only; Unix terminals interpret ANSI directly
+func (u *unixPlatform) legacyConsole(*os.File) (stdio.Writer, bool) { return nil, false }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 	"unsafe"
 )
 
@@ -100,3 +101,248 @@ func TestUnix_FluentRedirects(t *testing.T) {
 		t.Fatalf("missing writer")
 	}
 }
+
+func TestUnix_UnicodeAndHyperlinks(t *testing.T) {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG", "TERM_PROGRAM", "SNAP_GOOS", "WT_SESSION"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("TERM", "xterm-256color")
+	m := New()
+	if m.SupportsUnicode() {
+		t.Fatalf("no locale and no terminal program should mean ASCII")
+	}
+	t.Setenv("LANG", "en_US.UTF-8")
+	if !m.SupportsUnicode() {
+		t.Fatalf("UTF-8 locale should enable unicode")
+	}
+	t.Setenv("LC_ALL", "C")
+	if m.SupportsUnicode() {
+		t.Fatalf("LC_ALL should override LANG")
+	}
+	t.Setenv("LC_ALL", "")
+	t.Setenv("TERM", "dumb")
+	if m.SupportsUnicode() || m.Emoji("✅", "[ok]") != "[ok]" {
+		t.Fatalf("dumb terminal should fall back to ASCII")
+	}
+	if !m.ForceUnicode().SupportsUnicode() || m.NoUnicode().SupportsUnicode() {
+		t.Fatalf("unicode overrides not applied")
+	}
+
+	t.Setenv("FORCE_HYPERLINK", "0")
+	if got := m.Link("docs", "https://example.com"); got != "docs (https://example.com)" {
+		t.Fatalf("fallback link: %q", got)
+	}
+	if got := m.Link("", "https://example.com"); got != "https://example.com" {
+		t.Fatalf("bare link: %q", got)
+	}
+	t.Setenv("FORCE_HYPERLINK", "1")
+	if got := m.Link("docs", "https://example.com"); got != "\x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x1b\\" {
+		t.Fatalf("OSC 8 link: %q", got)
+	}
+}
+
+func TestUnix_Capabilities(t *testing.T) {
+	t.Setenv("COLUMNS", "101")
+	t.Setenv("LINES", "55")
+	t.Setenv("FORCE_HYPERLINK", "1")
+	t.Setenv("LANG", "en_US.UTF-8")
+	t.Setenv("LC_ALL", "")
+	caps := New().ForceColor().ForceColorLevel(2).Capabilities()
+	if caps.ColorLevel != 2 || !caps.Hyperlinks || !caps.Unicode || caps.Width != 101 || caps.Height != 55 ||
+		!caps.VirtualTerminal || caps.Env["FORCE_HYPERLINK"] != "1" {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+	report := caps.String()
+	for _, want := range []string{"color:             256 colors\n", "size:              101x55\n", "$FORCE_HYPERLINK:  1\n", "$LC_ALL:\n"} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report lacks %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestUnix_LoggerASCIIFallback(t *testing.T) {
+	var out strings.Builder
+	m := New().WithOut(&out).NoColor().NoUnicode()
+	l := NewLogger(m)
+	l.Info("hello")
+	l.SetPrefix(LevelSuccess, "OK").Success("done")
+	m.ForceUnicode()
+	l.Info("again")
+	if got, want := out.String(), "[INFO] hello\nOK done\n🔵 again\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestUnix_PagerFallback(t *testing.T) {
+	var out strings.Builder
+	p := New().WithOut(&out).Pager()
+	for range 500 {
+		_, _ = p.Write([]byte("line\n"))
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "line\n") != 500 {
+		t.Fatalf("non-terminal output must be written directly, got %d bytes", out.Len())
+	}
+
+	t.Setenv("PAGER", "less -S")
+	if got := pagerCommand(); len(got) != 2 || got[0] != "less" || got[1] != "-S" {
+		t.Fatalf("pager command: %q", got)
+	}
+}
+
+func TestUnix_RawModeKeys(t *testing.T) {
+	var out strings.Builder
+	m := New().WithIn(strings.NewReader("a\x1b[A\x03\x1b[3~\r\x1bxé\x1b[1;5C")).WithOut(&out)
+	var keys []string
+	err := m.RawMode(func(term *Terminal) error {
+		for {
+			key, err := term.ReadKey()
+			if err != nil {
+				return nil //nolint:nilerr // end of scripted input
+			}
+			keys = append(keys, key.String())
+			_, _ = term.Write([]byte("."))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a up ctrl+c delete enter alt+x é right"
+	if got := strings.Join(keys, " "); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if out.String() != "........" {
+		t.Fatalf("terminal output: %q", out.String())
+	}
+}
+
+func TestUnix_LegacyConsoleWriter(t *testing.T) {
+	var out strings.Builder
+	var attrs []uint16
+	w := newANSIConsoleWriter(&out, 0x07, func(a uint16) { attrs = append(attrs, a) })
+
+	// Sequences may be split across writes; non-SGR escapes are dropped
+	for _, chunk := range []string{"a\x1b[1;3", "1mred\x1b[0m b", "\x1b]8;;http://x\x07c\x1b[38;5;200;44md\x1b[2K"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out.String() != "ared bcd" {
+		t.Fatalf("text = %q", out.String())
+	}
+	want := []uint16{0x0c, 0x07, 0x17}
+	if len(attrs) != len(want) {
+		t.Fatalf("attrs = %#x, want %#x", attrs, want)
+	}
+	for i := range want {
+		if attrs[i] != want[i] {
+			t.Fatalf("attrs = %#x, want %#x", attrs, want)
+		}
+	}
+
+	// On Unix the fallback never activates
+	m := New().LegacyConsoleColors()
+	if !m.EnableVirtualTerminal() || m.LegacyConsoleActive() {
+		t.Fatalf("legacy console should stay off on unix")
+	}
+}
+
+func TestUnix_FormatHelpers(t *testing.T) {
+	cases := []struct{ got, want string }{
+		{FormatDuration(time.Hour), "1h"},
+		{FormatDuration(90 * time.Minute), "1h30m"},
+		{FormatDuration(2 * time.Minute), "2m"},
+		{FormatDuration(1500 * time.Millisecond), "1.5s"},
+		{FormatDuration(time.Hour + time.Second), "1h0m1s"},
+		{FormatNumber(8080), "8080"},
+		{FormatNumber(1000000), "1,000,000"},
+		{FormatNumber(-12345), "-12,345"},
+		{FormatUint(123456789), "123,456,789"},
+		{FormatFloat(1e6), "1,000,000"},
+		{FormatFloat(-12345.25), "-12,345.25"},
+		{FormatFloat(0.5), "0.5"},
+		{FormatBytes(512), "512B"},
+		{FormatBytes(1536), "1.5KiB"},
+		{FormatBytes(64 << 20), "64MiB"},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("got %q, want %q", c.got, c.want)
+		}
+	}
+	if d, err := time.ParseDuration(FormatDuration(90 * time.Minute)); err != nil || d != 90*time.Minute {
+		t.Fatalf("round trip: %v %v", d, err)
+	}
+}
+
+func TestUnix_Layout(t *testing.T) {
+	cases := []struct{ got, want string }{
+		{Wrap("the quick brown fox jumps", 10), "the quick\nbrown fox\njumps"},
+		{Wrap("  indented text here", 12), "  indented\n  text here"},
+		{Wrap("abcdefghij", 4), "abcd\nefgh\nij"},
+		{Wrap("\x1b[1mbold\x1b[0m word", 6), "\x1b[1mbold\x1b[0m\nword"},
+		{Wrap("日本語のテキスト", 6), "日本語\nのテキ\nスト"},
+		{Indent("a\n\nb", 2), "  a\n\n  b"},
+		{Truncate("hello world", 8), "hello..."},
+		{Truncate("short", 8), "short"},
+		{Truncate("\x1b[31mred text\x1b[0m", 6), "\x1b[31mred...\x1b[0m"},
+		{Truncate("日本語", 5), "日..."},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("got %q, want %q", c.got, c.want)
+		}
+	}
+	if w := StringWidth("\x1b[32m✅ ok\x1b[0m é"); w != 7 {
+		t.Fatalf("StringWidth = %d, want 7", w)
+	}
+
+	list := DefinitionList([]Definition{
+		{Term: "--output FILE", Description: "Write the report to FILE instead of stdout"},
+		{Term: "-v", Description: "Verbose"},
+	}, 41)
+	want := "  --output FILE  Write the report to FILE\n" +
+		"                 instead of stdout\n" +
+		"  -v             Verbose\n"
+	if list != want {
+		t.Fatalf("DefinitionList:\n%s\nwant:\n%s", list, want)
+	}
+	if got := DefinitionList([]Definition{{Term: "--name", Description: "Name to use"}}, 24); got !=
+		"  --name\n      Name to use\n" {
+		t.Fatalf("stacked DefinitionList = %q", got)
+	}
+}
+
+func TestUnix_OnResize(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	t.Setenv("LINES", "30")
+	m := New()
+	if m.IsTTY() {
+		t.Skip("size comes from the terminal, not COLUMNS")
+	}
+	sizes := make(chan [2]int, 1)
+	stop := m.OnResize(func(w, h int) { sizes <- [2]int{w, h} })
+	defer stop()
+
+	t.Setenv("COLUMNS", "120")
+	deadline := time.After(2 * time.Second)
+	for {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGWINCH); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-sizes:
+			if got != [2]int{120, 30} {
+				t.Fatalf("resize reported %v, want [120 30]", got)
+			}
+			stop()
+			stop() // stopping twice is harmless
+			return
+		case <-deadline:
+			t.Fatal("no resize notification")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package snapio
 
 import (
+	stdio "io"
 	"os"
 	"syscall"
 	"unsafe"
@@ -29,12 +30,20 @@ var (
 	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
 	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
 	procGetStdHandle               = kernel32.NewProc("GetStdHandle")
+	procSetConsoleTextAttribute    = kernel32.NewProc("SetConsoleTextAttribute")
 )
 
 const (
 	stdOutputHandle                 = ^uintptr(10) + 1 // (uintptr)(-11)
 	stdInputHandle                  = ^uintptr(8) + 1  // (uintptr)(-10)
+	stdErrorHandle                  = ^uintptr(11) + 1 // (uintptr)(-12)
 	enableVirtualTerminalProcessing = 0x0004
+
+	// Console input modes
+	enableProcessedInput       = 0x0001
+	enableLineInput            = 0x0002
+	enableEchoInput            = 0x0004
+	enableVirtualTerminalInput = 0x0200
 )
 
 func stdHandle(file *os.File) uintptr {
@@ -44,6 +53,7 @@ func stdHandle(file *os.File) uintptr {
 	if file == os.Stdin {
 		return stdInputHandle
 	}
+
 	return uintptr(file.Fd())
 }
 
@@ -129,3 +139,43 @@ func (w *windowsPlatform) colorCapabilityLevel() int {
 
 	return 0 // No color support detected
 }
+
+// makeRaw disables line input, echo and Ctrl+C processing on the console and
+// enables VT input so arrow keys arrive as escape sequences
+func (w *windowsPlatform) makeRaw(f *os.File) (func() error, error) {
+	h := stdHandle(f)
+	var old uint32
+	if r, _, err := procGetConsoleMode.Call(h, uintptr(unsafe.Pointer(&old))); r == 0 {
+		return nil, err
+	}
+	raw := old&^(enableProcessedInput|enableLineInput|enableEchoInput) | enableVirtualTerminalInput
+	if r, _, err := procSetConsoleMode.Call(h, uintptr(raw)); r == 0 {
+		return nil, err
+	}
+	return func() error {
+		if r, _, err := procSetConsoleMode.Call(h, uintptr(old)); r == 0 {
+			return err
+		}
+		return nil
+	}, nil
+}
+
+// legacyConsole returns a writer translating ANSI colors into console text
+// attributes for f, starting from its current attributes
+func (w *windowsPlatform) legacyConsole(f *os.File) (stdio.Writer, bool) {
+	std := stdOutputHandle
+	if f == os.Stderr {
+		std = stdErrorHandle
+	}
+	h, _, _ := procGetStdHandle.Call(std)
+	if h == 0 || h == uintptr(^uintptr(0)) {
+		return nil, false
+	}
+	var info consoleScreenBufferInfo
+	if r, _, _ := procGetConsoleScreenBufferInfo.Call(h, uintptr(unsafe.Pointer(&info))); r == 0 {
+		return nil, false
+	}
+	return newANSIConsoleWriter(f, info.WAttributes, func(attr uint16) {
+		_, _, _ = procSetConsoleTextAttribute.Call(h, uintptr(attr))
+	}), true
+}
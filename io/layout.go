@@ -0,0 +1,240 @@
+package snapio
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Width-aware text layout. Widths are terminal columns: ANSI escape
+// sequences take none, East Asian wide characters and emoji take two and
+// combining marks none.
+
+// wideRanges are the code points rendered two columns wide (East Asian Wide
+// and Fullwidth, emoji presentation), sorted
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0},
+	{0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F},
+	{0x2693, 0x2693}, {0x26A1, 0x26A1}, {0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5},
+	{0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B}, {0x2728, 0x2728},
+	{0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+	{0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19}, {0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4}, {0x17000, 0x18CFF}, {0x1B000, 0x1B2FF},
+	{0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F2FF},
+	{0x1F300, 0x1F320}, {0x1F32D, 0x1F335}, {0x1F337, 0x1F37C}, {0x1F37E, 0x1F393}, {0x1F3A0, 0x1F3CA},
+	{0x1F3CF, 0x1F3D3}, {0x1F3E0, 0x1F3F0}, {0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E}, {0x1F440, 0x1F440},
+	{0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D}, {0x1F54B, 0x1F54E}, {0x1F550, 0x1F567}, {0x1F57A, 0x1F57A},
+	{0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4}, {0x1F5FB, 0x1F64F}, {0x1F680, 0x1F6C5}, {0x1F6CC, 0x1F6CC},
+	{0x1F6D0, 0x1F6D2}, {0x1F6D5, 0x1F6D7}, {0x1F6EB, 0x1F6EC}, {0x1F6F4, 0x1F6FC}, {0x1F7E0, 0x1F7EB},
+	{0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945}, {0x1F947, 0x1F9FF}, {0x1FA70, 0x1FAFF}, {0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+// RuneWidth returns the number of columns r occupies: 0 for control
+// characters and combining marks, 2 for wide characters, 1 otherwise
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	i := sort.Search(len(wideRanges), func(i int) bool { return wideRanges[i][1] >= r })
+	if i < len(wideRanges) && wideRanges[i][0] <= r {
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the number of columns s occupies on one line,
+// ignoring ANSI escape sequences
+func StringWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			i = escapeEnd(s, i)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += RuneWidth(r)
+		i += size
+	}
+	return width
+}
+
+// Wrap breaks each line of text at spaces so no line is wider than width
+// columns; words wider than width are split. Runs of spaces collapse, a
+// line's indentation is kept on its continuation lines and escape sequences
+// stay attached to their words. width <= 0 returns text unchanged.
+func Wrap(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapLine wraps one line of text (see Wrap)
+func wrapLine(line string, width int) string {
+	if StringWidth(line) <= width {
+		return line
+	}
+	indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+	if len(indent) >= width {
+		indent = ""
+	}
+	avail := width - len(indent)
+
+	var b strings.Builder
+	b.WriteString(indent)
+	col := 0
+	for _, word := range strings.Fields(line) {
+		w := StringWidth(word)
+		switch {
+		case col == 0:
+		case col+1+w <= avail:
+			b.WriteByte(' ')
+			col++
+		default:
+			b.WriteString("\n" + indent)
+			col = 0
+		}
+		for w > avail-col {
+			head, rest := cutWidth(word, avail-col)
+			if head == "" && col == 0 {
+				break // a single character wider than the line
+			}
+			b.WriteString(head + "\n" + indent)
+			word, w, col = rest, StringWidth(rest), 0
+		}
+		b.WriteString(word)
+		col += w
+	}
+	return b.String()
+}
+
+// cutWidth splits s after at most width columns; escape sequences before
+// the cut stay in head
+func cutWidth(s string, width int) (head, rest string) {
+	col := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			i = escapeEnd(s, i)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if col+RuneWidth(r) > width {
+			return s[:i], s[i:]
+		}
+		col += RuneWidth(r)
+		i += size
+	}
+	return s, ""
+}
+
+// Indent prefixes every non-empty line of text with n spaces
+func Indent(text string, n int) string {
+	if n <= 0 {
+		return text
+	}
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Truncate shortens each line of text to at most width columns, ending cut
+// lines with "..." (and a style reset when they contain escape sequences)
+func Truncate(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = truncateLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateLine truncates one line of text (see Truncate)
+func truncateLine(line string, width int) string {
+	if StringWidth(line) <= width {
+		return line
+	}
+	const ellipsis = "..."
+	tail := ellipsis
+	if width < len(ellipsis) {
+		tail = ""
+	}
+	head, _ := cutWidth(line, max(width-len(tail), 0))
+	if strings.Contains(line, "\x1b") {
+		tail += "\x1b[0m"
+	}
+	return head + tail
+}
+
+// Definition is one entry of a DefinitionList
+type Definition struct {
+	Term        string
+	Description string
+}
+
+// definitionGap separates the term and description columns
+const definitionGap = 2
+
+// DefinitionList renders terms and descriptions in two columns, indented
+// by two spaces, as in help output:
+//
+//	--output FILE  Write the report to FILE instead of stdout; the file
+//	               is replaced if it exists
+//	--verbose      Print more details
+//
+// Descriptions wrap at width columns (width <= 0 disables wrapping). A term
+// wider than a third of width gets its description on the next line, and
+// every description goes below its term when width leaves it less than 20
+// columns.
+func DefinitionList(defs []Definition, width int) string {
+	const indent, minDescription = 2, 20
+	column := 0
+	for _, def := range defs {
+		if w := StringWidth(def.Term); width <= 0 || w <= width/3 {
+			column = max(column, w)
+		}
+	}
+	start := indent + column + definitionGap
+	stacked := width > 0 && width-start < minDescription
+	if stacked {
+		start = indent + 4
+	}
+
+	var b strings.Builder
+	for _, def := range defs {
+		b.WriteString(strings.Repeat(" ", indent) + def.Term)
+		if def.Description == "" {
+			b.WriteByte('\n')
+			continue
+		}
+		description := def.Description
+		if width > 0 {
+			description = Wrap(description, width-start)
+		}
+		termWidth := StringWidth(def.Term)
+		if stacked || termWidth > column {
+			b.WriteString("\n" + strings.Repeat(" ", start))
+		} else {
+			b.WriteString(strings.Repeat(" ", start-indent-termWidth))
+		}
+		b.WriteString(strings.ReplaceAll(description, "\n", "\n"+strings.Repeat(" ", start)) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,191 @@
+package snapio
+
+import (
+	stdio "io"
+	"os"
+)
+
+// Console character attributes (wincon.h)
+const (
+	consoleBlue      = 0x1
+	consoleGreen     = 0x2
+	consoleRed       = 0x4
+	consoleIntensity = 0x8
+	consoleFgMask    = 0x0f
+	consoleBgMask    = 0xf0
+)
+
+// ansiToConsole maps ANSI color indexes (black, red, green, yellow, blue,
+// magenta, cyan, white) to console color bits, which are ordered BGR
+var ansiToConsole = [8]uint16{
+	0,
+	consoleRed,
+	consoleGreen,
+	consoleRed | consoleGreen,
+	consoleBlue,
+	consoleRed | consoleBlue,
+	consoleGreen | consoleBlue,
+	consoleRed | consoleGreen | consoleBlue,
+}
+
+// ansiConsoleWriter translates ANSI SGR color sequences into console text
+// attribute changes for consoles without VT processing. Other escape
+// sequences are dropped so they are not printed verbatim.
+type ansiConsoleWriter struct {
+	w    stdio.Writer
+	set  func(attr uint16)
+	base uint16 // attributes at startup, restored by SGR 0
+	attr uint16
+	seq  []byte // pending escape sequence, possibly split across writes
+}
+
+func newANSIConsoleWriter(w stdio.Writer, base uint16, set func(uint16)) *ansiConsoleWriter {
+	return &ansiConsoleWriter{w: w, set: set, base: base, attr: base}
+}
+
+// Write implements io.Writer
+func (c *ansiConsoleWriter) Write(p []byte) (int, error) {
+	start := 0
+	flush := func(end int) error {
+		if end > start {
+			if _, err := c.w.Write(p[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := 0; i < len(p); i++ {
+		b := p[i]
+		if c.seq == nil {
+			if b != 0x1b {
+				continue
+			}
+			if err := flush(i); err != nil {
+				return i, err
+			}
+			c.seq = append(make([]byte, 0, 16), b)
+			start = i + 1
+			continue
+		}
+		c.seq = append(c.seq, b)
+		start = i + 1
+		if c.sequenceDone() {
+			c.apply(c.seq)
+			c.seq = nil
+		}
+	}
+	if c.seq == nil {
+		if err := flush(len(p)); err != nil {
+			return start, err
+		}
+	}
+	return len(p), nil
+}
+
+// sequenceDone reports whether the pending escape sequence is complete
+func (c *ansiConsoleWriter) sequenceDone() bool {
+	s := c.seq
+	if len(s) < 2 {
+		return false
+	}
+	switch s[1] {
+	case '[': // CSI: parameters then a final byte in 0x40-0x7e
+		last := s[len(s)-1]
+		return len(s) > 2 && last >= 0x40 && last <= 0x7e
+	case ']': // OSC: terminated by BEL or ST (ESC \)
+		last := s[len(s)-1]
+		return last == 0x07 || (last == '\\' && s[len(s)-2] == 0x1b)
+	default: // two-byte escape
+		return true
+	}
+}
+
+// apply performs a complete escape sequence; only SGR (ESC [ ... m) has an effect
+func (c *ansiConsoleWriter) apply(seq []byte) {
+	if len(seq) < 3 || seq[1] != '[' || seq[len(seq)-1] != 'm' {
+		return
+	}
+	params := parseSGRParams(seq[2 : len(seq)-1])
+	attr := c.attr
+	for i := 0; i < len(params); i++ {
+		n := params[i]
+		switch {
+		case n == 0:
+			attr = c.base
+		case n == 1:
+			attr |= consoleIntensity
+		case n == 22:
+			attr &^= consoleIntensity
+		case n >= 30 && n <= 37:
+			attr = attr&^(consoleFgMask&^consoleIntensity) | ansiToConsole[n-30]
+		case n >= 90 && n <= 97:
+			attr = attr&^consoleFgMask | ansiToConsole[n-90] | consoleIntensity
+		case n == 39:
+			attr = attr&^(consoleFgMask&^consoleIntensity) | c.base&(consoleFgMask&^consoleIntensity)
+		case n >= 40 && n <= 47:
+			attr = attr&^consoleBgMask | ansiToConsole[n-40]<<4
+		case n >= 100 && n <= 107:
+			attr = attr&^consoleBgMask | (ansiToConsole[n-100]|consoleIntensity)<<4
+		case n == 49:
+			attr = attr&^consoleBgMask | c.base&consoleBgMask
+		case n == 38 || n == 48:
+			// 256-color (5;n) and truecolor (2;r;g;b) have no console equivalent
+			if i+1 < len(params) && params[i+1] == 5 {
+				i += 2
+			} else if i+1 < len(params) && params[i+1] == 2 {
+				i += 4
+			}
+		}
+	}
+	if attr != c.attr {
+		c.attr = attr
+		c.set(attr)
+	}
+}
+
+// parseSGRParams splits "1;31" into numbers; empty parameters count as 0
+func parseSGRParams(b []byte) []int {
+	params := []int{0}
+	for _, ch := range b {
+		switch {
+		case ch == ';' || ch == ':':
+			params = append(params, 0)
+		case ch >= '0' && ch <= '9':
+			params[len(params)-1] = params[len(params)-1]*10 + int(ch-'0')
+		}
+	}
+	return params
+}
+
+// LegacyConsoleColors enables a fallback for Windows consoles where
+// EnableVirtualTerminal fails (pre-Windows 10 conhost): ANSI colors written to
+// os.Stdout/os.Stderr are translated into console text attributes, limited
+// to 16 colors. It has no effect on other platforms.
+func (m *IOManager) LegacyConsoleColors() *IOManager { m.legacyColors = true; return m }
+
+// LegacyConsoleActive reports whether the legacy console color fallback is in use
+func (m *IOManager) LegacyConsoleActive() bool { return m.legacyActive }
+
+// enableLegacyConsole wraps the process stdout/stderr writers with ANSI
+// translators; writers set via WithOut/WithErr are left untouched
+func (m *IOManager) enableLegacyConsole() bool {
+	if !m.legacyColors {
+		return false
+	}
+	if m.legacyActive {
+		return true
+	}
+	if f, ok := m.out.(*os.File); ok && f == os.Stdout {
+		if w, ok := m.p.legacyConsole(f); ok {
+			m.out = w
+			m.legacyActive = true
+		}
+	}
+	if f, ok := m.err.(*os.File); ok && f == os.Stderr {
+		if w, ok := m.p.legacyConsole(f); ok {
+			m.err = w
+			m.legacyActive = true
+		}
+	}
+	return m.legacyActive
+}
@@ -0,0 +1,150 @@
+package snapio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WithRotation rotates a log file added with WithFile once a write would
+// grow it beyond maxSize bytes: app.log becomes app.log.1, app.log.1 becomes
+// app.log.2 and so on, keeping at most maxBackups old files (0 keeps none).
+// It has no effect on AddSink writers.
+func WithRotation(maxSize int64, maxBackups int) SinkOption {
+	return func(s *logSink) {
+		s.maxSize = max(maxSize, 0)
+		s.maxBackups = max(maxBackups, 0)
+	}
+}
+
+// WithFile tees every message to the log file at path, like AddSink:
+//
+//	app.Logger().WithFile("~/.myapp/logs/myapp.log", snapio.WithRotation(10<<20, 3))
+//
+// A leading ~ is expanded to the home directory and missing directories are
+// created. The file is opened on the first message and closed by Close.
+func (l *Logger) WithFile(path string, opts ...SinkOption) *Logger {
+	f := &logFile{path: path}
+	s := l.addSink(f, opts)
+	f.maxSize, f.maxBackups = s.maxSize, s.maxBackups
+	return l
+}
+
+// logFile is an appending, optionally rotating file writer; its sink
+// serializes writes
+type logFile struct {
+	path       string
+	maxSize    int64 // Rotate beyond this size (0: never)
+	maxBackups int
+	file       *os.File
+	size       int64
+	failed     bool // Opening failed; do not retry on every message
+}
+
+// Write appends p to the file, rotating it first when it would grow beyond
+// maxSize
+func (f *logFile) Write(p []byte) (int, error) {
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// open opens the file for appending, creating it and its directory
+func (f *logFile) open() error {
+	if f.failed {
+		return errors.New("log file " + f.path + " is unavailable")
+	}
+	path, err := expandHome(f.path)
+	if err == nil {
+		f.path = path
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+	}
+	var file *os.File
+	if err == nil {
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	}
+	if err != nil {
+		f.failed = true
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		f.failed = true
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts path.N-1 to path.N down to path to path.1, dropping the
+// oldest backup, and reopens an empty file
+func (f *logFile) rotate() error {
+	errs := []error{f.file.Close()}
+	f.file = nil
+	if f.maxBackups == 0 {
+		errs = append(errs, removeIfExists(f.path))
+	} else {
+		errs = append(errs, removeIfExists(f.backup(f.maxBackups)))
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+		errs = append(errs, os.Rename(f.path, f.backup(1)))
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		f.failed = true
+		return errors.Join(append(errs, err)...)
+	}
+	f.file, f.size = file, 0
+	return errors.Join(errs...)
+}
+
+// backup returns the path of the i-th rotated file
+func (f *logFile) backup(i int) string {
+	return f.path + "." + strconv.Itoa(i)
+}
+
+// Close closes the file; the next Write reopens it
+func (f *logFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// removeIfExists removes path, ignoring a missing file
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ in path with the home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
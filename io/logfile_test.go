@@ -0,0 +1,204 @@
+//nolint:testpackage // using package name 'snapio' to access unexported fields for testing
+package snapio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLogger_WithFile(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	rec := NewRecorder()
+	logger := NewLogger(rec.IO()).WithFormat(LogFormatTagged).WithFile(path)
+	logger.Info("ready")
+	logger.Error("failed")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The terminal keeps its colors and has no timestamp
+	if got := rec.Plain(); got != "[INFO] ready\n[ERROR] failed\n" {
+		t.Fatalf("terminal output = %q", got)
+	}
+	if !strings.Contains(rec.String(), "\x1b[") {
+		t.Fatalf("terminal output lost its colors: %q", rec.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\]`
+	want := regexp.MustCompile(`^\[INFO\] ` + line + ` ready\n\[ERROR\] ` + line + ` failed\n$`)
+	if !want.Match(data) {
+		t.Fatalf("log file = %q", data)
+	}
+}
+
+func TestLogger_WithTimestampPerSink(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecorder()
+	logger := NewLogger(rec.IO().NoColor()).WithFormat(LogFormatPlain).
+		WithTimestamp(true).WithTimeFormat("15:04").
+		WithFile(filepath.Join(dir, "plain.log"), WithTimestamp(false)).
+		WithFile(filepath.Join(dir, "stamped.log"), WithTimeFormat("2006"))
+	logger.Info("hello")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !regexp.MustCompile(`^\[\d{2}:\d{2}\] hello\n$`).MatchString(rec.String()) {
+		t.Fatalf("terminal output = %q", rec.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "plain.log")); string(data) != "hello\n" {
+		t.Fatalf("plain.log = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "stamped.log")); !regexp.MustCompile(`^\[\d{4}\] hello\n$`).Match(data) {
+		t.Fatalf("stamped.log = %q", data)
+	}
+}
+
+func TestLogger_WithFileKeepsUnicodePrefixes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := NewLogger(NewRecorder().IO().NoUnicode()).WithFile(path, WithTimestamp(false))
+	logger.Success("done")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "🟢 done\n" {
+		t.Fatalf("log file = %q", data)
+	}
+}
+
+func TestLogger_WithFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := NewLogger(NewRecorder().IO()).WithFormat(LogFormatPlain).
+		WithFile(path, WithTimestamp(false), WithRotation(10, 2))
+	for i := range 5 {
+		logger.Info("line %d", i) // 7 bytes each: one line per file
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		path:        "line 4\n",
+		path + ".1": "line 3\n",
+		path + ".2": "line 2\n",
+	} {
+		if data, err := os.ReadFile(name); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", filepath.Base(name), data, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("app.log.3 exists beyond maxBackups: %v", err)
+	}
+}
+
+func TestLogger_WithFileRotationNoBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := NewLogger(NewRecorder().IO()).WithFormat(LogFormatPlain).
+		WithFile(path, WithTimestamp(false), WithRotation(10, 0))
+	logger.Info("first")
+	logger.Info("second")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second\n" {
+		t.Fatalf("log file = %q", data)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Fatalf("backup kept with maxBackups 0: %v", err)
+	}
+}
+
+func TestLogger_WithFileAppendsAndReportsErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(NewRecorder().IO()).WithFormat(LogFormatPlain).
+		WithFile(path, WithTimestamp(false))
+	logger.Info("new")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old\nnew\n" {
+		t.Fatalf("log file = %q", data)
+	}
+
+	// A directory cannot be opened as a log file; the terminal still gets the message
+	rec := NewRecorder()
+	broken := NewLogger(rec.IO()).WithFormat(LogFormatPlain).WithFile(dir)
+	broken.Info("still printed")
+	if rec.Plain() != "still printed\n" {
+		t.Fatalf("terminal output = %q", rec.Plain())
+	}
+	if err := broken.Close(); err == nil {
+		t.Fatal("Close did not report the open error")
+	}
+}
+
+func TestLogger_WithFileExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	logger := NewLogger(NewRecorder().IO()).WithFile("~/.myapp/logs/myapp.log")
+	logger.Info("hi")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".myapp", "logs", "myapp.log")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogger_WithFileConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := NewLogger(NewRecorder().IO()).WithFormat(LogFormatPlain).
+		WithFile(path, WithTimestamp(false), WithRotation(1<<20, 1))
+	clone := logger.Clone(nil)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := logger
+			if g%2 == 1 {
+				l = clone
+			}
+			for i := range 50 {
+				l.Info("worker %d message %d", g, i)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 400 {
+		t.Fatalf("got %d lines, want 400", len(lines))
+	}
+	for _, line := range lines {
+		var g, i int
+		if _, err := fmt.Sscanf(line, "worker %d message %d", &g, &i); err != nil {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
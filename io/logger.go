@@ -1,8 +1,12 @@
 package snapio
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 )
@@ -45,6 +49,7 @@ const (
 	LogFormatTagged                   // [INFO] [SUCCESS] [WARN] [ERROR] [DEBUG]
 	LogFormatPlain                    // No prefix
 	LogFormatCustom                   // User-defined template
+	LogFormatJSON                     // {"time":"...","level":"INFO","msg":"..."} per line
 )
 
 // Logger provides structured logging with semantic levels and customizable formatting
@@ -53,10 +58,14 @@ type Logger struct {
 	format       LogFormat
 	template     string
 	prefixes     map[LogLevel]string
+	custom       map[LogLevel]bool // Levels whose prefix was set with SetPrefix
 	withTime     bool
 	timeFormat   string
 	errorsStderr bool
+	allStderr    bool
 	theme        Theme
+	level        LogLevel   // Messages below are not printed to the terminal
+	sinks        []*logSink // Added with AddSink and WithFile
 }
 
 // NewLogger creates a new logger bound to the given IOManager
@@ -114,19 +123,28 @@ func defaultTaggedPrefixes() map[LogLevel]string {
 // WithFormat sets the log format and returns the logger for chaining
 func (l *Logger) WithFormat(format LogFormat) *Logger {
 	l.format = format
+	l.custom = nil
+	if prefixes, ok := formatPrefixes(format); ok {
+		l.prefixes = prefixes
+	}
+	return l
+}
+
+// formatPrefixes returns the default prefixes of format; custom templates
+// keep the current prefixes, which may be customized separately
+func formatPrefixes(format LogFormat) (map[LogLevel]string, bool) {
 	switch format {
 	case LogFormatCircles:
-		l.prefixes = defaultCirclePrefixes()
+		return defaultCirclePrefixes(), true
 	case LogFormatSymbols:
-		l.prefixes = defaultSymbolPrefixes()
+		return defaultSymbolPrefixes(), true
 	case LogFormatTagged:
-		l.prefixes = defaultTaggedPrefixes()
-	case LogFormatPlain:
-		l.prefixes = make(map[LogLevel]string)
+		return defaultTaggedPrefixes(), true
+	case LogFormatPlain, LogFormatJSON:
+		return make(map[LogLevel]string), true
 	case LogFormatCustom:
-		// Custom template will be used, prefixes may be customized separately
 	}
-	return l
+	return nil, false
 }
 
 // WithTemplate sets a custom template for LogFormatCustom
@@ -143,6 +161,10 @@ func (l *Logger) SetPrefix(level LogLevel, prefix string) *Logger {
 		l.prefixes = make(map[LogLevel]string)
 	}
 	l.prefixes[level] = prefix
+	if l.custom == nil {
+		l.custom = make(map[LogLevel]bool)
+	}
+	l.custom[level] = true
 	return l
 }
 
@@ -158,12 +180,43 @@ func (l *Logger) WithTimeFormat(format string) *Logger {
 	return l
 }
 
+// WithLevel hides messages below level on the terminal (LevelDebug, the
+// default, prints everything); sinks have their own level
+func (l *Logger) WithLevel(level LogLevel) *Logger {
+	l.level = level
+	return l
+}
+
+// Level returns the minimum level printed to the terminal
+func (l *Logger) Level() LogLevel { return l.level }
+
 // ErrorsToStderr controls whether errors and warnings go to stderr
 func (l *Logger) ErrorsToStderr(enabled bool) *Logger {
 	l.errorsStderr = enabled
 	return l
 }
 
+// AllToStderr sends every level to stderr, keeping stdout free for program
+// output (e.g. JSON).
+func (l *Logger) AllToStderr(enabled bool) *Logger {
+	l.allStderr = enabled
+	return l
+}
+
+// Clone returns a copy of the logger with the same format and prefixes that
+// writes through io (nil keeps the current IOManager). Sinks are shared;
+// sinks added to the copy are not added to the original.
+func (l *Logger) Clone(io *IOManager) *Logger {
+	c := *l
+	if io != nil {
+		c.io = io
+	}
+	c.prefixes = maps.Clone(l.prefixes)
+	c.custom = maps.Clone(l.custom)
+	c.sinks = slices.Clip(l.sinks)
+	return &c
+}
+
 // WithTheme sets a custom theme for semantic colors
 func (l *Logger) WithTheme(theme Theme) *Logger {
 	l.theme = theme
@@ -173,27 +226,55 @@ func (l *Logger) WithTheme(theme Theme) *Logger {
 // Log outputs a log message at the specified level
 func (l *Logger) Log(level LogLevel, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	output := l.formatMessage(level, msg)
+	now := time.Now()
+	if level >= l.level {
+		if output, ok := l.formatMessage(level, msg, l.terminalStyle(now)); ok {
+			writer := l.selectWriter(level)
+			fmt.Fprintln(writer, output)
+		}
+	}
 
-	writer := l.selectWriter(level)
-	fmt.Fprintln(writer, output)
+	for _, s := range l.sinks {
+		s.log(l, level, msg, now)
+	}
 }
 
-// formatMessage formats the log message according to the configured format
-func (l *Logger) formatMessage(level LogLevel, msg string) string {
-	if l.format == LogFormatCustom && l.template != "" {
-		return l.formatCustomTemplate(level, msg)
+// logStyle is how one sink renders messages: the terminal or a log file
+type logStyle struct {
+	withTime   bool
+	timeFormat string
+	now        time.Time
+	plain      bool // No colors and no prefix fallbacks, for files
+}
+
+// terminalStyle returns the style of messages printed to the terminal
+func (l *Logger) terminalStyle(now time.Time) logStyle {
+	return logStyle{withTime: l.withTime, timeFormat: l.timeFormat, now: now}
+}
+
+// formatMessage formats the log message according to the configured format;
+// it reports false when there is nothing to print (blank JSON messages)
+func (l *Logger) formatMessage(level LogLevel, msg string, style logStyle) (string, bool) {
+	switch {
+	case l.format == LogFormatJSON:
+		return formatJSON(level, msg, style)
+	case l.format == LogFormatCustom && l.template != "":
+		return l.formatCustomTemplate(level, msg, style), true
 	}
+	return l.formatText(level, msg, style), true
+}
 
+// formatText formats the message with a prefix and optional timestamp
+func (l *Logger) formatText(level LogLevel, msg string, style logStyle) string {
 	// Check if message is empty or only whitespace
 	trimmedMsg := strings.TrimSpace(msg)
 	isEmpty := len(trimmedMsg) == 0
 
-	prefix := l.prefixes[level]
+	prefix := l.prefix(level, style)
 	timeStr := ""
 
-	if l.withTime {
-		timeStr = " [" + time.Now().Format(l.timeFormat) + "]"
+	if style.withTime {
+		timeStr = " [" + style.now.Format(style.timeFormat) + "]"
 	}
 
 	// Build the formatted message
@@ -206,12 +287,12 @@ func (l *Logger) formatMessage(level LogLevel, msg string) string {
 
 	// For plain format, no prefix but still apply color
 	if l.format == LogFormatPlain {
-		if l.withTime {
+		if style.withTime {
 			formatted = timeStr[1:] + " " + msg // Remove leading space from timeStr
 		} else {
 			formatted = msg
 		}
-		return l.colorizeByLevel(level, formatted)
+		return l.colorizeByLevel(level, formatted, style)
 	}
 
 	// Build formatted message with prefix
@@ -222,52 +303,93 @@ func (l *Logger) formatMessage(level LogLevel, msg string) string {
 	}
 
 	// Apply semantic color based on level
-	return l.colorizeByLevel(level, formatted)
+	return l.colorizeByLevel(level, formatted, style)
 }
 
 // formatCustomTemplate formats using a custom template
-func (l *Logger) formatCustomTemplate(level LogLevel, msg string) string {
+func (l *Logger) formatCustomTemplate(level LogLevel, msg string, style logStyle) string {
 	output := l.template
 	output = strings.ReplaceAll(output, "{{.Level}}", level.String())
 	output = strings.ReplaceAll(output, "{{.Message}}", msg)
-	output = strings.ReplaceAll(output, "{{.Prefix}}", l.prefixes[level])
+	output = strings.ReplaceAll(output, "{{.Prefix}}", l.prefix(level, style))
 
 	if strings.Contains(output, "{{.Time}}") {
-		output = strings.ReplaceAll(output, "{{.Time}}", time.Now().Format(l.timeFormat))
+		output = strings.ReplaceAll(output, "{{.Time}}", style.now.Format(style.timeFormat))
 	}
 
-	return l.colorizeByLevel(level, output)
+	return l.colorizeByLevel(level, output, style)
+}
+
+// jsonLine is one message in LogFormatJSON
+type jsonLine struct {
+	Time  string `json:"time,omitempty"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// formatJSON formats the message as a JSON object; blank messages, which
+// only space out terminal output, are dropped
+func formatJSON(level LogLevel, msg string, style logStyle) (string, bool) {
+	if strings.TrimSpace(msg) == "" {
+		return "", false
+	}
+	line := jsonLine{Level: level.String(), Msg: msg}
+	if style.withTime {
+		line.Time = style.now.Format(style.timeFormat)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(line); err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
+
+// prefix returns the prefix for level; the built-in emoji and symbol prefixes
+// degrade to ASCII tags when the terminal cannot render them
+func (l *Logger) prefix(level LogLevel, style logStyle) string {
+	if (l.format == LogFormatCircles || l.format == LogFormatSymbols) &&
+		!l.custom[level] && !style.plain && !l.io.SupportsUnicode() {
+		return "[" + level.String() + "]"
+	}
+	return l.prefixes[level]
 }
 
 // colorizeByLevel applies semantic color based on log level
-func (l *Logger) colorizeByLevel(level LogLevel, text string) string {
-	if !l.io.SupportsColor() {
+func (l *Logger) colorizeByLevel(level LogLevel, text string, style logStyle) string {
+	if style.plain || !l.io.SupportsColor() {
 		return text
 	}
 
 	var color ColorSpec
 	switch level {
+	case LevelSuccess:
+		if l.theme.SuccessStyle != nil {
+			return l.theme.SuccessStyle.Sprint(l.io, text)
+		}
+		color = l.theme.Success
+	case LevelError:
+		if l.theme.ErrorStyle != nil {
+			return l.theme.ErrorStyle.Sprint(l.io, text)
+		}
+		color = l.theme.Error
 	case LevelDebug:
 		color = l.theme.Debug
 	case LevelInfo:
 		color = l.theme.Info
-	case LevelSuccess:
-		color = l.theme.Success
 	case LevelWarning:
 		color = l.theme.Warning
-	case LevelError:
-		color = l.theme.Error
 	default:
 		return text
 	}
 
-	style := NewStyle().Fg(color)
-	return style.Sprint(l.io, text)
+	return NewStyle().Fg(color).Sprint(l.io, text)
 }
 
 // selectWriter chooses stdout or stderr based on log level and configuration
 func (l *Logger) selectWriter(level LogLevel) io.Writer {
-	if l.errorsStderr && (level == LevelError || level == LevelWarning) {
+	if l.allStderr || (l.errorsStderr && (level == LevelError || level == LevelWarning)) {
 		return l.io.Err()
 	}
 	return l.io.Out()
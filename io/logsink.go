@@ -0,0 +1,154 @@
+package snapio
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// defaultSinkTimeFormat is the timestamp of sink lines; unlike the
+// terminal, a log file or collector outlives the day it was written
+const defaultSinkTimeFormat = "2006-01-02 15:04:05"
+
+// SinkOption configures a sink added with Logger.AddSink or Logger.WithFile
+type SinkOption func(*logSink)
+
+// WithFormat sets the format of the sink's lines (the logger's format by
+// default). LogFormatCustom uses the logger's template unless WithTemplate
+// is given too.
+func WithFormat(format LogFormat) SinkOption {
+	return func(s *logSink) {
+		s.format = format
+		s.ownFormat = true
+	}
+}
+
+// WithTemplate sets the template of the sink's lines, like Logger.WithTemplate
+func WithTemplate(template string) SinkOption {
+	return func(s *logSink) {
+		s.format = LogFormatCustom
+		s.template = template
+		s.ownFormat = true
+	}
+}
+
+// WithLevel drops messages below level from the sink (LevelDebug, the
+// default, keeps everything)
+func WithLevel(level LogLevel) SinkOption {
+	return func(s *logSink) { s.level = level }
+}
+
+// WithTimestamp enables or disables the timestamp of the sink's lines
+// (enabled by default, independently of the terminal's timestamp)
+func WithTimestamp(enabled bool) SinkOption {
+	return func(s *logSink) { s.withTime = enabled }
+}
+
+// WithTimeFormat sets the timestamp format of the sink's lines (Go time
+// format string, "2006-01-02 15:04:05" by default)
+func WithTimeFormat(format string) SinkOption {
+	return func(s *logSink) { s.timeFormat = format }
+}
+
+// AddSink sends every message to w as well as to the terminal, each sink
+// with its own format, level and timestamp:
+//
+//	app.Logger().WithLevel(snapio.LevelInfo).
+//		AddSink(collector, snapio.WithFormat(snapio.LogFormatJSON), snapio.WithLevel(snapio.LevelDebug))
+//
+// Sink lines are plain text (no colors); Unicode prefixes are kept. Each
+// line is a single Write, serialized across goroutines and clones of the
+// logger, which share their sinks. Write errors never interrupt logging to
+// the terminal; Close reports them. w is not closed by Close.
+func (l *Logger) AddSink(w io.Writer, opts ...SinkOption) *Logger {
+	l.addSink(w, opts)
+	return l
+}
+
+// addSink registers a sink writing to w
+func (l *Logger) addSink(w io.Writer, opts []SinkOption) *logSink {
+	s := &logSink{w: w, withTime: true, timeFormat: defaultSinkTimeFormat}
+	for _, opt := range opts {
+		opt(s)
+	}
+	l.sinks = append(l.sinks, s)
+	return s
+}
+
+// Close closes the log files added with WithFile and returns the errors
+// encountered opening, writing or rotating files and writing to sinks
+func (l *Logger) Close() error {
+	errs := make([]error, 0, len(l.sinks))
+	for _, s := range l.sinks {
+		errs = append(errs, s.close())
+	}
+	return errors.Join(errs...)
+}
+
+// logSink is an additional destination of log messages
+type logSink struct {
+	mu         sync.Mutex
+	w          io.Writer
+	level      LogLevel
+	format     LogFormat
+	ownFormat  bool // Set by WithFormat or WithTemplate
+	template   string
+	withTime   bool
+	timeFormat string
+	maxSize    int64 // WithRotation, for files
+	maxBackups int
+	err        error // First error, reported by Close
+}
+
+// log formats and writes one message to the sink
+func (s *logSink) log(l *Logger, level LogLevel, msg string, now time.Time) {
+	if level < s.level {
+		return
+	}
+	formatter := l
+	if s.ownFormat {
+		formatter = l.withSinkFormat(s)
+	}
+	line, ok := formatter.formatMessage(level, msg, logStyle{
+		withTime:   s.withTime,
+		timeFormat: s.timeFormat,
+		now:        now,
+		plain:      true,
+	})
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := io.WriteString(s.w, line+"\n"); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// withSinkFormat returns a copy of l rendering the sink's own format
+func (l *Logger) withSinkFormat(s *logSink) *Logger {
+	c := *l
+	c.format = s.format
+	c.custom = nil
+	if prefixes, ok := formatPrefixes(s.format); ok {
+		c.prefixes = prefixes
+	}
+	if s.template != "" {
+		c.template = s.template
+	}
+	return &c
+}
+
+// close closes a file sink and returns the first error of the sink
+func (s *logSink) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	if f, ok := s.w.(*logFile); ok {
+		err = errors.Join(err, f.Close())
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+//nolint:testpackage // using package name 'snapio' to access unexported fields for testing
+package snapio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLogger_AddSinkFormatsAndLevels(t *testing.T) {
+	rec := NewRecorder()
+	var jsonOut, tagged bytes.Buffer
+	logger := NewLogger(rec.IO().NoColor()).ErrorsToStderr(false).WithLevel(LevelInfo).
+		AddSink(&jsonOut, WithFormat(LogFormatJSON), WithLevel(LevelDebug), WithTimestamp(false)).
+		AddSink(&tagged, WithFormat(LogFormatTagged), WithLevel(LevelWarning), WithTimestamp(false))
+
+	logger.Debug("probing")
+	logger.Info("ready")
+	logger.Info("")
+	logger.Warning("%s", `disk <90%> "full"`)
+
+	if got := rec.String(); got != "🔵 ready\n\n🟡 disk <90%> \"full\"\n" {
+		t.Fatalf("terminal output = %q", got)
+	}
+	wantJSON := `{"level":"DEBUG","msg":"probing"}` + "\n" +
+		`{"level":"INFO","msg":"ready"}` + "\n" +
+		`{"level":"WARN","msg":"disk <90%> \"full\""}` + "\n"
+	if jsonOut.String() != wantJSON {
+		t.Fatalf("json sink = %q", jsonOut.String())
+	}
+	if tagged.String() != "[WARN] disk <90%> \"full\"\n" {
+		t.Fatalf("tagged sink = %q", tagged.String())
+	}
+
+	// Changing the terminal format leaves sinks with their own format alone
+	logger.WithFormat(LogFormatPlain)
+	logger.Error("boom")
+	if !strings.HasSuffix(rec.String(), "\nboom\n") || !strings.HasSuffix(tagged.String(), "[ERROR] boom\n") {
+		t.Fatalf("terminal %q, tagged sink %q", rec.String(), tagged.String())
+	}
+}
+
+func TestLogger_AddSinkFollowsLoggerFormat(t *testing.T) {
+	var sink bytes.Buffer
+	logger := NewLogger(NewRecorder().IO()).WithFormat(LogFormatTagged).
+		AddSink(&sink, WithTimestamp(false))
+	logger.Info("one")
+	logger.WithTemplate("{{.Level}}: {{.Message}}")
+	logger.Info("two")
+	if sink.String() != "[INFO] one\nINFO: two\n" {
+		t.Fatalf("sink = %q", sink.String())
+	}
+
+	var custom bytes.Buffer
+	logger.AddSink(&custom, WithTemplate("{{.Message}} @ {{.Time}}"), WithTimeFormat("2006"))
+	logger.Info("three")
+	if !strings.HasPrefix(custom.String(), "three @ 2") {
+		t.Fatalf("template sink = %q", custom.String())
+	}
+}
+
+func TestLogger_AddSinkJSONTimestamp(t *testing.T) {
+	var sink bytes.Buffer
+	logger := NewLogger(NewRecorder().IO()).
+		AddSink(&sink, WithFormat(LogFormatJSON), WithTimeFormat("2006-01-02"))
+	logger.Success("done")
+
+	var line struct{ Time, Level, Msg string }
+	if err := json.Unmarshal(sink.Bytes(), &line); err != nil {
+		t.Fatalf("sink = %q: %v", sink.String(), err)
+	}
+	if len(line.Time) != len("2006-01-02") || line.Level != "SUCCESS" || line.Msg != "done" {
+		t.Fatalf("json line = %+v", line)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("collector down") }
+
+func TestLogger_AddSinkSharedByClonesAndErrors(t *testing.T) {
+	rec := NewRecorder()
+	var sink bytes.Buffer
+	logger := NewLogger(rec.IO()).WithFormat(LogFormatPlain).AddSink(&sink, WithTimestamp(false))
+	clone := logger.Clone(nil).AddSink(failingWriter{})
+	clone.Info("from clone")
+	logger.Info("from original")
+
+	if sink.String() != "from clone\nfrom original\n" {
+		t.Fatalf("shared sink = %q", sink.String())
+	}
+	if rec.Plain() != "from clone\nfrom original\n" {
+		t.Fatalf("terminal output = %q", rec.Plain())
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("original reported the clone's sink: %v", err)
+	}
+	if err := clone.Close(); err == nil || !strings.Contains(err.Error(), "collector down") {
+		t.Fatalf("Close = %v", err)
+	}
+}
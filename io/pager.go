@@ -0,0 +1,85 @@
+package snapio
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Pager buffers output and, on Close, shows it through $PAGER (less by
+// default) when it would not fit on screen. Output that fits, and any output
+// that is not going to an interactive terminal, is written to Out unchanged.
+type Pager struct {
+	m   *IOManager
+	buf bytes.Buffer
+}
+
+// Pager returns a paging writer; always Close it to flush the output:
+//
+//	w := io.Pager()
+//	defer w.Close()
+//	for _, item := range items {
+//		fmt.Fprintln(w, item)
+//	}
+func (m *IOManager) Pager() *Pager { return &Pager{m: m} }
+
+// Write buffers p until Close
+func (p *Pager) Write(b []byte) (int, error) { return p.buf.Write(b) }
+
+// Close pages the buffered output, falling back to writing it to Out when
+// paging is not needed or the pager cannot be started.
+func (p *Pager) Close() error {
+	data := p.buf.Bytes()
+	if p.shouldPage(data) && runPager(data) {
+		return nil
+	}
+	_, err := p.m.Out().Write(data)
+	return err
+}
+
+// shouldPage reports whether data overflows an interactive stdout terminal
+func (p *Pager) shouldPage(data []byte) bool {
+	if f, ok := p.m.Out().(*os.File); !ok || f != os.Stdout {
+		return false
+	}
+	if !p.m.IsTTY() || !p.m.p.isTerminal(os.Stdin) || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return bytes.Count(data, []byte{'\n'}) >= p.m.Height()
+}
+
+// runPager feeds data to the pager command, reporting whether it started.
+// The pager's exit status is not an error: the user saw the output.
+func runPager(data []byte) bool {
+	args := pagerCommand()
+	if len(args) == 0 {
+		return false
+	}
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // the pager is chosen by the user via $PAGER
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// Quit if one screen, pass colors through, keep output on screen
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	_ = cmd.Wait()
+	return true
+}
+
+// pagerCommand returns $PAGER split into arguments, or less/more when unset
+func pagerCommand() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	for _, name := range []string{"less", "more"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return []string{path}
+		}
+	}
+	return nil
+}
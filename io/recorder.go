@@ -0,0 +1,206 @@
+package snapio
+
+import (
+	"bytes"
+	stdio "io"
+	"strings"
+	"sync"
+)
+
+// Recorder captures stdout and stderr output, including ANSI styling, so
+// tests can compare logger and help output against golden files:
+//
+//	rec := snapio.NewRecorder()
+//	snapio.NewLogger(rec.IO()).Info("ready")
+//	golden(t, "info.txt", snapio.NormalizeANSI(rec.String()))
+//
+// It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	chunks []recordedChunk
+}
+
+// recordedChunk is one write, in order
+type recordedChunk struct {
+	stderr bool
+	data   []byte
+}
+
+// recorderStream writes to one stream of a Recorder
+type recorderStream struct {
+	r      *Recorder
+	stderr bool
+}
+
+func (s recorderStream) Write(p []byte) (int, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	n := len(s.r.chunks)
+	if n > 0 && s.r.chunks[n-1].stderr == s.stderr {
+		s.r.chunks[n-1].data = append(s.r.chunks[n-1].data, p...)
+	} else {
+		s.r.chunks = append(s.r.chunks, recordedChunk{stderr: s.stderr, data: bytes.Clone(p)})
+	}
+	return len(p), nil
+}
+
+// NewRecorder returns an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Write records p as stdout output
+func (r *Recorder) Write(p []byte) (int, error) { return r.Out().Write(p) }
+
+// Out returns a writer recording stdout output
+func (r *Recorder) Out() stdio.Writer { return recorderStream{r: r} }
+
+// Err returns a writer recording stderr output
+func (r *Recorder) Err() stdio.Writer { return recorderStream{r: r, stderr: true} }
+
+// IO returns a manager writing to the recorder with deterministic styling:
+// truecolor and Unicode are forced on (chain NoColor or NoUnicode to test
+// the fallbacks) and input is empty. NO_COLOR in the environment still
+// disables color, so golden tests should clear it with t.Setenv.
+func (r *Recorder) IO() *IOManager {
+	return New().WithIn(strings.NewReader("")).WithOut(r.Out()).WithErr(r.Err()).
+		ForceColor().ForceColorLevel(3).ForceUnicode()
+}
+
+// String returns stdout and stderr output interleaved in write order, with ANSI codes
+func (r *Recorder) String() string { return r.collect(func(recordedChunk) bool { return true }) }
+
+// Stdout returns the recorded stdout output, with ANSI codes
+func (r *Recorder) Stdout() string { return r.collect(func(c recordedChunk) bool { return !c.stderr }) }
+
+// Stderr returns the recorded stderr output, with ANSI codes
+func (r *Recorder) Stderr() string { return r.collect(func(c recordedChunk) bool { return c.stderr }) }
+
+// Plain returns String without ANSI codes
+func (r *Recorder) Plain() string { return StripANSI(r.String()) }
+
+// Reset discards the recorded output
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chunks = nil
+}
+
+// Replay writes the recorded output to out and errOut in the original order
+func (r *Recorder) Replay(out, errOut stdio.Writer) error {
+	r.mu.Lock()
+	chunks := make([]recordedChunk, len(r.chunks))
+	copy(chunks, r.chunks)
+	r.mu.Unlock()
+	for _, c := range chunks {
+		w := out
+		if c.stderr {
+			w = errOut
+		}
+		if _, err := w.Write(c.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect concatenates the chunks selected by keep
+func (r *Recorder) collect(keep func(recordedChunk) bool) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b strings.Builder
+	for _, c := range r.chunks {
+		if keep(c) {
+			b.Write(c.data)
+		}
+	}
+	return b.String()
+}
+
+// StripANSI removes ANSI escape sequences (colors, cursor movement, OSC 8
+// hyperlinks) from s, leaving the visible text
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			i = escapeEnd(s, i)
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// NormalizeANSI makes escape sequences in s readable and stable for golden
+// files: SGR codes become <1;31> (a reset is always <0>), OSC 8 hyperlinks
+// become <link url> and </link>, other sequences <esc ...>; CRLF becomes LF
+func NormalizeANSI(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != 0x1b {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := escapeEnd(s, i)
+		b.WriteString(describeEscape(s[i:end]))
+		i = end
+	}
+	return b.String()
+}
+
+// escapeEnd returns the index just past the escape sequence starting at s[i]
+func escapeEnd(s string, i int) int {
+	if i+1 >= len(s) {
+		return len(s)
+	}
+	switch s[i+1] {
+	case '[': // CSI: parameters then a final byte in 0x40-0x7e
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return len(s)
+	case ']': // OSC: terminated by BEL or ST (ESC \)
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	default: // two-byte escape
+		return i + 2
+	}
+}
+
+// describeEscape renders one escape sequence for NormalizeANSI
+func describeEscape(seq string) string {
+	switch {
+	case strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m"):
+		params := seq[2 : len(seq)-1]
+		if params == "" || params == "00" {
+			params = "0"
+		}
+		return "<" + params + ">"
+	case strings.HasPrefix(seq, "\x1b]8;"):
+		body := strings.TrimSuffix(strings.TrimSuffix(seq[len("\x1b]8;"):], "\x07"), "\x1b\\")
+		if _, url, _ := strings.Cut(body, ";"); url != "" {
+			return "<link " + url + ">"
+		}
+		return "</link>"
+	default:
+		return "<esc " + strings.TrimPrefix(seq, "\x1b") + ">"
+	}
+}
@@ -0,0 +1,80 @@
+//nolint:testpackage // using package name 'snapio' to access unexported fields for testing
+package snapio
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecorder_LoggerFormats(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	rec := NewRecorder()
+	logger := NewLogger(rec.IO()).WithFormat(LogFormatTagged)
+	logger.Info("ready")
+	logger.Error("failed")
+	logger.Success("done")
+
+	golden := "<38;2;139;233;253>[INFO] ready<0>\n" +
+		"<1;38;2;255;85;85>[ERROR] failed<0>\n" +
+		"<38;2;80;250;123>[SUCCESS] done<0>\n"
+	if got := NormalizeANSI(rec.String()); got != golden {
+		t.Fatalf("normalized output:\n%s\nwant:\n%s", got, golden)
+	}
+	if got := rec.Plain(); got != "[INFO] ready\n[ERROR] failed\n[SUCCESS] done\n" {
+		t.Fatalf("plain output = %q", got)
+	}
+	if !strings.Contains(rec.Stderr(), "failed") || strings.Contains(rec.Stdout(), "failed") {
+		t.Fatalf("streams: stdout %q, stderr %q", rec.Stdout(), rec.Stderr())
+	}
+
+	var out, errOut bytes.Buffer
+	if err := rec.Replay(&out, &errOut); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != rec.Stdout() || errOut.String() != rec.Stderr() {
+		t.Fatalf("replay: %q / %q", out.String(), errOut.String())
+	}
+
+	rec.Reset()
+	NewLogger(rec.IO().NoColor()).WithFormat(LogFormatTagged).Info("ready")
+	if got := rec.String(); got != "[INFO] ready\n" {
+		t.Fatalf("no-color output = %q", got)
+	}
+}
+
+func TestRecorder_ANSIHelpers(t *testing.T) {
+	s := "\x1b[1;31mred\x1b[m \x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x1b\\ \x1b[2K\x1b7done\r\n"
+	if got := StripANSI(s); got != "red docs done\r\n" {
+		t.Fatalf("StripANSI = %q", got)
+	}
+	want := "<1;31>red<0> <link https://example.com>docs</link> <esc [2K><esc 7>done\n"
+	if got := NormalizeANSI(s); got != want {
+		t.Fatalf("NormalizeANSI = %q, want %q", got, want)
+	}
+	// Truncated sequences are dropped rather than leaking escape bytes
+	if got := StripANSI("ok\x1b[31"); got != "ok" {
+		t.Fatalf("StripANSI(truncated) = %q", got)
+	}
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+	rec := NewRecorder()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = rec.Out().Write([]byte("o"))
+				_, _ = rec.Err().Write([]byte("e"))
+			}
+		}()
+	}
+	wg.Wait()
+	if len(rec.Stdout()) != 800 || len(rec.Stderr()) != 800 || len(rec.String()) != 1600 {
+		t.Fatalf("lengths: %d / %d / %d", len(rec.Stdout()), len(rec.Stderr()), len(rec.String()))
+	}
+}
@@ -0,0 +1,62 @@
+package snapio
+
+import "sync"
+
+// OnResize calls fn with the new width and height whenever the terminal is
+// resized, so progress bars, tables and other live output can re-render. It
+// listens for SIGWINCH on Unix and polls the console size on Windows. fn runs
+// on its own goroutine and only when the size actually changed; call the
+// returned stop function to end the notifications:
+//
+//	stop := io.OnResize(func(w, h int) { bar.SetWidth(w) })
+//	defer stop()
+func (m *IOManager) OnResize(fn func(width, height int)) (stop func()) {
+	events, cancel := watchResize()
+	done := make(chan struct{})
+	width, height := m.Width(), m.Height()
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-events:
+				if w, h := m.Width(), m.Height(); w != width || h != height {
+					width, height = w, h
+					fn(w, h)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			close(done)
+		})
+	}
+}
+
+// forwardEvents turns values received from src into events until the
+// returned stop function runs cancel. Events coalesce while the receiver
+// is busy.
+func forwardEvents[T any](src <-chan T, cancel func()) (<-chan struct{}, func()) {
+	events := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-src:
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return events, func() {
+		cancel()
+		close(done)
+	}
+}
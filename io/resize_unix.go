@@ -0,0 +1,17 @@
+//go:build !windows
+
+package snapio
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchResize delivers an event for every SIGWINCH, which the terminal sends
+// on each resize
+func watchResize() (<-chan struct{}, func()) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+	return forwardEvents(sig, func() { signal.Stop(sig) })
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package snapio
+
+import "time"
+
+// resizePollInterval is how often the console size is checked; Windows only
+// reports resizes as console input events, which would compete with readers
+// of stdin
+const resizePollInterval = 250 * time.Millisecond
+
+// watchResize delivers an event every resizePollInterval so the caller
+// re-checks the size
+func watchResize() (<-chan struct{}, func()) {
+	ticker := time.NewTicker(resizePollInterval)
+	return forwardEvents(ticker.C, ticker.Stop)
+}
@@ -0,0 +1,200 @@
+package snapio
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// errRawUnsupported is returned by platforms without raw terminal support
+var errRawUnsupported = errors.New("raw terminal mode is not supported on this platform")
+
+// KeyCode identifies the kind of key read by Terminal.ReadKey
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota // Printable character in Key.Rune
+	KeyCtrl                // Ctrl combination; Key.Rune holds the lowercase letter ('c' for Ctrl+C)
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+	KeyUnknown // Unrecognized escape sequence
+)
+
+// Key is a single key press
+type Key struct {
+	Code KeyCode
+	Rune rune // For KeyRune and KeyCtrl
+	Alt  bool // Sent with an ESC prefix (Alt/Meta held)
+}
+
+var keyNames = map[KeyCode]string{
+	KeyEnter: "enter", KeyTab: "tab", KeyBackspace: "backspace", KeyEscape: "esc",
+	KeyUp: "up", KeyDown: "down", KeyLeft: "left", KeyRight: "right",
+	KeyHome: "home", KeyEnd: "end", KeyInsert: "insert", KeyDelete: "delete",
+	KeyPageUp: "pgup", KeyPageDown: "pgdown", KeyUnknown: "unknown",
+}
+
+// String names the key for matching: "a", "ctrl+c", "alt+x", "up", "enter"
+func (k Key) String() string {
+	var name string
+	switch k.Code {
+	case KeyRune:
+		name = string(k.Rune)
+	case KeyCtrl:
+		name = "ctrl+" + string(k.Rune)
+	default:
+		name = keyNames[k.Code]
+	}
+	if k.Alt {
+		return "alt+" + name
+	}
+	return name
+}
+
+// Terminal reads key presses and writes output while RawMode is active
+type Terminal struct {
+	m *IOManager
+	r *bufio.Reader
+}
+
+// RawMode switches the input terminal to raw mode (no echo, no line
+// buffering, Ctrl+C delivered as a key), runs fn and restores the previous
+// state, even if fn panics. When input is not a terminal (pipes, tests) fn
+// runs without switching modes and keys are decoded from the stream as-is.
+func (m *IOManager) RawMode(fn func(term *Terminal) error) error {
+	if f, ok := m.in.(*os.File); ok && m.p.isTerminal(f) {
+		restore, err := m.p.makeRaw(f)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = restore() }()
+	}
+	return fn(&Terminal{m: m, r: bufio.NewReader(m.in)})
+}
+
+// Write writes p to the output stream (implements io.Writer)
+func (t *Terminal) Write(p []byte) (int, error) { return t.m.Out().Write(p) }
+
+// ReadKey blocks until the next key press and decodes it
+func (t *Terminal) ReadKey() (Key, error) {
+	r, _, err := t.r.ReadRune()
+	if err != nil {
+		return Key{}, err
+	}
+	if r != 0x1b {
+		return controlKey(r), nil
+	}
+
+	// A lone ESC arrives on its own; sequences arrive in a single read
+	if t.r.Buffered() == 0 {
+		return Key{Code: KeyEscape}, nil
+	}
+	next, _, err := t.r.ReadRune()
+	if err != nil {
+		return Key{Code: KeyEscape}, nil //nolint:nilerr // ESC at end of input is still a key
+	}
+	switch next {
+	case '[':
+		return t.readCSI(), nil
+	case 'O':
+		if t.r.Buffered() > 0 {
+			b, _ := t.r.ReadByte()
+			return ss3Key(b), nil
+		}
+	}
+	key := controlKey(next)
+	key.Alt = true
+	return key, nil
+}
+
+// readCSI decodes "ESC [" sequences: arrows/Home/End ("ESC [ A") and
+// numbered keys ("ESC [ 3 ~"), ignoring modifier parameters
+func (t *Terminal) readCSI() Key {
+	var params strings.Builder
+	for t.r.Buffered() > 0 {
+		b, _ := t.r.ReadByte()
+		if b >= 0x40 && b <= 0x7e { // final byte
+			if b == '~' {
+				num, _, _ := strings.Cut(params.String(), ";")
+				return tildeKey(num)
+			}
+			return ss3Key(b)
+		}
+		params.WriteByte(b)
+	}
+	return Key{Code: KeyUnknown}
+}
+
+// ss3Key maps the final byte of arrow/Home/End sequences
+func ss3Key(b byte) Key {
+	switch b {
+	case 'A':
+		return Key{Code: KeyUp}
+	case 'B':
+		return Key{Code: KeyDown}
+	case 'C':
+		return Key{Code: KeyRight}
+	case 'D':
+		return Key{Code: KeyLeft}
+	case 'H':
+		return Key{Code: KeyHome}
+	case 'F':
+		return Key{Code: KeyEnd}
+	default:
+		return Key{Code: KeyUnknown}
+	}
+}
+
+// tildeKey maps "ESC [ n ~" sequences
+func tildeKey(num string) Key {
+	switch num {
+	case "1", "7":
+		return Key{Code: KeyHome}
+	case "2":
+		return Key{Code: KeyInsert}
+	case "3":
+		return Key{Code: KeyDelete}
+	case "4", "8":
+		return Key{Code: KeyEnd}
+	case "5":
+		return Key{Code: KeyPageUp}
+	case "6":
+		return Key{Code: KeyPageDown}
+	default:
+		return Key{Code: KeyUnknown}
+	}
+}
+
+// controlKey maps a non-escape rune to a key
+func controlKey(r rune) Key {
+	switch {
+	case r == '\r' || r == '\n':
+		return Key{Code: KeyEnter}
+	case r == '\t':
+		return Key{Code: KeyTab}
+	case r == 0x7f || r == 0x08:
+		return Key{Code: KeyBackspace}
+	case r == 0:
+		return Key{Code: KeyCtrl, Rune: ' '}
+	case r < 0x20:
+		return Key{Code: KeyCtrl, Rune: 'a' + r - 1}
+	case r == utf8.RuneError:
+		return Key{Code: KeyUnknown}
+	default:
+		return Key{Code: KeyRune, Rune: r}
+	}
+}
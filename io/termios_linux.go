@@ -0,0 +1,10 @@
+//go:build linux
+
+package snapio
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
@@ -0,0 +1,7 @@
+//go:build !windows && !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package snapio
+
+import "os"
+
+func makeRawTermios(*os.File) (func() error, error) { return nil, errRawUnsupported }
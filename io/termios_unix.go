@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package snapio
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// makeRawTermios puts f into raw input mode (like cfmakeraw, but keeping
+// output post-processing so "\n" still starts a new line) and returns a
+// function restoring the previous state
+func makeRawTermios(f *os.File) (func() error, error) {
+	fd := f.Fd()
+	var old syscall.Termios
+	if err := termiosIoctl(fd, ioctlGetTermios, &old); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := termiosIoctl(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+	return func() error { return termiosIoctl(fd, ioctlSetTermios, &old) }, nil
+}
+
+func termiosIoctl(fd, req uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
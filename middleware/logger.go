@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/dzonerzy/go-snap/internal/pool"
+	snapio "github.com/dzonerzy/go-snap/io"
 )
 
 // requestInfoPool is a global pool for RequestInfo objects to reduce allocations
@@ -150,7 +151,7 @@ func writeTextLog(writer io.Writer, info *RequestInfo, level string, config *Mid
 
 	if info.Duration > 0 {
 		*buf = append(*buf, " duration="...)
-		*buf = append(*buf, info.Duration.String()...)
+		*buf = append(*buf, snapio.FormatDuration(info.Duration)...)
 	}
 
 	if config.IncludeArgs && len(info.Args) > 0 {
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	snapio "github.com/dzonerzy/go-snap/io"
 )
 
 // Mock implementations for testing
@@ -880,3 +882,69 @@ func TestComprehensiveValidationExample(t *testing.T) {
 		t.Errorf("Expected no error with sufficient memory, got %v", err)
 	}
 }
+
+// stdinContext is a MockContext with an IO manager reading input
+type stdinContext struct {
+	*MockContext
+	io *snapio.IOManager
+}
+
+func (s *stdinContext) IO() *snapio.IOManager { return s.io }
+
+func TestStdinJSON(t *testing.T) {
+	type Manifest struct {
+		Name     string `json:"name" required:"true"`
+		Strategy string `json:"strategy" enum:"rolling,recreate"`
+		Spec     struct {
+			Replicas int `json:"replicas" required:"true"`
+		} `json:"spec"`
+	}
+	run := func(schema any, input string) (any, error) {
+		ctx := &stdinContext{NewMockContext(), snapio.New().WithIn(strings.NewReader(input))}
+		err := StdinJSON(schema)(successAction)(ctx)
+		return ctx.Get(StdinKey), err
+	}
+
+	value, err := run(Manifest{}, `{"name": "web", "strategy": "rolling", "spec": {"replicas": 3}}`)
+	m, ok := value.(*Manifest)
+	if err != nil || !ok || m.Name != "web" || m.Spec.Replicas != 3 {
+		t.Fatalf("got %#v, %v", value, err)
+	}
+	if value, err := run(&Manifest{}, "  \n"); err != nil || value != nil {
+		t.Fatalf("empty input: got %#v, %v", value, err)
+	}
+
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"name"},
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string"},
+			"ports": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+		},
+	}
+	value, err = run(schema, `{"name": "web", "ports": [80, 443]}`)
+	if doc, ok := value.(map[string]any); err != nil || !ok || doc["name"] != "web" {
+		t.Fatalf("got %#v, %v", value, err)
+	}
+
+	for _, tc := range []struct {
+		schema any
+		input  string
+		field  string
+	}{
+		{Manifest{}, `{"name": "web"`, "stdin"},
+		{Manifest{}, `{"name": "web", "spec": {"replicas": 1}, "extra": 1}`, "stdin"},
+		{Manifest{}, `{"spec": {"replicas": 1}}`, "stdin.name"},
+		{Manifest{}, `{"name": "web", "spec": {}}`, "stdin.spec.replicas"},
+		{Manifest{}, `{"name": "web", "strategy": "blue", "spec": {"replicas": 1}}`, "stdin.strategy"},
+		{schema, `{"ports": [80]}`, "stdin.name"},
+		{schema, `{"name": "web", "ports": [80, "443"]}`, "stdin.ports[1]"},
+		{schema, `[]`, "stdin"},
+	} {
+		_, err := run(tc.schema, tc.input)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != tc.field {
+			t.Errorf("%s: expected error on %s, got %v", tc.input, tc.field, err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	snapio "github.com/dzonerzy/go-snap/io"
+)
+
+// StdinKey is the context key under which StdinJSON stores the decoded input
+const StdinKey = "stdin"
+
+// StdinJSON creates a middleware that reads a JSON document piped on stdin,
+// validates it against schema and stores it in the context for the action.
+//
+// schema is either a struct (or pointer to one) describing the document or
+// a JSON Schema given as map[string]any:
+//
+//   - With a struct, unknown fields are rejected, `required:"true"` fields
+//     must be set and `enum:"a,b"` string fields must hold one of the values,
+//     as in configuration structs. ctx.Get("stdin") returns a pointer to a
+//     new value of the struct type.
+//   - With a JSON Schema, the keywords type, properties, required, items and
+//     enum are checked. ctx.Get("stdin") returns the decoded document.
+//
+// Nothing is stored when stdin is a terminal or the input is empty, so the
+// action can fall back to flags:
+//
+//	type Manifest struct {
+//		Name     string `json:"name" required:"true"`
+//		Replicas int    `json:"replicas"`
+//	}
+//
+//	app.Command("apply", "Apply a manifest").
+//		Use(middleware.StdinJSON(Manifest{})).
+//		Action(func(ctx *snap.Context) error {
+//			m, ok := ctx.Get("stdin").(*Manifest)
+//			...
+//		})
+func StdinJSON(schema any) Middleware {
+	return func(next ActionFunc) ActionFunc {
+		return func(ctx Context) error {
+			data, err := readPipedStdin(ctx)
+			if err != nil {
+				return &ValidationError{Field: StdinKey, Message: "cannot read stdin", Cause: err}
+			}
+			if len(bytes.TrimSpace(data)) == 0 {
+				return next(ctx)
+			}
+
+			value, err := decodeStdin(data, schema)
+			if err != nil {
+				validationErr := &ValidationError{}
+				if errors.As(err, &validationErr) {
+					return validationErr
+				}
+				return &ValidationError{Field: StdinKey, Message: "invalid JSON on stdin", Cause: err}
+			}
+			ctx.Set(StdinKey, value)
+			return next(ctx)
+		}
+	}
+}
+
+// readPipedStdin returns the input of the context's IO manager unless it is
+// an interactive terminal. A reader other than os.Stdin (e.g. set with
+// IOManager.WithIn) is always read.
+func readPipedStdin(ctx Context) ([]byte, error) {
+	in := io.Reader(os.Stdin)
+	piped := false
+	if c, ok := any(ctx).(interface{ IO() *snapio.IOManager }); ok && c.IO() != nil {
+		in = c.IO().In()
+		piped = in != os.Stdin || c.IO().IsPiped()
+	}
+	if !piped || in == nil {
+		return nil, nil
+	}
+	return io.ReadAll(in)
+}
+
+// decodeStdin unmarshals data into a value shaped by schema and validates it
+func decodeStdin(data []byte, schema any) (any, error) {
+	if jsonSchema, ok := schema.(map[string]any); ok {
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		if err := checkJSONSchema(doc, jsonSchema, StdinKey); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, errors.New("no schema given")
+	}
+	target := reflect.New(t)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target.Interface()); err != nil {
+		return nil, err
+	}
+	if err := checkStructTags(target.Elem(), StdinKey); err != nil {
+		return nil, err
+	}
+	return target.Interface(), nil
+}
+
+// checkStructTags enforces the required and enum tags of a decoded struct
+func checkStructTags(v reflect.Value, path string) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := path + "." + jsonFieldName(field)
+		value := v.Field(i)
+		if field.Tag.Get("required") == "true" && value.IsZero() {
+			return &ValidationError{Field: fieldPath, Message: "missing required field " + fieldPath}
+		}
+		if enum := field.Tag.Get("enum"); enum != "" && value.Kind() == reflect.String && !value.IsZero() &&
+			!slices.Contains(strings.Split(enum, ","), value.String()) {
+			return &ValidationError{
+				Field:   fieldPath,
+				Value:   value.String(),
+				Message: fmt.Sprintf("invalid value %q for %s, valid values: %s", value.String(), fieldPath, enum),
+			}
+		}
+		if err := checkStructTags(reflect.Indirect(value), fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonFieldName returns the name of a struct field in JSON documents
+func jsonFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// checkJSONSchema validates doc against the type, properties, required,
+// items and enum keywords of schema
+func checkJSONSchema(doc any, schema map[string]any, path string) error {
+	if want, ok := schema["type"].(string); ok && jsonType(doc, want) != want {
+		return &ValidationError{Field: path, Value: doc, Message: fmt.Sprintf("%s must be of type %s", path, want)}
+	}
+	isDoc := func(v any) bool { return reflect.DeepEqual(v, doc) }
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, isDoc) {
+		return &ValidationError{Field: path, Value: doc, Message: fmt.Sprintf("%s must be one of %v", path, enum)}
+	}
+
+	switch v := doc.(type) {
+	case map[string]any:
+		for _, name := range schemaStrings(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				fieldPath := path + "." + name
+				return &ValidationError{Field: fieldPath, Message: "missing required field " + fieldPath}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, value := range v {
+			if sub, ok := properties[name].(map[string]any); ok {
+				if err := checkJSONSchema(value, sub, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := checkJSONSchema(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON Schema type of a decoded value; numbers without
+// a fraction are reported as "integer" when want asks for one
+func jsonType(v any, want string) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if want == "integer" && v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return ""
+}
+
+// schemaStrings returns a JSON Schema string list such as "required"
+func schemaStrings(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
@@ -1,12 +1,18 @@
 package snap
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	snapio "github.com/dzonerzy/go-snap/io"
@@ -44,10 +50,14 @@ type App struct {
 	flagGroups  []*FlagGroup // Flag groups for validation
 	args        []*Arg       // Positional arguments (ordered by position)
 	hasRestArgs bool         // If true, collect all remaining args after declared args
+	restMode    restArgsMode // When flag parsing stops with RestArgs
 
 	// Global configuration
-	helpFlag    bool
-	versionFlag bool
+	helpFlag           bool
+	versionFlag        bool
+	allowAbbreviations bool // Resolve unambiguous prefixes of long flags and commands
+	strictGlobalFlags  bool // Reject global flags placed after the command name
+	allowArgumentFiles bool // Expand @file tokens into the file's arguments
 
 	// Execution context
 	action       ActionFunc // Default action when no command is matched
@@ -80,6 +90,106 @@ type App struct {
 
 	// Raw arguments as passed to RunWithArgs (before parsing)
 	rawArgs []string
+
+	// Help rendering: output is built into a pooled buffer and the app-level
+	// help is cached until the next registration or IO change
+	renderBuf    *bytes.Buffer
+	helpCache    []byte
+	helpCacheKey helpKey
+
+	// Free-form help topics shown by "myapp help TOPIC"
+	helpTopics map[string]string
+
+	// Page long help output through $PAGER (see HelpPager)
+	helpPager bool
+
+	// Run the close match of a mistyped command (see AutoCorrect)
+	autoCorrect AutoCorrectMode
+
+	// Replaces the app help output (see UsageFunc)
+	usageFunc func(*App, io.Writer) error
+
+	// Detailed version output (set by VersionInfo / VersionTemplate)
+	buildInfo       *BuildInfo
+	versionTemplate string
+
+	// Environment checks for the built-in doctor command (see Doctor)
+	doctor *DoctorBuilder
+
+	// Credential store for the built-in auth command (see Auth)
+	auth *AuthBuilder
+
+	// Name of the built-in command palette (see SearchCommand)
+	searchCommand string
+
+	// Built-in environment variable listing (see EnvCommand)
+	envCommand string
+	envCmd     *Command
+
+	// Usage reporting (see OnInvocation)
+	invocationHooks []InvocationHook
+	telemetryOptOut []string
+
+	// Styling for help, errors and logs (see SetTheme)
+	theme *snapio.Theme
+
+	// Message catalog (see SetLocale)
+	locale           string
+	translations     map[string]Messages
+	messageOverrides Messages
+
+	// Tee context output into per-run buffers (see CaptureOutput)
+	captureOutput bool
+
+	// Lifecycle trace on stderr (see Debug); tracer is set for the current run
+	debug  bool
+	tracer *tracer
+
+	// Arguments that may be replaced by a flag (see RequireArgsOrFlag)
+	argsOrFlag []argFlagRequirement
+
+	// Number of RestArgs accepted (see MinRestArgs / MaxRestArgs; 0: no limit)
+	restMin int
+	restMax int
+
+	// Lifecycle event handlers (see OnEvent), called one at a time
+	eventHandlers []func(Event)
+	eventMu       sync.Mutex
+
+	// Several commands in one invocation (see AllowChaining)
+	chainDelimiter string
+	chainPolicy    ChainPolicy
+
+	// Dark-launched commands and flags (see Experiments)
+	experiments *Experiments
+
+	// Output contract flags (see EnableQuietFlag, VerbosityFlags) and their values in the
+	// current run
+	quietFlag     bool
+	porcelainFlag bool
+	verbosityFlag bool
+	quiet         bool
+	porcelain     bool
+	verbosity     int
+
+	// Panic handling (see CrashPolicy)
+	crash *crashPolicy
+
+	// Backends for scheme:name credential references (see CredentialResolver)
+	credentialResolvers map[string]CredentialResolver
+
+	// Execution vetoes checked before every command runs (see Gate)
+	gates []GateFunc
+
+	// Registration ended (see Freeze) and a run in progress, for the
+	// registration-after-run panic
+	frozen  bool
+	running bool
+}
+
+// helpBufferPool recycles buffers used to render help output
+var helpBufferPool = sync.Pool{
+	New: func() any { return bytes.NewBuffer(make([]byte, 0, 1024)) },
 }
 
 // New creates a new CLI application with fluent API
@@ -105,6 +215,7 @@ func New(name, description string) *App {
 // Version sets the application version
 func (a *App) Version(version string) *App {
 	a.version = version
+	a.invalidateHelp()
 	a.versionFlag = true
 	return a
 }
@@ -112,18 +223,21 @@ func (a *App) Version(version string) *App {
 // Author adds an application author
 func (a *App) Author(name, email string) *App {
 	a.authors = append(a.authors, Author{Name: name, Email: email})
+	a.invalidateHelp()
 	return a
 }
 
 // Authors sets multiple application authors
 func (a *App) Authors(authors ...Author) *App {
 	a.authors = append(a.authors, authors...)
+	a.invalidateHelp()
 	return a
 }
 
 // HelpText sets detailed help text for the application
 func (a *App) HelpText(help string) *App {
 	a.helpText = help
+	a.invalidateHelp()
 	return a
 }
 
@@ -139,6 +253,23 @@ func (a *App) DisableHelp() *App {
 	return a
 }
 
+// AllowAbbreviations enables unambiguous prefix matching for long flags and
+// commands: --verb resolves to --verbose and "stat" to "status" when only one
+// candidate matches. Ambiguous prefixes fail with an error listing every candidate.
+func (a *App) AllowAbbreviations(enabled bool) *App {
+	a.allowAbbreviations = enabled
+	return a
+}
+
+// StrictGlobalFlags controls where global flags may appear. By default flags
+// declared with Global() are accepted anywhere, including after the command
+// name ("myapp serve --verbose"), and are always stored as global values.
+// When strict, global flags must precede the command.
+func (a *App) StrictGlobalFlags(strict bool) *App {
+	a.strictGlobalFlags = strict
+	return a
+}
+
 // Before sets a function to run before any command action
 func (a *App) Before(fn ActionFunc) *App {
 	a.beforeAction = fn
@@ -169,6 +300,9 @@ func (a *App) IO() *snapio.IOManager {
 func (a *App) Logger() *snapio.Logger {
 	if a.logger == nil {
 		a.logger = snapio.NewLogger(a.IO())
+		if a.theme != nil {
+			a.logger.WithTheme(*a.theme)
+		}
 	}
 	return a.logger
 }
@@ -182,7 +316,9 @@ func (a *App) StringFlag(name, description string) *FlagBuilder[string, *App] {
 		Description: description,
 		Type:        FlagTypeString,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[string, *App]{flag: flag, parent: a}
 }
 
@@ -193,10 +329,64 @@ func (a *App) IntFlag(name, description string) *FlagBuilder[int, *App] {
 		Description: description,
 		Type:        FlagTypeInt,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[int, *App]{flag: flag, parent: a}
 }
 
+// Int64Flag adds an int64 flag to the application
+func (a *App) Int64Flag(name, description string) *FlagBuilder[int64, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt64,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[int64, *App]{flag: flag, parent: a}
+}
+
+// Int32Flag adds an int32 flag to the application
+func (a *App) Int32Flag(name, description string) *FlagBuilder[int32, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt32,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[int32, *App]{flag: flag, parent: a}
+}
+
+// UintFlag adds a uint flag to the application
+func (a *App) UintFlag(name, description string) *FlagBuilder[uint, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeUint,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[uint, *App]{flag: flag, parent: a}
+}
+
+// Uint64Flag adds a uint64 flag to the application
+func (a *App) Uint64Flag(name, description string) *FlagBuilder[uint64, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeUint64,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[uint64, *App]{flag: flag, parent: a}
+}
+
 // BoolFlag adds a boolean flag to the application
 func (a *App) BoolFlag(name, description string) *FlagBuilder[bool, *App] {
 	flag := &Flag{
@@ -204,10 +394,29 @@ func (a *App) BoolFlag(name, description string) *FlagBuilder[bool, *App] {
 		Description: description,
 		Type:        FlagTypeBool,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[bool, *App]{flag: flag, parent: a}
 }
 
+// CountFlag adds an int flag counting its occurrences: -v, -vv and
+// "-v -v -v" give 1, 2 and 3, also inside a short flag cluster such as
+// "-vvo out.txt". An explicit value (--verbose=2) sets the count, which
+// later occurrences add to. Read it with ctx.MustInt.
+func (a *App) CountFlag(name, description string) *FlagBuilder[int, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt,
+		counter:     true,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[int, *App]{flag: flag, parent: a}
+}
+
 // DurationFlag adds a duration flag to the application
 func (a *App) DurationFlag(name, description string) *FlagBuilder[time.Duration, *App] {
 	flag := &Flag{
@@ -215,7 +424,9 @@ func (a *App) DurationFlag(name, description string) *FlagBuilder[time.Duration,
 		Description: description,
 		Type:        FlagTypeDuration,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[time.Duration, *App]{flag: flag, parent: a}
 }
 
@@ -226,7 +437,9 @@ func (a *App) FloatFlag(name, description string) *FlagBuilder[float64, *App] {
 		Description: description,
 		Type:        FlagTypeFloat,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[float64, *App]{flag: flag, parent: a}
 }
 
@@ -238,7 +451,9 @@ func (a *App) EnumFlag(name, description string, values ...string) *FlagBuilder[
 		Type:        FlagTypeEnum,
 		EnumValues:  values,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[string, *App]{flag: flag, parent: a}
 }
 
@@ -249,7 +464,9 @@ func (a *App) StringSliceFlag(name, description string) *FlagBuilder[[]string, *
 		Description: description,
 		Type:        FlagTypeStringSlice,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[[]string, *App]{flag: flag, parent: a}
 }
 
@@ -260,17 +477,61 @@ func (a *App) IntSliceFlag(name, description string) *FlagBuilder[[]int, *App] {
 		Description: description,
 		Type:        FlagTypeIntSlice,
 	}
+	a.checkMutable("flag", name)
 	a.flags[name] = flag
+	a.invalidateHelp()
 	return &FlagBuilder[[]int, *App]{flag: flag, parent: a}
 }
 
+// FloatSliceFlag adds a float64 slice flag to the application
+func (a *App) FloatSliceFlag(name, description string) *FlagBuilder[[]float64, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeFloatSlice,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[[]float64, *App]{flag: flag, parent: a}
+}
+
+// DurationSliceFlag adds a duration slice flag to the application
+func (a *App) DurationSliceFlag(name, description string) *FlagBuilder[[]time.Duration, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeDurationSlice,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[[]time.Duration, *App]{flag: flag, parent: a}
+}
+
+// EnumSliceFlag adds a multi-value enum flag to the application
+func (a *App) EnumSliceFlag(name, description string, values ...string) *FlagBuilder[[]string, *App] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeEnumSlice,
+		EnumValues:  values,
+	}
+	a.checkMutable("flag", name)
+	a.flags[name] = flag
+	a.invalidateHelp()
+	return &FlagBuilder[[]string, *App]{flag: flag, parent: a}
+}
+
 // Positional argument methods
 
 // StringArg adds a string positional argument to the application
 func (a *App) StringArg(name, description string) *ArgBuilder[string, *App] {
 	position := len(a.args)
 	builder := newStringArg(name, description, position, a)
+	a.checkMutable("argument", name)
 	a.args = append(a.args, builder.arg)
+	a.invalidateHelp()
 	return builder
 }
 
@@ -278,7 +539,9 @@ func (a *App) StringArg(name, description string) *ArgBuilder[string, *App] {
 func (a *App) IntArg(name, description string) *ArgBuilder[int, *App] {
 	position := len(a.args)
 	builder := newIntArg(name, description, position, a)
+	a.checkMutable("argument", name)
 	a.args = append(a.args, builder.arg)
+	a.invalidateHelp()
 	return builder
 }
 
@@ -286,7 +549,9 @@ func (a *App) IntArg(name, description string) *ArgBuilder[int, *App] {
 func (a *App) BoolArg(name, description string) *ArgBuilder[bool, *App] {
 	position := len(a.args)
 	builder := newBoolArg(name, description, position, a)
+	a.checkMutable("argument", name)
 	a.args = append(a.args, builder.arg)
+	a.invalidateHelp()
 	return builder
 }
 
@@ -294,7 +559,9 @@ func (a *App) BoolArg(name, description string) *ArgBuilder[bool, *App] {
 func (a *App) FloatArg(name, description string) *ArgBuilder[float64, *App] {
 	position := len(a.args)
 	builder := newFloatArg(name, description, position, a)
+	a.checkMutable("argument", name)
 	a.args = append(a.args, builder.arg)
+	a.invalidateHelp()
 	return builder
 }
 
@@ -302,7 +569,9 @@ func (a *App) FloatArg(name, description string) *ArgBuilder[float64, *App] {
 func (a *App) DurationArg(name, description string) *ArgBuilder[time.Duration, *App] {
 	position := len(a.args)
 	builder := newDurationArg(name, description, position, a)
+	a.checkMutable("argument", name)
 	a.args = append(a.args, builder.arg)
+	a.invalidateHelp()
 	return builder
 }
 
@@ -311,7 +580,9 @@ func (a *App) DurationArg(name, description string) *ArgBuilder[time.Duration, *
 func (a *App) StringSliceArg(name, description string) *ArgBuilder[[]string, *App] {
 	position := len(a.args)
 	builder := newStringSliceArg(name, description, position, a)
+	a.checkMutable("argument", name)
 	a.args = append(a.args, builder.arg)
+	a.invalidateHelp()
 	return builder
 }
 
@@ -320,7 +591,9 @@ func (a *App) StringSliceArg(name, description string) *ArgBuilder[[]string, *Ap
 func (a *App) IntSliceArg(name, description string) *ArgBuilder[[]int, *App] {
 	position := len(a.args)
 	builder := newIntSliceArg(name, description, position, a)
+	a.checkMutable("argument", name)
 	a.args = append(a.args, builder.arg)
+	a.invalidateHelp()
 	return builder
 }
 
@@ -328,6 +601,26 @@ func (a *App) IntSliceArg(name, description string) *ArgBuilder[[]int, *App] {
 // after declared args. Cannot be used with .Variadic() on the last arg.
 func (a *App) RestArgs() *App {
 	a.hasRestArgs = true
+	a.invalidateHelp()
+	return a
+}
+
+// FlagsFirst enables RestArgs but keeps parsing flags while declared args are
+// being filled; the first positional beyond them and everything after it go
+// to RestArgs unparsed
+func (a *App) FlagsFirst() *App {
+	a.hasRestArgs = true
+	a.restMode = restFlagsFirst
+	a.invalidateHelp()
+	return a
+}
+
+// StopAtFirstPositional enables RestArgs but parses flags up to the first
+// positional argument; from there on every token is positional
+func (a *App) StopAtFirstPositional() *App {
+	a.hasRestArgs = true
+	a.restMode = restStopAtFirstPositional
+	a.invalidateHelp()
 	return a
 }
 
@@ -346,8 +639,10 @@ func (a *App) Command(name, description string) *CommandBuilder {
 		flagGroups:  make([]*FlagGroup, 0),
 		middleware:  make([]middleware.Middleware, 0),
 	}
+	a.checkMutable("command", name)
 	a.addCommandHelpFlag(cmd)
 	a.commands[name] = cmd
+	a.invalidateHelp()
 	return &CommandBuilder{
 		command: cmd,
 		app:     a,
@@ -366,39 +661,82 @@ func (a *App) RunContext(ctx context.Context) error {
 	return a.RunWithArgs(ctx, os.Args[1:])
 }
 
-// RunWithArgs runs the application with provided arguments
+// RunWithArgs runs the application with provided arguments, freezing it
+// first (see Freeze)
+func (a *App) RunWithArgs(ctx context.Context, args []string) error {
+	a.Freeze()
+	defer func(running bool) { a.running = running }(a.running)
+	a.running = true
+
+	args = a.startTrace(args)
+	if capabilitiesRequested(args) {
+		return a.showCapabilities()
+	}
+	if segments := a.chainSegments(args); segments != nil {
+		return a.runChain(ctx, segments)
+	}
+	return a.runOnce(ctx, args)
+}
+
+// runOnce runs a single command line, tracing and reporting it
+func (a *App) runOnce(ctx context.Context, args []string) error {
+	start := time.Now()
+	a.currentResult = nil
+	err := a.runGuarded(ctx, args)
+	a.tracer.done("done", "run", start, err)
+	a.reportInvocation(start, err)
+	return err
+}
+
+// enableVirtualTerminal turns on ANSI processing on Windows when writing to a
+// TTY, unless SNAP_DISABLE_VT is set
+func (a *App) enableVirtualTerminal() {
+	if runtime.GOOS == "windows" && a.IO().IsTTY() && os.Getenv("SNAP_DISABLE_VT") == "" {
+		_ = a.IO().EnableVirtualTerminal() // best-effort; ignore failure
+	}
+}
+
+// run parses args and executes the matched command
 //
 //nolint:gocognit,nestif,funlen,cyclop,gocyclo // Main execution flow is inherently complex
-func (a *App) RunWithArgs(ctx context.Context, args []string) error {
+func (a *App) run(ctx context.Context, args []string) error {
 	// Store raw arguments before parsing for later access via Context.RawArgs()
 	a.rawArgs = args
 
-	// Windows: auto-enable Virtual Terminal (ANSI) when writing to a TTY, unless disabled
-	if runtime.GOOS == "windows" && a.IO().IsTTY() && os.Getenv("SNAP_DISABLE_VT") == "" {
-		_ = a.IO().EnableVirtualTerminal() // best-effort; ignore failure
+	a.enableVirtualTerminal()
+	a.addBuiltins()
+	a.scanOutputFlags(args)
+
+	args, err := a.expandArgumentFiles(args)
+	if err != nil {
+		return a.parseFailure(err)
 	}
-	// Add default help and version flags if enabled
-	if a.helpFlag {
-		a.addHelpFlag()
+	if a.allowArgumentFiles && a.tracer != nil {
+		a.tracer.printf("argfiles", "expanded to %q", a.redactArgs(args))
 	}
-	if a.versionFlag {
-		a.addVersionFlag()
+
+	// Built-in "help [COMMAND...|TOPIC]" command, equivalent to --help at that level
+	if a.isHelpCommand(args) {
+		return a.runHelpCommand(args[1:])
 	}
 
-	// Create parser and parse arguments
-	parser := NewParser(a)
-	result, err := parser.Parse(args)
+	parseStart := time.Now()
+	result, err := a.parseArgs(args)
+	a.tracer.done("parse", "arguments", parseStart, err)
 	if err != nil {
-		// Handle parsing errors with smart suggestions and contextual help
-		parseErr := &ParseError{}
-		if errors.As(err, &parseErr) {
-			return a.handleParseError(parseErr)
-		}
 		return err
 	}
 
 	// Store parse result for flag access
 	a.currentResult = result
+	a.readOutputFlags(result)
+	if result.Command != nil {
+		a.tracer.printf("command", "%s", a.commandPath(result.Command))
+	} else {
+		a.tracer.printf("command", "(none)")
+	}
+	a.tracer.flags(result)
+	a.emit(Event{Type: EventCommandResolved, Result: result})
 
 	// Handle built-in flags BEFORE populating configuration
 	if helpErr := a.handleHelpAndVersion(result); helpErr != nil {
@@ -409,9 +747,11 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 		return helpErr
 	}
 
-	// Populate configuration if config builder is attached
-	if a.configBuilder != nil {
-		cfgErr := a.populateConfiguration()
+	// Populate configuration if config builder is attached; the config and
+	// env commands run even when the configuration is incomplete or invalid
+	if a.configBuilder != nil && !a.configBuilder.isConfigCommand(result.Command) &&
+		(result.Command == nil || result.Command != a.envCmd) {
+		cfgErr := a.tracer.timed("config", "populate", a.populateConfiguration)
 		if cfgErr != nil {
 			return fmt.Errorf("configuration error: %w", cfgErr)
 		}
@@ -426,10 +766,19 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 		cancel:   cancel,
 		metadata: make(map[string]any),
 	}
+	execCtx.scopeIO(result.Command)
+	execCtx.applyOutputMode()
+	if a.captureOutput {
+		execCtx.captureOutput()
+	}
+
+	if gateErr := a.checkGates(execCtx, result.Command); gateErr != nil {
+		return gateErr
+	}
 
 	// Execute before action
 	if a.beforeAction != nil {
-		if beforeErr := a.beforeAction(execCtx); beforeErr != nil {
+		if beforeErr := a.traceHook("app before", a.beforeAction, execCtx); beforeErr != nil {
 			return beforeErr
 		}
 	}
@@ -439,7 +788,7 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 	if result.Command != nil {
 		// Execute command-level Before hook
 		if result.Command.beforeAction != nil {
-			if beforeErr := result.Command.beforeAction(execCtx); beforeErr != nil {
+			if beforeErr := a.traceHook("command before", result.Command.beforeAction, execCtx); beforeErr != nil {
 				return beforeErr
 			}
 		}
@@ -457,7 +806,7 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 		case result.Command.Action != nil:
 			// Apply middleware and execute action
 			wrappedAction := a.wrapActionWithMiddleware(result.Command.Action, result.Command)
-			actionErr = wrappedAction(execCtx)
+			actionErr = a.runAction(execCtx, "command action", wrappedAction)
 		case result.Command.wrapper != nil:
 			// Command-level wrapper (no explicit action)
 			actionErr = result.Command.wrapper.run(execCtx, args)
@@ -468,7 +817,7 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 
 		// Execute command-level After hook
 		if result.Command.afterAction != nil {
-			if afterErr := result.Command.afterAction(execCtx); afterErr != nil {
+			if afterErr := a.traceHook("command after", result.Command.afterAction, execCtx); afterErr != nil {
 				// If action succeeded but after hook failed, return after error
 				if actionErr == nil {
 					actionErr = afterErr
@@ -481,7 +830,7 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 		case a.action != nil:
 			// Execute app-level action (if defined)
 			wrappedAction := a.wrapActionWithMiddleware(a.action, nil)
-			actionErr = wrappedAction(execCtx)
+			actionErr = a.runAction(execCtx, "app action", wrappedAction)
 		case a.defaultWrapper != nil:
 			// Check if app has a default wrapper
 			actionErr = a.defaultWrapper.run(execCtx, args)
@@ -498,7 +847,7 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 
 	// Execute after action
 	if a.afterAction != nil {
-		if afterErr := a.afterAction(execCtx); afterErr != nil {
+		if afterErr := a.traceHook("app after", a.afterAction, execCtx); afterErr != nil {
 			return afterErr
 		}
 	}
@@ -506,12 +855,109 @@ func (a *App) RunWithArgs(ctx context.Context, args []string) error {
 	return actionErr
 }
 
+// Parse parses args into a typed result without running hooks, middleware or
+// actions, for hosts that make their own execution decisions. Built-in flags
+// are registered as in Run but not acted upon: check result.MustGetBool("help",
+// false) yourself. Errors carry the same suggestions as Run, and a bound
+// configuration (ConfigBuilder with FromFlags) is populated from the result.
+func (a *App) Parse(args []string) (*ParseResult, error) {
+	a.rawArgs = args
+	a.addBuiltins()
+
+	args, err := a.expandArgumentFiles(args)
+	if err != nil {
+		return nil, a.parseFailure(err)
+	}
+	result, err := a.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	a.currentResult = result
+
+	if a.configBuilder != nil {
+		if cfgErr := a.populateConfiguration(); cfgErr != nil {
+			return nil, fmt.Errorf("configuration error: %w", cfgErr)
+		}
+	}
+	return result, nil
+}
+
+// addBuiltins registers the default help and version flags (and the version
+// doctor, auth, config and search commands) when enabled
+func (a *App) addBuiltins() {
+	if a.helpFlag {
+		a.addHelpFlag()
+	}
+	if a.versionFlag {
+		a.addVersionFlag()
+	}
+	a.addOutputFlags()
+	a.addVersionCommand()
+	a.addDoctorCommand()
+	a.addAuthCommand()
+	a.addConfigCommand()
+	a.addSearchCommand()
+	a.addEnvCommand()
+}
+
+// PreParse resolves the command path and the flags registered so far without
+// running anything, so a plugin loader or dynamic completion can register
+// more flags and subcommands on result.Command before the full parse.
+// Unknown flags are skipped (use --flag=value for values), unknown commands
+// and other tokens are kept in result.Args, and required arguments and flag
+// groups are not checked. Argument files are expanded as in Parse.
+func (a *App) PreParse(args []string) (*ParseResult, error) {
+	a.addBuiltins()
+
+	args, err := a.expandArgumentFiles(args)
+	if err != nil {
+		return nil, a.parseFailure(err)
+	}
+	p := NewParser(a)
+	p.lenient = true
+	result, err := p.Parse(args)
+	if err != nil {
+		return nil, a.parseFailure(err)
+	}
+	return result, nil
+}
+
+// parseArgs runs the parser, turning parse errors into CLI errors with smart
+// suggestions and contextual help
+func (a *App) parseArgs(args []string) (*ParseResult, error) {
+	start := time.Now()
+	for {
+		result, err := NewParser(a).Parse(args)
+		if err == nil {
+			a.emit(Event{Type: EventParseCompleted, Result: result, Duration: time.Since(start)})
+			return result, nil
+		}
+		// Each correction replaces an unknown command with a known one, so this ends
+		corrected, ok := a.correctArgs(err, args)
+		if !ok {
+			a.emit(Event{Type: EventParseCompleted, Duration: time.Since(start), Err: err})
+			return nil, a.parseFailure(err)
+		}
+		args = corrected
+	}
+}
+
+// parseFailure routes parse errors through handleParseError; other errors pass through
+func (a *App) parseFailure(err error) error {
+	parseErr := &ParseError{}
+	if errors.As(err, &parseErr) {
+		return a.handleParseError(parseErr)
+	}
+	return err
+}
+
 // ExitCodes returns the exit-code manager for this app. Use it to override
 // defaults or register custom mappings. Resolution precedence is:
 // ExitError > CLI category (DefineCLI) > concrete error type (DefineError) > defaults.
 func (a *App) ExitCodes() *ExitCodeManager {
 	if a.exitCodes == nil {
 		a.exitCodes = newExitCodeManager()
+		a.exitCodes.app = a
 	}
 	return a.exitCodes
 }
@@ -523,7 +969,23 @@ func (a *App) RunAndGetExitCode() int {
 	if err == nil {
 		return a.ExitCodes().defaults.Success
 	}
-	return a.ExitCodes().resolve(err)
+	return a.exitCode(err)
+}
+
+// ExitCode returns the exit code err maps to, for an error returned by the
+// last run (RunWithArgs and friends), as RunAndGetExitCode would
+func (a *App) ExitCode(err error) int {
+	return a.exitCode(err)
+}
+
+// exitCode resolves err of the last run, honouring the mappings of the
+// command that ran
+func (a *App) exitCode(err error) int {
+	var cmd *Command
+	if a.currentResult != nil {
+		cmd = a.currentResult.Command
+	}
+	return a.ExitCodes().resolveFor(cmd, err)
 }
 
 // RunAndExit executes the app and terminates the process with the mapped exit
@@ -536,11 +998,13 @@ func (a *App) RunAndExit() {
 
 // addShortFlag adds a short flag mapping for O(1) lookup
 func (a *App) addShortFlag(short rune, flag *Flag) {
+	a.checkMutable("short flag", string(short))
 	a.shortFlags[short] = flag
 }
 
 // addFlagGroup adds a flag group to the app (implements FlagGroupParent interface)
 func (a *App) addFlagGroup(group *FlagGroup) {
+	a.checkMutable("flag group", group.Name)
 	// Check if group already exists to prevent duplicates
 	for _, existingGroup := range a.flagGroups {
 		if existingGroup.Name == group.Name {
@@ -549,9 +1013,11 @@ func (a *App) addFlagGroup(group *FlagGroup) {
 	}
 
 	a.flagGroups = append(a.flagGroups, group)
+	a.invalidateHelp()
 
 	// Also add all flags in the group to the app's flag map for parsing
 	for _, flag := range group.Flags {
+		flag.groupHidden = group.Hidden
 		a.flags[flag.Name] = flag
 		if flag.Short != 0 {
 			a.shortFlags[flag.Short] = flag
@@ -577,15 +1043,27 @@ func (a *App) ErrorHandler() *ErrorHandler {
 }
 
 // wrapActionWithMiddleware wraps the action with app-level and command-level middleware
+// (including middleware inherited from parent commands)
 func (a *App) wrapActionWithMiddleware(action ActionFunc, cmd *Command) ActionFunc {
 	// Combine app-level and command-level middleware
-	allMiddleware := make([]middleware.Middleware, 0, len(a.middleware)+len(cmd.middleware))
+	var cmdMiddleware []middleware.Middleware
+	if cmd != nil {
+		cmdMiddleware = cmd.commandMiddleware()
+	}
+	allMiddleware := make([]middleware.Middleware, 0, len(a.middleware)+len(cmdMiddleware))
 	allMiddleware = append(allMiddleware, a.middleware...)
-	allMiddleware = append(allMiddleware, cmd.middleware...)
+	allMiddleware = append(allMiddleware, cmdMiddleware...)
 
 	if len(allMiddleware) == 0 {
 		return action
 	}
+	if a.tracer != nil {
+		names := make([]string, len(allMiddleware))
+		for i, m := range allMiddleware {
+			names[i] = funcName(m)
+		}
+		a.tracer.printf("middleware", "%s", strings.Join(names, " -> "))
+	}
 
 	// Create middleware chain
 	chain := middleware.Chain(allMiddleware...)
@@ -609,10 +1087,46 @@ func (a *App) wrapActionWithMiddleware(action ActionFunc, cmd *Command) ActionFu
 	}
 }
 
+// traceHook runs a Before/After hook, tracing its duration when debugging
+func (a *App) traceHook(name string, hook ActionFunc, ctx *Context) error {
+	return a.tracer.timed("hook", name, func() error { return hook(ctx) })
+}
+
+// commandPath returns the space-separated path of cmd from the app root ("server status"),
+// or an empty string if cmd is not registered
+func (a *App) commandPath(cmd *Command) string {
+	var find func(prefix string, commands map[string]*Command) string
+	find = func(prefix string, commands map[string]*Command) string {
+		for name, c := range commands {
+			if c == cmd {
+				return prefix + name
+			}
+			if path := find(prefix+name+" ", c.subcommands); path != "" {
+				return path
+			}
+		}
+		return ""
+	}
+	return find("", a.commands)
+}
+
 // handleParseError converts ParseError to CLIError and displays it with context
 func (a *App) handleParseError(parseErr *ParseError) error {
-	// Convert ParseError to CLIError for enhanced handling
-	cliErr := NewError(parseErr.Type, parseErr.Message)
+	// Convert ParseError to CLIError for enhanced handling, in the app's locale
+	message := parseErr.Message
+	if parseErr.msgID != "" {
+		message = a.msgf(parseErr.msgID, parseErr.msgArgs...)
+	}
+	cliErr := NewError(parseErr.Type, message)
+	if parseErr.Token != "" {
+		// Echoed by ShowPosition, so secret values are redacted like in traces
+		cliErr.args = a.redactArgs(parseErr.args)
+		token := parseErr.Token
+		if parseErr.Index >= 0 && parseErr.Index < len(cliErr.args) {
+			token = cliErr.args[parseErr.Index]
+		}
+		cliErr = cliErr.WithContext("index", parseErr.Index).WithContext("token", token)
+	}
 
 	// Add context based on error type
 	switch parseErr.Type { // exhaustive over ErrorType for context enrichment
@@ -632,13 +1146,26 @@ func (a *App) handleParseError(parseErr *ParseError) error {
 		if parseErr.CurrentCommand != nil {
 			cliErr = cliErr.WithContext("current_command", parseErr.CurrentCommand)
 		}
+	case ErrorTypeAmbiguousFlag:
+		if parseErr.Flag != "" {
+			cliErr = cliErr.WithContext("flag", parseErr.Flag)
+		}
+		cliErr = cliErr.WithContext("candidates", parseErr.Candidates)
+	case ErrorTypeAmbiguousCommand:
+		if parseErr.Command != "" {
+			cliErr = cliErr.WithContext("command", parseErr.Command)
+		}
+		cliErr = cliErr.WithContext("candidates", parseErr.Candidates)
 	case ErrorTypeFlagGroupViolation:
 		if parseErr.GroupName != "" {
 			cliErr = cliErr.WithContext("group", parseErr.GroupName)
 		}
+		if parseErr.CurrentCommand != nil {
+			cliErr = cliErr.WithContext("current_command", parseErr.CurrentCommand)
+		}
 	case ErrorTypeInvalidFlag, ErrorTypeInvalidValue, ErrorTypeMissingValue,
 		ErrorTypeInternal, ErrorTypeMissingRequired, ErrorTypePermission, ErrorTypeValidation,
-		ErrorTypeInvalidArgument:
+		ErrorTypeInvalidArgument, ErrorTypeUnavailable, ErrorTypeLimitExceeded, ErrorTypeGated:
 		// No additional context for these types here.
 	}
 
@@ -647,15 +1174,20 @@ func (a *App) handleParseError(parseErr *ParseError) error {
 	cliErr = a.errorHandler.formatError(cliErr, a)
 
 	// If ShowHelpOnError is enabled, print contextual help to stderr before returning the error
-	if a.errorHandler.showHelpOnError {
+	if a.errorHandler.showHelpOnError && !a.quiet && !a.porcelain {
+		cmd := parseErr.CurrentCommand
 		if a.currentResult != nil && a.currentResult.Command != nil {
-			_ = a.showCommandHelp(a.currentResult.Command)
+			cmd = a.currentResult.Command
+		}
+		if cmd != nil {
+			_ = a.showCommandHelp(cmd)
 		} else {
 			_ = a.showHelp()
 		}
 		a.println("") // Add spacing between help and error
 	}
 
+	a.emit(Event{Type: EventErrorDisplayed, Err: cliErr})
 	return cliErr
 }
 
@@ -665,12 +1197,14 @@ func (a *App) handleParseError(parseErr *ParseError) error {
 func (a *App) addHelpFlag() {
 	if _, exists := a.flags["help"]; !exists {
 		flag := &Flag{
-			Name:        "help",
-			Description: "Show help",
-			Type:        FlagTypeBool,
-			Global:      true,
+			Name:          "help",
+			Description:   "Show help",
+			descriptionID: MsgHelpFlag,
+			Type:          FlagTypeBool,
+			Global:        true,
 		}
 		a.flags["help"] = flag
+		a.invalidateHelp()
 		// Provide -h by default if not already in use
 		if _, taken := a.shortFlags['h']; !taken {
 			flag.Short = 'h'
@@ -683,12 +1217,14 @@ func (a *App) addHelpFlag() {
 func (a *App) addVersionFlag() {
 	if _, exists := a.flags["version"]; !exists {
 		flag := &Flag{
-			Name:        "version",
-			Description: "Show version",
-			Type:        FlagTypeBool,
-			Global:      false, // Version flag should only work at app level, not in subcommands
+			Name:          "version",
+			Description:   "Show version",
+			descriptionID: MsgVersionFlag,
+			Type:          FlagTypeBool,
+			Global:        false, // Version flag should only work at app level, not in subcommands
 		}
 		a.flags["version"] = flag
+		a.invalidateHelp()
 	}
 }
 
@@ -696,10 +1232,11 @@ func (a *App) addVersionFlag() {
 func (a *App) addCommandHelpFlag(cmd *Command) {
 	if _, exists := cmd.flags["help"]; !exists {
 		flag := &Flag{
-			Name:        "help",
-			Description: "Show command help",
-			Type:        FlagTypeBool,
-			Global:      false,
+			Name:          "help",
+			Description:   "Show command help",
+			descriptionID: MsgCommandHelpFlag,
+			Type:          FlagTypeBool,
+			Global:        false,
 		}
 		cmd.flags["help"] = flag
 		// Provide -h by default at command level if not already in use
@@ -710,15 +1247,115 @@ func (a *App) addCommandHelpFlag(cmd *Command) {
 	}
 }
 
-// showHelp displays comprehensive application help
-// print writes formatted output to the app's IO manager output stream
+// print writes formatted output to the help buffer being rendered, or the app's IO manager output stream
 func (a *App) print(args ...interface{}) {
-	fmt.Fprint(a.IO().Out(), args...)
+	fmt.Fprint(a.out(), args...)
 }
 
-// println writes formatted output with a newline to the app's IO manager output stream
+// println writes formatted output with a newline to the help buffer being rendered, or the app's IO manager output stream
 func (a *App) println(args ...interface{}) {
-	fmt.Fprintln(a.IO().Out(), args...)
+	fmt.Fprintln(a.out(), args...)
+}
+
+// out returns the current render target
+func (a *App) out() io.Writer {
+	if a.renderBuf != nil {
+		return a.renderBuf
+	}
+	return a.IO().Out()
+}
+
+// invalidateHelp drops the cached app help; called whenever the definition changes
+func (a *App) invalidateHelp() {
+	a.helpCache = nil
+}
+
+// helpKey is the IO state the cached help was rendered for: a different
+// output, width, TTY, color or unicode setting renders it again
+type helpKey struct {
+	io      *snapio.IOManager
+	out     io.Writer
+	width   int
+	tty     bool
+	color   bool
+	level   int
+	unicode bool
+}
+
+// currentHelpKey snapshots the IO state help rendering depends on
+func (a *App) currentHelpKey() helpKey {
+	m := a.IO()
+	out := m.Out()
+	if t := reflect.TypeOf(out); t != nil && !t.Comparable() {
+		out = nil // Keys must stay comparable
+	}
+	return helpKey{
+		io:      m,
+		out:     out,
+		width:   m.Width(),
+		tty:     m.IsTTY(),
+		color:   m.SupportsColor(),
+		level:   m.ColorLevel(),
+		unicode: m.SupportsUnicode(),
+	}
+}
+
+// render runs fn against a pooled buffer and returns a copy of what it printed
+func (a *App) render(fn func()) []byte {
+	buf, _ := helpBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	prev := a.renderBuf
+	a.renderBuf = buf
+	fn()
+	a.renderBuf = prev
+	out := append([]byte(nil), buf.Bytes()...)
+	helpBufferPool.Put(buf)
+	return out
+}
+
+// showHelp displays comprehensive application help, rendering it once and
+// serving the cached copy until a flag, command, argument or group is
+// registered or the IO settings change. Help depending on runtime visibility
+// is rendered every time.
+func (a *App) showHelp() error {
+	if a.usageFunc != nil {
+		return a.writeUsage(func(w io.Writer) error { return a.usageFunc(a, w) })
+	}
+	if a.porcelain || a.dynamicVisibility() { // Rendered without colors, or may change between calls
+		return a.writeHelp(a.render(a.renderHelp))
+	}
+	if key := a.currentHelpKey(); a.helpCache == nil || key != a.helpCacheKey {
+		a.helpCache, a.helpCacheKey = a.render(a.renderHelp), key
+	}
+	return a.writeHelp(a.helpCache)
+}
+
+// dynamicVisibility reports whether a command or flag is shown depending on
+// a predicate evaluated at run time (VisibleIf, EnabledIf or an experiment)
+func (a *App) dynamicVisibility() bool {
+	dynamic := func(flags map[string]*Flag) bool {
+		for _, flag := range flags {
+			if flag.visibleIf != nil || flag.enabledIf != nil || flag.experiment != "" {
+				return true
+			}
+		}
+		return false
+	}
+	found := dynamic(a.flags)
+	for _, cmd := range a.commands {
+		eachCommand(cmd, func(c *Command) {
+			found = found || c.visibleIf != nil || c.enabledIf != nil || c.experiment != "" || dynamic(c.flags)
+		})
+	}
+	return found
+}
+
+// showCommandHelp displays detailed help for a specific command
+func (a *App) showCommandHelp(cmd *Command) error {
+	if fn := cmd.usage(); fn != nil {
+		return a.writeUsage(func(w io.Writer) error { return fn(cmd, w) })
+	}
+	return a.writeHelp(a.render(func() { a.renderCommandHelp(cmd) }))
 }
 
 // printArgumentsSection prints the Arguments section for help output
@@ -726,35 +1363,18 @@ func (a *App) printArgumentsSection(args []*Arg, hasRestArgs bool) {
 	//nolint:nestif // Help rendering naturally has nested structures
 	if len(args) > 0 {
 		a.println()
-		a.println("Arguments:")
+		a.println(a.heading(MsgArguments))
 
 		// Calculate max argument name width for alignment
 		maxArgWidth := 0
 		for _, arg := range args {
-			width := 2 + 2 + len(arg.Name) // "  " prefix + brackets/angles + name
-			if arg.Variadic {
-				width += 3 // "..."
-			}
-			if width > maxArgWidth {
-				maxArgWidth = width
-			}
+			maxArgWidth = max(maxArgWidth, 2+len(arg.usage())) // "  " prefix + usage
 		}
 
 		for _, arg := range args {
-			a.print("  ")
-			currentWidth := 2
-			nameWidth := len(arg.Name)
-			if arg.Required {
-				a.print("<", arg.Name, ">")
-				currentWidth += 2 + nameWidth
-			} else {
-				a.print("[", arg.Name, "]")
-				currentWidth += 2 + nameWidth
-			}
-			if arg.Variadic {
-				a.print("...")
-				currentWidth += 3
-			}
+			usage := arg.usage()
+			a.print("  ", usage)
+			currentWidth := 2 + len(usage)
 			if arg.Description != "" {
 				// Add padding to align descriptions (spaces only, no tabs)
 				padding := maxArgWidth - currentWidth + 2 // +2 for minimum spacing
@@ -767,14 +1387,15 @@ func (a *App) printArgumentsSection(args []*Arg, hasRestArgs bool) {
 		}
 	} else if hasRestArgs {
 		a.println()
-		a.println("Arguments:")
-		a.println("  [args...]  All remaining arguments are passed through")
+		a.println(a.heading(MsgArguments))
+		a.println("  [args...]  " + a.Message(MsgRestArguments))
 	}
 }
 
+// renderHelp renders the application help
 //
 //nolint:gocognit,funlen // Help rendering involves many small branches; splitting would harm readability.
-func (a *App) showHelp() error {
+func (a *App) renderHelp() {
 	// Application name and description
 	if a.description != "" {
 		a.println(a.description)
@@ -788,47 +1409,26 @@ func (a *App) showHelp() error {
 	}
 
 	// Usage line
-	a.println("Usage:")
-	a.print("  ", a.name)
-	if len(a.flags) > 0 {
-		a.print(" [GLOBAL FLAGS]")
-	}
-
-	// Show positional arguments in usage line
-	if len(a.args) > 0 {
-		for _, arg := range a.args {
-			a.print(" ")
-			if arg.Required {
-				a.print("<", arg.Name, ">")
-			} else {
-				a.print("[", arg.Name, "]")
-			}
-			if arg.Variadic {
-				a.print("...")
-			}
-		}
-	} else if a.hasRestArgs {
-		a.print(" [args...]")
-	}
-
+	a.println(a.heading(MsgUsage))
+	tail := ""
 	if len(a.commands) > 0 {
-		a.print(" COMMAND [COMMAND FLAGS]")
+		tail = "COMMAND [COMMAND FLAGS]"
 	}
-	a.println()
+	a.printUsageLine(a.name, a.flags, "[GLOBAL FLAGS]", a.args, a.hasRestArgs, tail)
 
 	// Version information
 	if a.version != "" {
 		a.println()
-		a.println("Version:", a.version)
+		a.println(a.heading(MsgVersion), a.version)
 	}
 
 	// Authors information
 	if len(a.authors) > 0 {
 		a.println()
 		if len(a.authors) == 1 {
-			a.println("Author:", a.authors[0].Name, "<"+a.authors[0].Email+">")
+			a.println(a.heading(MsgAuthor), a.authors[0].Name, "<"+a.authors[0].Email+">")
 		} else {
-			a.println("Authors:")
+			a.println(a.heading(MsgAuthors))
 			for _, author := range a.authors {
 				a.println("  ", author.Name, "<"+author.Email+">")
 			}
@@ -838,26 +1438,23 @@ func (a *App) showHelp() error {
 	// Show flags organized by groups
 	a.showOrganizedFlags()
 
+	// Flags of the wrapped binary (see ForwardHelp)
+	a.showForwardedFlags(a.defaultWrapper, nil)
+
 	// Positional arguments
 	a.printArgumentsSection(a.args, a.hasRestArgs)
 
 	// Commands (deterministic order)
 	if len(a.commands) > 0 { //nolint:nestif // help rendering uses explicit nested branches for clarity
 		a.println()
-		a.println("Commands:")
+		a.println(a.heading(MsgCommands))
 		names := make([]string, 0, len(a.commands))
 		for name := range a.commands {
-			if !a.commands[name].Hidden {
+			if !a.commands[name].isHidden() {
 				names = append(names, name)
 			}
 		}
-		for i := 0; i < len(names); i++ {
-			for j := i + 1; j < len(names); j++ {
-				if names[j] < names[i] {
-					names[i], names[j] = names[j], names[i]
-				}
-			}
-		}
+		sort.Strings(names)
 
 		// Calculate max command name length for alignment
 		maxNameLen := 0
@@ -879,24 +1476,24 @@ func (a *App) showHelp() error {
 				a.print(cmd.Description())
 			}
 			if len(cmd.Aliases) > 0 {
-				a.print(" (aliases: ")
-				for i, alias := range cmd.Aliases {
-					if i > 0 {
-						a.print(", ")
-					}
-					a.print(alias)
-				}
-				a.print(")")
+				a.print(" (", a.msgf(MsgAliases, strings.Join(cmd.Aliases, ", ")), ")")
 			}
 			a.println()
 		}
 	}
 
+	// Free-form help topics
+	a.printTopicsSection()
+
+	// Experiments gating commands and flags
+	a.printExperimentsSection()
+
 	// Footer
 	a.println()
-	a.println("Use \"" + a.name + " COMMAND --help\" for more information about a command.")
-
-	return nil
+	a.println(a.msgf(MsgCommandHelpFooter, a.name))
+	if len(a.helpTopics) > 0 {
+		a.println(a.msgf(MsgTopicHelpFooter, a.name))
+	}
 }
 
 // flagDisplayWidth calculates the width of the flag display string (before description)
@@ -905,9 +1502,7 @@ func flagDisplayWidth(flag *Flag) int {
 	if flag.Short != 0 {
 		width += 4 // ", -X"
 	}
-	if flag.Type != FlagTypeBool {
-		width += 6 // " value"
-	}
+	width += len(flag.valueUsage()) // " value" or "[=value]"
 	return width
 }
 
@@ -928,7 +1523,7 @@ func (a *App) showOrganizedFlags() {
 
 	// Collect ungrouped flags
 	for name, flag := range a.flags {
-		if !groupedFlags[name] && !flag.Hidden {
+		if !groupedFlags[name] && !flag.isHidden() {
 			ungroupedFlags[name] = flag
 		}
 	}
@@ -936,7 +1531,7 @@ func (a *App) showOrganizedFlags() {
 	// Calculate max flag display width across all visible flags
 	maxWidth := 0
 	for _, flag := range a.flags {
-		if !flag.Hidden {
+		if !flag.isHidden() {
 			width := flagDisplayWidth(flag)
 			if width > maxWidth {
 				maxWidth = width
@@ -944,58 +1539,16 @@ func (a *App) showOrganizedFlags() {
 		}
 	}
 
-	// Sort groups by name for deterministic output
-	groups := append(make([]*FlagGroup, 0, len(a.flagGroups)), a.flagGroups...)
-	for i := 0; i < len(groups); i++ {
-		for j := i + 1; j < len(groups); j++ {
-			if groups[j].Name < groups[i].Name {
-				groups[i], groups[j] = groups[j], groups[i]
-			}
-		}
-	}
-
-	// Show flag groups first (sorted)
-	//nolint:dupl // Similar to command flag rendering but operates on app-level flags
-	for _, group := range groups {
-		a.println()
-		if group.Description != "" {
-			a.println(group.Name + " - " + group.Description + ":")
-		} else {
-			a.println(group.Name + ":")
-		}
-
-		// sort flags by name
-		names := make([]string, 0, len(group.Flags))
-		for _, flag := range group.Flags {
-			if !flag.Hidden {
-				names = append(names, flag.Name)
-			}
-		}
-		for i := 0; i < len(names); i++ {
-			for j := i + 1; j < len(names); j++ {
-				if names[j] < names[i] {
-					names[i], names[j] = names[j], names[i]
-				}
-			}
-		}
-		for _, name := range names {
-			a.showFlag(a.flags[name], maxWidth)
-		}
-
-		// Show constraint info
-		constraintDesc := a.formatGroupConstraint(group.Constraint)
-		if constraintDesc != "" {
-			a.println("  Note:", constraintDesc)
-		}
-	}
+	// Show flag groups first
+	a.printFlagGroups(a.flagGroups, a.flags, maxWidth)
 
 	// Show ungrouped flags
 	if len(ungroupedFlags) > 0 {
 		a.println()
 		if len(a.flagGroups) > 0 {
-			a.println("Global Flags:")
+			a.println(a.heading(MsgGlobalFlags))
 		} else {
-			a.println("Flags:")
+			a.println(a.heading(MsgFlags))
 		}
 
 		// sort names
@@ -1003,13 +1556,7 @@ func (a *App) showOrganizedFlags() {
 		for n := range ungroupedFlags {
 			names = append(names, n)
 		}
-		for i := 0; i < len(names); i++ {
-			for j := i + 1; j < len(names); j++ {
-				if names[j] < names[i] {
-					names[i], names[j] = names[j], names[i]
-				}
-			}
-		}
+		sort.Strings(names)
 		for _, n := range names {
 			a.showFlag(ungroupedFlags[n], maxWidth)
 		}
@@ -1018,17 +1565,16 @@ func (a *App) showOrganizedFlags() {
 
 // showFlag displays a single flag with both long and short forms
 func (a *App) showFlag(flag *Flag, maxWidth int) {
-	a.print("  --", flag.Name)
+	flagStyle := a.Theme().FlagStyle
+	a.print("  ", a.styled(flagStyle, "--"+flag.Name))
 
 	// Show short form if available
 	if flag.Short != 0 {
-		a.print(", -", string(flag.Short))
+		a.print(", ", a.styled(flagStyle, "-"+string(flag.Short)))
 	}
 
-	// Show value type for non-boolean flags
-	if flag.Type != FlagTypeBool {
-		a.print(" value")
-	}
+	// Show the value placeholder for non-boolean flags
+	a.print(flag.valueUsage())
 
 	// Add padding to align descriptions (spaces only, no tabs)
 	currentWidth := flagDisplayWidth(flag)
@@ -1037,42 +1583,112 @@ func (a *App) showFlag(flag *Flag, maxWidth int) {
 		a.print(" ")
 	}
 
-	// Show description
-	if flag.Description != "" {
-		a.print(flag.Description)
+	// Show description and default value, wrapped to the terminal width
+	description := flag.Description
+	if flag.descriptionID != "" {
+		description = a.Message(flag.descriptionID)
 	}
+	if defaultValue := a.getDefaultValue(flag); defaultValue != "" {
+		description += " (" + a.msgf(MsgDefault, defaultValue) + ")"
+	}
+	a.println(a.wrapHelp(description, maxWidth+4))
+}
 
-	// Show default value if present
-	defaultValue := a.getDefaultValue(flag)
-	if defaultValue != "" {
-		a.print(" (default: ", defaultValue, ")")
+// wrapHelp wraps help text starting at column indent to the terminal width,
+// aligning continuation lines with the first; output that is not a terminal
+// or leaves fewer than 20 columns is returned unchanged
+func (a *App) wrapHelp(text string, indent int) string {
+	const minWidth = 20
+	width := a.IO().Width() - indent
+	if !a.IO().IsTTY() || width < minWidth {
+		return text
 	}
+	return strings.ReplaceAll(snapio.Wrap(text, width), "\n", "\n"+strings.Repeat(" ", indent))
+}
 
-	a.println()
+// printFlagGroups prints each visible flag group with its flags and notes,
+// ordered by Order and then by name; hidden groups and groups without
+// visible flags are skipped
+func (a *App) printFlagGroups(groups []*FlagGroup, flags map[string]*Flag, maxWidth int) {
+	sorted := append(make([]*FlagGroup, 0, len(groups)), groups...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Order != sorted[j].Order {
+			return sorted[i].Order < sorted[j].Order
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, group := range sorted {
+		if group.Hidden {
+			continue
+		}
+		names := make([]string, 0, len(group.Flags))
+		for _, flag := range group.Flags {
+			if !flag.isHidden() {
+				names = append(names, flag.Name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+
+		a.println()
+		if group.Description != "" {
+			a.println(a.styled(a.Theme().HeadingStyle, group.Name+" - "+group.Description+":"))
+		} else {
+			a.println(a.styled(a.Theme().HeadingStyle, group.Name+":"))
+		}
+		for _, name := range names {
+			a.showFlag(flags[name], maxWidth)
+		}
+		a.printGroupNotes(group)
+	}
+}
+
+// printGroupNotes prints the constraint and default selection notes of a flag group
+func (a *App) printGroupNotes(group *FlagGroup) {
+	if constraintDesc := a.formatGroupConstraint(group.Constraint); constraintDesc != "" {
+		a.println("  " + a.msgf(MsgNote, constraintDesc))
+	}
+	if group.selectsDefault() {
+		a.println("  " + a.msgf(MsgNote, a.msgf(MsgGroupDefaultFlag, group.DefaultFlag)))
+	}
 }
 
 // formatGroupConstraint returns a human-readable constraint description
 func (a *App) formatGroupConstraint(constraint GroupConstraintType) string {
 	switch constraint { // exhaustive over GroupConstraintType
 	case GroupMutuallyExclusive:
-		return "Only one of these flags can be used at a time"
+		return a.Message(MsgGroupMutuallyExclusive)
 	case GroupRequiredGroup:
-		return "At least one of these flags is required"
+		return a.Message(MsgGroupAtLeastOne)
 	case GroupAllOrNone:
-		return "Either all of these flags must be provided, or none"
+		return a.Message(MsgGroupAllOrNone)
 	case GroupExactlyOne:
-		return "Exactly one of these flags must be provided"
+		return a.Message(MsgGroupExactlyOne)
 	case GroupNoConstraint:
 		return ""
 	case GroupAtLeastOne:
-		return "At least one of these flags is required"
+		return a.Message(MsgGroupAtLeastOne)
 	default:
 		return ""
 	}
 }
 
-// getDefaultValue returns the default value of a flag as a string
+// getDefaultValue returns the default value of a flag as shown in help:
+// durations without trailing zero units, large numbers with thousands
+// separators (see snapio.FormatDuration and friends)
 func (a *App) getDefaultValue(flag *Flag) string {
+	if flag.DefaultText != "" {
+		return flag.DefaultText
+	}
+	if flag.defaultFunc != nil {
+		return "auto"
+	}
+	if flag.secret {
+		return ""
+	}
 	switch flag.Type {
 	case FlagTypeString, FlagTypeEnum:
 		if flag.DefaultString != "" {
@@ -1080,7 +1696,15 @@ func (a *App) getDefaultValue(flag *Flag) string {
 		}
 	case FlagTypeInt:
 		if flag.DefaultInt != 0 {
-			return strconv.Itoa(flag.DefaultInt)
+			return snapio.FormatNumber(int64(flag.DefaultInt))
+		}
+	case FlagTypeInt64, FlagTypeInt32:
+		if flag.DefaultInt64 != 0 {
+			return snapio.FormatNumber(flag.DefaultInt64)
+		}
+	case FlagTypeUint, FlagTypeUint64:
+		if flag.DefaultUint64 != 0 {
+			return snapio.FormatUint(flag.DefaultUint64)
 		}
 	case FlagTypeBool:
 		if flag.DefaultBool {
@@ -1088,81 +1712,65 @@ func (a *App) getDefaultValue(flag *Flag) string {
 		}
 	case FlagTypeDuration:
 		if flag.DefaultDuration != 0 {
-			return flag.DefaultDuration.String()
+			return snapio.FormatDuration(flag.DefaultDuration)
 		}
 	case FlagTypeFloat:
 		if flag.DefaultFloat != 0 {
-			return fmt.Sprintf("%g", flag.DefaultFloat)
+			return snapio.FormatFloat(flag.DefaultFloat)
 		}
 	case FlagTypeStringSlice:
-		if len(flag.DefaultStringSlice) > 0 {
-			// join with comma
-			s := ""
-			for i, v := range flag.DefaultStringSlice {
-				if i > 0 {
-					s += ","
-				}
-				s += v
-			}
-			return s
-		}
+		return strings.Join(flag.DefaultStringSlice, ",")
 	case FlagTypeIntSlice:
-		if len(flag.DefaultIntSlice) > 0 {
-			s := ""
-			for i, v := range flag.DefaultIntSlice {
-				if i > 0 {
-					s += ","
-				}
-				s += strconv.Itoa(v)
-			}
-			return s
-		}
+		return joinDefaults(flag.DefaultIntSlice, strconv.Itoa)
+	case FlagTypeFloatSlice:
+		return joinDefaults(flag.DefaultFloatSlice, func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) })
+	case FlagTypeDurationSlice:
+		return joinDefaults(flag.DefaultDurationSlice, snapio.FormatDuration)
+	case FlagTypeEnumSlice:
+		return strings.Join(flag.DefaultEnumSlice, ",")
 	}
 	return ""
 }
 
+// joinDefaults formats each default slice element and joins them with
+// commas; numbers are not grouped here since the comma separates elements
+func joinDefaults[T any](values []T, format func(T) string) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = format(v)
+	}
+	return strings.Join(parts, ",")
+}
+
 // showVersion displays application version
 func (a *App) showVersion() error {
-	a.println(a.name, a.version)
+	if a.buildInfo == nil && a.versionTemplate == "" {
+		a.println(a.name, a.version)
+		return nil
+	}
+	text, err := a.renderVersion()
+	if err != nil {
+		return err
+	}
+	a.print(text)
 	return nil
 }
 
-// showCommandHelp displays detailed help for a specific command
+// renderCommandHelp renders detailed help for a specific command
 //
 //nolint:gocognit,funlen // Command help rendering prioritizes clarity over reduced nesting.
-func (a *App) showCommandHelp(cmd *Command) error {
+func (a *App) renderCommandHelp(cmd *Command) {
 	// Command name and description
 	a.println(cmd.Description())
 	a.println()
 
 	// Usage line
-	a.println("Usage:")
-	a.print("  ", a.name, " ", cmd.Name())
-	if len(cmd.flags) > 0 {
-		a.print(" [FLAGS]")
-	}
-
-	// Show positional arguments in usage line
-	if len(cmd.args) > 0 {
-		for _, arg := range cmd.args {
-			a.print(" ")
-			if arg.Required {
-				a.print("<", arg.Name, ">")
-			} else {
-				a.print("[", arg.Name, "]")
-			}
-			if arg.Variadic {
-				a.print("...")
-			}
-		}
-	} else if cmd.hasRestArgs {
-		a.print(" [args...]")
-	}
-
+	a.println(a.heading(MsgUsage))
+	tail := ""
 	if len(cmd.subcommands) > 0 {
-		a.print(" SUBCOMMAND")
+		tail = "SUBCOMMAND"
 	}
-	a.println()
+	a.printUsageLine(a.name+" "+cmd.Name(), cmd.flags, "[FLAGS]", cmd.args, cmd.hasRestArgs, tail)
 
 	// Long help text if available
 	if cmd.HelpText != "" {
@@ -1176,26 +1784,23 @@ func (a *App) showCommandHelp(cmd *Command) error {
 	// Global flags (available to all commands)
 	a.showGlobalFlags()
 
+	// Flags of the wrapped binary (see ForwardHelp)
+	a.showForwardedFlags(cmd.wrapper, cmd.flags)
+
 	// Positional arguments
 	a.printArgumentsSection(cmd.args, cmd.hasRestArgs)
 
 	// Subcommands (sorted)
 	if len(cmd.subcommands) > 0 { //nolint:nestif // help rendering uses explicit nested branches for clarity
 		a.println()
-		a.println("Subcommands:")
+		a.println(a.heading(MsgSubcommands))
 		names := make([]string, 0, len(cmd.subcommands))
 		for name, sc := range cmd.subcommands {
-			if !sc.Hidden {
+			if !sc.isHidden() {
 				names = append(names, name)
 			}
 		}
-		for i := 0; i < len(names); i++ {
-			for j := i + 1; j < len(names); j++ {
-				if names[j] < names[i] {
-					names[i], names[j] = names[j], names[i]
-				}
-			}
-		}
+		sort.Strings(names)
 
 		// Calculate max subcommand name length for alignment
 		maxNameLen := 0
@@ -1217,14 +1822,7 @@ func (a *App) showCommandHelp(cmd *Command) error {
 				a.print(subcmd.Description())
 			}
 			if len(subcmd.Aliases) > 0 {
-				a.print(" (aliases: ")
-				for i, alias := range subcmd.Aliases {
-					if i > 0 {
-						a.print(", ")
-					}
-					a.print(alias)
-				}
-				a.print(")")
+				a.print(" (", a.msgf(MsgAliases, strings.Join(subcmd.Aliases, ", ")), ")")
 			}
 			a.println()
 		}
@@ -1232,9 +1830,7 @@ func (a *App) showCommandHelp(cmd *Command) error {
 
 	// Footer
 	a.println()
-	a.println("Use \"" + a.name + " " + cmd.Name() + " SUBCOMMAND --help\" for more information about a subcommand.")
-
-	return nil
+	a.println(a.msgf(MsgSubcommandHelpFooter, a.name+" "+cmd.Name()))
 }
 
 // showOrganizedCommandFlags displays command flags with grouping and deterministic order
@@ -1248,7 +1844,7 @@ func (a *App) showOrganizedCommandFlags(cmd *Command) {
 	// Calculate max flag display width across all visible command flags
 	maxWidth := 0
 	for _, flag := range cmd.flags {
-		if !flag.Hidden {
+		if !flag.isHidden() {
 			width := flagDisplayWidth(flag)
 			if width > maxWidth {
 				maxWidth = width
@@ -1265,56 +1861,19 @@ func (a *App) showOrganizedCommandFlags(cmd *Command) {
 	}
 
 	// Print groups
-	//nolint:dupl // Similar to app flag rendering but operates on command-level flags
-	for _, g := range cmd.flagGroups {
-		a.println()
-		if g.Description != "" {
-			a.println(g.Name + " - " + g.Description + ":")
-		} else {
-			a.println(g.Name + ":")
-		}
-		// deterministic order
-		names := make([]string, 0, len(g.Flags))
-		for _, f := range g.Flags {
-			if !f.Hidden {
-				names = append(names, f.Name)
-			}
-		}
-		// simple sort (no import to avoid clutter)
-		for i := 0; i < len(names); i++ {
-			for j := i + 1; j < len(names); j++ {
-				if names[j] < names[i] {
-					names[i], names[j] = names[j], names[i]
-				}
-			}
-		}
-		for _, name := range names {
-			a.showFlag(cmd.flags[name], maxWidth)
-		}
-		constraintDesc := a.formatGroupConstraint(g.Constraint)
-		if constraintDesc != "" {
-			a.println("  Note:", constraintDesc)
-		}
-	}
+	a.printFlagGroups(cmd.flagGroups, cmd.flags, maxWidth)
 
 	// Ungrouped flags
 	ungrouped := make([]string, 0)
 	for name, f := range cmd.flags {
-		if !f.Hidden && !grouped[name] {
+		if !f.isHidden() && !grouped[name] {
 			ungrouped = append(ungrouped, name)
 		}
 	}
 	if len(ungrouped) > 0 {
-		// sort
-		for i := 0; i < len(ungrouped); i++ {
-			for j := i + 1; j < len(ungrouped); j++ {
-				if ungrouped[j] < ungrouped[i] {
-					ungrouped[i], ungrouped[j] = ungrouped[j], ungrouped[i]
-				}
-			}
-		}
+		sort.Strings(ungrouped)
 		a.println()
-		a.println("Flags:")
+		a.println(a.heading(MsgFlags))
 		for _, name := range ungrouped {
 			a.showFlag(cmd.flags[name], maxWidth)
 		}
@@ -1326,7 +1885,7 @@ func (a *App) showGlobalFlags() {
 	// Collect global flags
 	globalFlags := make([]*Flag, 0)
 	for _, flag := range a.flags {
-		if flag.Global && !flag.Hidden {
+		if flag.Global && !flag.isHidden() {
 			globalFlags = append(globalFlags, flag)
 		}
 	}
@@ -1344,17 +1903,10 @@ func (a *App) showGlobalFlags() {
 		}
 	}
 
-	// Sort global flags by name
-	for i := 0; i < len(globalFlags); i++ {
-		for j := i + 1; j < len(globalFlags); j++ {
-			if globalFlags[j].Name < globalFlags[i].Name {
-				globalFlags[i], globalFlags[j] = globalFlags[j], globalFlags[i]
-			}
-		}
-	}
+	sort.Slice(globalFlags, func(i, j int) bool { return globalFlags[i].Name < globalFlags[j].Name })
 
 	a.println()
-	a.println("Global Flags:")
+	a.println(a.heading(MsgGlobalFlags))
 	for _, flag := range globalFlags {
 		a.showFlag(flag, maxWidth)
 	}
@@ -1366,30 +1918,56 @@ func (a *App) populateConfiguration() error {
 		return nil
 	}
 
+	if err := a.configBuilder.refreshSources(); err != nil {
+		return err
+	}
+
+	// Resolve configuration with precedence using the precedence manager
+	resolved, err := a.configBuilder.precedenceManager.ResolveWithSchema(a.configBuilder.schema)
+	if err != nil {
+		return err
+	}
+
+	// Apply resolved configuration to target struct
+	if err := a.configBuilder.applyToStruct(resolved); err != nil {
+		return err
+	}
+	return a.configBuilder.validateConfig()
+}
+
+// refreshSources brings the sources up to date with the parsed command line
+// and the current environment
+func (cb *ConfigBuilder) refreshSources() error {
 	// Execute any pending source additions
-	for _, addSource := range a.configBuilder.pendingSources {
+	for _, addSource := range cb.pendingSources {
 		addSource()
 	}
 
-	// Collect flag values now that we have parsed results
-	a.configBuilder.collectFlagValues()
+	// Collect flag values now that we have parsed results, replacing those of
+	// an earlier run
+	cb.precedenceManager.removeSources(SourceTypeFlags)
+	cb.collectFlagValues()
 
 	// Refresh environment source to ensure current process env is honored in CLI mode
-	if a.configBuilder.schema != nil {
-		envData := a.configBuilder.loadFromEnv()
+	if cb.schema != nil {
+		cb.precedenceManager.removeSources(SourceTypeEnv)
+		envData := cb.loadFromEnv()
 		if len(envData) > 0 {
-			a.configBuilder.precedenceManager.AddSource(SourceTypeEnv, envData)
+			cb.precedenceManager.AddSource(SourceTypeEnv, envData)
 		}
 	}
 
-	// Resolve configuration with precedence using the precedence manager
-	resolved, err := a.configBuilder.precedenceManager.ResolveWithSchema(a.configBuilder.schema)
-	if err != nil {
+	// Fail on Required files that could not be loaded, then load the one
+	// named on the command line
+	if err := cb.checkFiles(); err != nil {
+		return err
+	}
+	if err := cb.applyFileFlag(); err != nil {
 		return err
 	}
 
-	// Apply resolved configuration to target struct
-	return a.configBuilder.applyToStruct(resolved)
+	// Overlay the selected profile's file sections
+	return cb.applyProfile()
 }
 
 // handleHelpAndVersion provides comprehensive help and version handling for all command levels
@@ -1428,9 +2006,10 @@ func (a *App) isHelpRequested(result *ParseResult) bool {
 
 // isVersionRequested checks if version was requested at any command level
 func (a *App) isVersionRequested(result *ParseResult) bool {
-	// Check global version first: myapp --version
+	// Check global version first: myapp --version (the version flag is app-scoped,
+	// not Global, so it is stored with the regular flags)
 	if result.Command == nil {
-		return result.MustGetGlobalBool("version", false)
+		return result.MustGetGlobalBool("version", false) || result.MustGetBool("version", false)
 	}
 
 	// Check command-level version: myapp command --version, myapp cmd subcmd --version, etc.
@@ -38,10 +38,23 @@ type Arg struct {
 	DefaultStringSlice []string
 	DefaultIntSlice    []int
 	Required           bool
-	Variadic           bool // Only valid for last arg, only for StringSlice/IntSlice types
+	Variadic           bool   // Only valid for last arg, only for StringSlice/IntSlice types
+	Placeholder        string // Shown instead of <name>/[name] in usage lines ("SRC", "[DEST]")
 
 	// Type-safe validation function (will be cast to func(T) error at runtime)
 	Validator interface{}
+
+	// Flags that cannot be given together with this argument (see ConflictsWithFlag)
+	conflictFlags []string
+
+	// Number of values of a variadic argument (see MinCount / MaxCount; 0: no limit)
+	minCount int
+	maxCount int
+
+	// Allowed values and completion hint (see EnumArg, FileArg and DirArg)
+	EnumValues []string
+	Completion ArgCompletion
+	mustExist  bool
 }
 
 // IsRequired returns true if the argument is required
@@ -91,6 +104,18 @@ func (b *ArgBuilder[T, P]) Default(value T) P {
 	return b.parent
 }
 
+// Placeholder sets how the argument appears in usage lines: "SRC" when
+// required, "[DEST]" when optional, instead of "<src>" and "[dest]"
+func (b *ArgBuilder[T, P]) Placeholder(name string) *ArgBuilder[T, P] {
+	b.arg.Placeholder = name
+	return b
+}
+
+// Metavar is an alias of Placeholder
+func (b *ArgBuilder[T, P]) Metavar(name string) *ArgBuilder[T, P] {
+	return b.Placeholder(name)
+}
+
 // Variadic marks the argument as variadic (accepts multiple values)
 // Only valid for StringSliceArg and must be the last positional argument
 // Returns parent to complete the chain
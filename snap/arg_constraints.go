@@ -0,0 +1,112 @@
+package snap
+
+import "strings"
+
+// argFlagRequirement is a RequireArgsOrFlag rule: the argument or one of
+// the flags must be given
+type argFlagRequirement struct {
+	arg   string
+	flags []string
+}
+
+// ConflictsWithFlag rejects the argument when any of the flags is given on
+// the command line or through its environment variable (bool flags only
+// when true):
+//
+//	cmd.StringSliceArg("files", "Files to remove").ConflictsWithFlag("all").Variadic()
+func (b *ArgBuilder[T, P]) ConflictsWithFlag(flags ...string) *ArgBuilder[T, P] {
+	b.arg.conflictFlags = append(b.arg.conflictFlags, flags...)
+	return b
+}
+
+// RequireArgsOrFlag requires the optional argument arg or one of the flags,
+// e.g. "rm FILES..." or "rm --all"; combine it with ConflictsWithFlag to
+// forbid both
+func (c *CommandBuilder) RequireArgsOrFlag(arg string, flags ...string) *CommandBuilder {
+	c.command.argsOrFlag = append(c.command.argsOrFlag, argFlagRequirement{arg: arg, flags: flags})
+	return c
+}
+
+// RequireArgsOrFlag requires the optional app argument arg or one of the
+// flags, like CommandBuilder.RequireArgsOrFlag
+func (a *App) RequireArgsOrFlag(arg string, flags ...string) *App {
+	a.argsOrFlag = append(a.argsOrFlag, argFlagRequirement{arg: arg, flags: flags})
+	return a
+}
+
+// validateArgConstraints checks ConflictsWithFlag and RequireArgsOrFlag for
+// the arguments of the matched command (or the app)
+func (p *Parser) validateArgConstraints(result *ParseResult) error {
+	args, requirements := p.app.args, p.app.argsOrFlag
+	if result.Command != nil {
+		args, requirements = result.Command.args, result.Command.argsOrFlag
+	}
+
+	for _, arg := range args {
+		if len(arg.conflictFlags) == 0 || !p.argProvided(arg) {
+			continue
+		}
+		for _, flag := range arg.conflictFlags {
+			if flagProvided(result, flag) {
+				return &ParseError{
+					Type:    ErrorTypeInvalidArgument,
+					Message: "argument " + arg.Name + " cannot be used with --" + flag,
+					Flag:    flag,
+					msgID:   MsgArgFlagConflict,
+					msgArgs: []any{arg.Name, "--" + flag},
+				}
+			}
+		}
+	}
+
+	for _, req := range requirements {
+		if p.requirementMet(result, args, req) {
+			continue
+		}
+		flags := "--" + strings.Join(req.flags, ", --")
+		return &ParseError{
+			Type:    ErrorTypeInvalidArgument,
+			Message: "either argument " + req.arg + " or " + flags + " is required",
+			msgID:   MsgArgOrFlagRequired,
+			msgArgs: []any{req.arg, flags},
+		}
+	}
+	return nil
+}
+
+// requirementMet reports whether the argument or one of the flags of req is given
+func (p *Parser) requirementMet(result *ParseResult, args []*Arg, req argFlagRequirement) bool {
+	for _, arg := range args {
+		if arg.Name == req.arg && p.argProvided(arg) {
+			return true
+		}
+	}
+	for _, flag := range req.flags {
+		if flagProvided(result, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// argProvided reports whether the command line reached the argument's
+// position, i.e. its value is not a default
+func (p *Parser) argProvided(arg *Arg) bool {
+	return len(p.argsBuffer) > arg.Position
+}
+
+// flagProvided reports whether the flag was given on the command line or
+// through its environment variable; defaults and bool flags set to false
+// do not count
+func flagProvided(result *ParseResult, name string) bool {
+	if src := result.Source(name); src != ValueSourceFlag && src != ValueSourceEnv {
+		return false
+	}
+	if value, ok := result.GetBool(name); ok {
+		return value
+	}
+	if value, ok := result.GetGlobalBool(name); ok {
+		return value
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package snap
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// ArgCompletion tells shell completion what kind of value an argument takes
+type ArgCompletion string
+
+const (
+	// CompleteAny leaves the argument to the shell's default completion.
+	CompleteAny ArgCompletion = ""
+	// CompleteChoices completes one of the argument's EnumValues.
+	CompleteChoices ArgCompletion = "choices"
+	// CompleteFile completes file paths.
+	CompleteFile ArgCompletion = "file"
+	// CompleteDir completes directory paths.
+	CompleteDir ArgCompletion = "dir"
+)
+
+// EnumArg adds a string argument accepting only one of values:
+//
+//	app.Command("service", "Control the service").
+//		EnumArg("action", "What to do", "start", "stop", "restart").Required()
+func (c *CommandBuilder) EnumArg(name, description string, values ...string) *ArgBuilder[string, *CommandBuilder] {
+	return withChoices(c.StringArg(name, description), values)
+}
+
+// FileArg adds a string argument holding a file path; add MustExist to
+// reject missing files
+func (c *CommandBuilder) FileArg(name, description string) *ArgBuilder[string, *CommandBuilder] {
+	return withCompletion(c.StringArg(name, description), CompleteFile)
+}
+
+// DirArg adds a string argument holding a directory path; add MustExist to
+// reject missing directories and files
+func (c *CommandBuilder) DirArg(name, description string) *ArgBuilder[string, *CommandBuilder] {
+	return withCompletion(c.StringArg(name, description), CompleteDir)
+}
+
+// EnumArg adds an app-level string argument accepting only one of values
+func (a *App) EnumArg(name, description string, values ...string) *ArgBuilder[string, *App] {
+	return withChoices(a.StringArg(name, description), values)
+}
+
+// FileArg adds an app-level string argument holding a file path
+func (a *App) FileArg(name, description string) *ArgBuilder[string, *App] {
+	return withCompletion(a.StringArg(name, description), CompleteFile)
+}
+
+// DirArg adds an app-level string argument holding a directory path
+func (a *App) DirArg(name, description string) *ArgBuilder[string, *App] {
+	return withCompletion(a.StringArg(name, description), CompleteDir)
+}
+
+// MustExist makes a FileArg or DirArg fail the parse when the path does not
+// exist (or, for DirArg, is not a directory)
+func (b *ArgBuilder[T, P]) MustExist() *ArgBuilder[T, P] {
+	b.arg.mustExist = true
+	return b
+}
+
+func withChoices[P any](b *ArgBuilder[string, P], values []string) *ArgBuilder[string, P] {
+	b.arg.EnumValues = values
+	return withCompletion(b, CompleteChoices)
+}
+
+func withCompletion[P any](b *ArgBuilder[string, P], completion ArgCompletion) *ArgBuilder[string, P] {
+	b.arg.Completion = completion
+	return b
+}
+
+// checkValue validates a command-line value of a string argument against
+// its EnumValues and, with MustExist, the file system
+func (a *Arg) checkValue(value string) error {
+	if a.EnumValues != nil && !slices.Contains(a.EnumValues, value) {
+		choices := strings.Join(a.EnumValues, ", ")
+		return &ParseError{
+			Type:    ErrorTypeInvalidArgument,
+			Message: "invalid value for argument " + a.Name + ": " + value + ", valid values: " + choices,
+			msgID:   MsgInvalidArgEnumValue,
+			msgArgs: []any{a.Name, value, choices},
+		}
+	}
+	if !a.mustExist {
+		return nil
+	}
+
+	info, err := os.Stat(value)
+	switch {
+	case os.IsNotExist(err):
+		return a.pathError(MsgArgPathNotFound, value, "does not exist")
+	case err != nil:
+		return &ParseError{
+			Type:    ErrorTypeInvalidArgument,
+			Message: "argument " + a.Name + ": " + err.Error(),
+			msgID:   MsgArgPathError,
+			msgArgs: []any{a.Name, err},
+		}
+	case a.Completion == CompleteDir && !info.IsDir():
+		return a.pathError(MsgArgNotADirectory, value, "is not a directory")
+	}
+	return nil
+}
+
+// pathError reports a FileArg or DirArg path rejected by MustExist
+func (a *Arg) pathError(id MessageID, path, problem string) error {
+	return &ParseError{
+		Type:    ErrorTypeInvalidArgument,
+		Message: "argument " + a.Name + ": " + path + " " + problem,
+		msgID:   id,
+		msgArgs: []any{a.Name, path},
+	}
+}
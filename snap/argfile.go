@@ -0,0 +1,204 @@
+package snap
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// maxArgumentFileDepth bounds nested @file expansion (and breaks include cycles)
+const maxArgumentFileDepth = 8
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// AllowArgumentFiles enables response files: a token like @build-args.txt is
+// replaced in place by the arguments read from that file before parsing.
+// Arguments are separated by whitespace or newlines; single quotes keep text
+// literal, double quotes allow \" and \\ escapes, and outside quotes a
+// backslash escapes only whitespace and quotes, so Windows paths survive
+// unquoted. Files may reference further @files (up to 8 levels). "@@x" passes
+// the literal "@x"; nothing after "--" is expanded, nor are "@path" values of
+// AllowFromFile flags.
+func (a *App) AllowArgumentFiles(enabled bool) *App {
+	a.allowArgumentFiles = enabled
+	return a
+}
+
+// expandArgumentFiles applies AllowArgumentFiles expansion to args
+func (a *App) expandArgumentFiles(args []string) ([]string, error) {
+	if !a.allowArgumentFiles || !hasArgumentFileToken(args) {
+		return args, nil
+	}
+	return a.expandArgs(args, 0)
+}
+
+// hasArgumentFileToken reports whether any token needs expansion, so the
+// common case returns args untouched
+func hasArgumentFileToken(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if len(arg) > 1 && arg[0] == '@' {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) expandArgs(args []string, depth int) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return append(expanded, args[i:]...), nil
+		case len(arg) < 2 || arg[0] != '@':
+			expanded = append(expanded, arg)
+		case i > 0 && a.takesFileValue(args[i-1]):
+			expanded = append(expanded, arg) // Including "@@x", which the flag unescapes
+		case arg[1] == '@':
+			expanded = append(expanded, arg[1:])
+		default:
+			if depth >= maxArgumentFileDepth {
+				return nil, &ParseError{
+					Type:    ErrorTypeInvalidArgument,
+					Message: "argument files nested too deeply at " + arg,
+					msgID:   MsgArgFileTooDeep,
+					msgArgs: []any{arg},
+				}
+			}
+			fileArgs, err := readArgumentFile(arg[1:])
+			if err != nil {
+				return nil, err
+			}
+			nested, err := a.expandArgs(fileArgs, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, nested...)
+		}
+	}
+	return expanded, nil
+}
+
+// takesFileValue reports whether token is a flag declared with AllowFromFile
+// that expects its value in the next argument ("--cert @ca.pem")
+func (a *App) takesFileValue(token string) bool {
+	var long string
+	var short rune
+	switch {
+	case strings.HasPrefix(token, "--") && !strings.Contains(token, "="):
+		long = token[2:]
+	case len(token) == 2 && token[0] == '-':
+		short = rune(token[1])
+	default:
+		return false
+	}
+
+	match := func(flags map[string]*Flag, shorts map[rune]*Flag) bool {
+		if long != "" {
+			f := flags[long]
+			return f != nil && f.AllowFromFile
+		}
+		f := shorts[short]
+		return f != nil && f.AllowFromFile
+	}
+	if match(a.flags, a.shortFlags) {
+		return true
+	}
+	var walk func(commands map[string]*Command) bool
+	walk = func(commands map[string]*Command) bool {
+		for _, cmd := range commands {
+			if match(cmd.flags, cmd.shortFlags) || walk(cmd.subcommands) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(a.commands)
+}
+
+// readArgumentFile reads and tokenizes one response file
+func readArgumentFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ParseError{
+			Type:    ErrorTypeInvalidArgument,
+			Message: "cannot read argument file: " + err.Error(),
+			msgID:   MsgArgFileUnreadable,
+			msgArgs: []any{err},
+		}
+	}
+	args, err := splitArgumentFile(string(data))
+	if err != nil {
+		return nil, &ParseError{
+			Type:    ErrorTypeInvalidArgument,
+			Message: "invalid argument file " + path + ": " + err.Error(),
+			msgID:   MsgArgFileInvalid,
+			msgArgs: []any{path, err},
+		}
+	}
+	return args, nil
+}
+
+// splitArgumentFile splits response file content into arguments using
+// shell-like quoting (no variable or glob expansion)
+func splitArgumentFile(content string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				current.WriteRune(runes[i])
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inToken = true
+		case r == '\\' && i+1 < len(runes) && isArgumentFileEscapable(runes[i+1]):
+			i++
+			current.WriteRune(runes[i])
+			inToken = true
+		case unicode.IsSpace(r):
+			if inToken {
+				args = append(args, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errUnterminatedQuote
+	}
+	if inToken {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// isArgumentFileEscapable reports whether an unquoted backslash escapes r
+func isArgumentFileEscapable(r rune) bool {
+	return unicode.IsSpace(r) || r == '"' || r == '\''
+}
@@ -0,0 +1,84 @@
+package snap
+
+import "strconv"
+
+// MinCount requires a variadic argument to collect at least n values when
+// it is given; a Required one always needs n:
+//
+//	cmd.StringSliceArg("files", "Files to merge").MinCount(2).MaxCount(10).Variadic()
+func (b *ArgBuilder[T, P]) MinCount(n int) *ArgBuilder[T, P] {
+	b.arg.minCount = max(n, 0)
+	return b
+}
+
+// MaxCount limits a variadic argument to at most n values (0: no limit)
+func (b *ArgBuilder[T, P]) MaxCount(n int) *ArgBuilder[T, P] {
+	b.arg.maxCount = max(n, 0)
+	return b
+}
+
+// MinRestArgs requires at least n arguments in RestArgs, enabling RestArgs
+// if needed
+func (c *CommandBuilder) MinRestArgs(n int) *CommandBuilder {
+	c.command.hasRestArgs = true
+	c.command.restMin = max(n, 0)
+	return c
+}
+
+// MaxRestArgs allows at most n arguments in RestArgs (0: no limit),
+// enabling RestArgs if needed
+func (c *CommandBuilder) MaxRestArgs(n int) *CommandBuilder {
+	c.command.hasRestArgs = true
+	c.command.restMax = max(n, 0)
+	return c
+}
+
+// MinRestArgs requires at least n arguments in RestArgs, enabling RestArgs
+// if needed
+func (a *App) MinRestArgs(n int) *App {
+	a.hasRestArgs = true
+	a.restMin = max(n, 0)
+	a.invalidateHelp()
+	return a
+}
+
+// MaxRestArgs allows at most n arguments in RestArgs (0: no limit),
+// enabling RestArgs if needed
+func (a *App) MaxRestArgs(n int) *App {
+	a.hasRestArgs = true
+	a.restMax = max(n, 0)
+	a.invalidateHelp()
+	return a
+}
+
+// checkVariadicCount enforces MinCount and MaxCount on the n values of a
+// variadic argument; an optional argument may also be left out entirely
+func checkVariadicCount(arg *Arg, n int) error {
+	switch {
+	case n < arg.minCount && (n > 0 || arg.Required):
+		return countError(MsgTooFewArgValues, "too few values for argument "+arg.Name+": got "+
+			strconv.Itoa(n)+", expected at least "+strconv.Itoa(arg.minCount), arg.Name, n, arg.minCount)
+	case arg.maxCount > 0 && n > arg.maxCount:
+		return countError(MsgTooManyArgValues, "too many values for argument "+arg.Name+": got "+
+			strconv.Itoa(n)+", expected at most "+strconv.Itoa(arg.maxCount), arg.Name, n, arg.maxCount)
+	}
+	return nil
+}
+
+// checkRestCount enforces MinRestArgs and MaxRestArgs on n rest arguments
+func checkRestCount(minCount, maxCount, n int) error {
+	switch {
+	case n < minCount:
+		return countError(MsgTooFewRestArgs, "too few extra arguments: got "+strconv.Itoa(n)+
+			", expected at least "+strconv.Itoa(minCount), n, minCount)
+	case maxCount > 0 && n > maxCount:
+		return countError(MsgTooManyRestArgs, "too many extra arguments: got "+strconv.Itoa(n)+
+			", expected at most "+strconv.Itoa(maxCount), n, maxCount)
+	}
+	return nil
+}
+
+// countError reports an argument count outside its bounds
+func countError(id MessageID, message string, args ...any) error {
+	return &ParseError{Type: ErrorTypeInvalidArgument, Message: message, msgID: id, msgArgs: args}
+}
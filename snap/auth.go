@@ -0,0 +1,197 @@
+package snap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	snapio "github.com/dzonerzy/go-snap/io"
+	"github.com/dzonerzy/go-snap/snapcreds"
+)
+
+// AuthBuilder configures the built-in "auth" command and the credential
+// store behind Context.Credential
+type AuthBuilder struct {
+	app         *App
+	service     string
+	backend     snapcreds.Backend
+	credentials map[string]string // name -> description
+}
+
+// Auth enables an "auth" command with "login [name]" and "logout [name]"
+// subcommands that store and remove named secrets in the OS credential store
+// (see snapcreds), for other commands to read with Context.Credential:
+//
+//	app.Auth().Credential("registry", "Registry access token")
+//	// echo $TOKEN | myapp auth login registry
+//	token, err := ctx.Credential("registry")
+//
+// login prompts for the secret without echo on a terminal and otherwise
+// reads one line from stdin. Like the doctor command it is added at run time
+// unless the app already defines an "auth" command.
+func (a *App) Auth() *AuthBuilder {
+	if a.auth == nil {
+		a.auth = &AuthBuilder{app: a, credentials: make(map[string]string)}
+	}
+	return a.auth
+}
+
+// Credential declares a credential login and logout accept; the description
+// is the login prompt. Without declarations any name is accepted.
+func (b *AuthBuilder) Credential(name, description string) *AuthBuilder {
+	b.credentials[name] = description
+	return b
+}
+
+// Service sets the service secrets are stored under (the app name by default)
+func (b *AuthBuilder) Service(service string) *AuthBuilder {
+	b.service = service
+	return b
+}
+
+// Backend replaces the OS credential store, e.g. with snapcreds.Memory() in tests
+func (b *AuthBuilder) Backend(backend snapcreds.Backend) *AuthBuilder {
+	b.backend = backend
+	return b
+}
+
+// credentialStore returns the backend and service secrets are kept under
+func (a *App) credentialStore() (snapcreds.Backend, string) {
+	backend, service := snapcreds.System(), a.name
+	if a.auth != nil {
+		if a.auth.backend != nil {
+			backend = a.auth.backend
+		}
+		if a.auth.service != "" {
+			service = a.auth.service
+		}
+	}
+	return backend, service
+}
+
+// Credential returns the secret stored by "auth login name". When nothing is
+// stored the error wraps snapcreds.ErrNotFound and tells the user how to log in.
+func (c *Context) Credential(name string) (string, error) {
+	backend, service := c.App.credentialStore()
+	secret, err := backend.Load(service, name)
+	if errors.Is(err, snapcreds.ErrNotFound) {
+		return "", fmt.Errorf("not logged in to %s (run '%s auth login %s'): %w", name, c.App.name, name, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read credential %s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// addAuthCommand registers the command enabled by Auth
+func (a *App) addAuthCommand() {
+	if a.auth == nil {
+		return
+	}
+	if _, exists := a.commands["auth"]; exists {
+		return
+	}
+	auth := a.Command("auth", "Manage stored credentials")
+	auth.Command("login", "Store a credential").
+		StringArg("name", "Credential name").Back().
+		Action(a.auth.login)
+	auth.Command("logout", "Remove a stored credential").
+		StringArg("name", "Credential name").Back().
+		Action(a.auth.logout)
+}
+
+// login reads a secret and stores it
+func (b *AuthBuilder) login(ctx *Context) error {
+	name, err := b.credentialName(ctx)
+	if err != nil {
+		return err
+	}
+	prompt := b.credentials[name]
+	if prompt == "" {
+		prompt = name
+	}
+	secret, err := readSecret(ctx.IO(), prompt)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return NewError(ErrorTypeValidation, "empty credential for "+name)
+	}
+	backend, service := b.app.credentialStore()
+	if err := backend.Store(service, name, secret); err != nil {
+		return fmt.Errorf("cannot store credential %s: %w", name, err)
+	}
+	fmt.Fprintf(ctx.Stderr(), "Logged in to %s\n", name)
+	return nil
+}
+
+// logout removes a stored secret; removing one that is not stored succeeds
+func (b *AuthBuilder) logout(ctx *Context) error {
+	name, err := b.credentialName(ctx)
+	if err != nil {
+		return err
+	}
+	backend, service := b.app.credentialStore()
+	if err := backend.Delete(service, name); err != nil && !errors.Is(err, snapcreds.ErrNotFound) {
+		return fmt.Errorf("cannot remove credential %s: %w", name, err)
+	}
+	fmt.Fprintf(ctx.Stderr(), "Logged out of %s\n", name)
+	return nil
+}
+
+// credentialName returns the name argument, which may be omitted when a
+// single credential is declared
+func (b *AuthBuilder) credentialName(ctx *Context) (string, error) {
+	name := ctx.MustArgString("name", "")
+	names := sortedKeys(b.credentials)
+	_, declared := b.credentials[name]
+
+	switch {
+	case name == "" && len(names) == 1:
+		return names[0], nil
+	case name == "":
+		return "", NewError(ErrorTypeMissingRequired, "missing credential name").
+			WithSuggestion("Credentials: " + strings.Join(names, ", "))
+	case len(names) > 0 && !declared:
+		return "", NewError(ErrorTypeValidation, "unknown credential "+name).
+			WithSuggestion("Credentials: " + strings.Join(names, ", "))
+	}
+	return name, nil
+}
+
+// readSecret prompts for a secret without echoing it on a terminal; from
+// pipes it reads up to the end of the first line
+func readSecret(ioManager *snapio.IOManager, prompt string) (string, error) {
+	if ioManager.IsInteractive() {
+		fmt.Fprintf(ioManager.Err(), "%s: ", prompt)
+		defer fmt.Fprintln(ioManager.Err())
+	}
+	var secret []rune
+	err := ioManager.RawMode(func(term *snapio.Terminal) error {
+		for {
+			key, err := term.ReadKey()
+			if errors.Is(err, io.EOF) {
+				return nil // end of input ends the secret
+			}
+			if err != nil {
+				return err
+			}
+			switch key.Code {
+			case snapio.KeyEnter:
+				return nil
+			case snapio.KeyBackspace:
+				if len(secret) > 0 {
+					secret = secret[:len(secret)-1]
+				}
+			case snapio.KeyCtrl:
+				if key.Rune == 'c' || key.Rune == 'd' {
+					return errors.New("login canceled")
+				}
+			case snapio.KeyRune:
+				secret = append(secret, key.Rune)
+			}
+		}
+	})
+	return string(secret), err
+}
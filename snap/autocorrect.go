@@ -0,0 +1,85 @@
+package snap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/internal/fuzzy"
+)
+
+// AutoCorrectMode selects what happens when an unknown command has exactly
+// one close match ("sttus" for "status")
+type AutoCorrectMode int
+
+// Auto-correct modes
+const (
+	AutoCorrectOff    AutoCorrectMode = iota // Report the error with a suggestion (default)
+	AutoCorrectPrompt                        // Ask "Did you mean 'status'? [y/N]" on an interactive terminal
+	AutoCorrectAlways                        // Run the match after printing a notice on stderr
+)
+
+// AutoCorrect runs the single close match of a mistyped command instead of
+// only reporting it. AutoCorrectPrompt asks first and falls back to the usual
+// error when stdin is not interactive; ambiguous typos always error.
+func (a *App) AutoCorrect(mode AutoCorrectMode) *App {
+	a.autoCorrect = mode
+	return a
+}
+
+// correctArgs returns args with the unknown command of err replaced by its
+// only close match, or false when there is nothing to correct or the user declined
+func (a *App) correctArgs(err error, args []string) ([]string, bool) {
+	var parseErr *ParseError
+	if a.autoCorrect == AutoCorrectOff || !errors.As(err, &parseErr) ||
+		parseErr.Type != ErrorTypeUnknownCommand || parseErr.Index >= len(args) || args[parseErr.Index] != parseErr.Command {
+		return nil, false
+	}
+
+	commands := a.commands
+	if parseErr.CurrentCommand != nil {
+		commands = parseErr.CurrentCommand.subcommands
+	}
+	var candidates []string
+	for name, cmd := range commands {
+		if cmd.isHidden() {
+			continue
+		}
+		candidates = append(candidates, name)
+		candidates = append(candidates, cmd.Aliases...)
+	}
+	matches := fuzzy.FindSuggestions(parseErr.Command, candidates, a.errorHandler.maxDistance, 2)
+	if len(matches) != 1 {
+		return nil, false
+	}
+	match := matches[0]
+
+	switch a.autoCorrect {
+	case AutoCorrectPrompt:
+		if !a.IO().IsInteractive() || !a.confirmCorrection(a.IO().In(), match) {
+			return nil, false
+		}
+	case AutoCorrectAlways:
+		fmt.Fprintln(a.IO().Err(), a.msgf(MsgAutoCorrectNotice, parseErr.Command, match))
+	case AutoCorrectOff:
+	}
+
+	corrected := slices.Clone(args)
+	corrected[parseErr.Index] = match
+	return corrected, true
+}
+
+// confirmCorrection asks whether to run match and reads the answer from in
+func (a *App) confirmCorrection(in io.Reader, match string) bool {
+	fmt.Fprint(a.IO().Err(), a.msgf(MsgAutoCorrectPrompt, match)+" ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+package snap
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// CaptureOutput tees everything written through ctx.Stdout()/ctx.Stderr()
+// during a run (including context logging and passthrough wrapper output)
+// into buffers readable from After hooks via ctx.CapturedStdout() and
+// ctx.CapturedStderr(). Output is still written to the real destinations.
+func (a *App) CaptureOutput(enabled bool) *App {
+	a.captureOutput = enabled
+	return a
+}
+
+// outputCapture holds the buffers of one captured run
+type outputCapture struct {
+	stdout, stderr lockedBuffer
+}
+
+// lockedBuffer is a bytes.Buffer safe for the concurrent writers of parallel wrappers
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// bytes returns a copy of the buffered output
+func (b *lockedBuffer) bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
+
+// captureOutput routes the context's stdout and stderr through capture buffers
+func (c *Context) captureOutput() {
+	c.captured = &outputCapture{}
+	m := c.IO()
+	scoped := c.withIO(m.Clone().
+		WithOut(teeWriter(m.Out(), &c.captured.stdout)).
+		WithErr(teeWriter(m.Err(), &c.captured.stderr)))
+	c.io, c.logger = scoped.io, scoped.logger
+}
+
+// teeWriter writes to w and the capture buffer; a nil w only captures
+func teeWriter(w io.Writer, buf *lockedBuffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
+}
+
+// CapturedStdout returns what was written to ctx.Stdout() so far in this run,
+// or nil unless the app enabled CaptureOutput
+func (c *Context) CapturedStdout() []byte {
+	if c.captured == nil {
+		return nil
+	}
+	return c.captured.stdout.bytes()
+}
+
+// CapturedStderr returns what was written to ctx.Stderr() so far in this run,
+// or nil unless the app enabled CaptureOutput
+func (c *Context) CapturedStderr() []byte {
+	if c.captured == nil {
+		return nil
+	}
+	return c.captured.stderr.bytes()
+}
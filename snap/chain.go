@@ -0,0 +1,157 @@
+package snap
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ChainPolicy decides how a chained invocation goes on after a command fails
+type ChainPolicy int
+
+const (
+	// ChainStopOnError skips the remaining commands and returns the error.
+	ChainStopOnError ChainPolicy = iota
+	// ChainContinue runs every command and returns a *ChainError listing the failures.
+	ChainContinue
+)
+
+// ChainFailure is one failed command of a chained invocation
+type ChainFailure struct {
+	Args []string // The command's arguments, without the shared leading flags
+	Err  error
+
+	command *Command // The command that ran, for exit code mappings
+}
+
+// ChainError reports the failed commands of an invocation chained with
+// ChainContinue. Its exit code is the highest of the failures.
+type ChainError struct {
+	Failures []ChainFailure
+	Total    int // Number of chained commands
+}
+
+// Error lists the failed commands with their errors
+func (e *ChainError) Error() string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(e.Failures)) + " of " + strconv.Itoa(e.Total) + " chained commands failed")
+	for _, f := range e.Failures {
+		b.WriteString("; " + QuoteArgs(f.Args) + ": " + f.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the failed commands
+func (e *ChainError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// AllowChaining lets one invocation run several commands separated by
+// delimiter, one after the other:
+//
+//	app.AllowChaining(";", snap.ChainStopOnError)
+//	// myapp --verbose fmt \; vet \; build
+//
+// Flags before the first command (--verbose) apply to every command, also
+// when they stand alone before the first delimiter. Tokens
+// after "--" are never split, so wrapped tools still receive a literal
+// delimiter. An empty delimiter turns chaining off.
+func (a *App) AllowChaining(delimiter string, policy ChainPolicy) *App {
+	a.chainDelimiter = delimiter
+	a.chainPolicy = policy
+	return a
+}
+
+// chainSegments splits args at the chain delimiter; nil when chaining is
+// off or args hold a single command
+func (a *App) chainSegments(args []string) [][]string {
+	if a.chainDelimiter == "" {
+		return nil
+	}
+	var segments [][]string
+	start := 0
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == a.chainDelimiter {
+			segments = append(segments, args[start:i])
+			start = i + 1
+		}
+	}
+	if segments == nil {
+		return nil
+	}
+	segments = append(segments, args[start:])
+	return slices.DeleteFunc(segments, func(segment []string) bool { return len(segment) == 0 })
+}
+
+// runChain runs the chained commands in order under the chain policy
+func (a *App) runChain(ctx context.Context, segments [][]string) error {
+	if len(segments) == 0 {
+		return a.runOnce(ctx, nil)
+	}
+	shared := slices.Clip(a.leadingFlags(segments[0]))
+	if len(shared) == len(segments[0]) && len(segments) > 1 {
+		// "--verbose \; fmt": the first segment only holds the shared flags
+		segments = segments[1:]
+	} else {
+		segments[0] = segments[0][len(shared):]
+	}
+	chainErr := &ChainError{Total: len(segments)}
+	for i, segment := range segments {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		args := append(shared, segment...)
+		if a.tracer != nil {
+			a.tracer.printf("chain", "command %d of %d: %q", i+1, len(segments), a.redactArgs(args))
+		}
+
+		err := a.runOnce(ctx, args)
+		if err == nil {
+			continue
+		}
+		if a.chainPolicy == ChainStopOnError {
+			return err
+		}
+		failure := ChainFailure{Args: segment, Err: err}
+		if a.currentResult != nil {
+			failure.command = a.currentResult.Command
+		}
+		chainErr.Failures = append(chainErr.Failures, failure)
+	}
+	if len(chainErr.Failures) > 0 {
+		return chainErr
+	}
+	return nil
+}
+
+// leadingFlags returns the app flags (with their values) before the
+// command name in args
+func (a *App) leadingFlags(args []string) []string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			return args[:i]
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		var flag *Flag
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			flag = a.flags[name]
+		} else if len(arg) == 2 {
+			flag = a.shortFlags[rune(arg[1])]
+		}
+		if flag != nil && flag.RequiresValue() {
+			i++
+		}
+	}
+	return args
+}
@@ -1,8 +1,11 @@
 package snap
 
 import (
+	"io"
+	"reflect"
 	"time"
 
+	snapio "github.com/dzonerzy/go-snap/io"
 	"github.com/dzonerzy/go-snap/middleware"
 )
 
@@ -19,11 +22,99 @@ type Command struct {
 	flagGroups   []*FlagGroup // Flag groups for validation
 	args         []*Arg       // Positional arguments (ordered by position)
 	hasRestArgs  bool         // If true, collect all remaining args after declared args
+	restMode     restArgsMode // When flag parsing stops with RestArgs
 	Action       ActionFunc
-	beforeAction ActionFunc              // Runs before the action
-	afterAction  ActionFunc              // Runs after the action
-	middleware   []middleware.Middleware // Command-level middleware
-	wrapper      *WrapperSpec            // Optional wrapper configuration
+	beforeAction ActionFunc                      // Runs before the action
+	afterAction  ActionFunc                      // Runs after the action
+	middleware   []middleware.Middleware         // Command-level middleware
+	wrapper      *WrapperSpec                    // Optional wrapper configuration
+	parent       *Command                        // Enclosing command for subcommands (nil at top level)
+	usageFunc    func(*Command, io.Writer) error // Replaces the help output (see UsageFunc)
+
+	// Middleware inheritance: inheritMiddleware shares this command's middleware
+	// with its subcommands; noInheritMiddleware opts this command out of its ancestors'
+	inheritMiddleware   bool
+	noInheritMiddleware bool
+
+	// Runtime predicates (see VisibleIf / EnabledIf)
+	visibleIf func() bool
+	enabledIf func() bool
+
+	// Per-command IO and logger settings, applied to a copy of the app's
+	// (see CommandBuilder.IO / CommandBuilder.Logger)
+	ioConfig     []func(*snapio.IOManager)
+	loggerConfig []func(*snapio.Logger)
+
+	// Arguments that may be replaced by a flag (see RequireArgsOrFlag)
+	argsOrFlag []argFlagRequirement
+
+	// Number of RestArgs accepted (see MinRestArgs / MaxRestArgs; 0: no limit)
+	restMin int
+	restMax int
+
+	// Experiment the command belongs to (see Experimental)
+	experiment  string
+	experiments *Experiments
+
+	// Exit code mappings taking precedence over the app's (see ExitCodes)
+	exitCodes *ExitCodeManager
+
+	// Unknown flags are collected instead of failing (see CollectUnknownFlags)
+	collectUnknown bool
+}
+
+// isHidden reports whether the command is left out of help and suggestions
+func (c *Command) isHidden() bool {
+	return c.Hidden || (c.visibleIf != nil && !c.visibleIf()) || !c.isEnabled()
+}
+
+// usage returns the UsageFunc of the command or of its nearest ancestor that sets one
+func (c *Command) usage() func(*Command, io.Writer) error {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.usageFunc != nil {
+			return cmd.usageFunc
+		}
+	}
+	return nil
+}
+
+// isEnabled reports whether the command may be run in this process
+func (c *Command) isEnabled() bool {
+	return (c.enabledIf == nil || c.enabledIf()) && experimentOn(c.experiment, c.experiments)
+}
+
+// scopesIO reports whether this command or an ancestor customizes IO or logging
+func (c *Command) scopesIO() bool {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if len(cmd.ioConfig) > 0 || len(cmd.loggerConfig) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// commandMiddleware returns the middleware wrapping this command: inherited
+// middleware from ancestors (outermost first) followed by its own
+func (c *Command) commandMiddleware() []middleware.Middleware {
+	if c.noInheritMiddleware || c.parent == nil {
+		return c.middleware
+	}
+	var chain []middleware.Middleware
+	for _, ancestor := range c.ancestors() {
+		if ancestor.inheritMiddleware {
+			chain = append(chain, ancestor.middleware...)
+		}
+	}
+	return append(chain, c.middleware...)
+}
+
+// ancestors returns the enclosing commands from the top level down
+func (c *Command) ancestors() []*Command {
+	var chain []*Command
+	for p := c.parent; p != nil; p = p.parent {
+		chain = append([]*Command{p}, chain...)
+	}
+	return chain
 }
 
 // Name returns the command name (implements middleware.Command interface)
@@ -36,6 +127,11 @@ func (c *Command) Description() string {
 	return c.description
 }
 
+// Wrapper returns the command's wrapper configuration, or nil if it wraps no binary
+func (c *Command) Wrapper() *WrapperSpec {
+	return c.wrapper
+}
+
 // CommandBuilder provides fluent API for building commands
 type CommandBuilder struct {
 	command *Command
@@ -47,6 +143,9 @@ type CommandBuilder struct {
 // Alias adds aliases for the command
 func (c *CommandBuilder) Alias(aliases ...string) *CommandBuilder {
 	c.command.Aliases = append(c.command.Aliases, aliases...)
+	if c.app != nil {
+		c.app.invalidateHelp()
+	}
 	return c
 }
 
@@ -59,6 +158,30 @@ func (c *CommandBuilder) Action(fn ActionFunc) *CommandBuilder {
 // Hidden marks the command as hidden from help
 func (c *CommandBuilder) Hidden() *CommandBuilder {
 	c.command.Hidden = true
+	if c.app != nil {
+		c.app.invalidateHelp()
+	}
+	return c
+}
+
+// VisibleIf hides the command from help and suggestions while pred returns
+// false; the command can still be run (e.g. feature-flagged previews)
+func (c *CommandBuilder) VisibleIf(pred func() bool) *CommandBuilder {
+	c.command.visibleIf = pred
+	if c.app != nil {
+		c.app.invalidateHelp()
+	}
+	return c
+}
+
+// EnabledIf makes the command available only while pred returns true (e.g.
+// Windows-only commands). When disabled it is hidden and rejected at parse
+// time with an "unavailable" error.
+func (c *CommandBuilder) EnabledIf(pred func() bool) *CommandBuilder {
+	c.command.enabledIf = pred
+	if c.app != nil {
+		c.app.invalidateHelp()
+	}
 	return c
 }
 
@@ -68,12 +191,53 @@ func (c *CommandBuilder) HelpText(help string) *CommandBuilder {
 	return c
 }
 
+// UsageFunc replaces the help output of this command and of its subcommands
+// that do not set their own. It runs for --help, -h, "help COMMAND" and
+// ShowHelpOnError; the output goes through HelpPager like the built-in help.
+func (c *CommandBuilder) UsageFunc(fn func(cmd *Command, w io.Writer) error) *CommandBuilder {
+	c.command.usageFunc = fn
+	return c
+}
+
 // Use adds middleware to the command
 func (c *CommandBuilder) Use(middleware ...middleware.Middleware) *CommandBuilder {
 	c.command.middleware = append(c.command.middleware, middleware...)
 	return c
 }
 
+// IO customizes the IOManager seen by this command and its subcommands
+// through Context (ctx.IO(), ctx.Stdout(), logging, wrappers). fn receives a
+// copy of the app's IOManager on each run, so other commands are unaffected:
+//
+//	app.Command("list", "List items").
+//	    IO(func(io *snapio.IOManager) { io.NoColor() })
+func (c *CommandBuilder) IO(fn func(io *snapio.IOManager)) *CommandBuilder {
+	c.command.ioConfig = append(c.command.ioConfig, fn)
+	return c
+}
+
+// Logger customizes the logger used by this command and its subcommands
+// (ctx.Logger(), ctx.LogInfo, ...). fn receives a copy of the app's logger on
+// each run, e.g. to send all levels to stderr with AllToStderr(true).
+func (c *CommandBuilder) Logger(fn func(logger *snapio.Logger)) *CommandBuilder {
+	c.command.loggerConfig = append(c.command.loggerConfig, fn)
+	return c
+}
+
+// InheritMiddleware makes middleware attached to this command (via Use) also wrap
+// all of its subcommands. Ordering is app → parent command → child command.
+func (c *CommandBuilder) InheritMiddleware() *CommandBuilder {
+	c.command.inheritMiddleware = true
+	return c
+}
+
+// NoInheritMiddleware opts this command out of middleware inherited from parent
+// commands; app-level middleware still applies.
+func (c *CommandBuilder) NoInheritMiddleware() *CommandBuilder {
+	c.command.noInheritMiddleware = true
+	return c
+}
+
 // Before sets a function to run before the command action
 func (c *CommandBuilder) Before(fn ActionFunc) *CommandBuilder {
 	c.command.beforeAction = fn
@@ -86,6 +250,41 @@ func (c *CommandBuilder) After(fn ActionFunc) *CommandBuilder {
 	return c
 }
 
+// CollectUnknownFlags makes unrecognized flags of the command no error: they
+// are collected with their values, in order, for Context.UnknownFlags, e.g.
+// to pass template parameters on to another API. A flag written without "="
+// takes the next argument as its value unless that looks like a flag:
+//
+//	app.Command("render", "Render a template").CollectUnknownFlags().
+//		Action(func(ctx *snap.Context) error {
+//			// render --name web --replicas=3 -> ["--name" "web" "--replicas=3"]
+//			return render(ctx.UnknownFlags())
+//		})
+func (c *CommandBuilder) CollectUnknownFlags() *CommandBuilder {
+	c.command.collectUnknown = true
+	return c
+}
+
+// ExitCodes returns the command's exit code mappings. Errors of a run of the
+// command or one of its subcommands (hooks, middleware and action) resolve
+// through DefineError and DefineCLI mappings of the command first, then the
+// app's ExitCodes; defaults always come from the app:
+//
+//	app.Command("diff", "Compare files").
+//		ExitCodes().DefineError(ErrDifferent{}, 1).Describe(1, "files differ")
+func (c *CommandBuilder) ExitCodes() *ExitCodeManager {
+	if c.command.exitCodes == nil {
+		c.command.exitCodes = &ExitCodeManager{
+			codesByName:  make(map[string]int),
+			codesByType:  make(map[reflect.Type]int),
+			codesByCLI:   make(map[ErrorType]int),
+			descriptions: make(map[int]string),
+			app:          c.app,
+		}
+	}
+	return c.command.exitCodes
+}
+
 // Flag builders for command-specific flags
 
 // StringFlag adds a string flag to the command
@@ -95,6 +294,7 @@ func (c *CommandBuilder) StringFlag(name, description string) *FlagBuilder[strin
 		Description: description,
 		Type:        FlagTypeString,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[string, *CommandBuilder]{flag: flag, parent: c}
 }
@@ -106,10 +306,59 @@ func (c *CommandBuilder) IntFlag(name, description string) *FlagBuilder[int, *Co
 		Description: description,
 		Type:        FlagTypeInt,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[int, *CommandBuilder]{flag: flag, parent: c}
 }
 
+// Int64Flag adds an int64 flag to the command
+func (c *CommandBuilder) Int64Flag(name, description string) *FlagBuilder[int64, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt64,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[int64, *CommandBuilder]{flag: flag, parent: c}
+}
+
+// Int32Flag adds an int32 flag to the command
+func (c *CommandBuilder) Int32Flag(name, description string) *FlagBuilder[int32, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt32,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[int32, *CommandBuilder]{flag: flag, parent: c}
+}
+
+// UintFlag adds a uint flag to the command
+func (c *CommandBuilder) UintFlag(name, description string) *FlagBuilder[uint, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeUint,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[uint, *CommandBuilder]{flag: flag, parent: c}
+}
+
+// Uint64Flag adds a uint64 flag to the command
+func (c *CommandBuilder) Uint64Flag(name, description string) *FlagBuilder[uint64, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeUint64,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[uint64, *CommandBuilder]{flag: flag, parent: c}
+}
+
 // BoolFlag adds a boolean flag to the command
 func (c *CommandBuilder) BoolFlag(name, description string) *FlagBuilder[bool, *CommandBuilder] {
 	flag := &Flag{
@@ -117,10 +366,24 @@ func (c *CommandBuilder) BoolFlag(name, description string) *FlagBuilder[bool, *
 		Description: description,
 		Type:        FlagTypeBool,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[bool, *CommandBuilder]{flag: flag, parent: c}
 }
 
+// CountFlag adds an int flag counting its occurrences to the command (see App.CountFlag)
+func (c *CommandBuilder) CountFlag(name, description string) *FlagBuilder[int, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt,
+		counter:     true,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[int, *CommandBuilder]{flag: flag, parent: c}
+}
+
 // DurationFlag adds a duration flag to the command
 func (c *CommandBuilder) DurationFlag(name, description string) *FlagBuilder[time.Duration, *CommandBuilder] {
 	flag := &Flag{
@@ -128,6 +391,7 @@ func (c *CommandBuilder) DurationFlag(name, description string) *FlagBuilder[tim
 		Description: description,
 		Type:        FlagTypeDuration,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[time.Duration, *CommandBuilder]{flag: flag, parent: c}
 }
@@ -139,6 +403,7 @@ func (c *CommandBuilder) FloatFlag(name, description string) *FlagBuilder[float6
 		Description: description,
 		Type:        FlagTypeFloat,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[float64, *CommandBuilder]{flag: flag, parent: c}
 }
@@ -151,6 +416,7 @@ func (c *CommandBuilder) EnumFlag(name, description string, values ...string) *F
 		Type:        FlagTypeEnum,
 		EnumValues:  values,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[string, *CommandBuilder]{flag: flag, parent: c}
 }
@@ -162,6 +428,7 @@ func (c *CommandBuilder) StringSliceFlag(name, description string) *FlagBuilder[
 		Description: description,
 		Type:        FlagTypeStringSlice,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[[]string, *CommandBuilder]{flag: flag, parent: c}
 }
@@ -173,16 +440,58 @@ func (c *CommandBuilder) IntSliceFlag(name, description string) *FlagBuilder[[]i
 		Description: description,
 		Type:        FlagTypeIntSlice,
 	}
+	c.app.checkMutable("flag", name)
 	c.command.flags[name] = flag
 	return &FlagBuilder[[]int, *CommandBuilder]{flag: flag, parent: c}
 }
 
+// FloatSliceFlag adds a float64 slice flag to the command
+func (c *CommandBuilder) FloatSliceFlag(name, description string) *FlagBuilder[[]float64, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeFloatSlice,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[[]float64, *CommandBuilder]{flag: flag, parent: c}
+}
+
+// DurationSliceFlag adds a duration slice flag to the command
+func (c *CommandBuilder) DurationSliceFlag(name, description string) *FlagBuilder[[]time.Duration, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeDurationSlice,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[[]time.Duration, *CommandBuilder]{flag: flag, parent: c}
+}
+
+// EnumSliceFlag adds a multi-value enum flag to the command
+func (c *CommandBuilder) EnumSliceFlag(
+	name, description string,
+	values ...string,
+) *FlagBuilder[[]string, *CommandBuilder] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeEnumSlice,
+		EnumValues:  values,
+	}
+	c.app.checkMutable("flag", name)
+	c.command.flags[name] = flag
+	return &FlagBuilder[[]string, *CommandBuilder]{flag: flag, parent: c}
+}
+
 // Positional argument methods
 
 // StringArg adds a string positional argument to the command
 func (c *CommandBuilder) StringArg(name, description string) *ArgBuilder[string, *CommandBuilder] {
 	position := len(c.command.args)
 	builder := newStringArg(name, description, position, c)
+	c.app.checkMutable("argument", name)
 	c.command.args = append(c.command.args, builder.arg)
 	return builder
 }
@@ -191,6 +500,7 @@ func (c *CommandBuilder) StringArg(name, description string) *ArgBuilder[string,
 func (c *CommandBuilder) IntArg(name, description string) *ArgBuilder[int, *CommandBuilder] {
 	position := len(c.command.args)
 	builder := newIntArg(name, description, position, c)
+	c.app.checkMutable("argument", name)
 	c.command.args = append(c.command.args, builder.arg)
 	return builder
 }
@@ -199,6 +509,7 @@ func (c *CommandBuilder) IntArg(name, description string) *ArgBuilder[int, *Comm
 func (c *CommandBuilder) BoolArg(name, description string) *ArgBuilder[bool, *CommandBuilder] {
 	position := len(c.command.args)
 	builder := newBoolArg(name, description, position, c)
+	c.app.checkMutable("argument", name)
 	c.command.args = append(c.command.args, builder.arg)
 	return builder
 }
@@ -207,6 +518,7 @@ func (c *CommandBuilder) BoolArg(name, description string) *ArgBuilder[bool, *Co
 func (c *CommandBuilder) FloatArg(name, description string) *ArgBuilder[float64, *CommandBuilder] {
 	position := len(c.command.args)
 	builder := newFloatArg(name, description, position, c)
+	c.app.checkMutable("argument", name)
 	c.command.args = append(c.command.args, builder.arg)
 	return builder
 }
@@ -215,6 +527,7 @@ func (c *CommandBuilder) FloatArg(name, description string) *ArgBuilder[float64,
 func (c *CommandBuilder) DurationArg(name, description string) *ArgBuilder[time.Duration, *CommandBuilder] {
 	position := len(c.command.args)
 	builder := newDurationArg(name, description, position, c)
+	c.app.checkMutable("argument", name)
 	c.command.args = append(c.command.args, builder.arg)
 	return builder
 }
@@ -224,6 +537,7 @@ func (c *CommandBuilder) DurationArg(name, description string) *ArgBuilder[time.
 func (c *CommandBuilder) StringSliceArg(name, description string) *ArgBuilder[[]string, *CommandBuilder] {
 	position := len(c.command.args)
 	builder := newStringSliceArg(name, description, position, c)
+	c.app.checkMutable("argument", name)
 	c.command.args = append(c.command.args, builder.arg)
 	return builder
 }
@@ -233,6 +547,7 @@ func (c *CommandBuilder) StringSliceArg(name, description string) *ArgBuilder[[]
 func (c *CommandBuilder) IntSliceArg(name, description string) *ArgBuilder[[]int, *CommandBuilder] {
 	position := len(c.command.args)
 	builder := newIntSliceArg(name, description, position, c)
+	c.app.checkMutable("argument", name)
 	c.command.args = append(c.command.args, builder.arg)
 	return builder
 }
@@ -244,6 +559,24 @@ func (c *CommandBuilder) RestArgs() *CommandBuilder {
 	return c
 }
 
+// FlagsFirst enables RestArgs but keeps parsing the command's flags while
+// declared args are being filled; the first positional beyond them and
+// everything after it go to RestArgs unparsed
+func (c *CommandBuilder) FlagsFirst() *CommandBuilder {
+	c.command.hasRestArgs = true
+	c.command.restMode = restFlagsFirst
+	return c
+}
+
+// StopAtFirstPositional enables RestArgs but parses the command's flags up to
+// the first positional argument; from there on every token is positional
+// (docker run-style: "run --rm IMAGE --flag-for-image")
+func (c *CommandBuilder) StopAtFirstPositional() *CommandBuilder {
+	c.command.hasRestArgs = true
+	c.command.restMode = restStopAtFirstPositional
+	return c
+}
+
 // Subcommand builder
 
 // Command adds a subcommand to this command
@@ -258,7 +591,9 @@ func (c *CommandBuilder) Command(name, description string) *CommandBuilder {
 		subcommands: make(map[string]*Command),
 		flagGroups:  make([]*FlagGroup, 0),
 		middleware:  make([]middleware.Middleware, 0),
+		parent:      c.command,
 	}
+	c.app.checkMutable("command", name)
 	c.app.addCommandHelpFlag(cmd)
 	c.command.subcommands[name] = cmd
 	return &CommandBuilder{
@@ -271,15 +606,26 @@ func (c *CommandBuilder) Command(name, description string) *CommandBuilder {
 
 // addShortFlag adds a short flag mapping for O(1) lookup to the command
 func (c *CommandBuilder) addShortFlag(short rune, flag *Flag) {
+	c.app.checkMutable("short flag", string(short))
 	c.command.shortFlags[short] = flag
 }
 
 // addFlagGroup adds a flag group to the command (implements FlagGroupParent interface)
 func (c *CommandBuilder) addFlagGroup(group *FlagGroup) {
+	c.app.checkMutable("flag group", group.Name)
+	// Check if group already exists to prevent duplicates
+	for _, existingGroup := range c.command.flagGroups {
+		if existingGroup.Name == group.Name {
+			return // Group already added, skip
+		}
+	}
+
 	c.command.flagGroups = append(c.command.flagGroups, group)
+	c.app.invalidateHelp()
 
 	// Also add all flags in the group to the command's flag map for parsing
 	for _, flag := range group.Flags {
+		flag.groupHidden = group.Hidden
 		c.command.flags[flag.Name] = flag
 		if flag.Short != 0 {
 			c.command.shortFlags[flag.Short] = flag
@@ -1,12 +1,15 @@
 package snap
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -34,9 +37,12 @@ type FieldSchema struct {
 	EnumTag     string
 	GroupTag    string
 	IgnoreTag   string // ignore:"true" - skip flag generation
+	MergeTag    string // merge:"append|replace|union" - how slices/maps combine across sources
 	EnumValues  []string
 	GroupName   string
-	Ignored     bool // Parsed from IgnoreTag
+	Ignored     bool          // Parsed from IgnoreTag
+	Merge       MergeStrategy // Parsed from MergeTag
+	Secret      bool          // secret:"true" - redact the value like a Secret flag
 }
 
 // parseFlagTagOptions parses flag tag to extract name and options.
@@ -73,6 +79,10 @@ type GroupSchema struct {
 type ConfigSchema struct {
 	Fields map[string]*FieldSchema
 	Groups map[string]*GroupSchema // Enhanced group information
+
+	// Keys of files and defaults spelled with the json name where the schema
+	// key uses the flag tag, mapped to the schema key
+	aliases map[string]string
 }
 
 // ConfigBuilder provides fluent API for configuration management
@@ -83,6 +93,34 @@ type ConfigBuilder struct {
 	precedenceManager *PrecedenceManager
 	pendingSources    []func()
 	flagsEnabled      bool // Track if FromFlags() was called - enables CLI generation
+
+	// Named environments overlaying the file config (see Profiles)
+	profiles      []string
+	profileFlag   string
+	profileEnv    []string
+	activeProfile string
+
+	// Checks run after binding (see RegisterStructValidator / RequireOneOf)
+	structValidators []structValidator
+	requireOneOf     [][]string
+
+	// Config file flag read at run time (see FromFlagFile)
+	fileFlag      string
+	fileFlagShort string
+
+	// Files added by FromFile, in order (see Required)
+	files []*configFile
+
+	// User config file edited by the config command (see ConfigCommand)
+	userFile  string
+	configCmd *Command
+}
+
+// configFile is a file added with FromFile and the outcome of loading it
+type configFile struct {
+	path   string
+	policy FileErrorPolicy
+	err    error
 }
 
 // Config creates a standalone configuration builder with app name and description
@@ -128,6 +166,45 @@ func (cb *ConfigBuilder) Bind(target any) *ConfigBuilder {
 	return cb
 }
 
+// RegisterDecoder decodes fields of type t (e.g. url.URL or a custom ID
+// type) from the raw value of any source: the default tag and FromDefaults,
+// files, environment variables and flags. fn receives a string or, from
+// FromDefaults and JSON files, a number or bool, and returns a value of type t
+// (or a pointer to one). Fields with a decoder are settable through a string
+// flag. Register decoders before Bind:
+//
+//	cb.RegisterDecoder(reflect.TypeOf(url.URL{}), func(raw any) (any, error) {
+//		return url.Parse(fmt.Sprint(raw))
+//	})
+func (cb *ConfigBuilder) RegisterDecoder(t reflect.Type, fn func(raw any) (any, error)) *ConfigBuilder {
+	if cb.precedenceManager.decoders == nil {
+		cb.precedenceManager.decoders = make(map[reflect.Type]func(raw any) (any, error))
+	}
+	cb.precedenceManager.decoders[t] = fn
+	return cb
+}
+
+// decoded reports whether a decoder is registered for fields of type t
+func (cb *ConfigBuilder) decoded(t reflect.Type) bool {
+	_, ok := cb.precedenceManager.decoders[t]
+	return ok
+}
+
+// isNestedStruct reports whether fields of type t hold a nested config
+// section rather than a single value
+func (cb *ConfigBuilder) isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && !cb.decoded(t)
+}
+
+// flagKind returns the kind of flag generated for a field; decoded fields
+// take their raw value from a string flag
+func (cb *ConfigBuilder) flagKind(fieldSchema *FieldSchema) reflect.Kind {
+	if cb.decoded(fieldSchema.Type) {
+		return reflect.String
+	}
+	return fieldSchema.Type.Kind()
+}
+
 // FromDefaults adds default values as a configuration source
 // Usage: .FromDefaults(snap.D{"host": "localhost", "port": 8080})
 func (cb *ConfigBuilder) FromDefaults(defaults D) *ConfigBuilder {
@@ -141,24 +218,109 @@ func (cb *ConfigBuilder) FromDefaults(defaults D) *ConfigBuilder {
 	return cb
 }
 
-// FromFile adds file-based configuration source
-func (cb *ConfigBuilder) FromFile(filename string) *ConfigBuilder {
-	if cb.schema != nil {
-		data, err := cb.loadFromFile(filename)
-		if err == nil {
+// FileErrorPolicy is how FromFile treats a config file that does not exist
+// or is not in a supported format. Files that exist but cannot be read or
+// parsed always fail.
+type FileErrorPolicy int
+
+// ErrUnsupportedConfigFormat is wrapped by the *ConfigFileError of a config
+// file whose extension is not supported
+var ErrUnsupportedConfigFormat = errors.New("unsupported config format")
+
+const (
+	Silent         FileErrorPolicy = iota // Skip the file (the default)
+	WarnIfMissing                         // Skip the file, logging a warning
+	ErrorIfMissing                        // Fail resolving the configuration
+)
+
+// ConfigFileError reports a config file that could not be loaded. Line and
+// Column locate JSON syntax errors and a top level that is not an object (0
+// when not known); values of the wrong type for a field fail later, when the
+// configuration is resolved.
+type ConfigFileError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ConfigFileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("config file %s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("config file %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the read or decoding error
+func (e *ConfigFileError) Unwrap() error {
+	return e.Err
+}
+
+// FromFile adds file-based configuration source. It may be called several
+// times (e.g. config.json, then config.local.json): later files override
+// earlier ones. policy (Silent by default) decides what a missing file does;
+// a malformed file makes Build (config-only mode) or Run return a
+// *ConfigFileError.
+func (cb *ConfigBuilder) FromFile(filename string, policy ...FileErrorPolicy) *ConfigBuilder {
+	file := &configFile{path: filename}
+	if len(policy) > 0 {
+		file.policy = policy[0]
+	}
+	cb.files = append(cb.files, file)
+	load := func() {
+		var data map[string]any
+		data, file.err = cb.loadFromFile(filename)
+		if file.err == nil {
 			cb.precedenceManager.AddSource(SourceTypeFile, data)
 		}
+	}
+	if cb.schema != nil {
+		load()
 	} else {
-		cb.pendingSources = append(cb.pendingSources, func() {
-			data, err := cb.loadFromFile(filename)
-			if err == nil {
-				cb.precedenceManager.AddSource(SourceTypeFile, data)
-			}
-		})
+		cb.pendingSources = append(cb.pendingSources, load)
+	}
+	return cb
+}
+
+// Required makes a missing file of the last FromFile call an error
+// (ErrorIfMissing)
+func (cb *ConfigBuilder) Required() *ConfigBuilder {
+	return cb.setFilePolicy(ErrorIfMissing)
+}
+
+// Optional makes a missing file of the last FromFile call skipped (Silent,
+// the default)
+func (cb *ConfigBuilder) Optional() *ConfigBuilder {
+	return cb.setFilePolicy(Silent)
+}
+
+// setFilePolicy sets the policy of the file of the last FromFile call
+func (cb *ConfigBuilder) setFilePolicy(policy FileErrorPolicy) *ConfigBuilder {
+	if len(cb.files) > 0 {
+		cb.files[len(cb.files)-1].policy = policy
 	}
 	return cb
 }
 
+// checkFiles applies the file policies: it returns the first error of a
+// malformed file or a missing or unsupported ErrorIfMissing file and warns
+// about missing or unsupported WarnIfMissing files
+func (cb *ConfigBuilder) checkFiles() error {
+	for _, file := range cb.files {
+		unsupported := errors.Is(file.err, ErrUnsupportedConfigFormat)
+		switch {
+		case file.err == nil:
+		case !errors.Is(file.err, fs.ErrNotExist) && !unsupported, file.policy == ErrorIfMissing:
+			return file.err
+		case file.policy == WarnIfMissing && unsupported:
+			cb.app.Logger().Warning("%v, skipping", file.err)
+		case file.policy == WarnIfMissing:
+			cb.app.Logger().Warning("config file %s not found, skipping", file.path)
+		}
+	}
+	return nil
+}
+
 // FromEnv adds environment variable configuration source
 func (cb *ConfigBuilder) FromEnv() *ConfigBuilder {
 	if cb.schema != nil {
@@ -205,6 +367,8 @@ func (cb *ConfigBuilder) Build() (*App, error) {
 	if cb.flagsEnabled {
 		// CLI mode: generate flags and return App for later Run()
 		cb.generateFlags()
+		cb.addProfileFlag()
+		cb.addFileFlag()
 
 		// Store the config builder in the app for later use during Run()
 		cb.app.configBuilder = cb
@@ -223,6 +387,13 @@ func (cb *ConfigBuilder) buildConfigOnly() error {
 		addSource()
 	}
 
+	if err := cb.checkFiles(); err != nil {
+		return err
+	}
+	if err := cb.applyProfile(); err != nil {
+		return err
+	}
+
 	// Resolve configuration with precedence using the precedence manager
 	resolved, err := cb.precedenceManager.ResolveWithSchema(cb.schema)
 	if err != nil {
@@ -230,14 +401,18 @@ func (cb *ConfigBuilder) buildConfigOnly() error {
 	}
 
 	// Apply resolved configuration to target struct
-	return cb.applyToStruct(resolved)
+	if err := cb.applyToStruct(resolved); err != nil {
+		return err
+	}
+	return cb.validateConfig()
 }
 
 // generateSchema creates schema from struct reflection
 func (cb *ConfigBuilder) generateSchema(target any) *ConfigSchema {
 	schema := &ConfigSchema{
-		Fields: make(map[string]*FieldSchema),
-		Groups: make(map[string]*GroupSchema),
+		Fields:  make(map[string]*FieldSchema),
+		Groups:  make(map[string]*GroupSchema),
+		aliases: make(map[string]string),
 	}
 
 	targetType := reflect.TypeOf(target)
@@ -273,10 +448,12 @@ func (cb *ConfigBuilder) parseStructFieldsWithGroup(
 		}
 
 		fieldName := cb.getFieldName(field, prefix)
+		if jsonName := jsonFieldName(field); jsonName != "" && prefix+jsonName != fieldName {
+			schema.aliases[prefix+jsonName] = fieldName
+		}
 
 		// Handle nested structs
-		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) &&
-			fieldType != reflect.TypeOf(time.Duration(0)) {
+		if cb.isNestedStruct(fieldType) {
 			nestedGroupName := field.Tag.Get("group")
 			if nestedGroupName == "" {
 				nestedGroupName = strings.ToLower(field.Name)
@@ -306,7 +483,9 @@ func (cb *ConfigBuilder) parseStructFieldsWithGroup(
 			EnumTag:     field.Tag.Get("enum"),
 			GroupTag:    field.Tag.Get("group"),
 			IgnoreTag:   field.Tag.Get("ignore"),
+			MergeTag:    field.Tag.Get("merge"),
 		}
+		fieldSchema.Merge, _ = parseMergeTag(fieldSchema.MergeTag) // Unknown tags fail in resolveFields
 
 		// Parse ignore from flag options first, then fall back to separate ignore tag
 		if flagOptions["ignore"] {
@@ -320,6 +499,10 @@ func (cb *ConfigBuilder) parseStructFieldsWithGroup(
 			continue
 		}
 
+		if secretValue, secretExists := field.Tag.Lookup("secret"); secretExists {
+			fieldSchema.Secret = secretValue == "true" || secretValue == ""
+		}
+
 		// Parse required from flag options first, then fall back to separate required tag
 		if flagOptions["required"] {
 			fieldSchema.Required = true
@@ -415,15 +598,26 @@ func (cb *ConfigBuilder) getFieldName(field reflect.StructField, prefix string)
 			return prefix + flagName
 		}
 	}
-	if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
-		parts := strings.Split(jsonTag, ",")
-		return prefix + parts[0]
+	if jsonName := jsonFieldName(field); jsonName != "" {
+		return prefix + jsonName
 	}
 	return prefix + strings.ToLower(field.Name)
 }
 
+// jsonFieldName returns the name in the json tag of field, or "" without one
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
 // parseDefaultValue parses default value string to appropriate type
 func (cb *ConfigBuilder) parseDefaultValue(defaultStr string, fieldType reflect.Type) any {
+	if cb.decoded(fieldType) {
+		return defaultStr // decoded with the other sources' values
+	}
 	pm := NewPrecedenceManager()
 	switch fieldType.Kind() { //nolint:exhaustive // only handle supported defaultable kinds
 	case reflect.String:
@@ -439,18 +633,34 @@ func (cb *ConfigBuilder) parseDefaultValue(defaultStr string, fieldType reflect.
 			val, _ := pm.parseDurationString(defaultStr)
 			return val
 		}
-		val, _ := strconv.ParseInt(defaultStr, 10, 64)
+		val, _ := strconv.ParseInt(defaultStr, 0, 64)
+		return val
+	case reflect.Int32:
+		val, _ := strconv.ParseInt(defaultStr, 0, 32)
+		return int32(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		val, _ := strconv.ParseUint(defaultStr, 0, int(fieldType.Size()*8))
+		return uint(val)
+	case reflect.Uint64:
+		val, _ := strconv.ParseUint(defaultStr, 0, 64)
 		return val
 	case reflect.Float64:
 		val, _ := strconv.ParseFloat(defaultStr, 64)
 		return val
 	case reflect.Slice:
 		// Handle slice types
-		if fieldType.Elem().Kind() == reflect.String {
+		switch {
+		case fieldType.Elem() == reflect.TypeOf(time.Duration(0)):
+			val, _ := cb.parseDurationSliceString(defaultStr)
+			return val
+		case fieldType.Elem().Kind() == reflect.String:
 			return cb.parseStringSliceString(defaultStr)
-		} else if fieldType.Elem().Kind() == reflect.Int {
+		case fieldType.Elem().Kind() == reflect.Int:
 			val, _ := cb.parseIntSliceString(defaultStr)
 			return val
+		case fieldType.Elem().Kind() == reflect.Float64:
+			val, _ := cb.parseFloatSliceString(defaultStr)
+			return val
 		}
 		return defaultStr
 	default:
@@ -511,7 +721,7 @@ func (cb *ConfigBuilder) generateFlags() {
 			groupBuilder := groupBuilders[fieldSchema.GroupName]
 
 			// Generate appropriate flag type based on field type
-			switch fieldSchema.Type.Kind() { //nolint:exhaustive // only supported kinds become flags
+			switch cb.flagKind(fieldSchema) { //nolint:exhaustive // only supported kinds become flags
 			case reflect.String:
 				//nolint:nestif // Enum vs string handling requires nested checks for correctness.
 				if len(fieldSchema.EnumValues) > 0 {
@@ -529,21 +739,34 @@ func (cb *ConfigBuilder) generateFlags() {
 				if fieldSchema.Type == reflect.TypeOf(time.Duration(0)) {
 					flagBuilder = groupBuilder.DurationFlag(flagName, description)
 				} else {
-					flagBuilder = groupBuilder.IntFlag(flagName, description)
+					flagBuilder = groupBuilder.Int64Flag(flagName, description)
 				}
+			case reflect.Int32:
+				flagBuilder = groupBuilder.Int32Flag(flagName, description)
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+				flagBuilder = groupBuilder.UintFlag(flagName, description)
+			case reflect.Uint64:
+				flagBuilder = groupBuilder.Uint64Flag(flagName, description)
 			case reflect.Float64:
 				flagBuilder = groupBuilder.FloatFlag(flagName, description)
 			case reflect.Slice:
-				//nolint:nestif // Slice element-type branching is explicit by design.
-				if fieldSchema.Type.Elem().Kind() == reflect.String {
+				switch {
+				case fieldSchema.Type.Elem() == reflect.TypeOf(time.Duration(0)):
+					flagBuilder = groupBuilder.DurationSliceFlag(flagName, description)
+				case fieldSchema.Type.Elem().Kind() == reflect.String && len(fieldSchema.EnumValues) > 0:
+					description += fmt.Sprintf(" (valid values: %s)", strings.Join(fieldSchema.EnumValues, ", "))
+					flagBuilder = groupBuilder.EnumSliceFlag(flagName, description, fieldSchema.EnumValues...)
+				case fieldSchema.Type.Elem().Kind() == reflect.String:
 					flagBuilder = groupBuilder.StringSliceFlag(flagName, description)
-				} else if fieldSchema.Type.Elem().Kind() == reflect.Int {
+				case fieldSchema.Type.Elem().Kind() == reflect.Int:
 					flagBuilder = groupBuilder.IntSliceFlag(flagName, description)
+				case fieldSchema.Type.Elem().Kind() == reflect.Float64:
+					flagBuilder = groupBuilder.FloatSliceFlag(flagName, description)
 				}
 			}
 		} else {
 			// Add flag directly to app
-			switch fieldSchema.Type.Kind() { //nolint:exhaustive // only supported kinds become flags
+			switch cb.flagKind(fieldSchema) { //nolint:exhaustive // only supported kinds become flags
 			case reflect.String:
 				if len(fieldSchema.EnumValues) > 0 {
 					// For enum fields, add validation note and create enum flag
@@ -560,21 +783,38 @@ func (cb *ConfigBuilder) generateFlags() {
 				if fieldSchema.Type == reflect.TypeOf(time.Duration(0)) {
 					flagBuilder = cb.app.DurationFlag(flagName, description)
 				} else {
-					flagBuilder = cb.app.IntFlag(flagName, description)
+					flagBuilder = cb.app.Int64Flag(flagName, description)
 				}
+			case reflect.Int32:
+				flagBuilder = cb.app.Int32Flag(flagName, description)
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+				flagBuilder = cb.app.UintFlag(flagName, description)
+			case reflect.Uint64:
+				flagBuilder = cb.app.Uint64Flag(flagName, description)
 			case reflect.Float64:
 				flagBuilder = cb.app.FloatFlag(flagName, description)
 			case reflect.Slice:
-				if fieldSchema.Type.Elem().Kind() == reflect.String {
+				switch {
+				case fieldSchema.Type.Elem() == reflect.TypeOf(time.Duration(0)):
+					flagBuilder = cb.app.DurationSliceFlag(flagName, description)
+				case fieldSchema.Type.Elem().Kind() == reflect.String && len(fieldSchema.EnumValues) > 0:
+					description += fmt.Sprintf(" (valid values: %s)", strings.Join(fieldSchema.EnumValues, ", "))
+					flagBuilder = cb.app.EnumSliceFlag(flagName, description, fieldSchema.EnumValues...)
+				case fieldSchema.Type.Elem().Kind() == reflect.String:
 					flagBuilder = cb.app.StringSliceFlag(flagName, description)
-				} else if fieldSchema.Type.Elem().Kind() == reflect.Int {
+				case fieldSchema.Type.Elem().Kind() == reflect.Int:
 					flagBuilder = cb.app.IntSliceFlag(flagName, description)
+				case fieldSchema.Type.Elem().Kind() == reflect.Float64:
+					flagBuilder = cb.app.FloatSliceFlag(flagName, description)
 				}
 			}
 		}
 
 		// Apply common flag settings based on field type
 		cb.applyFlagSettings(flagBuilder, fieldSchema)
+		if flag := cb.app.flags[flagName]; flag != nil && fieldSchema.Secret {
+			flag.secret = true
+		}
 	}
 
 	// Close all flag groups
@@ -631,6 +871,70 @@ func (cb *ConfigBuilder) applyFlagSettings(flagBuilder interface{}, fieldSchema
 			fb.Required()
 		}
 		fb.Global()
+	case *FlagBuilder[int64, *App]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(int64))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[int64, *FlagGroupBuilder[*App]]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(int64))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[int32, *App]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(int32))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[int32, *FlagGroupBuilder[*App]]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(int32))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[uint, *App]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(uint))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[uint, *FlagGroupBuilder[*App]]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(uint))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[uint64, *App]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(uint64))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[uint64, *FlagGroupBuilder[*App]]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.(uint64))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
 	case *FlagBuilder[time.Duration, *App]:
 		if fieldSchema.Default != nil {
 			fb = fb.Default(fieldSchema.Default.(time.Duration))
@@ -695,30 +999,89 @@ func (cb *ConfigBuilder) applyFlagSettings(flagBuilder interface{}, fieldSchema
 			fb.Required()
 		}
 		fb.Global()
+	case *FlagBuilder[[]float64, *App]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.([]float64))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[[]float64, *FlagGroupBuilder[*App]]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.([]float64))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[[]time.Duration, *App]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.([]time.Duration))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
+	case *FlagBuilder[[]time.Duration, *FlagGroupBuilder[*App]]:
+		if fieldSchema.Default != nil {
+			fb = fb.Default(fieldSchema.Default.([]time.Duration))
+		}
+		if fieldSchema.Required {
+			fb.Required()
+		}
+		fb.Global()
 	}
 }
 
-// loadFromFile loads configuration from JSON file
+// loadFromFile loads configuration from JSON file; errors are *ConfigFileError
 func (cb *ConfigBuilder) loadFromFile(filename string) (map[string]any, error) {
-	// Support JSON only; ignore other formats by returning an error so caller skips adding the source
+	// A missing file is reported as such whatever its extension
+	if _, err := os.Stat(filename); err != nil {
+		return nil, &ConfigFileError{Path: filename, Err: err}
+	}
+
+	// Support JSON only; other formats are an error so the source is not added
 	ext := strings.ToLower(filepath.Ext(filename))
 	if ext != ".json" {
-		return nil, fmt.Errorf("unsupported config format: %s (only .json supported)", ext)
+		return nil, &ConfigFileError{
+			Path: filename,
+			Err:  fmt.Errorf("%w: %s (only .json supported)", ErrUnsupportedConfigFormat, ext),
+		}
 	}
 
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		return nil, err
+		return nil, &ConfigFileError{Path: filename, Err: err}
 	}
 
 	var config map[string]any
 	if uErr := json.Unmarshal(data, &config); uErr != nil {
-		return nil, uErr
+		fileErr := &ConfigFileError{Path: filename, Err: uErr}
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(uErr, &syntaxErr):
+			fileErr.Line, fileErr.Column = lineColumn(data, syntaxErr.Offset)
+		case errors.As(uErr, &typeErr):
+			fileErr.Line, fileErr.Column = lineColumn(data, typeErr.Offset)
+		}
+		return nil, fileErr
 	}
 
 	return config, nil
 }
 
+// lineColumn converts a byte offset reported by encoding/json (just past the
+// offending byte) to a 1-based line and column
+func lineColumn(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 1), int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte{'\n'}) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	return line, column
+}
+
 // loadFromEnv loads configuration from environment variables based on struct tags
 func (cb *ConfigBuilder) loadFromEnv() map[string]any {
 	data := make(map[string]any)
@@ -748,7 +1111,7 @@ func (cb *ConfigBuilder) collectFlagValues() {
 		}
 
 		// Try to get flag value based on type
-		switch fieldSchema.Type.Kind() { //nolint:exhaustive // only supported kinds are collected
+		switch cb.flagKind(fieldSchema) { //nolint:exhaustive // only supported kinds are collected
 		case reflect.String:
 			// If schema declares enum values, pull from enum storage first
 			//nolint:nestif // explicit nested check keeps enum vs string retrieval readable
@@ -785,6 +1148,28 @@ func (cb *ConfigBuilder) collectFlagValues() {
 						flagData[fieldName] = value
 					}
 				}
+			} else if value, exists := cb.app.getInt64FlagValue(flagName); exists {
+				if def, ok := fieldSchema.Default.(int64); !ok || value != def {
+					flagData[fieldName] = value
+				}
+			}
+		case reflect.Int32:
+			if value, exists := cb.app.getInt64FlagValue(flagName); exists {
+				if def, ok := fieldSchema.Default.(int32); !ok || value != int64(def) {
+					flagData[fieldName] = value
+				}
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			if value, exists := cb.app.getUint64FlagValue(flagName); exists {
+				if def, ok := fieldSchema.Default.(uint); !ok || value != uint64(def) {
+					flagData[fieldName] = value
+				}
+			}
+		case reflect.Uint64:
+			if value, exists := cb.app.getUint64FlagValue(flagName); exists {
+				if def, ok := fieldSchema.Default.(uint64); !ok || value != def {
+					flagData[fieldName] = value
+				}
 			}
 		case reflect.Float64:
 			if value, exists := cb.app.getFloatFlagValue(flagName); exists {
@@ -793,9 +1178,27 @@ func (cb *ConfigBuilder) collectFlagValues() {
 				}
 			}
 		case reflect.Slice:
-			// Handle []string and []int
+			// Handle []string, []int, []float64 and []time.Duration
 			//nolint:nestif // explicit element-type branching is intentional
-			if fieldSchema.Type.Elem().Kind() == reflect.String {
+			if fieldSchema.Type.Elem() == reflect.TypeOf(time.Duration(0)) {
+				if value, exists := cb.app.getDurationSliceFlagValue(flagName); exists {
+					if def, ok := fieldSchema.Default.([]time.Duration); !ok || !slices.Equal(def, value) {
+						flagData[fieldName] = value
+					}
+				}
+			} else if fieldSchema.Type.Elem().Kind() == reflect.Float64 {
+				if value, exists := cb.app.getFloatSliceFlagValue(flagName); exists {
+					if def, ok := fieldSchema.Default.([]float64); !ok || !slices.Equal(def, value) {
+						flagData[fieldName] = value
+					}
+				}
+			} else if fieldSchema.Type.Elem().Kind() == reflect.String && len(fieldSchema.EnumValues) > 0 {
+				if value, exists := cb.app.getEnumSliceFlagValue(flagName); exists {
+					if def, ok := fieldSchema.Default.([]string); !ok || !slices.Equal(def, value) {
+						flagData[fieldName] = value
+					}
+				}
+			} else if fieldSchema.Type.Elem().Kind() == reflect.String {
 				if value, exists := cb.app.getStringSliceFlagValue(flagName); exists {
 					// compare lengths and items if default exists
 					if def, ok := fieldSchema.Default.([]string); ok {
@@ -879,6 +1282,26 @@ func (a *App) getIntFlagValue(name string) (int, bool) {
 	return a.currentResult.GetGlobalInt(name)
 }
 
+func (a *App) getInt64FlagValue(name string) (int64, bool) {
+	if a.currentResult == nil {
+		return 0, false
+	}
+	if v, ok := a.currentResult.GetInt64(name); ok {
+		return v, true
+	}
+	return a.currentResult.GetGlobalInt64(name)
+}
+
+func (a *App) getUint64FlagValue(name string) (uint64, bool) {
+	if a.currentResult == nil {
+		return 0, false
+	}
+	if v, ok := a.currentResult.GetUint64(name); ok {
+		return v, true
+	}
+	return a.currentResult.GetGlobalUint64(name)
+}
+
 func (a *App) getDurationFlagValue(name string) (time.Duration, bool) {
 	if a.currentResult == nil {
 		return 0, false
@@ -927,6 +1350,45 @@ func (a *App) getIntSliceFlagValue(name string) ([]int, bool) {
 	return nil, false
 }
 
+func (a *App) getFloatSliceFlagValue(name string) ([]float64, bool) {
+	if a.currentResult == nil {
+		return nil, false
+	}
+	if v, ok := a.currentResult.GetFloatSlice(name); ok {
+		return v, true
+	}
+	if v, ok := a.currentResult.GetGlobalFloatSlice(name); ok {
+		return v, true
+	}
+	return nil, false
+}
+
+func (a *App) getDurationSliceFlagValue(name string) ([]time.Duration, bool) {
+	if a.currentResult == nil {
+		return nil, false
+	}
+	if v, ok := a.currentResult.GetDurationSlice(name); ok {
+		return v, true
+	}
+	if v, ok := a.currentResult.GetGlobalDurationSlice(name); ok {
+		return v, true
+	}
+	return nil, false
+}
+
+func (a *App) getEnumSliceFlagValue(name string) ([]string, bool) {
+	if a.currentResult == nil {
+		return nil, false
+	}
+	if v, ok := a.currentResult.GetEnumSlice(name); ok {
+		return v, true
+	}
+	if v, ok := a.currentResult.GetGlobalEnumSlice(name); ok {
+		return v, true
+	}
+	return nil, false
+}
+
 func (a *App) getEnumFlagValue(name string) (string, bool) {
 	if a.currentResult == nil {
 		return "", false
@@ -970,8 +1432,7 @@ func (cb *ConfigBuilder) setStructFields(
 		fieldName := cb.getFieldName(field, prefix)
 
 		// Handle nested structs
-		if fieldValue.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) &&
-			field.Type != reflect.TypeOf(time.Duration(0)) {
+		if cb.isNestedStruct(field.Type) {
 			if err := cb.setStructFields(fieldValue, field.Type, fieldName+".", config); err != nil {
 				return err
 			}
@@ -1010,8 +1471,7 @@ func (cb *ConfigBuilder) setFieldValue(fieldValue reflect.Value, value any) erro
 		// Use reflect to obtain the string representation safely; this also
 		// supports named string types without a direct type assertion.
 		s := valueReflect.String()
-		pm := NewPrecedenceManager()
-		convertedValue, convErr := pm.convertStringToType(s, fieldValue.Type())
+		convertedValue, convErr := cb.precedenceManager.convertValueToType(s, fieldValue.Type())
 		if convErr != nil {
 			return convErr
 		}
@@ -1060,3 +1520,54 @@ func (cb *ConfigBuilder) parseIntSliceString(s string) ([]int, error) {
 
 	return result, nil
 }
+
+// parseFloatSliceString parses comma-separated floats: "0.5,1.5"
+func (cb *ConfigBuilder) parseFloatSliceString(s string) ([]float64, error) {
+	if s == "" {
+		return []float64{}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	result := make([]float64, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float in slice: %s", part)
+		}
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+// parseDurationSliceString parses comma-separated durations: "1s,5m,1h"
+func (cb *ConfigBuilder) parseDurationSliceString(s string) ([]time.Duration, error) {
+	if s == "" {
+		return []time.Duration{}, nil
+	}
+
+	pm := NewPrecedenceManager()
+	parts := strings.Split(s, ",")
+	result := make([]time.Duration, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		value, err := pm.parseDurationString(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration in slice: %s", part)
+		}
+		result = append(result, value)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,295 @@
+package snap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError is a validation failure of a configuration field, reported as
+// "path: message" with the dotted config key ("cache.redis.port")
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// structValidator is a hook registered with RegisterStructValidator
+type structValidator struct {
+	fn    func(any) error
+	types []reflect.Type // Nested struct types it applies to (none: the bound struct)
+}
+
+// RegisterStructValidator adds a cross-field check run after the bound
+// struct is populated. Without types, fn receives the pointer passed to
+// Bind; with types, it runs for every nested struct of those types, e.g.
+// RegisterStructValidator(checkTLS, TLSConfig{}), receiving a pointer to it.
+// Returned errors are reported under the struct's path; return *FieldError
+// (or several joined with errors.Join) to name a field relative to it.
+func (cb *ConfigBuilder) RegisterStructValidator(fn func(s any) error, types ...any) *ConfigBuilder {
+	sv := structValidator{fn: fn}
+	for _, t := range types {
+		typ := reflect.TypeOf(t)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		sv.types = append(sv.types, typ)
+	}
+	cb.structValidators = append(cb.structValidators, sv)
+	return cb
+}
+
+// RequireOneOf requires at least one of the config keys ("tls.cert",
+// "token") to be set to a non-zero value
+func (cb *ConfigBuilder) RequireOneOf(paths ...string) *ConfigBuilder {
+	cb.requireOneOf = append(cb.requireOneOf, paths)
+	return cb
+}
+
+// configValidator accumulates the validation errors of a bound struct
+type configValidator struct {
+	cb     *ConfigBuilder
+	values map[string]reflect.Value // Leaf fields by config key
+	errs   []error
+}
+
+// validateConfig checks the populated target against its validate tags,
+// RequireOneOf groups and struct validators, reporting every failure at once
+func (cb *ConfigBuilder) validateConfig() error {
+	targetValue := reflect.ValueOf(cb.target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v := &configValidator{cb: cb, values: make(map[string]reflect.Value)}
+	v.walk(targetValue.Elem(), "")
+
+	for _, paths := range cb.requireOneOf {
+		v.checkOneOf(paths)
+	}
+	for _, sv := range cb.structValidators {
+		if len(sv.types) == 0 {
+			v.report("", sv.fn(cb.target))
+		}
+	}
+	return errors.Join(v.errs...)
+}
+
+// walk validates the fields of a struct whose config keys start with prefix
+func (v *configValidator) walk(structValue reflect.Value, prefix string) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+		path := v.cb.getFieldName(field, prefix)
+
+		if v.cb.isNestedStruct(field.Type) {
+			v.walk(fieldValue, path+".")
+			continue
+		}
+		v.values[path] = fieldValue
+		if rules := field.Tag.Get("validate"); rules != "" {
+			v.checkField(structValue, fieldValue, path, rules)
+		}
+	}
+
+	for _, sv := range v.cb.structValidators {
+		for _, typ := range sv.types {
+			if typ == structType && prefix != "" {
+				v.report(strings.TrimSuffix(prefix, "."), sv.fn(structValue.Addr().Interface()))
+			}
+		}
+	}
+}
+
+// report records err, qualifying it with path
+func (v *configValidator) report(path string, err error) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			v.report(path, e)
+		}
+		return
+	}
+	var fieldErr *FieldError
+	switch {
+	case !errors.As(err, &fieldErr):
+		v.errs = append(v.errs, &FieldError{Path: path, Message: err.Error()})
+	case path != "" && fieldErr.Path != "":
+		v.errs = append(v.errs, &FieldError{Path: path + "." + fieldErr.Path, Message: fieldErr.Message})
+	case path != "":
+		v.errs = append(v.errs, &FieldError{Path: path, Message: fieldErr.Message})
+	default:
+		v.errs = append(v.errs, fieldErr)
+	}
+}
+
+// fail records a failure of the field at path
+func (v *configValidator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkField applies the comma-separated rules of a validate tag:
+// required, required_with=A B, required_without=A B, gt, gte, lt and lte
+func (v *configValidator) checkField(parent, value reflect.Value, path, rules string) {
+	var lower, upper *bound
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+		case "required":
+			if value.IsZero() {
+				v.fail(path, "is required")
+				return
+			}
+		case "required_with", "required_without":
+			set, names, err := v.siblings(parent, param)
+			if err != nil {
+				v.fail(path, "invalid %s rule: %v", name, err)
+				return
+			}
+			if name == "required_with" && set && value.IsZero() {
+				v.fail(path, "is required when %s is set", strings.Join(names, " or "))
+				return
+			}
+			if name == "required_without" && !set && value.IsZero() {
+				v.fail(path, "is required when %s is not set", strings.Join(names, " or "))
+				return
+			}
+		case "gt", "gte", "lt", "lte":
+			b, err := parseBound(name, param, value.Type())
+			if err != nil {
+				v.fail(path, "invalid %s rule: %v", name, err)
+				return
+			}
+			if name[0] == 'g' {
+				lower = b
+			} else {
+				upper = b
+			}
+		default:
+			v.fail(path, "unknown validate rule %q", name)
+			return
+		}
+	}
+	v.checkRange(value, path, lower, upper)
+}
+
+// siblings reports whether any of the space-separated fields of parent is
+// set, and returns their config keys for messages
+func (v *configValidator) siblings(parent reflect.Value, param string) (bool, []string, error) {
+	fields := strings.Fields(param)
+	if len(fields) == 0 {
+		return false, nil, errors.New("no fields")
+	}
+	set := false
+	names := make([]string, 0, len(fields))
+	for _, name := range fields {
+		field, ok := parent.Type().FieldByName(name)
+		if !ok {
+			return false, nil, fmt.Errorf("unknown field %s", name)
+		}
+		names = append(names, v.cb.getFieldName(field, ""))
+		if !parent.FieldByIndex(field.Index).IsZero() {
+			set = true
+		}
+	}
+	return set, names, nil
+}
+
+// bound is one side of a range rule
+type bound struct {
+	value     float64
+	text      string
+	exclusive bool
+}
+
+// parseBound parses the parameter of a range rule for a field of type typ:
+// durations for time.Duration fields, numbers otherwise
+func parseBound(rule, param string, typ reflect.Type) (*bound, error) {
+	b := &bound{text: param, exclusive: rule == "gt" || rule == "lt"}
+	if typ == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(param)
+		if err != nil {
+			return nil, err
+		}
+		b.value = float64(d)
+		return b, nil
+	}
+	f, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, err
+	}
+	b.value = f
+	return b, nil
+}
+
+// checkRange compares a number, or the length of a string, slice or map,
+// against the bounds of its range rules
+func (v *configValidator) checkRange(value reflect.Value, path string, lower, upper *bound) {
+	if lower == nil && upper == nil {
+		return
+	}
+	var n float64
+	subject := "must be"
+	switch value.Kind() { //nolint:exhaustive // other kinds have no natural order
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = value.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		n = float64(value.Len())
+		subject = "length must be"
+	default:
+		v.fail(path, "range rules do not apply to %s", value.Type())
+		return
+	}
+
+	tooLow := lower != nil && (n < lower.value || (lower.exclusive && n == lower.value))
+	tooHigh := upper != nil && (n > upper.value || (upper.exclusive && n == upper.value))
+	if !tooLow && !tooHigh {
+		return
+	}
+	switch {
+	case lower != nil && upper != nil && !lower.exclusive && !upper.exclusive:
+		v.fail(path, "%s between %s and %s", subject, lower.text, upper.text)
+	case tooLow && lower.exclusive:
+		v.fail(path, "%s greater than %s", subject, lower.text)
+	case tooLow:
+		v.fail(path, "%s at least %s", subject, lower.text)
+	case upper.exclusive:
+		v.fail(path, "%s less than %s", subject, upper.text)
+	default:
+		v.fail(path, "%s at most %s", subject, upper.text)
+	}
+}
+
+// checkOneOf requires one of the config keys to be set
+func (v *configValidator) checkOneOf(paths []string) {
+	for _, path := range paths {
+		value, ok := v.values[path]
+		if !ok {
+			v.fail(path, "RequireOneOf names an unknown config key")
+			return
+		}
+		if !value.IsZero() {
+			return
+		}
+	}
+	v.fail("", "one of %s is required", strings.Join(paths, ", "))
+}
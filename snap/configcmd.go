@@ -0,0 +1,332 @@
+package snap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dzonerzy/go-snap/internal/fuzzy"
+)
+
+// ConfigCommand adds the user config file, ConfigDir()/filename (a .json
+// name), as a source like FromFile and registers a built-in "config" command
+// to edit it, in the style of git config:
+//
+//	myapp config list                       # every key with its effective value
+//	myapp config get cache.redis.port       # one effective value
+//	myapp config set cache.redis.port 6380  # write to the user config file
+//	myapp config unset cache.redis.port     # remove from the user config file
+//
+// Keys are the dotted schema paths; set writes them with the json names, like
+// hand-written files. set checks the value against the field type and enum
+// like any other source, so the file never holds a value the app would
+// reject. get and list show the value the app would use, from all sources,
+// and run even when the configuration is incomplete; list redacts fields
+// tagged secret:"true", which get prints when asked for by key. The command is
+// added at run time (CLI mode, see FromFlags) unless the app already defines
+// a "config" command.
+func (cb *ConfigBuilder) ConfigCommand(filename string) *ConfigBuilder {
+	dir := cb.app.ConfigDir()
+	if dir == "" {
+		return cb
+	}
+	cb.userFile = filepath.Join(dir, filename)
+	for _, file := range cb.files {
+		if file.path == cb.userFile {
+			return cb // Already added by FromFileDiscovery
+		}
+	}
+	return cb.FromFile(cb.userFile)
+}
+
+// isConfigCommand reports whether cmd is the config command or one of its subcommands
+func (cb *ConfigBuilder) isConfigCommand(cmd *Command) bool {
+	for ; cmd != nil && cb.configCmd != nil; cmd = cmd.parent {
+		if cmd == cb.configCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// addConfigCommand registers the command enabled by ConfigCommand
+func (a *App) addConfigCommand() {
+	cb := a.configBuilder
+	if cb == nil || cb.userFile == "" {
+		return
+	}
+	if _, exists := a.commands["config"]; exists {
+		return
+	}
+	config := a.Command("config", "Get and set configuration options")
+	config.Command("list", "List configuration values").
+		Action(cb.listValues)
+	config.Command("get", "Print a configuration value").
+		StringArg("key", "Configuration key, e.g. server.port").Required().Back().
+		Action(cb.getValue)
+	config.Command("set", "Set a configuration value in the user config file").
+		StringArg("key", "Configuration key, e.g. server.port").Required().Back().
+		StringArg("value", "New value").Required().Back().
+		Action(cb.setValue)
+	config.Command("unset", "Remove a configuration value from the user config file").
+		StringArg("key", "Configuration key, e.g. server.port").Required().Back().
+		Action(cb.unsetValue)
+	cb.configCmd = config.command
+}
+
+// effectiveValues resolves the configuration without the required field and
+// struct checks, so values can be inspected while the configuration is incomplete
+func (cb *ConfigBuilder) effectiveValues() (map[string]any, error) {
+	if err := cb.refreshSources(); err != nil {
+		return nil, err
+	}
+	values, err := cb.precedenceManager.resolveFields(cb.schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := cb.precedenceManager.applySchemaDefaults(values, cb.schema); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// listValues prints key=value for every key that has a value, sorted by key,
+// with secret values redacted
+func (cb *ConfigBuilder) listValues(ctx *Context) error {
+	values, err := cb.effectiveValues()
+	if err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(cb.schema.Fields) {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		shown := formatConfigValue(value)
+		if cb.schema.Fields[key].Secret {
+			shown = redacted
+		}
+		fmt.Fprintf(ctx.Stdout(), "%s=%s\n", key, shown)
+	}
+	return nil
+}
+
+// getValue prints the effective value of a key
+func (cb *ConfigBuilder) getValue(ctx *Context) error {
+	key := ctx.MustArgString("key", "")
+	if err := cb.checkKey(key); err != nil {
+		return err
+	}
+	values, err := cb.effectiveValues()
+	if err != nil {
+		return err
+	}
+	value, ok := values[key]
+	if !ok {
+		return fmt.Errorf("%s is not set", key)
+	}
+	fmt.Fprintln(ctx.Stdout(), formatConfigValue(value))
+	return nil
+}
+
+// setValue validates a value against the schema and writes it to the user config file
+func (cb *ConfigBuilder) setValue(ctx *Context) error {
+	key, raw := ctx.MustArgString("key", ""), ctx.MustArgString("value", "")
+	if err := cb.checkKey(key); err != nil {
+		return err
+	}
+	field := cb.schema.Fields[key]
+	value, err := cb.precedenceManager.convertValueToType(raw, field.Type)
+	if err != nil {
+		return NewError(ErrorTypeInvalidValue, fmt.Sprintf("invalid value %q for %s: %v", raw, key, err))
+	}
+	if err := cb.precedenceManager.applySchemaDefaults(map[string]any{key: value},
+		&ConfigSchema{Fields: map[string]*FieldSchema{key: field}}); err != nil {
+		return NewError(ErrorTypeInvalidValue, err.Error())
+	}
+
+	stored := any(raw) // Decoded types are stored as typed
+	if !cb.decoded(field.Type) {
+		stored = jsonConfigValue(reflect.ValueOf(value))
+	}
+	path := cb.fileKey(key)
+	return cb.editUserFile(func(data map[string]any) {
+		if path != key {
+			deleteConfigPath(data, key) // Keep one spelling per field
+		}
+		setConfigPath(data, path, stored)
+	})
+}
+
+// unsetValue removes a key from the user config file
+func (cb *ConfigBuilder) unsetValue(ctx *Context) error {
+	key := ctx.MustArgString("key", "")
+	if err := cb.checkKey(key); err != nil {
+		return err
+	}
+	return cb.editUserFile(func(data map[string]any) {
+		deleteConfigPath(data, key)
+		deleteConfigPath(data, cb.fileKey(key))
+	})
+}
+
+// fileKey spells a schema key the way config files do, with the json name of
+// each field or group that a flag tag renames
+func (cb *ConfigBuilder) fileKey(key string) string {
+	jsonNames := make(map[string]string, len(cb.schema.aliases))
+	for alias, canonical := range cb.schema.aliases {
+		jsonNames[canonical] = alias[strings.LastIndexByte(alias, '.')+1:]
+	}
+	segments, parts := strings.Split(key, "."), strings.Split(key, ".")
+	for i := range segments {
+		if name, ok := jsonNames[strings.Join(segments[:i+1], ".")]; ok {
+			parts[i] = name
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// checkKey rejects keys that are not in the schema, suggesting close matches
+func (cb *ConfigBuilder) checkKey(key string) error {
+	if _, ok := cb.schema.Fields[key]; ok {
+		return nil
+	}
+	err := NewError(ErrorTypeInvalidArgument, "unknown configuration key "+key)
+	if matches := fuzzy.FindSuggestions(key, sortedKeys(cb.schema.Fields), 3, 1); len(matches) > 0 {
+		_ = err.WithSuggestion(cb.app.msgf(MsgDidYouMean, matches[0]))
+	}
+	return err
+}
+
+// editUserFile applies edit to the user config file (empty when it does not
+// exist yet) and writes it back atomically
+func (cb *ConfigBuilder) editUserFile(edit func(data map[string]any)) error {
+	data := make(map[string]any)
+	if _, err := os.Stat(cb.userFile); !errors.Is(err, fs.ErrNotExist) {
+		loaded, loadErr := cb.loadFromFile(cb.userFile)
+		if loadErr != nil {
+			return loadErr
+		}
+		if loaded != nil {
+			data = loaded
+		}
+	}
+	edit(data)
+
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(cb.userFile)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(cb.userFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone after a successful rename
+	if _, err := tmp.Write(append(out, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cb.userFile)
+}
+
+// setConfigPath stores value at the dotted key, creating nested objects; a
+// flat "a.b" key already in the file is updated in place
+func setConfigPath(data map[string]any, key string, value any) {
+	if _, flat := data[key]; flat {
+		data[key] = value
+		return
+	}
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := data[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			data[part] = next
+		}
+		data = next
+	}
+	data[parts[len(parts)-1]] = value
+}
+
+// deleteConfigPath removes the dotted key and any objects it leaves empty
+func deleteConfigPath(data map[string]any, key string) {
+	delete(data, key)
+	parts := strings.Split(key, ".")
+	var walk func(m map[string]any, parts []string)
+	walk = func(m map[string]any, parts []string) {
+		if len(parts) == 1 {
+			delete(m, parts[0])
+			return
+		}
+		next, ok := m[parts[0]].(map[string]any)
+		if !ok {
+			return
+		}
+		walk(next, parts[1:])
+		if len(next) == 0 {
+			delete(m, parts[0])
+		}
+	}
+	walk(data, parts)
+}
+
+// jsonConfigValue converts a typed value to the form written to the file:
+// durations as strings ("1m30s"), slices and maps element by element
+func jsonConfigValue(v reflect.Value) any {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	switch v.Kind() { //nolint:exhaustive // other kinds marshal as-is
+	case reflect.Slice, reflect.Array:
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = jsonConfigValue(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = jsonConfigValue(iter.Value())
+		}
+		return out
+	}
+	return v.Interface()
+}
+
+// formatConfigValue renders a value the way it is accepted by config set:
+// lists and maps comma-separated
+func formatConfigValue(value any) string {
+	v := reflect.ValueOf(value)
+	switch v.Kind() { //nolint:exhaustive // other kinds print with %v
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatConfigValue(v.Index(i).Interface())
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			parts = append(parts, fmt.Sprintf("%v=%s", iter.Key().Interface(), formatConfigValue(iter.Value().Interface())))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,181 @@
+package snap
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"unicode/utf8"
+)
+
+// systemConfigRoot is the machine-wide configuration root (overridable in tests)
+var systemConfigRoot = func() string {
+	if runtime.GOOS == "windows" {
+		return os.Getenv("ProgramData")
+	}
+	return "/etc"
+}
+
+// FromFileDiscovery adds every copy of filename found in the conventional
+// cascade, merged from least to most specific:
+//
+//  1. system:  /etc/<app>/filename (%ProgramData% on Windows)
+//  2. user:    $XDG_CONFIG_HOME/<app>/filename (see App.ConfigDir)
+//  3. project: the nearest parent directory holding filename, up to the repository root
+//  4. local:   ./filename
+//
+// Only existing files are added; a malformed one fails like with FromFile.
+func (cb *ConfigBuilder) FromFileDiscovery(filename string) *ConfigBuilder {
+	for _, path := range cb.app.ConfigFiles(filename) {
+		cb.FromFile(path)
+	}
+	return cb
+}
+
+// FromFlagFile adds a global --name flag (and -short when given and free)
+// naming a config file loaded at run time, e.g. FromFlagFile("config", "c").
+// The file overrides those of FromFile and FromFileDiscovery and, unlike
+// them, fails the run when it cannot be loaded. It applies in CLI mode
+// (FromFlags) only.
+func (cb *ConfigBuilder) FromFlagFile(name, short string) *ConfigBuilder {
+	cb.fileFlag, cb.fileFlagShort = name, short
+	return cb
+}
+
+// addFileFlag registers the config file flag on the app (once)
+func (cb *ConfigBuilder) addFileFlag() {
+	if cb.fileFlag == "" || cb.app == nil {
+		return
+	}
+	if _, exists := cb.app.flags[cb.fileFlag]; exists {
+		return
+	}
+	flag := cb.app.StringFlag(cb.fileFlag, "Configuration file").Global()
+	if short, size := utf8.DecodeRuneInString(cb.fileFlagShort); size > 0 && cb.app.shortFlags[short] == nil {
+		flag.Short(short)
+	}
+}
+
+// applyFileFlag replaces the source loaded from the config file flag with
+// the file given in this run
+func (cb *ConfigBuilder) applyFileFlag() error {
+	if cb.fileFlag == "" {
+		return nil
+	}
+	pm := cb.precedenceManager
+	pm.sources = slices.DeleteFunc(pm.sources, func(s ConfigSource) bool { return s.fromFlag })
+
+	path, _ := cb.app.getStringFlagValue(cb.fileFlag)
+	if path == "" {
+		return nil
+	}
+	data, err := cb.loadFromFile(path)
+	if err != nil {
+		return err
+	}
+	pm.sources = append(pm.sources, ConfigSource{
+		Type:     SourceTypeFile,
+		Data:     data,
+		Priority: int(SourceTypeFile),
+		fromFlag: true,
+	})
+	return nil
+}
+
+// ConfigDir returns the per-user configuration directory for the app:
+// $XDG_CONFIG_HOME/<app> when set, otherwise os.UserConfigDir()/<app>.
+// It returns "" when no user configuration directory can be determined.
+func (a *App) ConfigDir() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, a.name)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, a.name)
+}
+
+// CacheDir returns the per-user cache directory for the app:
+// $XDG_CACHE_HOME/<app> when set, otherwise os.UserCacheDir()/<app>.
+// It returns "" when no user cache directory can be determined.
+func (a *App) CacheDir() string {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, a.name)
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, a.name)
+}
+
+// SystemConfigDir returns the machine-wide configuration directory for the app
+// (/etc/<app>, or %ProgramData%\<app> on Windows)
+func (a *App) SystemConfigDir() string {
+	root := systemConfigRoot()
+	if root == "" {
+		return ""
+	}
+	return filepath.Join(root, a.name)
+}
+
+// ConfigFiles returns the existing copies of filename in discovery order
+// (system, user, project, local), without duplicates
+func (a *App) ConfigFiles(filename string) []string {
+	var candidates []string
+	if dir := a.SystemConfigDir(); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, filename))
+	}
+	if dir := a.ConfigDir(); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, filename))
+	}
+	cwd, err := os.Getwd()
+	if err == nil {
+		if path := findProjectFile(cwd, filename); path != "" {
+			candidates = append(candidates, path)
+		}
+		candidates = append(candidates, filepath.Join(cwd, filename))
+	}
+
+	files := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, statErr := os.Stat(path); statErr == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// findProjectFile walks up from the parent of dir looking for filename,
+// stopping after the repository root (a directory containing .git)
+func findProjectFile(dir, filename string) string {
+	if isRepoRoot(dir) {
+		return ""
+	}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+		path := filepath.Join(dir, filename)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		if isRepoRoot(dir) {
+			return ""
+		}
+	}
+}
+
+// isRepoRoot reports whether dir is the top of a version-controlled project
+func isRepoRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
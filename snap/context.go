@@ -18,7 +18,15 @@ type Context struct {
 	cancel        context.CancelFunc
 	metadata      map[string]any
 	currentBinary string   // Current binary being executed (for WrapMany)
+	binaryIndex   int      // Position of currentBinary in binaries
 	binaries      []string // All binaries in WrapMany execution
+
+	// Scoped IO and logger (per-command settings or WithStdout/WithStderr);
+	// nil means the app's
+	io     *snapio.IOManager
+	logger *snapio.Logger
+
+	captured *outputCapture // Output of this run (see App.CaptureOutput)
 }
 
 // Context methods for accessing the underlying Go context
@@ -31,9 +39,12 @@ func (c *Context) Context() context.Context {
 // WithContext creates a new Context with a different underlying context
 func (c *Context) WithContext(ctx context.Context) *Context {
 	return &Context{
-		App:    c.App,
-		Result: c.Result,
-		ctx:    ctx,
+		App:      c.App,
+		Result:   c.Result,
+		ctx:      ctx,
+		io:       c.io,
+		logger:   c.logger,
+		captured: c.captured,
 	}
 }
 
@@ -75,6 +86,51 @@ func (c *Context) Get(key string) any {
 	return c.metadata[key]
 }
 
+// Key is a typed, namespaced metadata key. Middleware and libraries should
+// declare their keys with NewKey using their own namespace so values never
+// collide with user code storing plain string keys.
+type Key[T any] struct {
+	id string
+}
+
+// NewKey creates a typed key scoped to namespace (e.g., "auth", "myapp").
+func NewKey[T any](namespace, name string) Key[T] {
+	if namespace == "" {
+		return Key[T]{id: name}
+	}
+	return Key[T]{id: namespace + ":" + name}
+}
+
+// String returns the fully qualified key name
+func (k Key[T]) String() string { return k.id }
+
+// Set stores v under the key in the context metadata
+func (k Key[T]) Set(c *Context, v T) { c.Set(k.id, v) }
+
+// Get retrieves the value stored under the key; ok is false when the key is
+// missing or holds a value of a different type
+func (k Key[T]) Get(c *Context) (T, bool) { return CtxGet[T](c, k.id) }
+
+// CtxSet stores a typed value in the context metadata
+func CtxSet[T any](c *Context, key string, v T) {
+	c.Set(key, v)
+}
+
+// CtxGet retrieves a typed value from the context metadata. ok is false when
+// the key is missing or the stored value is not a T.
+func CtxGet[T any](c *Context, key string) (T, bool) {
+	v, ok := c.Get(key).(T)
+	return v, ok
+}
+
+// CtxMustGet retrieves a typed value from the context metadata with default fallback
+func CtxMustGet[T any](c *Context, key string, defaultValue T) T {
+	if v, ok := CtxGet[T](c, key); ok {
+		return v
+	}
+	return defaultValue
+}
+
 // Exit helpers integrate with ExitCodeManager. They store an exit request
 // in context metadata and cancel the context; App handles mapping at the end.
 func (c *Context) Exit(code int) {
@@ -97,8 +153,11 @@ func (c *Context) ExitOnError(err error) {
 	if err == nil {
 		return
 	}
-	mgr := c.App.ExitCodes()
-	code := mgr.resolve(err)
+	var cmd *Command
+	if c.Result != nil {
+		cmd = c.Result.Command
+	}
+	code := c.App.ExitCodes().resolveFor(cmd, err)
 	c.ExitWithError(err, code)
 }
 
@@ -115,13 +174,83 @@ func (c *Context) Parent() *Context {
 }
 
 // IO accessors
-func (c *Context) IO() *snapio.IOManager { return c.App.IO() }
-func (c *Context) Stdout() stdio.Writer  { return c.App.IO().Out() }
-func (c *Context) Stderr() stdio.Writer  { return c.App.IO().Err() }
-func (c *Context) Stdin() stdio.Reader   { return c.App.IO().In() }
+func (c *Context) IO() *snapio.IOManager {
+	if c.io != nil {
+		return c.io
+	}
+	return c.App.IO()
+}
+func (c *Context) Stdout() stdio.Writer { return c.IO().Out() }
+func (c *Context) Stderr() stdio.Writer { return c.IO().Err() }
+func (c *Context) Stdin() stdio.Reader  { return c.IO().In() }
+
+// Logger returns the logger for this context: the app's, or a copy bound to
+// the scoped IO when the command customizes IO or logging
+func (c *Context) Logger() *snapio.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return c.App.Logger()
+}
+
+// WithStdout returns a copy of the context whose Stdout (and logger) write to
+// w; the app and other contexts are unaffected
+func (c *Context) WithStdout(w stdio.Writer) *Context {
+	return c.withIO(c.IO().Clone().WithOut(w))
+}
+
+// WithStderr returns a copy of the context whose Stderr (and logger errors)
+// write to w; the app and other contexts are unaffected
+func (c *Context) WithStderr(w stdio.Writer) *Context {
+	return c.withIO(c.IO().Clone().WithErr(w))
+}
+
+// withIO copies the context with io and a logger bound to it
+func (c *Context) withIO(io *snapio.IOManager) *Context {
+	scoped := *c
+	scoped.io = io
+	scoped.logger = c.Logger().Clone(io)
+	return &scoped
+}
+
+// scopeIO applies cmd's IO and logger settings (ancestors first) to copies of
+// the app's, leaving app-wide state untouched
+func (c *Context) scopeIO(cmd *Command) {
+	if cmd == nil || !cmd.scopesIO() {
+		return
+	}
+	chain := append(cmd.ancestors(), cmd)
+	c.io = c.App.IO().Clone()
+	for _, command := range chain {
+		for _, fn := range command.ioConfig {
+			fn(c.io)
+		}
+	}
+	c.logger = c.Logger().Clone(c.io)
+	for _, command := range chain {
+		for _, fn := range command.loggerConfig {
+			fn(c.logger)
+		}
+	}
+}
 
 // Convenience methods for flag access - delegates to ParseResult
 
+// IsSet reports whether the flag was explicitly provided on the command line
+func (c *Context) IsSet(name string) bool {
+	return c.Result.IsSet(name)
+}
+
+// IsDefault reports whether the flag holds its default value (not set via CLI or env)
+func (c *Context) IsDefault(name string) bool {
+	return c.Result.IsDefault(name)
+}
+
+// Source reports where the flag's value came from (flag, env or default)
+func (c *Context) Source(name string) ValueSource {
+	return c.Result.Source(name)
+}
+
 // String retrieves a string flag value (safe access)
 func (c *Context) String(name string) (string, bool) {
 	return c.Result.GetString(name)
@@ -142,6 +271,46 @@ func (c *Context) MustInt(name string, defaultValue int) int {
 	return c.Result.MustGetInt(name, defaultValue)
 }
 
+// Int64 retrieves an int64 flag value (safe access)
+func (c *Context) Int64(name string) (int64, bool) {
+	return c.Result.GetInt64(name)
+}
+
+// MustInt64 retrieves an int64 flag value with default fallback
+func (c *Context) MustInt64(name string, defaultValue int64) int64 {
+	return c.Result.MustGetInt64(name, defaultValue)
+}
+
+// Int32 retrieves an int32 flag value (safe access)
+func (c *Context) Int32(name string) (int32, bool) {
+	return c.Result.GetInt32(name)
+}
+
+// MustInt32 retrieves an int32 flag value with default fallback
+func (c *Context) MustInt32(name string, defaultValue int32) int32 {
+	return c.Result.MustGetInt32(name, defaultValue)
+}
+
+// Uint retrieves a uint flag value (safe access)
+func (c *Context) Uint(name string) (uint, bool) {
+	return c.Result.GetUint(name)
+}
+
+// MustUint retrieves a uint flag value with default fallback
+func (c *Context) MustUint(name string, defaultValue uint) uint {
+	return c.Result.MustGetUint(name, defaultValue)
+}
+
+// Uint64 retrieves a uint64 flag value (safe access)
+func (c *Context) Uint64(name string) (uint64, bool) {
+	return c.Result.GetUint64(name)
+}
+
+// MustUint64 retrieves a uint64 flag value with default fallback
+func (c *Context) MustUint64(name string, defaultValue uint64) uint64 {
+	return c.Result.MustGetUint64(name, defaultValue)
+}
+
 // Bool retrieves a bool flag value (safe access)
 func (c *Context) Bool(name string) (bool, bool) {
 	return c.Result.GetBool(name)
@@ -202,6 +371,36 @@ func (c *Context) MustIntSlice(name string, defaultValue []int) []int {
 	return c.Result.MustGetIntSlice(name, defaultValue)
 }
 
+// FloatSlice retrieves a float64 slice flag value (safe access)
+func (c *Context) FloatSlice(name string) ([]float64, bool) {
+	return c.Result.GetFloatSlice(name)
+}
+
+// MustFloatSlice retrieves a float64 slice flag value with default fallback
+func (c *Context) MustFloatSlice(name string, defaultValue []float64) []float64 {
+	return c.Result.MustGetFloatSlice(name, defaultValue)
+}
+
+// DurationSlice retrieves a duration slice flag value (safe access)
+func (c *Context) DurationSlice(name string) ([]time.Duration, bool) {
+	return c.Result.GetDurationSlice(name)
+}
+
+// MustDurationSlice retrieves a duration slice flag value with default fallback
+func (c *Context) MustDurationSlice(name string, defaultValue []time.Duration) []time.Duration {
+	return c.Result.MustGetDurationSlice(name, defaultValue)
+}
+
+// EnumSlice retrieves a multi-value enum flag value (safe access)
+func (c *Context) EnumSlice(name string) ([]string, bool) {
+	return c.Result.GetEnumSlice(name)
+}
+
+// MustEnumSlice retrieves a multi-value enum flag value with default fallback
+func (c *Context) MustEnumSlice(name string, defaultValue []string) []string {
+	return c.Result.MustGetEnumSlice(name, defaultValue)
+}
+
 // Global flag access methods
 
 // GlobalString retrieves a global string flag value (safe access)
@@ -224,6 +423,46 @@ func (c *Context) MustGlobalInt(name string, defaultValue int) int {
 	return c.Result.MustGetGlobalInt(name, defaultValue)
 }
 
+// GlobalInt64 retrieves a global int64 flag value (safe access)
+func (c *Context) GlobalInt64(name string) (int64, bool) {
+	return c.Result.GetGlobalInt64(name)
+}
+
+// MustGlobalInt64 retrieves a global int64 flag value with default fallback
+func (c *Context) MustGlobalInt64(name string, defaultValue int64) int64 {
+	return c.Result.MustGetGlobalInt64(name, defaultValue)
+}
+
+// GlobalInt32 retrieves a global int32 flag value (safe access)
+func (c *Context) GlobalInt32(name string) (int32, bool) {
+	return c.Result.GetGlobalInt32(name)
+}
+
+// MustGlobalInt32 retrieves a global int32 flag value with default fallback
+func (c *Context) MustGlobalInt32(name string, defaultValue int32) int32 {
+	return c.Result.MustGetGlobalInt32(name, defaultValue)
+}
+
+// GlobalUint retrieves a global uint flag value (safe access)
+func (c *Context) GlobalUint(name string) (uint, bool) {
+	return c.Result.GetGlobalUint(name)
+}
+
+// MustGlobalUint retrieves a global uint flag value with default fallback
+func (c *Context) MustGlobalUint(name string, defaultValue uint) uint {
+	return c.Result.MustGetGlobalUint(name, defaultValue)
+}
+
+// GlobalUint64 retrieves a global uint64 flag value (safe access)
+func (c *Context) GlobalUint64(name string) (uint64, bool) {
+	return c.Result.GetGlobalUint64(name)
+}
+
+// MustGlobalUint64 retrieves a global uint64 flag value with default fallback
+func (c *Context) MustGlobalUint64(name string, defaultValue uint64) uint64 {
+	return c.Result.MustGetGlobalUint64(name, defaultValue)
+}
+
 // GlobalBool retrieves a global bool flag value (safe access)
 func (c *Context) GlobalBool(name string) (bool, bool) {
 	return c.Result.GetGlobalBool(name)
@@ -259,6 +498,21 @@ func (c *Context) GlobalIntSlice(name string) ([]int, bool) {
 	return c.Result.GetGlobalIntSlice(name)
 }
 
+// GlobalFloatSlice retrieves a global float64 slice flag value (safe access)
+func (c *Context) GlobalFloatSlice(name string) ([]float64, bool) {
+	return c.Result.GetGlobalFloatSlice(name)
+}
+
+// GlobalDurationSlice retrieves a global duration slice flag value (safe access)
+func (c *Context) GlobalDurationSlice(name string) ([]time.Duration, bool) {
+	return c.Result.GetGlobalDurationSlice(name)
+}
+
+// GlobalEnumSlice retrieves a global multi-value enum flag value (safe access)
+func (c *Context) GlobalEnumSlice(name string) ([]string, bool) {
+	return c.Result.GetGlobalEnumSlice(name)
+}
+
 // Positional argument access methods
 
 // ArgString retrieves a string positional argument value (safe access)
@@ -353,11 +607,51 @@ func (c *Context) Command() middleware.Command {
 	return c.Result.Command
 }
 
+// CommandPath returns the space-separated path of the executed command from
+// the app root ("server up"), or "" when no command was run
+func (c *Context) CommandPath() string {
+	if c.Result.Command == nil {
+		return ""
+	}
+	return c.App.commandPath(c.Result.Command)
+}
+
+// ParentCommand returns the parent of the executed command ("server" in
+// "server up"), or nil for a top-level command or when no command was run
+func (c *Context) ParentCommand() *Command {
+	if c.Result.Command == nil {
+		return nil
+	}
+	return c.Result.Command.parent
+}
+
+// RootCommand returns the top-level command of the executed command path
+// ("server" in "server up"), or nil when no command was run
+func (c *Context) RootCommand() *Command {
+	cmd := c.Result.Command
+	for cmd != nil && cmd.parent != nil {
+		cmd = cmd.parent
+	}
+	return cmd
+}
+
 // Args returns positional arguments (non-flag arguments after parsing)
 func (c *Context) Args() []string {
 	return c.Result.Args
 }
 
+// UnknownFlags returns the unrecognized flags, with their values, of a
+// command with CollectUnknownFlags in command-line order
+func (c *Context) UnknownFlags() []string {
+	return c.Result.UnknownFlags()
+}
+
+// PassthroughArgs returns the arguments given after "--" (e.g. "cmd args..." in
+// "run -- cmd args..."), or nil if the invocation had no terminator
+func (c *Context) PassthroughArgs() []string {
+	return c.Result.ArgsAfterTerminator()
+}
+
 // RawArgs returns the original unparsed arguments as passed to RunWithArgs.
 // This includes all flags, commands, and arguments before any parsing.
 // The binary name (os.Args[0]) is NOT included.
@@ -383,6 +677,15 @@ func (c *Context) CurrentBinary() string {
 	return c.currentBinary
 }
 
+// CurrentBinaryIndex returns the position of CurrentBinary in Binaries, e.g.
+// for "[2/3] go1.22" progress output, or -1 outside a WrapMany() execution.
+func (c *Context) CurrentBinaryIndex() int {
+	if c.binaries == nil {
+		return -1
+	}
+	return c.binaryIndex
+}
+
 // Binaries returns all binaries configured in WrapMany().
 // Returns nil for single Wrap() configurations.
 //
@@ -413,6 +716,12 @@ func (c *Context) WrapperResult() (*ExecResult, bool) {
 	return nil, false
 }
 
+// SetWrapperStdin replaces the stdin of the wrapped binary for this run; call
+// it from BeforeExec to feed generated input, e.g. a rendered manifest
+func (c *Context) SetWrapperStdin(r stdio.Reader) {
+	c.Set("__wrapper_stdin__", r)
+}
+
 // App metadata accessors
 
 // AppName returns the application name
@@ -425,6 +734,15 @@ func (c *Context) AppVersion() string {
 	return c.App.version
 }
 
+// AppBuildInfo returns the build information set with App.VersionInfo
+// (zero value if not set)
+func (c *Context) AppBuildInfo() BuildInfo {
+	if c.App.buildInfo == nil {
+		return BuildInfo{}
+	}
+	return *c.App.buildInfo
+}
+
 // AppDescription returns the application description
 func (c *Context) AppDescription() string {
 	return c.App.description
@@ -439,25 +757,25 @@ func (c *Context) AppAuthors() []Author {
 
 // LogDebug logs a debug message (purple circle by default)
 func (c *Context) LogDebug(format string, args ...any) {
-	c.App.Logger().Debug(format, args...)
+	c.Logger().Debug(format, args...)
 }
 
 // LogInfo logs an informational message (blue circle by default)
 func (c *Context) LogInfo(format string, args ...any) {
-	c.App.Logger().Info(format, args...)
+	c.Logger().Info(format, args...)
 }
 
 // LogSuccess logs a success message (green circle by default)
 func (c *Context) LogSuccess(format string, args ...any) {
-	c.App.Logger().Success(format, args...)
+	c.Logger().Success(format, args...)
 }
 
 // LogWarning logs a warning message (yellow circle by default)
 func (c *Context) LogWarning(format string, args ...any) {
-	c.App.Logger().Warning(format, args...)
+	c.Logger().Warning(format, args...)
 }
 
 // LogError logs an error message (red circle by default)
 func (c *Context) LogError(format string, args ...any) {
-	c.App.Logger().Error(format, args...)
+	c.Logger().Error(format, args...)
 }
@@ -0,0 +1,234 @@
+package snap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dzonerzy/go-snap/middleware"
+)
+
+// CrashOption configures the app's CrashPolicy
+type CrashOption func(*crashPolicy)
+
+// crashPolicy is how the app handles panics (see CrashPolicy)
+type crashPolicy struct {
+	reportDir string
+	code      int
+	hasCode   bool
+}
+
+// CrashError reports a panic recovered under the app's CrashPolicy
+type CrashError struct {
+	Panic  any
+	Stack  []byte
+	Report string // Path of the crash report file; empty when none was written
+}
+
+// Error describes the panic and where its report was written
+func (e *CrashError) Error() string {
+	msg := fmt.Sprintf("panic: %v", e.Panic)
+	if e.Report != "" {
+		msg += " (crash report: " + e.Report + ")"
+	}
+	return msg
+}
+
+// CrashPolicy recovers panics anywhere in a run (parsing, hooks, middleware
+// and actions) and returns them as a *CrashError, including panics already
+// recovered by the Recovery middleware:
+//
+//	app.CrashPolicy(snap.WriteCrashReport("~/.myapp/crash"), snap.ExitCode(70))
+//
+// Without ExitCode the error resolves through ExitCodes like any other
+// (GeneralError by default, EX_SOFTWARE with UseSysexits).
+func (a *App) CrashPolicy(options ...CrashOption) *App {
+	a.crash = &crashPolicy{}
+	for _, option := range options {
+		option(a.crash)
+	}
+	return a
+}
+
+// WriteCrashReport writes a report for each panic into dir (a leading ~ is
+// the home directory): the panic, its stack trace, the command line with
+// Secret flag values redacted and the app, Go and platform versions
+func WriteCrashReport(dir string) CrashOption {
+	return func(p *crashPolicy) { p.reportDir = dir }
+}
+
+// ExitCode makes crashes exit with code, whatever the ExitCodes mappings
+func ExitCode(code int) CrashOption {
+	return func(p *crashPolicy) { p.code, p.hasCode = code, true }
+}
+
+// runGuarded runs args, turning panics into crash errors when the app has a
+// CrashPolicy
+func (a *App) runGuarded(ctx context.Context, args []string) (err error) {
+	if a.crash == nil {
+		return a.run(ctx, args)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = a.crashed(r, debug.Stack(), args)
+		}
+	}()
+	err = a.run(ctx, args)
+	var recovered *middleware.RecoveryError
+	if errors.As(err, &recovered) {
+		err = a.crashed(recovered.Panic, recovered.Stack, args)
+	}
+	return err
+}
+
+// crashed writes the crash report and builds the error returned for a panic
+func (a *App) crashed(panicValue any, stack []byte, args []string) error {
+	crashErr := &CrashError{Panic: panicValue, Stack: stack}
+	if a.crash.reportDir != "" {
+		crashErr.Report, _ = a.writeCrashReport(crashErr, args)
+	}
+	if a.crash.hasCode {
+		return &ExitError{Code: a.crash.code, Err: crashErr}
+	}
+	return crashErr
+}
+
+// writeCrashReport writes the report of crashErr and returns its path
+func (a *App) writeCrashReport(crashErr *CrashError, args []string) (string, error) {
+	dir := a.crash.reportDir
+	if dir == "~" || strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s crash report\n\n", a.name)
+	fmt.Fprintf(&b, "Time:     %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version:  %s\n", a.version)
+	fmt.Fprintf(&b, "Go:       %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Command:  %s\n", strings.Join(append([]string{a.name}, a.redactArgs(args)...), " "))
+	fmt.Fprintf(&b, "Panic:    %v\n\n", crashErr.Panic)
+	if len(crashErr.Stack) > 0 {
+		b.Write(crashErr.Stack)
+	} else {
+		b.WriteString("(no stack trace: enable it in the Recovery middleware)\n")
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-crash-%s-%d.txt", a.name, now.Format("20060102-150405"), os.Getpid()))
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// redactArgs returns args with the values of Secret flags (of the app or any
+// command) replaced by "<redacted>". It walks short flag clusters and resolves
+// abbreviated long flags the way the parser does, so a secret value never
+// shows up in a report or trace.
+func (a *App) redactArgs(args []string) []string {
+	long, short := map[string]*Flag{}, map[rune]*Flag{}
+	// When commands reuse a name, the secret (or value taking) flag wins
+	keep := func(old, flag *Flag) bool {
+		return old == nil || flag.secret && !old.secret || flag.RequiresValue() && !old.RequiresValue()
+	}
+	collect := func(flags map[string]*Flag) {
+		for name, flag := range flags {
+			if keep(long[name], flag) {
+				long[name] = flag
+			}
+			if flag.Short != 0 && keep(short[flag.Short], flag) {
+				short[flag.Short] = flag
+			}
+		}
+	}
+	collect(a.flags)
+	for _, cmd := range a.commands {
+		eachCommand(cmd, func(c *Command) { collect(c.flags) })
+	}
+
+	out := append([]string(nil), args...)
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" {
+			break
+		}
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, hasValue := strings.Cut(name, "=")
+			flag := long[name]
+			if flag == nil && a.allowAbbreviations {
+				flag = longByPrefix(long, name)
+			}
+			switch {
+			case flag == nil:
+			case flag.secret && hasValue:
+				out[i] = "--" + name + "=" + redacted
+			case hasValue || !flag.RequiresValue() || i+1 >= len(out):
+			case flag.secret:
+				i++
+				out[i] = redacted
+			default:
+				i++ // the value may look like a flag
+			}
+			continue
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		// Walk the cluster (-vt HUNTER2, -vtHUNTER2) until a flag takes the rest
+		for j, r := range arg[1:] {
+			flag := short[r]
+			if flag == nil {
+				break
+			}
+			if !flag.RequiresValue() && !flag.optionalValue {
+				continue
+			}
+			rest := 1 + j + utf8.RuneLen(r)
+			switch {
+			case flag.secret && rest < len(arg):
+				out[i] = arg[:rest] + redacted
+			case rest < len(arg) || !flag.RequiresValue() || i+1 >= len(out):
+			case flag.secret:
+				i++
+				out[i] = redacted
+			default:
+				i++
+			}
+			break
+		}
+	}
+	return out
+}
+
+// longByPrefix resolves an abbreviated long flag among flags the way
+// findFlagByPrefix does; when several match, a secret one is returned so an
+// ambiguous prefix is still redacted
+func longByPrefix(flags map[string]*Flag, prefix string) *Flag {
+	if prefix == "" {
+		return nil
+	}
+	var match *Flag
+	for name, flag := range flags {
+		if !matchesFlagPrefix(name, prefix, flag) {
+			continue
+		}
+		if match == nil || flag.secret {
+			match = flag
+		}
+	}
+	return match
+}
@@ -0,0 +1,183 @@
+package snap
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snapcreds"
+)
+
+// CredentialResolver looks up the credential a scheme:ref value names (see
+// App.CredentialResolver)
+type CredentialResolver interface {
+	ResolveCredential(ref string) (string, error)
+}
+
+// CredentialResolverFunc adapts a function to CredentialResolver
+type CredentialResolverFunc func(ref string) (string, error)
+
+// ResolveCredential calls f(ref)
+func (f CredentialResolverFunc) ResolveCredential(ref string) (string, error) {
+	return f(ref)
+}
+
+// CredentialFlag adds a string flag holding a password, token or key. Its
+// value, whether from the command line, the environment or the default, is
+// resolved while parsing:
+//
+//   - "@path" reads the file (one trailing newline dropped), "-" reads stdin
+//     and "@@x" is the literal "@x"
+//   - "env:VAR" reads the environment variable VAR
+//   - "keyring:NAME" reads the credential store of the auth command (see
+//     App.Auth and KeyringResolver)
+//   - "scheme:ref" uses a resolver registered with App.CredentialResolver
+//   - anything else is the credential itself
+//
+// The flag is Secret: its value never shows in help, dumps, crash reports or
+// the debug trace, and resolution errors only name the reference.
+func (a *App) CredentialFlag(name, description string) *FlagBuilder[string, *App] {
+	f := a.StringFlag(name, description)
+	f.flag.credential, f.flag.secret = true, true
+	return f
+}
+
+// CredentialFlag adds a credential flag to the command (see App.CredentialFlag)
+func (c *CommandBuilder) CredentialFlag(name, description string) *FlagBuilder[string, *CommandBuilder] {
+	f := c.StringFlag(name, description)
+	f.flag.credential, f.flag.secret = true, true
+	return f
+}
+
+// CredentialFlag adds a credential flag to the group (see App.CredentialFlag)
+func (g *FlagGroupBuilder[P]) CredentialFlag(name, description string) *FlagBuilder[string, *FlagGroupBuilder[P]] {
+	f := g.StringFlag(name, description)
+	f.flag.credential, f.flag.secret = true, true
+	return f
+}
+
+// CredentialResolver resolves credential flag values of the form scheme:ref
+// with r, e.g. a secrets manager:
+//
+//	app.CredentialResolver("vault", snap.CredentialResolverFunc(func(ref string) (string, error) {
+//		return vaultClient.Read(ref)
+//	}))
+//	// myapp deploy --token vault:ci/deploy-token
+//
+// Registering "keyring" replaces the OS keyring backend, "env" the
+// environment lookup.
+func (a *App) CredentialResolver(scheme string, r CredentialResolver) *App {
+	if a.credentialResolvers == nil {
+		a.credentialResolvers = make(map[string]CredentialResolver)
+	}
+	a.credentialResolvers[scheme] = r
+	return a
+}
+
+// credentialResolver returns the resolver for scheme, falling back to the
+// built-in env and keyring backends
+func (a *App) credentialResolver(scheme string) CredentialResolver {
+	if r, ok := a.credentialResolvers[scheme]; ok {
+		return r
+	}
+	switch scheme {
+	case "env":
+		return CredentialResolverFunc(func(name string) (string, error) {
+			if value, ok := os.LookupEnv(name); ok {
+				return value, nil
+			}
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		})
+	case "keyring":
+		return keyringResolver(a.credentialStore())
+	}
+	return nil
+}
+
+// resolveCredentials replaces the values of the credential flags in scope
+// with the credentials they reference. Strict parses keep values literal.
+func (p *Parser) resolveCredentials(result *ParseResult) error {
+	if p.strict || !p.hasCredentials {
+		return nil
+	}
+	resolve := func(flags map[string]*Flag, global bool) error {
+		for name, flag := range flags {
+			if !flag.credential || flag.IsGlobal() != global {
+				continue
+			}
+			values := result.StringFlags
+			if global {
+				values = result.GlobalStringFlags
+			}
+			value, ok := values[name]
+			if !ok || value == "" {
+				continue
+			}
+			resolved, err := p.resolveCredential(flag, value)
+			if err != nil {
+				return err
+			}
+			values[name] = resolved
+		}
+		return nil
+	}
+
+	if err := resolve(p.app.flags, true); err != nil {
+		return err
+	}
+	if err := resolve(p.app.flags, false); err != nil {
+		return err
+	}
+	if result.Command == nil {
+		return nil
+	}
+	if err := resolve(result.Command.flags, false); err != nil {
+		return err
+	}
+	return resolve(result.Command.flags, true)
+}
+
+// resolveCredential returns the credential value references
+func (p *Parser) resolveCredential(flag *Flag, value string) (string, error) {
+	if value[0] == '@' || value == "-" {
+		resolved, err := p.resolveValueSource(flag, []byte(value))
+		return string(resolved), err
+	}
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	resolver := p.app.credentialResolver(scheme)
+	if resolver == nil {
+		return value, nil // Not a reference, e.g. a password containing ':'
+	}
+	resolved, err := resolver.ResolveCredential(ref)
+	if err != nil {
+		return "", &ParseError{
+			Type:    ErrorTypeInvalidValue,
+			Message: "cannot resolve credential for --" + flag.Name + " from " + scheme + ":" + ref + ": " + err.Error(),
+			Flag:    flag.Name,
+			msgID:   MsgCredentialUnresolved,
+			msgArgs: []any{flag.Name, scheme + ":" + ref, err},
+		}
+	}
+	return resolved, nil
+}
+
+// KeyringResolver returns the OS keyring backend of credential flags:
+// keyring:NAME reads the secret stored as NAME of service (see snapcreds),
+// keyring:SERVICE/NAME names the service too.
+func KeyringResolver(service string) CredentialResolver {
+	return keyringResolver(snapcreds.System(), service)
+}
+
+// keyringResolver resolves keyring:[SERVICE/]NAME references from backend
+func keyringResolver(backend snapcreds.Backend, service string) CredentialResolver {
+	return CredentialResolverFunc(func(ref string) (string, error) {
+		svc, name := service, ref
+		if before, after, ok := strings.Cut(ref, "/"); ok {
+			svc, name = before, after
+		}
+		return backend.Load(svc, name)
+	})
+}
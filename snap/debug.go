@@ -0,0 +1,194 @@
+package snap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// debugFlag enables tracing for a single run; it is removed from the
+// arguments before parsing and only recognized before "--"
+const debugFlag = "--snap-debug"
+
+// capabilitiesFlag prints the terminal capabilities instead of running, for
+// bug reports; like debugFlag it is recognized before "--" in any app
+const capabilitiesFlag = "--snap-capabilities"
+
+// Debug enables a diagnostic trace of each run on stderr: parse states,
+// matched command, flag sources, middleware order, hooks, wrapper argv and
+// timings. The trace is also enabled by SNAP_DEBUG=1 or --snap-debug.
+func (a *App) Debug(enabled bool) *App {
+	a.debug = enabled
+	return a
+}
+
+// debugEnabled reports whether Debug or SNAP_DEBUG (anything but "", "0" or
+// "false") turned tracing on
+func (a *App) debugEnabled() bool {
+	if a.debug {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("SNAP_DEBUG"))) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
+// startTrace installs a tracer for this run when debugging is enabled and
+// strips --snap-debug from args
+func (a *App) startTrace(args []string) []string {
+	a.tracer = nil
+	enabled := a.debugEnabled()
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == debugFlag {
+			args = append(args[:i:i], args[i+1:]...)
+			enabled = true
+			break
+		}
+	}
+	if enabled {
+		a.tracer = &tracer{w: a.IO().Err(), start: time.Now()}
+		a.tracer.printf("start", "%s %q", a.name, a.redactArgs(args))
+		caps := a.IO().Capabilities()
+		a.tracer.printf("io", "stdin tty=%t stdout tty=%t color=%d unicode=%t hyperlinks=%t size=%dx%d",
+			caps.StdinTTY, caps.StdoutTTY, caps.ColorLevel, caps.Unicode, caps.Hyperlinks, caps.Width, caps.Height)
+	}
+	return args
+}
+
+// capabilitiesRequested reports whether args ask for the capability report
+func capabilitiesRequested(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == capabilitiesFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// showCapabilities prints what the terminal supports (see snapio.Capabilities),
+// after the same Windows VT setup as a normal run
+func (a *App) showCapabilities() error {
+	a.enableVirtualTerminal()
+	_, err := fmt.Fprintf(a.IO().Out(), "%s %s\n%s", a.name, a.version, a.IO().Capabilities())
+	return err
+}
+
+// tracer writes the debug trace; all methods are no-ops on a nil tracer
+type tracer struct {
+	w     io.Writer
+	start time.Time
+}
+
+// printf writes one trace line prefixed with the time since the run started
+func (t *tracer) printf(stage, format string, args ...any) {
+	if t == nil {
+		return
+	}
+	elapsed := time.Since(t.start).Round(time.Microsecond)
+	fmt.Fprintf(t.w, "[snap %10s] %-10s %s\n", elapsed, stage, fmt.Sprintf(format, args...))
+}
+
+// timed runs fn and traces its duration and error
+func (t *tracer) timed(stage, name string, fn func() error) error {
+	if t == nil {
+		return fn()
+	}
+	begin := time.Now()
+	err := fn()
+	t.done(stage, name, begin, err)
+	return err
+}
+
+// done traces the end of a stage started at begin
+func (t *tracer) done(stage, name string, begin time.Time, err error) {
+	if t == nil {
+		return
+	}
+	took := time.Since(begin).Round(time.Microsecond)
+	if err != nil {
+		t.printf(stage, "%s failed in %s: %s", name, took, firstLine(err.Error()))
+		return
+	}
+	t.printf(stage, "%s done in %s", name, took)
+}
+
+// flags traces where each flag of the result got its value from
+func (t *tracer) flags(result *ParseResult) {
+	if t == nil || result == nil {
+		return
+	}
+	names, sources := result.flagSources()
+	if len(names) == 0 {
+		t.printf("flags", "(none set)")
+		return
+	}
+	for i, name := range names {
+		names[i] = "--" + name + " (" + sources[i].String() + ")"
+	}
+	t.printf("flags", "%s", strings.Join(names, ", "))
+}
+
+// run executes a wrapped command, tracing its final argv, exit code and duration
+func (t *tracer) run(cmd *exec.Cmd) error {
+	if t == nil {
+		return cmd.Run()
+	}
+	t.printf("wrapper", "exec %s", QuoteArgs(append([]string{cmd.Path}, cmd.Args[1:]...)))
+	if cmd.Dir != "" {
+		t.printf("wrapper", "dir %s", cmd.Dir)
+	}
+	begin := time.Now()
+	err := cmd.Run()
+	code := 0
+	if ee := toExitError(err); ee != nil {
+		code = ee.Code
+	}
+	t.printf("wrapper", "exit %d in %s", code, time.Since(begin).Round(time.Microsecond))
+	return err
+}
+
+// tracerOf returns the tracer of ctx's app, if any
+func tracerOf(ctx *Context) *tracer {
+	if ctx == nil || ctx.App == nil {
+		return nil
+	}
+	return ctx.App.tracer
+}
+
+// funcName returns a short name for a function value ("middleware.Logger.func1")
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return "?"
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "?"
+	}
+	name := f.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// firstLine returns s up to the first newline
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
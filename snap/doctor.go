@@ -0,0 +1,420 @@
+package snap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	snapio "github.com/dzonerzy/go-snap/io"
+)
+
+// CheckStatus is the outcome of a doctor check
+type CheckStatus int
+
+// Check outcomes, from best to worst
+const (
+	CheckPass CheckStatus = iota
+	CheckWarn
+	CheckFail
+)
+
+// String returns "pass", "warn" or "fail"
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckPass:
+		return "pass"
+	case CheckWarn:
+		return "warn"
+	default:
+		return "fail"
+	}
+}
+
+// MarshalText encodes the status by name in the --json report
+func (s CheckStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// CheckResult is what a doctor check reports
+type CheckResult struct {
+	Status  CheckStatus
+	Message string
+}
+
+// CheckFunc inspects the environment; it runs with the doctor command's context
+type CheckFunc func(ctx *Context) CheckResult
+
+// Pass reports a successful check
+func Pass(format string, args ...any) CheckResult {
+	return CheckResult{Status: CheckPass, Message: fmt.Sprintf(format, args...)}
+}
+
+// Warn reports a problem that does not fail the doctor command
+func Warn(format string, args ...any) CheckResult {
+	return CheckResult{Status: CheckWarn, Message: fmt.Sprintf(format, args...)}
+}
+
+// Fail reports a problem that makes the doctor command exit non-zero
+func Fail(format string, args ...any) CheckResult {
+	return CheckResult{Status: CheckFail, Message: fmt.Sprintf(format, args...)}
+}
+
+// defaultCheckTimeout bounds checks that run processes or dial the network
+const defaultCheckTimeout = 5 * time.Second
+
+// doctorCheck is a named check registered on the DoctorBuilder
+type doctorCheck struct {
+	name string
+	fn   CheckFunc
+}
+
+// DoctorBuilder registers the checks run by the built-in "doctor" command
+type DoctorBuilder struct {
+	app             *App
+	description     string
+	checks          []doctorCheck
+	wrapperBinaries bool
+}
+
+// Doctor enables a "doctor" command that runs the registered checks and prints
+// a pass/warn/fail report (or JSON with --json). The command exits with the
+// GeneralError code when a check fails; warnings alone exit successfully.
+// Like the version command it is added at run time unless the app already
+// defines a "doctor" command.
+func (a *App) Doctor() *DoctorBuilder {
+	if a.doctor == nil {
+		a.doctor = &DoctorBuilder{app: a, description: "Check the environment for problems"}
+	}
+	return a.doctor
+}
+
+// Description overrides the doctor command's description
+func (d *DoctorBuilder) Description(description string) *DoctorBuilder {
+	d.description = description
+	return d
+}
+
+// Check registers a named check; checks run and are reported in registration order
+func (d *DoctorBuilder) Check(name string, fn CheckFunc) *DoctorBuilder {
+	d.checks = append(d.checks, doctorCheck{name: name, fn: fn})
+	return d
+}
+
+// Binary checks that name resolves on PATH
+func (d *DoctorBuilder) Binary(name string) *DoctorBuilder {
+	return d.Check(name, CheckBinary(name))
+}
+
+// BinaryVersion checks that name is on PATH and that the version it prints
+// for versionArgs satisfies every constraint (see CheckBinaryVersion)
+func (d *DoctorBuilder) BinaryVersion(name string, versionArgs []string, constraints ...string) *DoctorBuilder {
+	return d.Check(name, CheckBinaryVersion(name, versionArgs, constraints...))
+}
+
+// Env checks that the environment variables are set and not empty
+func (d *DoctorBuilder) Env(names ...string) *DoctorBuilder {
+	return d.Check("env "+strings.Join(names, ", "), CheckEnv(names...))
+}
+
+// TCP checks that addr ("host:port") accepts connections within timeout
+// (zero means five seconds)
+func (d *DoctorBuilder) TCP(name, addr string, timeout time.Duration) *DoctorBuilder {
+	return d.Check(name, CheckTCP(addr, timeout))
+}
+
+// WrapperBinaries adds a presence check for every binary wrapped by the app
+// or its commands (Wrap, WrapMany), collected when the doctor command runs
+func (d *DoctorBuilder) WrapperBinaries() *DoctorBuilder {
+	d.wrapperBinaries = true
+	return d
+}
+
+// Back returns to the app builder
+func (d *DoctorBuilder) Back() *App {
+	return d.app
+}
+
+// CheckBinary passes when name resolves on PATH and reports its location
+func CheckBinary(name string) CheckFunc {
+	return func(*Context) CheckResult {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return Fail("%s not found in PATH", name)
+		}
+		return Pass("%s", path)
+	}
+}
+
+// versionPattern finds the first dotted version number in command output
+var versionPattern = regexp.MustCompile(`\d+(?:\.\d+)+|\d+`)
+
+// CheckBinaryVersion runs name with versionArgs, takes the first version
+// number from its output ("go version go1.22.5" yields 1.22.5) and checks it
+// against constraints such as ">=1.21" or "<2". Supported operators are >=,
+// >, <=, < and = (the default); missing components compare as zero.
+func CheckBinaryVersion(name string, versionArgs []string, constraints ...string) CheckFunc {
+	return func(ctx *Context) CheckResult {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return Fail("%s not found in PATH", name)
+		}
+		runCtx, cancel := context.WithTimeout(ctx, defaultCheckTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(runCtx, path, versionArgs...).CombinedOutput()
+		if err != nil {
+			return Fail("%s %s: %v", name, strings.Join(versionArgs, " "), err)
+		}
+		version := versionPattern.FindString(string(out))
+		if version == "" {
+			return Warn("could not determine %s version", name)
+		}
+		for _, constraint := range constraints {
+			ok, err := versionSatisfies(version, constraint)
+			if err != nil {
+				return Fail("%v", err)
+			}
+			if !ok {
+				return Fail("%s %s does not satisfy %s", name, version, constraint)
+			}
+		}
+		return Pass("%s %s", name, version)
+	}
+}
+
+// CheckEnv passes when every variable is set to a non-empty value
+func CheckEnv(names ...string) CheckFunc {
+	return func(*Context) CheckResult {
+		var missing []string
+		for _, name := range names {
+			if os.Getenv(name) == "" {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return Fail("not set: %s", strings.Join(missing, ", "))
+		}
+		return Pass("set")
+	}
+}
+
+// CheckTCP passes when addr accepts a TCP connection within timeout (zero
+// means five seconds)
+func CheckTCP(addr string, timeout time.Duration) CheckFunc {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	return func(ctx *Context) CheckResult {
+		dialer := net.Dialer{Timeout: timeout}
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return Fail("%s unreachable: %v", addr, err)
+		}
+		_ = conn.Close()
+		return Pass("%s reachable in %s", addr, snapio.FormatDuration(time.Since(start).Round(time.Millisecond)))
+	}
+}
+
+// versionSatisfies compares version against a single constraint
+func versionSatisfies(version, constraint string) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+	op := "="
+	for _, candidate := range []string{">=", "<=", "==", ">", "<", "="} {
+		if strings.HasPrefix(constraint, candidate) {
+			op = candidate
+			constraint = strings.TrimSpace(constraint[len(candidate):])
+			break
+		}
+	}
+	want, err := parseVersion(constraint)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint %q", op+constraint)
+	}
+	have, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	cmp := compareVersions(have, want)
+	switch op {
+	case ">=":
+		return cmp >= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case "<":
+		return cmp < 0, nil
+	default:
+		return cmp == 0, nil
+	}
+}
+
+// parseVersion splits "1.22.5" (an optional leading "v" is allowed) into numbers
+func parseVersion(s string) ([]int, error) {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" {
+		return nil, fmt.Errorf("empty version")
+	}
+	parts := strings.Split(s, ".")
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// compareVersions returns -1, 0 or 1; missing components count as zero
+func compareVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// doctorReport is one line of the report, also the --json element
+type doctorReport struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message,omitempty"`
+}
+
+// addDoctorCommand registers the built-in "doctor" command when Doctor was used
+func (a *App) addDoctorCommand() {
+	if a.doctor == nil {
+		return
+	}
+	if _, exists := a.commands["doctor"]; exists {
+		return
+	}
+	a.Command("doctor", a.doctor.description).
+		BoolFlag("json", "Print the report as JSON").Back().
+		Action(a.doctor.run)
+}
+
+// checkList returns the registered checks plus the wrapper binary checks
+func (d *DoctorBuilder) checkList() []doctorCheck {
+	checks := d.checks
+	if !d.wrapperBinaries {
+		return checks
+	}
+	seen := make(map[string]bool)
+	for _, check := range checks {
+		seen[check.name] = true
+	}
+	for _, bin := range d.app.wrappedBinaries() {
+		if !seen[bin] {
+			seen[bin] = true
+			checks = append(checks, doctorCheck{name: bin, fn: CheckBinary(bin)})
+		}
+	}
+	return checks
+}
+
+// run executes the checks and prints the report
+func (d *DoctorBuilder) run(ctx *Context) error {
+	checks := d.checkList()
+	reports := make([]doctorReport, 0, len(checks))
+	failed, warned := 0, 0
+	for _, check := range checks {
+		result := check.fn(ctx)
+		reports = append(reports, doctorReport{Name: check.name, Status: result.Status, Message: result.Message})
+		switch result.Status {
+		case CheckPass:
+		case CheckWarn:
+			warned++
+		default:
+			failed++
+		}
+	}
+
+	if ctx.MustBool("json", false) {
+		enc := json.NewEncoder(ctx.Stdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	} else {
+		d.printReport(ctx, reports, failed, warned)
+	}
+
+	if failed > 0 {
+		return &ExitError{
+			Code: d.app.ExitCodes().defaults.GeneralError,
+			Err:  fmt.Errorf("%d of %d checks failed", failed, len(reports)),
+		}
+	}
+	return nil
+}
+
+// printReport renders the text report: one line per check and a summary
+func (d *DoctorBuilder) printReport(ctx *Context, reports []doctorReport, failed, warned int) {
+	theme := d.app.Theme()
+	io := ctx.IO()
+	marks := map[CheckStatus]string{
+		CheckPass: theme.SuccessStyle.Sprint(io, "✓"),
+		CheckWarn: snapio.NewStyle().Bold().Fg(theme.Warning).Sprint(io, "!"),
+		CheckFail: theme.ErrorStyle.Sprint(io, "✗"),
+	}
+	width := 0
+	for _, report := range reports {
+		width = max(width, len(report.Name))
+	}
+	out := ctx.Stdout()
+	for _, report := range reports {
+		line := fmt.Sprintf("%s %-*s", marks[report.Status], width, report.Name)
+		if report.Message != "" {
+			line += "  " + report.Message
+		}
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+	passed := len(reports) - failed - warned
+	fmt.Fprintf(out, "\n%d passed, %d warnings, %d failed\n", passed, warned, failed)
+}
+
+// wrappedBinaries lists the binaries of the app's default wrapper and of
+// every command wrapper, in command order
+func (a *App) wrappedBinaries() []string {
+	var bins []string
+	add := func(spec *WrapperSpec) {
+		if spec == nil {
+			return
+		}
+		if spec.Binary != "" {
+			bins = append(bins, spec.Binary)
+		}
+		bins = append(bins, spec.Binaries...)
+	}
+	add(a.defaultWrapper)
+	var walk func(cmds map[string]*Command)
+	walk = func(cmds map[string]*Command) {
+		for _, name := range sortedKeys(cmds) {
+			add(cmds[name].wrapper)
+			walk(cmds[name].subcommands)
+		}
+	}
+	walk(a.commands)
+	return bins
+}
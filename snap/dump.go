@@ -0,0 +1,171 @@
+package snap
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// redacted replaces the value of a Secret flag in dumps and reports
+const redacted = "<redacted>"
+
+// Secret marks the flag's value as sensitive (a password, token or key):
+// DumpInvocation prints it as "<redacted>"
+func (f *FlagBuilder[T, P]) Secret() *FlagBuilder[T, P] {
+	f.flag.secret = true
+	return f
+}
+
+// invocationDump is the JSON document written by DumpInvocation
+type invocationDump struct {
+	App        string                `json:"app"`
+	Version    string                `json:"version,omitempty"`
+	Command    string                `json:"command"`
+	Flags      map[string]dumpedFlag `json:"flags"`
+	Args       map[string]any        `json:"args"`
+	Positional []string              `json:"positional"`
+	RestArgs   []string              `json:"rest_args"`
+}
+
+// dumpedFlag is a flag value and where it came from
+type dumpedFlag struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// DumpInvocation writes the resolved invocation as indented JSON: the
+// command path, every flag in scope with its value and source ("flag",
+// "env", "default" or "none"), the declared arguments, the raw positionals
+// and RestArgs. Values of Secret flags are replaced with "<redacted>".
+// Meant for --print-config style flags and bug reports:
+//
+//	if ctx.MustBool("print-invocation", false) {
+//		return ctx.DumpInvocation(ctx.Stdout())
+//	}
+func (c *Context) DumpInvocation(w io.Writer) error {
+	dump := invocationDump{
+		Flags:      map[string]dumpedFlag{},
+		Args:       map[string]any{},
+		Positional: []string{},
+		RestArgs:   []string{},
+	}
+	flags := map[string]*Flag{}
+	var args []*Arg
+	if app := c.App; app != nil {
+		dump.App, dump.Version = app.name, app.version
+		for name, flag := range app.flags {
+			flags[name] = flag
+		}
+		args = app.args
+	}
+	if result := c.Result; result != nil {
+		if cmd := result.Command; cmd != nil {
+			dump.Command = c.App.commandPath(cmd)
+			for name, flag := range cmd.flags {
+				flags[name] = flag
+			}
+			args = cmd.args
+		}
+		dump.Positional = append(dump.Positional, result.Args...)
+		dump.RestArgs = append(dump.RestArgs, result.RestArgs...)
+	}
+
+	for name, flag := range flags {
+		if flag.descriptionID != "" {
+			continue // --help, --version
+		}
+		entry := dumpedFlag{Source: ValueSourceNone.String()}
+		if value, ok := lookupTyped(c.Result, name, flagValueLookups); ok {
+			entry.Value, entry.Source = value, c.Result.Source(name).String()
+			if flag.secret {
+				entry.Value = redacted
+			}
+		}
+		dump.Flags[name] = entry
+	}
+	for _, arg := range args {
+		value, _ := lookupTyped(c.Result, arg.Name, argValueLookups)
+		dump.Args[arg.Name] = value
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump)
+}
+
+// typedLookup reads a value of one type from a ParseResult
+type typedLookup func(r *ParseResult, name string) (any, bool)
+
+// typed adapts a typed getter to a typedLookup
+func typed[T any](get func(*ParseResult, string) (T, bool)) typedLookup {
+	return func(r *ParseResult, name string) (any, bool) {
+		return get(r, name)
+	}
+}
+
+// flagValueLookups tries every local and global flag type in turn
+var flagValueLookups = []typedLookup{
+	typed((*ParseResult).GetString),
+	typed((*ParseResult).GetEnum),
+	typed((*ParseResult).GetBool),
+	typed((*ParseResult).GetInt),
+	typed((*ParseResult).GetInt64),
+	typed((*ParseResult).GetUint64),
+	typed((*ParseResult).GetDuration),
+	typed((*ParseResult).GetFloat),
+	typed((*ParseResult).GetStringSlice),
+	typed((*ParseResult).GetEnumSlice),
+	typed((*ParseResult).GetIntSlice),
+	typed((*ParseResult).GetFloatSlice),
+	typed((*ParseResult).GetDurationSlice),
+	typed((*ParseResult).GetGlobalString),
+	typed((*ParseResult).GetGlobalEnum),
+	typed((*ParseResult).GetGlobalBool),
+	typed((*ParseResult).GetGlobalInt),
+	typed((*ParseResult).GetGlobalInt64),
+	typed((*ParseResult).GetGlobalUint64),
+	typed((*ParseResult).GetGlobalDuration),
+	typed((*ParseResult).GetGlobalFloat),
+	typed((*ParseResult).GetGlobalStringSlice),
+	typed((*ParseResult).GetGlobalEnumSlice),
+	typed((*ParseResult).GetGlobalIntSlice),
+	typed((*ParseResult).GetGlobalFloatSlice),
+	typed((*ParseResult).GetGlobalDurationSlice),
+}
+
+// argValueLookups tries every positional argument type in turn
+var argValueLookups = []typedLookup{
+	typed((*ParseResult).GetArgString),
+	typed((*ParseResult).GetArgInt),
+	typed((*ParseResult).GetArgBool),
+	typed((*ParseResult).GetArgDuration),
+	typed((*ParseResult).GetArgFloat),
+	typed((*ParseResult).GetArgStringSlice),
+	typed((*ParseResult).GetArgIntSlice),
+}
+
+// lookupTyped returns the value of name from the first matching lookup, with
+// durations spelled out ("1m30s") instead of as nanoseconds
+func lookupTyped(r *ParseResult, name string, lookups []typedLookup) (any, bool) {
+	if r == nil {
+		return nil, false
+	}
+	for _, lookup := range lookups {
+		value, ok := lookup(r, name)
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case time.Duration:
+			return v.String(), true
+		case []time.Duration:
+			spelled := make([]string, len(v))
+			for i, d := range v {
+				spelled[i] = d.String()
+			}
+			return spelled, true
+		}
+		return value, true
+	}
+	return nil, false
+}
@@ -0,0 +1,182 @@
+package snap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// envUnset marks an empty column of the environment listing
+const envUnset = "-"
+
+// envEntry is one environment variable read by a flag or configuration field
+type envEntry struct {
+	variable string // "MYAPP_PORT"
+	target   string // "--port", "serve --port" or the config key
+	typ      string
+	def      string
+	value    string // Current value, redacted for secrets
+}
+
+// EnvCommand registers a built-in command (usually "env") that prints the
+// PrintEnvHelp listing, so operators get the list from the binary itself:
+//
+//	$ myapp env
+//	VARIABLE     FLAG          TYPE    DEFAULT  VALUE
+//	MYAPP_PORT   serve --port  int     8080     9090
+//	MYAPP_TOKEN  --token       string  -        <redacted>
+//
+// The command runs even when the configuration is incomplete. Like the
+// version command it is added at run time unless the app already defines a
+// command with that name.
+func (a *App) EnvCommand(name string) *App {
+	a.envCommand = name
+	return a
+}
+
+// addEnvCommand registers the command enabled by EnvCommand
+func (a *App) addEnvCommand() {
+	if a.envCommand == "" {
+		return
+	}
+	if _, exists := a.commands[a.envCommand]; exists {
+		return
+	}
+	a.envCmd = a.Command(a.envCommand, "List the environment variables read by "+a.name).
+		Action(func(ctx *Context) error {
+			return a.PrintEnvHelp(ctx.Stdout())
+		}).command
+}
+
+// PrintEnvHelp writes every environment variable the app reads to w, sorted
+// by name: those of flags (FromEnv, on the app and every command, hidden
+// ones included) and of configuration fields (env tags), each with the flag
+// or config key it sets, its type, its default and its current value.
+// Values of Secret and credential flags and of config fields tagged
+// secret:"true" are shown as "<redacted>" when set.
+func (a *App) PrintEnvHelp(w io.Writer) error {
+	a.addBuiltins()
+	entries := a.envEntries()
+	rows := make([][]string, 0, len(entries)+1)
+	rows = append(rows, []string{"VARIABLE", "FLAG", "TYPE", "DEFAULT", "VALUE"})
+	for _, e := range entries {
+		rows = append(rows, []string{e.variable, e.target, e.typ, e.def, e.value})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			fmt.Fprintf(&line, "%-*s  ", widths[i], cell)
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// envEntries collects the variables of flags and configuration fields
+func (a *App) envEntries() []envEntry {
+	var entries []envEntry
+	addFlags := func(prefix string, flags map[string]*Flag) {
+		for _, name := range sortedKeys(flags) {
+			flag := flags[name]
+			for _, variable := range flag.EnvVars {
+				entries = append(entries, a.flagEnvEntry(variable, prefix+"--"+name, flag))
+			}
+		}
+	}
+	addFlags("", a.flags)
+	for _, top := range a.Commands() {
+		eachCommandSorted(top, func(cmd *Command) {
+			addFlags(a.commandPath(cmd)+" ", cmd.flags)
+		})
+	}
+
+	if cb := a.configBuilder; cb != nil && cb.schema != nil {
+		for _, key := range sortedKeys(cb.schema.Fields) {
+			field := cb.schema.Fields[key]
+			if field.EnvTag == "" {
+				continue
+			}
+			entries = append(entries, a.fieldEnvEntry(key, field))
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].variable < entries[j].variable })
+	return entries
+}
+
+// flagEnvEntry describes variable as read by flag
+func (a *App) flagEnvEntry(variable, target string, flag *Flag) envEntry {
+	typ := string(flag.Type)
+	if flag.counter {
+		typ = "count"
+	}
+	return envEntry{
+		variable: variable,
+		target:   target,
+		typ:      typ,
+		def:      orUnset(a.getDefaultValue(flag)),
+		value:    envValue(variable, flag.secret || flag.credential),
+	}
+}
+
+// fieldEnvEntry describes the env tag of the configuration field key; the
+// target is the generated flag in CLI mode (see FromFlags)
+func (a *App) fieldEnvEntry(key string, field *FieldSchema) envEntry {
+	target := key
+	flagName := key
+	if field.FlagTag != "" {
+		flagName = field.FlagTag
+	}
+	flag := a.flags[flagName]
+	if a.configBuilder.flagsEnabled && flag != nil {
+		target = "--" + flagName
+	}
+
+	def := field.DefaultTag
+	if def == "" && field.Default != nil {
+		def = formatConfigValue(field.Default)
+	}
+	secret := field.Secret || flag != nil && (flag.secret || flag.credential)
+	if secret {
+		def = ""
+	}
+	return envEntry{
+		variable: field.EnvTag,
+		target:   target,
+		typ:      field.Type.String(),
+		def:      orUnset(def),
+		value:    envValue(field.EnvTag, secret),
+	}
+}
+
+// envValue returns the current value of variable, redacted for secrets
+func envValue(variable string, secret bool) string {
+	value := os.Getenv(variable)
+	switch {
+	case value == "":
+		return envUnset
+	case secret:
+		return redacted
+	}
+	return value
+}
+
+// orUnset returns s, or envUnset when it is empty
+func orUnset(s string) string {
+	if s == "" {
+		return envUnset
+	}
+	return s
+}
@@ -2,7 +2,10 @@ package snap
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/dzonerzy/go-snap/internal/fuzzy"
 )
@@ -14,6 +17,8 @@ type ErrorType string
 const (
 	ErrorTypeUnknownFlag        ErrorType = "unknown_flag"
 	ErrorTypeUnknownCommand     ErrorType = "unknown_command"
+	ErrorTypeAmbiguousFlag      ErrorType = "ambiguous_flag"
+	ErrorTypeAmbiguousCommand   ErrorType = "ambiguous_command"
 	ErrorTypeInvalidFlag        ErrorType = "invalid_flag"
 	ErrorTypeInvalidValue       ErrorType = "invalid_value"
 	ErrorTypeMissingValue       ErrorType = "missing_value"
@@ -23,6 +28,9 @@ const (
 	ErrorTypePermission         ErrorType = "permission"
 	ErrorTypeValidation         ErrorType = "validation"
 	ErrorTypeInvalidArgument    ErrorType = "invalid_argument"
+	ErrorTypeUnavailable        ErrorType = "unavailable" // Flag or command disabled by EnabledIf
+	ErrorTypeLimitExceeded      ErrorType = "limit_exceeded"
+	ErrorTypeGated              ErrorType = "gated" // Command vetoed by an App.Gate
 )
 
 // ParseError represents parsing-specific errors (used by parser.go)
@@ -33,7 +41,20 @@ type ParseError struct {
 	Command        string
 	GroupName      string // For flag group errors - enables contextual help
 	Suggestion     string
+	Candidates     []string // For ambiguous abbreviations - every name the prefix matched
 	CurrentCommand *Command // The command context where error occurred (for flag suggestions)
+
+	// Index and Token locate the offending command-line argument (Index into
+	// the parsed args, after @file expansion). Token is empty when the error is
+	// not tied to one argument, e.g. a missing required flag.
+	Index int
+	Token string
+
+	args []string // parsed args, for echoing the command line
+
+	// Catalog entry and arguments used to render Message in the app's locale
+	msgID   MessageID
+	msgArgs []any
 }
 
 func (e *ParseError) Error() string {
@@ -55,7 +76,8 @@ type CLIError struct {
 	Suggestions    []string
 	Cause          error
 	Context        map[string]any
-	formattedError string // Full formatted error message including suggestions and help
+	args           []string // parsed args, for ShowPosition
+	formattedError string   // Full formatted error message including suggestions and help
 }
 
 // Error implements the error interface
@@ -102,8 +124,10 @@ type ErrorHandler struct {
 	suggestCommands bool
 	suggestFlags    bool
 	maxDistance     int
+	maxSuggestions  int
 	customHandlers  map[ErrorType]func(*CLIError) *CLIError
 	showHelpOnError bool
+	showPosition    bool
 }
 
 // NewErrorHandler creates a new error handler with defaults
@@ -112,6 +136,7 @@ func NewErrorHandler() *ErrorHandler {
 		suggestCommands: false, // Disabled by default - user must opt-in
 		suggestFlags:    false, // Disabled by default - user must opt-in
 		maxDistance:     2,
+		maxSuggestions:  3,
 		customHandlers:  make(map[ErrorType]func(*CLIError) *CLIError),
 	}
 }
@@ -134,6 +159,12 @@ func (eh *ErrorHandler) MaxDistance(distance int) *ErrorHandler {
 	return eh
 }
 
+// MaxSuggestions sets how many ranked suggestions are shown for unknown flags/commands
+func (eh *ErrorHandler) MaxSuggestions(n int) *ErrorHandler {
+	eh.maxSuggestions = n
+	return eh
+}
+
 // ShowHelpOnError controls whether contextual help is printed after an error.
 // When enabled, app-level or command-level help is displayed based on the
 // current parse context.
@@ -142,6 +173,17 @@ func (eh *ErrorHandler) ShowHelpOnError(enabled bool) *ErrorHandler {
 	return eh
 }
 
+// ShowPosition controls whether parse errors echo the command line with a
+// caret under the offending argument:
+//
+//	Error: unknown flag: --prot
+//	  serve --prot 80
+//	        ^^^^^^
+func (eh *ErrorHandler) ShowPosition(enabled bool) *ErrorHandler {
+	eh.showPosition = enabled
+	return eh
+}
+
 // Handle registers a custom handler for a specific error type
 func (eh *ErrorHandler) Handle(typ ErrorType, handler func(*CLIError) *CLIError) *ErrorHandler {
 	eh.customHandlers[typ] = handler
@@ -165,12 +207,17 @@ func (eh *ErrorHandler) ProcessError(err *CLIError, app *App) *CLIError {
 		if eh.suggestCommands {
 			eh.addCommandSuggestions(err, app)
 		}
+	case ErrorTypeAmbiguousFlag:
+		// Ambiguity is not a guess: always list the candidates
+		eh.addCandidateSuggestions(err, "--", app)
+	case ErrorTypeAmbiguousCommand:
+		eh.addCandidateSuggestions(err, "", app)
 	case ErrorTypeFlagGroupViolation:
 		// Flag group errors get contextual help
 		eh.addGroupContext(err, app)
 	case ErrorTypeInvalidFlag, ErrorTypeInvalidValue, ErrorTypeMissingValue,
 		ErrorTypeInternal, ErrorTypeMissingRequired, ErrorTypePermission, ErrorTypeValidation,
-		ErrorTypeInvalidArgument:
+		ErrorTypeInvalidArgument, ErrorTypeUnavailable, ErrorTypeGated:
 		// No suggestions for these by default.
 	}
 
@@ -178,85 +225,194 @@ func (eh *ErrorHandler) ProcessError(err *CLIError, app *App) *CLIError {
 }
 
 // addFlagSuggestions adds fuzzy-matched flag suggestions using internal/fuzzy.
+// Candidates include current command flags, global flags and their short forms.
 func (eh *ErrorHandler) addFlagSuggestions(err *CLIError, app *App) {
 	if flagName, ok := err.Context["flag"].(string); ok {
+		// Single characters carry no signal for edit distance
+		if len(flagName) < 2 {
+			return
+		}
+
 		// Get command context if available
 		var currentCmd *Command
 		if cmd, okCmd := err.Context["current_command"].(*Command); okCmd {
 			currentCmd = cmd
 		}
 
-		// Find similar flag names using fuzzy matching
-		bestMatch := eh.findBestFlagMatch(flagName, app, currentCmd)
-		if bestMatch != "" {
-			_ = err.WithSuggestion(fmt.Sprintf("Did you mean '--%s'?", bestMatch))
-		}
+		// Find similar flags using fuzzy matching on their display form (--name, -n)
+		matches := fuzzy.FindSuggestions("--"+flagName, eh.flagCandidates(app, currentCmd), eh.maxDistance, eh.maxSuggestions)
+		eh.addDidYouMean(err, matches, app)
 	}
 }
 
 // addCommandSuggestions adds fuzzy-matched command suggestions using internal/fuzzy.
+// Candidates are full command paths ("server status") including aliases.
 func (eh *ErrorHandler) addCommandSuggestions(err *CLIError, app *App) {
 	if cmdName, ok := err.Context["command"].(string); ok {
-		// Get command context if available
-		var currentCmd *Command
-		if cmd, okCmd := err.Context["current_command"].(*Command); okCmd {
-			currentCmd = cmd
+		// Prefix the input with the current command path so "server sttaus" matches "server status"
+		input := cmdName
+		current, _ := err.Context["current_command"].(*Command)
+		if current != nil {
+			if path := app.commandPath(current); path != "" {
+				input = path + " " + cmdName
+			}
 		}
 
-		// Find similar command names
-		bestMatch := eh.findBestCommandMatch(cmdName, app, currentCmd)
-		if bestMatch != "" {
-			_ = err.WithSuggestion(fmt.Sprintf("Did you mean '%s'?", bestMatch))
+		matches := fuzzy.FindSuggestions(input, eh.commandCandidates(app), eh.maxDistance, eh.maxSuggestions)
+
+		// Users often forget the parent command: also match the name against
+		// commands nested deeper below the current one
+		for _, path := range eh.nestedCommandMatches(app, current, cmdName) {
+			if len(matches) >= eh.maxSuggestions {
+				break
+			}
+			if !slices.Contains(matches, path) {
+				matches = append(matches, path)
+			}
 		}
+		eh.addDidYouMean(err, matches, app)
 	}
 }
 
-// addGroupContext adds context for flag group violations
-func (eh *ErrorHandler) addGroupContext(err *CLIError, app *App) {
-	// This will be enhanced when we integrate with help system
-	if groupName, ok := err.Context["group"].(string); ok {
-		_ = err.WithSuggestion(
-			fmt.Sprintf(
-				"Run '%s --help' to see valid flag combinations for group '%s'",
-				app.name, groupName,
-			),
-		)
+// nestedSuggestionDepth bounds how many levels below the current command
+// are searched for a mistyped or misplaced command name
+const nestedSuggestionDepth = 3
+
+// nestedCommandMatches returns the full paths of visible commands two to
+// nestedSuggestionDepth levels below parent (the app when nil) whose name or
+// alias equals name, followed by those within the edit distance
+func (eh *ErrorHandler) nestedCommandMatches(app *App, parent *Command, name string) []string {
+	commands := app.commands
+	if parent != nil {
+		commands = parent.subcommands
 	}
-}
 
-// Efficient fuzzy matching using internal/fuzzy package
-func (eh *ErrorHandler) findBestFlagMatch(input string, app *App, currentCmd *Command) string {
-	// Collect app-level flags
-	flagNames := make([]string, 0, len(app.flags))
-	for flagName := range app.flags {
-		flagNames = append(flagNames, flagName)
+	// Index the nested commands by name and alias
+	byName := make(map[string][]string)
+	var names []string
+	var walk func(commands map[string]*Command, depth int)
+	walk = func(commands map[string]*Command, depth int) {
+		for _, cmd := range commands {
+			if cmd.isHidden() {
+				continue
+			}
+			if depth > 1 {
+				for _, n := range append([]string{cmd.name}, cmd.Aliases...) {
+					if _, ok := byName[n]; !ok {
+						names = append(names, n)
+					}
+					byName[n] = append(byName[n], app.commandPath(cmd))
+				}
+			}
+			if depth < nestedSuggestionDepth {
+				walk(cmd.subcommands, depth+1)
+			}
+		}
 	}
+	walk(commands, 1)
 
-	// If we're in a command context, also include command-level flags
-	if currentCmd != nil {
-		for flagName := range currentCmd.flags {
-			flagNames = append(flagNames, flagName)
+	sort.Strings(names)
+	for _, paths := range byName {
+		sort.Strings(paths)
+	}
+
+	var paths []string
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			paths = append(paths, byName[n]...)
 		}
 	}
+	for _, n := range fuzzy.FindSuggestions(name, names, eh.maxDistance, eh.maxSuggestions) {
+		for _, path := range byName[n] {
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// addDidYouMean renders ranked matches: a single inline hint, or a "Did you mean:" list.
+func (eh *ErrorHandler) addDidYouMean(err *CLIError, matches []string, app *App) {
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		_ = err.WithSuggestion(app.msgf(MsgDidYouMean, matches[0]))
+	default:
+		_ = err.WithSuggestion(app.Message(MsgDidYouMeanList) + "\n  " + strings.Join(matches, "\n  "))
+	}
+}
+
+// addCandidateSuggestions lists every candidate matched by an ambiguous abbreviation.
+func (eh *ErrorHandler) addCandidateSuggestions(err *CLIError, prefix string, app *App) {
+	candidates, ok := err.Context["candidates"].([]string)
+	if !ok || len(candidates) == 0 {
+		return
+	}
+	quoted := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		quoted[i] = "'" + prefix + candidate + "'"
+	}
+	_ = err.WithSuggestion(app.msgf(MsgDidYouMeanOneOf, strings.Join(quoted, ", ")))
+}
 
-	return fuzzy.FindBestFlag(input, flagNames, eh.maxDistance)
+// addGroupContext points flag group violations to the group's help
+// ("myapp help deploy --group output")
+func (eh *ErrorHandler) addGroupContext(err *CLIError, app *App) {
+	if groupName, ok := err.Context["group"].(string); ok {
+		command := app.name + " help"
+		if cmd, _ := err.Context["current_command"].(*Command); cmd != nil {
+			command += " " + app.commandPath(cmd)
+		}
+		command += " --group " + QuotePOSIXArg(groupName)
+		_ = err.WithSuggestion(app.msgf(MsgGroupHelpHint, command, groupName))
+	}
 }
 
-func (eh *ErrorHandler) findBestCommandMatch(input string, app *App, currentCmd *Command) string {
-	// Collect app-level commands
-	cmdNames := make([]string, 0, len(app.commands))
-	for cmdName := range app.commands {
-		cmdNames = append(cmdNames, cmdName)
+// flagCandidates collects visible flags valid in the current context in display form.
+func (eh *ErrorHandler) flagCandidates(app *App, currentCmd *Command) []string {
+	candidates := make([]string, 0, len(app.flags)*2)
+	seen := make(map[string]bool)
+	add := func(flags map[string]*Flag) {
+		for name, flag := range flags {
+			if flag.isHidden() || seen[name] {
+				continue
+			}
+			seen[name] = true
+			candidates = append(candidates, "--"+name)
+			if flag.Short != 0 {
+				candidates = append(candidates, "-"+string(flag.Short))
+			}
+		}
 	}
 
-	// If we're in a command context, also include subcommands
+	// If we're in a command context, command-level flags come first
 	if currentCmd != nil {
-		for cmdName := range currentCmd.subcommands {
-			cmdNames = append(cmdNames, cmdName)
-		}
+		add(currentCmd.flags)
 	}
+	add(app.flags)
+	return candidates
+}
 
-	return fuzzy.FindBestCommand(input, cmdNames, eh.maxDistance)
+// commandCandidates collects every visible command path, including alias spellings.
+func (eh *ErrorHandler) commandCandidates(app *App) []string {
+	var candidates []string
+	var walk func(prefix string, commands map[string]*Command)
+	walk = func(prefix string, commands map[string]*Command) {
+		for name, cmd := range commands {
+			if cmd.isHidden() {
+				continue
+			}
+			candidates = append(candidates, prefix+name)
+			for _, alias := range cmd.Aliases {
+				candidates = append(candidates, prefix+alias)
+			}
+			walk(prefix+name+" ", cmd.subcommands)
+		}
+	}
+	walk("", app.commands)
+	return candidates
 }
 
 // formatError builds the error message with suggestions.
@@ -265,12 +421,19 @@ func (eh *ErrorHandler) findBestCommandMatch(input string, app *App, currentCmd
 func (eh *ErrorHandler) formatError(err *CLIError, app *App) *CLIError {
 	var builder strings.Builder
 
-	// Build the main error message
-	builder.WriteString(fmt.Sprintf("Error: %s\n", err.Message))
+	// Build the main error message; porcelain output is just that line
+	builder.WriteString(app.styled(app.Theme().ErrorStyle, app.msgf(MsgError, err.Message)) + "\n")
+	if app.porcelain {
+		err.formattedError = strings.TrimRight(builder.String(), "\n")
+		return err
+	}
+	if eh.showPosition {
+		builder.WriteString(eh.formatPosition(err))
+	}
 
-	// Add suggestions if any
+	// Add suggestions if any (continuation lines of multi-line suggestions are indented too)
 	for _, suggestion := range err.Suggestions {
-		builder.WriteString(fmt.Sprintf("  %s\n", suggestion))
+		builder.WriteString(fmt.Sprintf("  %s\n", strings.ReplaceAll(suggestion, "\n", "\n  ")))
 	}
 
 	// For flag group violations, add group help
@@ -286,13 +449,65 @@ func (eh *ErrorHandler) formatError(err *CLIError, app *App) *CLIError {
 	return err
 }
 
+// positionContext is how many arguments are echoed on each side of the
+// offending one; the rest of a long command line is elided
+const positionContext = 3
+
+// formatPosition echoes the parsed arguments around the offending token with
+// a caret line underneath; empty when the error has no position
+func (eh *ErrorHandler) formatPosition(err *CLIError) string {
+	index, ok := err.Context["index"].(int)
+	if !ok || index < 0 || index >= len(err.args) {
+		return ""
+	}
+	first := max(index-positionContext, 0)
+	last := min(index+positionContext, len(err.args)-1)
+
+	var line, caret strings.Builder
+	line.WriteString("  ")
+	caret.WriteString("  ")
+	if first > 0 {
+		line.WriteString("... ")
+		caret.WriteString("    ")
+	}
+	for i := first; i <= last; i++ {
+		if i > first {
+			line.WriteByte(' ')
+			if i <= index {
+				caret.WriteByte(' ')
+			}
+		}
+		arg := quoteArg(err.args[i])
+		line.WriteString(arg)
+		width := utf8.RuneCountInString(arg)
+		switch {
+		case i < index:
+			caret.WriteString(strings.Repeat(" ", width))
+		case i == index:
+			caret.WriteString(strings.Repeat("^", max(width, 1)))
+		}
+	}
+	if last < len(err.args)-1 {
+		line.WriteString(" ...")
+	}
+	return line.String() + "\n" + caret.String() + "\n"
+}
+
+// quoteArg single-quotes arguments that would not survive a POSIX shell as-is
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`;&|<>()*?[]{}~#!") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
 // formatFlagGroupHelp builds help text for a specific flag group
 func (eh *ErrorHandler) formatFlagGroupHelp(groupName string, app *App) string {
 	var builder strings.Builder
 
 	for _, group := range app.flagGroups {
 		if group.Name == groupName {
-			builder.WriteString(fmt.Sprintf("Flag group '%s':\n", groupName))
+			builder.WriteString(app.msgf(MsgFlagGroup, groupName) + "\n")
 			if group.Description != "" {
 				builder.WriteString(fmt.Sprintf("  %s\n", group.Description))
 			}
@@ -301,7 +516,7 @@ func (eh *ErrorHandler) formatFlagGroupHelp(groupName string, app *App) string {
 				builder.WriteString(fmt.Sprintf("  --%s    %s\n", flag.Name, flag.Description))
 			}
 
-			builder.WriteString(fmt.Sprintf("\nConstraint: %s\n", eh.formatConstraint(group.Constraint)))
+			builder.WriteString("\n" + app.msgf(MsgConstraint, app.formatGroupConstraint(group.Constraint)) + "\n")
 			return builder.String()
 		}
 	}
@@ -309,37 +524,17 @@ func (eh *ErrorHandler) formatFlagGroupHelp(groupName string, app *App) string {
 	if app.currentResult != nil && app.currentResult.Command != nil {
 		for _, group := range app.currentResult.Command.flagGroups {
 			if group.Name == groupName {
-				builder.WriteString(fmt.Sprintf("Flag group '%s':\n", groupName))
+				builder.WriteString(app.msgf(MsgFlagGroup, groupName) + "\n")
 				if group.Description != "" {
 					builder.WriteString(fmt.Sprintf("  %s\n", group.Description))
 				}
 				for _, flag := range group.Flags {
 					builder.WriteString(fmt.Sprintf("  --%s    %s\n", flag.Name, flag.Description))
 				}
-				builder.WriteString(fmt.Sprintf("\nConstraint: %s\n", eh.formatConstraint(group.Constraint)))
+				builder.WriteString("\n" + app.msgf(MsgConstraint, app.formatGroupConstraint(group.Constraint)) + "\n")
 				return builder.String()
 			}
 		}
 	}
 	return ""
 }
-
-// formatConstraint returns a human-readable description of the constraint
-func (eh *ErrorHandler) formatConstraint(constraint GroupConstraintType) string {
-	switch constraint { // exhaustive over GroupConstraintType
-	case GroupMutuallyExclusive:
-		return "Only one of these flags can be used at a time"
-	case GroupRequiredGroup:
-		return "At least one of these flags is required"
-	case GroupAllOrNone:
-		return "Either all of these flags must be provided, or none"
-	case GroupExactlyOne:
-		return "Exactly one of these flags must be provided"
-	case GroupNoConstraint:
-		return ""
-	case GroupAtLeastOne:
-		return "At least one of these flags is required"
-	default:
-		return ""
-	}
-}
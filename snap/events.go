@@ -0,0 +1,107 @@
+package snap
+
+import (
+	"os/exec"
+	"time"
+)
+
+// EventType identifies a lifecycle event delivered to OnEvent handlers
+type EventType string
+
+// Lifecycle events, in the order a run emits them
+const (
+	EventParseCompleted      EventType = "parse_completed"       // Arguments parsed (Err set on failure)
+	EventCommandResolved     EventType = "command_resolved"      // Command to run is known ("" for the app itself)
+	EventActionStarted       EventType = "action_started"        // Command or app action, with middleware, is about to run
+	EventActionFinished      EventType = "action_finished"       // Action returned (Err, Duration)
+	EventWrapperExecStarted  EventType = "wrapper_exec_started"  // Wrapped binary is about to be executed
+	EventWrapperExecFinished EventType = "wrapper_exec_finished" // Wrapped binary exited (ExitCode, Err, Duration)
+	EventErrorDisplayed      EventType = "error_displayed"       // CLI error formatted for the user, with suggestions
+)
+
+// Event describes one step of a run. Fields that do not apply to the event
+// type are zero.
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Command  string        // Space-separated command path ("" for the app itself)
+	Result   *ParseResult  // Set once parsing succeeded
+	Context  *Context      // Set for action and wrapper events
+	Binary   string        // Wrapper events: path of the executed binary
+	Args     []string      // Wrapper events: arguments passed to it
+	ExitCode int           // EventWrapperExecFinished
+	Duration time.Duration // EventParseCompleted and the finished events
+	Err      error
+}
+
+// OnEvent registers a handler observing every run's lifecycle events, e.g.
+// for metrics, audit logs or notifications, without wrapping each command
+// with middleware and hooks:
+//
+//	app.OnEvent(func(e snap.Event) {
+//		if e.Type == snap.EventActionFinished {
+//			metrics.Observe(e.Command, e.Duration, e.Err)
+//		}
+//	})
+//
+// Handlers run synchronously, in registration order, and are never called
+// concurrently, even for wrappers executing binaries in parallel.
+func (a *App) OnEvent(handler func(e Event)) *App {
+	a.eventHandlers = append(a.eventHandlers, handler)
+	return a
+}
+
+// emit delivers e to the event handlers, filling in the time and the
+// command of the current parse result
+func (a *App) emit(e Event) {
+	if a == nil || len(a.eventHandlers) == 0 {
+		return
+	}
+	e.Time = time.Now()
+	if e.Result == nil {
+		e.Result = a.currentResult
+	}
+	if e.Result != nil && e.Result.Command != nil && e.Command == "" {
+		e.Command = a.commandPath(e.Result.Command)
+	}
+
+	a.eventMu.Lock()
+	defer a.eventMu.Unlock()
+	for _, handler := range a.eventHandlers {
+		handler(e)
+	}
+}
+
+// runAction runs a command or app action, emitting the action events
+func (a *App) runAction(ctx *Context, name string, action ActionFunc) error {
+	a.emit(Event{Type: EventActionStarted, Context: ctx})
+	start := time.Now()
+	err := a.tracer.timed("action", name, func() error { return action(ctx) })
+	a.emit(Event{Type: EventActionFinished, Context: ctx, Duration: time.Since(start), Err: err})
+	return err
+}
+
+// execWrapped runs the wrapped binary of cmd, emitting the wrapper events
+func execWrapped(ctx *Context, cmd *exec.Cmd) error {
+	var app *App
+	if ctx != nil {
+		app = ctx.App
+	}
+	app.emit(Event{Type: EventWrapperExecStarted, Context: ctx, Binary: cmd.Path, Args: cmd.Args[1:]})
+	start := time.Now()
+	err := tracerOf(ctx).run(cmd)
+	code := 0
+	if ee := toExitError(err); ee != nil {
+		code = ee.Code
+	}
+	app.emit(Event{
+		Type:     EventWrapperExecFinished,
+		Context:  ctx,
+		Binary:   cmd.Path,
+		Args:     cmd.Args[1:],
+		ExitCode: code,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+	return err
+}
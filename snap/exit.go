@@ -2,7 +2,12 @@ package snap
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/dzonerzy/go-snap/middleware"
 )
@@ -20,6 +25,9 @@ func (e *ExitError) Error() string {
 	return "exit"
 }
 
+// Unwrap returns the underlying error
+func (e *ExitError) Unwrap() error { return e.Err }
+
 // ExitCodeDefaults holds common default codes.
 type ExitCodeDefaults struct {
 	Success         int // default: 0
@@ -41,20 +49,60 @@ func defaultExitDefaults() ExitCodeDefaults {
 	}
 }
 
+// Conventional BSD sysexits(3) codes used by UseSysexits.
+const (
+	SysexitUsage       = 64 // EX_USAGE: command line usage error
+	SysexitDataErr     = 65 // EX_DATAERR: data format error
+	SysexitNoInput     = 66 // EX_NOINPUT: cannot open input
+	SysexitNoUser      = 67 // EX_NOUSER: addressee unknown
+	SysexitNoHost      = 68 // EX_NOHOST: host name unknown
+	SysexitUnavailable = 69 // EX_UNAVAILABLE: service unavailable
+	SysexitSoftware    = 70 // EX_SOFTWARE: internal software error
+	SysexitOSErr       = 71 // EX_OSERR: system error
+	SysexitOSFile      = 72 // EX_OSFILE: critical OS file missing
+	SysexitCantCreat   = 73 // EX_CANTCREAT: can't create (user) output file
+	SysexitIOErr       = 74 // EX_IOERR: input/output error
+	SysexitTempFail    = 75 // EX_TEMPFAIL: temporary failure, retry later
+	SysexitProtocol    = 76 // EX_PROTOCOL: remote error in protocol
+	SysexitNoPerm      = 77 // EX_NOPERM: permission denied
+	SysexitConfig      = 78 // EX_CONFIG: configuration error
+)
+
+// ExitCodeEntry describes a single exit code for documentation purposes.
+type ExitCodeEntry struct {
+	Code        int
+	Names       []string
+	Description string
+}
+
 // ExitCodeManager maps errors and categories to process exit codes.
 type ExitCodeManager struct {
-	codesByName map[string]int
-	codesByType map[reflect.Type]int
-	codesByCLI  map[ErrorType]int
-	defaults    ExitCodeDefaults
+	codesByName  map[string]int
+	codesByType  map[reflect.Type]int
+	codesByCLI   map[ErrorType]int
+	descriptions map[int]string
+	defaults     ExitCodeDefaults
+	app          *App // Owning app, for localized descriptions (nil when standalone)
+
+	// Sentinel errors matched with errors.Is and custom matchers, both in
+	// registration order (see DefineError and DefineErrorFunc)
+	codesBySentinel []sentinelCode
+	codeFuncs       []func(error) (int, bool)
+}
+
+// sentinelCode maps a sentinel error value to an exit code
+type sentinelCode struct {
+	err  error
+	code int
 }
 
 func newExitCodeManager() *ExitCodeManager {
 	m := &ExitCodeManager{
-		codesByName: make(map[string]int),
-		codesByType: make(map[reflect.Type]int),
-		codesByCLI:  make(map[ErrorType]int),
-		defaults:    defaultExitDefaults(),
+		codesByName:  make(map[string]int),
+		codesByType:  make(map[reflect.Type]int),
+		codesByCLI:   make(map[ErrorType]int),
+		descriptions: make(map[int]string),
+		defaults:     defaultExitDefaults(),
 	}
 	// Prewire common CLI mappings
 	m.codesByCLI[ErrorTypeValidation] = m.defaults.ValidationError
@@ -62,12 +110,19 @@ func newExitCodeManager() *ExitCodeManager {
 	m.codesByCLI[ErrorTypeMissingRequired] = m.defaults.MisusageError
 	m.codesByCLI[ErrorTypeUnknownFlag] = m.defaults.MisusageError
 	m.codesByCLI[ErrorTypeUnknownCommand] = m.defaults.MisusageError
+	m.codesByCLI[ErrorTypeAmbiguousFlag] = m.defaults.MisusageError
+	m.codesByCLI[ErrorTypeAmbiguousCommand] = m.defaults.MisusageError
 	m.codesByCLI[ErrorTypeFlagGroupViolation] = m.defaults.MisusageError
+	m.codesByCLI[ErrorTypeUnavailable] = m.defaults.MisusageError
+	m.codesByCLI[ErrorTypeLimitExceeded] = m.defaults.MisusageError
+	m.codesByCLI[ErrorTypeGated] = SysexitUnavailable
 
 	// Prewire middleware types
 	m.codesByType[reflect.TypeOf(&middleware.TimeoutError{})] = m.defaults.GeneralError
 	m.codesByType[reflect.TypeOf(&middleware.ValidationError{})] = m.defaults.ValidationError
 	m.codesByType[reflect.TypeOf(&middleware.RecoveryError{})] = m.defaults.GeneralError
+	m.codesByType[reflect.TypeOf(&CrashError{})] = m.defaults.GeneralError
+	m.DefineError(ErrBinaryNotFound, m.defaults.NotFoundError)
 	return m
 }
 
@@ -81,18 +136,58 @@ func (e *ExitCodeManager) Define(name string, code int) *ExitCodeManager {
 	return e
 }
 
-// DefineError maps a concrete error value (by its dynamic type) to an exit
-// code. During resolution, a matching error type takes precedence over the
+// DefineError maps an error to an exit code. Sentinels created with
+// errors.New or fmt.Errorf match by identity anywhere in the wrap chain
+// (errors.Is), so DefineError(ErrNotFound, 127) also covers
+// fmt.Errorf("load: %w", ErrNotFound); other errors match by dynamic type
+// (errors.As). During resolution, a matching error takes precedence over the
 // default codes but is secondary to an explicit ExitError requested by the
 // action.
 func (e *ExitCodeManager) DefineError(err error, code int) *ExitCodeManager {
 	if err == nil {
 		return e
 	}
-	e.codesByType[reflect.TypeOf(err)] = code
+	if !sentinelTypes[reflect.TypeOf(err)] {
+		e.codesByType[reflect.TypeOf(err)] = code
+		return e
+	}
+	for i, s := range e.codesBySentinel {
+		if s.err == err {
+			e.codesBySentinel[i].code = code
+			return e
+		}
+	}
+	e.codesBySentinel = append(e.codesBySentinel, sentinelCode{err: err, code: code})
 	return e
 }
 
+// DefineErrorFunc adds a matcher returning the exit code for the errors it
+// recognizes, e.g. by inspecting a field of a wrapped error. Matchers run in
+// registration order before the DefineError mappings:
+//
+//	app.ExitCodes().DefineErrorFunc(func(err error) (int, bool) {
+//		var apiErr *APIError
+//		if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+//			return 127, true
+//		}
+//		return 0, false
+//	})
+func (e *ExitCodeManager) DefineErrorFunc(fn func(err error) (int, bool)) *ExitCodeManager {
+	if fn != nil {
+		e.codeFuncs = append(e.codeFuncs, fn)
+	}
+	return e
+}
+
+// sentinelTypes are the dynamic types of errors.New and fmt.Errorf errors,
+// which tell nothing about the error and so are matched by identity
+var sentinelTypes = map[reflect.Type]bool{
+	reflect.TypeOf(errors.New("")):                                      true,
+	reflect.TypeOf(fmt.Errorf("%w", errors.New(""))):                    true,
+	reflect.TypeOf(fmt.Errorf("%w %w", errors.New(""), errors.New(""))): true,
+	reflect.TypeOf(errors.Join(errors.New(""))):                         true,
+}
+
 // DefineCLI overrides the exit code used for a specific CLI error category
 // produced by the parser (e.g., unknown flag/command, validation). CLI mappings
 // are applied when the error is a *CLIError.
@@ -105,39 +200,308 @@ func (e *ExitCodeManager) DefineCLI(typ ErrorType, code int) *ExitCodeManager {
 // Defaults apply when no specific mapping matches.
 func (e *ExitCodeManager) Default(d ExitCodeDefaults) *ExitCodeManager { e.defaults = d; return e }
 
-// resolve converts an error to an exit code according to registered mappings.
+// Describe attaches a human-readable description to an exit code. Descriptions
+// are only used for documentation (see Table) and never affect resolution.
+func (e *ExitCodeManager) Describe(code int, description string) *ExitCodeManager {
+	e.descriptions[code] = description
+	return e
+}
+
+// merge adds the named codes, error mappings and descriptions of other that
+// e does not define yet; its matchers run after e's. CLI mappings and
+// defaults stay e's, as they cover every command.
+func (e *ExitCodeManager) merge(other *ExitCodeManager) {
+	for name, code := range other.codesByName {
+		if _, ok := e.codesByName[name]; !ok {
+			e.codesByName[name] = code
+		}
+	}
+	for typ, code := range other.codesByType {
+		if _, ok := e.codesByType[typ]; !ok {
+			e.codesByType[typ] = code
+		}
+	}
+	for _, s := range other.codesBySentinel {
+		if !slices.ContainsFunc(e.codesBySentinel, func(own sentinelCode) bool { return own.err == s.err }) {
+			e.codesBySentinel = append(e.codesBySentinel, s)
+		}
+	}
+	e.codeFuncs = append(e.codeFuncs, other.codeFuncs...)
+	for code, description := range other.descriptions {
+		if _, ok := e.descriptions[code]; !ok {
+			e.descriptions[code] = description
+		}
+	}
+}
+
+// UseSysexits switches the manager to the conventional BSD sysexits(3) preset:
+// usage errors map to EX_USAGE (64), validation errors to EX_DATAERR (65),
+// permission errors to EX_NOPERM (77), internal errors to EX_SOFTWARE (70) and
+// missing resources to EX_NOINPUT (66). Mappings registered via DefineCLI or
+// DefineError afterwards still override the preset.
+func (e *ExitCodeManager) UseSysexits() *ExitCodeManager {
+	e.defaults = ExitCodeDefaults{
+		Success:         0,
+		GeneralError:    1,
+		MisusageError:   SysexitUsage,
+		ValidationError: SysexitDataErr,
+		NotFoundError:   SysexitNoInput,
+		PermissionError: SysexitNoPerm,
+	}
+
+	usage := []ErrorType{
+		ErrorTypeUnknownFlag,
+		ErrorTypeUnknownCommand,
+		ErrorTypeAmbiguousFlag,
+		ErrorTypeAmbiguousCommand,
+		ErrorTypeInvalidFlag,
+		ErrorTypeInvalidValue,
+		ErrorTypeMissingValue,
+		ErrorTypeFlagGroupViolation,
+		ErrorTypeMissingRequired,
+		ErrorTypeInvalidArgument,
+		ErrorTypeLimitExceeded,
+	}
+	for _, typ := range usage {
+		e.codesByCLI[typ] = SysexitUsage
+	}
+	e.codesByCLI[ErrorTypeValidation] = SysexitDataErr
+	e.codesByCLI[ErrorTypePermission] = SysexitNoPerm
+	e.codesByCLI[ErrorTypeInternal] = SysexitSoftware
+	e.codesByCLI[ErrorTypeUnavailable] = SysexitUnavailable
+	e.codesByCLI[ErrorTypeGated] = SysexitUnavailable
+
+	e.codesByType[reflect.TypeOf(&middleware.TimeoutError{})] = SysexitTempFail
+	e.codesByType[reflect.TypeOf(&middleware.ValidationError{})] = SysexitDataErr
+	e.codesByType[reflect.TypeOf(&middleware.RecoveryError{})] = SysexitSoftware
+	e.codesByType[reflect.TypeOf(&CrashError{})] = SysexitSoftware
+	e.DefineError(ErrBinaryNotFound, e.defaults.NotFoundError)
+	return e
+}
+
+// Table returns every known exit code sorted by code, ready to be rendered as
+// an "EXIT CODES" section in generated documentation or man pages. It lists
+// the defaults, codes named via Define and every code an error can resolve to
+// through DefineCLI and DefineError (including the UseSysexits preset), named
+// after the CLI error category or the error.
+func (e *ExitCodeManager) Table() []ExitCodeEntry {
+	byCode := make(map[int]*ExitCodeEntry)
+	add := func(name string, code int) {
+		entry, ok := byCode[code]
+		if !ok {
+			entry = &ExitCodeEntry{Code: code}
+			byCode[code] = entry
+		}
+		for _, n := range entry.Names {
+			if n == name {
+				return
+			}
+		}
+		entry.Names = append(entry.Names, name)
+	}
+
+	add("success", e.defaults.Success)
+	add("general_error", e.defaults.GeneralError)
+	add("misusage", e.defaults.MisusageError)
+	add("validation", e.defaults.ValidationError)
+	add("not_found", e.defaults.NotFoundError)
+	add("permission", e.defaults.PermissionError)
+
+	names := make([]string, 0, len(e.codesByName))
+	for name := range e.codesByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, e.codesByName[name])
+	}
+	categories := make([]ErrorType, 0, len(e.codesByCLI))
+	for typ := range e.codesByCLI {
+		categories = append(categories, typ)
+	}
+	slices.Sort(categories)
+	for _, typ := range categories {
+		add(string(typ), e.codesByCLI[typ])
+	}
+	types := make([]string, 0, len(e.codesByType))
+	typeCodes := make(map[string]int, len(e.codesByType))
+	for typ, code := range e.codesByType {
+		name := snakeCase(typ.String()[strings.LastIndexByte(typ.String(), '.')+1:])
+		types = append(types, name)
+		typeCodes[name] = code
+	}
+	sort.Strings(types)
+	for _, name := range types {
+		add(name, typeCodes[name])
+	}
+	for _, s := range e.codesBySentinel {
+		add(snakeCase(s.err.Error()), s.code)
+	}
+	for code := range e.descriptions {
+		if _, ok := byCode[code]; !ok {
+			byCode[code] = &ExitCodeEntry{Code: code}
+		}
+	}
+
+	table := make([]ExitCodeEntry, 0, len(byCode))
+	for code, entry := range byCode {
+		entry.Description = e.describe(code)
+		table = append(table, *entry)
+	}
+	sort.Slice(table, func(i, j int) bool { return table[i].Code < table[j].Code })
+	return table
+}
+
+// snakeCase turns a type name or error message into a Table name:
+// "TimeoutError" -> "timeout_error", "binary not found" -> "binary_not_found"
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// describe returns the registered description for code, falling back to a
+// built-in description for the default and sysexits codes.
+func (e *ExitCodeManager) describe(code int) string {
+	if d, ok := e.descriptions[code]; ok {
+		return d
+	}
+	switch code {
+	case e.defaults.Success:
+		return e.message(MsgExitSuccess)
+	case e.defaults.GeneralError:
+		return e.message(MsgExitGeneral)
+	case e.defaults.MisusageError:
+		return e.message(MsgExitUsage)
+	case e.defaults.ValidationError:
+		return e.message(MsgExitInvalidInput)
+	case e.defaults.NotFoundError:
+		return e.message(MsgExitNotFound)
+	case e.defaults.PermissionError:
+		return e.message(MsgExitPermission)
+	}
+	if id, ok := sysexitDescriptions[code]; ok {
+		return e.message(id)
+	}
+	return ""
+}
+
+// message looks id up in the owning app's catalog (English when standalone)
+func (e *ExitCodeManager) message(id MessageID) string {
+	if e.app != nil {
+		return e.app.Message(id)
+	}
+	return builtinMessages[defaultLocale][id]
+}
+
+var sysexitDescriptions = map[int]MessageID{
+	SysexitUsage:       MsgExitUsage,
+	SysexitDataErr:     MsgExitDataErr,
+	SysexitNoInput:     MsgExitNoInput,
+	SysexitNoUser:      MsgExitNoUser,
+	SysexitNoHost:      MsgExitNoHost,
+	SysexitUnavailable: MsgExitUnavailable,
+	SysexitSoftware:    MsgExitSoftware,
+	SysexitOSErr:       MsgExitOSErr,
+	SysexitOSFile:      MsgExitOSFile,
+	SysexitCantCreat:   MsgExitCantCreat,
+	SysexitIOErr:       MsgExitIOErr,
+	SysexitTempFail:    MsgExitTempFail,
+	SysexitProtocol:    MsgExitProtocol,
+	SysexitNoPerm:      MsgExitPermission,
+	SysexitConfig:      MsgExitConfig,
+}
+
+// resolve converts an error to an exit code according to the app-level mappings
+func (e *ExitCodeManager) resolve(err error) int {
+	return e.resolveFor(nil, err)
+}
+
+// resolveFor converts an error of a run of cmd (nil when no command ran) to
+// an exit code according to registered mappings.
 // Precedence:
 //  1. ExitError (requested code)
-//  2. CLIError category mapping (DefineCLI)
-//  3. Concrete error type mapping (DefineError)
-//  4. Default codes
-func (e *ExitCodeManager) resolve(err error) int {
+//  2. Mappings of cmd and its ancestors, nearest first (CommandBuilder.ExitCodes)
+//  3. CLIError category mapping (DefineCLI)
+//  4. Matchers (DefineErrorFunc), then sentinel and type mappings (DefineError)
+//  5. Default codes
+func (e *ExitCodeManager) resolveFor(cmd *Command, err error) int {
 	if err == nil {
 		return e.defaults.Success
 	}
 
+	// A chain exits with the highest code of its failed commands
+	var chainErr *ChainError
+	if errors.As(err, &chainErr) {
+		code := e.defaults.Success
+		for _, f := range chainErr.Failures {
+			code = max(code, e.resolveFor(f.command, f.Err))
+		}
+		return code
+	}
+
 	// ExitError wins
 	var exitErr *ExitError
 	if errors.As(err, &exitErr) {
 		return exitErr.Code
 	}
 
+	for c := cmd; c != nil; c = c.parent {
+		if code, ok := c.exitCodes.lookup(err); ok {
+			return code
+		}
+	}
+	if code, ok := e.lookup(err); ok {
+		return code
+	}
+
+	// Fallback
+	return e.defaults.GeneralError
+}
+
+// lookup returns the code mapped to err by category (CLIError) or by
+// matcher, sentinel and concrete type (other errors), walking the wrap
+// chain; nil managers map nothing
+func (e *ExitCodeManager) lookup(err error) (int, bool) {
+	if e == nil {
+		return 0, false
+	}
+
 	// CLIError mapping
 	var cli *CLIError
 	if errors.As(err, &cli) {
-		if code, ok := e.codesByCLI[cli.Type]; ok {
-			return code
+		code, ok := e.codesByCLI[cli.Type]
+		return code, ok
+	}
+
+	for _, fn := range e.codeFuncs {
+		if code, ok := fn(err); ok {
+			return code, true
+		}
+	}
+	for _, s := range e.codesBySentinel {
+		if errors.Is(err, s.err) {
+			return s.code, true
 		}
-		return e.defaults.GeneralError
 	}
 
-	// middleware errors by concrete type
+	// middleware and user errors by concrete type
 	for t, code := range e.codesByType {
 		if errors.As(err, reflect.New(t).Interface()) {
-			return code
+			return code, true
 		}
 	}
-
-	// Fallback
-	return e.defaults.GeneralError
+	return 0, false
 }
@@ -0,0 +1,212 @@
+package snap
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Experiment is a named, dark-launched feature listed by Experiments.List
+type Experiment struct {
+	Name        string
+	Description string
+	Enabled     bool
+}
+
+// Experiments is the registry of experiments gating commands and flags
+// marked Experimental. An experiment is enabled by Enable, by listing it in
+// an environment variable (<APP>_EXPERIMENTS by default, names separated by
+// commas or spaces) or under a configuration key (see FromConfig).
+type Experiments struct {
+	app          *App
+	descriptions map[string]string
+	enabled      map[string]bool
+	envVars      []string
+	configKey    string
+}
+
+// Experiments returns the app's experiment registry
+func (a *App) Experiments() *Experiments {
+	if a.experiments == nil {
+		envName := strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(a.name)
+		a.experiments = &Experiments{
+			app:          a,
+			descriptions: make(map[string]string),
+			enabled:      make(map[string]bool),
+			envVars:      []string{strings.ToUpper(envName) + "_EXPERIMENTS"},
+		}
+	}
+	return a.experiments
+}
+
+// Define registers an experiment with the description shown in help.
+// Experiments named by Experimental are registered automatically.
+func (e *Experiments) Define(name, description string) *Experiments {
+	e.descriptions[name] = description
+	e.app.invalidateHelp()
+	return e
+}
+
+// Enable turns experiments on regardless of the environment and configuration
+func (e *Experiments) Enable(names ...string) *Experiments {
+	for _, name := range names {
+		e.enabled[name] = true
+	}
+	e.app.invalidateHelp()
+	return e
+}
+
+// FromEnv replaces the environment variables listing enabled experiments
+func (e *Experiments) FromEnv(vars ...string) *Experiments {
+	e.envVars = vars
+	return e
+}
+
+// FromConfig also reads enabled experiments from key of the app's
+// configuration (a list or a comma-separated string), e.g. "experiments"
+// for {"experiments": ["new-ui"]}. Only sources loaded before parsing
+// count: defaults, files and environment variables.
+func (e *Experiments) FromConfig(key string) *Experiments {
+	e.configKey = key
+	return e
+}
+
+// Enabled reports whether the experiment is turned on
+func (e *Experiments) Enabled(name string) bool {
+	if e == nil {
+		return false
+	}
+	if e.enabled[name] {
+		return true
+	}
+	for _, v := range e.envVars {
+		if slices.Contains(strings.FieldsFunc(os.Getenv(v), isListSeparator), name) {
+			return true
+		}
+	}
+	return slices.Contains(e.configured(), name)
+}
+
+// List returns the registered experiments sorted by name
+func (e *Experiments) List() []Experiment {
+	names := make([]string, 0, len(e.descriptions))
+	for name := range e.descriptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]Experiment, len(names))
+	for i, name := range names {
+		list[i] = Experiment{Name: name, Description: e.descriptions[name], Enabled: e.Enabled(name)}
+	}
+	return list
+}
+
+// register adds an experiment named by Experimental, keeping a description
+// set by Define
+func (e *Experiments) register(name string) {
+	if _, ok := e.descriptions[name]; !ok {
+		e.descriptions[name] = ""
+	}
+	e.app.invalidateHelp()
+}
+
+// configured returns the experiments listed under the configuration key
+func (e *Experiments) configured() []string {
+	cb := e.app.configBuilder
+	if e.configKey == "" || cb == nil {
+		return nil
+	}
+	switch value := cb.precedenceManager.Resolve()[e.configKey].(type) {
+	case string:
+		return strings.FieldsFunc(value, isListSeparator)
+	case []any:
+		names := make([]string, 0, len(value))
+		for _, item := range value {
+			names = append(names, fmt.Sprint(item))
+		}
+		return names
+	case []string:
+		return value
+	}
+	return nil
+}
+
+// isListSeparator splits experiment lists at commas and white space
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t' || r == '\n'
+}
+
+// appOwner is a builder that belongs to an app
+type appOwner interface {
+	owningApp() *App
+}
+
+func (a *App) owningApp() *App            { return a }
+func (c *CommandBuilder) owningApp() *App { return c.app }
+func (g *FlagGroupBuilder[P]) owningApp() *App {
+	if owner, ok := any(g.parent).(appOwner); ok {
+		return owner.owningApp()
+	}
+	return nil
+}
+
+// Experimental hides the command and rejects it as unavailable unless the
+// experiment is enabled (see App.Experiments)
+func (c *CommandBuilder) Experimental(name string) *CommandBuilder {
+	c.command.experiment = name
+	if c.app != nil {
+		c.command.experiments = c.app.Experiments()
+		c.command.experiments.register(name)
+	}
+	return c
+}
+
+// Experimental hides the flag and rejects it as unavailable unless the
+// experiment is enabled (see App.Experiments)
+func (f *FlagBuilder[T, P]) Experimental(name string) *FlagBuilder[T, P] {
+	f.flag.experiment = name
+	if owner, ok := any(f.parent).(appOwner); ok && owner.owningApp() != nil {
+		f.flag.experiments = owner.owningApp().Experiments()
+		f.flag.experiments.register(name)
+	}
+	return f
+}
+
+// experimentOn reports whether the experiment gating a flag or command is
+// enabled; items outside any experiment are always on
+func experimentOn(name string, experiments *Experiments) bool {
+	return name == "" || experiments.Enabled(name)
+}
+
+// printExperimentsSection lists the registered experiments in the app help
+func (a *App) printExperimentsSection() {
+	if a.experiments == nil || len(a.experiments.descriptions) == 0 {
+		return
+	}
+	list := a.experiments.List()
+	maxNameLen := 0
+	for _, exp := range list {
+		maxNameLen = max(maxNameLen, len(exp.Name))
+	}
+
+	a.println()
+	a.println(a.heading(MsgExperiments))
+	for _, exp := range list {
+		a.print("  ", exp.Name)
+		if exp.Description != "" || exp.Enabled {
+			a.print(strings.Repeat(" ", maxNameLen-len(exp.Name)+2), exp.Description)
+		}
+		if exp.Enabled {
+			if exp.Description != "" {
+				a.print(" ")
+			}
+			a.print(a.msgf(MsgExperimentEnabled))
+		}
+		a.println()
+	}
+	if len(a.experiments.envVars) > 0 {
+		a.println(a.msgf(MsgExperimentsFooter, a.experiments.envVars[0]))
+	}
+}
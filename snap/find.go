@@ -0,0 +1,171 @@
+package snap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/internal/fuzzy"
+)
+
+// findMaxDistance is the edit distance tolerated for typos in find queries
+const findMaxDistance = 2
+
+// Ranks of a find match, best first
+const (
+	findExact = iota
+	findPrefix
+	findSubstring
+	findFuzzy
+	findDescription
+)
+
+// findEntry is a searchable command or flag invocation
+type findEntry struct {
+	invocation  string   // "myapp deploy service --force"
+	description string   // Command or flag description
+	names       []string // Names matched against the query (name, aliases, path)
+}
+
+// findMatch is a ranked entry
+type findMatch struct {
+	entry *findEntry
+	rank  int
+	score float64
+}
+
+// SearchCommand registers a built-in command (usually "find") that fuzzy
+// matches the query against every visible command, alias and flag of the
+// tree and prints the matching invocations with their descriptions:
+//
+//	myapp find depl
+//	  myapp deploy                  Deploy the application
+//	  myapp deploy --dry-run        Print the plan only
+//
+// Like the version command it is added at run time unless the app already
+// defines a command with that name.
+func (a *App) SearchCommand(name string) *App {
+	a.searchCommand = name
+	return a
+}
+
+// addSearchCommand registers the command enabled by SearchCommand
+func (a *App) addSearchCommand() {
+	if a.searchCommand == "" {
+		return
+	}
+	if _, exists := a.commands[a.searchCommand]; exists {
+		return
+	}
+	a.Command(a.searchCommand, "Search commands and flags").
+		StringArg("query", "Text to look for").Required().Back().
+		Action(func(ctx *Context) error {
+			return a.showFindResults(ctx.MustArgString("query", ""))
+		})
+}
+
+// findEntries lists the app flags and every visible command and its flags
+func (a *App) findEntries() []*findEntry {
+	var entries []*findEntry
+	addFlags := func(prefix string, flags map[string]*Flag) {
+		for _, name := range sortedKeys(flags) {
+			flag := flags[name]
+			if flag.isHidden() || flag.descriptionID != "" {
+				continue
+			}
+			entries = append(entries, &findEntry{
+				invocation:  prefix + " " + flag.usage(),
+				description: flag.Description,
+				names:       []string{flag.Name},
+			})
+		}
+	}
+	addFlags(a.name, a.flags)
+
+	var walk func(prefix string, commands map[string]*Command)
+	walk = func(prefix string, commands map[string]*Command) {
+		for _, name := range sortedKeys(commands) {
+			cmd := commands[name]
+			if cmd.isHidden() || name == a.searchCommand {
+				continue
+			}
+			path := prefix + " " + name
+			names := append([]string{name}, cmd.Aliases...)
+			entries = append(entries, &findEntry{
+				invocation:  path,
+				description: cmd.description,
+				names:       append(names, strings.TrimPrefix(path, a.name+" ")),
+			})
+			addFlags(path, cmd.flags)
+			walk(path, cmd.subcommands)
+		}
+	}
+	walk(a.name, a.commands)
+	return entries
+}
+
+// findMatches ranks the entries matching query: exact, prefix and substring
+// name matches first, then typos within findMaxDistance, then descriptions
+func (a *App) findMatches(query string) []findMatch {
+	query = strings.ToLower(strings.TrimLeft(strings.TrimSpace(query), "-"))
+	if query == "" {
+		return nil
+	}
+	matcher := fuzzy.NewMatcher(findMaxDistance)
+
+	var matches []findMatch
+	for _, entry := range a.findEntries() {
+		best := findMatch{entry: entry, rank: -1}
+		consider := func(rank int, score float64) {
+			if best.rank < 0 || rank < best.rank || (rank == best.rank && score > best.score) {
+				best.rank, best.score = rank, score
+			}
+		}
+		for _, name := range entry.names {
+			lower := strings.ToLower(name)
+			switch {
+			case lower == query:
+				consider(findExact, 1)
+			case strings.HasPrefix(lower, query):
+				consider(findPrefix, float64(len(query))/float64(len(lower)))
+			case strings.Contains(lower, query):
+				consider(findSubstring, float64(len(query))/float64(len(lower)))
+			default:
+				if found := matcher.FindMatches(query, []string{lower}); len(found) > 0 {
+					consider(findFuzzy, found[0].Score)
+				}
+			}
+		}
+		if best.rank < 0 && strings.Contains(strings.ToLower(entry.description), query) {
+			consider(findDescription, 0)
+		}
+		if best.rank >= 0 {
+			matches = append(matches, best)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
+
+// showFindResults prints the invocations matching query with their descriptions
+func (a *App) showFindResults(query string) error {
+	matches := a.findMatches(query)
+	if len(matches) == 0 {
+		return fmt.Errorf("no commands or flags match %q", query)
+	}
+	width := 0
+	for _, match := range matches {
+		width = max(width, len(match.entry.invocation))
+	}
+	for _, match := range matches {
+		line := fmt.Sprintf("  %-*s  %s", width, match.entry.invocation, match.entry.description)
+		a.println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
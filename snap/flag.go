@@ -3,8 +3,11 @@ package snap
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -23,6 +26,14 @@ const (
 	FlagTypeBool FlagType = "bool"
 	// FlagTypeInt indicates an integer flag.
 	FlagTypeInt FlagType = "int"
+	// FlagTypeInt64 indicates an int64 flag.
+	FlagTypeInt64 FlagType = "int64"
+	// FlagTypeInt32 indicates an int32 flag (values outside the int32 range are rejected).
+	FlagTypeInt32 FlagType = "int32"
+	// FlagTypeUint indicates a uint flag (negative values are rejected).
+	FlagTypeUint FlagType = "uint"
+	// FlagTypeUint64 indicates a uint64 flag (negative values are rejected).
+	FlagTypeUint64 FlagType = "uint64"
 	// FlagTypeDuration indicates a time.Duration flag.
 	FlagTypeDuration FlagType = "duration"
 	// FlagTypeFloat indicates a float64 flag.
@@ -34,38 +45,87 @@ const (
 	FlagTypeStringSlice FlagType = "[]string"
 	// FlagTypeIntSlice indicates a []int flag.
 	FlagTypeIntSlice FlagType = "[]int"
+	// FlagTypeFloatSlice indicates a []float64 flag.
+	FlagTypeFloatSlice FlagType = "[]float64"
+	// FlagTypeDurationSlice indicates a []time.Duration flag.
+	FlagTypeDurationSlice FlagType = "[]duration"
+	// FlagTypeEnumSlice indicates a multi-value enum flag ([]string constrained to EnumValues).
+	FlagTypeEnumSlice FlagType = "[]enum"
 )
 
 // Flag represents a command-line flag with all its properties
 type Flag struct {
-	Name               string
-	Description        string
-	Type               FlagType
-	DefaultString      string
-	DefaultInt         int
-	DefaultBool        bool
-	DefaultDuration    time.Duration
-	DefaultFloat       float64
-	DefaultEnum        string
-	DefaultStringSlice []string
-	DefaultIntSlice    []int
-	Global             bool
-	Required           bool
-	Hidden             bool
-	Short              rune
-	EnvVars            []string // Environment variables to check (in precedence order)
-	Usage              string
+	Name                 string
+	Description          string
+	Type                 FlagType
+	DefaultString        string
+	DefaultInt           int
+	DefaultInt64         int64  // Default for Int64Flag and Int32Flag
+	DefaultUint64        uint64 // Default for Uint64Flag and UintFlag
+	DefaultBool          bool
+	DefaultDuration      time.Duration
+	DefaultFloat         float64
+	DefaultEnum          string
+	DefaultStringSlice   []string
+	DefaultIntSlice      []int
+	DefaultFloatSlice    []float64
+	DefaultDurationSlice []time.Duration
+	DefaultEnumSlice     []string
+	Global               bool
+	Required             bool
+	Hidden               bool
+	Short                rune
+	EnvVars              []string // Environment variables to check (in precedence order)
+	Usage                string
+	AllowFromFile        bool   // Accept "@path" (file contents) and "-" (stdin) as the value
+	DefaultText          string // Default shown in help instead of the actual value
+	Placeholder          string // Name of the value in help ("--output FILE"); "value" when empty
 
 	// Enum-specific fields
-	EnumValues []string // Valid enum values
+	EnumValues          []string          // Valid enum values
+	EnumCaseInsensitive bool              // Match values regardless of case
+	EnumAliases         map[string]string // Alternative spellings mapped to a canonical value
 
 	// Type-safe validation function (will be cast to func(T) error at runtime)
 	Validator interface{}
+
+	// Lazily computed default (see DefaultFunc); fills the Default* field of a copy at parse time
+	defaultFunc func(*Flag)
+
+	// Runtime predicates (see VisibleIf / EnabledIf)
+	visibleIf func() bool
+	enabledIf func() bool
+
+	// Set when the flag belongs to a hidden flag group
+	groupHidden bool
+
+	// Catalog entry used for the help description of built-in flags
+	descriptionID MessageID
+
+	// Expand references to other flags after parsing (see Interpolate)
+	interpolate bool
+
+	// Value redacted in dumps and reports (see Secret)
+	secret bool
+
+	// Value resolved from @file, env:VAR or keyring:NAME (see CredentialFlag)
+	credential bool
+
+	// Int flag counting its occurrences, -vvv = 3 (see VerbosityFlags)
+	counter bool
+
+	// Value of the flag given bare, e.g. --color for --color=auto (see OptionalValue)
+	optionalValue bool
+	bareValue     string
+
+	// Experiment the flag belongs to (see Experimental)
+	experiment  string
+	experiments *Experiments
 }
 
 // RequiresValue returns true if the flag type requires a value
 func (f *Flag) RequiresValue() bool {
-	return f.Type != FlagTypeBool
+	return f.Type != FlagTypeBool && !f.counter && !f.optionalValue
 }
 
 // IsGlobal returns true if the flag is global
@@ -73,6 +133,41 @@ func (f *Flag) IsGlobal() bool {
 	return f.Global
 }
 
+// isHidden reports whether the flag is left out of help and suggestions
+func (f *Flag) isHidden() bool {
+	return f.Hidden || f.groupHidden || (f.visibleIf != nil && !f.visibleIf()) || !f.isEnabled()
+}
+
+// isEnabled reports whether the flag may be used in this process
+func (f *Flag) isEnabled() bool {
+	return (f.enabledIf == nil || f.enabledIf()) && experimentOn(f.experiment, f.experiments)
+}
+
+// canonicalEnum maps an enum value, alias or (when case-insensitive) a
+// differently cased spelling to its canonical value
+func (f *Flag) canonicalEnum(value string) (string, bool) {
+	if slices.Contains(f.EnumValues, value) {
+		return value, true
+	}
+	if canonical, ok := f.EnumAliases[value]; ok {
+		return canonical, true
+	}
+	if !f.EnumCaseInsensitive {
+		return "", false
+	}
+	for _, canonical := range f.EnumValues {
+		if strings.EqualFold(canonical, value) {
+			return canonical, true
+		}
+	}
+	for alias, canonical := range f.EnumAliases {
+		if strings.EqualFold(alias, value) {
+			return canonical, true
+		}
+	}
+	return "", false
+}
+
 // Validation helper functions
 
 // ValidateFile creates a validation function for file paths
@@ -161,40 +256,106 @@ type FlagBuilder[T any, P FlagParent] struct {
 
 // Default sets the default value for the flag
 func (f *FlagBuilder[T, P]) Default(value T) *FlagBuilder[T, P] {
-	switch f.flag.Type {
+	setFlagDefault(f.flag, value)
+	return f
+}
+
+// DefaultFunc computes the default at parse time, only when the flag is not
+// given on the command line or via environment. Help shows "(default: auto)"
+// unless DefaultText overrides it.
+func (f *FlagBuilder[T, P]) DefaultFunc(fn func() T) *FlagBuilder[T, P] {
+	f.flag.defaultFunc = func(target *Flag) { setFlagDefault(target, fn()) }
+	return f
+}
+
+// DefaultText overrides how the default value is shown in help
+func (f *FlagBuilder[T, P]) DefaultText(text string) *FlagBuilder[T, P] {
+	f.flag.DefaultText = text
+	return f
+}
+
+// DisplayDefault is DefaultText under the name used alongside the help
+// formatting helpers, e.g. .Default(30*time.Second).DisplayDefault("30s")
+// or .DisplayDefault(snapio.FormatBytes(64 << 20)) for a size in bytes
+func (f *FlagBuilder[T, P]) DisplayDefault(text string) *FlagBuilder[T, P] {
+	return f.DefaultText(text)
+}
+
+// setFlagDefault stores value in the typed default field matching the flag type
+func setFlagDefault[T any](flag *Flag, value T) {
+	switch flag.Type {
 	case FlagTypeString:
 		if v, ok := any(value).(string); ok {
-			f.flag.DefaultString = v
+			flag.DefaultString = v
 		}
 	case FlagTypeInt:
 		if v, ok := any(value).(int); ok {
-			f.flag.DefaultInt = v
+			flag.DefaultInt = v
+		}
+	case FlagTypeInt64:
+		if v, ok := any(value).(int64); ok {
+			flag.DefaultInt64 = v
+		}
+	case FlagTypeInt32:
+		if v, ok := any(value).(int32); ok {
+			flag.DefaultInt64 = int64(v)
+		}
+	case FlagTypeUint:
+		if v, ok := any(value).(uint); ok {
+			flag.DefaultUint64 = uint64(v)
+		}
+	case FlagTypeUint64:
+		if v, ok := any(value).(uint64); ok {
+			flag.DefaultUint64 = v
 		}
 	case FlagTypeBool:
 		if v, ok := any(value).(bool); ok {
-			f.flag.DefaultBool = v
+			flag.DefaultBool = v
 		}
 	case FlagTypeDuration:
 		if v, ok := any(value).(time.Duration); ok {
-			f.flag.DefaultDuration = v
+			flag.DefaultDuration = v
 		}
 	case FlagTypeFloat:
 		if v, ok := any(value).(float64); ok {
-			f.flag.DefaultFloat = v
+			flag.DefaultFloat = v
 		}
 	case FlagTypeEnum:
 		if v, ok := any(value).(string); ok {
-			f.flag.DefaultEnum = v
+			flag.DefaultEnum = v
 		}
 	case FlagTypeStringSlice:
 		if v, ok := any(value).([]string); ok {
-			f.flag.DefaultStringSlice = v
+			flag.DefaultStringSlice = v
 		}
 	case FlagTypeIntSlice:
 		if v, ok := any(value).([]int); ok {
-			f.flag.DefaultIntSlice = v
+			flag.DefaultIntSlice = v
+		}
+	case FlagTypeFloatSlice:
+		if v, ok := any(value).([]float64); ok {
+			flag.DefaultFloatSlice = v
+		}
+	case FlagTypeDurationSlice:
+		if v, ok := any(value).([]time.Duration); ok {
+			flag.DefaultDurationSlice = v
+		}
+	case FlagTypeEnumSlice:
+		if v, ok := any(value).([]string); ok {
+			flag.DefaultEnumSlice = v
 		}
 	}
+}
+
+// OptionalValue lets the flag be given without a value, standing for value:
+// with OptionalValue("default"), "--profile" means "default" and
+// "--profile=prod" means "prod". Like getopt's optional arguments, the value
+// must be attached ("--profile=prod", "-pprod"); in "--profile prod", prod is
+// a positional argument. Help shows the flag as "--profile[=value]". Not for
+// bool flags, which already work this way.
+func (f *FlagBuilder[T, P]) OptionalValue(value T) *FlagBuilder[T, P] {
+	f.flag.optionalValue = true
+	f.flag.bareValue = formatConfigValue(value)
 	return f
 }
 
@@ -226,12 +387,64 @@ func (f *FlagBuilder[T, P]) Hidden() *FlagBuilder[T, P] {
 	return f
 }
 
+// VisibleIf hides the flag from help and suggestions while pred returns false;
+// the flag itself keeps working (e.g. experimental options)
+func (f *FlagBuilder[T, P]) VisibleIf(pred func() bool) *FlagBuilder[T, P] {
+	f.flag.visibleIf = pred
+	return f
+}
+
+// EnabledIf makes the flag available only while pred returns true (e.g.
+// platform-specific options). When disabled it is hidden and rejected at
+// parse time with an "unavailable" error.
+func (f *FlagBuilder[T, P]) EnabledIf(pred func() bool) *FlagBuilder[T, P] {
+	f.flag.enabledIf = pred
+	return f
+}
+
 // FromEnv binds the flag to environment variables (checked in precedence order)
 func (f *FlagBuilder[T, P]) FromEnv(envVars ...string) *FlagBuilder[T, P] {
 	f.flag.EnvVars = envVars
 	return f
 }
 
+// AllowFromFile lets the flag value come from a file or stdin: "--cert @ca.pem"
+// reads the file, "--data -" reads stdin and "@@x" passes the literal "@x".
+// A single trailing newline is trimmed from the content.
+func (f *FlagBuilder[T, P]) AllowFromFile() *FlagBuilder[T, P] {
+	f.flag.AllowFromFile = true
+	return f
+}
+
+// CaseInsensitive makes an enum flag accept values in any case ("INFO" for
+// "info"); the parse result always holds the canonical value
+func (f *FlagBuilder[T, P]) CaseInsensitive() *FlagBuilder[T, P] {
+	f.flag.EnumCaseInsensitive = true
+	return f
+}
+
+// WithAliases adds alternative spellings for enum values, e.g.
+// map[string]string{"warning": "warn"}; aliases are normalized to their
+// canonical value and are not listed in help or errors
+func (f *FlagBuilder[T, P]) WithAliases(aliases map[string]string) *FlagBuilder[T, P] {
+	if f.flag.EnumAliases == nil {
+		f.flag.EnumAliases = make(map[string]string, len(aliases))
+	}
+	maps.Copy(f.flag.EnumAliases, aliases)
+	return f
+}
+
+// Placeholder names the flag value in help and usage lines ("--output FILE")
+func (f *FlagBuilder[T, P]) Placeholder(name string) *FlagBuilder[T, P] {
+	f.flag.Placeholder = name
+	return f
+}
+
+// Metavar is an alias of Placeholder
+func (f *FlagBuilder[T, P]) Metavar(name string) *FlagBuilder[T, P] {
+	return f.Placeholder(name)
+}
+
 // Usage sets a detailed usage description
 func (f *FlagBuilder[T, P]) Usage(usage string) *FlagBuilder[T, P] {
 	f.flag.Usage = usage
@@ -257,9 +470,9 @@ func (f *FlagBuilder[T, P]) Build() interface{} {
 
 // Convenience methods - syntactic sugar over validation functions
 
-// Range sets inclusive min/max validation for numeric flags (int and float64).
-// The value must satisfy min <= value <= max.
-func Range[T int | float64, P FlagParent](f *FlagBuilder[T, P], minVal, maxVal T) *FlagBuilder[T, P] {
+// Range sets inclusive min/max validation for numeric flags (int, int32, int64,
+// uint, uint64 and float64). The value must satisfy min <= value <= max.
+func Range[T int | int32 | int64 | uint | uint64 | float64, P FlagParent](f *FlagBuilder[T, P], minVal, maxVal T) *FlagBuilder[T, P] {
 	return f.Validate(func(value T) error {
 		if value < minVal || value > maxVal {
 			return fmt.Errorf("value %v is not within range [%v, %v]", value, minVal, maxVal)
@@ -314,6 +527,14 @@ type FlagGroup struct {
 	Description string
 	Flags       []*Flag
 	Constraint  GroupConstraintType
+	DefaultFlag string // Bool flag selected when no flag of the group is given
+	Hidden      bool   // Leave the group and its flags out of help (the flags still work)
+	Order       int    // Help position; groups sort by Order, then by name
+}
+
+// selectsDefault reports whether the group has a DefaultFlag its constraint can use
+func (g *FlagGroup) selectsDefault() bool {
+	return g.DefaultFlag != "" && g.Constraint != GroupAllOrNone && g.Constraint != GroupNoConstraint
 }
 
 // FlagGroupParent interface for type-safe group building
@@ -361,6 +582,28 @@ func (g *FlagGroupBuilder[P]) AtLeastOne() *FlagGroupBuilder[P] {
 	return g
 }
 
+// DefaultFlag names a bool flag of the group that is selected when none of
+// its flags is given, instead of failing an ExactlyOne or AtLeastOne
+// constraint (or leaving a MutuallyExclusive group empty)
+func (g *FlagGroupBuilder[P]) DefaultFlag(name string) *FlagGroupBuilder[P] {
+	g.group.DefaultFlag = name
+	return g
+}
+
+// Hidden leaves the group and its flags out of help, usage lines and
+// suggestions; the flags are still parsed and the constraint still applies
+func (g *FlagGroupBuilder[P]) Hidden() *FlagGroupBuilder[P] {
+	g.group.Hidden = true
+	return g
+}
+
+// Order sets the help position of the group: lower values come first,
+// groups with the same order are sorted by name (the default order is 0)
+func (g *FlagGroupBuilder[P]) Order(order int) *FlagGroupBuilder[P] {
+	g.group.Order = order
+	return g
+}
+
 // Description sets a description for the flag group
 func (g *FlagGroupBuilder[P]) Description(desc string) *FlagGroupBuilder[P] {
 	g.group.Description = desc
@@ -411,6 +654,62 @@ func (g *FlagGroupBuilder[P]) BoolFlag(name, description string) *FlagBuilder[bo
 	}
 }
 
+// Int64Flag creates an int64 flag within the group
+func (g *FlagGroupBuilder[P]) Int64Flag(name, description string) *FlagBuilder[int64, *FlagGroupBuilder[P]] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt64,
+	}
+	g.group.Flags = append(g.group.Flags, flag)
+	return &FlagBuilder[int64, *FlagGroupBuilder[P]]{
+		flag:   flag,
+		parent: g,
+	}
+}
+
+// Int32Flag creates an int32 flag within the group
+func (g *FlagGroupBuilder[P]) Int32Flag(name, description string) *FlagBuilder[int32, *FlagGroupBuilder[P]] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeInt32,
+	}
+	g.group.Flags = append(g.group.Flags, flag)
+	return &FlagBuilder[int32, *FlagGroupBuilder[P]]{
+		flag:   flag,
+		parent: g,
+	}
+}
+
+// UintFlag creates a uint flag within the group
+func (g *FlagGroupBuilder[P]) UintFlag(name, description string) *FlagBuilder[uint, *FlagGroupBuilder[P]] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeUint,
+	}
+	g.group.Flags = append(g.group.Flags, flag)
+	return &FlagBuilder[uint, *FlagGroupBuilder[P]]{
+		flag:   flag,
+		parent: g,
+	}
+}
+
+// Uint64Flag creates a uint64 flag within the group
+func (g *FlagGroupBuilder[P]) Uint64Flag(name, description string) *FlagBuilder[uint64, *FlagGroupBuilder[P]] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeUint64,
+	}
+	g.group.Flags = append(g.group.Flags, flag)
+	return &FlagBuilder[uint64, *FlagGroupBuilder[P]]{
+		flag:   flag,
+		parent: g,
+	}
+}
+
 // DurationFlag creates a duration flag within the group
 func (g *FlagGroupBuilder[P]) DurationFlag(name, description string) *FlagBuilder[time.Duration, *FlagGroupBuilder[P]] {
 	flag := &Flag{
@@ -467,6 +766,54 @@ func (g *FlagGroupBuilder[P]) IntSliceFlag(name, description string) *FlagBuilde
 	}
 }
 
+// FloatSliceFlag creates a float64 slice flag within the group
+func (g *FlagGroupBuilder[P]) FloatSliceFlag(name, description string) *FlagBuilder[[]float64, *FlagGroupBuilder[P]] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeFloatSlice,
+	}
+	g.group.Flags = append(g.group.Flags, flag)
+	return &FlagBuilder[[]float64, *FlagGroupBuilder[P]]{
+		flag:   flag,
+		parent: g,
+	}
+}
+
+// DurationSliceFlag creates a duration slice flag within the group
+func (g *FlagGroupBuilder[P]) DurationSliceFlag(
+	name, description string,
+) *FlagBuilder[[]time.Duration, *FlagGroupBuilder[P]] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeDurationSlice,
+	}
+	g.group.Flags = append(g.group.Flags, flag)
+	return &FlagBuilder[[]time.Duration, *FlagGroupBuilder[P]]{
+		flag:   flag,
+		parent: g,
+	}
+}
+
+// EnumSliceFlag creates a multi-value enum flag within the group
+func (g *FlagGroupBuilder[P]) EnumSliceFlag(
+	name, description string,
+	values ...string,
+) *FlagBuilder[[]string, *FlagGroupBuilder[P]] {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Type:        FlagTypeEnumSlice,
+		EnumValues:  values,
+	}
+	g.group.Flags = append(g.group.Flags, flag)
+	return &FlagBuilder[[]string, *FlagGroupBuilder[P]]{
+		flag:   flag,
+		parent: g,
+	}
+}
+
 // EnumFlag creates an enum flag within the group
 func (g *FlagGroupBuilder[P]) EnumFlag(
 	name, description string,
@@ -0,0 +1,49 @@
+package snap
+
+import "fmt"
+
+// Freeze ends registration: the built-in flags and commands are added, and
+// adding a command, flag, positional argument or flag group to the app or
+// any of its commands afterwards panics, as the parser's lookup tables would
+// otherwise change under a run. Freeze may be called more than once.
+//
+// Run, RunContext and RunWithArgs are the only other entry points that
+// freeze, implicitly on the first run, so hooks, gates and actions cannot
+// register either. Parse, PreParse, PrintEnvHelp, ShowFlagHelp and
+// ShowGroupHelp add the built-ins without freezing.
+func (a *App) Freeze() *App {
+	if !a.frozen {
+		a.addBuiltins()
+		a.frozen = true
+	}
+	return a
+}
+
+// Unfreeze reopens registration after Freeze or a run, for long-lived hosts
+// that load plugins between runs. It panics while the app is running.
+func (a *App) Unfreeze() *App {
+	if a.running {
+		panic(fmt.Sprintf("snap: cannot unfreeze app %q while it is running", a.name))
+	}
+	a.frozen = false
+	return a
+}
+
+// IsFrozen reports whether registration has ended (see Freeze)
+func (a *App) IsFrozen() bool {
+	return a.frozen
+}
+
+// checkMutable panics with a hint when the app is frozen; kind and name
+// describe the registration, e.g. "flag" and "verbose"
+func (a *App) checkMutable(kind, name string) {
+	if a == nil || !a.frozen {
+		return
+	}
+	when := "after Freeze or Run"
+	if a.running {
+		when = "while the app is running (from a hook, gate or action)"
+	}
+	panic(fmt.Sprintf("snap: cannot register %s %q in app %q %s: register commands and flags before Run, "+
+		"or from a plugin loader between PreParse and Run", kind, name, a.name, when))
+}
@@ -0,0 +1,83 @@
+package snap
+
+import "errors"
+
+// GateFunc decides whether cmd may run now; cmd is nil for the app's own
+// action. A non-nil error vetoes the run (see App.Gate).
+type GateFunc func(ctx *Context, cmd *Command) error
+
+// GateError is the error a gate returns to veto a command with a reason and
+// remediation hints (see Deny). Gates may return any error: others are
+// reported with their message and no hints.
+type GateError struct {
+	Reason string   // Why the command cannot run, e.g. "maintenance in progress"
+	Hints  []string // Remediation hints printed below the error
+	Err    error    // Underlying cause, if any
+}
+
+// Error returns the reason
+func (e *GateError) Error() string { return e.Reason }
+
+// Unwrap returns the underlying cause
+func (e *GateError) Unwrap() error { return e.Err }
+
+// Deny returns a GateError vetoing a command for reason, with hints telling
+// the user how to proceed
+func Deny(reason string, hints ...string) *GateError {
+	return &GateError{Reason: reason, Hints: hints}
+}
+
+// Gate adds a check run before every command, after parsing and
+// configuration and before the Before hooks, that can veto execution based
+// on runtime conditions such as lock files, maintenance windows or a missing
+// login. Gates run in registration order and the first error stops the run:
+//
+//	app.Gate(func(ctx *snap.Context, cmd *snap.Command) error {
+//		if cmd != nil && cmd.Name() == "deploy" && fileExists("/var/run/deploy.lock") {
+//			return snap.Deny("another deploy is in progress", "wait for it to finish or remove /var/run/deploy.lock")
+//		}
+//		return nil
+//	})
+//
+// A vetoed run returns a *CLIError of type ErrorTypeGated, printed like a
+// parse error with the hints as suggestions, and exits with
+// SysexitUnavailable (69) unless remapped with ExitCodes().DefineCLI.
+// --help, --version and the help command are never gated.
+func (a *App) Gate(fn GateFunc) *App {
+	if fn != nil {
+		a.gates = append(a.gates, fn)
+	}
+	return a
+}
+
+// checkGates runs the gates for cmd, turning a veto into a formatted
+// CLIError naming the command
+func (a *App) checkGates(ctx *Context, cmd *Command) error {
+	for _, gate := range a.gates {
+		err := gate(ctx, cmd)
+		if err == nil {
+			continue
+		}
+		a.tracer.printf("gate", "vetoed: %v", err)
+
+		name := a.name
+		if cmd != nil {
+			name += " " + a.commandPath(cmd)
+		}
+		cliErr := NewError(ErrorTypeGated, a.msgf(MsgCommandGated, name, err.Error())).WithCause(err)
+		var gateErr *GateError
+		if errors.As(err, &gateErr) {
+			for _, hint := range gateErr.Hints {
+				_ = cliErr.WithSuggestion(hint)
+			}
+		}
+		if cmd != nil {
+			cliErr = cliErr.WithContext("current_command", cmd)
+		}
+		cliErr = a.errorHandler.ProcessError(cliErr, a)
+		cliErr = a.errorHandler.formatError(cliErr, a)
+		a.emit(Event{Type: EventErrorDisplayed, Err: cliErr})
+		return cliErr
+	}
+	return nil
+}
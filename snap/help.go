@@ -0,0 +1,212 @@
+package snap
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// HelpTopic registers a free-form help topic shown by "myapp help <name>" and
+// listed in the Topics section of the app help. The first line of text is
+// used as the topic summary.
+func (a *App) HelpTopic(name, text string) *App {
+	if a.helpTopics == nil {
+		a.helpTopics = make(map[string]string)
+	}
+	a.helpTopics[name] = text
+	a.invalidateHelp()
+	return a
+}
+
+// HelpPager pipes help output through $PAGER (less by default) when it does
+// not fit on the terminal. Redirected or non-interactive output is unaffected.
+func (a *App) HelpPager(enabled bool) *App {
+	a.helpPager = enabled
+	return a
+}
+
+// isHelpCommand reports whether args invoke the built-in "help" command.
+// It is available while help is enabled, the app has commands or topics to
+// describe (or args ask for --flag or --group details), and no user command
+// named "help" takes precedence.
+func (a *App) isHelpCommand(args []string) bool {
+	if !a.helpFlag || len(args) == 0 || args[0] != "help" {
+		return false
+	}
+	if _, taken := a.commands["help"]; taken {
+		return false
+	}
+	if _, _, at := helpDetailOption(args[1:]); at >= 0 {
+		return true
+	}
+	return len(a.commands) > 0 || len(a.helpTopics) > 0
+}
+
+// helpDetailOptions are the options of the help command showing one flag or
+// flag group
+var helpDetailOptions = []string{"--flag", "--group"}
+
+// helpDetailOption finds "--flag NAME" or "--group NAME" (or the
+// --opt=NAME form) in the help command arguments, returning the option, its
+// value and its index (-1 when there is none)
+func helpDetailOption(args []string) (option, value string, at int) {
+	for i, arg := range args {
+		for _, opt := range helpDetailOptions {
+			switch {
+			case arg == opt && i+1 < len(args):
+				return opt, args[i+1], i
+			case arg == opt:
+				return opt, "", i
+			case strings.HasPrefix(arg, opt+"="):
+				return opt, arg[len(opt)+1:], i
+			}
+		}
+	}
+	return "", "", -1
+}
+
+// runHelpCommand resolves "help [COMMAND...]" or "help TOPIC" and prints the
+// same output as --help at that level, or the details of one flag or group
+// with "help [COMMAND...] --flag NAME" and "--group NAME". Unknown names are
+// reported like any other unknown command, with suggestions.
+func (a *App) runHelpCommand(path []string) error {
+	option, value, at := helpDetailOption(path)
+	detail := at >= 0
+	if detail {
+		path = path[:at] // The command path precedes the option
+		if value == "" {
+			return a.handleParseError(&ParseError{
+				Type:    ErrorTypeMissingValue,
+				Message: "flag requires a value: " + option,
+				Flag:    strings.TrimPrefix(option, "--"),
+				msgID:   MsgFlagRequiresValue,
+				msgArgs: []any{option},
+			})
+		}
+	}
+
+	if len(path) == 1 && !detail {
+		if text, ok := a.helpTopics[path[0]]; ok && a.lookupSubcommand(a.commands, path[0]) == nil {
+			return a.showTopic(text)
+		}
+	}
+
+	p := NewParser(a)
+	for _, name := range path {
+		commands := a.commands
+		if p.currentCmd != nil {
+			commands = p.currentCmd.subcommands
+		}
+
+		cmd := a.lookupSubcommand(commands, name)
+		var err error
+		if cmd == nil {
+			cmd, err = p.findCommandByPrefix(name)
+		}
+		if err == nil && cmd == nil {
+			err = p.createUnknownCommandError(name)
+		} else if err == nil && !cmd.isEnabled() {
+			err = p.createUnavailableCommandError(cmd)
+		}
+		if err != nil {
+			parseErr := &ParseError{}
+			if errors.As(err, &parseErr) {
+				return a.handleParseError(parseErr)
+			}
+			return err
+		}
+		p.currentCmd = cmd
+	}
+
+	switch {
+	case option == "--flag":
+		return a.showFlagHelp(value, p.currentCmd, p.currentCmd != nil, a.IO().Out())
+	case option == "--group":
+		return a.showGroupHelp(value, p.currentCmd, p.currentCmd != nil, a.IO().Out())
+	}
+	if p.currentCmd == nil {
+		return a.showHelp()
+	}
+	return a.showCommandHelp(p.currentCmd)
+}
+
+// lookupSubcommand finds a command by name or alias within a single scope
+func (a *App) lookupSubcommand(commands map[string]*Command, name string) *Command {
+	if cmd := commands[name]; cmd != nil {
+		return cmd
+	}
+	return findCommandAlias(commands, name)
+}
+
+// showTopic prints a help topic followed by a trailing newline
+func (a *App) showTopic(text string) error {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return a.writeHelp([]byte(text))
+}
+
+// UsageFunc replaces the app help output (--help, -h, "help" and
+// ShowHelpOnError without a command) with fn, for products that render their
+// own branded help. Commands keep the built-in help unless they set
+// CommandBuilder.UsageFunc; DefaultUsage returns the built-in text to embed.
+func (a *App) UsageFunc(fn func(app *App, w io.Writer) error) *App {
+	a.usageFunc = fn
+	return a
+}
+
+// DefaultUsage returns the built-in help for cmd, or for the app when cmd is
+// nil, ignoring any UsageFunc
+func (a *App) DefaultUsage(cmd *Command) string {
+	if cmd == nil {
+		return string(a.render(a.renderHelp))
+	}
+	return string(a.render(func() { a.renderCommandHelp(cmd) }))
+}
+
+// writeUsage renders a UsageFunc into a buffer and writes it like the built-in help
+func (a *App) writeUsage(fn func(w io.Writer) error) error {
+	var err error
+	data := a.render(func() { err = fn(a.renderBuf) })
+	if err != nil {
+		return err
+	}
+	return a.writeHelp(data)
+}
+
+// writeHelp writes rendered help to stdout, through the pager if enabled
+func (a *App) writeHelp(data []byte) error {
+	if !a.helpPager || a.porcelain {
+		_, err := a.IO().Out().Write(data)
+		return err
+	}
+	pager := a.IO().Pager()
+	_, _ = pager.Write(data)
+	return pager.Close()
+}
+
+// printTopicsSection prints the Topics section for app help output
+func (a *App) printTopicsSection() {
+	if len(a.helpTopics) == 0 {
+		return
+	}
+	names := make([]string, 0, len(a.helpTopics))
+	maxNameLen := 0
+	for name := range a.helpTopics {
+		names = append(names, name)
+		maxNameLen = max(maxNameLen, len(name))
+	}
+	sort.Strings(names)
+
+	a.println()
+	a.println(a.heading(MsgTopics))
+	for _, name := range names {
+		summary, _, _ := strings.Cut(strings.TrimSpace(a.helpTopics[name]), "\n")
+		a.print("  ", name)
+		if summary != "" {
+			a.print(strings.Repeat(" ", maxNameLen-len(name)+2), summary)
+		}
+		a.println()
+	}
+}
@@ -0,0 +1,281 @@
+package snap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/internal/fuzzy"
+	snapio "github.com/dzonerzy/go-snap/io"
+)
+
+// flagScope is a flag with the command defining it (nil for app flags)
+type flagScope struct {
+	flag *Flag
+	cmd  *Command
+}
+
+// ShowFlagHelp writes the full details of the flag name ("timeout",
+// "--timeout" or "-t") to w: description, type, default, environment
+// variables, allowed values, the command defining it, its group and the
+// other flags of that group. A flag defined by several commands is shown
+// once per command. "myapp help --flag NAME" prints the same, limited to a
+// command with "myapp help COMMAND... --flag NAME". Unknown flags return an
+// unknown flag error, with suggestions when SuggestFlags is enabled.
+func (a *App) ShowFlagHelp(name string, w io.Writer) error {
+	a.addBuiltins()
+	return a.showFlagHelp(name, nil, false, w)
+}
+
+// ShowGroupHelp writes the flags of the flag group name to w, with its
+// description and constraint, like the group's section of the help output.
+// Groups of the same name defined by several commands are all shown.
+// "myapp help --group NAME" prints the same, limited to a command with
+// "myapp help COMMAND... --group NAME".
+func (a *App) ShowGroupHelp(name string, w io.Writer) error {
+	a.addBuiltins()
+	return a.showGroupHelp(name, nil, false, w)
+}
+
+// showFlagHelp renders the flag name defined by the app or, when scoped,
+// by cmd and the app; unscoped lookups search every command
+func (a *App) showFlagHelp(name string, cmd *Command, scoped bool, w io.Writer) error {
+	matches := a.findFlagScopes(strings.TrimLeft(name, "-"), cmd, scoped)
+	if len(matches) == 0 {
+		p := NewParser(a)
+		p.currentCmd = cmd
+		parseErr := &ParseError{}
+		if err := p.createUnknownFlagError(strings.TrimLeft(name, "-")); errors.As(err, &parseErr) {
+			return a.handleParseError(parseErr)
+		}
+		return nil
+	}
+	data := a.render(func() {
+		for i, match := range matches {
+			if i > 0 {
+				a.println()
+			}
+			a.renderFlagDetails(match)
+		}
+	})
+	_, err := w.Write(data)
+	return err
+}
+
+// findFlagScopes returns the visible flags called name (or with that short
+// form), first the app's, then those of cmd or, unscoped, of every command
+func (a *App) findFlagScopes(name string, cmd *Command, scoped bool) []flagScope {
+	var matches []flagScope
+	seen := make(map[*Flag]bool)
+	collect := func(flags map[string]*Flag, owner *Command) {
+		for _, flag := range sortedValues(flags) {
+			if seen[flag] || flag.isHidden() || !matchesFlagName(flag, name) {
+				continue
+			}
+			// Every command has its own --help; one is enough
+			if owner != nil && flag.descriptionID != "" && len(matches) > 0 {
+				continue
+			}
+			seen[flag] = true
+			matches = append(matches, flagScope{flag: flag, cmd: owner})
+		}
+	}
+	collect(a.flags, nil)
+	if scoped {
+		if cmd != nil {
+			collect(cmd.flags, cmd)
+		}
+		return matches
+	}
+	for _, top := range a.Commands() {
+		eachCommandSorted(top, func(c *Command) {
+			if !c.isHidden() {
+				collect(c.flags, c)
+			}
+		})
+	}
+	return matches
+}
+
+// matchesFlagName reports whether name is the long name or the short form of flag
+func matchesFlagName(flag *Flag, name string) bool {
+	if flag.Name == name {
+		return true
+	}
+	return len(name) == 1 && flag.Short == rune(name[0])
+}
+
+// eachCommandSorted calls fn for cmd and its subcommands, depth first in name order
+func eachCommandSorted(cmd *Command, fn func(*Command)) {
+	fn(cmd)
+	for _, sc := range cmd.Subcommands() {
+		eachCommandSorted(sc, fn)
+	}
+}
+
+// renderFlagDetails prints the heading, description and detail list of one flag
+func (a *App) renderFlagDetails(match flagScope) {
+	flag := match.flag
+	heading := "--" + flag.Name
+	if flag.Short != 0 {
+		heading += ", -" + string(flag.Short)
+	}
+	heading += flag.valueUsage()
+	a.println(a.styled(a.Theme().FlagStyle, heading))
+
+	description := flag.Description
+	if flag.descriptionID != "" {
+		description = a.Message(flag.descriptionID)
+	}
+	if description != "" {
+		a.println("  " + a.wrapHelp(description, 2))
+	}
+
+	typ := string(flag.Type)
+	if flag.counter {
+		typ = "count"
+	}
+	details := []snapio.Definition{{Term: a.Message(MsgDetailType), Description: typ}}
+	if value := a.getDefaultValue(flag); value != "" {
+		details = append(details, snapio.Definition{Term: a.Message(MsgDetailDefault), Description: value})
+	}
+	if len(flag.EnvVars) > 0 {
+		details = append(details, snapio.Definition{
+			Term: a.Message(MsgDetailEnvironment), Description: strings.Join(flag.EnvVars, ", "),
+		})
+	}
+	if len(flag.EnumValues) > 0 {
+		details = append(details, snapio.Definition{Term: a.Message(MsgDetailValues), Description: enumDetails(flag)})
+	}
+	scope := a.name
+	if match.cmd != nil {
+		scope += " " + a.commandPath(match.cmd)
+	}
+	details = append(details, snapio.Definition{Term: a.Message(MsgDetailCommand), Description: scope})
+	if group := a.groupOf(flag, match.cmd); group != nil {
+		details = append(details, a.groupDetails(group, flag)...)
+	}
+	if flag.Required {
+		details = append(details, snapio.Definition{Term: a.Message(MsgDetailRequired)})
+	}
+
+	a.println()
+	width := 0
+	if a.IO().IsTTY() {
+		width = a.IO().Width()
+	}
+	a.print(snapio.DefinitionList(details, width))
+}
+
+// enumDetails lists the values of an enum flag with its aliases
+func enumDetails(flag *Flag) string {
+	values := strings.Join(flag.EnumValues, ", ")
+	if len(flag.EnumAliases) == 0 {
+		return values
+	}
+	aliases := make([]string, 0, len(flag.EnumAliases))
+	for alias, value := range flag.EnumAliases {
+		aliases = append(aliases, alias+"="+value)
+	}
+	sort.Strings(aliases)
+	return values + " (" + strings.Join(aliases, ", ") + ")"
+}
+
+// groupOf returns the flag group of cmd (or of the app) containing flag
+func (a *App) groupOf(flag *Flag, cmd *Command) *FlagGroup {
+	groups := a.flagGroups
+	if cmd != nil {
+		groups = cmd.flagGroups
+	}
+	for _, group := range groups {
+		for _, f := range group.Flags {
+			if f == flag {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+// groupDetails describes the group of flag and the other flags in it
+func (a *App) groupDetails(group *FlagGroup, flag *Flag) []snapio.Definition {
+	description := group.Name
+	if group.Description != "" {
+		description += " - " + group.Description
+	}
+	details := []snapio.Definition{{Term: a.Message(MsgDetailGroup), Description: description}}
+	if constraint := a.formatGroupConstraint(group.Constraint); constraint != "" {
+		details = append(details, snapio.Definition{Term: a.Message(MsgDetailRule), Description: constraint})
+	}
+	var related []string
+	for _, f := range group.Flags {
+		if f != flag && !f.isHidden() {
+			related = append(related, "--"+f.Name)
+		}
+	}
+	if len(related) > 0 {
+		sort.Strings(related)
+		details = append(details, snapio.Definition{
+			Term: a.Message(MsgDetailRelated), Description: strings.Join(related, ", "),
+		})
+	}
+	return details
+}
+
+// showGroupHelp renders the flag group name of the app or, when scoped, of
+// cmd and the app; unscoped lookups search every command
+func (a *App) showGroupHelp(name string, cmd *Command, scoped bool, w io.Writer) error {
+	type groupScope struct {
+		group *FlagGroup
+		flags map[string]*Flag
+	}
+	var matches []groupScope
+	var names []string
+	collect := func(groups []*FlagGroup, flags map[string]*Flag) {
+		for _, group := range groups {
+			if group.Hidden {
+				continue
+			}
+			names = append(names, group.Name)
+			if group.Name == name {
+				matches = append(matches, groupScope{group: group, flags: flags})
+			}
+		}
+	}
+	collect(a.flagGroups, a.flags)
+	switch {
+	case scoped && cmd != nil:
+		collect(cmd.flagGroups, cmd.flags)
+	case !scoped:
+		for _, top := range a.Commands() {
+			eachCommandSorted(top, func(c *Command) { collect(c.flagGroups, c.flags) })
+		}
+	}
+
+	if len(matches) == 0 {
+		cliErr := NewError(ErrorTypeInvalidArgument, a.msgf(MsgUnknownFlagGroup, name))
+		if eh := a.errorHandler; eh.suggestFlags {
+			eh.addDidYouMean(cliErr, fuzzy.FindSuggestions(name, names, eh.maxDistance, eh.maxSuggestions), a)
+		}
+		cliErr = a.errorHandler.ProcessError(cliErr, a)
+		cliErr = a.errorHandler.formatError(cliErr, a)
+		a.emit(Event{Type: EventErrorDisplayed, Err: cliErr})
+		return cliErr
+	}
+
+	data := a.render(func() {
+		for _, match := range matches {
+			maxWidth := 0
+			for _, flag := range match.group.Flags {
+				if !flag.isHidden() {
+					maxWidth = max(maxWidth, flagDisplayWidth(flag))
+				}
+			}
+			a.printFlagGroups([]*FlagGroup{match.group}, match.flags, maxWidth)
+		}
+	})
+	_, err := w.Write(bytes.TrimPrefix(data, []byte("\n")))
+	return err
+}
@@ -0,0 +1,476 @@
+package snap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MessageID identifies a built-in user-facing string (help headers, parse
+// errors, suggestions, exit code descriptions). Texts may contain fmt verbs,
+// which translations must keep in the same order.
+type MessageID string
+
+// Messages maps message IDs to their text in one language
+type Messages map[MessageID]string
+
+// Help output
+const (
+	MsgUsage                MessageID = "help.usage"
+	MsgFlags                MessageID = "help.flags"
+	MsgGlobalFlags          MessageID = "help.global_flags"
+	MsgCommands             MessageID = "help.commands"
+	MsgSubcommands          MessageID = "help.subcommands"
+	MsgArguments            MessageID = "help.arguments"
+	MsgRestArguments        MessageID = "help.rest_arguments"
+	MsgTopics               MessageID = "help.topics"
+	MsgVersion              MessageID = "help.version"
+	MsgAuthor               MessageID = "help.author"
+	MsgAuthors              MessageID = "help.authors"
+	MsgAliases              MessageID = "help.aliases"
+	MsgDefault              MessageID = "help.default"
+	MsgNote                 MessageID = "help.note"
+	MsgCommandHelpFooter    MessageID = "help.footer.command"
+	MsgTopicHelpFooter      MessageID = "help.footer.topic"
+	MsgSubcommandHelpFooter MessageID = "help.footer.subcommand"
+	MsgHelpFlag             MessageID = "help.flag.help"
+	MsgCommandHelpFlag      MessageID = "help.flag.command_help"
+	MsgVersionFlag          MessageID = "help.flag.version"
+	MsgQuietFlag            MessageID = "help.flag.quiet"
+	MsgPorcelainFlag        MessageID = "help.flag.porcelain"
+	MsgVerboseFlag          MessageID = "help.flag.verbose"
+	MsgExperiments          MessageID = "help.experiments"
+	MsgExperimentEnabled    MessageID = "help.experiment_enabled"
+	MsgExperimentsFooter    MessageID = "help.footer.experiments"
+	MsgForwardedFlags       MessageID = "help.forwarded_flags"
+	MsgDetailType           MessageID = "help.detail.type"
+	MsgDetailDefault        MessageID = "help.detail.default"
+	MsgDetailEnvironment    MessageID = "help.detail.environment"
+	MsgDetailValues         MessageID = "help.detail.values"
+	MsgDetailCommand        MessageID = "help.detail.command"
+	MsgDetailGroup          MessageID = "help.detail.group"
+	MsgDetailRule           MessageID = "help.detail.rule"
+	MsgDetailRelated        MessageID = "help.detail.related"
+	MsgDetailRequired       MessageID = "help.detail.required"
+)
+
+// Flag group constraints (help notes and error details)
+const (
+	MsgGroupMutuallyExclusive MessageID = "group.mutually_exclusive"
+	MsgGroupAtLeastOne        MessageID = "group.at_least_one"
+	MsgGroupAllOrNone         MessageID = "group.all_or_none"
+	MsgGroupExactlyOne        MessageID = "group.exactly_one"
+	MsgGroupDefaultFlag       MessageID = "group.default_flag"
+)
+
+// Parse errors and suggestions
+const (
+	MsgError                   MessageID = "error.prefix"
+	MsgUnknownFlag             MessageID = "error.unknown_flag"
+	MsgUnknownCommand          MessageID = "error.unknown_command"
+	MsgUnknownFlagGroup        MessageID = "error.unknown_flag_group"
+	MsgAmbiguousFlag           MessageID = "error.ambiguous_flag"
+	MsgAmbiguousCommand        MessageID = "error.ambiguous_command"
+	MsgFlagRequiresValue       MessageID = "error.flag_requires_value"
+	MsgGlobalFlagPosition      MessageID = "error.global_flag_position"
+	MsgFlagUnavailable         MessageID = "error.flag_unavailable"
+	MsgCommandUnavailable      MessageID = "error.command_unavailable"
+	MsgCommandGated            MessageID = "error.command_gated"
+	MsgInvalidEnumValue        MessageID = "error.invalid_enum_value"
+	MsgInvalidValue            MessageID = "error.invalid_value"
+	MsgFlagValidation          MessageID = "error.flag_validation"
+	MsgFlagValueUnreadable     MessageID = "error.flag_value_unreadable"
+	MsgMissingValue            MessageID = "error.missing_value"
+	MsgInvalidOverride         MessageID = "error.invalid_override"
+	MsgCredentialUnresolved    MessageID = "error.credential_unresolved"
+	MsgInterpolationCycle      MessageID = "error.interpolation_cycle"
+	MsgInterpolationUnknown    MessageID = "error.interpolation_unknown_flag"
+	MsgMissingArgument         MessageID = "error.missing_argument"
+	MsgMissingVariadicArgument MessageID = "error.missing_variadic_argument"
+	MsgArgFlagConflict         MessageID = "error.arg_flag_conflict"
+	MsgArgOrFlagRequired       MessageID = "error.arg_or_flag_required"
+	MsgTooFewArgValues         MessageID = "error.too_few_arg_values"
+	MsgTooManyArgValues        MessageID = "error.too_many_arg_values"
+	MsgTooFewRestArgs          MessageID = "error.too_few_rest_args"
+	MsgTooManyRestArgs         MessageID = "error.too_many_rest_args"
+	MsgInvalidArgEnumValue     MessageID = "error.invalid_arg_enum_value"
+	MsgArgPathNotFound         MessageID = "error.arg_path_not_found"
+	MsgArgNotADirectory        MessageID = "error.arg_not_a_directory"
+	MsgArgPathError            MessageID = "error.arg_path_error"
+	MsgInvalidArgValue         MessageID = "error.invalid_arg_value"
+	MsgInvalidVariadicArgValue MessageID = "error.invalid_variadic_arg_value"
+	MsgArgFileTooDeep          MessageID = "error.argfile_too_deep"
+	MsgArgFileUnreadable       MessageID = "error.argfile_unreadable"
+	MsgArgFileInvalid          MessageID = "error.argfile_invalid"
+	MsgTooManyArgs             MessageID = "error.limit.too_many_args"
+	MsgArgTooLong              MessageID = "error.limit.arg_too_long"
+	MsgFlagNameTooLong         MessageID = "error.limit.flag_name_too_long"
+	MsgFlagNameNotUTF8         MessageID = "error.limit.flag_name_not_utf8"
+	MsgGroupExclusiveViolation MessageID = "error.group.mutually_exclusive"
+	MsgGroupAtLeastOneMissing  MessageID = "error.group.at_least_one"
+	MsgGroupAllOrNoneViolation MessageID = "error.group.all_or_none"
+	MsgGroupExactlyOneMismatch MessageID = "error.group.exactly_one"
+	MsgDidYouMean              MessageID = "suggest.did_you_mean"
+	MsgDidYouMeanList          MessageID = "suggest.did_you_mean_list"
+	MsgDidYouMeanOneOf         MessageID = "suggest.did_you_mean_one_of"
+	MsgGroupHelpHint           MessageID = "suggest.group_help"
+	MsgFlagGroup               MessageID = "suggest.flag_group"
+	MsgConstraint              MessageID = "suggest.constraint"
+	MsgAutoCorrectPrompt       MessageID = "suggest.autocorrect_prompt"
+	MsgAutoCorrectNotice       MessageID = "suggest.autocorrect_notice"
+)
+
+// Exit code descriptions (ExitCodeManager.Table)
+const (
+	MsgExitSuccess      MessageID = "exit.success"
+	MsgExitGeneral      MessageID = "exit.general"
+	MsgExitUsage        MessageID = "exit.usage"
+	MsgExitInvalidInput MessageID = "exit.invalid_input"
+	MsgExitDataErr      MessageID = "exit.dataerr"
+	MsgExitNotFound     MessageID = "exit.not_found"
+	MsgExitPermission   MessageID = "exit.permission"
+	MsgExitNoInput      MessageID = "exit.noinput"
+	MsgExitNoUser       MessageID = "exit.nouser"
+	MsgExitNoHost       MessageID = "exit.nohost"
+	MsgExitUnavailable  MessageID = "exit.unavailable"
+	MsgExitSoftware     MessageID = "exit.software"
+	MsgExitOSErr        MessageID = "exit.oserr"
+	MsgExitOSFile       MessageID = "exit.osfile"
+	MsgExitCantCreat    MessageID = "exit.cantcreat"
+	MsgExitIOErr        MessageID = "exit.ioerr"
+	MsgExitTempFail     MessageID = "exit.tempfail"
+	MsgExitProtocol     MessageID = "exit.protocol"
+	MsgExitConfig       MessageID = "exit.config"
+)
+
+// defaultLocale is the catalog every lookup falls back to
+const defaultLocale = "en"
+
+// builtinMessages holds the catalogs shipped with snap
+var builtinMessages = map[string]Messages{
+	"en": {
+		MsgUsage:                "Usage:",
+		MsgFlags:                "Flags:",
+		MsgGlobalFlags:          "Global Flags:",
+		MsgCommands:             "Commands:",
+		MsgSubcommands:          "Subcommands:",
+		MsgArguments:            "Arguments:",
+		MsgRestArguments:        "All remaining arguments are passed through",
+		MsgTopics:               "Topics:",
+		MsgVersion:              "Version:",
+		MsgAuthor:               "Author:",
+		MsgAuthors:              "Authors:",
+		MsgAliases:              "aliases: %s",
+		MsgDefault:              "default: %s",
+		MsgNote:                 "Note: %s",
+		MsgCommandHelpFooter:    `Use "%s COMMAND --help" for more information about a command.`,
+		MsgTopicHelpFooter:      `Use "%s help TOPIC" for more information about a topic.`,
+		MsgSubcommandHelpFooter: `Use "%s SUBCOMMAND --help" for more information about a subcommand.`,
+		MsgHelpFlag:             "Show help",
+		MsgCommandHelpFlag:      "Show command help",
+		MsgVersionFlag:          "Show version",
+		MsgQuietFlag:            "Only print warnings, errors and results",
+		MsgPorcelainFlag:        "Print stable, machine-readable output",
+		MsgVerboseFlag:          "Print more details (-vv adds timestamps)",
+		MsgExperiments:          "Experiments:",
+		MsgExperimentEnabled:    "(enabled)",
+		MsgExperimentsFooter:    "Enable experiments with %s=NAME[,NAME].",
+		MsgForwardedFlags:       "Forwarded Flags:",
+		MsgDetailType:           "Type:",
+		MsgDetailDefault:        "Default:",
+		MsgDetailEnvironment:    "Environment:",
+		MsgDetailValues:         "Values:",
+		MsgDetailCommand:        "Command:",
+		MsgDetailGroup:          "Group:",
+		MsgDetailRule:           "Rule:",
+		MsgDetailRelated:        "Related:",
+		MsgDetailRequired:       "Required",
+
+		MsgGroupMutuallyExclusive: "Only one of these flags can be used at a time",
+		MsgGroupAtLeastOne:        "At least one of these flags is required",
+		MsgGroupAllOrNone:         "Either all of these flags must be provided, or none",
+		MsgGroupExactlyOne:        "Exactly one of these flags must be provided",
+		MsgGroupDefaultFlag:       "--%s is used when none of these flags is given",
+
+		MsgError:                   "Error: %s",
+		MsgUnknownFlag:             "unknown flag: --%s",
+		MsgUnknownCommand:          "unknown command: %s",
+		MsgUnknownFlagGroup:        "unknown flag group: %s",
+		MsgAmbiguousFlag:           "ambiguous flag: --%s",
+		MsgAmbiguousCommand:        "ambiguous command: %s",
+		MsgFlagRequiresValue:       "flag requires a value: %s",
+		MsgGlobalFlagPosition:      "global flag --%s must be given before the command",
+		MsgFlagUnavailable:         "flag --%s is not available",
+		MsgCommandUnavailable:      "command %s is not available",
+		MsgCommandGated:            "cannot run %s: %s",
+		MsgInvalidEnumValue:        "invalid enum value: %s, valid values: %s",
+		MsgInvalidValue:            "invalid %s value",
+		MsgFlagValidation:          "invalid value for --%s: %v",
+		MsgFlagValueUnreadable:     "cannot read value for --%s: %v",
+		MsgMissingValue:            "missing required value",
+		MsgInvalidOverride:         "invalid value %v for --%s: %v",
+		MsgCredentialUnresolved:    "cannot resolve credential for --%s from %s: %v",
+		MsgInterpolationCycle:      "flag interpolation cycle: --%s",
+		MsgInterpolationUnknown:    "--%s references unknown flag --%s",
+		MsgMissingArgument:         "missing required argument: %s",
+		MsgArgFlagConflict:         "argument %s cannot be used with %s",
+		MsgArgOrFlagRequired:       "either argument %s or %s is required",
+		MsgTooFewArgValues:         "too few values for argument %s: got %d, expected at least %d",
+		MsgTooManyArgValues:        "too many values for argument %s: got %d, expected at most %d",
+		MsgTooFewRestArgs:          "too few extra arguments: got %d, expected at least %d",
+		MsgTooManyRestArgs:         "too many extra arguments: got %d, expected at most %d",
+		MsgInvalidArgEnumValue:     "invalid value for argument %s: %s, valid values: %s",
+		MsgArgPathNotFound:         "argument %s: %s does not exist",
+		MsgArgNotADirectory:        "argument %s: %s is not a directory",
+		MsgArgPathError:            "argument %s: %v",
+		MsgInvalidArgValue:         "invalid %s value for argument '%s': %s",
+		MsgInvalidVariadicArgValue: "invalid %s value in variadic argument '%s': %s",
+		MsgArgFileTooDeep:          "argument files nested too deeply at %s",
+		MsgArgFileUnreadable:       "cannot read argument file: %v",
+		MsgArgFileInvalid:          "invalid argument file %s: %v",
+		MsgTooManyArgs:             "too many arguments: %d (max %d)",
+		MsgArgTooLong:              "argument %d is too long: %d bytes (max %d)",
+		MsgFlagNameTooLong:         "flag name is too long: %d bytes (max %d)",
+		MsgFlagNameNotUTF8:         "flag name is not valid UTF-8: %q",
+		MsgMissingVariadicArgument: "missing required variadic argument: %s",
+		MsgGroupExclusiveViolation: "flags in group '%s' are mutually exclusive, but multiple were provided: %v",
+		MsgGroupAtLeastOneMissing:  "group '%s' requires at least one flag to be set",
+		MsgGroupAllOrNoneViolation: "group '%s' requires either all flags or no flags to be set",
+		MsgGroupExactlyOneMismatch: "group '%s' requires exactly one flag to be set, but %d were provided",
+		MsgDidYouMean:              "Did you mean '%s'?",
+		MsgDidYouMeanList:          "Did you mean:",
+		MsgDidYouMeanOneOf:         "Did you mean one of %s?",
+		MsgGroupHelpHint:           "Run '%s' to see valid flag combinations for group '%s'",
+		MsgFlagGroup:               "Flag group '%s':",
+		MsgConstraint:              "Constraint: %s",
+		MsgAutoCorrectPrompt:       "Did you mean '%s'? [y/N]",
+		MsgAutoCorrectNotice:       "Unknown command '%s', running '%s' instead",
+
+		MsgExitSuccess:      "Successful termination",
+		MsgExitGeneral:      "General error",
+		MsgExitUsage:        "Command line usage error",
+		MsgExitInvalidInput: "Invalid input data",
+		MsgExitDataErr:      "Data format error",
+		MsgExitNotFound:     "Resource not found",
+		MsgExitPermission:   "Permission denied",
+		MsgExitNoInput:      "Cannot open input",
+		MsgExitNoUser:       "Addressee unknown",
+		MsgExitNoHost:       "Host name unknown",
+		MsgExitUnavailable:  "Service unavailable",
+		MsgExitSoftware:     "Internal software error",
+		MsgExitOSErr:        "System error",
+		MsgExitOSFile:       "Critical OS file missing",
+		MsgExitCantCreat:    "Cannot create output file",
+		MsgExitIOErr:        "Input/output error",
+		MsgExitTempFail:     "Temporary failure, retry later",
+		MsgExitProtocol:     "Remote error in protocol",
+		MsgExitConfig:       "Configuration error",
+	},
+	"de": {
+		MsgUsage:                "Verwendung:",
+		MsgFlags:                "Optionen:",
+		MsgGlobalFlags:          "Globale Optionen:",
+		MsgCommands:             "Befehle:",
+		MsgSubcommands:          "Unterbefehle:",
+		MsgArguments:            "Argumente:",
+		MsgRestArguments:        "Alle weiteren Argumente werden unverändert weitergereicht",
+		MsgTopics:               "Themen:",
+		MsgVersion:              "Version:",
+		MsgAuthor:               "Autor:",
+		MsgAuthors:              "Autoren:",
+		MsgAliases:              "Aliase: %s",
+		MsgDefault:              "Standard: %s",
+		MsgNote:                 "Hinweis: %s",
+		MsgCommandHelpFooter:    `Verwenden Sie "%s BEFEHL --help" für weitere Informationen zu einem Befehl.`,
+		MsgTopicHelpFooter:      `Verwenden Sie "%s help THEMA" für weitere Informationen zu einem Thema.`,
+		MsgSubcommandHelpFooter: `Verwenden Sie "%s UNTERBEFEHL --help" für weitere Informationen zu einem Unterbefehl.`,
+		MsgHelpFlag:             "Hilfe anzeigen",
+		MsgCommandHelpFlag:      "Hilfe zum Befehl anzeigen",
+		MsgVersionFlag:          "Version anzeigen",
+		MsgQuietFlag:            "Nur Warnungen, Fehler und Ergebnisse ausgeben",
+		MsgPorcelainFlag:        "Stabile, maschinenlesbare Ausgabe",
+		MsgVerboseFlag:          "Mehr Details ausgeben (-vv mit Zeitstempeln)",
+		MsgExperiments:          "Experimente:",
+		MsgExperimentEnabled:    "(aktiviert)",
+		MsgExperimentsFooter:    "Experimente aktivieren mit %s=NAME[,NAME].",
+		MsgForwardedFlags:       "Weitergereichte Optionen:",
+		MsgDetailType:           "Typ:",
+		MsgDetailDefault:        "Standard:",
+		MsgDetailEnvironment:    "Umgebung:",
+		MsgDetailValues:         "Werte:",
+		MsgDetailCommand:        "Befehl:",
+		MsgDetailGroup:          "Gruppe:",
+		MsgDetailRule:           "Regel:",
+		MsgDetailRelated:        "Verwandt:",
+		MsgDetailRequired:       "Erforderlich",
+
+		MsgGroupMutuallyExclusive: "Nur eine dieser Optionen kann gleichzeitig verwendet werden",
+		MsgGroupAtLeastOne:        "Mindestens eine dieser Optionen ist erforderlich",
+		MsgGroupAllOrNone:         "Entweder alle oder keine dieser Optionen müssen angegeben werden",
+		MsgGroupExactlyOne:        "Genau eine dieser Optionen muss angegeben werden",
+		MsgGroupDefaultFlag:       "Ohne Angabe einer dieser Optionen wird --%s verwendet",
+
+		MsgError:                   "Fehler: %s",
+		MsgUnknownFlag:             "unbekannte Option: --%s",
+		MsgUnknownCommand:          "unbekannter Befehl: %s",
+		MsgUnknownFlagGroup:        "unbekannte Optionsgruppe: %s",
+		MsgAmbiguousFlag:           "mehrdeutige Option: --%s",
+		MsgAmbiguousCommand:        "mehrdeutiger Befehl: %s",
+		MsgFlagRequiresValue:       "Option benötigt einen Wert: %s",
+		MsgGlobalFlagPosition:      "globale Option --%s muss vor dem Befehl angegeben werden",
+		MsgFlagUnavailable:         "Option --%s ist nicht verfügbar",
+		MsgCommandUnavailable:      "Befehl %s ist nicht verfügbar",
+		MsgCommandGated:            "%s kann nicht ausgeführt werden: %s",
+		MsgInvalidEnumValue:        "ungültiger Wert: %s, erlaubte Werte: %s",
+		MsgInvalidValue:            "ungültiger Wert vom Typ %s",
+		MsgFlagValidation:          "ungültiger Wert für --%s: %v",
+		MsgFlagValueUnreadable:     "Wert für --%s kann nicht gelesen werden: %v",
+		MsgMissingValue:            "erforderlicher Wert fehlt",
+		MsgInvalidOverride:         "ungültiger Wert %v für --%s: %v",
+		MsgCredentialUnresolved:    "Zugangsdaten für --%s aus %s können nicht aufgelöst werden: %v",
+		MsgInterpolationCycle:      "zyklische Interpolation von Optionen: --%s",
+		MsgInterpolationUnknown:    "--%s verweist auf die unbekannte Option --%s",
+		MsgMissingArgument:         "erforderliches Argument fehlt: %s",
+		MsgArgFlagConflict:         "Argument %s kann nicht zusammen mit %s verwendet werden",
+		MsgArgOrFlagRequired:       "entweder Argument %s oder %s ist erforderlich",
+		MsgTooFewArgValues:         "zu wenige Werte für Argument %s: %d angegeben, mindestens %d erwartet",
+		MsgTooManyArgValues:        "zu viele Werte für Argument %s: %d angegeben, höchstens %d erwartet",
+		MsgTooFewRestArgs:          "zu wenige weitere Argumente: %d angegeben, mindestens %d erwartet",
+		MsgTooManyRestArgs:         "zu viele weitere Argumente: %d angegeben, höchstens %d erwartet",
+		MsgInvalidArgEnumValue:     "ungültiger Wert für Argument %s: %s, erlaubte Werte: %s",
+		MsgArgPathNotFound:         "Argument %s: %s existiert nicht",
+		MsgArgNotADirectory:        "Argument %s: %s ist kein Verzeichnis",
+		MsgArgPathError:            "Argument %s: %v",
+		MsgInvalidArgValue:         "ungültiger Wert vom Typ %s für Argument '%s': %s",
+		MsgInvalidVariadicArgValue: "ungültiger Wert vom Typ %s im variadischen Argument '%s': %s",
+		MsgArgFileTooDeep:          "Argumentdateien zu tief verschachtelt bei %s",
+		MsgArgFileUnreadable:       "Argumentdatei kann nicht gelesen werden: %v",
+		MsgArgFileInvalid:          "ungültige Argumentdatei %s: %v",
+		MsgTooManyArgs:             "zu viele Argumente: %d (höchstens %d)",
+		MsgArgTooLong:              "Argument %d ist zu lang: %d Bytes (höchstens %d)",
+		MsgFlagNameTooLong:         "Optionsname ist zu lang: %d Bytes (höchstens %d)",
+		MsgFlagNameNotUTF8:         "Optionsname ist kein gültiges UTF-8: %q",
+		MsgMissingVariadicArgument: "erforderliches variadisches Argument fehlt: %s",
+		MsgGroupExclusiveViolation: "die Optionen der Gruppe '%s' schließen sich gegenseitig aus, angegeben wurden: %v",
+		MsgGroupAtLeastOneMissing:  "Gruppe '%s' erfordert mindestens eine Option",
+		MsgGroupAllOrNoneViolation: "Gruppe '%s' erfordert entweder alle oder keine Optionen",
+		MsgGroupExactlyOneMismatch: "Gruppe '%s' erfordert genau eine Option, angegeben wurden %d",
+		MsgDidYouMean:              "Meinten Sie '%s'?",
+		MsgDidYouMeanList:          "Meinten Sie:",
+		MsgDidYouMeanOneOf:         "Meinten Sie eines von %s?",
+		MsgGroupHelpHint:           "Führen Sie '%s' aus, um die gültigen Kombinationen der Gruppe '%s' zu sehen",
+		MsgFlagGroup:               "Optionsgruppe '%s':",
+		MsgConstraint:              "Bedingung: %s",
+		MsgAutoCorrectPrompt:       "Meinten Sie '%s'? [y/N]",
+		MsgAutoCorrectNotice:       "Unbekannter Befehl '%s', führe stattdessen '%s' aus",
+
+		MsgExitSuccess:      "Erfolgreich beendet",
+		MsgExitGeneral:      "Allgemeiner Fehler",
+		MsgExitUsage:        "Fehlerhafte Befehlszeile",
+		MsgExitInvalidInput: "Ungültige Eingabedaten",
+		MsgExitDataErr:      "Fehlerhaftes Datenformat",
+		MsgExitNotFound:     "Ressource nicht gefunden",
+		MsgExitPermission:   "Zugriff verweigert",
+		MsgExitNoInput:      "Eingabe kann nicht geöffnet werden",
+		MsgExitNoUser:       "Empfänger unbekannt",
+		MsgExitNoHost:       "Hostname unbekannt",
+		MsgExitUnavailable:  "Dienst nicht verfügbar",
+		MsgExitSoftware:     "Interner Softwarefehler",
+		MsgExitOSErr:        "Systemfehler",
+		MsgExitOSFile:       "Wichtige Systemdatei fehlt",
+		MsgExitCantCreat:    "Ausgabedatei kann nicht erstellt werden",
+		MsgExitIOErr:        "Ein-/Ausgabefehler",
+		MsgExitTempFail:     "Vorübergehender Fehler, später erneut versuchen",
+		MsgExitProtocol:     "Protokollfehler der Gegenstelle",
+		MsgExitConfig:       "Konfigurationsfehler",
+	},
+}
+
+// SetLocale selects the language of built-in messages ("de", "pt-BR",
+// "de_DE.UTF-8"). Lookups fall back from the exact locale to its base
+// language and finally to English; "" restores English.
+func (a *App) SetLocale(locale string) *App {
+	a.locale = normalizeLocale(locale)
+	a.invalidateHelp()
+	return a
+}
+
+// Locale returns the normalized locale set with SetLocale ("" for English)
+func (a *App) Locale() string {
+	return a.locale
+}
+
+// RegisterMessages adds translations for locale, extending or replacing
+// entries of the built-in catalog for that language. Missing IDs fall back
+// to the base language and English.
+func (a *App) RegisterMessages(locale string, messages Messages) *App {
+	locale = normalizeLocale(locale)
+	if a.translations == nil {
+		a.translations = make(map[string]Messages)
+	}
+	catalog := a.translations[locale]
+	if catalog == nil {
+		catalog = make(Messages, len(messages))
+		a.translations[locale] = catalog
+	}
+	for id, text := range messages {
+		catalog[id] = text
+	}
+	a.invalidateHelp()
+	return a
+}
+
+// SetMessage overrides a single message regardless of the active locale
+func (a *App) SetMessage(id MessageID, text string) *App {
+	if a.messageOverrides == nil {
+		a.messageOverrides = make(Messages)
+	}
+	a.messageOverrides[id] = text
+	a.invalidateHelp()
+	return a
+}
+
+// Message returns the text for id in the active locale
+func (a *App) Message(id MessageID) string {
+	if text, ok := a.messageOverrides[id]; ok {
+		return text
+	}
+	if a.locale != "" {
+		if text, ok := a.lookupMessage(a.locale, id); ok {
+			return text
+		}
+		if base, _, found := strings.Cut(a.locale, "-"); found {
+			if text, ok := a.lookupMessage(base, id); ok {
+				return text
+			}
+		}
+	}
+	if text, ok := builtinMessages[defaultLocale][id]; ok {
+		return text
+	}
+	return string(id)
+}
+
+// lookupMessage checks the user catalog for locale, then the built-in one
+func (a *App) lookupMessage(locale string, id MessageID) (string, bool) {
+	if text, ok := a.translations[locale][id]; ok {
+		return text, true
+	}
+	text, ok := builtinMessages[locale][id]
+	return text, ok
+}
+
+// msgf formats the message for id with args
+func (a *App) msgf(id MessageID, args ...any) string {
+	return fmt.Sprintf(a.Message(id), args...)
+}
+
+// normalizeLocale turns "de_DE.UTF-8" into "de-de"
+func normalizeLocale(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if locale == "c" || locale == "posix" {
+		return ""
+	}
+	return locale
+}
@@ -0,0 +1,168 @@
+package snap
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// interpolationPattern matches references to other flags in interpolated
+// values: ${flag:name} and {{.name}}
+var interpolationPattern = regexp.MustCompile(`\$\{flag:([^}]+)\}|\{\{\s*\.([\w-]+)\s*\}\}`)
+
+// Interpolate expands references to other flags in the value of a string or
+// string slice flag, whether it comes from the command line, the environment
+// or the default:
+//
+//	app.StringFlag("name", "").Default("app").Back().
+//		StringFlag("log-file", "").Default("{{.name}}.log").Interpolate().Back().
+//		StringFlag("addr", "").Default("${flag:host}:${flag:port}").Interpolate().Back()
+//
+// References are resolved after parsing, using the formatted value of the
+// referenced flag (slices are joined with commas); interpolated flags may
+// reference each other, and cycles or unknown flags fail the parse.
+func (f *FlagBuilder[T, P]) Interpolate() *FlagBuilder[T, P] {
+	f.flag.interpolate = true
+	return f
+}
+
+// interpolator resolves the interpolated flags of one parse result
+type interpolator struct {
+	result  *ParseResult
+	scopes  [2]map[string]*Flag // Command flags, then app flags
+	done    map[string]bool     // Resolved flags
+	pending []string            // Flags being resolved, outermost first
+}
+
+// interpolateFlags expands the flags declared with Interpolate in the scope
+// of result; it does nothing (and does not allocate) when there are none
+func (p *Parser) interpolateFlags(result *ParseResult) error {
+	if !p.hasInterpolation {
+		return nil
+	}
+	var cmdFlags map[string]*Flag
+	if result.Command != nil {
+		cmdFlags = result.Command.flags
+	}
+	scopes := [2]map[string]*Flag{cmdFlags, p.app.flags}
+	in := &interpolator{result: result, scopes: scopes, done: make(map[string]bool)}
+	for _, flags := range scopes {
+		for _, name := range sortedKeys(flags) {
+			if !flags[name].interpolate {
+				continue
+			}
+			if err := in.resolve(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookup finds a flag in scope, command flags first
+func (in *interpolator) lookup(name string) *Flag {
+	for _, flags := range in.scopes {
+		if flag := flags[name]; flag != nil {
+			return flag
+		}
+	}
+	return nil
+}
+
+// resolve expands the references in the value of the interpolated flag name,
+// resolving interpolated flags it references first
+func (in *interpolator) resolve(name string) error {
+	if in.done[name] {
+		return nil
+	}
+	for i, outer := range in.pending {
+		if outer == name {
+			cycle := append(slices.Clone(in.pending[i:]), name)
+			path := strings.Join(cycle, " -> --")
+			return &ParseError{
+				Type:    ErrorTypeInvalidValue,
+				Message: "flag interpolation cycle: --" + path,
+				Flag:    name,
+				msgID:   MsgInterpolationCycle,
+				msgArgs: []any{path},
+			}
+		}
+	}
+	in.pending = append(in.pending, name)
+	defer func() { in.pending = in.pending[:len(in.pending)-1] }()
+
+	flag := in.lookup(name)
+	r := in.result
+	switch {
+	case flag.Type == FlagTypeString && flag.Global:
+		if value, ok := r.GetGlobalString(name); ok {
+			expanded, err := in.expand(name, value)
+			if err != nil {
+				return err
+			}
+			r.GlobalStringFlags[name] = expanded
+		}
+	case flag.Type == FlagTypeString:
+		if value, ok := r.GetString(name); ok {
+			expanded, err := in.expand(name, value)
+			if err != nil {
+				return err
+			}
+			r.StringFlags[name] = expanded
+		}
+	case flag.Type == FlagTypeStringSlice:
+		get := r.GetStringSlice
+		if flag.Global {
+			get = r.GetGlobalStringSlice
+		}
+		// The slice shares storage with the result, so elements are updated in place
+		values, _ := get(name)
+		for i, value := range values {
+			expanded, err := in.expand(name, value)
+			if err != nil {
+				return err
+			}
+			values[i] = expanded
+		}
+	}
+	in.done[name] = true
+	return nil
+}
+
+// expand replaces the references in value, an element of flag name
+func (in *interpolator) expand(name, value string) (string, error) {
+	if !strings.Contains(value, "${flag:") && !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	var err error
+	expanded := interpolationPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		m := interpolationPattern.FindStringSubmatch(ref)
+		target := m[1] + m[2]
+		flag := in.lookup(target)
+		if flag == nil {
+			err = &ParseError{
+				Type:    ErrorTypeInvalidValue,
+				Message: "--" + name + " references unknown flag --" + target,
+				Flag:    name,
+				msgID:   MsgInterpolationUnknown,
+				msgArgs: []any{name, target},
+			}
+			return ""
+		}
+		if flag.interpolate {
+			if err = in.resolve(target); err != nil {
+				return ""
+			}
+		}
+		for _, lookup := range flagLookups {
+			if values, ok := lookup(in.result, target); ok {
+				return strings.Join(values, ",")
+			}
+		}
+		return ""
+	})
+	return expanded, err
+}
@@ -0,0 +1,109 @@
+package snap
+
+import "slices"
+
+// Commands returns the top-level commands sorted by name, including hidden
+// ones (see Command.IsHidden). Built-in commands such as "version" are added
+// when the app runs and are listed only afterwards.
+func (a *App) Commands() []*Command {
+	return sortedValues(a.commands)
+}
+
+// Flags returns the app-level flags sorted by name
+func (a *App) Flags() []*Flag {
+	return sortedValues(a.flags)
+}
+
+// Args returns the app-level positional arguments in position order
+func (a *App) Args() []*Arg {
+	return slices.Clone(a.args)
+}
+
+// Flags returns the flags of the command sorted by name; global flags are
+// listed by App.Flags
+func (c *Command) Flags() []*Flag {
+	return sortedValues(c.flags)
+}
+
+// Subcommands returns the subcommands sorted by name, including hidden ones
+func (c *Command) Subcommands() []*Command {
+	return sortedValues(c.subcommands)
+}
+
+// Args returns the positional arguments of the command in position order
+func (c *Command) Args() []*Arg {
+	return slices.Clone(c.args)
+}
+
+// Parent returns the enclosing command, or nil for a top-level command
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// IsHidden reports whether the command is left out of help, either through
+// Hidden or a VisibleIf/EnabledIf predicate that is currently false
+func (c *Command) IsHidden() bool {
+	return c.isHidden()
+}
+
+// IsHidden reports whether the flag is left out of help, either through
+// Hidden, a hidden flag group or a VisibleIf/EnabledIf predicate
+func (f *Flag) IsHidden() bool {
+	return f.isHidden()
+}
+
+// IsSecret reports whether the flag's value is redacted in dumps (see Secret)
+func (f *Flag) IsSecret() bool {
+	return f.secret
+}
+
+// Default returns the default value of the flag with its Go type (string,
+// int, time.Duration, []string, ...) as used when the flag is not set.
+// Defaults from DefaultFunc are computed on each call.
+func (f *Flag) Default() any {
+	flag := f
+	if f.defaultFunc != nil {
+		resolved := *f
+		f.defaultFunc(&resolved)
+		flag = &resolved
+	}
+	switch flag.Type {
+	case FlagTypeString:
+		return flag.DefaultString
+	case FlagTypeEnum:
+		return flag.DefaultEnum
+	case FlagTypeBool:
+		return flag.DefaultBool
+	case FlagTypeInt:
+		return flag.DefaultInt
+	case FlagTypeInt64, FlagTypeInt32:
+		return flag.DefaultInt64
+	case FlagTypeUint, FlagTypeUint64:
+		return flag.DefaultUint64
+	case FlagTypeDuration:
+		return flag.DefaultDuration
+	case FlagTypeFloat:
+		return flag.DefaultFloat
+	case FlagTypeStringSlice:
+		return flag.DefaultStringSlice
+	case FlagTypeIntSlice:
+		return flag.DefaultIntSlice
+	case FlagTypeFloatSlice:
+		return flag.DefaultFloatSlice
+	case FlagTypeDurationSlice:
+		return flag.DefaultDurationSlice
+	case FlagTypeEnumSlice:
+		return flag.DefaultEnumSlice
+	default:
+		return nil
+	}
+}
+
+// sortedValues returns the values of m ordered by key
+func sortedValues[V any](m map[string]V) []V {
+	values := make([]V, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		values = append(values, m[key])
+	}
+	return values
+}
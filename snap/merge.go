@@ -0,0 +1,300 @@
+package snap
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergeStrategy controls how a slice or map field combines values from
+// several configuration sources (merge:"..." struct tag)
+type MergeStrategy int
+
+const (
+	MergeDefault MergeStrategy = iota // Slices: replace; maps: deep-merge
+	MergeReplace                      // The highest-priority source wins
+	MergeAppend                       // Slices: concatenate in priority order; maps: deep-merge
+	MergeUnion                        // Slices: concatenate, dropping duplicates; maps: deep-merge
+)
+
+// parseMergeTag maps a merge tag to its strategy; an empty tag is the default
+func parseMergeTag(tag string) (MergeStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(tag)) {
+	case "":
+		return MergeDefault, nil
+	case "replace":
+		return MergeReplace, nil
+	case "append":
+		return MergeAppend, nil
+	case "union":
+		return MergeUnion, nil
+	default:
+		return MergeDefault, fmt.Errorf("unknown merge strategy %q (want append, union or replace)", tag)
+	}
+}
+
+// resolveFields merges sources in priority order like Resolve, but field by
+// field: slices follow their merge strategy, map fields are deep-merged (or
+// replaced) as a whole, and "field.N" keys address element N of a slice field.
+// Defaults only fill fields no other source sets, so they are never appended to.
+func (pm *PrecedenceManager) resolveFields(schema *ConfigSchema) (map[string]any, error) {
+	for _, key := range sortedKeys(schema.Fields) {
+		if _, err := parseMergeTag(schema.Fields[key].MergeTag); err != nil {
+			return nil, fmt.Errorf("field '%s': %w", key, err)
+		}
+	}
+	result := make(map[string]any)
+	defaulted := make(map[string]bool) // keys whose current value came from defaults
+
+	for priority := int(SourceTypeDefaults); priority <= int(SourceTypeFlags); priority++ {
+		for _, source := range pm.sources {
+			if source.Priority != priority {
+				continue
+			}
+			flat := make(map[string]any)
+			flattenFields("", source.Data, flat, schema)
+
+			// Sorted so element addressing ("tags.0", "tags.1") applies in order
+			keys := make([]string, 0, len(flat))
+			for key := range flat {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				isDefault := source.Type == SourceTypeDefaults
+				if err := mergeField(result, key, flat[key], schema, isDefault || defaulted[key]); err != nil {
+					return nil, err
+				}
+				defaulted[key] = isDefault
+			}
+		}
+	}
+	return result, nil
+}
+
+// flattenFields converts nested maps to dotted keys like flattenMap, but keeps
+// the value of map-typed schema fields intact and spells keys given by json
+// name (files, defaults) like the schema, as env and flag sources do
+func flattenFields(prefix string, src map[string]any, dst map[string]any, schema *ConfigSchema) {
+	for k, v := range src {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if alias, ok := schema.aliases[key]; ok && schema.Fields[key] == nil {
+			key = alias
+		}
+		if field := schema.Fields[key]; field != nil && field.Type != nil && field.Type.Kind() == reflect.Map {
+			dst[key] = v
+			continue
+		}
+		if sub, ok := v.(map[string]any); ok {
+			flattenFields(key, sub, dst, schema)
+			continue
+		}
+		dst[key] = v
+	}
+}
+
+// mergeField merges one source value into result; replace forces the value to
+// win regardless of strategy (used while result only holds a default)
+func mergeField(result map[string]any, key string, value any, schema *ConfigSchema, replace bool) error {
+	field := schema.Fields[key]
+	if field == nil || field.Type == nil {
+		if base, index, ok := sliceElementKey(key, schema); ok {
+			return setSliceElement(result, base, index, value)
+		}
+		result[key] = value
+		return nil
+	}
+
+	existing, exists := result[key]
+	if !exists || replace {
+		result[key] = value
+		return nil
+	}
+
+	switch field.Type.Kind() { //nolint:exhaustive // only collections have merge strategies
+	case reflect.Slice:
+		switch field.Merge { // exhaustive over MergeStrategy
+		case MergeAppend:
+			result[key] = append(sliceElements(existing), sliceElements(value)...)
+		case MergeUnion:
+			result[key] = unionElements(sliceElements(existing), sliceElements(value))
+		case MergeDefault, MergeReplace:
+			result[key] = value
+		}
+	case reflect.Map:
+		if field.Merge == MergeReplace {
+			result[key] = value
+			return nil
+		}
+		base, err := mapEntries(existing)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", key, err)
+		}
+		overlay, err := mapEntries(value)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", key, err)
+		}
+		result[key] = deepMerge(base, overlay)
+	default:
+		result[key] = value
+	}
+	return nil
+}
+
+// sliceElementKey splits "tags.2" into a slice field name and element index
+func sliceElementKey(key string, schema *ConfigSchema) (string, int, bool) {
+	dot := strings.LastIndexByte(key, '.')
+	if dot < 0 {
+		return "", 0, false
+	}
+	field := schema.Fields[key[:dot]]
+	if field == nil || field.Type == nil || field.Type.Kind() != reflect.Slice {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(key[dot+1:])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return key[:dot], index, true
+}
+
+// setSliceElement replaces element index of the slice field base, or appends
+// when index is one past the end
+func setSliceElement(result map[string]any, base string, index int, value any) error {
+	elems := sliceElements(result[base])
+	switch {
+	case index < len(elems):
+		elems[index] = value
+	case index == len(elems):
+		elems = append(elems, value)
+	default:
+		return fmt.Errorf("field '%s': index %d out of range (length %d)", base, index, len(elems))
+	}
+	result[base] = elems
+	return nil
+}
+
+// sliceElements returns a fresh []any holding the elements of a slice value
+// or of a comma-separated string (as given by env vars)
+func sliceElements(value any) []any {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() { //nolint:exhaustive // other kinds are a single element
+	case reflect.String:
+		var elems []any
+		for _, part := range strings.Split(v.String(), ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				elems = append(elems, part)
+			}
+		}
+		return elems
+	case reflect.Slice, reflect.Array:
+		elems := make([]any, v.Len())
+		for i := range elems {
+			elems[i] = v.Index(i).Interface()
+		}
+		return elems
+	default:
+		return []any{value}
+	}
+}
+
+// unionElements appends the elements of b missing from a (compared by their
+// printed form, so "8080" from env matches 8080 from a file)
+func unionElements(a, b []any) []any {
+	seen := make(map[string]bool, len(a)+len(b))
+	out := make([]any, 0, len(a)+len(b))
+	for _, elem := range append(a, b...) {
+		key := fmt.Sprint(elem)
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, elem)
+		}
+	}
+	return out
+}
+
+// mapEntries returns a copy of a map value as map[string]any; strings are
+// parsed as "key=value,key=value" (as given by env vars)
+func mapEntries(value any) (map[string]any, error) {
+	if m, ok := value.(map[string]any); ok {
+		return maps.Clone(m), nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() { //nolint:exhaustive // only maps and strings hold entries
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+		}
+		return out, nil
+	case reflect.String:
+		out := make(map[string]any)
+		for _, pair := range strings.Split(v.String(), ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid map entry %q (want key=value)", pair)
+			}
+			out[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("cannot use %T as a map", value)
+	}
+}
+
+// deepMerge overlays src onto dst, merging nested maps key by key
+func deepMerge(dst, src map[string]any) map[string]any {
+	for key, value := range src {
+		sub, isMap := value.(map[string]any)
+		existing, wasMap := dst[key].(map[string]any)
+		if isMap && wasMap {
+			dst[key] = deepMerge(maps.Clone(existing), sub)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// convertToMap converts a map value or "key=value,..." string into targetType,
+// converting keys and values individually
+func (pm *PrecedenceManager) convertToMap(value any, targetType reflect.Type) (any, error) {
+	entries, err := mapEntries(value)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.MakeMapWithSize(targetType, len(entries))
+	for k, v := range entries {
+		key, err := pm.convertValueToType(k, targetType.Key())
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		if v == nil || targetType.Elem().Kind() == reflect.Interface {
+			elem := reflect.Zero(targetType.Elem())
+			if v != nil {
+				elem = reflect.ValueOf(v)
+			}
+			out.SetMapIndex(reflect.ValueOf(key), elem)
+			continue
+		}
+		elem, err := pm.convertValueToType(v, targetType.Elem())
+		if err != nil {
+			return nil, fmt.Errorf("value for %q: %w", k, err)
+		}
+		out.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(elem))
+	}
+	return out.Interface(), nil
+}
@@ -0,0 +1,104 @@
+package snap
+
+// Mount attaches a fully built app as the command name, e.g. a "db" CLI
+// maintained in another package:
+//
+//	app.Mount("db", dbcli.New()) // myapp db migrate --dsn ...
+//
+// The mounted app's commands become subcommands and its positional arguments,
+// action or default wrapper belong to the new command. Its flags, flag groups,
+// Before/After hooks and middleware apply to the whole subtree, as they did in
+// the app, and its DefineError mappings and exit code descriptions are added to
+// this app's unless already defined. App-wide settings such as IO, theme,
+// locale and configuration stay this app's. Mount the app once it is fully
+// built and do not run it on its own afterwards, since the commands are shared.
+func (a *App) Mount(name string, sub *App) *CommandBuilder {
+	builder := a.Command(name, sub.description)
+	builder.mount(sub)
+	return builder
+}
+
+// Mount attaches a fully built app as a subcommand, like App.Mount
+func (c *CommandBuilder) Mount(name string, sub *App) *CommandBuilder {
+	builder := c.Command(name, sub.description)
+	builder.mount(sub)
+	return builder
+}
+
+// mount moves the definition of sub into the builder's command
+func (c *CommandBuilder) mount(sub *App) {
+	cmd := c.command
+	cmd.HelpText = sub.helpText
+	cmd.Action = sub.action
+	cmd.wrapper = sub.defaultWrapper
+	cmd.args = sub.args
+	cmd.hasRestArgs, cmd.restMode = sub.hasRestArgs, sub.restMode
+	cmd.restMin, cmd.restMax = sub.restMin, sub.restMax
+	cmd.argsOrFlag = sub.argsOrFlag
+	cmd.middleware = append(cmd.middleware, sub.middleware...)
+	cmd.inheritMiddleware = true
+	for name, sc := range sub.commands {
+		sc.parent = cmd
+		cmd.subcommands[name] = sc
+	}
+
+	// App-wide parts of sub apply to every command of the subtree, as app
+	// flags are accepted after any command
+	eachCommand(cmd, func(sc *Command) {
+		for _, flag := range sortedValues(sub.flags) {
+			if flag.descriptionID == "" { // Not --help or --version
+				addMountedFlag(sc, flag)
+			}
+		}
+		sc.flagGroups = append(sc.flagGroups, sub.flagGroups...)
+		sc.beforeAction = runBoth(sub.beforeAction, sc.beforeAction, true)
+		sc.afterAction = runBoth(sc.afterAction, sub.afterAction, false)
+	})
+
+	if sub.exitCodes != nil {
+		c.app.ExitCodes().merge(sub.exitCodes)
+	}
+	c.app.invalidateHelp()
+}
+
+// addMountedFlag registers flag on cmd unless cmd defines one of that name;
+// the short form is added when still free
+func addMountedFlag(cmd *Command, flag *Flag) {
+	if _, exists := cmd.flags[flag.Name]; exists {
+		return
+	}
+	cmd.flags[flag.Name] = flag
+	if _, taken := cmd.shortFlags[flag.Short]; flag.Short != 0 && !taken {
+		cmd.shortFlags[flag.Short] = flag
+	}
+}
+
+// eachCommand calls fn for cmd and all its subcommands
+func eachCommand(cmd *Command, fn func(*Command)) {
+	fn(cmd)
+	for _, sc := range cmd.subcommands {
+		eachCommand(sc, fn)
+	}
+}
+
+// runBoth combines two hooks into one running first, then second. With
+// stopOnError an error of first skips second; otherwise both run and the
+// first error is returned.
+func runBoth(first, second ActionFunc, stopOnError bool) ActionFunc {
+	switch {
+	case first == nil:
+		return second
+	case second == nil:
+		return first
+	}
+	return func(ctx *Context) error {
+		err := first(ctx)
+		if err != nil && stopOnError {
+			return err
+		}
+		if secondErr := second(ctx); err == nil {
+			err = secondErr
+		}
+		return err
+	}
+}
@@ -0,0 +1,196 @@
+package snap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dzonerzy/go-snap/internal/pool"
+)
+
+// Override replaces the value of the flag name in the result, e.g. to
+// normalize a path or apply a computed default after parsing. value has the
+// flag's Go type or converts to it like a config value ("9090" or 9090 for an
+// int flag, "a,b" for a string slice); enum values are validated. Later reads
+// return the new value and Source reports ValueSourceOverride.
+func (r *ParseResult) Override(name string, value any) error {
+	flag := r.lookupFlag(name)
+	if flag == nil {
+		return &ParseError{
+			Type:    ErrorTypeUnknownFlag,
+			Message: "unknown flag: --" + name,
+			Flag:    name,
+			msgID:   MsgUnknownFlag,
+			msgArgs: []any{name},
+		}
+	}
+
+	err := errors.New("no value")
+	if goType := flagGoTypes[flag.Type]; goType == nil {
+		err = fmt.Errorf("unsupported flag type %s", flag.Type)
+	} else if value != nil {
+		var typed any
+		typed, err = NewPrecedenceManager().convertValueToType(value, goType)
+		if err == nil {
+			err = r.storeOverride(name, flag, typed)
+		}
+	}
+	if err != nil {
+		return &ParseError{
+			Type:    ErrorTypeInvalidValue,
+			Message: fmt.Sprintf("invalid value %v for --%s: %v", value, name, err),
+			Flag:    name,
+			msgID:   MsgInvalidOverride,
+			msgArgs: []any{value, name, err},
+		}
+	}
+	if r.sources != nil {
+		r.sources[name] = ValueSourceOverride
+	}
+	return nil
+}
+
+// flagGoTypes maps flag types to the Go type of their values (see Flag.Default)
+var flagGoTypes = map[FlagType]reflect.Type{
+	FlagTypeString:        reflect.TypeFor[string](),
+	FlagTypeEnum:          reflect.TypeFor[string](),
+	FlagTypeBool:          reflect.TypeFor[bool](),
+	FlagTypeInt:           reflect.TypeFor[int](),
+	FlagTypeInt64:         reflect.TypeFor[int64](),
+	FlagTypeInt32:         reflect.TypeFor[int64](),
+	FlagTypeUint:          reflect.TypeFor[uint64](),
+	FlagTypeUint64:        reflect.TypeFor[uint64](),
+	FlagTypeDuration:      reflect.TypeFor[time.Duration](),
+	FlagTypeFloat:         reflect.TypeFor[float64](),
+	FlagTypeStringSlice:   reflect.TypeFor[[]string](),
+	FlagTypeIntSlice:      reflect.TypeFor[[]int](),
+	FlagTypeFloatSlice:    reflect.TypeFor[[]float64](),
+	FlagTypeDurationSlice: reflect.TypeFor[[]time.Duration](),
+	FlagTypeEnumSlice:     reflect.TypeFor[[]string](),
+}
+
+// lookupFlag finds the flag name in the scope of the parsed command
+func (r *ParseResult) lookupFlag(name string) *Flag {
+	if r.Command != nil {
+		if flag := r.Command.flags[name]; flag != nil {
+			return flag
+		}
+	}
+	if r.app != nil {
+		return r.app.flags[name]
+	}
+	return nil
+}
+
+// storeOverride stores a value of the flag's Go type in its typed map
+//
+//nolint:gocyclo,cyclop // One case per flag type, as in storeFlagValue.
+func (r *ParseResult) storeOverride(name string, flag *Flag, value any) error {
+	global := flag.IsGlobal()
+	switch flag.Type {
+	case FlagTypeInt:
+		setTyped(r.IntFlags, r.GlobalIntFlags, global, name, value.(int))
+	case FlagTypeInt64, FlagTypeInt32:
+		v := value.(int64)
+		if minVal, maxVal := intBounds(flag.Type); v < minVal || v > maxVal {
+			return fmt.Errorf("value out of range for %s", flag.Type)
+		}
+		setTyped(r.Int64Flags, r.GlobalInt64Flags, global, name, v)
+	case FlagTypeUint, FlagTypeUint64:
+		v := value.(uint64)
+		if v > uintBound(flag.Type) {
+			return fmt.Errorf("value out of range for %s", flag.Type)
+		}
+		setTyped(r.Uint64Flags, r.GlobalUint64Flags, global, name, v)
+	case FlagTypeString:
+		setTyped(r.StringFlags, r.GlobalStringFlags, global, name, value.(string))
+	case FlagTypeBool:
+		setTyped(r.BoolFlags, r.GlobalBoolFlags, global, name, value.(bool))
+	case FlagTypeDuration:
+		setTyped(r.DurationFlags, r.GlobalDurationFlags, global, name, value.(time.Duration))
+	case FlagTypeFloat:
+		setTyped(r.FloatFlags, r.GlobalFloatFlags, global, name, value.(float64))
+	case FlagTypeEnum:
+		canonical, ok := flag.canonicalEnum(value.(string))
+		if !ok {
+			return fmt.Errorf("valid values: %s", strings.Join(flag.EnumValues, ", "))
+		}
+		setTyped(r.EnumFlags, r.GlobalEnumFlags, global, name, canonical)
+	case FlagTypeStringSlice:
+		offsets := pick(r.StringSliceOffsets, r.GlobalStringSliceOffsets, global)
+		replaceSlice(&r.stringSlices, offsets, name, value.([]string), pool.GetStringSlice, pool.PutStringSlice)
+	case FlagTypeIntSlice:
+		offsets := pick(r.IntSliceOffsets, r.GlobalIntSliceOffsets, global)
+		replaceSlice(&r.intSlices, offsets, name, value.([]int), pool.GetIntSlice, pool.PutIntSlice)
+	case FlagTypeFloatSlice:
+		offsets := pick(r.FloatSliceOffsets, r.GlobalFloatSliceOffsets, global)
+		replaceSlice(&r.floatSlices, offsets, name, value.([]float64), pool.GetFloatSlice, pool.PutFloatSlice)
+	case FlagTypeDurationSlice:
+		offsets := pick(r.DurationSliceOffsets, r.GlobalDurationSliceOffsets, global)
+		replaceSlice(&r.durationSlices, offsets, name, value.([]time.Duration), pool.GetDurationSlice,
+			pool.PutDurationSlice)
+	case FlagTypeEnumSlice:
+		values := slices.Clone(value.([]string))
+		for i, v := range values {
+			canonical, ok := flag.canonicalEnum(v)
+			if !ok {
+				return fmt.Errorf("invalid enum value %s, valid values: %s", v, strings.Join(flag.EnumValues, ", "))
+			}
+			values[i] = canonical
+		}
+		offsets := pick(r.EnumSliceOffsets, r.GlobalEnumSliceOffsets, global)
+		replaceSlice(&r.stringSlices, offsets, name, values, pool.GetStringSlice, pool.PutStringSlice)
+	default:
+		return fmt.Errorf("unsupported flag type %s", flag.Type)
+	}
+	return nil
+}
+
+// pick returns the global map for global flags, the command one otherwise
+func pick[M any](local, global M, isGlobal bool) M {
+	if isGlobal {
+		return global
+	}
+	return local
+}
+
+// setTyped stores value for name in the command or global map
+func setTyped[V any](local, global map[string]V, isGlobal bool, name string, value V) {
+	pick(local, global, isGlobal)[name] = value
+}
+
+// replaceSlice stores a pooled copy of values for name, dropping the values
+// stored before (which return to their pool with the result)
+func replaceSlice[T any](
+	store *[]*[]T,
+	offsets map[string]pool.SliceOffset,
+	name string,
+	values []T,
+	get func() *[]T,
+	put func(*[]T),
+) {
+	slice := get()
+	*slice = append(*slice, values...)
+	delete(offsets, name)
+	storeSlice(store, offsets, name, slice, put)
+}
+
+// SetFlag overrides the value of a flag for the rest of the run (see
+// ParseResult.Override), e.g. in a Before hook:
+//
+//	ctx.SetFlag("out", filepath.Clean(ctx.MustString("out", ".")))
+//
+// A configuration bound with ConfigBuilder.FromFlags is resolved again, so
+// the bound struct sees the new value.
+func (c *Context) SetFlag(name string, value any) error {
+	if err := c.Result.Override(name, value); err != nil {
+		return err
+	}
+	if c.App != nil && c.App.configBuilder != nil && c.App.currentResult == c.Result {
+		return c.App.populateConfiguration()
+	}
+	return nil
+}
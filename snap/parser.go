@@ -1,9 +1,12 @@
 package snap
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"reflect"
 	"slices"
 	"strconv"
 	"time"
@@ -31,14 +34,80 @@ const (
 	StateError
 )
 
+// String returns the state name (used in debug traces)
+func (s ParseState) String() string {
+	switch s {
+	case StateInit:
+		return "init"
+	case StateGlobalFlags:
+		return "global-flags"
+	case StateCommand:
+		return "command"
+	case StateCommandFlags:
+		return "command-flags"
+	case StatePositionalArgs:
+		return "positional"
+	case StateComplete:
+		return "complete"
+	case StateError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ValueSource reports where a flag's final value came from
+type ValueSource int
+
+const (
+	ValueSourceNone    ValueSource = iota // Flag not set and no default value
+	ValueSourceDefault                    // Value taken from the flag's default
+	ValueSourceEnv                        // Value taken from an environment variable
+	ValueSourceFlag                       // Value explicitly provided on the command line
+
+	// Value set by the program after parsing (see ParseResult.Override)
+	ValueSourceOverride
+)
+
+// String returns a human-readable name for the source
+func (s ValueSource) String() string {
+	switch s {
+	case ValueSourceNone:
+		return "none"
+	case ValueSourceDefault:
+		return "default"
+	case ValueSourceEnv:
+		return "env"
+	case ValueSourceFlag:
+		return "flag"
+	case ValueSourceOverride:
+		return "override"
+	}
+	return "unknown"
+}
+
 // ParseResult contains the parsed command structure without allocations
 type ParseResult struct {
 	Command           *Command
 	*pool.ParseResult // Embed the pooled ParseResult
 
 	// Slices that need cleanup
-	stringSlices []*[]string
-	intSlices    []*[]int
+	stringSlices   []*[]string
+	intSlices      []*[]int
+	floatSlices    []*[]float64
+	durationSlices []*[]time.Duration
+
+	// Where each declared flag's value came from (flag, env or default)
+	sources map[string]ValueSource
+
+	// Index in Args of the first argument after "--" (-1 when no terminator was given)
+	terminator int
+
+	// Unrecognized flags of a command with CollectUnknownFlags, in order
+	unknownFlags []string
+
+	// App whose flags were parsed (see Override)
+	app *App
 }
 
 // Parser implements zero-allocation argument parsing
@@ -54,6 +123,31 @@ type Parser struct {
 	position   int
 	currentCmd *Command
 	app        *App
+	terminator int // Index in argsBuffer where args after "--" start (-1 if none)
+
+	stdinConsumed bool // A "-" flag value already read stdin during this parse
+
+	// Pre-parse mode (App.PreParse): unknown flags are skipped, unknown
+	// commands become positionals and args/groups are not validated
+	lenient bool
+
+	// Strict mode (ParseStrict): input is untrusted, so names are looked up
+	// without growing the global interner and @file/stdin values are literal
+	strict bool
+	limits ParseLimits
+
+	// Streaming mode (Feed / End): feeding is set while a parse is in
+	// progress; pendingFlag takes the next token as its value
+	feeding     bool
+	pendingFlag *Flag
+	pendingName string
+	lastToken   string
+
+	// Flags in scope needing a pass after defaults, noted by applyDefaults so
+	// parses without them skip the extra walks over the flag maps
+	hasCredentials   bool
+	hasInterpolation bool
+	hasValidators    bool
 
 	// Error tracking (pre-allocated)
 	lastError     error
@@ -96,7 +190,7 @@ func NewParser(app *App) *Parser {
 
 	// Use pooled result instead of pre-allocated one
 	pooledResult := pool.GetParseResult()
-	p.reusableResult = &ParseResult{ParseResult: pooledResult}
+	p.reusableResult = &ParseResult{ParseResult: pooledResult, sources: make(map[string]ValueSource, 16), terminator: -1}
 
 	// Removed: Pre-allocated boxed values approach
 	// Note: String interning is now handled by internal/intern package
@@ -128,8 +222,12 @@ func (p *Parser) Parse(args []string) (*ParseResult, error) {
 		}
 
 		// Parse based on current state and argument format
+		start := p.position
 		if err := p.parseArgument(arg, args); err != nil {
-			return nil, err
+			return nil, p.locateError(err, args)
+		}
+		if p.app != nil && p.app.tracer != nil {
+			p.traceArgument(start, args[start:p.position+1])
 		}
 
 		p.position++
@@ -139,6 +237,27 @@ func (p *Parser) Parse(args []string) (*ParseResult, error) {
 	return p.finalize()
 }
 
+// traceArgument reports the parser state after consuming the tokens starting at index start
+func (p *Parser) traceArgument(start int, tokens []string) {
+	cmd := ""
+	if p.currentCmd != nil {
+		cmd = " cmd=" + p.currentCmd.name
+	}
+	p.app.tracer.printf("parse", "arg[%d] %q -> %s%s", start, p.app.redactArgs(tokens), p.state, cmd)
+}
+
+// locateError records the argument being parsed (the flag's value when one
+// was consumed) on parse errors that do not carry a position yet
+func (p *Parser) locateError(err error, args []string) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) && parseErr.Token == "" {
+		parseErr.Index = p.position
+		parseErr.Token = args[p.position]
+		parseErr.args = args
+	}
+	return err
+}
+
 // parseArgument handles a single argument based on parser state
 //
 //nolint:gocognit,gocyclo,cyclop // This is acceptable because it's a complex function that handles many cases.
@@ -151,14 +270,10 @@ func (p *Parser) parseArgument(arg string, allArgs []string) error {
 		return p.parsePositionalArg(argBytes)
 	}
 
-	// Check if RestArgs is enabled - if so, treat everything as positional
-	hasRestArgs := false
-	if p.currentCmd != nil {
-		hasRestArgs = p.currentCmd.hasRestArgs
-	} else if p.app != nil {
-		hasRestArgs = p.app.hasRestArgs
-	}
-	if hasRestArgs {
+	// With RestArgs, everything is positional unless the mode keeps parsing
+	// flags up to a point (FlagsFirst / StopAtFirstPositional)
+	if p.restArgsStop(argBytes) {
+		p.state = StatePositionalArgs
 		return p.parsePositionalArg(argBytes)
 	}
 
@@ -178,8 +293,11 @@ func (p *Parser) parseArgument(arg string, allArgs []string) error {
 
 		// In dynamic mode, add "--" as a positional argument instead of consuming it
 		if isDynamic {
-			return p.parsePositionalArg(argBytes)
+			if err := p.parsePositionalArg(argBytes); err != nil {
+				return err
+			}
 		}
+		p.terminator = len(p.argsBuffer)
 		return nil
 	}
 
@@ -207,16 +325,22 @@ func (p *Parser) parseArgument(arg string, allArgs []string) error {
 	case p.state == StateInit || p.state == StateGlobalFlags:
 		// Top-level token: treat as a command only if it exists; otherwise,
 		// treat as positional arg if positional args are defined, or if wrapper is configured.
-		name := intern.InternBytes(argBytes)
+		name := p.internName(argBytes)
 		if cmd := p.findCommand(name); cmd != nil {
 			return p.parseCommand(argBytes)
 		}
+		if cmd, err := p.findCommandByPrefix(name); err != nil || cmd != nil {
+			if err != nil {
+				return err
+			}
+			return p.enterCommand(cmd)
+		}
 		// If app has positional args defined or RestArgs, treat as positional
 		if p.app != nil && (len(p.app.args) > 0 || p.app.hasRestArgs) {
 			return p.parsePositionalArg(argBytes)
 		}
 		// If app has a wrapper, treat as positional
-		if p.app != nil && p.app.defaultWrapper != nil {
+		if (p.app != nil && p.app.defaultWrapper != nil) || p.lenient {
 			return p.parsePositionalArg(argBytes)
 		}
 		// Otherwise, it's an unknown command
@@ -226,11 +350,23 @@ func (p *Parser) parseArgument(arg string, allArgs []string) error {
 		// an unknown non-flag token should be treated as an unknown subcommand
 		// to enable suggestions (rather than silently becoming a positional arg).
 		if p.currentCmd != nil && p.currentCmd.subcommands != nil && len(p.currentCmd.subcommands) > 0 {
-			name := intern.InternBytes(argBytes)
+			name := p.internName(argBytes)
 			if _, ok := p.currentCmd.subcommands[name]; ok {
 				return p.parseCommand(argBytes)
 			}
+			if findCommandAlias(p.currentCmd.subcommands, name) != nil {
+				return p.parseCommand(argBytes)
+			}
+			if cmd, err := p.findCommandByPrefix(name); err != nil || cmd != nil {
+				if err != nil {
+					return err
+				}
+				return p.enterCommand(cmd)
+			}
 			// Unknown token while subcommands exist -> surface an error with suggestion
+			if p.lenient {
+				return p.parsePositionalArg(argBytes)
+			}
 			return p.createUnknownCommandError(name)
 		}
 		// No subcommands defined -> treat as positional argument
@@ -263,10 +399,20 @@ func (p *Parser) parseLongFlag(argBytes []byte, allArgs []string) error {
 	}
 
 	// Intern flag name to avoid string allocation
-	flagName := intern.InternBytes(nameBytes)
+	flagName := p.internName(nameBytes)
 
 	// Look up flag definition
 	flagDef := p.findFlag(flagName)
+	if flagDef == nil && p.app != nil && p.app.allowAbbreviations {
+		// Opt-in prefix matching: --verb resolves to --verbose when unambiguous
+		var err error
+		if flagDef, err = p.findFlagByPrefix(flagName); err != nil {
+			return err
+		}
+		if flagDef != nil {
+			flagName = flagDef.Name
+		}
+	}
 	if flagDef == nil {
 		// Wrapper support: forward unknown flags as positional args when enabled
 		if p.currentCmd != nil && p.currentCmd.wrapper != nil && p.currentCmd.wrapper.ForwardUnknown {
@@ -276,8 +422,21 @@ func (p *Parser) parseLongFlag(argBytes []byte, allArgs []string) error {
 		if p.currentCmd == nil && p.app != nil && p.app.defaultWrapper != nil && p.app.defaultWrapper.ForwardUnknown {
 			return p.parsePositionalArg(argBytes)
 		}
+		if p.currentCmd != nil && p.currentCmd.collectUnknown {
+			p.collectUnknownFlag(string(argBytes), !hasValue, allArgs)
+			return nil
+		}
+		if p.lenient {
+			return nil
+		}
 		return p.createUnknownFlagError(flagName)
 	}
+	if err := p.checkFlagEnabled(flagDef); err != nil {
+		return err
+	}
+	if err := p.checkGlobalFlagPosition(flagDef); err != nil {
+		return err
+	}
 
 	// Direct parsing to typed maps to avoid interface{} boxing
 
@@ -285,10 +444,14 @@ func (p *Parser) parseLongFlag(argBytes []byte, allArgs []string) error {
 	if hasValue {
 		return p.storeFlagValue(flagName, flagDef, valueBytes, flagDef.IsGlobal())
 	}
+	if flagDef.optionalValue {
+		// Never takes the next argument: "--profile prod" leaves prod positional
+		return p.storeFlagValue(flagName, flagDef, stringToBytes(flagDef.bareValue), flagDef.IsGlobal())
+	}
 	if flagDef.RequiresValue() {
 		// Value should be next argument - get it and parse directly
 		if p.position+1 >= len(allArgs) {
-			return &ParseError{Type: ErrorTypeInvalidValue, Message: "missing required value"}
+			return p.awaitValue(flagName, flagDef)
 		}
 
 		// Advance position and get next argument
@@ -297,6 +460,9 @@ func (p *Parser) parseLongFlag(argBytes []byte, allArgs []string) error {
 		valueBytes = stringToBytes(nextArg)
 		return p.storeFlagValue(flagName, flagDef, valueBytes, flagDef.IsGlobal())
 	}
+	if flagDef.counter {
+		return p.countFlag(flagName, flagDef)
+	}
 	if flagDef.Type == FlagTypeBool {
 		// Boolean flag without value - defaults to true
 		return p.storeFlagValue(flagName, flagDef, trueBoolBytes, flagDef.IsGlobal())
@@ -306,6 +472,8 @@ func (p *Parser) parseLongFlag(argBytes []byte, allArgs []string) error {
 		Type:    ErrorTypeMissingValue,
 		Message: "flag requires a value: --" + flagName,
 		Flag:    flagName,
+		msgID:   MsgFlagRequiresValue,
+		msgArgs: []any{"--" + flagName},
 	}
 }
 
@@ -333,8 +501,22 @@ parseShort:
 				p.app.defaultWrapper.ForwardUnknown {
 				return p.parsePositionalArg(argBytes)
 			}
+			if p.currentCmd != nil && p.currentCmd.collectUnknown {
+				// The rest of the cluster belongs to the unknown flag
+				p.collectUnknownFlag("-"+string(flagBytes[i:]), i == len(flagBytes)-1, allArgs)
+				return nil
+			}
+			if p.lenient {
+				return nil // skip the rest of the cluster
+			}
 			return p.createUnknownFlagError(flagName)
 		}
+		if err := p.checkFlagEnabled(flagDef); err != nil {
+			return err
+		}
+		if err := p.checkGlobalFlagPosition(flagDef); err != nil {
+			return err
+		}
 
 		// Variables removed since we're using direct typed parsing
 
@@ -346,7 +528,7 @@ parseShort:
 			if i == len(flagBytes)-1 {
 				// Value is next argument - get it and parse directly
 				if p.position+1 >= len(allArgs) {
-					return &ParseError{Type: ErrorTypeInvalidValue, Message: "missing required value"}
+					return p.awaitValue(flagDef.Name, flagDef)
 				}
 
 				// Advance position and get next argument
@@ -366,6 +548,20 @@ parseShort:
 				return err
 			}
 			break parseShort
+		case flagDef.optionalValue:
+			// The rest of the cluster is the value (-pprod); alone it is bare (-p)
+			valueBytes := stringToBytes(flagDef.bareValue)
+			if i < len(flagBytes)-1 {
+				valueBytes = flagBytes[i+1:]
+			}
+			if err := p.storeFlagValue(flagDef.Name, flagDef, valueBytes, flagDef.IsGlobal()); err != nil {
+				return err
+			}
+			break parseShort
+		case flagDef.counter:
+			if err := p.countFlag(flagDef.Name, flagDef); err != nil {
+				return err
+			}
 		case flagDef.Type == FlagTypeBool:
 			// Boolean flag without value - defaults to true
 			err := p.storeFlagValue(flagDef.Name, flagDef, trueBoolBytes, flagDef.IsGlobal())
@@ -378,6 +574,8 @@ parseShort:
 				Type:    ErrorTypeMissingValue,
 				Message: "flag requires a value: -" + flagName,
 				Flag:    flagDef.Name,
+				msgID:   MsgFlagRequiresValue,
+				msgArgs: []any{"-" + flagName},
 			}
 		}
 	}
@@ -387,7 +585,7 @@ parseShort:
 
 // parseCommand identifies and sets the current command
 func (p *Parser) parseCommand(argBytes []byte) error {
-	cmdName := intern.InternBytes(argBytes)
+	cmdName := p.internName(argBytes)
 
 	// Find command in current context
 	cmd := p.findCommand(cmdName)
@@ -395,13 +593,210 @@ func (p *Parser) parseCommand(argBytes []byte) error {
 		return p.createUnknownCommandError(cmdName)
 	}
 
+	return p.enterCommand(cmd)
+}
+
+// checkGlobalFlagPosition rejects app-level global flags after the command name
+// when the app opted into StrictGlobalFlags. Command flags that shadow a global
+// flag are unaffected.
+func (p *Parser) checkGlobalFlagPosition(flag *Flag) error {
+	if p.currentCmd == nil || p.app == nil || !p.app.strictGlobalFlags || !flag.Global {
+		return nil
+	}
+	if p.currentCmd.flags[flag.Name] == flag {
+		return nil
+	}
+	return &ParseError{
+		Type:           ErrorTypeInvalidFlag,
+		Message:        "global flag --" + flag.Name + " must be given before the command",
+		Flag:           flag.Name,
+		CurrentCommand: p.currentCmd,
+		msgID:          MsgGlobalFlagPosition,
+		msgArgs:        []any{flag.Name},
+	}
+}
+
+// createUnavailableCommandError reports a command switched off by EnabledIf
+func (p *Parser) createUnavailableCommandError(cmd *Command) error {
+	return &ParseError{
+		Type:           ErrorTypeUnavailable,
+		Message:        "command " + cmd.name + " is not available",
+		Command:        cmd.name,
+		CurrentCommand: p.currentCmd,
+		msgID:          MsgCommandUnavailable,
+		msgArgs:        []any{cmd.name},
+	}
+}
+
+// checkFlagEnabled rejects flags switched off by EnabledIf
+func (p *Parser) checkFlagEnabled(flag *Flag) error {
+	if flag.isEnabled() {
+		return nil
+	}
+	return &ParseError{
+		Type:           ErrorTypeUnavailable,
+		Message:        "flag --" + flag.Name + " is not available",
+		Flag:           flag.Name,
+		CurrentCommand: p.currentCmd,
+		msgID:          MsgFlagUnavailable,
+		msgArgs:        []any{flag.Name},
+	}
+}
+
+// enterCommand makes cmd the current (most nested) command, rejecting
+// commands switched off by EnabledIf
+func (p *Parser) enterCommand(cmd *Command) error {
+	if !cmd.isEnabled() {
+		return p.createUnavailableCommandError(cmd)
+	}
 	p.currentCmd = cmd
 	p.currentResult.Command = cmd // Update result to point to most nested command
 	p.state = StateCommandFlags
-
 	return nil
 }
 
+// findFlagByPrefix resolves an abbreviated long flag name. It returns nil when
+// nothing matches and an ErrorTypeAmbiguousFlag error when several flags do.
+// Only called after an exact lookup failed, so the hot path stays allocation-free.
+func (p *Parser) findFlagByPrefix(prefix string) (*Flag, error) {
+	if prefix == "" {
+		return nil, nil //nolint:nilnil // nil flag means "no match", not an error
+	}
+	var match *Flag
+	var candidates []string
+	// Command flags are considered first so they shadow global flags of the same name
+	consider := func(flags map[string]*Flag) {
+		for name, flag := range flags {
+			if !matchesFlagPrefix(name, prefix, flag) || slices.Contains(candidates, name) {
+				continue
+			}
+			match = flag
+			candidates = append(candidates, name)
+		}
+	}
+	if p.currentCmd != nil {
+		consider(p.currentCmd.flags)
+	}
+	if p.app != nil {
+		consider(p.app.flags)
+	}
+
+	if len(candidates) > 1 {
+		slices.Sort(candidates)
+		return nil, &ParseError{
+			Type:           ErrorTypeAmbiguousFlag,
+			Message:        "ambiguous flag: --" + prefix,
+			Flag:           prefix,
+			Candidates:     candidates,
+			CurrentCommand: p.currentCmd,
+			msgID:          MsgAmbiguousFlag,
+			msgArgs:        []any{prefix},
+		}
+	}
+	return match, nil
+}
+
+// matchesFlagPrefix reports whether prefix abbreviates the visible flag name
+func matchesFlagPrefix(name, prefix string, flag *Flag) bool {
+	return !flag.isHidden() && len(name) > len(prefix) && name[:len(prefix)] == prefix
+}
+
+// findCommandByPrefix resolves an abbreviated command name against the commands
+// valid at the current position (subcommands inside a command, top-level otherwise).
+// It returns nil when abbreviations are disabled or nothing matches.
+func (p *Parser) findCommandByPrefix(prefix string) (*Command, error) {
+	if p.app == nil || !p.app.allowAbbreviations || prefix == "" {
+		return nil, nil //nolint:nilnil // nil command means "no match", not an error
+	}
+	commands := p.app.commands
+	if p.currentCmd != nil {
+		commands = p.currentCmd.subcommands
+	}
+
+	var match *Command
+	var candidates []string
+	for name, cmd := range commands {
+		if cmd.isHidden() || len(name) <= len(prefix) || name[:len(prefix)] != prefix {
+			continue
+		}
+		match = cmd
+		candidates = append(candidates, name)
+	}
+
+	if len(candidates) > 1 {
+		slices.Sort(candidates)
+		return nil, &ParseError{
+			Type:           ErrorTypeAmbiguousCommand,
+			Message:        "ambiguous command: " + prefix,
+			Command:        prefix,
+			Candidates:     candidates,
+			CurrentCommand: p.currentCmd,
+			msgID:          MsgAmbiguousCommand,
+			msgArgs:        []any{prefix},
+		}
+	}
+	return match, nil
+}
+
+// restArgsMode selects when flag parsing stops for commands with RestArgs
+type restArgsMode uint8
+
+const (
+	restAll                   restArgsMode = iota // Every token is positional (RestArgs)
+	restFlagsFirst                                // Flags parse until a token beyond the declared args
+	restStopAtFirstPositional                     // Flags parse until the first positional
+)
+
+// restArgsStop reports whether a token ends flag parsing under RestArgs
+func (p *Parser) restArgsStop(argBytes []byte) bool {
+	var (
+		enabled  bool
+		mode     restArgsMode
+		declared int
+	)
+	switch {
+	case p.currentCmd != nil:
+		enabled, mode, declared = p.currentCmd.hasRestArgs, p.currentCmd.restMode, len(p.currentCmd.args)
+	case p.app != nil:
+		enabled, mode, declared = p.app.hasRestArgs, p.app.restMode, len(p.app.args)
+	}
+	if !enabled {
+		return false
+	}
+
+	isFlag := len(argBytes) > 1 && argBytes[0] == '-'
+	switch mode {
+	case restStopAtFirstPositional:
+		return !isFlag && !p.isCommandToken(argBytes)
+	case restFlagsFirst:
+		return !isFlag && !p.isCommandToken(argBytes) && len(p.argsBuffer) >= declared
+	case restAll:
+		return true
+	}
+	return true
+}
+
+// isCommandToken reports whether a top-level token names a command, so app
+// level FlagsFirst / StopAtFirstPositional still dispatch to commands
+func (p *Parser) isCommandToken(argBytes []byte) bool {
+	if p.currentCmd != nil || p.app == nil || len(p.app.commands) == 0 {
+		return false
+	}
+	return p.findCommand(p.internName(argBytes)) != nil
+}
+
+// internName returns the interned form of a flag or command name. In strict
+// mode unknown names are copied instead, keeping the interner bounded.
+func (p *Parser) internName(b []byte) string {
+	if !p.strict {
+		return intern.InternBytes(b)
+	}
+	if name, ok := intern.LookupBytes(b); ok {
+		return name
+	}
+	return string(b)
+}
+
 // parsePositionalArg handles positional arguments
 func (p *Parser) parsePositionalArg(argBytes []byte) error {
 	// Convert to string and store (this is where we allocate for final result)
@@ -441,6 +836,11 @@ func (p *Parser) reset() {
 	p.state = StateInit
 	p.position = 0
 	p.currentCmd = nil
+	p.terminator = -1
+	p.stdinConsumed = false
+	p.feeding = false
+	p.pendingFlag, p.pendingName, p.lastToken = nil, "", ""
+	p.hasCredentials, p.hasInterpolation, p.hasValidators = false, false, false
 	p.lastError = nil
 	p.currentResult = nil
 
@@ -499,13 +899,43 @@ func (p *Parser) findCommand(name string) *Command {
 		if subCmd := p.currentCmd.subcommands[name]; subCmd != nil {
 			return subCmd
 		}
+		if subCmd := findCommandAlias(p.currentCmd.subcommands, name); subCmd != nil {
+			return subCmd
+		}
 	}
 
 	// Then check top-level commands
 	if p.app == nil || p.app.commands == nil {
 		return nil
 	}
-	return p.app.commands[name]
+	if cmd := p.app.commands[name]; cmd != nil {
+		return cmd
+	}
+	return findCommandAlias(p.app.commands, name)
+}
+
+// findCommandAlias looks up a command by one of its aliases (only after a name lookup missed)
+func findCommandAlias(commands map[string]*Command, name string) *Command {
+	for _, cmd := range commands {
+		if slices.Contains(cmd.Aliases, name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// countFlag adds one to a counter flag given without a value
+func (p *Parser) countFlag(name string, flag *Flag) error {
+	result := p.currentResult
+	if result == nil {
+		return &ParseError{Type: ErrorTypeInternal, Message: "no result context"}
+	}
+	if flag.IsGlobal() {
+		result.GlobalIntFlags[name]++
+	} else {
+		result.IntFlags[name]++
+	}
+	return nil
 }
 
 // storeFlag stores a parsed flag value in the appropriate result map.
@@ -518,16 +948,21 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 		return &ParseError{Type: ErrorTypeInternal, Message: "no result context"}
 	}
 
+	// Value-from-file/stdin indirection (opt-in per flag, off the zero-alloc path)
+	if flag.AllowFromFile && !p.strict && len(valueBytes) > 0 {
+		resolved, err := p.resolveValueSource(flag, valueBytes)
+		if err != nil {
+			return err
+		}
+		valueBytes = resolved
+	}
+
 	// Parse and store directly in typed maps to avoid interface{} boxing
 	switch flag.Type {
 	case FlagTypeInt:
 		value, err := p.parseIntBytes(valueBytes)
 		if err != nil {
-			return &ParseError{
-				Type:    ErrorTypeInvalidValue,
-				Message: "invalid integer value",
-				Flag:    flag.Name,
-			}
+			return invalidValueError(flag, "integer")
 		}
 		if isGlobal {
 			result.GlobalIntFlags[name] = value
@@ -535,6 +970,29 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 			result.IntFlags[name] = value
 		}
 
+	case FlagTypeInt64, FlagTypeInt32:
+		minVal, maxVal := intBounds(flag.Type)
+		value, err := p.parseSignedBytes(valueBytes, minVal, maxVal)
+		if err != nil {
+			return invalidValueError(flag, string(flag.Type))
+		}
+		if isGlobal {
+			result.GlobalInt64Flags[name] = value
+		} else {
+			result.Int64Flags[name] = value
+		}
+
+	case FlagTypeUint, FlagTypeUint64:
+		value, err := p.parseUnsignedBytes(valueBytes, uintBound(flag.Type))
+		if err != nil {
+			return invalidValueError(flag, string(flag.Type))
+		}
+		if isGlobal {
+			result.GlobalUint64Flags[name] = value
+		} else {
+			result.Uint64Flags[name] = value
+		}
+
 	case FlagTypeString:
 		value := bytesToString(valueBytes)
 		if isGlobal {
@@ -554,11 +1012,7 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 	case FlagTypeDuration:
 		value, err := p.parseDurationBytes(valueBytes)
 		if err != nil {
-			return &ParseError{
-				Type:    ErrorTypeInvalidValue,
-				Message: "invalid duration value",
-				Flag:    flag.Name,
-			}
+			return invalidValueError(flag, "duration")
 		}
 		if isGlobal {
 			result.GlobalDurationFlags[name] = value
@@ -569,11 +1023,7 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 	case FlagTypeFloat:
 		value, err := p.parseFloatBytes(valueBytes)
 		if err != nil {
-			return &ParseError{
-				Type:    ErrorTypeInvalidValue,
-				Message: "invalid float value",
-				Flag:    flag.Name,
-			}
+			return invalidValueError(flag, "float")
 		}
 		if isGlobal {
 			result.GlobalFloatFlags[name] = value
@@ -584,17 +1034,20 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 	case FlagTypeEnum:
 		// Parse enum value with validation
 		value := bytesToString(valueBytes)
-		if !p.isValidEnumValue(flag, value) {
+		canonical, ok := p.canonicalEnumValue(flag, value)
+		if !ok {
 			return &ParseError{
 				Type:    ErrorTypeInvalidValue,
 				Message: "invalid enum value: " + value + ", valid values: " + p.enumValuesString(flag),
 				Flag:    flag.Name,
+				msgID:   MsgInvalidEnumValue,
+				msgArgs: []any{value, p.enumValuesString(flag)},
 			}
 		}
 		if isGlobal {
-			result.GlobalEnumFlags[name] = value
+			result.GlobalEnumFlags[name] = canonical
 		} else {
-			result.EnumFlags[name] = value
+			result.EnumFlags[name] = canonical
 		}
 
 	case FlagTypeStringSlice:
@@ -613,11 +1066,7 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 		// Parse comma-separated integers using pooled slice
 		slice, err := p.parseIntSlice(valueBytes)
 		if err != nil {
-			return &ParseError{
-				Type:    ErrorTypeInvalidValue,
-				Message: "invalid int slice value",
-				Flag:    flag.Name,
-			}
+			return invalidValueError(flag, "int slice")
 		}
 		// Store slice for cleanup and create offset
 		result.intSlices = append(result.intSlices, slice)
@@ -628,6 +1077,51 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 			result.IntSliceOffsets[name] = offset
 		}
 
+	case FlagTypeFloatSlice:
+		slice, err := p.parseFloatSlice(valueBytes)
+		if err != nil {
+			return invalidValueError(flag, "float slice")
+		}
+		if isGlobal {
+			storeSlice(&result.floatSlices, result.GlobalFloatSliceOffsets, name, slice, pool.PutFloatSlice)
+		} else {
+			storeSlice(&result.floatSlices, result.FloatSliceOffsets, name, slice, pool.PutFloatSlice)
+		}
+
+	case FlagTypeDurationSlice:
+		slice, err := p.parseDurationSlice(valueBytes)
+		if err != nil {
+			return invalidValueError(flag, "duration slice")
+		}
+		if isGlobal {
+			storeSlice(&result.durationSlices, result.GlobalDurationSliceOffsets, name, slice, pool.PutDurationSlice)
+		} else {
+			storeSlice(&result.durationSlices, result.DurationSliceOffsets, name, slice, pool.PutDurationSlice)
+		}
+
+	case FlagTypeEnumSlice:
+		// Every element must be one of the declared values
+		slice := p.parseStringSlice(valueBytes)
+		for i, value := range *slice {
+			canonical, ok := p.canonicalEnumValue(flag, value)
+			if !ok {
+				pool.PutStringSlice(slice)
+				return &ParseError{
+					Type:    ErrorTypeInvalidValue,
+					Message: "invalid enum value: " + value + ", valid values: " + p.enumValuesString(flag),
+					Flag:    flag.Name,
+					msgID:   MsgInvalidEnumValue,
+					msgArgs: []any{value, p.enumValuesString(flag)},
+				}
+			}
+			(*slice)[i] = canonical
+		}
+		if isGlobal {
+			storeSlice(&result.stringSlices, result.GlobalEnumSliceOffsets, name, slice, pool.PutStringSlice)
+		} else {
+			storeSlice(&result.stringSlices, result.EnumSliceOffsets, name, slice, pool.PutStringSlice)
+		}
+
 	default:
 		// Unknown flag type - return error
 		return &ParseError{
@@ -640,6 +1134,118 @@ func (p *Parser) storeFlagValue(name string, flag *Flag, valueBytes []byte, isGl
 	return nil
 }
 
+// invalidValueError reports a flag value that does not parse as typeName
+func invalidValueError(flag *Flag, typeName string) error {
+	return &ParseError{
+		Type:    ErrorTypeInvalidValue,
+		Message: "invalid " + typeName + " value",
+		Flag:    flag.Name,
+		msgID:   MsgInvalidValue,
+		msgArgs: []any{typeName},
+	}
+}
+
+// invalidArgValueError reports a positional argument value that does not
+// parse as typeName; id selects the plain or variadic wording
+func invalidArgValueError(id MessageID, typeName string, argDef *Arg, value string) error {
+	args := []any{typeName, argDef.Name, value}
+	return &ParseError{
+		Type:    ErrorTypeInvalidArgument,
+		Message: fmt.Sprintf(builtinMessages[defaultLocale][id], args...),
+		msgID:   id,
+		msgArgs: args,
+	}
+}
+
+// resolveValueSource expands "@path" to the file's contents and "-" to stdin
+// for flags declared with AllowFromFile. "@@x" escapes a literal "@x".
+func (p *Parser) resolveValueSource(flag *Flag, value []byte) ([]byte, error) {
+	var data []byte
+	var err error
+	switch {
+	case len(value) > 1 && value[0] == '@' && value[1] == '@':
+		return value[1:], nil
+	case value[0] == '@':
+		data, err = os.ReadFile(string(value[1:]))
+	case len(value) == 1 && value[0] == '-':
+		data, err = p.readStdinValue()
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return nil, &ParseError{
+			Type:    ErrorTypeInvalidValue,
+			Message: "cannot read value for --" + flag.Name + ": " + err.Error(),
+			Flag:    flag.Name,
+			msgID:   MsgFlagValueUnreadable,
+			msgArgs: []any{flag.Name, err},
+		}
+	}
+
+	// Drop a single trailing newline (files and echo output usually end with one)
+	if n := len(data); n > 0 && data[n-1] == '\n' {
+		data = data[:n-1]
+		if n > 1 && data[n-2] == '\r' {
+			data = data[:n-2]
+		}
+	}
+	return data, nil
+}
+
+// readStdinValue reads the whole of stdin for a "-" flag value. Stdin can be
+// consumed only once per parse, and an interactive terminal is refused rather
+// than blocking on input that was never piped.
+func (p *Parser) readStdinValue() ([]byte, error) {
+	if p.stdinConsumed {
+		return nil, errors.New("stdin already consumed by another flag")
+	}
+	var in io.Reader = os.Stdin
+	if p.app != nil {
+		in = p.app.IO().In()
+	}
+	if f, ok := in.(*os.File); ok && f == os.Stdin && p.app != nil && !p.app.IO().IsPiped() {
+		return nil, errors.New("nothing piped to stdin")
+	}
+	p.stdinConsumed = true
+	return io.ReadAll(in)
+}
+
+// storeSlice records a pooled slice value for name. When the flag was already
+// provided (e.g. --feature a --feature b) the new values are appended to the
+// existing slice and the new pooled slice is returned to its pool.
+func storeSlice[T any](
+	store *[]*[]T,
+	offsets map[string]pool.SliceOffset,
+	name string,
+	slice *[]T,
+	put func(*[]T),
+) {
+	if offset, exists := offsets[name]; exists && offset.Start >= 0 && offset.Start < len(*store) {
+		if existing := (*store)[offset.Start]; existing != nil {
+			*existing = append(*existing, *slice...)
+			put(slice)
+			return
+		}
+	}
+	*store = append(*store, slice)
+	offsets[name] = pool.SliceOffset{Start: len(*store) - 1, End: len(*store)}
+}
+
+// collectUnknownFlag records an unrecognized flag token for
+// Context.UnknownFlags. When the token carries no value, the next argument
+// is taken as its value unless it looks like a flag.
+func (p *Parser) collectUnknownFlag(token string, mayTakeValue bool, allArgs []string) {
+	result := p.currentResult
+	result.unknownFlags = append(result.unknownFlags, token)
+	if !mayTakeValue || p.position+1 >= len(allArgs) {
+		return
+	}
+	if next := allArgs[p.position+1]; next != "" && next[0] != '-' {
+		p.position++
+		result.unknownFlags = append(result.unknownFlags, next)
+	}
+}
+
 // createUnknownFlagError creates an error with smart suggestions for unknown flags.
 // Uses Levenshtein distance to find the closest matching flag name.
 func (p *Parser) createUnknownFlagError(name string) error {
@@ -657,6 +1263,9 @@ func (p *Parser) createUnknownFlagError(name string) error {
 	p.reusableError.Flag = name
 	p.reusableError.Suggestion = suggestion
 	p.reusableError.CurrentCommand = p.currentCmd
+	p.reusableError.msgID = MsgUnknownFlag
+	p.reusableError.msgArgs = []any{name}
+	p.reusableError.Index, p.reusableError.Token, p.reusableError.args = 0, "", nil
 	return p.reusableError
 }
 
@@ -677,6 +1286,9 @@ func (p *Parser) createUnknownCommandError(name string) error {
 	p.reusableError.Command = name
 	p.reusableError.Suggestion = suggestion
 	p.reusableError.CurrentCommand = p.currentCmd
+	p.reusableError.msgID = MsgUnknownCommand
+	p.reusableError.msgArgs = []any{name}
+	p.reusableError.Index, p.reusableError.Token, p.reusableError.args = 0, "", nil
 	return p.reusableError
 }
 
@@ -696,6 +1308,15 @@ func (p *Parser) finalize() (*ParseResult, error) {
 
 	result := p.currentResult
 	result.Command = p.currentCmd
+	result.terminator = p.terminator
+	result.app = p.app
+
+	// Pre-parse stops at the command path and flags; nothing is validated
+	if p.lenient {
+		result.Args = append(result.Args[:0], p.argsBuffer...)
+		p.applyDefaults(result)
+		return result, nil
+	}
 
 	// Process positional arguments
 	if err := p.processPositionalArgs(result); err != nil {
@@ -705,13 +1326,34 @@ func (p *Parser) finalize() (*ParseResult, error) {
 	// Apply default values for flags that weren't provided
 	p.applyDefaults(result)
 
-	// Validate flag groups
-	if err := p.validateFlagGroups(result); err != nil {
+	// Read credentials from files, the environment or a keyring (see CredentialFlag)
+	if err := p.resolveCredentials(result); err != nil {
 		return nil, err
 	}
 
-	return result, nil
-}
+	// Expand references to other flags (see Interpolate)
+	if err := p.interpolateFlags(result); err != nil {
+		return nil, err
+	}
+
+	// Run Validate functions on values given on the command line or in the environment
+	if err := p.validateFlagValues(result); err != nil {
+		return nil, err
+	}
+
+	// Validate flag groups (not while help is requested, so "cmd --help"
+	// works for commands with ExactlyOne or AtLeastOne groups)
+	if !result.helpRequested() {
+		if err := p.validateArgConstraints(result); err != nil {
+			return nil, err
+		}
+		if err := p.validateFlagGroups(result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
 
 // processPositionalArgs processes positional arguments after flag parsing is complete.
 // This handles: type conversion, required validation, variadic args, RestArgs, and defaults.
@@ -722,13 +1364,16 @@ func (p *Parser) processPositionalArgs(result *ParseResult) error {
 	// Get the argument definitions for the current context
 	var args []*Arg
 	var hasRestArgs bool
+	var restMin, restMax int
 
 	if result.Command != nil {
 		args = result.Command.args
 		hasRestArgs = result.Command.hasRestArgs
+		restMin, restMax = result.Command.restMin, result.Command.restMax
 	} else if p.app != nil {
 		args = p.app.args
 		hasRestArgs = p.app.hasRestArgs
+		restMin, restMax = p.app.restMin, p.app.restMax
 	}
 
 	// Fast path: no args defined and no RestArgs
@@ -739,16 +1384,15 @@ func (p *Parser) processPositionalArgs(result *ParseResult) error {
 	}
 
 	// Check if help flag is set - skip required validation if help is requested
-	helpRequested := result.MustGetBool("help", false)
-	if !helpRequested {
-		// Also check global help flag
-		helpRequested = result.MustGetGlobalBool("help", false)
-	}
+	helpRequested := result.helpRequested()
 
-	// Check for RestArgs mode: collect all remaining args
-	if hasRestArgs {
+	// Check for RestArgs mode without declared args: collect everything
+	if hasRestArgs && len(args) == 0 {
 		result.RestArgs = append(result.RestArgs[:0], p.argsBuffer...)
 		result.Args = append(result.Args[:0], p.argsBuffer...)
+		if !helpRequested {
+			return checkRestCount(restMin, restMax, len(result.RestArgs))
+		}
 		return nil
 	}
 
@@ -785,6 +1429,14 @@ func (p *Parser) processPositionalArgs(result *ParseResult) error {
 				return &ParseError{
 					Type:    ErrorTypeInvalidArgument,
 					Message: "missing required variadic argument: " + argDef.Name,
+					msgID:   MsgMissingVariadicArgument,
+					msgArgs: []any{argDef.Name},
+				}
+			}
+
+			if !helpRequested {
+				if err := checkVariadicCount(argDef, len(remaining)); err != nil {
+					return err
 				}
 			}
 
@@ -804,6 +1456,8 @@ func (p *Parser) processPositionalArgs(result *ParseResult) error {
 				return &ParseError{
 					Type:    ErrorTypeInvalidArgument,
 					Message: "missing required argument: " + argDef.Name,
+					msgID:   MsgMissingArgument,
+					msgArgs: []any{argDef.Name},
 				}
 			}
 			// Apply default for optional arg
@@ -823,6 +1477,16 @@ func (p *Parser) processPositionalArgs(result *ParseResult) error {
 		}
 	}
 
+	// RestArgs collects whatever the declared args left over
+	if hasRestArgs {
+		result.RestArgs = append(result.RestArgs[:0], p.argsBuffer[argIndex:]...)
+		if !helpRequested {
+			if err := checkRestCount(restMin, restMax, len(result.RestArgs)); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Store raw args for ctx.Arg(index) access (zero-alloc copy)
 	result.Args = append(result.Args[:0], p.argsBuffer...)
 
@@ -834,15 +1498,15 @@ func (p *Parser) processPositionalArgs(result *ParseResult) error {
 func (p *Parser) storeArgValue(result *ParseResult, argDef *Arg, value string) error {
 	switch argDef.Type {
 	case ArgTypeString:
+		if err := argDef.checkValue(value); err != nil {
+			return err
+		}
 		result.ArgStrings[argDef.Name] = value
 
 	case ArgTypeInt:
 		intValue, err := p.parseIntBytes(stringToBytes(value))
 		if err != nil {
-			return &ParseError{
-				Type:    ErrorTypeInvalidArgument,
-				Message: "invalid integer value for argument '" + argDef.Name + "': " + value,
-			}
+			return invalidArgValueError(MsgInvalidArgValue, "integer", argDef, value)
 		}
 		result.ArgInts[argDef.Name] = intValue
 
@@ -853,20 +1517,14 @@ func (p *Parser) storeArgValue(result *ParseResult, argDef *Arg, value string) e
 	case ArgTypeDuration:
 		durationValue, err := p.parseDurationBytes(stringToBytes(value))
 		if err != nil {
-			return &ParseError{
-				Type:    ErrorTypeInvalidArgument,
-				Message: "invalid duration value for argument '" + argDef.Name + "': " + value,
-			}
+			return invalidArgValueError(MsgInvalidArgValue, "duration", argDef, value)
 		}
 		result.ArgDurations[argDef.Name] = durationValue
 
 	case ArgTypeFloat:
 		floatValue, err := p.parseFloatBytes(stringToBytes(value))
 		if err != nil {
-			return &ParseError{
-				Type:    ErrorTypeInvalidArgument,
-				Message: "invalid float value for argument '" + argDef.Name + "': " + value,
-			}
+			return invalidArgValueError(MsgInvalidArgValue, "float", argDef, value)
 		}
 		result.ArgFloats[argDef.Name] = floatValue
 
@@ -907,10 +1565,7 @@ func (p *Parser) processVariadicArg(result *ParseResult, argDef *Arg, values []s
 		for _, valueStr := range values {
 			intValue, err := p.parseIntBytes(stringToBytes(valueStr))
 			if err != nil {
-				return &ParseError{
-					Type:    ErrorTypeInvalidArgument,
-					Message: "invalid integer value in variadic argument '" + argDef.Name + "': " + valueStr,
-				}
+				return invalidArgValueError(MsgInvalidVariadicArgValue, "integer", argDef, valueStr)
 			}
 			*slice = append(*slice, intValue)
 		}
@@ -986,233 +1641,548 @@ func (p *Parser) applyArgDefault(result *ParseResult, argDef *Arg) error {
 	return nil
 }
 
-// applyDefaults applies default values for flags that weren't explicitly provided
+// applyDefaults applies default values for flags that weren't explicitly
+// provided and notes credential and interpolated flags in scope. Disabled
+// flags (EnabledIf, experiments) take no environment or default value.
 func (p *Parser) applyDefaults(result *ParseResult) {
 	// Apply defaults for app-level flags
 	for name, flag := range p.app.flags {
-		if flag.Global {
-			p.applyGlobalDefault(result, name, flag)
-		} else {
-			p.applyFlagDefault(result, name, flag)
+		p.noteFlag(flag)
+		switch {
+		case !flag.isEnabled():
+		case flag.Global:
+			recordSource(result, name, p.applyGlobalDefault(result, name, p.lazyDefault(result, name, flag)))
+		default:
+			recordSource(result, name, p.applyFlagDefault(result, name, p.lazyDefault(result, name, flag)))
 		}
 	}
 
-	// Apply defaults for command-specific flags if we have a command
+	// Apply defaults for command-specific flags if we have a command; global
+	// ones come from a mounted app (see Mount)
 	if result.Command != nil {
 		for name, flag := range result.Command.flags {
-			if !flag.Global {
-				p.applyFlagDefault(result, name, flag)
+			p.noteFlag(flag)
+			switch {
+			case !flag.isEnabled():
+			case !flag.Global:
+				recordSource(result, name, p.applyFlagDefault(result, name, p.lazyDefault(result, name, flag)))
+			case p.app.flags[name] != flag:
+				recordSource(result, name, p.applyGlobalDefault(result, name, p.lazyDefault(result, name, flag)))
 			}
 		}
 	}
 }
 
-// applyFlagDefault applies environment variable or default value for a regular flag if not already set
+// noteFlag records whether flag needs the credential, interpolation or
+// validation pass
+func (p *Parser) noteFlag(flag *Flag) {
+	p.hasCredentials = p.hasCredentials || flag.credential
+	p.hasInterpolation = p.hasInterpolation || flag.interpolate
+	p.hasValidators = p.hasValidators || flag.Validator != nil
+}
+
+// validateFlagValues runs the Validate functions of the flags in scope whose
+// value came from the command line or the environment; defaults are trusted
+func (p *Parser) validateFlagValues(result *ParseResult) error {
+	if !p.hasValidators {
+		return nil
+	}
+	var cmdFlags map[string]*Flag
+	if result.Command != nil {
+		cmdFlags = result.Command.flags
+	}
+	for i, flags := range [2]map[string]*Flag{cmdFlags, p.app.flags} {
+		for _, name := range sortedKeys(flags) {
+			flag := flags[name]
+			if flag.Validator == nil || (i == 1 && cmdFlags[name] != nil) {
+				continue // Command flags were checked first
+			}
+			if src := result.Source(name); src != ValueSourceFlag && src != ValueSourceEnv {
+				continue
+			}
+			if err := callValidator(flag.Validator, result, name); err != nil {
+				return &ParseError{
+					Type:    ErrorTypeInvalidValue,
+					Message: "invalid value for --" + name + ": " + err.Error(),
+					Flag:    name,
+					msgID:   MsgFlagValidation,
+					msgArgs: []any{name, err},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// callValidator calls fn, a func(T) error stored by FlagBuilder.Validate, with
+// the value of the flag name; sized integers are stored widened and converted
+// back to T here
+func callValidator(fn any, result *ParseResult, name string) error {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.Type().NumIn() != 1 || fv.Type().NumOut() != 1 {
+		return nil
+	}
+	for _, lookup := range flagValueLookups {
+		value, ok := lookup(result, name)
+		if !ok {
+			continue
+		}
+		in := reflect.ValueOf(value)
+		if want := fv.Type().In(0); in.Type() != want {
+			if !in.Type().ConvertibleTo(want) {
+				return nil
+			}
+			in = in.Convert(want)
+		}
+		err, _ := fv.Call([]reflect.Value{in})[0].Interface().(error)
+		return err
+	}
+	return nil
+}
+
+// helpRequested reports whether the command or global help flag is set
+func (r *ParseResult) helpRequested() bool {
+	return r.MustGetBool("help", false) || r.MustGetGlobalBool("help", false)
+}
+
+// lazyDefault returns a copy of flag with its DefaultFunc evaluated, or flag
+// itself when no computed default is needed (set explicitly or via env)
+func (p *Parser) lazyDefault(result *ParseResult, name string, flag *Flag) *Flag {
+	if flag.defaultFunc == nil {
+		return flag
+	}
+	explicit := result.HasFlag(name)
+	if flag.Global {
+		explicit = result.HasGlobalFlag(name)
+	}
+	if explicit || p.getEnvValue(flag.EnvVars) != "" {
+		return flag
+	}
+	resolved := *flag
+	flag.defaultFunc(&resolved)
+	return &resolved
+}
+
+// recordSource remembers a value taken from the environment or a default;
+// Source derives the other sources from the parsed values
+func recordSource(result *ParseResult, name string, src ValueSource) {
+	if result.sources != nil && (src == ValueSourceEnv || src == ValueSourceDefault) {
+		result.sources[name] = src
+	}
+}
+
+// parseEnumSliceEnv splits an environment value for an enum slice flag,
+// normalizing each element; it fails unless every element is allowed
+func (p *Parser) parseEnumSliceEnv(flag *Flag, envValue string) (*[]string, bool) {
+	slice := p.parseStringSlice([]byte(envValue))
+	for i, value := range *slice {
+		canonical, ok := p.canonicalEnumValue(flag, value)
+		if !ok {
+			pool.PutStringSlice(slice)
+			return nil, false
+		}
+		(*slice)[i] = canonical
+	}
+	return slice, true
+}
+
+// applyFlagDefault applies environment variable or default value for a regular
+// flag if not already set and returns where the value came from. An environment
+// value that does not parse is ignored, so the default applies.
 //
-//nolint:dupl,gocognit,gocyclo,cyclop // Similar to applyGlobalDefault but for non-global flags
-func (p *Parser) applyFlagDefault(result *ParseResult, name string, flag *Flag) {
+//nolint:dupl,gocognit,gocyclo,cyclop,funlen // Similar to applyGlobalDefault but for non-global flags
+func (p *Parser) applyFlagDefault(result *ParseResult, name string, flag *Flag) ValueSource {
 	switch flag.Type {
 	case FlagTypeString:
-		if _, exists := result.StringFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				result.StringFlags[name] = envValue
-			} else if flag.DefaultString != "" {
-				result.StringFlags[name] = flag.DefaultString
-			}
+		if _, exists := result.StringFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		// Check environment variables first (precedence order)
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			result.StringFlags[name] = envValue
+			return ValueSourceEnv
+		}
+		if flag.DefaultString != "" {
+			result.StringFlags[name] = flag.DefaultString
+			return ValueSourceDefault
 		}
 	case FlagTypeInt:
-		if _, exists := result.IntFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				if intValue, err := p.parseIntValue(envValue); err == nil {
-					result.IntFlags[name] = intValue
-				}
-			} else if flag.DefaultInt != 0 {
-				result.IntFlags[name] = flag.DefaultInt
+		if _, exists := result.IntFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if intValue, err := p.parseIntValue(envValue); err == nil {
+				result.IntFlags[name] = intValue
+				return ValueSourceEnv
 			}
 		}
-	case FlagTypeBool:
-		if _, exists := result.BoolFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				boolValue := p.parseBoolValue(envValue)
-				result.BoolFlags[name] = boolValue
-			} else {
-				result.BoolFlags[name] = flag.DefaultBool
+		if flag.DefaultInt != 0 {
+			result.IntFlags[name] = flag.DefaultInt
+			return ValueSourceDefault
+		}
+	case FlagTypeInt64, FlagTypeInt32:
+		if _, exists := result.Int64Flags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			minVal, maxVal := intBounds(flag.Type)
+			if value, err := p.parseSignedBytes(stringToBytes(envValue), minVal, maxVal); err == nil {
+				result.Int64Flags[name] = value
+				return ValueSourceEnv
+			}
+		}
+		if flag.DefaultInt64 != 0 {
+			result.Int64Flags[name] = flag.DefaultInt64
+			return ValueSourceDefault
+		}
+	case FlagTypeUint, FlagTypeUint64:
+		if _, exists := result.Uint64Flags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if value, err := p.parseUnsignedBytes(stringToBytes(envValue), uintBound(flag.Type)); err == nil {
+				result.Uint64Flags[name] = value
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultUint64 != 0 {
+			result.Uint64Flags[name] = flag.DefaultUint64
+			return ValueSourceDefault
+		}
+	case FlagTypeBool:
+		if _, exists := result.BoolFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			result.BoolFlags[name] = p.parseBoolValue(envValue)
+			return ValueSourceEnv
+		}
+		result.BoolFlags[name] = flag.DefaultBool
+		return ValueSourceDefault
 	case FlagTypeDuration:
-		if _, exists := result.DurationFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				if durationValue, err := p.parseDurationValue(envValue); err == nil {
-					result.DurationFlags[name] = durationValue
-				}
-			} else if flag.DefaultDuration != 0 {
-				result.DurationFlags[name] = flag.DefaultDuration
+		if _, exists := result.DurationFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if durationValue, err := p.parseDurationValue(envValue); err == nil {
+				result.DurationFlags[name] = durationValue
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultDuration != 0 {
+			result.DurationFlags[name] = flag.DefaultDuration
+			return ValueSourceDefault
+		}
 	case FlagTypeFloat:
-		if _, exists := result.FloatFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				if floatValue, err := p.parseFloatValue(envValue); err == nil {
-					result.FloatFlags[name] = floatValue
-				}
-			} else if flag.DefaultFloat != 0.0 {
-				result.FloatFlags[name] = flag.DefaultFloat
+		if _, exists := result.FloatFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if floatValue, err := p.parseFloatValue(envValue); err == nil {
+				result.FloatFlags[name] = floatValue
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultFloat != 0.0 {
+			result.FloatFlags[name] = flag.DefaultFloat
+			return ValueSourceDefault
+		}
 	case FlagTypeEnum:
-		if _, exists := result.EnumFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				// Validate enum value
-				if p.isValidEnumValue(flag, envValue) {
-					result.EnumFlags[name] = envValue
-				}
-			} else if flag.DefaultEnum != "" {
-				result.EnumFlags[name] = flag.DefaultEnum
+		if _, exists := result.EnumFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			// Validate and normalize enum value
+			if value, ok := p.canonicalEnumValue(flag, envValue); ok {
+				result.EnumFlags[name] = value
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultEnum != "" {
+			result.EnumFlags[name] = flag.DefaultEnum
+			return ValueSourceDefault
+		}
 	case FlagTypeStringSlice:
-		if _, exists := result.StringSliceOffsets[name]; !exists {
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				slice := p.parseStringSlice([]byte(envValue))
-				result.stringSlices = append(result.stringSlices, slice)
-				offset := pool.SliceOffset{Start: len(result.stringSlices) - 1, End: len(result.stringSlices)}
-				result.StringSliceOffsets[name] = offset
-			} else if len(flag.DefaultStringSlice) > 0 {
-				slice := pool.GetStringSlice()
-				*slice = append(*slice, flag.DefaultStringSlice...)
-				result.stringSlices = append(result.stringSlices, slice)
-				offset := pool.SliceOffset{Start: len(result.stringSlices) - 1, End: len(result.stringSlices)}
-				result.StringSliceOffsets[name] = offset
-			}
+		if _, exists := result.StringSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			slice := p.parseStringSlice([]byte(envValue))
+			storeSlice(&result.stringSlices, result.StringSliceOffsets, name, slice, pool.PutStringSlice)
+			return ValueSourceEnv
+		}
+		if len(flag.DefaultStringSlice) > 0 {
+			slice := pool.GetStringSlice()
+			*slice = append(*slice, flag.DefaultStringSlice...)
+			storeSlice(&result.stringSlices, result.StringSliceOffsets, name, slice, pool.PutStringSlice)
+			return ValueSourceDefault
 		}
 	case FlagTypeIntSlice:
-		if _, exists := result.IntSliceOffsets[name]; !exists {
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				slice, err := p.parseIntSlice([]byte(envValue))
-				if err == nil {
-					result.intSlices = append(result.intSlices, slice)
-					offset := pool.SliceOffset{Start: len(result.intSlices) - 1, End: len(result.intSlices)}
-					result.IntSliceOffsets[name] = offset
-				}
-			} else if len(flag.DefaultIntSlice) > 0 {
-				slice := pool.GetIntSlice()
-				*slice = append(*slice, flag.DefaultIntSlice...)
-				result.intSlices = append(result.intSlices, slice)
-				offset := pool.SliceOffset{Start: len(result.intSlices) - 1, End: len(result.intSlices)}
-				result.IntSliceOffsets[name] = offset
+		if _, exists := result.IntSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if slice, err := p.parseIntSlice([]byte(envValue)); err == nil {
+				storeSlice(&result.intSlices, result.IntSliceOffsets, name, slice, pool.PutIntSlice)
+				return ValueSourceEnv
 			}
 		}
+		if len(flag.DefaultIntSlice) > 0 {
+			slice := pool.GetIntSlice()
+			*slice = append(*slice, flag.DefaultIntSlice...)
+			storeSlice(&result.intSlices, result.IntSliceOffsets, name, slice, pool.PutIntSlice)
+			return ValueSourceDefault
+		}
+	case FlagTypeFloatSlice:
+		if _, exists := result.FloatSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if slice, err := p.parseFloatSlice([]byte(envValue)); err == nil {
+				storeSlice(&result.floatSlices, result.FloatSliceOffsets, name, slice, pool.PutFloatSlice)
+				return ValueSourceEnv
+			}
+		}
+		if len(flag.DefaultFloatSlice) > 0 {
+			slice := pool.GetFloatSlice()
+			*slice = append(*slice, flag.DefaultFloatSlice...)
+			storeSlice(&result.floatSlices, result.FloatSliceOffsets, name, slice, pool.PutFloatSlice)
+			return ValueSourceDefault
+		}
+	case FlagTypeDurationSlice:
+		if _, exists := result.DurationSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if slice, err := p.parseDurationSlice([]byte(envValue)); err == nil {
+				storeSlice(&result.durationSlices, result.DurationSliceOffsets, name, slice, pool.PutDurationSlice)
+				return ValueSourceEnv
+			}
+		}
+		if len(flag.DefaultDurationSlice) > 0 {
+			slice := pool.GetDurationSlice()
+			*slice = append(*slice, flag.DefaultDurationSlice...)
+			storeSlice(&result.durationSlices, result.DurationSliceOffsets, name, slice, pool.PutDurationSlice)
+			return ValueSourceDefault
+		}
+	case FlagTypeEnumSlice:
+		if _, exists := result.EnumSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			// Only accept env values when every element is valid
+			if slice, ok := p.parseEnumSliceEnv(flag, envValue); ok {
+				storeSlice(&result.stringSlices, result.EnumSliceOffsets, name, slice, pool.PutStringSlice)
+				return ValueSourceEnv
+			}
+		}
+		if len(flag.DefaultEnumSlice) > 0 {
+			slice := pool.GetStringSlice()
+			*slice = append(*slice, flag.DefaultEnumSlice...)
+			storeSlice(&result.stringSlices, result.EnumSliceOffsets, name, slice, pool.PutStringSlice)
+			return ValueSourceDefault
+		}
 	}
+	return ValueSourceNone
 }
 
-// applyGlobalDefault applies environment variable or default value for a global flag if not already set
+// applyGlobalDefault applies environment variable or default value for a global
+// flag if not already set and returns where the value came from
 //
-//nolint:dupl,gocognit,gocyclo,cyclop // Similar to applyFlagDefault but for global flags
-func (p *Parser) applyGlobalDefault(result *ParseResult, name string, flag *Flag) {
+//nolint:dupl,gocognit,gocyclo,cyclop,funlen // Similar to applyFlagDefault but for global flags
+func (p *Parser) applyGlobalDefault(result *ParseResult, name string, flag *Flag) ValueSource {
 	switch flag.Type {
 	case FlagTypeString:
-		if _, exists := result.GlobalStringFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				result.GlobalStringFlags[name] = envValue
-			} else if flag.DefaultString != "" {
-				result.GlobalStringFlags[name] = flag.DefaultString
-			}
+		if _, exists := result.GlobalStringFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		// Check environment variables first (precedence order)
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			result.GlobalStringFlags[name] = envValue
+			return ValueSourceEnv
+		}
+		if flag.DefaultString != "" {
+			result.GlobalStringFlags[name] = flag.DefaultString
+			return ValueSourceDefault
 		}
 	case FlagTypeInt:
-		if _, exists := result.GlobalIntFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				if intValue, err := p.parseIntValue(envValue); err == nil {
-					result.GlobalIntFlags[name] = intValue
-				}
-			} else if flag.DefaultInt != 0 {
-				result.GlobalIntFlags[name] = flag.DefaultInt
+		if _, exists := result.GlobalIntFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if intValue, err := p.parseIntValue(envValue); err == nil {
+				result.GlobalIntFlags[name] = intValue
+				return ValueSourceEnv
 			}
 		}
-	case FlagTypeBool:
-		if _, exists := result.GlobalBoolFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				boolValue := p.parseBoolValue(envValue)
-				result.GlobalBoolFlags[name] = boolValue
-			} else {
-				result.GlobalBoolFlags[name] = flag.DefaultBool
+		if flag.DefaultInt != 0 {
+			result.GlobalIntFlags[name] = flag.DefaultInt
+			return ValueSourceDefault
+		}
+	case FlagTypeInt64, FlagTypeInt32:
+		if _, exists := result.GlobalInt64Flags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			minVal, maxVal := intBounds(flag.Type)
+			if value, err := p.parseSignedBytes(stringToBytes(envValue), minVal, maxVal); err == nil {
+				result.GlobalInt64Flags[name] = value
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultInt64 != 0 {
+			result.GlobalInt64Flags[name] = flag.DefaultInt64
+			return ValueSourceDefault
+		}
+	case FlagTypeUint, FlagTypeUint64:
+		if _, exists := result.GlobalUint64Flags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if value, err := p.parseUnsignedBytes(stringToBytes(envValue), uintBound(flag.Type)); err == nil {
+				result.GlobalUint64Flags[name] = value
+				return ValueSourceEnv
+			}
+		}
+		if flag.DefaultUint64 != 0 {
+			result.GlobalUint64Flags[name] = flag.DefaultUint64
+			return ValueSourceDefault
+		}
+	case FlagTypeBool:
+		if _, exists := result.GlobalBoolFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			result.GlobalBoolFlags[name] = p.parseBoolValue(envValue)
+			return ValueSourceEnv
+		}
+		result.GlobalBoolFlags[name] = flag.DefaultBool
+		return ValueSourceDefault
 	case FlagTypeDuration:
-		if _, exists := result.GlobalDurationFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				if durationValue, err := p.parseDurationValue(envValue); err == nil {
-					result.GlobalDurationFlags[name] = durationValue
-				}
-			} else if flag.DefaultDuration != 0 {
-				result.GlobalDurationFlags[name] = flag.DefaultDuration
+		if _, exists := result.GlobalDurationFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if durationValue, err := p.parseDurationValue(envValue); err == nil {
+				result.GlobalDurationFlags[name] = durationValue
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultDuration != 0 {
+			result.GlobalDurationFlags[name] = flag.DefaultDuration
+			return ValueSourceDefault
+		}
 	case FlagTypeFloat:
-		if _, exists := result.GlobalFloatFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				if floatValue, err := p.parseFloatValue(envValue); err == nil {
-					result.GlobalFloatFlags[name] = floatValue
-				}
-			} else if flag.DefaultFloat != 0.0 {
-				result.GlobalFloatFlags[name] = flag.DefaultFloat
+		if _, exists := result.GlobalFloatFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if floatValue, err := p.parseFloatValue(envValue); err == nil {
+				result.GlobalFloatFlags[name] = floatValue
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultFloat != 0.0 {
+			result.GlobalFloatFlags[name] = flag.DefaultFloat
+			return ValueSourceDefault
+		}
 	case FlagTypeEnum:
-		if _, exists := result.GlobalEnumFlags[name]; !exists {
-			// Check environment variables first (precedence order)
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				// Validate enum value
-				if p.isValidEnumValue(flag, envValue) {
-					result.GlobalEnumFlags[name] = envValue
-				}
-			} else if flag.DefaultEnum != "" {
-				result.GlobalEnumFlags[name] = flag.DefaultEnum
+		if _, exists := result.GlobalEnumFlags[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			// Validate and normalize enum value
+			if value, ok := p.canonicalEnumValue(flag, envValue); ok {
+				result.GlobalEnumFlags[name] = value
+				return ValueSourceEnv
 			}
 		}
+		if flag.DefaultEnum != "" {
+			result.GlobalEnumFlags[name] = flag.DefaultEnum
+			return ValueSourceDefault
+		}
 	case FlagTypeStringSlice:
-		if _, exists := result.GlobalStringSliceOffsets[name]; !exists {
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				slice := p.parseStringSlice([]byte(envValue))
-				result.stringSlices = append(result.stringSlices, slice)
-				offset := pool.SliceOffset{Start: len(result.stringSlices) - 1, End: len(result.stringSlices)}
-				result.GlobalStringSliceOffsets[name] = offset
-			} else if len(flag.DefaultStringSlice) > 0 {
-				slice := pool.GetStringSlice()
-				*slice = append(*slice, flag.DefaultStringSlice...)
-				result.stringSlices = append(result.stringSlices, slice)
-				offset := pool.SliceOffset{Start: len(result.stringSlices) - 1, End: len(result.stringSlices)}
-				result.GlobalStringSliceOffsets[name] = offset
-			}
+		if _, exists := result.GlobalStringSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			slice := p.parseStringSlice([]byte(envValue))
+			storeSlice(&result.stringSlices, result.GlobalStringSliceOffsets, name, slice, pool.PutStringSlice)
+			return ValueSourceEnv
+		}
+		if len(flag.DefaultStringSlice) > 0 {
+			slice := pool.GetStringSlice()
+			*slice = append(*slice, flag.DefaultStringSlice...)
+			storeSlice(&result.stringSlices, result.GlobalStringSliceOffsets, name, slice, pool.PutStringSlice)
+			return ValueSourceDefault
 		}
 	case FlagTypeIntSlice:
-		if _, exists := result.GlobalIntSliceOffsets[name]; !exists {
-			if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
-				slice, err := p.parseIntSlice([]byte(envValue))
-				if err == nil {
-					result.intSlices = append(result.intSlices, slice)
-					offset := pool.SliceOffset{Start: len(result.intSlices) - 1, End: len(result.intSlices)}
-					result.GlobalIntSliceOffsets[name] = offset
-				}
-			} else if len(flag.DefaultIntSlice) > 0 {
-				slice := pool.GetIntSlice()
-				*slice = append(*slice, flag.DefaultIntSlice...)
-				result.intSlices = append(result.intSlices, slice)
-				offset := pool.SliceOffset{Start: len(result.intSlices) - 1, End: len(result.intSlices)}
-				result.GlobalIntSliceOffsets[name] = offset
+		if _, exists := result.GlobalIntSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if slice, err := p.parseIntSlice([]byte(envValue)); err == nil {
+				storeSlice(&result.intSlices, result.GlobalIntSliceOffsets, name, slice, pool.PutIntSlice)
+				return ValueSourceEnv
+			}
+		}
+		if len(flag.DefaultIntSlice) > 0 {
+			slice := pool.GetIntSlice()
+			*slice = append(*slice, flag.DefaultIntSlice...)
+			storeSlice(&result.intSlices, result.GlobalIntSliceOffsets, name, slice, pool.PutIntSlice)
+			return ValueSourceDefault
+		}
+	case FlagTypeFloatSlice:
+		if _, exists := result.GlobalFloatSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if slice, err := p.parseFloatSlice([]byte(envValue)); err == nil {
+				storeSlice(&result.floatSlices, result.GlobalFloatSliceOffsets, name, slice, pool.PutFloatSlice)
+				return ValueSourceEnv
 			}
 		}
+		if len(flag.DefaultFloatSlice) > 0 {
+			slice := pool.GetFloatSlice()
+			*slice = append(*slice, flag.DefaultFloatSlice...)
+			storeSlice(&result.floatSlices, result.GlobalFloatSliceOffsets, name, slice, pool.PutFloatSlice)
+			return ValueSourceDefault
+		}
+	case FlagTypeDurationSlice:
+		if _, exists := result.GlobalDurationSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			if slice, err := p.parseDurationSlice([]byte(envValue)); err == nil {
+				storeSlice(&result.durationSlices, result.GlobalDurationSliceOffsets, name, slice, pool.PutDurationSlice)
+				return ValueSourceEnv
+			}
+		}
+		if len(flag.DefaultDurationSlice) > 0 {
+			slice := pool.GetDurationSlice()
+			*slice = append(*slice, flag.DefaultDurationSlice...)
+			storeSlice(&result.durationSlices, result.GlobalDurationSliceOffsets, name, slice, pool.PutDurationSlice)
+			return ValueSourceDefault
+		}
+	case FlagTypeEnumSlice:
+		if _, exists := result.GlobalEnumSliceOffsets[name]; exists {
+			return ValueSourceFlag
+		}
+		if envValue := p.getEnvValue(flag.EnvVars); envValue != "" {
+			// Only accept env values when every element is valid
+			if slice, ok := p.parseEnumSliceEnv(flag, envValue); ok {
+				storeSlice(&result.stringSlices, result.GlobalEnumSliceOffsets, name, slice, pool.PutStringSlice)
+				return ValueSourceEnv
+			}
+		}
+		if len(flag.DefaultEnumSlice) > 0 {
+			slice := pool.GetStringSlice()
+			*slice = append(*slice, flag.DefaultEnumSlice...)
+			storeSlice(&result.stringSlices, result.GlobalEnumSliceOffsets, name, slice, pool.PutStringSlice)
+			return ValueSourceDefault
+		}
 	}
+	return ValueSourceNone
 }
 
 // Utility methods for zero-allocation operations
@@ -1230,8 +2200,20 @@ func (p *Parser) clearResult(result *ParseResult) {
 			pool.PutIntSlice(slice)
 		}
 	}
+	for _, slice := range result.floatSlices {
+		if slice != nil {
+			pool.PutFloatSlice(slice)
+		}
+	}
+	for _, slice := range result.durationSlices {
+		if slice != nil {
+			pool.PutDurationSlice(slice)
+		}
+	}
 	result.stringSlices = result.stringSlices[:0]
 	result.intSlices = result.intSlices[:0]
+	result.floatSlices = result.floatSlices[:0]
+	result.durationSlices = result.durationSlices[:0]
 
 	// Use the pool's reset functionality
 	if result.ParseResult != nil {
@@ -1240,7 +2222,11 @@ func (p *Parser) clearResult(result *ParseResult) {
 	}
 
 	result.Args = result.Args[:0]
+	result.unknownFlags = result.unknownFlags[:0]
 	result.Command = nil
+	if len(result.sources) > 0 {
+		clear(result.sources)
+	}
 }
 
 // parseBoolBytes parses boolean value from byte slice without allocation.
@@ -1265,9 +2251,18 @@ func (p *Parser) parseBoolBytes(b []byte) bool {
 	return false
 }
 
-// parseIntBytes transparently parses decimal and hex integers using ASCII math.
-// Supports: 123, -456, 0xFF, 0x1A2B, etc. Zero allocations.
+// parseIntBytes transparently parses decimal, hex, octal and binary integers using ASCII math.
+// Supports: 123, -456, 0xFF, 0x1A2B, 0o755, 0b1010, etc. Zero allocations.
 func (p *Parser) parseIntBytes(b []byte) (int, error) {
+	value, err := p.parseSignedBytes(b, math.MinInt, math.MaxInt)
+	if err != nil {
+		return 0, err
+	}
+	return int(value), nil
+}
+
+// parseSignedBytes parses a signed integer literal and rejects values outside [minVal, maxVal].
+func (p *Parser) parseSignedBytes(b []byte, minVal, maxVal int64) (int64, error) {
 	if len(b) == 0 {
 		return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "empty integer"}
 	}
@@ -1280,100 +2275,138 @@ func (p *Parser) parseIntBytes(b []byte) (int, error) {
 	case '-':
 		negative = true
 		start = 1
-		if len(b) == 1 {
-			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid integer"}
-		}
 	case '+':
 		start = 1
-		if len(b) == 1 {
-			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid integer"}
-		}
+	}
+	if start == 1 && len(b) == 1 {
+		return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid integer"}
 	}
 
-	// Check for hex prefix (0x or 0X) - transparent to user
-	remaining := b[start:]
-	if len(remaining) > 2 && remaining[0] == '0' && (remaining[1] == 'x' || remaining[1] == 'X') {
-		result, err := p.parseHexBytes(remaining[2:])
-		if err != nil {
-			return 0, err
-		}
-		if negative {
-			result = -result
-		}
-		return result, nil
+	// Magnitude limit: |minVal| for negatives (computed without overflowing), maxVal otherwise
+	limit := uint64(maxVal)
+	if negative {
+		limit = uint64(-(minVal + 1)) + 1
 	}
 
-	// Default to decimal parsing with ASCII math
-	result, err := p.parseDecimalBytes(remaining)
+	magnitude, err := p.parseMagnitudeBytes(b[start:], limit)
 	if err != nil {
 		return 0, err
 	}
 
 	if negative {
-		result = -result
+		// Two's complement negation also handles |MinInt64| correctly
+		return -int64(magnitude), nil //nolint:gosec // magnitude is bounded by limit above
 	}
+	return int64(magnitude), nil //nolint:gosec // magnitude is bounded by maxVal above
+}
 
-	return result, nil
+// parseUnsignedBytes parses an unsigned integer literal (optional '+') no greater than maxVal.
+func (p *Parser) parseUnsignedBytes(b []byte, maxVal uint64) (uint64, error) {
+	if len(b) == 0 {
+		return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "empty integer"}
+	}
+
+	switch b[0] {
+	case '-':
+		return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "negative value for unsigned integer"}
+	case '+':
+		if len(b) == 1 {
+			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid integer"}
+		}
+		b = b[1:]
+	}
+
+	return p.parseMagnitudeBytes(b, maxVal)
 }
 
-// parseDecimalBytes parses decimal using direct ASCII math: '8' - '0' = 8
-func (p *Parser) parseDecimalBytes(b []byte) (int, error) {
-	result := 0
+// parseMagnitudeBytes parses unsigned digits with an optional radix prefix
+// (0x hex, 0o octal, 0b binary - transparent to user) using ASCII math,
+// failing when the value would exceed limit.
+func (p *Parser) parseMagnitudeBytes(b []byte, limit uint64) (uint64, error) {
+	base := uint64(10)
+	if len(b) > 2 && b[0] == '0' {
+		switch b[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			b = b[2:]
+		}
+	}
 
+	var result uint64
 	for i := 0; i < len(b); i++ {
 		c := b[i]
+		var digit uint64
 
-		// Validate it's a digit
-		if c < '0' || c > '9' {
-			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid decimal character"}
+		// ASCII math for digits: '8' - '0' = 8, 'A' - 'A' + 10 = 10
+		switch {
+		case c >= '0' && c <= '9':
+			digit = uint64(c - '0')
+		case c >= 'A' && c <= 'F':
+			digit = uint64(c - 'A' + 10)
+		case c >= 'a' && c <= 'f':
+			digit = uint64(c - 'a' + 10)
+		default:
+			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid integer character"}
+		}
+		if digit >= base {
+			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid integer character"}
 		}
-
-		// ASCII math: '8' - '0' = 8
-		digit := int(c - '0')
 
 		// Check for overflow before multiplication (platform-agnostic)
-		if result > (math.MaxInt-digit)/10 {
+		if result > (limit-digit)/base {
 			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "integer overflow"}
 		}
 
-		// Build number: "123" -> 1*10 + 2 -> 12*10 + 3 = 123
-		result = result*10 + digit
+		result = result*base + digit
 	}
 
 	return result, nil
 }
 
-// parseHexBytes parses hexadecimal using ASCII math: 'A' - 'A' + 10 = 10
-func (p *Parser) parseHexBytes(b []byte) (int, error) {
-	if len(b) == 0 {
-		return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "empty hex value"}
+// intBounds returns the accepted range for signed sized integer flag types.
+func intBounds(t FlagType) (int64, int64) {
+	if t == FlagTypeInt32 {
+		return math.MinInt32, math.MaxInt32
 	}
+	return math.MinInt64, math.MaxInt64
+}
+
+// uintBound returns the maximum accepted value for unsigned integer flag types.
+func uintBound(t FlagType) uint64 {
+	if t == FlagTypeUint {
+		return math.MaxUint
+	}
+	return math.MaxUint64
+}
 
+// parseDecimalBytes parses decimal using direct ASCII math: '8' - '0' = 8
+func (p *Parser) parseDecimalBytes(b []byte) (int, error) {
 	result := 0
 
 	for i := 0; i < len(b); i++ {
 		c := b[i]
-		var digit int
 
-		// ASCII math for hex digits
-		switch {
-		case c >= '0' && c <= '9':
-			digit = int(c - '0') // '0' = 0, '9' = 9
-		case c >= 'A' && c <= 'F':
-			digit = int(c - 'A' + 10) // 'A' = 10, 'F' = 15
-		case c >= 'a' && c <= 'f':
-			digit = int(c - 'a' + 10) // 'a' = 10, 'f' = 15
-		default:
-			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid hex character"}
+		// Validate it's a digit
+		if c < '0' || c > '9' {
+			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "invalid decimal character"}
 		}
 
-		// Check for overflow (hex can get large quickly)
-		if result > (math.MaxInt-digit)/16 {
-			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "hex integer overflow"}
+		// ASCII math: '8' - '0' = 8
+		digit := int(c - '0')
+
+		// Check for overflow before multiplication (platform-agnostic)
+		if result > (math.MaxInt-digit)/10 {
+			return 0, &ParseError{Type: ErrorTypeInvalidValue, Message: "integer overflow"}
 		}
 
-		// Build hex number: "A1" -> 10*16 + 1 = 161
-		result = result*16 + digit
+		// Build number: "123" -> 1*10 + 2 -> 12*10 + 3 = 123
+		result = result*10 + digit
 	}
 
 	return result, nil
@@ -1723,44 +2756,90 @@ func (p *Parser) parseStringSlice(b []byte) *[]string {
 	start := 0
 	for i := 0; i <= len(b); i++ {
 		if i == len(b) || b[i] == ',' {
-			// Extract substring from start to i
-			segment := b[start:i]
-
-			// Trim whitespace
-			segment = trimSpaceBytes(segment)
-
-			// Convert to string and append to slice
+			// Extract substring from start to i
+			segment := b[start:i]
+
+			// Trim whitespace
+			segment = trimSpaceBytes(segment)
+
+			// Convert to string and append to slice
+			if len(segment) > 0 {
+				*slice = append(*slice, bytesToString(segment))
+			}
+			start = i + 1
+		}
+	}
+
+	return slice
+}
+
+// parseIntSlice parses comma-separated integers using pooled slice
+func (p *Parser) parseIntSlice(b []byte) (*[]int, error) {
+	slice := pool.GetIntSlice()
+
+	if len(b) == 0 {
+		return slice, nil
+	}
+
+	start := 0
+	for i := 0; i <= len(b); i++ {
+		if i == len(b) || b[i] == ',' {
+			// Extract substring from start to i
+			segment := b[start:i]
+
+			// Trim whitespace
+			segment = trimSpaceBytes(segment)
+
+			if len(segment) > 0 {
+				// Parse using our existing zero-allocation int parser
+				value, err := p.parseIntBytes(segment)
+				if err != nil {
+					return nil, err
+				}
+				*slice = append(*slice, value)
+			}
+			start = i + 1
+		}
+	}
+
+	return slice, nil
+}
+
+// parseFloatSlice parses comma-separated floats using pooled slice
+func (p *Parser) parseFloatSlice(b []byte) (*[]float64, error) {
+	slice := pool.GetFloatSlice()
+
+	start := 0
+	for i := 0; i <= len(b); i++ {
+		if i == len(b) || b[i] == ',' {
+			segment := trimSpaceBytes(b[start:i])
 			if len(segment) > 0 {
-				*slice = append(*slice, bytesToString(segment))
+				value, err := p.parseFloatBytes(segment)
+				if err != nil {
+					pool.PutFloatSlice(slice)
+					return nil, err
+				}
+				*slice = append(*slice, value)
 			}
 			start = i + 1
 		}
 	}
 
-	return slice
+	return slice, nil
 }
 
-// parseIntSlice parses comma-separated integers using pooled slice
-func (p *Parser) parseIntSlice(b []byte) (*[]int, error) {
-	slice := pool.GetIntSlice()
-
-	if len(b) == 0 {
-		return slice, nil
-	}
+// parseDurationSlice parses comma-separated durations using pooled slice
+func (p *Parser) parseDurationSlice(b []byte) (*[]time.Duration, error) {
+	slice := pool.GetDurationSlice()
 
 	start := 0
 	for i := 0; i <= len(b); i++ {
 		if i == len(b) || b[i] == ',' {
-			// Extract substring from start to i
-			segment := b[start:i]
-
-			// Trim whitespace
-			segment = trimSpaceBytes(segment)
-
+			segment := trimSpaceBytes(b[start:i])
 			if len(segment) > 0 {
-				// Parse using our existing zero-allocation int parser
-				value, err := p.parseIntBytes(segment)
+				value, err := p.parseDurationBytes(segment)
 				if err != nil {
+					pool.PutDurationSlice(slice)
 					return nil, err
 				}
 				*slice = append(*slice, value)
@@ -1772,18 +2851,19 @@ func (p *Parser) parseIntSlice(b []byte) (*[]int, error) {
 	return slice, nil
 }
 
-// isValidEnumValue checks if a value is valid for an enum flag
-func (p *Parser) isValidEnumValue(flag *Flag, value string) bool {
-	if flag == nil || flag.Type != FlagTypeEnum {
-		return false
+// canonicalEnumValue validates a value for an enum (or enum slice) flag and
+// returns its canonical form, resolving aliases and case differences
+func (p *Parser) canonicalEnumValue(flag *Flag, value string) (string, bool) {
+	if flag == nil || (flag.Type != FlagTypeEnum && flag.Type != FlagTypeEnumSlice) {
+		return "", false
 	}
 
-	return slices.Contains(flag.EnumValues, value)
+	return flag.canonicalEnum(value)
 }
 
 // enumValuesString returns a comma-separated string of valid enum values
 func (p *Parser) enumValuesString(flag *Flag) string {
-	if flag == nil || flag.Type != FlagTypeEnum || len(flag.EnumValues) == 0 {
+	if flag == nil || (flag.Type != FlagTypeEnum && flag.Type != FlagTypeEnumSlice) || len(flag.EnumValues) == 0 {
 		return ""
 	}
 
@@ -1807,6 +2887,16 @@ func (p *Parser) findClosestFlag(name string) string {
 	bestMatch := ""
 	bestDistance := 3 // Only suggest if distance <= 2
 
+	// Prefer flags of the current command
+	if p.currentCmd != nil {
+		for flagName := range p.currentCmd.flags {
+			distance := p.levenshteinDistance(name, flagName)
+			if distance < bestDistance {
+				bestDistance = distance
+				bestMatch = flagName
+			}
+		}
+	}
 	for flagName := range p.app.flags {
 		distance := p.levenshteinDistance(name, flagName)
 		if distance < bestDistance {
@@ -2003,6 +3093,33 @@ func (r *ParseResult) GetIntSlice(name string) ([]int, bool) {
 	return []int{}, false
 }
 
+// GetFloatSlice retrieves a float64 slice flag value using stored slice
+func (r *ParseResult) GetFloatSlice(name string) ([]float64, bool) {
+	return lookupSlice(r.floatSlices, r.FloatSliceOffsets, name)
+}
+
+// GetDurationSlice retrieves a duration slice flag value using stored slice
+func (r *ParseResult) GetDurationSlice(name string) ([]time.Duration, bool) {
+	return lookupSlice(r.durationSlices, r.DurationSliceOffsets, name)
+}
+
+// GetEnumSlice retrieves a multi-value enum flag value using stored slice
+func (r *ParseResult) GetEnumSlice(name string) ([]string, bool) {
+	return lookupSlice(r.stringSlices, r.EnumSliceOffsets, name)
+}
+
+// lookupSlice resolves a slice offset to its stored slice
+func lookupSlice[T any](store []*[]T, offsets map[string]pool.SliceOffset, name string) ([]T, bool) {
+	if offset, exists := offsets[name]; exists {
+		if offset.Start >= 0 && offset.Start < len(store) {
+			if slice := store[offset.Start]; slice != nil {
+				return *slice, true
+			}
+		}
+	}
+	return []T{}, false
+}
+
 // Global flag access methods
 
 // GetGlobalString retrieves a global string flag value
@@ -2079,6 +3196,21 @@ func (r *ParseResult) GetGlobalIntSlice(name string) ([]int, bool) {
 	return []int{}, false
 }
 
+// GetGlobalFloatSlice retrieves a global float64 slice flag value using stored slice
+func (r *ParseResult) GetGlobalFloatSlice(name string) ([]float64, bool) {
+	return lookupSlice(r.floatSlices, r.GlobalFloatSliceOffsets, name)
+}
+
+// GetGlobalDurationSlice retrieves a global duration slice flag value using stored slice
+func (r *ParseResult) GetGlobalDurationSlice(name string) ([]time.Duration, bool) {
+	return lookupSlice(r.durationSlices, r.GlobalDurationSliceOffsets, name)
+}
+
+// GetGlobalEnumSlice retrieves a global multi-value enum flag value using stored slice
+func (r *ParseResult) GetGlobalEnumSlice(name string) ([]string, bool) {
+	return lookupSlice(r.stringSlices, r.GlobalEnumSliceOffsets, name)
+}
+
 // Convenience methods with defaults (Must pattern) - return value or default
 
 // MustGetString retrieves a string flag value or returns the default
@@ -2145,6 +3277,30 @@ func (r *ParseResult) MustGetIntSlice(name string, defaultValue []int) []int {
 	return defaultValue
 }
 
+// MustGetFloatSlice retrieves a float64 slice flag value or returns the default
+func (r *ParseResult) MustGetFloatSlice(name string, defaultValue []float64) []float64 {
+	if value, exists := r.GetFloatSlice(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// MustGetDurationSlice retrieves a duration slice flag value or returns the default
+func (r *ParseResult) MustGetDurationSlice(name string, defaultValue []time.Duration) []time.Duration {
+	if value, exists := r.GetDurationSlice(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// MustGetEnumSlice retrieves a multi-value enum flag value or returns the default
+func (r *ParseResult) MustGetEnumSlice(name string, defaultValue []string) []string {
+	if value, exists := r.GetEnumSlice(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
 // Global convenience methods with defaults (Must pattern)
 
 // MustGetGlobalString retrieves a global string flag value or returns the default
@@ -2211,6 +3367,160 @@ func (r *ParseResult) MustGetGlobalIntSlice(name string, defaultValue []int) []i
 	return defaultValue
 }
 
+// MustGetGlobalFloatSlice retrieves a global float64 slice flag value or returns the default
+func (r *ParseResult) MustGetGlobalFloatSlice(name string, defaultValue []float64) []float64 {
+	if value, exists := r.GetGlobalFloatSlice(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// MustGetGlobalDurationSlice retrieves a global duration slice flag value or returns the default
+func (r *ParseResult) MustGetGlobalDurationSlice(name string, defaultValue []time.Duration) []time.Duration {
+	if value, exists := r.GetGlobalDurationSlice(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// MustGetGlobalEnumSlice retrieves a global multi-value enum flag value or returns the default
+func (r *ParseResult) MustGetGlobalEnumSlice(name string, defaultValue []string) []string {
+	if value, exists := r.GetGlobalEnumSlice(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// Sized integer access methods (Int64Flag, Int32Flag, UintFlag, Uint64Flag)
+
+// GetInt64 retrieves an int64 flag value
+func (r *ParseResult) GetInt64(name string) (int64, bool) {
+	if value, exists := r.Int64Flags[name]; exists {
+		return value, true
+	}
+	return 0, false
+}
+
+// MustGetInt64 retrieves an int64 flag value or returns the default
+func (r *ParseResult) MustGetInt64(name string, defaultValue int64) int64 {
+	if value, exists := r.GetInt64(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// GetInt32 retrieves an int32 flag value
+func (r *ParseResult) GetInt32(name string) (int32, bool) {
+	if value, exists := r.Int64Flags[name]; exists {
+		return int32(value), true
+	}
+	return 0, false
+}
+
+// MustGetInt32 retrieves an int32 flag value or returns the default
+func (r *ParseResult) MustGetInt32(name string, defaultValue int32) int32 {
+	if value, exists := r.GetInt32(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// GetUint retrieves a uint flag value
+func (r *ParseResult) GetUint(name string) (uint, bool) {
+	if value, exists := r.Uint64Flags[name]; exists {
+		return uint(value), true
+	}
+	return 0, false
+}
+
+// MustGetUint retrieves a uint flag value or returns the default
+func (r *ParseResult) MustGetUint(name string, defaultValue uint) uint {
+	if value, exists := r.GetUint(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// GetUint64 retrieves a uint64 flag value
+func (r *ParseResult) GetUint64(name string) (uint64, bool) {
+	if value, exists := r.Uint64Flags[name]; exists {
+		return value, true
+	}
+	return 0, false
+}
+
+// MustGetUint64 retrieves a uint64 flag value or returns the default
+func (r *ParseResult) MustGetUint64(name string, defaultValue uint64) uint64 {
+	if value, exists := r.GetUint64(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// GetGlobalInt64 retrieves an global int64 flag value
+func (r *ParseResult) GetGlobalInt64(name string) (int64, bool) {
+	if value, exists := r.GlobalInt64Flags[name]; exists {
+		return value, true
+	}
+	return 0, false
+}
+
+// MustGetGlobalInt64 retrieves an global int64 flag value or returns the default
+func (r *ParseResult) MustGetGlobalInt64(name string, defaultValue int64) int64 {
+	if value, exists := r.GetGlobalInt64(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// GetGlobalInt32 retrieves an global int32 flag value
+func (r *ParseResult) GetGlobalInt32(name string) (int32, bool) {
+	if value, exists := r.GlobalInt64Flags[name]; exists {
+		return int32(value), true
+	}
+	return 0, false
+}
+
+// MustGetGlobalInt32 retrieves an global int32 flag value or returns the default
+func (r *ParseResult) MustGetGlobalInt32(name string, defaultValue int32) int32 {
+	if value, exists := r.GetGlobalInt32(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// GetGlobalUint retrieves a global uint flag value
+func (r *ParseResult) GetGlobalUint(name string) (uint, bool) {
+	if value, exists := r.GlobalUint64Flags[name]; exists {
+		return uint(value), true
+	}
+	return 0, false
+}
+
+// MustGetGlobalUint retrieves a global uint flag value or returns the default
+func (r *ParseResult) MustGetGlobalUint(name string, defaultValue uint) uint {
+	if value, exists := r.GetGlobalUint(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// GetGlobalUint64 retrieves a global uint64 flag value
+func (r *ParseResult) GetGlobalUint64(name string) (uint64, bool) {
+	if value, exists := r.GlobalUint64Flags[name]; exists {
+		return value, true
+	}
+	return 0, false
+}
+
+// MustGetGlobalUint64 retrieves a global uint64 flag value or returns the default
+func (r *ParseResult) MustGetGlobalUint64(name string, defaultValue uint64) uint64 {
+	if value, exists := r.GetGlobalUint64(name); exists {
+		return value
+	}
+	return defaultValue
+}
+
 // Positional argument access methods (zero-allocation)
 
 // GetArgString retrieves a string positional argument value
@@ -2337,6 +3647,72 @@ func (r *ParseResult) MustGetArgIntSlice(name string, defaultValue []int) []int
 	return defaultValue
 }
 
+// ArgsAfterTerminator returns the positional arguments that followed "--".
+// They are still part of Args; this separates a passthrough payload
+// ("run -- cmd args...") from the command's own positionals.
+// Returns nil when no terminator was given.
+func (r *ParseResult) ArgsAfterTerminator() []string {
+	if r.terminator < 0 || r.terminator > len(r.Args) {
+		return nil
+	}
+	return r.Args[r.terminator:]
+}
+
+// UnknownFlags returns the unrecognized flags of a command with
+// CollectUnknownFlags, with their values, in command-line order
+func (r *ParseResult) UnknownFlags() []string {
+	return r.unknownFlags
+}
+
+// Source reports where the flag's value came from (command line, env or default)
+func (r *ParseResult) Source(name string) ValueSource {
+	if src, ok := r.sources[name]; ok {
+		return src
+	}
+	if r.HasFlag(name) || r.HasGlobalFlag(name) {
+		return ValueSourceFlag
+	}
+	return ValueSourceNone
+}
+
+// flagSources returns the source of every flag in scope (the app's and the
+// command's) that has a value, sorted by name
+func (r *ParseResult) flagSources() ([]string, []ValueSource) {
+	var names []string
+	if r.app != nil {
+		names = append(names, sortedKeys(r.app.flags)...)
+	}
+	if r.Command != nil {
+		for _, name := range sortedKeys(r.Command.flags) {
+			if r.app == nil || r.app.flags[name] == nil {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	sources := make([]ValueSource, 0, len(names))
+	set := names[:0]
+	for _, name := range names {
+		if src := r.Source(name); src != ValueSourceNone {
+			set = append(set, name)
+			sources = append(sources, src)
+		}
+	}
+	return set, sources
+}
+
+// IsSet returns true if the flag was explicitly provided on the command line
+func (r *ParseResult) IsSet(name string) bool {
+	return r.Source(name) == ValueSourceFlag
+}
+
+// IsDefault returns true if the flag was neither provided on the command line
+// nor via environment, i.e. it holds its default (or zero) value
+func (r *ParseResult) IsDefault(name string) bool {
+	src := r.Source(name)
+	return src == ValueSourceDefault || src == ValueSourceNone
+}
+
 // HasFlag returns true if the flag exists (was provided or has a default)
 func (r *ParseResult) HasFlag(name string) bool {
 	_, exists := r.StringFlags[name]
@@ -2347,6 +3723,14 @@ func (r *ParseResult) HasFlag(name string) bool {
 	if exists {
 		return true
 	}
+	_, exists = r.Int64Flags[name]
+	if exists {
+		return true
+	}
+	_, exists = r.Uint64Flags[name]
+	if exists {
+		return true
+	}
 	_, exists = r.BoolFlags[name]
 	if exists {
 		return true
@@ -2368,6 +3752,18 @@ func (r *ParseResult) HasFlag(name string) bool {
 		return true
 	}
 	_, exists = r.IntSliceOffsets[name]
+	if exists {
+		return true
+	}
+	_, exists = r.FloatSliceOffsets[name]
+	if exists {
+		return true
+	}
+	_, exists = r.DurationSliceOffsets[name]
+	if exists {
+		return true
+	}
+	_, exists = r.EnumSliceOffsets[name]
 	return exists
 }
 
@@ -2381,6 +3777,14 @@ func (r *ParseResult) HasGlobalFlag(name string) bool {
 	if exists {
 		return true
 	}
+	_, exists = r.GlobalInt64Flags[name]
+	if exists {
+		return true
+	}
+	_, exists = r.GlobalUint64Flags[name]
+	if exists {
+		return true
+	}
 	_, exists = r.GlobalBoolFlags[name]
 	if exists {
 		return true
@@ -2402,6 +3806,18 @@ func (r *ParseResult) HasGlobalFlag(name string) bool {
 		return true
 	}
 	_, exists = r.GlobalIntSliceOffsets[name]
+	if exists {
+		return true
+	}
+	_, exists = r.GlobalFloatSliceOffsets[name]
+	if exists {
+		return true
+	}
+	_, exists = r.GlobalDurationSliceOffsets[name]
+	if exists {
+		return true
+	}
+	_, exists = r.GlobalEnumSliceOffsets[name]
 	return exists
 }
 
@@ -2420,6 +3836,11 @@ func (p *Parser) validateFlagGroups(result *ParseResult) error {
 	if result.Command != nil {
 		for _, group := range result.Command.flagGroups {
 			if err := p.validateSingleGroup(group, result); err != nil {
+				// Lets the error point to the command's group help
+				var parseErr *ParseError
+				if errors.As(err, &parseErr) {
+					parseErr.CurrentCommand = result.Command
+				}
 				return err
 			}
 		}
@@ -2438,6 +3859,10 @@ func (p *Parser) validateSingleGroup(group *FlagGroup, result *ParseResult) erro
 		}
 	}
 
+	if setCount == 0 && p.selectGroupDefault(group, result) {
+		setCount = 1
+	}
+
 	// Validate based on constraint type
 	switch group.Constraint { // exhaustive over GroupConstraintType
 	case GroupMutuallyExclusive:
@@ -2455,6 +3880,7 @@ func (p *Parser) validateSingleGroup(group *FlagGroup, result *ParseResult) erro
 					group.Name, setFlags),
 			)
 			err.GroupName = group.Name
+			err.msgID, err.msgArgs = MsgGroupExclusiveViolation, []any{group.Name, setFlags}
 			return err
 		}
 
@@ -2465,6 +3891,7 @@ func (p *Parser) validateSingleGroup(group *FlagGroup, result *ParseResult) erro
 				fmt.Sprintf("group '%s' requires at least one flag to be set", group.Name),
 			)
 			err.GroupName = group.Name
+			err.msgID, err.msgArgs = MsgGroupAtLeastOneMissing, []any{group.Name}
 			return err
 		}
 
@@ -2475,6 +3902,7 @@ func (p *Parser) validateSingleGroup(group *FlagGroup, result *ParseResult) erro
 				fmt.Sprintf("group '%s' requires either all flags or no flags to be set", group.Name),
 			)
 			err.GroupName = group.Name
+			err.msgID, err.msgArgs = MsgGroupAllOrNoneViolation, []any{group.Name}
 			return err
 		}
 
@@ -2486,6 +3914,7 @@ func (p *Parser) validateSingleGroup(group *FlagGroup, result *ParseResult) erro
 					group.Name, setCount),
 			)
 			err.GroupName = group.Name
+			err.msgID, err.msgArgs = MsgGroupExactlyOneMismatch, []any{group.Name, setCount}
 			return err
 		}
 	case GroupNoConstraint:
@@ -2495,6 +3924,29 @@ func (p *Parser) validateSingleGroup(group *FlagGroup, result *ParseResult) erro
 	return nil
 }
 
+// selectGroupDefault turns on the group's DefaultFlag when no flag of the
+// group was given; AllOrNone groups never select a default
+func (p *Parser) selectGroupDefault(group *FlagGroup, result *ParseResult) bool {
+	if !group.selectsDefault() {
+		return false
+	}
+	for _, flag := range group.Flags {
+		if flag.Name != group.DefaultFlag || flag.Type != FlagTypeBool {
+			continue
+		}
+		if flag.Global {
+			result.GlobalBoolFlags[flag.Name] = true
+		} else {
+			result.BoolFlags[flag.Name] = true
+		}
+		if result.sources != nil {
+			result.sources[flag.Name] = ValueSourceDefault
+		}
+		return true
+	}
+	return false
+}
+
 // isFlagSet checks if a flag is set in the parse result
 //
 //nolint:funlen // Compact switch over flag types
@@ -2516,6 +3968,22 @@ func (p *Parser) isFlagSet(flag *Flag, result *ParseResult) bool {
 		_, exists := result.IntFlags[flag.Name]
 		return exists
 
+	case FlagTypeInt64, FlagTypeInt32:
+		if flag.Global {
+			_, exists := result.GlobalInt64Flags[flag.Name]
+			return exists
+		}
+		_, exists := result.Int64Flags[flag.Name]
+		return exists
+
+	case FlagTypeUint, FlagTypeUint64:
+		if flag.Global {
+			_, exists := result.GlobalUint64Flags[flag.Name]
+			return exists
+		}
+		_, exists := result.Uint64Flags[flag.Name]
+		return exists
+
 	case FlagTypeBool:
 		if flag.Global {
 			value, exists := result.GlobalBoolFlags[flag.Name]
@@ -2564,6 +4032,30 @@ func (p *Parser) isFlagSet(flag *Flag, result *ParseResult) bool {
 		_, exists := result.IntSliceOffsets[flag.Name]
 		return exists
 
+	case FlagTypeFloatSlice:
+		if flag.Global {
+			_, exists := result.GlobalFloatSliceOffsets[flag.Name]
+			return exists
+		}
+		_, exists := result.FloatSliceOffsets[flag.Name]
+		return exists
+
+	case FlagTypeDurationSlice:
+		if flag.Global {
+			_, exists := result.GlobalDurationSliceOffsets[flag.Name]
+			return exists
+		}
+		_, exists := result.DurationSliceOffsets[flag.Name]
+		return exists
+
+	case FlagTypeEnumSlice:
+		if flag.Global {
+			_, exists := result.GlobalEnumSliceOffsets[flag.Name]
+			return exists
+		}
+		_, exists := result.EnumSliceOffsets[flag.Name]
+		return exists
+
 	default:
 		return false
 	}
@@ -0,0 +1,84 @@
+package snap
+
+import "errors"
+
+// Feed parses the next argument of a command line that arrives one token at
+// a time, e.g. over an IPC protocol or from an interactive shell, without
+// collecting the whole []string first. The first Feed starts a new parse;
+// End finishes it and returns the result. A flag that takes its value from
+// the next argument ("--port 8080") waits for the following Feed.
+//
+// Errors are reported as soon as the offending token is fed, with Index
+// counting the tokens fed so far. After an error the parse is abandoned
+// and the next Feed starts a new one.
+func (p *Parser) Feed(token string) error {
+	if !p.feeding {
+		p.reset()
+		p.currentResult = p.getResult()
+		p.feeding = true
+	}
+	p.lastToken = token
+
+	start := p.position
+	var err error
+	switch {
+	case p.pendingFlag != nil:
+		flag, name := p.pendingFlag, p.pendingName
+		p.pendingFlag, p.pendingName = nil, ""
+		err = p.storeFlagValue(name, flag, stringToBytes(token), flag.IsGlobal())
+	case token == "":
+	default:
+		// With no lookahead, value flags wait in pendingFlag (see awaitValue)
+		err = p.parseArgument(token, nil)
+	}
+	if err != nil {
+		p.feeding = false
+		return p.locateToken(err, token)
+	}
+	if p.app != nil && p.app.tracer != nil && token != "" {
+		p.traceArgument(start, []string{token})
+	}
+	p.position++
+	return nil
+}
+
+// End completes a parse started by Feed, applying defaults and validating
+// required flags, arguments and groups like Parse. Calling End without any
+// Feed parses an empty command line.
+func (p *Parser) End() (*ParseResult, error) {
+	if !p.feeding {
+		return p.Parse(nil)
+	}
+	p.feeding = false
+	if p.pendingFlag != nil {
+		p.position--
+		return nil, p.locateToken(p.missingValue(), p.lastToken)
+	}
+	return p.finalize()
+}
+
+// awaitValue handles a flag whose value is the next argument when there is
+// none: streaming parses wait for the next Feed, others fail
+func (p *Parser) awaitValue(name string, flag *Flag) error {
+	if !p.feeding {
+		return p.missingValue()
+	}
+	p.pendingFlag, p.pendingName = flag, name
+	return nil
+}
+
+// missingValue reports a value flag at the end of the command line
+func (p *Parser) missingValue() error {
+	return &ParseError{Type: ErrorTypeInvalidValue, Message: "missing required value", msgID: MsgMissingValue}
+}
+
+// locateToken records the fed token on parse errors that do not carry a
+// position yet, like locateError does for Parse
+func (p *Parser) locateToken(err error, token string) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) && parseErr.Token == "" {
+		parseErr.Index = p.position
+		parseErr.Token = token
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package snap
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dzonerzy/go-snap/internal/pool"
+)
+
+// ParseLimits bounds the input accepted by Parser.ParseStrict
+type ParseLimits struct {
+	MaxArgs       int // Maximum number of arguments
+	MaxArgLength  int // Maximum length of a single argument in bytes
+	MaxFlagLength int // Maximum length of a flag name in bytes
+}
+
+// DefaultParseLimits are the limits used by ParseStrict unless changed with SetLimits
+var DefaultParseLimits = ParseLimits{
+	MaxArgs:       1024,
+	MaxArgLength:  64 * 1024,
+	MaxFlagLength: 128,
+}
+
+// SetLimits replaces the limits enforced by ParseStrict; zero fields keep
+// the DefaultParseLimits value
+func (p *Parser) SetLimits(limits ParseLimits) *Parser {
+	p.limits = limits
+	return p
+}
+
+// ParseStrict parses args that come from an untrusted source, e.g. commands
+// received over a network. Unlike Parse it:
+//   - rejects input beyond the configured ParseLimits and flag names that are
+//     not valid UTF-8 with an ErrorTypeLimitExceeded or ErrorTypeInvalidFlag error
+//   - copies args, so the result never shares memory with the caller's strings
+//   - returns a result and error that later parses do not reuse
+//   - takes "@file" and "-" values of AllowFromFile flags literally
+//   - does not add unknown names to the process-wide string interner
+//   - turns a panic in the parser into an ErrorTypeInternal error
+func (p *Parser) ParseStrict(args []string) (result *ParseResult, err error) {
+	if err := p.checkLimits(args); err != nil {
+		return nil, err
+	}
+
+	owned := make([]string, len(args))
+	for i, arg := range args {
+		owned[i] = strings.Clone(arg)
+	}
+
+	// Detach from the previous result and error, which Parse recycles
+	p.reusableResult = &ParseResult{ParseResult: pool.GetParseResult(), sources: make(map[string]ValueSource, 16), terminator: -1}
+	p.reusableError = &ParseError{}
+
+	p.strict = true
+	defer func() {
+		p.strict = false
+		if r := recover(); r != nil {
+			result, err = nil, &ParseError{Type: ErrorTypeInternal, Message: fmt.Sprintf("parser panic: %v", r)}
+		}
+	}()
+	return p.Parse(owned)
+}
+
+// checkLimits validates args against the parser limits before parsing
+func (p *Parser) checkLimits(args []string) error {
+	limits := p.limits
+	if limits.MaxArgs <= 0 {
+		limits.MaxArgs = DefaultParseLimits.MaxArgs
+	}
+	if limits.MaxArgLength <= 0 {
+		limits.MaxArgLength = DefaultParseLimits.MaxArgLength
+	}
+	if limits.MaxFlagLength <= 0 {
+		limits.MaxFlagLength = DefaultParseLimits.MaxFlagLength
+	}
+
+	if len(args) > limits.MaxArgs {
+		return &ParseError{
+			Type:    ErrorTypeLimitExceeded,
+			Message: fmt.Sprintf("too many arguments: %d (max %d)", len(args), limits.MaxArgs),
+			Index:   limits.MaxArgs,
+			msgID:   MsgTooManyArgs,
+			msgArgs: []any{len(args), limits.MaxArgs},
+		}
+	}
+	for i, arg := range args {
+		if arg == "--" {
+			// Everything after the terminator is positional
+			limits.MaxFlagLength = -1
+			continue
+		}
+		if len(arg) > limits.MaxArgLength {
+			return &ParseError{
+				Type:    ErrorTypeLimitExceeded,
+				Message: fmt.Sprintf("argument %d is too long: %d bytes (max %d)", i, len(arg), limits.MaxArgLength),
+				Index:   i,
+				msgID:   MsgArgTooLong,
+				msgArgs: []any{i, len(arg), limits.MaxArgLength},
+			}
+		}
+		if limits.MaxFlagLength < 0 || len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if len(name) > limits.MaxFlagLength {
+			return &ParseError{
+				Type:    ErrorTypeLimitExceeded,
+				Message: fmt.Sprintf("flag name is too long: %d bytes (max %d)", len(name), limits.MaxFlagLength),
+				Index:   i,
+				msgID:   MsgFlagNameTooLong,
+				msgArgs: []any{len(name), limits.MaxFlagLength},
+			}
+		}
+		if !utf8.ValidString(name) {
+			return &ParseError{
+				Type:    ErrorTypeInvalidFlag,
+				Message: fmt.Sprintf("flag name is not valid UTF-8: %q", name),
+				Index:   i,
+				msgID:   MsgFlagNameNotUTF8,
+				msgArgs: []any{name},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+//nolint:testpackage // Requires access to internal parser functions
+package snap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dzonerzy/go-snap/internal/intern"
+)
+
+// fuzzApp builds an app exercising every parser path the fuzz targets reach
+func fuzzApp() *App {
+	app := New("fuzz", "")
+	app.BoolFlag("verbose", "").Short('v').Global().Back()
+	app.IntFlag("count", "").Short('c').Back()
+	app.StringSliceFlag("tag", "").Back()
+	app.StringFlag("token", "").AllowFromFile().Back()
+	deploy := app.Command("deploy", "").Alias("d").
+		EnumFlag("env", "", "dev", "prod").Default("dev").Back().
+		DurationFlag("timeout", "").Back().
+		FloatFlag("ratio", "").Back().
+		IntSliceFlag("port", "").Back().
+		StringArg("service", "").Back()
+	deploy.Command("status", "")
+	app.Command("run", "").RestArgs()
+	return app
+}
+
+// splitFuzzArgs turns fuzz input into an argument list
+func splitFuzzArgs(data string) []string {
+	if data == "" {
+		return nil
+	}
+	return strings.Split(data, "\x00")
+}
+
+var fuzzSeeds = []string{
+	"",
+	"--verbose",
+	"-vc\x003",
+	"deploy\x00--env=prod\x00api",
+	"d\x00--timeout\x001m30s\x00--port=80,443\x00--ratio=.5",
+	"deploy\x00status\x00--verbose",
+	"--tag=a,b\x00--tag\x00c",
+	"run\x00--\x00-x\x00--y",
+	"--count=notanumber",
+	"--\xff\xfe",
+	"-\x00--\x00---\x00-=",
+	"--token=@/etc/passwd",
+}
+
+// FuzzParse checks that Parse never panics and results survive a reparse
+func FuzzParse(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	app := fuzzApp()
+	f.Fuzz(func(t *testing.T, data string) {
+		args := splitFuzzArgs(data)
+		if len(args) > 64 {
+			t.Skip()
+		}
+		for i, arg := range args {
+			if strings.HasPrefix(arg, "--token=") {
+				// Keep @file values away from the file system
+				args[i] = "--token=x"
+			}
+		}
+		_, _ = NewParser(app).Parse(args)
+	})
+}
+
+// FuzzParseStrict checks that ParseStrict never panics, returns only
+// structured errors and keeps results stable across later parses
+func FuzzParseStrict(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	app := fuzzApp()
+	f.Fuzz(func(t *testing.T, data string) {
+		p := NewParser(app).SetLimits(ParseLimits{MaxArgs: 32, MaxArgLength: 256, MaxFlagLength: 32})
+		result, err := p.ParseStrict(splitFuzzArgs(data))
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("unstructured error %T: %v", err, err)
+			}
+			if parseErr.Type == ErrorTypeInternal {
+				t.Fatalf("parser panicked: %v", err)
+			}
+			return
+		}
+		args := append([]string(nil), result.Args...)
+		_, _ = p.ParseStrict([]string{"deploy", "--port=1,2", "other"})
+		if strings.Join(result.Args, "\x00") != strings.Join(args, "\x00") {
+			t.Fatalf("result changed by a later parse: %q -> %q", args, result.Args)
+		}
+	})
+}
+
+func TestParseStrictLimits(t *testing.T) {
+	app := fuzzApp()
+	p := NewParser(app).SetLimits(ParseLimits{MaxArgs: 3, MaxArgLength: 16, MaxFlagLength: 8})
+
+	tests := []struct {
+		name  string
+		args  []string
+		typ   ErrorType
+		index int
+	}{
+		{"too many args", []string{"run", "a", "b", "c"}, ErrorTypeLimitExceeded, 3},
+		{"long arg", []string{"deploy", strings.Repeat("x", 17)}, ErrorTypeLimitExceeded, 1},
+		{"long flag", []string{"--verbosity-level"}, ErrorTypeLimitExceeded, 0},
+		{"invalid utf-8", []string{"--\xffv"}, ErrorTypeInvalidFlag, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.ParseStrict(tt.args)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || parseErr.Type != tt.typ || parseErr.Index != tt.index {
+				t.Fatalf("ParseStrict(%q) = %v, want %s at %d", tt.args, err, tt.typ, tt.index)
+			}
+		})
+	}
+
+	// Long values after "--" are positional, not flag names
+	if _, err := p.ParseStrict([]string{"run", "--", "--long-flag-x"}); err != nil {
+		t.Fatalf("terminator: %v", err)
+	}
+	// Parse itself stays unlimited
+	if _, err := p.Parse([]string{"--verbose", "--count=1", "run", "a", "b"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+}
+
+func TestParseStrictDetachesResults(t *testing.T) {
+	app := fuzzApp()
+	p := NewParser(app)
+
+	first, err := p.ParseStrict([]string{"--tag=a,b", "--count=3", "run", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.ParseStrict([]string{"--tag=c", "run", "y", "z"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, _ := first.GetStringSlice("tag")
+	count, _ := first.GetInt("count")
+	if strings.Join(tags, ",") != "a,b" || count != 3 || strings.Join(first.Args, ",") != "x" {
+		t.Fatalf("first result changed: tags=%v count=%d args=%v", tags, count, first.Args)
+	}
+
+	_, err1 := p.ParseStrict([]string{"--nope"})
+	_, err2 := p.ParseStrict([]string{"--other"})
+	if err1 == nil || err2 == nil || err1.Error() == err2.Error() {
+		t.Fatalf("errors share state: %v / %v", err1, err2)
+	}
+}
+
+func TestParseStrictLiteralFileValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	app := fuzzApp()
+
+	result, err := NewParser(app).ParseStrict([]string{"--token=@" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := result.GetString("token"); token != "@"+path {
+		t.Fatalf("strict token = %q, want the literal value", token)
+	}
+
+	result, err = NewParser(app).Parse([]string{"--token=@" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := result.GetString("token"); token != "s3cret" {
+		t.Fatalf("token = %q", token)
+	}
+}
+
+func TestParseStrictDoesNotGrowInterner(t *testing.T) {
+	app := fuzzApp()
+	p := NewParser(app)
+	_, _ = p.ParseStrict([]string{"--warmup"})
+
+	before := intern.GlobalInterner.Stats()
+	for i := range 50 {
+		_, _ = p.ParseStrict([]string{"--unknown-" + time.Duration(i).String()})
+		_, _ = p.ParseStrict([]string{"nosuchcommand" + time.Duration(i).String()})
+	}
+	if after := intern.GlobalInterner.Stats(); after != before {
+		t.Fatalf("interner grew from %d to %d names", before, after)
+	}
+}
@@ -3,7 +3,9 @@ package snap
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +17,7 @@ type SourceType int
 const (
 	SourceTypeDefaults SourceType = iota
 	SourceTypeFile
+	SourceTypeProfile // Selected profile section of the config files
 	SourceTypeEnv
 	SourceTypeFlags
 )
@@ -24,11 +27,17 @@ type ConfigSource struct {
 	Type     SourceType
 	Data     map[string]any
 	Priority int
+
+	// Loaded from the file named on the command line (see FromFlagFile)
+	fromFlag bool
 }
 
 // PrecedenceManager handles configuration precedence and resolution
 type PrecedenceManager struct {
 	sources []ConfigSource
+
+	// Conversions for custom field types (see ConfigBuilder.RegisterDecoder)
+	decoders map[reflect.Type]func(raw any) (any, error)
 }
 
 // NewPrecedenceManager creates a new precedence manager
@@ -48,6 +57,11 @@ func (pm *PrecedenceManager) AddSource(sourceType SourceType, data map[string]an
 	pm.sources = append(pm.sources, source)
 }
 
+// removeSources drops every source of the given type (used when a source is recomputed per run)
+func (pm *PrecedenceManager) removeSources(sourceType SourceType) {
+	pm.sources = slices.DeleteFunc(pm.sources, func(s ConfigSource) bool { return s.Type == sourceType })
+}
+
 // Resolve resolves configuration with proper precedence
 // Returns the final configuration map with highest priority values
 func (pm *PrecedenceManager) Resolve() map[string]any {
@@ -88,14 +102,15 @@ func flattenMap(prefix string, src map[string]any, dst map[string]any) {
 func (pm *PrecedenceManager) mergeWithPrecedence(result, source map[string]any) {
 	for key, value := range source {
 		// Handle nested objects
-		if existingValue, exists := result[key]; exists {
-			if existingMap, ok := existingValue.(map[string]any); ok {
-				if sourceMap, ok2 := value.(map[string]any); ok2 {
-					// Recursively merge nested maps
-					pm.mergeWithPrecedence(existingMap, sourceMap)
-					continue
-				}
+		if sourceMap, ok := value.(map[string]any); ok {
+			// Recursively merge nested maps into a copy so sources are never mutated
+			existingMap, isMap := result[key].(map[string]any)
+			if !isMap {
+				existingMap = make(map[string]any, len(sourceMap))
+				result[key] = existingMap
 			}
+			pm.mergeWithPrecedence(existingMap, sourceMap)
+			continue
 		}
 
 		// Override or set new value
@@ -105,8 +120,11 @@ func (pm *PrecedenceManager) mergeWithPrecedence(result, source map[string]any)
 
 // ResolveWithSchema resolves configuration using schema for validation and type conversion
 func (pm *PrecedenceManager) ResolveWithSchema(schema *ConfigSchema) (map[string]any, error) {
-	// First get the merged configuration
-	config := pm.Resolve()
+	// First get the merged configuration, honoring per-field merge strategies
+	config, err := pm.resolveFields(schema)
+	if err != nil {
+		return nil, err
+	}
 
 	// Validate required fields
 	if err := pm.validateRequired(config, schema); err != nil {
@@ -154,17 +172,16 @@ func (pm *PrecedenceManager) applySchemaDefaults(config map[string]any, schema *
 		//nolint:nestif // Enum validation requires nested checks to provide precise messages.
 		if len(fieldSchema.EnumValues) > 0 {
 			if value, exists := config[fieldName]; exists {
-				valueStr := fmt.Sprintf("%v", value)
-				found := false
-				for _, enumValue := range fieldSchema.EnumValues {
-					if valueStr == enumValue {
-						found = true
-						break
-					}
+				// Multi-value enums validate every element
+				values := []string{fmt.Sprintf("%v", value)}
+				if list, ok := value.([]string); ok {
+					values = list
 				}
-				if !found {
-					return fmt.Errorf("field '%s' must be one of: %s (got '%s')",
-						fieldName, strings.Join(fieldSchema.EnumValues, ", "), valueStr)
+				for _, valueStr := range values {
+					if !slices.Contains(fieldSchema.EnumValues, valueStr) {
+						return fmt.Errorf("field '%s' must be one of: %s (got '%s')",
+							fieldName, strings.Join(fieldSchema.EnumValues, ", "), valueStr)
+					}
 				}
 			}
 		}
@@ -181,6 +198,21 @@ func (pm *PrecedenceManager) convertValueToType(value any, targetType reflect.Ty
 		return value, nil
 	}
 
+	// Registered decoders take any raw value of their type
+	if decode, ok := pm.decoders[targetType]; ok {
+		return decodeValue(decode, value, targetType)
+	}
+
+	// Handle slices (JSON arrays decode to []any, env vars are comma-separated)
+	if targetType.Kind() == reflect.Slice {
+		return pm.convertToSlice(valueReflect, targetType)
+	}
+
+	// Handle maps (JSON objects, env vars as "key=value,key=value")
+	if targetType.Kind() == reflect.Map {
+		return pm.convertToMap(value, targetType)
+	}
+
 	// Handle string conversions (common from JSON/env vars)
 	if valueReflect.Kind() == reflect.String {
 		// Use reflect to safely read string (supports named string types)
@@ -190,12 +222,90 @@ func (pm *PrecedenceManager) convertValueToType(value any, targetType reflect.Ty
 
 	// Handle numeric conversions
 	if valueReflect.Type().ConvertibleTo(targetType) {
+		if integerOverflows(valueReflect, targetType) {
+			return nil, fmt.Errorf("value %v overflows %s", value, targetType)
+		}
 		return valueReflect.Convert(targetType).Interface(), nil
 	}
 
 	return nil, fmt.Errorf("cannot convert %T to %s", value, targetType)
 }
 
+// decodeValue runs a registered decoder and checks that it produced a
+// targetType (a pointer to one is dereferenced)
+func decodeValue(decode func(raw any) (any, error), value any, targetType reflect.Type) (any, error) {
+	decoded, err := decode(value)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(decoded)
+	if v.IsValid() && v.Kind() == reflect.Pointer && targetType.Kind() != reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch {
+	case !v.IsValid():
+		return reflect.Zero(targetType).Interface(), nil
+	case v.Type() == targetType:
+		return v.Interface(), nil
+	case v.Kind() == targetType.Kind() && v.Type().ConvertibleTo(targetType):
+		return v.Convert(targetType).Interface(), nil
+	}
+	return nil, fmt.Errorf("decoder for %s returned %T", targetType, decoded)
+}
+
+// integerOverflows reports whether converting an integer value to an integer
+// targetType would truncate it or change its sign
+func integerOverflows(v reflect.Value, targetType reflect.Type) bool {
+	target := reflect.New(targetType).Elem()
+	switch v.Kind() { //nolint:exhaustive // only integer sources can overflow integer targets
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch targetType.Kind() { //nolint:exhaustive // non-integer targets are not checked
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return target.OverflowInt(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return v.Int() < 0 || target.OverflowUint(uint64(v.Int())) //nolint:gosec // sign checked first
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch targetType.Kind() { //nolint:exhaustive // non-integer targets are not checked
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Uint() > math.MaxInt64 || target.OverflowInt(int64(v.Uint())) //nolint:gosec // range checked first
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return target.OverflowUint(v.Uint())
+		}
+	}
+	return false
+}
+
+// convertToSlice converts a comma-separated string or any slice/array value
+// into a slice of targetType, converting each element individually
+func (pm *PrecedenceManager) convertToSlice(valueReflect reflect.Value, targetType reflect.Type) (any, error) {
+	var elems []any
+	switch valueReflect.Kind() { //nolint:exhaustive // only strings and sequences convert to slices
+	case reflect.String:
+		for _, part := range strings.Split(valueReflect.String(), ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				elems = append(elems, part)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < valueReflect.Len(); i++ {
+			elems = append(elems, valueReflect.Index(i).Interface())
+		}
+	default:
+		return nil, fmt.Errorf("cannot convert %s to %s", valueReflect.Type(), targetType)
+	}
+
+	out := reflect.MakeSlice(targetType, 0, len(elems))
+	for _, elem := range elems {
+		converted, err := pm.convertValueToType(elem, targetType.Elem())
+		if err != nil {
+			return nil, err
+		}
+		out = reflect.Append(out, reflect.ValueOf(converted))
+	}
+	return out.Interface(), nil
+}
+
 // convertStringToType converts string values to specific types
 func (pm *PrecedenceManager) convertStringToType(str string, targetType reflect.Type) (any, error) {
 	switch targetType.Kind() { //nolint:exhaustive // only handle supported conversion targets
@@ -273,10 +383,11 @@ func (pm *PrecedenceManager) parseBoolString(str string) (bool, error) {
 // ConfigurationPrecedence documents the precedence order
 const ConfigurationPrecedence = `
 Configuration Precedence (highest to lowest):
-1. Command line flags        (Priority 3)
-2. Environment variables     (Priority 2)
-3. Configuration files       (Priority 1)
-4. Default values           (Priority 0)
+1. Command line flags        (Priority 4)
+2. Environment variables     (Priority 3)
+3. Selected profile section  (Priority 2)
+4. Configuration files       (Priority 1)
+5. Default values           (Priority 0)
 
 When the same configuration key is found in multiple sources,
 the source with higher precedence wins.
@@ -306,6 +417,8 @@ func (pm *PrecedenceManager) sourceTypeName(sourceType SourceType) string {
 		return "Defaults"
 	case SourceTypeFile:
 		return "Files"
+	case SourceTypeProfile:
+		return "Profile"
 	case SourceTypeEnv:
 		return "Environment"
 	case SourceTypeFlags:
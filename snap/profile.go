@@ -0,0 +1,106 @@
+package snap
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// profilesKey is the top-level config file section holding per-profile overrides:
+//
+//	{"host": "localhost", "profiles": {"prod": {"host": "api.example.com"}}}
+const profilesKey = "profiles"
+
+// Profiles declares the named environments a config file may define under its
+// "profiles" section. The selected profile's section overlays the base file
+// values, below environment variables and flags in the precedence chain.
+func (cb *ConfigBuilder) Profiles(names ...string) *ConfigBuilder {
+	cb.profiles = append(cb.profiles, names...)
+	return cb
+}
+
+// ProfileFlag selects the active profile with a global enum flag (CLI mode)
+// and the given environment variables; without envVars, <APP>_PROFILE is used.
+// In config-only mode only the environment variables are consulted.
+func (cb *ConfigBuilder) ProfileFlag(name string, envVars ...string) *ConfigBuilder {
+	if len(envVars) == 0 {
+		envVars = []string{strings.ToUpper(strings.ReplaceAll(cb.app.name, "-", "_")) + "_PROFILE"}
+	}
+	cb.profileFlag = name
+	cb.profileEnv = envVars
+	return cb
+}
+
+// ActiveProfile returns the profile applied by the last resolution ("" for none)
+func (cb *ConfigBuilder) ActiveProfile() string {
+	return cb.activeProfile
+}
+
+// addProfileFlag registers the profile selector flag on the app (once)
+func (cb *ConfigBuilder) addProfileFlag() {
+	if cb.profileFlag == "" || cb.app == nil {
+		return
+	}
+	if _, exists := cb.app.flags[cb.profileFlag]; exists {
+		return
+	}
+	cb.app.EnumFlag(cb.profileFlag, "Configuration profile", cb.profiles...).
+		FromEnv(cb.profileEnv...).
+		Global()
+}
+
+// selectedProfile returns the requested profile from the flag or environment
+func (cb *ConfigBuilder) selectedProfile() (string, error) {
+	if cb.profileFlag == "" {
+		return "", nil
+	}
+
+	var profile string
+	if cb.app != nil && cb.app.currentResult != nil {
+		profile, _ = cb.app.getEnumFlagValue(cb.profileFlag)
+	} else {
+		for _, env := range cb.profileEnv {
+			if profile = os.Getenv(env); profile != "" {
+				break
+			}
+		}
+	}
+
+	if profile != "" && !slices.Contains(cb.profiles, profile) {
+		return "", fmt.Errorf("unknown profile %q (expected one of: %s)", profile, strings.Join(cb.profiles, ", "))
+	}
+	return profile, nil
+}
+
+// applyProfile replaces the profile overlay source with the selected
+// profile's sections from every loaded config file
+func (cb *ConfigBuilder) applyProfile() error {
+	if cb.profileFlag == "" {
+		return nil
+	}
+	profile, err := cb.selectedProfile()
+	if err != nil {
+		return err
+	}
+	cb.activeProfile = profile
+
+	pm := cb.precedenceManager
+	pm.removeSources(SourceTypeProfile)
+	if profile == "" {
+		return nil
+	}
+
+	overlay := make(map[string]any)
+	for _, source := range pm.sources {
+		if source.Type != SourceTypeFile {
+			continue
+		}
+		sections, _ := source.Data[profilesKey].(map[string]any)
+		if section, ok := sections[profile].(map[string]any); ok {
+			pm.mergeWithPrecedence(overlay, section)
+		}
+	}
+	pm.AddSource(SourceTypeProfile, overlay)
+	return nil
+}
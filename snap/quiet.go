@@ -0,0 +1,174 @@
+package snap
+
+import (
+	"fmt"
+
+	snapio "github.com/dzonerzy/go-snap/io"
+)
+
+// EnableQuietFlag adds the global --quiet (-q when free) flag. In quiet mode
+// the logger only prints warnings and errors, Context.Println and
+// Context.Printf print nothing and errors are shown without the help that
+// ShowHelpOnError adds. Results written to Context.Stdout are unaffected.
+func (a *App) EnableQuietFlag() *App {
+	a.quietFlag = true
+	return a
+}
+
+// EnablePorcelainFlag adds the global --porcelain flag promising stable,
+// machine-readable output: it implies quiet mode, turns colors and the help
+// pager off and reduces errors to their message, without suggestions or
+// position. Actions check Context.Porcelain to switch their own output.
+func (a *App) EnablePorcelainFlag() *App {
+	a.porcelainFlag = true
+	return a
+}
+
+// VerbosityFlags adds the global --verbose (-v when free) flag, counted when
+// repeated, together with --quiet (see EnableQuietFlag). The verbosity sets
+// the logger level: -q prints warnings and errors, no flag everything but
+// debug messages, -v debug messages too and -vv adds timestamps. Actions read
+// it with Context.Verbosity (also stored as the "verbosity" metadata) for
+// their own details, e.g. -vvv for wire dumps.
+func (a *App) VerbosityFlags() *App {
+	a.verbosityFlag = true
+	return a.EnableQuietFlag()
+}
+
+// Verbosity returns -1 for --quiet, 0 by default and the number of -v flags
+// otherwise (see VerbosityFlags)
+func (c *Context) Verbosity() int {
+	if c.App == nil {
+		return 0
+	}
+	if c.IsQuiet() {
+		return -1
+	}
+	return c.App.verbosity
+}
+
+// IsQuiet reports whether --quiet or --porcelain was given
+func (c *Context) IsQuiet() bool {
+	return c.App != nil && (c.App.quiet || c.App.porcelain)
+}
+
+// Porcelain reports whether --porcelain was given
+func (c *Context) Porcelain() bool {
+	return c.App != nil && c.App.porcelain
+}
+
+// Println writes a progress or status line to Stdout unless in quiet mode
+func (c *Context) Println(args ...any) {
+	if !c.IsQuiet() {
+		fmt.Fprintln(c.Stdout(), args...)
+	}
+}
+
+// Printf writes formatted progress or status output to Stdout unless in
+// quiet mode
+func (c *Context) Printf(format string, args ...any) {
+	if !c.IsQuiet() {
+		fmt.Fprintf(c.Stdout(), format, args...)
+	}
+}
+
+// addOutputFlags registers --quiet and --porcelain when enabled
+func (a *App) addOutputFlags() {
+	if _, exists := a.flags["quiet"]; a.quietFlag && !exists {
+		flag := &Flag{
+			Name:          "quiet",
+			Description:   "Only print warnings, errors and results",
+			descriptionID: MsgQuietFlag,
+			Type:          FlagTypeBool,
+			Global:        true,
+		}
+		a.flags["quiet"] = flag
+		if _, taken := a.shortFlags['q']; !taken {
+			flag.Short = 'q'
+			a.shortFlags['q'] = flag
+		}
+		a.invalidateHelp()
+	}
+	if _, exists := a.flags["porcelain"]; a.porcelainFlag && !exists {
+		a.flags["porcelain"] = &Flag{
+			Name:          "porcelain",
+			Description:   "Print stable, machine-readable output",
+			descriptionID: MsgPorcelainFlag,
+			Type:          FlagTypeBool,
+			Global:        true,
+		}
+		a.invalidateHelp()
+	}
+	if _, exists := a.flags["verbose"]; a.verbosityFlag && !exists {
+		flag := &Flag{
+			Name:          "verbose",
+			Description:   "Print more details (-vv adds timestamps)",
+			descriptionID: MsgVerboseFlag,
+			Type:          FlagTypeInt,
+			Global:        true,
+			counter:       true,
+		}
+		a.flags["verbose"] = flag
+		if _, taken := a.shortFlags['v']; !taken {
+			flag.Short = 'v'
+			a.shortFlags['v'] = flag
+		}
+		a.invalidateHelp()
+	}
+}
+
+// scanOutputFlags sets the output mode from the raw arguments, so errors
+// found while parsing them already honour --quiet and --porcelain
+func (a *App) scanOutputFlags(args []string) {
+	a.quiet, a.porcelain = false, false
+	for _, arg := range args {
+		switch {
+		case arg == "--":
+			return
+		case arg == "--quiet" && a.quietFlag:
+			a.quiet = true
+		case arg == "-q" && a.quietFlag && a.shortFlags['q'] == a.flags["quiet"]:
+			a.quiet = true
+		case arg == "--porcelain" && a.porcelainFlag:
+			a.porcelain = true
+		}
+	}
+}
+
+// readOutputFlags sets the output mode from the parse result
+func (a *App) readOutputFlags(result *ParseResult) {
+	a.quiet = a.quietFlag && result.MustGetGlobalBool("quiet", false)
+	a.porcelain = a.porcelainFlag && result.MustGetGlobalBool("porcelain", false)
+	a.verbosity = 0
+	if a.verbosityFlag {
+		a.verbosity = max(result.MustGetGlobalInt("verbose", 0), 0)
+	}
+}
+
+// applyOutputMode gives the context a colorless IO in porcelain mode, a
+// logger printing only warnings and errors in quiet mode and one following
+// the -v count with VerbosityFlags
+func (c *Context) applyOutputMode() {
+	if c.Porcelain() {
+		scoped := c.withIO(c.IO().Clone().NoColor())
+		c.io, c.logger = scoped.io, scoped.logger
+	}
+	if c.IsQuiet() {
+		logger := c.Logger().Clone(nil)
+		c.logger = logger.WithLevel(max(logger.Level(), snapio.LevelWarning))
+	}
+	if c.App.verbosityFlag {
+		c.Set("verbosity", c.Verbosity())
+		switch v := c.Verbosity(); {
+		case v == 0:
+			logger := c.Logger().Clone(nil)
+			c.logger = logger.WithLevel(max(logger.Level(), snapio.LevelInfo))
+		case v > 0:
+			logger := c.Logger().Clone(nil).WithLevel(snapio.LevelDebug)
+			if v >= 2 {
+				logger.WithTimestamp(true)
+			}
+			c.logger = logger
+		}
+	}
+}
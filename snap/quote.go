@@ -0,0 +1,175 @@
+package snap
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+)
+
+// errTrailingBackslash reports a POSIX command line ending in an escape
+var errTrailingBackslash = errors.New("trailing backslash")
+
+// QuoteArgs joins args into a command line for the host platform that
+// SplitCommandLine (and the platform's shell) parse back unchanged, for
+// audit logs, dry-run output and traces. Arguments that need no quoting are
+// left as they are. Windows uses QuoteWindowsArg, other systems
+// QuotePOSIXArg.
+func QuoteArgs(args []string) string {
+	quote := QuotePOSIXArg
+	if runtime.GOOS == "windows" {
+		quote = QuoteWindowsArg
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = quote(arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// QuotePOSIXArg quotes arg for a POSIX shell: arguments made only of
+// letters, digits and -_./:=@%+, are returned as is, everything else is
+// wrapped in single quotes. A single quote inside becomes a closing quote,
+// an escaped quote and an opening quote.
+func QuotePOSIXArg(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, needsPOSIXQuote) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// needsPOSIXQuote reports whether r has a special meaning to POSIX shells
+func needsPOSIXQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./:=@%+,", r)
+}
+
+// SplitCommandLine splits s into arguments by the host platform's rules,
+// the inverse of QuoteArgs: SplitWindowsCommandLine on Windows,
+// SplitPOSIXCommandLine elsewhere.
+func SplitCommandLine(s string) ([]string, error) {
+	if runtime.GOOS == "windows" {
+		return SplitWindowsCommandLine(s), nil
+	}
+	return SplitPOSIXCommandLine(s)
+}
+
+// SplitPOSIXCommandLine splits s like a POSIX shell does, without any
+// expansion: whitespace separates arguments, single quotes keep text
+// literal, double quotes allow \", \\, \$ and \` escapes, and outside
+// quotes a backslash escapes any character (backslash-newline joins lines).
+// Unterminated quotes and a trailing backslash are errors.
+func SplitPOSIXCommandLine(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+			switch {
+			case quote == '"' && !strings.ContainsRune("$`\"\\\n", r):
+				// Inside double quotes other backslashes are literal
+				cur.WriteRune('\\')
+				cur.WriteRune(r)
+			case r != '\n':
+				cur.WriteRune(r)
+				inArg = true
+			}
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errUnterminatedQuote
+	}
+	if escaped {
+		return nil, errTrailingBackslash
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// SplitWindowsCommandLine splits s like CommandLineToArgvW and the MSVC
+// runtime, the inverse of QuoteWindowsArg: whitespace separates arguments
+// outside double quotes, backslashes are literal unless they precede a
+// quote, and "" inside quotes is a literal quote.
+func SplitWindowsCommandLine(s string) []string {
+	var args []string
+	var cur strings.Builder
+	inArg, inQuote := false, false
+	slashes := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' {
+			slashes++
+			inArg = true
+			continue
+		}
+		if c == '"' {
+			cur.WriteString(strings.Repeat(`\`, slashes/2))
+			odd := slashes%2 == 1
+			slashes = 0
+			inArg = true
+			switch {
+			case odd:
+				cur.WriteByte('"')
+			case inQuote && i+1 < len(s) && s[i+1] == '"':
+				cur.WriteByte('"')
+				i++
+			default:
+				inQuote = !inQuote
+			}
+			continue
+		}
+		cur.WriteString(strings.Repeat(`\`, slashes))
+		slashes = 0
+		if (c == ' ' || c == '\t') && !inQuote {
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+			continue
+		}
+		cur.WriteByte(c)
+		inArg = true
+	}
+	cur.WriteString(strings.Repeat(`\`, slashes))
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
@@ -2,12 +2,29 @@
 package snap
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	snapio "github.com/dzonerzy/go-snap/io"
+	"github.com/dzonerzy/go-snap/middleware"
+	"github.com/dzonerzy/go-snap/snapcreds"
 )
 
 // TestComprehensiveFlagTypes tests all implemented flag types with zero allocations
@@ -567,6 +584,113 @@ func TestExitCodes_Minimal(t *testing.T) {
 	}
 }
 
+// Sysexits preset and documentation table
+func TestExitCodes_SysexitsAndTable(t *testing.T) {
+	app := New("t", "")
+	mgr := app.ExitCodes().UseSysexits().
+		Define("config_missing", SysexitConfig).
+		Describe(SysexitConfig, "Configuration file not found")
+
+	if code := mgr.resolve(NewError(ErrorTypeUnknownFlag, "")); code != SysexitUsage {
+		t.Fatalf("expected usage=%d got %d", SysexitUsage, code)
+	}
+	if code := mgr.resolve(NewError(ErrorTypeValidation, "")); code != SysexitDataErr {
+		t.Fatalf("expected dataerr=%d got %d", SysexitDataErr, code)
+	}
+
+	table := mgr.Table()
+	for i := 1; i < len(table); i++ {
+		if table[i-1].Code >= table[i].Code {
+			t.Fatalf("table not sorted by code: %+v", table)
+		}
+	}
+	var found bool
+	for _, entry := range table {
+		if entry.Code == SysexitConfig {
+			found = true
+			if entry.Description != "Configuration file not found" || len(entry.Names) != 1 ||
+				entry.Names[0] != "config_missing" {
+				t.Fatalf("unexpected config entry: %+v", entry)
+			}
+		}
+		if entry.Code == SysexitUsage && entry.Description == "" {
+			t.Fatalf("expected built-in description for usage code")
+		}
+	}
+	if !found {
+		t.Fatalf("expected config_missing in table: %+v", table)
+	}
+}
+
+// Every code an error can resolve to is documented
+func TestExitCodes_TableListsMappedCodes(t *testing.T) {
+	mgr := New("t", "").ExitCodes().UseSysexits()
+	listed := map[int][]string{}
+	for _, entry := range mgr.Table() {
+		listed[entry.Code] = entry.Names
+		if entry.Description == "" {
+			t.Errorf("code %d has no description", entry.Code)
+		}
+	}
+	inUse := []int{mgr.defaults.Success, mgr.defaults.GeneralError}
+	for _, code := range mgr.codesByCLI {
+		inUse = append(inUse, code)
+	}
+	for _, code := range mgr.codesByType {
+		inUse = append(inUse, code)
+	}
+	for _, s := range mgr.codesBySentinel {
+		inUse = append(inUse, s.code)
+	}
+	for _, code := range inUse {
+		if _, ok := listed[code]; !ok {
+			t.Errorf("code %d in use but not listed", code)
+		}
+	}
+	if !slices.Contains(listed[SysexitSoftware], "internal_error") ||
+		!slices.Contains(listed[SysexitSoftware], "crash_error") ||
+		!slices.Contains(listed[SysexitTempFail], "timeout_error") ||
+		!slices.Contains(listed[SysexitNoInput], "wrapped_binary_not_found") {
+		t.Fatalf("table names: %v", listed)
+	}
+}
+
+// Wrapped errors map through sentinels, types and matchers
+func TestExitCodes_WrappedErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+	mgr := New("t", "").ExitCodes().
+		DefineError(errNotFound, 1).
+		DefineError(errNotFound, 127).
+		DefineError(errLocked{}, 75).
+		DefineError(&os.PathError{}, 5).
+		DefineErrorFunc(func(err error) (int, bool) {
+			var pathErr *os.PathError
+			if errors.As(err, &pathErr) && pathErr.Op == "open" {
+				return 66, true
+			}
+			return 0, false
+		})
+
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{errNotFound, 127},
+		{fmt.Errorf("load config: %w", errNotFound), 127},
+		{fmt.Errorf("retry: %w", fmt.Errorf("load: %w", errNotFound)), 127},
+		{errors.Join(errors.New("first"), errNotFound), 127},
+		{errors.New("not found"), 1}, // Equal text, other error
+		{fmt.Errorf("sync: %w", errLocked{}), 75},
+		{fmt.Errorf("read: %w", &os.PathError{Op: "open", Err: errNotFound}), 66},
+		{&os.PathError{Op: "stat", Err: errors.New("denied")}, 5},
+		{&ExitError{Code: 9, Err: errNotFound}, 9},
+	} {
+		if code := mgr.resolve(tc.err); code != tc.want {
+			t.Errorf("%v: exit code %d, want %d", tc.err, code, tc.want)
+		}
+	}
+}
+
 // Error display should include group help context for flag group violations
 func TestErrorDisplay_GroupViolation_ShowsGroupHelp(t *testing.T) {
 	app := New("x", "")
@@ -715,6 +839,195 @@ func TestConfig_EnumAndSlices_Collected(t *testing.T) {
 	}
 }
 
+// Float, duration and enum slices parse, accumulate on repeat and bind into config
+func TestFloatDurationEnumSliceFlags(t *testing.T) {
+	app := New("app", "")
+	app.FloatSliceFlag("weights", "").Back().
+		DurationSliceFlag("backoff", "").Default([]time.Duration{time.Second}).Back().
+		EnumSliceFlag("feature", "", "a", "b", "c").Back()
+
+	parser := NewParser(app)
+	res, err := parser.Parse([]string{"--weights", "0.5,1.5", "--feature", "a", "--feature", "c"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if w, ok := res.GetFloatSlice("weights"); !ok || len(w) != 2 || w[0] != 0.5 || w[1] != 1.5 {
+		t.Fatalf("weights: %#v", w)
+	}
+	if b := res.MustGetDurationSlice("backoff", nil); len(b) != 1 || b[0] != time.Second {
+		t.Fatalf("backoff default: %#v", b)
+	}
+	if f, ok := res.GetEnumSlice("feature"); !ok || len(f) != 2 || f[0] != "a" || f[1] != "c" {
+		t.Fatalf("feature: %#v", f)
+	}
+
+	if _, err = NewParser(app).Parse([]string{"--feature", "a,z"}); err == nil {
+		t.Fatalf("expected invalid enum slice value error")
+	}
+
+	// Repeated string and int slices keep the last occurrence
+	app.StringSliceFlag("tag", "").Back().IntSliceFlag("port", "")
+	res, err = NewParser(app).Parse([]string{"--tag", "a", "--tag", "b,c", "--port", "1", "--port", "2"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if tags, _ := res.GetStringSlice("tag"); len(tags) != 2 || tags[0] != "b" {
+		t.Fatalf("tag: %#v", tags)
+	}
+	if ports, _ := res.GetIntSlice("port"); len(ports) != 1 || ports[0] != 2 {
+		t.Fatalf("port: %#v", ports)
+	}
+}
+
+func TestConfig_FloatDurationEnumSlices(t *testing.T) {
+	type C struct {
+		Weights  []float64       `flag:"weights"`
+		Backoff  []time.Duration `flag:"backoff"  default:"1s,2s"`
+		Features []string        `flag:"features" enum:"x,y,z"`
+	}
+	var cfg C
+	app, err := Config("app", "").FromFlags().Bind(&cfg).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	res, err := NewParser(app).Parse([]string{"--weights", "1.5", "--features", "x,z"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	app.currentResult = res
+	if pErr := app.populateConfiguration(); pErr != nil {
+		t.Fatalf("populate: %v", pErr)
+	}
+	if len(cfg.Weights) != 1 || cfg.Weights[0] != 1.5 {
+		t.Fatalf("weights: %#v", cfg.Weights)
+	}
+	if len(cfg.Backoff) != 2 || cfg.Backoff[1] != 2*time.Second {
+		t.Fatalf("backoff: %#v", cfg.Backoff)
+	}
+	if len(cfg.Features) != 2 || cfg.Features[1] != "z" {
+		t.Fatalf("features: %#v", cfg.Features)
+	}
+}
+
+func TestSizedIntegerFlags(t *testing.T) {
+	app := New("t", "")
+	app.Int64Flag("big", "").Back()
+	app.Int32Flag("small", "").Default(7).Back()
+	app.UintFlag("mask", "").Back()
+	app.Uint64Flag("id", "").Back()
+	res, err := NewParser(app).Parse([]string{
+		"--big", "-9223372036854775808", "--mask", "0o755", "--id", "0xFFFFFFFFFFFFFFFF",
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if v, _ := res.GetInt64("big"); v != math.MinInt64 {
+		t.Fatalf("big: %d", v)
+	}
+	if v := res.MustGetInt32("small", 0); v != 7 {
+		t.Fatalf("small default: %d", v)
+	}
+	if v, _ := res.GetUint("mask"); v != 0o755 {
+		t.Fatalf("mask: %o", v)
+	}
+	if v, _ := res.GetUint64("id"); v != math.MaxUint64 {
+		t.Fatalf("id: %d", v)
+	}
+
+	res, err = NewParser(app).Parse([]string{"--small", "0b101", "--mask", "+10"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if v, _ := res.GetInt32("small"); v != 5 {
+		t.Fatalf("small binary: %d", v)
+	}
+	if v, _ := res.GetUint("mask"); v != 10 {
+		t.Fatalf("mask: %d", v)
+	}
+
+	for _, args := range [][]string{
+		{"--small", "2147483648"},
+		{"--small", "-2147483649"},
+		{"--mask", "-1"},
+		{"--id", "18446744073709551616"},
+		{"--id", "0b102"},
+	} {
+		if _, err := NewParser(app).Parse(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+// Validate functions run on command-line and environment values, not defaults
+func TestFlagValidators(t *testing.T) {
+	app := New("t", "")
+	Range(app.IntFlag("workers", ""), 1, 8).Back()
+	Range(app.Int32Flag("level", "").Default(99).FromEnv("T_LEVEL"), 0, 9).Back()
+	Range(app.UintFlag("port", ""), 1024, 65535).Back()
+	OneOf(app.StringFlag("mode", ""), "fast", "slow").Back()
+
+	if _, err := NewParser(app).Parse([]string{"--workers", "4", "--port", "8080", "--mode", "fast"}); err != nil {
+		t.Fatalf("valid values: %v", err)
+	}
+	for _, tc := range []struct {
+		args []string
+		env  string
+		flag string
+	}{
+		{[]string{"--workers", "9"}, "", "workers"},
+		{[]string{"--port", "80"}, "", "port"},
+		{[]string{"--mode", "medium"}, "", "mode"},
+		{nil, "10", "level"},
+	} {
+		t.Setenv("T_LEVEL", tc.env)
+		_, err := NewParser(app).Parse(tc.args)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Type != ErrorTypeInvalidValue || parseErr.Flag != tc.flag {
+			t.Errorf("%q (T_LEVEL=%q): got %v", tc.args, tc.env, err)
+		}
+	}
+	t.Setenv("T_LEVEL", "10")
+	_, err := NewParser(app).Parse(nil)
+	if err == nil || err.Error() != "invalid value for --level: value 10 is not within range [0, 9]" {
+		t.Errorf("message: %v", err)
+	}
+}
+
+func TestConfig_SizedIntegerFields(t *testing.T) {
+	type C struct {
+		Offset int64  `flag:"offset"`
+		Level  int32  `flag:"level"  default:"3"`
+		Port   uint16 `flag:"port"`
+		Limit  uint64 `flag:"limit"`
+	}
+	var cfg C
+	app, err := Config("app", "").FromFlags().Bind(&cfg).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	res, err := NewParser(app).Parse([]string{"--offset", "-5", "--port", "0x1F90", "--limit", "1"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	app.currentResult = res
+	if pErr := app.populateConfiguration(); pErr != nil {
+		t.Fatalf("populate: %v", pErr)
+	}
+	if cfg.Offset != -5 || cfg.Level != 3 || cfg.Port != 8080 || cfg.Limit != 1 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	// Values that do not fit the field kind are rejected rather than truncated
+	res, err = NewParser(app).Parse([]string{"--port", "70000"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	app.currentResult = res
+	if pErr := app.populateConfiguration(); pErr == nil {
+		t.Fatalf("expected overflow error for uint16 field")
+	}
+}
+
 // IO integration: writing via ctx.Stdout goes to configured writer
 func TestIO_Integration_Write(t *testing.T) {
 	var buf strings.Builder
@@ -1184,3 +1497,3889 @@ func TestNestedCommandBeforeAfter(t *testing.T) {
 		}
 	}
 }
+
+// TestContextTypedMetadata tests generic metadata helpers and namespaced keys
+func TestContextTypedMetadata(t *testing.T) {
+	ctx := &Context{}
+
+	CtxSet(ctx, "count", 42)
+	if v, ok := CtxGet[int](ctx, "count"); !ok || v != 42 {
+		t.Fatalf("expected count=42, got %v (ok=%v)", v, ok)
+	}
+	if _, ok := CtxGet[string](ctx, "count"); ok {
+		t.Fatalf("expected type mismatch to report ok=false")
+	}
+	if v := CtxMustGet(ctx, "missing", "fallback"); v != "fallback" {
+		t.Fatalf("expected fallback, got %q", v)
+	}
+
+	userKey := NewKey[string]("auth", "user")
+	ctx.Set("user", "plain")
+	userKey.Set(ctx, "alice")
+	if v, ok := userKey.Get(ctx); !ok || v != "alice" {
+		t.Fatalf("expected namespaced user=alice, got %q", v)
+	}
+	if v := ctx.Get("user"); v != "plain" {
+		t.Fatalf("namespaced key collided with plain key: %v", v)
+	}
+	if userKey.String() != "auth:user" {
+		t.Fatalf("unexpected key name %q", userKey.String())
+	}
+}
+
+// TestContextValueSource tests IsSet/IsDefault/Source detection
+func TestContextValueSource(t *testing.T) {
+	t.Setenv("SNAP_TEST_HOST", "example.com")
+
+	app := New("myapp", "Test app")
+	app.IntFlag("port", "Port").Default(8080).Back().
+		StringFlag("host", "Host").FromEnv("SNAP_TEST_HOST").Back().
+		BoolFlag("verbose", "Verbose").Back().
+		StringFlag("name", "Name").Back()
+
+	var port, host, verbose, name ValueSource
+	var portSet, verboseDefault bool
+	app.Action(func(ctx *Context) error {
+		port = ctx.Source("port")
+		host = ctx.Source("host")
+		verbose = ctx.Source("verbose")
+		name = ctx.Source("name")
+		portSet = ctx.IsSet("port")
+		verboseDefault = ctx.IsDefault("verbose")
+		return nil
+	})
+
+	if err := app.RunWithArgs(context.Background(), []string{"--port", "8080"}); err != nil {
+		t.Fatalf("RunWithArgs failed: %v", err)
+	}
+	if port != ValueSourceFlag || !portSet {
+		t.Errorf("expected port from flag, got %s", port)
+	}
+	if host != ValueSourceEnv {
+		t.Errorf("expected host from env, got %s", host)
+	}
+	if verbose != ValueSourceDefault || !verboseDefault {
+		t.Errorf("expected verbose from default, got %s", verbose)
+	}
+	if name != ValueSourceNone {
+		t.Errorf("expected name unset, got %s", name)
+	}
+}
+
+func TestValueSourceInvalidEnv(t *testing.T) {
+	t.Setenv("SNAP_TEST_WORKERS", "many")
+	t.Setenv("SNAP_TEST_RETRIES", "3")
+
+	app := New("myapp", "")
+	app.IntFlag("workers", "").Default(4).FromEnv("SNAP_TEST_WORKERS").Back().
+		IntFlag("retries", "").Default(1).FromEnv("SNAP_TEST_RETRIES").Back().
+		DurationFlag("timeout", "").FromEnv("SNAP_TEST_WORKERS")
+
+	result, err := NewParser(app).Parse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An unparsable environment value falls back to the default
+	if got, src := result.MustGetInt("workers", 0), result.Source("workers"); got != 4 || src != ValueSourceDefault {
+		t.Errorf("workers = %d from %s, want 4 from default", got, src)
+	}
+	if got, src := result.MustGetInt("retries", 0), result.Source("retries"); got != 3 || src != ValueSourceEnv {
+		t.Errorf("retries = %d from %s, want 3 from env", got, src)
+	}
+	if src := result.Source("timeout"); src != ValueSourceNone {
+		t.Errorf("timeout from %s, want none", src)
+	}
+}
+
+func TestAbbreviations(t *testing.T) {
+	newApp := func() *App {
+		app := New("t", "").AllowAbbreviations(true)
+		app.BoolFlag("verbose", "").Back()
+		app.BoolFlag("version-check", "").Back()
+		app.IntFlag("port", "").Back()
+		app.Command("status", "").Build()
+		app.Command("start", "").Build()
+		app.Command("server", "").Command("stop", "").Build()
+		return app
+	}
+
+	res, err := NewParser(newApp()).Parse([]string{"--verb", "--po=8080", "stat"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !res.MustGetBool("verbose", false) || res.MustGetInt("port", 0) != 8080 {
+		t.Fatalf("abbreviated flags not resolved")
+	}
+	if res.Command == nil || res.Command.Name() != "status" {
+		t.Fatalf("expected status command, got %v", res.Command)
+	}
+
+	res, err = NewParser(newApp()).Parse([]string{"serv", "sto"})
+	if err != nil || res.Command == nil || res.Command.Name() != "stop" {
+		t.Fatalf("expected nested abbreviation to resolve to stop, got %v (%v)", res.Command, err)
+	}
+
+	_, err = NewParser(newApp()).Parse([]string{"--ver"})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Type != ErrorTypeAmbiguousFlag ||
+		strings.Join(perr.Candidates, ",") != "verbose,version-check" {
+		t.Fatalf("expected ambiguous flag error, got %v", err)
+	}
+
+	app := newApp()
+	app.ioManager.WithErr(&strings.Builder{})
+	err = app.RunWithArgs(context.Background(), []string{"sta"})
+	if err == nil || !strings.Contains(err.Error(), "'start', 'status'") {
+		t.Fatalf("expected candidates in error, got %v", err)
+	}
+
+	// Disabled by default
+	app = New("t", "")
+	app.BoolFlag("verbose", "").Back()
+	if _, err = NewParser(app).Parse([]string{"--verb"}); err == nil {
+		t.Fatalf("expected unknown flag without AllowAbbreviations")
+	}
+}
+
+func TestSuggestionsIncludePathsAliasesAndShortFlags(t *testing.T) {
+	newApp := func() *App {
+		app := New("t", "")
+		app.ErrorHandler().SuggestFlags(true).SuggestCommands(true)
+		app.ioManager.WithErr(&strings.Builder{})
+		app.IntFlag("port", "").Back()
+		app.StringFlag("proto", "").Back()
+		app.BoolFlag("quiet", "").Short('q').Back()
+		srv := app.Command("server", "").Build()
+		srv.Command("status", "").Build()
+		app.Command("remove", "").Alias("rm").Build()
+		return app
+	}
+
+	err := newApp().RunWithArgs(context.Background(), []string{"server", "sttaus"})
+	if err == nil || !strings.Contains(err.Error(), "Did you mean 'server status'?") {
+		t.Fatalf("expected path suggestion, got %v", err)
+	}
+
+	err = newApp().RunWithArgs(context.Background(), []string{"--prot"})
+	if err == nil || !strings.Contains(err.Error(), "Did you mean:\n    --proto\n    --port") {
+		t.Fatalf("expected ranked flag list, got %v", err)
+	}
+
+	err = newApp().RunWithArgs(context.Background(), []string{"--qt"})
+	if err == nil || !strings.Contains(err.Error(), "'-q'") {
+		t.Fatalf("expected short flag suggestion, got %v", err)
+	}
+
+	err = newApp().RunWithArgs(context.Background(), []string{"rn"})
+	if err == nil || !strings.Contains(err.Error(), "'rm'") {
+		t.Fatalf("expected alias suggestion, got %v", err)
+	}
+
+	// Names of nested commands suggest their full path, exact names first
+	for _, typed := range []string{"status", "stauts"} {
+		err = newApp().RunWithArgs(context.Background(), []string{typed})
+		if err == nil || !strings.Contains(err.Error(), "Did you mean 'server status'?") {
+			t.Fatalf("%s: expected nested suggestion, got %v", typed, err)
+		}
+	}
+	app := newApp()
+	app.Command("db", "").Command("status", "").Build()
+	deep := app.Command("cluster", "").Command("node", "").Command("pool", "")
+	deep.Command("drain", "").Build()
+	err = app.RunWithArgs(context.Background(), []string{"status"})
+	if err == nil || !strings.Contains(err.Error(), "Did you mean:\n    db status\n    server status") {
+		t.Fatalf("expected every nested match, got %v", err)
+	}
+	err = app.RunWithArgs(context.Background(), []string{"pool"})
+	if err == nil || !strings.Contains(err.Error(), "'cluster node pool'") {
+		t.Fatalf("expected third-level suggestion, got %v", err)
+	}
+	err = app.RunWithArgs(context.Background(), []string{"drain"})
+	if err == nil || strings.Contains(err.Error(), "drain'") {
+		t.Fatalf("suggestions must stop at three levels, got %v", err)
+	}
+
+	// Aliases resolve to their command
+	res, err := NewParser(newApp()).Parse([]string{"rm"})
+	if err != nil || res.Command == nil || res.Command.Name() != "remove" {
+		t.Fatalf("expected alias to resolve to remove, got %v", err)
+	}
+}
+
+func TestPassthroughArgsAfterTerminator(t *testing.T) {
+	app := New("t", "")
+	var own, passthrough []string
+	app.Command("run", "").
+		StringArg("target", "").Back().
+		BoolFlag("watch", "").Back().
+		Action(func(ctx *Context) error {
+			own = ctx.Args()
+			passthrough = ctx.PassthroughArgs()
+			return nil
+		})
+
+	if err := app.RunWithArgs(context.Background(), []string{"run", "dev", "--watch", "--", "go", "test", "-v"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if strings.Join(own, " ") != "dev go test -v" {
+		t.Fatalf("args: %v", own)
+	}
+	if strings.Join(passthrough, " ") != "go test -v" {
+		t.Fatalf("passthrough: %v", passthrough)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"run", "dev"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if passthrough != nil {
+		t.Fatalf("expected nil passthrough without terminator, got %v", passthrough)
+	}
+
+	res, err := NewParser(app).Parse([]string{"run", "--"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if rest := res.ArgsAfterTerminator(); rest == nil || len(rest) != 0 {
+		t.Fatalf("expected empty non-nil passthrough, got %#v", rest)
+	}
+}
+
+func TestHelpCacheVisibleIf(t *testing.T) {
+	var buf strings.Builder
+	shown := false
+	app := New("t", "")
+	app.IO().WithOut(&buf)
+	app.Command("admin", "admin tools").VisibleIf(func() bool { return shown })
+	app.BoolFlag("trace", "trace calls").VisibleIf(func() bool { return shown })
+
+	_ = app.showHelp()
+	if strings.Contains(buf.String(), "admin") || strings.Contains(buf.String(), "--trace") {
+		t.Fatalf("hidden entries in help:\n%s", buf.String())
+	}
+
+	shown = true
+	buf.Reset()
+	_ = app.showHelp()
+	if !strings.Contains(buf.String(), "admin") || !strings.Contains(buf.String(), "--trace") {
+		t.Fatalf("predicate not re-evaluated:\n%s", buf.String())
+	}
+}
+
+func TestHelpCacheInvalidatedOnRegistration(t *testing.T) {
+	var buf strings.Builder
+	app := New("t", "")
+	app.IO().WithOut(&buf)
+	app.Command("zeta", "last").Build()
+	app.Command("alpha", "first").Build()
+
+	if err := app.showHelp(); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	first := buf.String()
+	if strings.Index(first, "alpha") > strings.Index(first, "zeta") {
+		t.Fatalf("commands not sorted:\n%s", first)
+	}
+	if app.helpCache == nil {
+		t.Fatalf("expected help to be cached")
+	}
+
+	buf.Reset()
+	_ = app.showHelp()
+	if buf.String() != first {
+		t.Fatalf("cached help differs")
+	}
+
+	app.Command("beta", "middle").Alias("b").Build()
+	if app.helpCache != nil {
+		t.Fatalf("expected registration to invalidate the help cache")
+	}
+	buf.Reset()
+	_ = app.showHelp()
+	if !strings.Contains(buf.String(), "beta") || !strings.Contains(buf.String(), "aliases: b") {
+		t.Fatalf("expected new command in help:\n%s", buf.String())
+	}
+}
+
+func TestHelpCacheFollowsIOChanges(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	var buf strings.Builder
+	app := New("t", "")
+	app.IO().WithOut(&buf).NoColor()
+	app.Command("deploy", "Deploy it").Build()
+
+	_ = app.showHelp()
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("unexpected colors:\n%s", buf.String())
+	}
+	app.IO().ForceColor()
+	buf.Reset()
+	_ = app.showHelp()
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("cached help ignored ForceColor:\n%s", buf.String())
+	}
+
+	var other strings.Builder
+	app.IO().WithOut(&other)
+	cached := app.helpCacheKey
+	_ = app.showHelp()
+	if app.helpCacheKey == cached || other.String() != buf.String() {
+		t.Fatalf("help not re-rendered for the new output")
+	}
+}
+
+func TestAppValidate(t *testing.T) {
+	app := New("t", "")
+	app.BoolFlag("verbose", "").Short('v').Global().Back()
+	app.EnumFlag("mode", "", "fast", "slow").Default("medium").Back()
+	app.StringArg("opt", "").Back().StringArg("req", "").Required().Back()
+	app.Command("serve", "").Alias("s").
+		StringFlag("verbose", "").Back().
+		BoolFlag("version-check", "").Short('v').Back().
+		StringSliceArg("files", "").Variadic().
+		StringArg("last", "").Back()
+	app.Command("status", "").Alias("s")
+
+	err := app.Validate()
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+	for _, want := range []string{
+		`app: default "medium" of --mode is not one of [fast slow]`,
+		"app: required argument <req> follows optional argument [opt]",
+		"command serve: flag --verbose shadows global flag --verbose",
+		"command serve: short flag -v of --version-check collides with global flag --verbose",
+		"command serve: variadic argument <files> must be the last argument",
+		`command status: alias "s" collides with "serve"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+
+	clean := New("t", "")
+	clean.BoolFlag("verbose", "").Short('v').Global().Back()
+	clean.FlagGroup("output").StringFlag("format", "").Back().BoolFlag("json", "").Back().MutuallyExclusive().EndGroup()
+	clean.Command("serve", "").BoolFlag("force", "").Short('h').Back().
+		FlagGroup("mode").BoolFlag("dev", "").Back().BoolFlag("prod", "").Back().ExactlyOne().EndGroup()
+	if err := clean.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected MustValidate to panic")
+		}
+	}()
+	app.MustValidate()
+}
+
+func TestArgFlagConstraints(t *testing.T) {
+	newApp := func() *App {
+		app := New("t", "")
+		app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+		rm := app.Command("rm", "").
+			BoolFlag("all", "").FromEnv("RM_ALL").Back().
+			BoolFlag("stdin", "").Back().
+			BoolFlag("force", "").Default(true).Back()
+		rm.StringSliceArg("files", "").ConflictsWithFlag("all", "force").Variadic()
+		rm.RequireArgsOrFlag("files", "all", "stdin").
+			Action(func(*Context) error { return nil })
+		return app
+	}
+
+	for _, args := range [][]string{
+		{"rm", "a", "b"},
+		{"rm", "--all"},
+		{"rm", "--stdin"},
+		{"rm", "--help"},
+		{"rm", "--all=false", "a"},
+	} {
+		if err := newApp().RunWithArgs(context.Background(), args); err != nil {
+			t.Errorf("%q: %v", args, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		env  string
+		want string
+	}{
+		{[]string{"rm"}, "", "either argument files or --all, --stdin is required"},
+		{[]string{"rm", "a", "--all"}, "", "argument files cannot be used with --all"},
+		{[]string{"rm", "--force", "a"}, "", "argument files cannot be used with --force"},
+		{[]string{"rm", "a"}, "1", "argument files cannot be used with --all"},
+	} {
+		t.Setenv("RM_ALL", tc.env)
+		err := newApp().RunWithArgs(context.Background(), tc.args)
+		var cliErr *CLIError
+		if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeInvalidArgument || cliErr.Message != tc.want {
+			t.Errorf("%q (RM_ALL=%q): got %v, want %q", tc.args, tc.env, err, tc.want)
+		}
+	}
+	t.Setenv("RM_ALL", "")
+
+	// Messages follow the app locale
+	app := newApp().SetLocale("de")
+	err := app.RunWithArgs(context.Background(), []string{"rm"})
+	if err == nil || !strings.Contains(err.Error(), "entweder Argument files oder --all, --stdin ist erforderlich") {
+		t.Fatalf("localized error: %v", err)
+	}
+
+	if err := newApp().Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	bad := New("t", "")
+	bad.StringArg("target", "").Required().ConflictsWithFlag("nope").Back().
+		RequireArgsOrFlag("target", "all").
+		RequireArgsOrFlag("source")
+	err = bad.Validate()
+	for _, want := range []string{
+		"app: argument <target> conflicts with unregistered flag --nope",
+		"app: RequireArgsOrFlag needs argument <target> to be optional",
+		"app: RequireArgsOrFlag references unregistered flag --all",
+		"app: RequireArgsOrFlag references unregistered argument <source>",
+		"app: RequireArgsOrFlag for argument <source> names no flags",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestArgCounts(t *testing.T) {
+	newApp := func() *App {
+		app := New("t", "")
+		app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+		app.Command("merge", "").
+			StringSliceArg("files", "").Required().MinCount(2).MaxCount(3).Variadic()
+		app.Command("tag", "").
+			IntSliceArg("ids", "").MinCount(2).Variadic()
+		app.Command("exec", "").
+			StringArg("host", "").Required().Back().
+			MinRestArgs(1).MaxRestArgs(2)
+		return app
+	}
+
+	for _, args := range [][]string{
+		{"merge", "a", "b"},
+		{"merge", "a", "b", "c"},
+		{"tag"}, // Optional: left out entirely
+		{"tag", "1", "2"},
+		{"exec", "host", "ls"},
+		{"exec", "host", "ls", "-l"},
+		{"merge", "--help"},
+		{"exec", "host", "--help"},
+	} {
+		if _, err := newApp().Parse(args); err != nil {
+			t.Errorf("%q: %v", args, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"merge", "a"}, "too few values for argument files: got 1, expected at least 2"},
+		{[]string{"merge", "a", "b", "c", "d"}, "too many values for argument files: got 4, expected at most 3"},
+		{[]string{"tag", "1"}, "too few values for argument ids: got 1, expected at least 2"},
+		{[]string{"exec", "host"}, "too few extra arguments: got 0, expected at least 1"},
+		{[]string{"exec", "host", "a", "b", "c"}, "too many extra arguments: got 3, expected at most 2"},
+	} {
+		_, err := newApp().Parse(tc.args)
+		var cliErr *CLIError
+		if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeInvalidArgument || cliErr.Message != tc.want {
+			t.Errorf("%q: got %v, want %q", tc.args, err, tc.want)
+		}
+	}
+
+	bad := New("t", "").MinRestArgs(3).MaxRestArgs(1)
+	bad.StringArg("name", "").MinCount(1).Back().
+		StringSliceArg("rest", "").MinCount(4).MaxCount(2).Variadic()
+	err := bad.Validate()
+	for _, want := range []string{
+		"app: MinRestArgs 3 is above MaxRestArgs 1",
+		"app: argument <name> sets MinCount or MaxCount but is not variadic",
+		"app: argument <rest> has MinCount 4 above MaxCount 2",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestTypedArgs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newApp := func() *App {
+		app := New("t", "")
+		app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+		app.Command("service", "").
+			EnumArg("action", "", "start", "stop").Required().Back().
+			FileArg("config", "").Back().
+			DirArg("root", "").MustExist()
+		app.Command("open", "").
+			FileArg("path", "").MustExist()
+		return app
+	}
+
+	result, err := newApp().Parse([]string{"service", "stop", "missing.yaml", dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action, _ := result.GetArgString("action"); action != "stop" {
+		t.Errorf("action = %q", action)
+	}
+	if _, err := newApp().Parse([]string{"open", file}); err != nil {
+		t.Errorf("existing file: %v", err)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"service", "restart"}, "invalid value for argument action: restart, valid values: start, stop"},
+		{[]string{"service", "start", "c.yaml", file}, "argument root: " + file + " is not a directory"},
+		{[]string{"open", filepath.Join(dir, "gone")}, "argument path: " + filepath.Join(dir, "gone") + " does not exist"},
+	} {
+		_, err := newApp().Parse(tc.args)
+		var cliErr *CLIError
+		if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeInvalidArgument || cliErr.Message != tc.want {
+			t.Errorf("%q: got %v, want %q", tc.args, err, tc.want)
+		}
+	}
+
+	args := newApp().commands["service"].Args()
+	if args[0].Completion != CompleteChoices || !slices.Equal(args[0].EnumValues, []string{"start", "stop"}) ||
+		args[1].Completion != CompleteFile || args[2].Completion != CompleteDir {
+		t.Errorf("completion metadata: %+v %+v %+v", args[0], args[1], args[2])
+	}
+
+	bad := New("t", "")
+	bad.EnumArg("mode", "").Back().
+		EnumArg("level", "", "low", "high").Default("max")
+	bad.StringArg("name", "").MustExist()
+	err = bad.Validate()
+	for _, want := range []string{
+		"app: enum argument <mode> has no values",
+		`app: default "max" of argument <level> is not one of [low high]`,
+		"app: argument <name> uses MustExist but is not a FileArg or DirArg",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestDumpInvocation(t *testing.T) {
+	t.Setenv("T_REGION", "eu-west")
+	var dump bytes.Buffer
+	app := New("t", "").Version("1.2.3")
+	app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+	app.BoolFlag("verbose", "").Global().Back().
+		StringFlag("token", "").Secret().Global().Back().
+		StringFlag("unused", "").Global()
+	app.Command("remote", "").Command("add", "").
+		StringFlag("region", "").FromEnv("T_REGION").Back().
+		DurationFlag("timeout", "").Default(90*time.Second).Back().
+		StringArg("name", "").Required().Back().
+		RestArgs().
+		Action(func(ctx *Context) error { return ctx.DumpInvocation(&dump) })
+
+	err := app.RunWithArgs(context.Background(), []string{"--verbose", "--token", "s3cr3t", "remote", "add", "origin", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(dump.String(), "s3cr3t") {
+		t.Fatalf("secret leaked:\n%s", dump.String())
+	}
+	var got map[string]any
+	if err := json.Unmarshal(dump.Bytes(), &got); err != nil {
+		t.Fatalf("%v:\n%s", err, dump.String())
+	}
+	want := map[string]any{
+		"app":     "t",
+		"version": "1.2.3",
+		"command": "remote add",
+		"flags": map[string]any{
+			"verbose": map[string]any{"value": true, "source": "flag"},
+			"token":   map[string]any{"value": "<redacted>", "source": "flag"},
+			"unused":  map[string]any{"value": nil, "source": "none"},
+			"region":  map[string]any{"value": "eu-west", "source": "env"},
+			"timeout": map[string]any{"value": "1m30s", "source": "default"},
+		},
+		"args":       map[string]any{"name": "origin"},
+		"positional": []any{"origin", "x"},
+		"rest_args":  []any{"x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dump:\n%s", dump.String())
+	}
+}
+
+func TestCommandChaining(t *testing.T) {
+	var ran []string
+	newApp := func(policy ChainPolicy) *App {
+		ran = nil
+		app := New("t", "").AllowChaining(";", policy)
+		app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+		app.BoolFlag("verbose", "").Short('v').Global().Back().
+			StringFlag("profile", "").Global()
+		record := func(ctx *Context) error {
+			name := ctx.Result.Command.Name()
+			if ctx.MustGlobalBool("verbose", false) {
+				name += "+v"
+			}
+			name += ctx.MustGlobalString("profile", "")
+			ran = append(ran, name+strings.Join(ctx.RestArgs(), ","))
+			return nil
+		}
+		app.Command("fmt", "").Action(record)
+		app.Command("vet", "").Action(func(ctx *Context) error {
+			_ = record(ctx)
+			return &ExitError{Code: 3, Err: errors.New("vet failed")}
+		})
+		app.Command("build", "").Action(record)
+		app.Command("exec", "").RestArgs().Action(record)
+		return app
+	}
+
+	app := newApp(ChainStopOnError)
+	err := app.RunWithArgs(context.Background(), []string{"--verbose", "--profile", "ci", "fmt", ";", "build", ";", ";"})
+	if err != nil || !slices.Equal(ran, []string{"fmt+vci", "build+vci"}) {
+		t.Fatalf("ran %q, err %v", ran, err)
+	}
+
+	ran = nil
+	err = app.RunWithArgs(context.Background(), []string{"fmt", ";", "vet", ";", "build"})
+	if app.ExitCodes().resolve(err) != 3 || !slices.Equal(ran, []string{"fmt", "vet"}) {
+		t.Fatalf("stop on error: ran %q, err %v", ran, err)
+	}
+
+	app = newApp(ChainContinue)
+	err = app.RunWithArgs(context.Background(), []string{"-v", "vet", ";", "build", ";", "vet", "--bogus"})
+	var chainErr *ChainError
+	if !errors.As(err, &chainErr) || chainErr.Total != 3 || len(chainErr.Failures) != 2 ||
+		!slices.Equal(chainErr.Failures[0].Args, []string{"vet"}) || !slices.Equal(ran, []string{"vet+v", "build+v"}) {
+		t.Fatalf("continue: ran %q, err %v", ran, err)
+	}
+	if code := app.ExitCodes().resolve(err); code != 3 {
+		t.Errorf("chain exit code = %d, want 3", code)
+	}
+
+	// Leading flags alone before the first delimiter are shared, not a command
+	ran = nil
+	err = app.RunWithArgs(context.Background(), []string{"--verbose", ";", "fmt", ";", "build"})
+	if err != nil || !slices.Equal(ran, []string{"fmt+v", "build+v"}) {
+		t.Fatalf("shared flags segment: ran %q, err %v", ran, err)
+	}
+	ran = nil
+	err = app.RunWithArgs(context.Background(), []string{"--profile", "ci", ";", "vet", ";", "build"})
+	if !errors.As(err, &chainErr) || chainErr.Total != 2 || len(chainErr.Failures) != 1 ||
+		!slices.Equal(ran, []string{"vetci", "buildci"}) {
+		t.Fatalf("shared flags segment: ran %q, err %v", ran, err)
+	}
+
+	// The delimiter is left alone after "--"
+	ran = nil
+	_ = app.RunWithArgs(context.Background(), []string{"exec", "--", "find", ".", ";", "build"})
+	if !slices.Equal(ran, []string{"exec--,find,.,;,build"}) {
+		t.Fatalf("after --: ran %q", ran)
+	}
+}
+
+type errLocked struct{}
+
+func (errLocked) Error() string { return "database locked" }
+
+func TestMount(t *testing.T) {
+	var trace []string
+	newDB := func() *App {
+		db := New("db", "Database tools").
+			FlagGroup("target").MutuallyExclusive().
+			BoolFlag("primary", "").Back().
+			BoolFlag("replica", "").Back().
+			EndGroup().
+			Use(func(next middleware.ActionFunc) middleware.ActionFunc {
+				return func(ctx middleware.Context) error {
+					trace = append(trace, "db middleware")
+					return next(ctx)
+				}
+			}).
+			Before(func(*Context) error {
+				trace = append(trace, "db before")
+				return nil
+			}).
+			Action(func(*Context) error {
+				trace = append(trace, "db action")
+				return nil
+			})
+		db.StringFlag("dsn", "").Short('d').FromEnv("T_DSN").Default("sqlite://").Global()
+		db.Command("migrate", "").Command("up", "").
+			IntFlag("steps", "").Default(1).Back().
+			Action(func(ctx *Context) error {
+				dsn, _ := ctx.GlobalString("dsn")
+				trace = append(trace, fmt.Sprintf("up %s %d %v", dsn, ctx.MustInt("steps", 0), ctx.MustBool("primary", false)))
+				return errLocked{}
+			})
+		db.ExitCodes().DefineError(errLocked{}, 75)
+		return db
+	}
+
+	app := New("t", "")
+	out := &strings.Builder{}
+	app.IO().WithOut(out).WithErr(&strings.Builder{})
+	app.Mount("db", newDB())
+	app.Command("tools", "").Mount("sql", newDB())
+	if err := app.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := app.RunWithArgs(context.Background(), []string{"db", "migrate", "up", "-d", "pg://", "--primary", "--steps", "3"})
+	if app.ExitCodes().resolve(err) != 75 ||
+		!slices.Equal(trace, []string{"db before", "db middleware", "up pg:// 3 true"}) {
+		t.Fatalf("trace %q, err %v", trace, err)
+	}
+
+	trace = nil
+	t.Setenv("T_DSN", "mysql://")
+	_ = app.RunWithArgs(context.Background(), []string{"tools", "sql", "migrate", "up"})
+	if !slices.Equal(trace, []string{"db before", "db middleware", "up mysql:// 1 false"}) {
+		t.Fatalf("nested mount: trace %q", trace)
+	}
+
+	trace = nil
+	if err := app.RunWithArgs(context.Background(), []string{"db"}); err != nil ||
+		!slices.Equal(trace, []string{"db before", "db middleware", "db action"}) {
+		t.Fatalf("mounted app action: trace %q, err %v", trace, err)
+	}
+
+	_, err = app.Parse([]string{"db", "migrate", "up", "--primary", "--replica"})
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeFlagGroupViolation {
+		t.Fatalf("flag group: %v", err)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Database tools") {
+		t.Errorf("help does not list the mounted app:\n%s", out.String())
+	}
+}
+
+func TestExperiments(t *testing.T) {
+	var out strings.Builder
+	app := New("t", "")
+	app.IO().WithOut(&out)
+	app.Experiments().Define("fast-sync", "Parallel synchronisation")
+	app.Command("sync", "Sync").Experimental("fast-sync").Action(func(*Context) error { return nil })
+	app.Command("run", "Run").
+		BoolFlag("turbo", "Turbo mode").Experimental("turbo").Back().
+		StringFlag("newui", "New UI").Experimental("turbo").FromEnv("X_NEWUI").Back().
+		Action(func(*Context) error { return nil })
+
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	help := out.String()
+	if strings.Contains(help, "sync   ") || !strings.Contains(help, "Experiments:") ||
+		!strings.Contains(help, "fast-sync  Parallel synchronisation") || !strings.Contains(help, "T_EXPERIMENTS=NAME") {
+		t.Fatalf("unexpected help:\n%s", help)
+	}
+	for _, args := range [][]string{{"sync"}, {"run", "--turbo"}} {
+		_, err := NewParser(app).Parse(args)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Type != ErrorTypeUnavailable {
+			t.Fatalf("%v: expected unavailable error, got %v", args, err)
+		}
+	}
+
+	// Gated flags take no value from the environment until enabled
+	t.Setenv("X_NEWUI", "on")
+	result, err := NewParser(app).Parse([]string{"run"})
+	if got, ok := result.GetString("newui"); err != nil || ok {
+		t.Fatalf("gated flag set from env: got=%q ok=%v err=%v", got, ok, err)
+	}
+
+	t.Setenv("T_EXPERIMENTS", "fast-sync, other")
+	app.Experiments().Enable("turbo")
+	result, err = NewParser(app).Parse([]string{"run"})
+	if got, _ := result.GetString("newui"); err != nil || got != "on" {
+		t.Fatalf("enabled flag not set from env: got=%q err=%v", got, err)
+	}
+	if _, err := app.Parse([]string{"sync"}); err != nil {
+		t.Fatalf("experiment enabled from env rejected: %v", err)
+	}
+	if _, err := app.Parse([]string{"run", "--turbo"}); err != nil {
+		t.Fatalf("experiment enabled in code rejected: %v", err)
+	}
+	out.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	if !strings.Contains(out.String(), "Parallel synchronisation (enabled)") || !strings.Contains(out.String(), "turbo") {
+		t.Fatalf("enabled experiments not marked:\n%s", out.String())
+	}
+	list := app.Experiments().List()
+	if len(list) != 2 || list[0].Name != "fast-sync" || !list[1].Enabled {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	var cfg struct {
+		Name string `json:"name"`
+	}
+	cfgApp, err := Config("c", "").Bind(&cfg).
+		FromDefaults(D{"experiments": []any{"beta"}}).
+		FromFlags().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgApp.Experiments().FromConfig("experiments")
+	cfgApp.Command("beta", "").Experimental("beta").Action(func(*Context) error { return nil })
+	cfgApp.Command("gamma", "").Experimental("gamma").Action(func(*Context) error { return nil })
+	if _, err := cfgApp.Parse([]string{"beta"}); err != nil {
+		t.Fatalf("experiment enabled in config rejected: %v", err)
+	}
+	if _, err := cfgApp.Parse([]string{"gamma"}); err == nil {
+		t.Fatal("experiment not in config accepted")
+	}
+}
+
+func TestQuietAndPorcelain(t *testing.T) {
+	var out, errOut strings.Builder
+	var quiet, porcelain bool
+	app := New("t", "").EnableQuietFlag().EnablePorcelainFlag()
+	app.IO().WithOut(&out).WithErr(&errOut).ForceColor()
+	app.ErrorHandler().ShowHelpOnError(true)
+	app.Command("sync", "Sync").
+		BoolFlag("verbose", "").Back().
+		Action(func(ctx *Context) error {
+			quiet, porcelain = ctx.IsQuiet(), ctx.Porcelain()
+			ctx.Logger().Info("connecting")
+			ctx.Logger().Warning("slow mirror")
+			ctx.Printf("synced %d files\n", 3)
+			fmt.Fprintln(ctx.Stdout(), "result")
+			return nil
+		})
+
+	run := func(args ...string) error {
+		out.Reset()
+		errOut.Reset()
+		return app.RunWithArgs(context.Background(), args)
+	}
+	if err := run("sync"); err != nil || quiet || porcelain ||
+		!strings.Contains(out.String(), "connecting") || !strings.Contains(out.String(), "synced 3 files") {
+		t.Fatalf("normal run: %v, out %q", err, out.String())
+	}
+	for _, args := range [][]string{{"-q", "sync"}, {"sync", "--quiet"}, {"sync", "--porcelain"}} {
+		if err := run(args...); err != nil || !quiet {
+			t.Fatalf("%v: %v, quiet %v", args, err, quiet)
+		}
+		all := out.String() + errOut.String()
+		if strings.Contains(all, "connecting") || strings.Contains(all, "synced") ||
+			!strings.Contains(all, "slow mirror") || !strings.Contains(out.String(), "result") {
+			t.Fatalf("%v: unexpected output %q", args, all)
+		}
+	}
+	if !porcelain || strings.Contains(out.String()+errOut.String(), "\x1b[") {
+		t.Fatalf("porcelain output has colors: %q", out.String()+errOut.String())
+	}
+
+	err := run("sync", "--porcelain", "--verbos")
+	if err == nil || strings.Contains(err.Error(), "\n") || strings.Contains(err.Error(), "\x1b[") ||
+		out.Len() > 0 {
+		t.Fatalf("porcelain error %q, out %q", err, out.String())
+	}
+	if err := run("sync", "-q", "--verbos"); err == nil || out.Len() > 0 {
+		t.Fatalf("quiet error %q, out %q", err, out.String())
+	}
+
+	if err := run("--help", "--porcelain"); err != nil ||
+		!strings.Contains(out.String(), "--quiet") || strings.Contains(out.String(), "\x1b[") {
+		t.Fatalf("porcelain help: %v\n%s", err, out.String())
+	}
+	if err := run("--help"); err != nil || !strings.Contains(out.String(), "\x1b[") {
+		t.Fatalf("help: %v\n%s", err, out.String())
+	}
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	var exitWith error
+	app := New("t", "").AllowChaining(";", ChainContinue)
+	app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+	app.ExitCodes().DefineError(errLocked{}, 75)
+	diff := app.Command("diff", "").Action(func(*Context) error { return exitWith })
+	diff.ExitCodes().DefineError(errLocked{}, 1).DefineCLI(ErrorTypeValidation, 4)
+	diff.Command("tree", "").Action(func(*Context) error { return errLocked{} })
+	app.Command("check", "").Action(func(*Context) error { return errLocked{} })
+
+	for _, tc := range []struct {
+		args []string
+		err  error
+		want int
+	}{
+		{[]string{"diff"}, errLocked{}, 1},
+		{[]string{"diff", "tree"}, nil, 1},
+		{[]string{"check"}, nil, 75},
+		{[]string{"diff"}, NewError(ErrorTypeValidation, "bad"), 4},
+		{[]string{"diff"}, &ExitError{Code: 9, Err: errLocked{}}, 9},
+		{[]string{"diff"}, errors.New("other"), 1},
+		{[]string{"diff", ";", "check"}, errLocked{}, 75},
+		{[]string{"check", ";", "diff"}, NewError(ErrorTypeValidation, "bad"), 75},
+		{[]string{"diff", "--nope"}, nil, 2},
+	} {
+		exitWith = tc.err
+		if code := app.exitCode(app.RunWithArgs(context.Background(), tc.args)); code != tc.want {
+			t.Errorf("%v returning %v: exit code %d, want %d", tc.args, tc.err, code, tc.want)
+		}
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	app := New("t", "").AllowAbbreviations(true)
+	app.BoolFlag("verbose", "").Short('v')
+	app.StringFlag("name", "").Short('n')
+	app.StringFlag("token", "").Short('t').Secret()
+	app.Command("login", "").StringFlag("password", "").Short('p').Secret()
+
+	for _, tc := range []struct{ args, want []string }{
+		{[]string{"-vt", "HUNTER2"}, []string{"-vt", redacted}},
+		{[]string{"-vtHUNTER2"}, []string{"-vt" + redacted}},
+		{[]string{"-vn", "-t", "x"}, []string{"-vn", "-t", "x"}},
+		{[]string{"-vnbob", "-t", "x"}, []string{"-vnbob", "-t", redacted}},
+		{[]string{"--tok", "HUNTER2", "--tok=HUNTER2"}, []string{"--tok", redacted, "--tok=" + redacted}},
+		{[]string{"login", "--pass", "pw", "-vp", "pw"}, []string{"login", "--pass", redacted, "-vp", redacted}},
+		{[]string{"--name", "--token", "x"}, []string{"--name", "--token", "x"}},
+		{[]string{"--", "--token", "x"}, []string{"--", "--token", "x"}},
+	} {
+		if got := app.redactArgs(tc.args); !slices.Equal(got, tc.want) {
+			t.Errorf("redactArgs(%q) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+
+	// Without abbreviations a prefix is not a flag, so nothing is redacted
+	app.AllowAbbreviations(false)
+	if got := app.redactArgs([]string{"--tok", "x"}); got[1] != "x" {
+		t.Errorf("redactArgs without abbreviations = %q", got)
+	}
+}
+
+func TestCrashPolicy(t *testing.T) {
+	dir := t.TempDir()
+	app := New("t", "").Version("1.2.3").CrashPolicy(WriteCrashReport(dir), ExitCode(70))
+	app.StringFlag("token", "").Short('t').Secret().Global()
+	app.Command("boom", "").Action(func(*Context) error { panic("kaboom") })
+
+	err := app.RunWithArgs(context.Background(), []string{"boom", "--token", "s3cret", "-tx2", "--token=abc"})
+	var crashErr *CrashError
+	if app.exitCode(err) != 70 || !errors.As(err, &crashErr) || crashErr.Panic != "kaboom" || crashErr.Report == "" {
+		t.Fatalf("unexpected error %v (exit code %d)", err, app.exitCode(err))
+	}
+	data, readErr := os.ReadFile(crashErr.Report)
+	report := string(data)
+	if readErr != nil || !strings.Contains(report, "Version:  1.2.3") || !strings.Contains(report, "Panic:    kaboom") ||
+		!strings.Contains(report, "goroutine") ||
+		!strings.Contains(report, "Command:  t boom --token <redacted> -t<redacted> --token=<redacted>") {
+		t.Fatalf("unexpected report (%v):\n%s", readErr, report)
+	}
+
+	// Panics recovered by the Recovery middleware are crashes too
+	app = New("t", "").CrashPolicy(WriteCrashReport(dir)).Use(middleware.RecoveryToError())
+	app.Command("boom", "").Action(func(*Context) error { panic("again") })
+	err = app.RunWithArgs(context.Background(), []string{"boom"})
+	if !errors.As(err, &crashErr) || crashErr.Panic != "again" || app.exitCode(err) != 1 {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if app.ExitCodes().UseSysexits(); app.exitCode(err) != SysexitSoftware {
+		t.Fatalf("sysexits code %d", app.exitCode(err))
+	}
+	if data, _ := os.ReadFile(crashErr.Report); !strings.Contains(string(data), "no stack trace") {
+		t.Fatalf("unexpected report:\n%s", data)
+	}
+}
+
+func TestCollectUnknownFlags(t *testing.T) {
+	var got []string
+	app := New("t", "")
+	app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+	app.Command("render", "").CollectUnknownFlags().
+		BoolFlag("verbose", "").Short('v').Back().
+		Action(func(ctx *Context) error {
+			got = append(ctx.UnknownFlags(), ctx.Args()...)
+			return nil
+		})
+	app.Command("plain", "").Action(func(*Context) error { return nil })
+
+	args := []string{"render", "--name", "web", "--replicas=3", "-v", "--dry", "--tag", "-x", "1", "-vk", "file"}
+	if err := app.RunWithArgs(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--name", "web", "--replicas=3", "--dry", "--tag", "-x", "1", "-k", "file"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"render"}); err != nil || len(got) != 0 {
+		t.Fatalf("unknown flags kept across runs: %q (%v)", got, err)
+	}
+	if err := app.RunWithArgs(context.Background(), []string{"plain", "--name"}); err == nil {
+		t.Fatal("unknown flag accepted by a command without CollectUnknownFlags")
+	}
+}
+
+func TestConfig_RegisterDecoder(t *testing.T) {
+	type userID int
+	type C struct {
+		Endpoint url.URL   `flag:"endpoint" default:"https://example.com"`
+		Mirror   *url.URL  `json:"mirror" env:"T_MIRROR"`
+		Backups  []url.URL `json:"backups"`
+		Owner    userID    `json:"owner" env:"T_OWNER"`
+	}
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte(`{"owner": 7, "backups": ["https://b1", "https://b2"]}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	t.Setenv("T_MIRROR", "https://mirror")
+
+	parseURL := func(raw any) (any, error) { return url.Parse(fmt.Sprint(raw)) }
+	var cfg C
+	app, err := Config("app", "").
+		RegisterDecoder(reflect.TypeOf(url.URL{}), parseURL).
+		RegisterDecoder(reflect.TypeOf(&url.URL{}), parseURL).
+		RegisterDecoder(reflect.TypeOf(userID(0)), func(raw any) (any, error) {
+			if s, ok := raw.(string); ok {
+				n, err := strconv.Atoi(strings.TrimPrefix(s, "u-"))
+				return userID(n), err
+			}
+			return userID(raw.(float64)), nil
+		}).
+		FromFile(path).FromEnv().FromFlags().Bind(&cfg).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	populate := func(args ...string) error {
+		res, err := NewParser(app).Parse(args)
+		if err != nil {
+			return err
+		}
+		app.currentResult = res
+		return app.populateConfiguration()
+	}
+
+	if err := populate(); err != nil {
+		t.Fatalf("populate: %v", err)
+	}
+	if cfg.Endpoint.Host != "example.com" || cfg.Mirror == nil || cfg.Mirror.Host != "mirror" ||
+		len(cfg.Backups) != 2 || cfg.Backups[1].Host != "b2" || cfg.Owner != 7 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("T_OWNER", "u-42")
+	if err := populate("--endpoint", "https://flag.example", "--owner", "u-9"); err != nil {
+		t.Fatalf("populate: %v", err)
+	}
+	if cfg.Endpoint.Host != "flag.example" || cfg.Owner != 9 {
+		t.Fatalf("flags not decoded: %+v", cfg)
+	}
+	if err := populate("--owner", "nine"); err == nil || !strings.Contains(err.Error(), "owner") {
+		t.Fatalf("expected decode error for owner, got %v", err)
+	}
+}
+
+func TestConfig_FromFlagFile(t *testing.T) {
+	dir := t.TempDir()
+	base, chosen := filepath.Join(dir, "base.json"), filepath.Join(dir, "chosen.json")
+	if err := os.WriteFile(base, []byte(`{"host": "base", "port": 1}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(chosen, []byte(`{"host": "chosen"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	type C struct {
+		Host string `flag:"host"`
+		Port int    `flag:"port"`
+	}
+	var cfg C
+	app, err := Config("app", "").FromFile(base).FromFlags().FromFlagFile("config", "c").Bind(&cfg).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if flag := app.flags["config"]; flag == nil || !flag.IsGlobal() || flag.Short != 'c' {
+		t.Fatalf("config flag not registered: %+v", flag)
+	}
+	populate := func(args ...string) error {
+		cfg = C{}
+		res, err := NewParser(app).Parse(args)
+		if err != nil {
+			return err
+		}
+		app.currentResult = res
+		return app.populateConfiguration()
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want C
+	}{
+		{[]string{"--config", chosen}, C{Host: "chosen", Port: 1}},
+		{[]string{"-c", chosen, "--host", "flag"}, C{Host: "flag", Port: 1}},
+		{nil, C{Host: "base", Port: 1}},
+	} {
+		if err := populate(tc.args...); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		if cfg != tc.want {
+			t.Fatalf("%v: got %+v, want %+v", tc.args, cfg, tc.want)
+		}
+	}
+
+	if err := populate("--config", filepath.Join(dir, "missing.json")); err == nil ||
+		!strings.Contains(err.Error(), "missing.json") {
+		t.Fatalf("expected error for a missing config file, got %v", err)
+	}
+}
+
+func TestConfig_LayeredFiles(t *testing.T) {
+	dir := t.TempDir()
+	base, local := filepath.Join(dir, "config.json"), filepath.Join(dir, "config.local.json")
+	if err := os.WriteFile(base, []byte(`{"host": "base", "port": 1}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(local, []byte(`{"host": "local"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.json")
+
+	type C struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	var cfg C
+	_, err := Config("app", "").FromFile(base).Required().FromFile(local).FromFile(missing).Optional().Bind(&cfg).Build()
+	if err != nil || cfg != (C{Host: "local", Port: 1}) {
+		t.Fatalf("layered files: %+v (%v)", cfg, err)
+	}
+
+	_, err = Config("app", "").FromFile(base).FromFile(missing).Required().Bind(&C{}).Build()
+	if err == nil || !strings.Contains(err.Error(), "missing.json") {
+		t.Fatalf("expected error for a missing required file, got %v", err)
+	}
+
+	// CLI mode fails when resolving, on every run
+	app, err := Config("app", "").Bind(&C{}).FromFile(missing).Required().FromFlags().Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := app.RunWithArgs(context.Background(), nil); err == nil || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected error for a missing required file, got %v", err)
+	}
+}
+
+func TestConfig_FileErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("{\n  \"host\": \"a\",\n  \"port\": ,\n}"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.json")
+	type C struct {
+		Host string `json:"host"`
+	}
+
+	// Malformed files fail whatever the policy, with their position
+	_, err := Config("app", "").FromFile(bad, Silent).Bind(&C{}).Build()
+	var fileErr *ConfigFileError
+	if !errors.As(err, &fileErr) || fileErr.Path != bad || fileErr.Line != 3 || fileErr.Column != 11 ||
+		!strings.Contains(err.Error(), "bad.json:3:11: invalid character") {
+		t.Fatalf("unexpected error for a malformed file: %v", err)
+	}
+
+	_, err = Config("app", "").FromFile(missing, ErrorIfMissing).Bind(&C{}).Build()
+	if !errors.As(err, &fileErr) || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected error for a missing file, got %v", err)
+	}
+
+	var logs strings.Builder
+	cb := Config("app", "").FromFile(missing, WarnIfMissing)
+	cb.app.IO().WithOut(&logs).WithErr(&logs)
+	if _, err := cb.Bind(&C{}).Build(); err != nil || !strings.Contains(logs.String(), "missing.json not found") {
+		t.Fatalf("expected a warning for a missing file, got %q (%v)", logs.String(), err)
+	}
+	if _, err := Config("app", "").FromFile(missing).Bind(&C{}).Build(); err != nil {
+		t.Fatalf("missing files are skipped by default: %v", err)
+	}
+
+	// A missing file is missing whatever its extension
+	_, err = Config("app", "").FromFile("/nonexistent/config.yaml", ErrorIfMissing).Bind(&C{}).Build()
+	if !errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrUnsupportedConfigFormat) {
+		t.Fatalf("expected a missing file error, got %v", err)
+	}
+	if _, err := Config("app", "").FromFile("/nonexistent/config.yaml").Bind(&C{}).Build(); err != nil {
+		t.Fatalf("missing files are skipped by default: %v", err)
+	}
+
+	// Unsupported formats follow the policy too
+	yaml := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(yaml, []byte("host: a\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Config("app", "").FromFile(yaml).Bind(&C{}).Build(); err != nil {
+		t.Fatalf("unsupported files are skipped by default: %v", err)
+	}
+	logs.Reset()
+	cb = Config("app", "").FromFile(yaml, WarnIfMissing)
+	cb.app.IO().WithOut(&logs).WithErr(&logs)
+	_, err = cb.Bind(&C{}).Build()
+	if err != nil || !strings.Contains(logs.String(), "unsupported config format: .yaml") {
+		t.Fatalf("expected a warning for an unsupported file, got %q (%v)", logs.String(), err)
+	}
+	_, err = Config("app", "").FromFile(yaml).Required().Bind(&C{}).Build()
+	if !errors.As(err, &fileErr) || !errors.Is(err, ErrUnsupportedConfigFormat) {
+		t.Fatalf("expected an unsupported format error, got %v", err)
+	}
+}
+
+func TestSetFlag(t *testing.T) {
+	var port int
+	var tags []string
+	var level string
+	var src ValueSource
+	app := New("t", "")
+	app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+	app.IntFlag("port", "").Default(80).Global().Back().
+		Before(func(ctx *Context) error {
+			return ctx.SetFlag("port", ctx.MustGlobalInt("port", 0)+1)
+		})
+	app.Command("run", "").
+		StringSliceFlag("tag", "").Back().
+		EnumFlag("level", "", "debug", "info").Default("info").Back().
+		Before(func(ctx *Context) error {
+			if err := ctx.SetFlag("tag", "a,b"); err != nil {
+				return err
+			}
+			return ctx.SetFlag("level", "debug")
+		}).
+		Action(func(ctx *Context) error {
+			port, tags, level = ctx.MustGlobalInt("port", 0), ctx.MustStringSlice("tag", nil), ctx.MustEnum("level", "")
+			src = ctx.Source("port")
+			return nil
+		})
+
+	if err := app.RunWithArgs(context.Background(), []string{"run", "--port", "8080", "--tag", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if port != 8081 || !slices.Equal(tags, []string{"a", "b"}) || level != "debug" || src != ValueSourceOverride {
+		t.Fatalf("overrides not applied: port=%d tags=%q level=%q source=%s", port, tags, level, src)
+	}
+
+	res, err := NewParser(app).Parse([]string{"run"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]any{"level": "trace", "port": "eighty", "nope": 1, "tag": nil} {
+		if err := res.Override(name, value); err == nil {
+			t.Errorf("Override(%q, %v) accepted", name, value)
+		}
+	}
+}
+
+func TestOverrideSkipsDefaultFunc(t *testing.T) {
+	calls := 0
+	app := New("t", "")
+	app.StringFlag("out", "").DefaultFunc(func() string { calls++; return "computed" })
+	app.Int32Flag("level", "")
+
+	res, err := NewParser(app).Parse([]string{"--out", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := res.Override("out", "y"); err != nil || res.MustGetString("out", "") != "y" {
+		t.Fatalf("Override: %v, out=%q", err, res.MustGetString("out", ""))
+	}
+	if calls != 0 {
+		t.Fatalf("DefaultFunc ran %d times", calls)
+	}
+	if err := res.Override("level", "7"); err != nil || res.MustGetInt32("level", 0) != 7 {
+		t.Fatalf("Override int32: %v", err)
+	}
+}
+
+func TestSetFlag_ConfigBinding(t *testing.T) {
+	type C struct {
+		Dir string `flag:"dir" default:"."`
+	}
+	var cfg C
+	app, err := Config("app", "").FromFlags().Bind(&cfg).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	var seen string
+	app.Before(func(ctx *Context) error { return ctx.SetFlag("dir", "/srv/data") }).
+		Action(func(*Context) error {
+			seen = cfg.Dir
+			return nil
+		})
+	if err := app.RunWithArgs(context.Background(), nil); err != nil || seen != "/srv/data" {
+		t.Fatalf("config not rebound: %q (%v)", seen, err)
+	}
+}
+
+func TestContextCommandPath(t *testing.T) {
+	type seen struct {
+		path         string
+		parent, root *Command
+	}
+	var got seen
+	record := func(ctx *Context) error {
+		got = seen{ctx.CommandPath(), ctx.ParentCommand(), ctx.RootCommand()}
+		return nil
+	}
+	app := New("t", "").Action(record)
+	server := app.Command("server", "").Action(record)
+	server.Command("up", "").Alias("start").Action(record)
+	serverCmd := app.commands["server"]
+
+	for _, tc := range []struct {
+		args []string
+		want seen
+	}{
+		{[]string{"server", "start"}, seen{"server up", serverCmd, serverCmd}},
+		{[]string{"server"}, seen{"server", nil, serverCmd}},
+		{nil, seen{}},
+	} {
+		if err := app.RunWithArgs(context.Background(), tc.args); err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%v: got %+v, want %+v", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestCredentialFlag(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_TOKEN", "from-env")
+
+	run := func(args ...string) (string, error) {
+		var got string
+		app := New("t", "")
+		app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+		app.CredentialResolver("keyring", CredentialResolverFunc(func(ref string) (string, error) {
+			if ref == "deploy" {
+				return "from-keyring", nil
+			}
+			return "", errors.New("not found")
+		}))
+		app.CredentialFlag("token", "").Default("hunter2")
+		app.Action(func(ctx *Context) error {
+			got, _ = ctx.String("token")
+			return nil
+		})
+		err := app.RunWithArgs(context.Background(), args)
+		return got, err
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "hunter2"},
+		{[]string{"--token", "plain"}, "plain"},
+		{[]string{"--token", "pa:ss"}, "pa:ss"},
+		{[]string{"--token", "@" + file}, "from-file"},
+		{[]string{"--token", "@@literal"}, "@literal"},
+		{[]string{"--token", "env:T_TOKEN"}, "from-env"},
+		{[]string{"--token=keyring:deploy"}, "from-keyring"},
+	}
+	for _, tt := range tests {
+		got, err := run(tt.args...)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %q, %v; want %q", tt.args, got, err, tt.want)
+		}
+	}
+
+	_, err := run("--token", "keyring:missing")
+	if err == nil || !strings.Contains(err.Error(), "keyring:missing") {
+		t.Errorf("keyring error = %v", err)
+	}
+	_, err = run("--token", "env:T_UNSET_TOKEN")
+	if err == nil || !strings.Contains(err.Error(), "T_UNSET_TOKEN") {
+		t.Errorf("env error = %v", err)
+	}
+
+	var help strings.Builder
+	app := New("t", "")
+	app.IO().WithOut(&help).WithErr(&help)
+	app.CredentialFlag("token", "API token").Default("hunter2")
+	_ = app.RunWithArgs(context.Background(), []string{"--help"})
+	if strings.Contains(help.String(), "hunter2") || !strings.Contains(help.String(), "API token") {
+		t.Errorf("help:\n%s", help.String())
+	}
+}
+
+func TestAuthCommand(t *testing.T) {
+	store := snapcreds.Memory()
+	var got string
+	var credErr error
+	newApp := func(stdin string) *App {
+		app := New("t", "")
+		app.IO().WithIn(strings.NewReader(stdin)).WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+		app.Auth().Backend(store).Credential("registry", "Registry token")
+		app.CredentialFlag("token", "").Back()
+		app.Command("push", "").Action(func(ctx *Context) error {
+			got, credErr = ctx.Credential("registry")
+			return nil
+		})
+		app.Command("flag", "").Action(func(ctx *Context) error {
+			got, _ = ctx.String("token")
+			return nil
+		})
+		return app
+	}
+	run := func(stdin string, args ...string) error {
+		return newApp(stdin).RunWithArgs(context.Background(), args)
+	}
+
+	if err := run("", "push"); err != nil || !errors.Is(credErr, snapcreds.ErrNotFound) {
+		t.Fatalf("before login: %v, %v", err, credErr)
+	}
+	if !strings.Contains(credErr.Error(), "t auth login registry") {
+		t.Errorf("error = %v", credErr)
+	}
+	if err := run("s3cr3t\n", "auth", "login"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("", "push"); err != nil || credErr != nil || got != "s3cr3t" {
+		t.Fatalf("after login: %q, %v, %v", got, err, credErr)
+	}
+	if err := run("", "--token", "keyring:registry", "flag"); err != nil || got != "s3cr3t" {
+		t.Fatalf("keyring flag: %q, %v", got, err)
+	}
+	if err := run("x\n", "auth", "login", "nope"); err == nil {
+		t.Error("login accepted an undeclared credential")
+	}
+	if err := run("\n", "auth", "login", "registry"); err == nil {
+		t.Error("login accepted an empty secret")
+	}
+	if err := run("", "auth", "logout", "registry"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("t", "registry"); !errors.Is(err, snapcreds.ErrNotFound) {
+		t.Fatalf("after logout: %v", err)
+	}
+}
+
+func TestConfig_ConfigCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	type Redis struct {
+		Port int `json:"port" default:"6379"`
+	}
+	type Cache struct {
+		Redis Redis `json:"redis"`
+	}
+	type C struct {
+		Name    string        `json:"name" required:"true"`
+		Level   string        `json:"level" enum:"debug,info"`
+		Timeout time.Duration `json:"timeout"`
+		Tags    []string      `json:"tags"`
+		Cache   Cache         `json:"cache"`
+		Token   string        `json:"token" secret:"true"`
+		Port    int           `json:"port" flag:"listen-port"`
+	}
+	run := func(args ...string) (string, error) {
+		var cfg C
+		app, err := Config("app", "").FromFlags().ConfigCommand("config.json").Bind(&cfg).Build()
+		if err != nil {
+			t.Fatalf("build: %v", err)
+		}
+		var out strings.Builder
+		app.IO().WithOut(&out).WithErr(&strings.Builder{})
+		err = app.RunWithArgs(context.Background(), args)
+		return out.String(), err
+	}
+
+	// Runs although the required name is missing
+	if out, err := run("config", "get", "cache.redis.port"); err != nil || out != "6379\n" {
+		t.Fatalf("get default: %q, %v", out, err)
+	}
+	for _, args := range [][]string{
+		{"config", "set", "cache.redis.port", "6380"},
+		{"config", "set", "name", "svc"},
+		{"config", "set", "timeout", "90s"},
+		{"config", "set", "tags", "a,b"},
+		{"config", "set", "level", "info"},
+		{"config", "set", "token", "hunter2"},
+		{"config", "set", "listen-port", "8080"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"config", "set", "cache.redis.port", "many"},
+		{"config", "set", "level", "trace"},
+		{"config", "set", "cache.redis.prot", "1"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("%v: accepted", args)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "app", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file map[string]any
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("%v:\n%s", err, data)
+	}
+	if file["timeout"] != "1m30s" || file["cache"].(map[string]any)["redis"].(map[string]any)["port"] != float64(6380) {
+		t.Fatalf("file:\n%s", data)
+	}
+	// Written with the json name, like hand-written files
+	if _, ok := file["listen-port"]; ok || file["port"] != float64(8080) {
+		t.Fatalf("file:\n%s", data)
+	}
+
+	// Secret values are only shown when asked for by key
+	out, err := run("config", "list")
+	want := "cache.redis.port=6380\nlevel=info\nlisten-port=8080\nname=svc\ntags=a,b\ntimeout=1m30s\ntoken=<redacted>\n"
+	if err != nil || out != want {
+		t.Fatalf("list: %q, %v", out, err)
+	}
+	if out, err := run("config", "get", "token"); err != nil || out != "hunter2\n" {
+		t.Fatalf("get secret: %q, %v", out, err)
+	}
+	if _, err := run("config", "unset", "listen-port"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := run("config", "get", "listen-port"); out != "" {
+		t.Fatalf("after unset: %q", out)
+	}
+	if _, err := run("config", "unset", "cache.redis.port"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := run("config", "get", "cache.redis.port"); out != "6379\n" {
+		t.Fatalf("after unset: %q", out)
+	}
+}
+
+func TestVerbosityFlags(t *testing.T) {
+	var out strings.Builder
+	var verbosity int
+	var meta any
+	app := New("t", "").VerbosityFlags()
+	app.IO().WithOut(&out).WithErr(&out)
+	app.Command("sync", "").Action(func(ctx *Context) error {
+		verbosity, meta = ctx.Verbosity(), ctx.Get("verbosity")
+		ctx.Logger().Debug("dialing")
+		ctx.Logger().Info("connecting")
+		return nil
+	})
+
+	for _, tc := range []struct {
+		args          []string
+		want          int
+		debug, info   bool
+		withTimestamp bool
+	}{
+		{[]string{"sync"}, 0, false, true, false},
+		{[]string{"-v", "sync"}, 1, true, true, false},
+		{[]string{"sync", "-vv"}, 2, true, true, true},
+		{[]string{"-vvv", "sync"}, 3, true, true, true},
+		{[]string{"--verbose", "sync", "--verbose"}, 2, true, true, true},
+		{[]string{"--verbose=1", "sync"}, 1, true, true, false},
+		{[]string{"-q", "sync"}, -1, false, false, false},
+	} {
+		out.Reset()
+		if err := app.RunWithArgs(context.Background(), tc.args); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		got := out.String()
+		if verbosity != tc.want || meta != tc.want ||
+			strings.Contains(got, "dialing") != tc.debug || strings.Contains(got, "connecting") != tc.info {
+			t.Errorf("%v: verbosity %d (%v), output %q", tc.args, verbosity, meta, got)
+		}
+		if hasTime := regexp.MustCompile(`\d\d:\d\d:\d\d`).MatchString(got); hasTime != tc.withTimestamp {
+			t.Errorf("%v: timestamps %v in %q", tc.args, hasTime, got)
+		}
+	}
+
+	out.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil ||
+		!strings.Contains(out.String(), "--verbose, -v  ") || !strings.Contains(out.String(), "--quiet, -q") {
+		t.Fatalf("help: %v\n%s", err, out.String())
+	}
+}
+
+func TestCapabilitiesFlag(t *testing.T) {
+	var out strings.Builder
+	ran := false
+	app := New("t", "").Version("1.0.0")
+	app.IO().WithOut(&out).WithErr(&out)
+	app.RestArgs().Action(func(*Context) error {
+		ran = true
+		return nil
+	})
+
+	if err := app.RunWithArgs(context.Background(), []string{"x", "--snap-capabilities"}); err != nil || ran {
+		t.Fatalf("err %v, ran %v", err, ran)
+	}
+	if !strings.HasPrefix(out.String(), "t 1.0.0\n") || !strings.Contains(out.String(), "stdout tty:") {
+		t.Fatalf("report:\n%s", out.String())
+	}
+	if err := app.RunWithArgs(context.Background(), []string{"--", "--snap-capabilities"}); err != nil || !ran {
+		t.Fatalf("after --: err %v, ran %v", err, ran)
+	}
+}
+
+func TestGate(t *testing.T) {
+	var buf bytes.Buffer
+	var ran, before []string
+	locked := true
+	app := New("t", "")
+	app.IO().WithOut(&buf).WithErr(&buf)
+	app.Before(func(ctx *Context) error {
+		before = append(before, "before")
+		return nil
+	})
+	app.Gate(func(_ *Context, cmd *Command) error {
+		if locked && cmd != nil && cmd.Name() == "deploy" {
+			return Deny("maintenance in progress", "try again after the window closes")
+		}
+		return nil
+	})
+	for _, name := range []string{"deploy", "status"} {
+		app.Command(name, "").Action(func(ctx *Context) error {
+			ran = append(ran, ctx.Command().Name())
+			return nil
+		})
+	}
+
+	err := app.RunWithArgs(context.Background(), []string{"deploy"})
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeGated {
+		t.Fatalf("want gated CLIError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "cannot run t deploy: maintenance in progress") ||
+		!strings.Contains(err.Error(), "try again after the window closes") {
+		t.Fatalf("error lacks reason or hint:\n%s", err)
+	}
+	if code := app.ExitCode(err); code != SysexitUnavailable {
+		t.Fatalf("exit code = %d, want %d", code, SysexitUnavailable)
+	}
+	if len(ran) != 0 || len(before) != 0 {
+		t.Fatalf("vetoed command ran: %v %v", ran, before)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"deploy", "--help"}); err != nil {
+		t.Fatalf("help must not be gated: %v", err)
+	}
+	if err := app.RunWithArgs(context.Background(), []string{"status"}); err != nil || len(ran) != 1 {
+		t.Fatalf("status: %v %v", err, ran)
+	}
+
+	app.ExitCodes().DefineCLI(ErrorTypeGated, SysexitTempFail)
+	if code := app.ExitCode(app.RunWithArgs(context.Background(), []string{"deploy"})); code != SysexitTempFail {
+		t.Fatalf("remapped exit code = %d", code)
+	}
+	locked = false
+	if err := app.RunWithArgs(context.Background(), []string{"deploy"}); err != nil || len(ran) != 2 {
+		t.Fatalf("unlocked deploy: %v %v", err, ran)
+	}
+}
+
+func TestQuoteAndSplitCommandLine(t *testing.T) {
+	args := []string{"plain", "", "two words", "it's", `back\slash`, `"quoted"`, "$HOME", "tab\tand\nnewline",
+		`C:\tmp dir\`, `trailing\\`, "--flag=a b", "ünïcode", "*.go"}
+
+	posix := make([]string, len(args))
+	windows := make([]string, len(args))
+	for i, arg := range args {
+		posix[i] = QuotePOSIXArg(arg)
+		windows[i] = QuoteWindowsArg(arg)
+	}
+	if got, err := SplitPOSIXCommandLine(strings.Join(posix, " ")); err != nil || !slices.Equal(got, args) {
+		t.Fatalf("POSIX round trip: %q %v", got, err)
+	}
+	if got := SplitWindowsCommandLine(strings.Join(windows, " ")); !slices.Equal(got, args) {
+		t.Fatalf("Windows round trip: %q", got)
+	}
+	if got, err := SplitCommandLine(QuoteArgs(args)); err != nil || !slices.Equal(got, args) {
+		t.Fatalf("host round trip: %q %v", got, err)
+	}
+	if got := QuotePOSIXArg("it's"); got != `'it'\''s'` {
+		t.Fatalf("QuotePOSIXArg = %s", got)
+	}
+	if got := QuoteArgs([]string{"go", "build", "-o", "bin/app", "./..."}); got != "go build -o bin/app ./..." {
+		t.Fatalf("safe arguments must stay unquoted: %s", got)
+	}
+
+	got, err := SplitPOSIXCommandLine("a  \"b \\\"c\\\" \\x $y\" 'd'\\''e' f\\ g \"\" h\\\ni")
+	if want := []string{"a", `b "c" \x $y`, "d'e", "f g", "", "hi"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("SplitPOSIXCommandLine = %q %v, want %q", got, err, want)
+	}
+	for _, bad := range []string{"'open", `"open`, `end\`} {
+		if _, err := SplitPOSIXCommandLine(bad); err == nil {
+			t.Fatalf("%s: expected an error", bad)
+		}
+	}
+	got = SplitWindowsCommandLine(`a\\b "c""d" e\\\"f  \\\\"g h"`)
+	if want := []string{`a\\b`, `c"d`, `e\"f`, `\\g h`}; !slices.Equal(got, want) {
+		t.Fatalf("SplitWindowsCommandLine = %q", got)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	panicMessage := func(fn func()) (msg string) {
+		defer func() { msg, _ = recover().(string) }()
+		fn()
+		return ""
+	}
+
+	app := New("t", "")
+	app.Command("serve", "")
+	if app.Freeze().Freeze(); !app.IsFrozen() {
+		t.Fatalf("expected the app to be frozen")
+	}
+	if app.flags["help"] == nil {
+		t.Fatalf("expected Freeze to register the built-in flags")
+	}
+	msg := panicMessage(func() { app.BoolFlag("late", "") })
+	if !strings.Contains(msg, `flag "late"`) || !strings.Contains(msg, "after Freeze or Run") {
+		t.Fatalf("unexpected panic for a late flag: %q", msg)
+	}
+
+	// Registration from a hook is caught during the run
+	app = New("t", "")
+	app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+	app.Command("serve", "").Action(func(ctx *Context) error {
+		msg = panicMessage(func() { ctx.App.Command("late", "") })
+		return nil
+	})
+	if err := app.RunWithArgs(context.Background(), []string{"serve"}); err != nil {
+		t.Fatalf("RunWithArgs failed: %v", err)
+	}
+	if !strings.Contains(msg, `command "late"`) || !strings.Contains(msg, "while the app is running") {
+		t.Fatalf("unexpected panic for a command added by an action: %q", msg)
+	}
+
+	// Plugin loaders still register between PreParse and Run; PrintEnvHelp
+	// and PreParse never freeze
+	app = New("t", "")
+	ran := false
+	plugins := app.Command("plugin", "")
+	_ = app.PrintEnvHelp(&strings.Builder{})
+	if _, err := app.PreParse([]string{"plugin", "hello"}); err != nil || app.IsFrozen() {
+		t.Fatalf("PreParse: %v (frozen %v)", err, app.IsFrozen())
+	}
+	plugins.Command("hello", "").Action(func(*Context) error { ran = true; return nil })
+	if err := app.RunWithArgs(context.Background(), []string{"plugin", "hello"}); err != nil || !ran {
+		t.Fatalf("expected the plugin command to run, got %v", err)
+	}
+
+	// The first run freezes for good; a long-lived host opts out with Unfreeze
+	msg = panicMessage(func() { plugins.Command("bye", "") })
+	if !app.IsFrozen() || !strings.Contains(msg, "after Freeze or Run") {
+		t.Fatalf("expected registration after a run to panic, got %q", msg)
+	}
+	ran = false
+	app.Unfreeze()
+	plugins.Command("bye", "").Action(func(*Context) error { ran = true; return nil })
+	if err := app.RunWithArgs(context.Background(), []string{"plugin", "bye"}); err != nil || !ran || !app.IsFrozen() {
+		t.Fatalf("expected the late plugin command to run, got %v", err)
+	}
+}
+
+func TestFlagAndGroupHelp(t *testing.T) {
+	newApp := func() (*App, *strings.Builder) {
+		var out strings.Builder
+		app := New("t", "")
+		app.IO().WithOut(&out).WithErr(&strings.Builder{})
+		app.ErrorHandler().SuggestFlags(true)
+		app.DurationFlag("timeout", "Request timeout").Default(30 * time.Second).Short('t').
+			FromEnv("T_TIMEOUT").Placeholder("DURATION").Global().Back()
+		app.FlagGroup("output").Description("Output format").
+			BoolFlag("json", "JSON output").Back().
+			BoolFlag("yaml", "YAML output").Back().
+			MutuallyExclusive().EndGroup()
+		app.Command("deploy", "").
+			EnumFlag("format", "Manifest format", "json", "yaml").WithAliases(map[string]string{"yml": "yaml"}).
+			Required().Back()
+		app.Command("build", "").EnumFlag("format", "Build format", "tar", "zip").Back()
+		return app, &out
+	}
+
+	app, out := newApp()
+	if err := app.ShowFlagHelp("-t", out); err != nil {
+		t.Fatalf("ShowFlagHelp failed: %v", err)
+	}
+	want := "--timeout, -t DURATION\n  Request timeout\n\n" +
+		"  Type:         duration\n  Default:      30s\n  Environment:  T_TIMEOUT\n  Command:      t\n"
+	if out.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out, want)
+	}
+
+	app, out = newApp()
+	if err := app.RunWithArgs(context.Background(), []string{"help", "--flag", "json"}); err != nil {
+		t.Fatalf("help --flag failed: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "Group:    output - Output format\n  Rule:     Only one of these flags can be used at a time\n  Related:  --yaml\n") {
+		t.Fatalf("missing group details:\n%s", got)
+	}
+
+	// Unscoped lookups show every command's flag, scoped ones only the command's
+	app, out = newApp()
+	if err := app.RunWithArgs(context.Background(), []string{"help", "--flag=format"}); err != nil {
+		t.Fatalf("help --flag failed: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "Command:  t build") || !strings.Contains(got, "Command:  t deploy") {
+		t.Fatalf("expected both format flags:\n%s", got)
+	}
+	app, out = newApp()
+	if err := app.RunWithArgs(context.Background(), []string{"help", "deploy", "--flag", "format"}); err != nil {
+		t.Fatalf("help deploy --flag failed: %v", err)
+	}
+	got := out.String()
+	if strings.Contains(got, "t build") || !strings.Contains(got, "Values:   json, yaml (yml=yaml)\n") ||
+		!strings.Contains(got, "  Required\n") {
+		t.Fatalf("unexpected scoped flag help:\n%s", got)
+	}
+
+	app, out = newApp()
+	if err := app.RunWithArgs(context.Background(), []string{"help", "--group", "output"}); err != nil {
+		t.Fatalf("help --group failed: %v", err)
+	}
+	if got := out.String(); !strings.HasPrefix(got, "output - Output format:\n  --json") ||
+		!strings.Contains(got, "Note: ") {
+		t.Fatalf("unexpected group help:\n%s", got)
+	}
+
+	app, _ = newApp()
+	err := app.RunWithArgs(context.Background(), []string{"help", "--flag", "timout"})
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeUnknownFlag ||
+		!strings.Contains(strings.Join(cliErr.Suggestions, " "), "--timeout") {
+		t.Fatalf("expected an unknown flag error suggesting --timeout, got %v", err)
+	}
+	app, _ = newApp()
+	err = app.ShowGroupHelp("outptu", &strings.Builder{})
+	if !errors.As(err, &cliErr) || !strings.Contains(strings.Join(cliErr.Suggestions, " "), "output") {
+		t.Fatalf("expected an unknown group error suggesting output, got %v", err)
+	}
+
+	// Group violations point to the group help
+	app, _ = newApp()
+	err = app.RunWithArgs(context.Background(), []string{"--json", "--yaml"})
+	if err == nil || !strings.Contains(err.Error(), "Run 't help --group output'") {
+		t.Fatalf("expected a group help hint, got %v", err)
+	}
+}
+
+func TestShortFlagClusters(t *testing.T) {
+	var debug, level int
+	var force bool
+	var output string
+	app := New("t", "")
+	app.IO().WithOut(io.Discard).WithErr(io.Discard)
+	app.CountFlag("debug", "Debug level").Short('d').Global()
+	app.Command("build", "").
+		CountFlag("verbose", "More output").Short('v').Back().
+		BoolFlag("force", "").Short('f').Back().
+		StringFlag("output", "").Short('o').Back().
+		Action(func(ctx *Context) error {
+			debug, level = ctx.MustGlobalInt("debug", 0), ctx.MustInt("verbose", 0)
+			force, output = ctx.MustBool("force", false), ctx.MustString("output", "")
+			return nil
+		})
+
+	for _, tc := range []struct {
+		args   []string
+		debug  int
+		level  int
+		force  bool
+		output string
+	}{
+		{[]string{"build", "-vvo", "out.txt"}, 0, 2, false, "out.txt"},
+		{[]string{"build", "-vfvoout.txt"}, 0, 2, true, "out.txt"},
+		{[]string{"-dd", "build", "-v", "-v", "-fo", "a"}, 2, 2, true, "a"},
+		{[]string{"build", "--verbose=2", "-v", "-ov"}, 0, 3, false, "v"},
+		{[]string{"build", "-vdv"}, 1, 2, false, ""},
+	} {
+		debug, level, force, output = -1, -1, false, ""
+		if err := app.RunWithArgs(context.Background(), tc.args); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		if debug != tc.debug || level != tc.level || force != tc.force || output != tc.output {
+			t.Errorf("%v: debug %d, verbose %d, force %v, output %q", tc.args, debug, level, force, output)
+		}
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"build", "-vvo"}); err == nil {
+		t.Fatal("-vvo without a value: expected an error")
+	}
+}
+
+func TestOptionalValueFlags(t *testing.T) {
+	var out strings.Builder
+	var profile, color string
+	var level int
+	var args []string
+	app := New("t", "")
+	app.IO().WithOut(&out).WithErr(&out)
+	app.Command("run", "").
+		StringFlag("profile", "Profile to use").Short('p').OptionalValue("default").Placeholder("NAME").Back().
+		EnumFlag("color", "When to color", "auto", "always", "never").Default("never").OptionalValue("auto").Back().
+		IntFlag("level", "Level").OptionalValue(3).Back().
+		BoolFlag("force", "").Short('f').Back().
+		StringSliceArg("items", "").Variadic().
+		Action(func(ctx *Context) error {
+			profile, color, level = ctx.MustString("profile", ""), ctx.MustEnum("color", ""), ctx.MustInt("level", 0)
+			args = ctx.Args()
+			return nil
+		})
+
+	for _, tc := range []struct {
+		args    []string
+		profile string
+		color   string
+		level   int
+		rest    []string
+	}{
+		{[]string{}, "", "never", 0, []string{}},
+		{[]string{"--profile"}, "default", "never", 0, []string{}},
+		{[]string{"--profile=prod", "--color"}, "prod", "auto", 0, []string{}},
+		{[]string{"--profile", "prod"}, "default", "never", 0, []string{"prod"}},
+		{[]string{"--color=always", "--level", "x"}, "", "always", 3, []string{"x"}},
+		{[]string{"--level=7"}, "", "never", 7, []string{}},
+		{[]string{"-p", "prod"}, "default", "never", 0, []string{"prod"}},
+		{[]string{"-pprod"}, "prod", "never", 0, []string{}},
+		{[]string{"-fp", "x"}, "default", "never", 0, []string{"x"}},
+	} {
+		profile, color, level, args = "", "", 0, nil
+		if err := app.RunWithArgs(context.Background(), append([]string{"run"}, tc.args...)); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		if profile != tc.profile || color != tc.color || level != tc.level || len(args) != len(tc.rest) ||
+			(len(args) > 0 && args[0] != tc.rest[0]) {
+			t.Errorf("%v: profile %q, color %q, level %d, args %v", tc.args, profile, color, level, args)
+		}
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"run", "--color=rainbow"}); err == nil {
+		t.Fatal("--color=rainbow: expected an invalid value error")
+	}
+
+	out.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"run", "--help"}); err != nil ||
+		!strings.Contains(out.String(), "--profile, -p[=NAME]") || !strings.Contains(out.String(), "--color[=value]") {
+		t.Fatalf("help: %v\n%s", err, out.String())
+	}
+}
+
+func TestEnvCommand(t *testing.T) {
+	t.Setenv("T_TOKEN", "s3cret")
+	t.Setenv("T_PORT", "9090")
+	t.Setenv("T_LEVEL", "debug")
+	var cfg struct {
+		Level string `flag:"level" env:"T_LEVEL" default:"info"`
+		Owner string `flag:"owner" env:"T_OWNER" validate:"required"`
+	}
+	app, err := Config("t", "").FromEnv().FromFlags().Bind(&cfg).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	app.IO().WithOut(&out).WithErr(&out)
+	app.EnvCommand("env")
+	app.StringFlag("token", "API token").FromEnv("T_TOKEN").Secret()
+	app.Command("serve", "").
+		IntFlag("port", "Port").Default(8080).FromEnv("T_PORT", "PORT").Back().
+		Action(func(*Context) error { return nil })
+
+	// Runs although the required owner is missing
+	if err := app.RunWithArgs(context.Background(), []string{"env"}); err != nil {
+		t.Fatalf("env: %v\n%s", err, out.String())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := [][]string{
+		{"VARIABLE", "FLAG", "TYPE", "DEFAULT", "VALUE"},
+		{"PORT", "serve", "--port", "int", "8080", "-"},
+		{"T_LEVEL", "--level", "string", "info", "debug"},
+		{"T_OWNER", "--owner", "string", "-", "-"},
+		{"T_PORT", "serve", "--port", "int", "8080", "9090"},
+		{"T_TOKEN", "--token", "string", "-", "<redacted>"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("listing:\n%s", out.String())
+	}
+	for i, line := range lines {
+		if got := strings.Fields(line); !slices.Equal(got, want[i]) {
+			t.Errorf("line %d = %q, want %q", i, got, want[i])
+		}
+	}
+
+	var direct strings.Builder
+	if err := app.PrintEnvHelp(&direct); err != nil || direct.String() != out.String() {
+		t.Fatalf("PrintEnvHelp: %v\n%s", err, direct.String())
+	}
+}
+
+func TestEnvHelpSecretConfigField(t *testing.T) {
+	t.Setenv("T_KEY", "hunter2")
+	t.Setenv("T_DB_PASSWORD", "s3cret")
+	var cfg struct {
+		Key  string `flag:"key" env:"T_KEY" default:"dev-key" secret:"true"`
+		Name string `flag:"name" env:"T_NAME" default:"svc"`
+		DB   struct {
+			Password string `env:"T_DB_PASSWORD" secret:"true"`
+		} `group:"db"`
+	}
+	app, err := Config("t", "").FromEnv().FromFlags().Bind(&cfg).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := app.PrintEnvHelp(&out); err != nil {
+		t.Fatal(err)
+	}
+	listing := out.String()
+	if strings.Count(listing, "<redacted>") != 2 || strings.Contains(listing, "hunter2") ||
+		strings.Contains(listing, "s3cret") || strings.Contains(listing, "dev-key") || !strings.Contains(listing, "svc") {
+		t.Fatalf("listing:\n%s", listing)
+	}
+	// The generated flag is Secret too
+	if flag := app.flags["key"]; flag == nil || !flag.secret || app.flags["name"].secret {
+		t.Fatalf("generated flags: %+v", app.flags)
+	}
+}
+
+func TestSubcommandMiddlewareInheritance(t *testing.T) {
+	var trace []string
+	mark := func(name string) middleware.Middleware {
+		return func(next middleware.ActionFunc) middleware.ActionFunc {
+			return func(ctx middleware.Context) error {
+				trace = append(trace, name)
+				return next(ctx)
+			}
+		}
+	}
+	action := func(*Context) error {
+		trace = append(trace, "action")
+		return nil
+	}
+
+	app := New("t", "").Use(mark("app"))
+	cfg := app.Command("config", "").Use(mark("config")).InheritMiddleware()
+	cfg.Command("validate", "").Use(mark("validate")).Action(action)
+	cfg.Command("raw", "").NoInheritMiddleware().Action(action)
+	plain := app.Command("plain", "").Use(mark("plain"))
+	plain.Command("child", "").Action(action)
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"config", "validate"}, "app,config,validate,action"},
+		{[]string{"config", "raw"}, "app,action"},
+		{[]string{"plain", "child"}, "app,action"},
+	} {
+		trace = nil
+		if err := app.RunWithArgs(context.Background(), tc.args); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		if got := strings.Join(trace, ","); got != tc.want {
+			t.Errorf("%v: got %s, want %s", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestGlobalFlagsAfterCommand(t *testing.T) {
+	newApp := func() *App {
+		app := New("t", "")
+		app.BoolFlag("verbose", "").Short('v').Global().Back()
+		app.BoolFlag("quiet", "").Short('q').Global().Back()
+		app.Command("serve", "").IntFlag("port", "").Short('p').Back().Build()
+		return app
+	}
+
+	res, err := NewParser(newApp()).Parse([]string{"serve", "--verbose", "-qp", "80"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !res.MustGetGlobalBool("verbose", false) || !res.MustGetGlobalBool("quiet", false) {
+		t.Fatalf("expected global flags stored in global maps")
+	}
+	if res.HasFlag("verbose") || res.MustGetInt("port", 0) != 80 {
+		t.Fatalf("unexpected local flag storage")
+	}
+
+	strict := newApp().StrictGlobalFlags(true)
+	if _, err = NewParser(strict).Parse([]string{"--verbose", "serve", "-p", "80"}); err != nil {
+		t.Fatalf("strict: global flag before command should parse: %v", err)
+	}
+	for _, args := range [][]string{{"serve", "--verbose"}, {"serve", "-p", "80", "-v"}, {"serve", "-qp", "80"}} {
+		_, err = NewParser(strict).Parse(args)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Type != ErrorTypeInvalidFlag {
+			t.Fatalf("strict %v: expected invalid flag error, got %v", args, err)
+		}
+	}
+}
+
+func TestAllowFromFileValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("-----CERT-----\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var cert, data, plain string
+	var port int
+	app := New("t", "")
+	app.IO().WithIn(strings.NewReader("payload\r\n"))
+	app.Command("send", "").
+		StringFlag("cert", "").AllowFromFile().Back().
+		StringFlag("data", "").AllowFromFile().Back().
+		StringFlag("plain", "").Back().
+		IntFlag("port", "").AllowFromFile().Back().
+		Action(func(ctx *Context) error {
+			cert, _ = ctx.String("cert")
+			data, _ = ctx.String("data")
+			plain, _ = ctx.String("plain")
+			port, _ = ctx.Int("port")
+			return nil
+		})
+
+	args := []string{"send", "--cert", "@" + path, "--data", "-", "--plain", "@" + path, "--port", "8080"}
+	if err := app.RunWithArgs(context.Background(), args); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if cert != "-----CERT-----" || data != "payload" || plain != "@"+path || port != 8080 {
+		t.Fatalf("got cert=%q data=%q plain=%q port=%d", cert, data, plain, port)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"send", "--cert=@@literal"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if cert != "@literal" {
+		t.Fatalf("escaped value: %q", cert)
+	}
+
+	_, err := NewParser(app).Parse([]string{"send", "--cert", "@" + path + ".missing"})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Type != ErrorTypeInvalidValue || perr.Flag != "cert" {
+		t.Fatalf("expected invalid value error for missing file, got %v", err)
+	}
+
+	app.IO().WithIn(strings.NewReader("x"))
+	_, err = NewParser(app).Parse([]string{"send", "--cert", "-", "--data", "-"})
+	if !errors.As(err, &perr) || !strings.Contains(perr.Message, "already consumed") {
+		t.Fatalf("expected stdin reuse error, got %v", err)
+	}
+}
+
+func TestHelpCommandAndTopics(t *testing.T) {
+	newApp := func(out *strings.Builder) *App {
+		app := New("t", "demo")
+		app.IO().WithOut(out)
+		app.HelpTopic("environment", "Environment variables\n\nT_HOME sets the home directory.")
+		srv := app.Command("serve", "Run the server").Alias("s")
+		srv.Command("up", "Start it").
+			StringArg("target", "").Required().Back().
+			Action(func(*Context) error { return errors.New("action must not run") })
+		return app
+	}
+
+	helpOf := func(args ...string) string {
+		var out strings.Builder
+		if err := newApp(&out).RunWithArgs(context.Background(), args); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	cases := [][2][]string{
+		{{"help"}, {"--help"}},
+		{{"help", "serve"}, {"serve", "--help"}},
+		{{"help", "s"}, {"serve", "--help"}},
+		{{"help", "serve", "up"}, {"serve", "up", "--help"}},
+	}
+	for _, tc := range cases {
+		if got, want := helpOf(tc[0]...), helpOf(tc[1]...); got != want {
+			t.Fatalf("%v output differs from %v:\n%s\n---\n%s", tc[0], tc[1], got, want)
+		}
+	}
+
+	root := helpOf("help")
+	if !strings.Contains(root, "Topics:\n  environment  Environment variables\n") {
+		t.Fatalf("missing topics section:\n%s", root)
+	}
+	if got := helpOf("help", "environment"); got != "Environment variables\n\nT_HOME sets the home directory.\n" {
+		t.Fatalf("topic output: %q", got)
+	}
+
+	var out strings.Builder
+	err := newApp(&out).RunWithArgs(context.Background(), []string{"help", "serve", "dwn"})
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeUnknownCommand {
+		t.Fatalf("expected unknown command error, got %v", err)
+	}
+
+	// A user-defined "help" command takes precedence over the built-in one
+	ran := false
+	app := newApp(&out)
+	app.Command("help", "custom").Action(func(*Context) error { ran = true; return nil })
+	if err := app.RunWithArgs(context.Background(), []string{"help"}); err != nil || !ran {
+		t.Fatalf("custom help command not run: %v", err)
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	var out strings.Builder
+	app := New("t", "").VersionInfo(BuildInfo{Version: "1.2.3", Commit: "abc123", Date: "2024-05-01", BuiltBy: "ci"})
+	app.IO().WithOut(&out)
+	app.Command("serve", "").Action(func(*Context) error { return nil })
+
+	if err := app.RunWithArgs(context.Background(), []string{"--version"}); err != nil {
+		t.Fatalf("--version: %v", err)
+	}
+	flagOut := out.String()
+	for _, want := range []string{"t 1.2.3\n", "Commit:    abc123", "Built:     2024-05-01", "Built by:  ci", "Go:        " + runtime.Version(), "Platform:  " + runtime.GOOS + "/" + runtime.GOARCH} {
+		if !strings.Contains(flagOut, want) {
+			t.Fatalf("missing %q in:\n%s", want, flagOut)
+		}
+	}
+
+	out.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"version"}); err != nil {
+		t.Fatalf("version command: %v", err)
+	}
+	if out.String() != flagOut {
+		t.Fatalf("version command differs from --version:\n%s\n---\n%s", out.String(), flagOut)
+	}
+
+	out.Reset()
+	app.VersionTemplate("{{.Name}}@{{.Version}} ({{.Commit}})\n")
+	if err := app.RunWithArgs(context.Background(), []string{"version"}); err != nil {
+		t.Fatalf("custom template: %v", err)
+	}
+	if out.String() != "t@1.2.3 (abc123)\n" {
+		t.Fatalf("custom template output: %q", out.String())
+	}
+
+	// A user-defined version command is left alone
+	ran := false
+	custom := New("t", "").VersionInfo(BuildInfo{Version: "1.0.0"})
+	custom.IO().WithOut(&out)
+	custom.Command("version", "").Action(func(ctx *Context) error {
+		ran = ctx.AppBuildInfo().Version == "1.0.0"
+		return nil
+	})
+	if err := custom.RunWithArgs(context.Background(), []string{"version"}); err != nil || !ran {
+		t.Fatalf("custom version command not run: %v", err)
+	}
+}
+
+func TestOnInvocation(t *testing.T) {
+	var infos []InvocationInfo
+	app := New("my-tool", "").Version("2.0.0").
+		OnInvocation(func(info InvocationInfo) { infos = append(infos, info) }).
+		TelemetryOptOutEnv("CI_NO_STATS")
+	app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+	app.BoolFlag("verbose", "").Global().Back()
+	srv := app.Command("server", "")
+	srv.Command("up", "").
+		StringFlag("token", "").Back().
+		IntFlag("port", "").Default(80).Back().
+		Action(func(*Context) error { return nil })
+	srv.Command("down", "").Action(func(*Context) error { return &ExitError{Code: 4} })
+
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("MY_TOOL_NO_TELEMETRY", "0")
+	t.Setenv("CI_NO_STATS", "")
+
+	_ = app.RunWithArgs(context.Background(), []string{"--verbose", "server", "up", "--token", "s3cr3t"})
+	_ = app.RunWithArgs(context.Background(), []string{"server", "down"})
+	_ = app.RunWithArgs(context.Background(), []string{"server", "up", "--bogus"})
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 invocations, got %d", len(infos))
+	}
+
+	first := infos[0]
+	if first.App != "my-tool" || first.Version != "2.0.0" || first.Command != "server up" ||
+		strings.Join(first.Flags, ",") != "token,verbose" || first.ExitCode != 0 || first.ErrorCategory != "" {
+		t.Fatalf("unexpected first invocation: %+v", first)
+	}
+	if strings.Contains(fmt.Sprintf("%+v", first), "s3cr3t") {
+		t.Fatalf("invocation leaks values: %+v", first)
+	}
+	if infos[1].Command != "server down" || infos[1].ExitCode != 4 || infos[1].ErrorCategory != "exit" {
+		t.Fatalf("unexpected exit invocation: %+v", infos[1])
+	}
+	if infos[2].ErrorCategory != string(ErrorTypeUnknownFlag) || infos[2].ExitCode != 2 {
+		t.Fatalf("unexpected parse error invocation: %+v", infos[2])
+	}
+
+	for _, env := range []string{"DO_NOT_TRACK", "MY_TOOL_NO_TELEMETRY", "CI_NO_STATS"} {
+		t.Setenv(env, "1")
+		if app.TelemetryEnabled() {
+			t.Fatalf("%s=1 should opt out", env)
+		}
+		_ = app.RunWithArgs(context.Background(), []string{"server", "up"})
+		if len(infos) != 3 {
+			t.Fatalf("hook fired despite %s=1", env)
+		}
+		t.Setenv(env, "false")
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	var events []Event
+	app := New("app", "").OnEvent(func(e Event) { events = append(events, e) })
+	app.IO().WithOut(&strings.Builder{}).WithErr(&strings.Builder{})
+	srv := app.Command("server", "")
+	srv.Command("up", "").Action(func(*Context) error { return nil })
+	srv.Command("down", "").Action(func(*Context) error { return errors.New("busy") })
+
+	types := func() string {
+		names := make([]string, len(events))
+		for i, e := range events {
+			names[i] = string(e.Type)
+		}
+		events = nil
+		return strings.Join(names, ",")
+	}
+
+	_ = app.RunWithArgs(context.Background(), []string{"server", "up"})
+	if got := types(); got != "parse_completed,command_resolved,action_started,action_finished" {
+		t.Fatalf("events: %s", got)
+	}
+
+	_ = app.RunWithArgs(context.Background(), []string{"server", "down"})
+	last := events[len(events)-1]
+	if last.Type != EventActionFinished || last.Command != "server down" || last.Context == nil ||
+		last.Err == nil || last.Err.Error() != "busy" || last.Result == nil {
+		t.Fatalf("action finished: %+v", last)
+	}
+	types()
+
+	_ = app.RunWithArgs(context.Background(), []string{"server", "up", "--bogus"})
+	if len(events) != 2 || events[0].Type != EventParseCompleted || events[0].Err == nil ||
+		events[1].Type != EventErrorDisplayed || !strings.Contains(events[1].Err.Error(), "--bogus") {
+		t.Fatalf("parse failure events: %+v", events)
+	}
+	var cliErr *CLIError
+	if !errors.As(events[1].Err, &cliErr) || cliErr.Type != ErrorTypeUnknownFlag {
+		t.Fatalf("displayed error: %v", events[1].Err)
+	}
+	types()
+
+	// Help is not an action
+	_ = app.RunWithArgs(context.Background(), []string{"server", "up", "--help"})
+	if got := types(); got != "parse_completed,command_resolved" {
+		t.Fatalf("help events: %s", got)
+	}
+}
+
+func TestConfig_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	file := `{
+		"host": "localhost",
+		"port": 8080,
+		"db": {"name": "dev"},
+		"profiles": {
+			"prod": {"host": "api.example.com", "db": {"name": "main"}},
+			"staging": {"host": "staging.example.com"}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	type C struct {
+		Host string `flag:"host"`
+		Port int    `flag:"port"`
+		DB   struct {
+			Name string `flag:"name"`
+		} `group:"db"`
+	}
+	var cfg C
+	cb := Config("app", "").FromFile(path).FromFlags().Profiles("dev", "staging", "prod").ProfileFlag("profile")
+	app, err := cb.Bind(&cfg).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	app.Action(func(*Context) error { return nil })
+	t.Setenv("APP_PROFILE", "")
+
+	run := func(args ...string) {
+		t.Helper()
+		cfg = C{}
+		if rErr := app.RunWithArgs(context.Background(), args); rErr != nil {
+			t.Fatalf("%v: %v", args, rErr)
+		}
+	}
+
+	run()
+	if cfg.Host != "localhost" || cfg.DB.Name != "dev" || cb.ActiveProfile() != "" {
+		t.Fatalf("base config: %+v (profile %q)", cfg, cb.ActiveProfile())
+	}
+
+	run("--profile", "prod")
+	if cfg.Host != "api.example.com" || cfg.Port != 8080 || cfg.DB.Name != "main" || cb.ActiveProfile() != "prod" {
+		t.Fatalf("prod profile: %+v", cfg)
+	}
+
+	// Flags still beat the profile, and the env var selects it too
+	t.Setenv("APP_PROFILE", "staging")
+	run("--host", "override")
+	if cfg.Host != "override" || cfg.DB.Name != "dev" || cb.ActiveProfile() != "staging" {
+		t.Fatalf("staging via env: %+v (profile %q)", cfg, cb.ActiveProfile())
+	}
+
+	t.Setenv("APP_PROFILE", "")
+	run()
+	if cfg.DB.Name != "dev" {
+		t.Fatalf("profile overlay leaked into base config: %+v", cfg)
+	}
+
+	// Config-only mode reads the profile from the environment
+	var only C
+	t.Setenv("APP_PROFILE", "qa")
+	_, err = Config("app", "").FromFile(path).Profiles("prod").ProfileFlag("profile").Bind(&only).Build()
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "qa"`) {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestConfig_FromFileDiscovery(t *testing.T) {
+	root := t.TempDir()
+	system := filepath.Join(root, "etc")
+	xdg := filepath.Join(root, "xdg")
+	repo := filepath.Join(root, "repo")
+	work := filepath.Join(repo, "svc", "api")
+
+	write := func(dir, body string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "app.json"), []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	write(filepath.Join(system, "app"), `{"host": "system", "port": 1, "user": "root", "level": "warn"}`)
+	write(filepath.Join(xdg, "app"), `{"host": "user", "port": 2, "user": "me"}`)
+	write(repo, `{"host": "project", "port": 3}`)
+	write(work, `{"host": "local"}`)
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	// Above the repository root: never reached by the project walk
+	write(root, `{"level": "outside"}`)
+
+	prevRoot := systemConfigRoot
+	systemConfigRoot = func() string { return system }
+	t.Cleanup(func() { systemConfigRoot = prevRoot })
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	prevDir, _ := os.Getwd()
+	if err := os.Chdir(work); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(prevDir) })
+
+	type C struct {
+		Host  string `flag:"host"`
+		Port  int    `flag:"port"`
+		User  string `flag:"user"`
+		Level string `flag:"level"`
+	}
+	var cfg C
+	cb := Config("app", "")
+	if _, err := cb.FromFileDiscovery("app.json").Bind(&cfg).Build(); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if cfg != (C{Host: "local", Port: 3, User: "me", Level: "warn"}) {
+		t.Fatalf("unexpected merge: %+v", cfg)
+	}
+
+	if got := cb.app.ConfigDir(); got != filepath.Join(xdg, "app") {
+		t.Fatalf("ConfigDir: %q", got)
+	}
+	files := cb.app.ConfigFiles("app.json")
+	if len(files) != 4 || !strings.HasPrefix(files[0], system) || !strings.HasSuffix(files[3], filepath.Join("api", "app.json")) {
+		t.Fatalf("ConfigFiles: %v", files)
+	}
+}
+
+func TestFlagDefaultFunc(t *testing.T) {
+	calls := 0
+	var help strings.Builder
+	app := New("t", "")
+	app.IO().WithOut(&help)
+	app.StringFlag("host", "Host name").DefaultFunc(func() string { calls++; return "computed" }).FromEnv("T_HOST").Back()
+	app.IntFlag("workers", "Worker count").DefaultFunc(func() int { return 8 }).DefaultText("number of CPUs").Back()
+	if calls != 0 {
+		t.Fatalf("default computed at construction")
+	}
+
+	t.Setenv("T_HOST", "")
+	res, err := NewParser(app).Parse(nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if host := res.MustGetString("host", ""); host != "computed" || calls != 1 {
+		t.Fatalf("host=%q calls=%d", host, calls)
+	}
+	if workers := res.MustGetInt("workers", 0); workers != 8 || res.Source("workers") != ValueSourceDefault {
+		t.Fatalf("workers=%d source=%v", workers, res.Source("workers"))
+	}
+
+	// Explicit and env values skip the computation entirely
+	if res, err = NewParser(app).Parse([]string{"--host", "given"}); err != nil || res.MustGetString("host", "") != "given" {
+		t.Fatalf("explicit: %v", err)
+	}
+	t.Setenv("T_HOST", "from-env")
+	if res, err = NewParser(app).Parse(nil); err != nil || res.MustGetString("host", "") != "from-env" {
+		t.Fatalf("env: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("default computed %d times", calls)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	out := help.String()
+	if !strings.Contains(out, "Host name (default: auto)") || !strings.Contains(out, "Worker count (default: number of CPUs)") {
+		t.Fatalf("help output:\n%s", out)
+	}
+}
+
+func TestVisibleIfAndEnabledIf(t *testing.T) {
+	experimental, windows := false, false
+	var out strings.Builder
+	app := New("t", "")
+	app.IO().WithOut(&out)
+	app.BoolFlag("preview", "Preview mode").VisibleIf(func() bool { return experimental }).Back()
+	app.StringFlag("registry-key", "Registry key").FromEnv("T_REGISTRY_KEY").
+		EnabledIf(func() bool { return windows }).Back()
+	app.Command("svc", "Manage the Windows service").EnabledIf(func() bool { return windows }).
+		Action(func(*Context) error { return nil })
+	app.Command("beta", "Beta command").VisibleIf(func() bool { return experimental }).
+		Action(func(*Context) error { return nil })
+	app.Command("run", "Run").Action(func(*Context) error { return nil })
+
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	for _, hidden := range []string{"--preview", "--registry-key", "svc", "beta"} {
+		if strings.Contains(out.String(), hidden) {
+			t.Fatalf("%s should be hidden:\n%s", hidden, out.String())
+		}
+	}
+
+	// Invisible but enabled items still work
+	if _, err := NewParser(app).Parse([]string{"--preview", "beta"}); err != nil {
+		t.Fatalf("visible-if items must stay usable: %v", err)
+	}
+
+	for _, args := range [][]string{{"--registry-key", "x", "run"}, {"svc"}} {
+		_, err := NewParser(app).Parse(args)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Type != ErrorTypeUnavailable || !strings.Contains(perr.Message, "is not available") {
+			t.Fatalf("%v: expected unavailable error, got %v", args, err)
+		}
+	}
+	if code := app.ExitCodes().resolve(app.RunWithArgs(context.Background(), []string{"svc"})); code != 2 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+
+	// Disabled flags take no value from the environment either
+	t.Setenv("T_REGISTRY_KEY", "secret")
+	result, err := NewParser(app).Parse([]string{"run"})
+	if got, ok := result.GetString("registry-key"); err != nil || ok || result.Source("registry-key") != ValueSourceNone {
+		t.Fatalf("disabled flag set from env: got=%q ok=%v err=%v", got, ok, err)
+	}
+
+	experimental, windows = true, true
+	app.invalidateHelp()
+	out.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	for _, shown := range []string{"--preview", "--registry-key", "svc", "beta"} {
+		if !strings.Contains(out.String(), shown) {
+			t.Fatalf("%s should be shown:\n%s", shown, out.String())
+		}
+	}
+	if _, err := NewParser(app).Parse([]string{"--registry-key", "x", "svc"}); err != nil {
+		t.Fatalf("enabled items rejected: %v", err)
+	}
+	result, err = NewParser(app).Parse([]string{"run"})
+	if got, _ := result.GetString("registry-key"); err != nil || got != "secret" {
+		t.Fatalf("enabled flag not set from env: got=%q err=%v", got, err)
+	}
+}
+
+func TestAppParse(t *testing.T) {
+	ran := false
+	app := New("t", "").
+		Before(func(*Context) error { ran = true; return nil })
+	app.Use(func(next middleware.ActionFunc) middleware.ActionFunc { ran = true; return next })
+	app.Command("deploy", "").
+		StringFlag("env", "").Default("staging").Back().
+		StringArg("service", "").Required().Back().
+		Action(func(*Context) error { ran = true; return nil })
+
+	res, err := app.Parse([]string{"deploy", "--env", "prod", "api"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if ran {
+		t.Fatalf("Parse must not run hooks, middleware or actions")
+	}
+	if res.Command == nil || res.Command.Name() != "deploy" || res.MustGetString("env", "") != "prod" || res.MustGetArgString("service", "") != "api" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// --help is reported, not handled
+	res, err = app.Parse([]string{"deploy", "--help"})
+	if err != nil || !res.MustGetBool("help", false) {
+		t.Fatalf("help: %v", err)
+	}
+
+	app.ErrorHandler().SuggestCommands(true)
+	_, err = app.Parse([]string{"deplo"})
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeUnknownCommand || len(cliErr.Suggestions) == 0 {
+		t.Fatalf("expected unknown command error with suggestion, got %#v", err)
+	}
+}
+
+func TestArgumentFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return path
+	}
+	nested := write("nested.txt", "--tag 'two words'\n")
+	main := write("args.txt", "--out C:\\build\\bin \"say \\\"hi\\\"\"\n\tplain\\ space @"+nested+"\n")
+	ca := write("ca.pem", "CERT\n")
+
+	var got []string
+	var out, tag, cert string
+	app := New("t", "").AllowArgumentFiles(true)
+	app.Command("build", "").
+		StringFlag("out", "").Back().
+		StringFlag("tag", "").Back().
+		StringFlag("cert", "").AllowFromFile().Back().
+		StringSliceArg("items", "").Variadic().
+		Action(func(ctx *Context) error {
+			got = ctx.Args()
+			out, _ = ctx.String("out")
+			tag, _ = ctx.String("tag")
+			cert, _ = ctx.String("cert")
+			return nil
+		})
+
+	args := []string{"build", "@" + main, "--cert", "@" + ca, "@@literal", "--", "@" + main}
+	if err := app.RunWithArgs(context.Background(), args); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if out != `C:\build\bin` || tag != "two words" || cert != "CERT" {
+		t.Fatalf("flags: out=%q tag=%q cert=%q", out, tag, cert)
+	}
+	if strings.Join(got, "|") != `say "hi"|plain space|@literal|@`+main {
+		t.Fatalf("args: %q", got)
+	}
+	if strings.Join(app.rawArgs, " ") != strings.Join(args, " ") {
+		t.Fatalf("raw args must stay unexpanded: %v", app.rawArgs)
+	}
+
+	// An escaped value of an AllowFromFile flag is left to the flag, as
+	// without argument files
+	if err := app.RunWithArgs(context.Background(), []string{"build", "--cert", "@@literal"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if cert != "@literal" {
+		t.Fatalf("cert=%q", cert)
+	}
+
+	loop := write("loop.txt", "@"+filepath.Join(dir, "loop.txt"))
+	bad := write("bad.txt", "'unterminated")
+	for _, arg := range []string{"@" + loop, "@" + bad, "@" + filepath.Join(dir, "missing.txt")} {
+		_, err := app.Parse([]string{"build", arg})
+		var cliErr *CLIError
+		if !errors.As(err, &cliErr) || cliErr.Type != ErrorTypeInvalidArgument {
+			t.Fatalf("%s: expected invalid argument error, got %v", arg, err)
+		}
+	}
+
+	// Disabled by default
+	plain := New("t", "").RestArgs().Action(func(ctx *Context) error { got = ctx.Args(); return nil })
+	if err := plain.RunWithArgs(context.Background(), []string{"@" + main}); err != nil || got[0] != "@"+main {
+		t.Fatalf("expansion must be opt-in: %v %v", got, err)
+	}
+}
+
+func TestMessageCatalog(t *testing.T) {
+	newApp := func(out *strings.Builder) *App {
+		app := New("t", "demo")
+		app.IO().WithOut(out)
+		app.BoolFlag("verbose", "Verbose output")
+		app.Command("deploy", "Deploy it").Action(func(*Context) error { return nil })
+		return app
+	}
+
+	var out strings.Builder
+	app := newApp(&out).SetLocale("de_DE.UTF-8")
+	if app.Locale() != "de-de" {
+		t.Fatalf("locale: %q", app.Locale())
+	}
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Verwendung:", "Befehle:", "Hilfe anzeigen", `Verwenden Sie "t BEFEHL --help"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in:\n%s", want, out.String())
+		}
+	}
+
+	err := app.RunWithArgs(context.Background(), []string{"--nope"})
+	if err == nil || !strings.Contains(err.Error(), "Fehler: unbekannte Option: --nope") {
+		t.Fatalf("localized error: %v", err)
+	}
+
+	// Value, argument and limit errors are localized too
+	typed := New("t", "").SetLocale("de")
+	typed.IO().WithOut(&out).WithErr(&out)
+	typed.IntFlag("port", "")
+	typed.IntArg("count", "")
+	for args, want := range map[string]string{
+		"--port abc":  "Fehler: ungültiger Wert vom Typ integer",
+		"x":           "Fehler: ungültiger Wert vom Typ integer für Argument 'count': x",
+		"--port=7 1x": "für Argument 'count': 1x",
+	} {
+		err := typed.RunWithArgs(context.Background(), strings.Fields(args))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", args, err, want)
+		}
+	}
+	_, err = NewParser(typed).SetLimits(ParseLimits{MaxArgs: 3}).ParseStrict([]string{"a", "b", "c", "d"})
+	var limitErr *ParseError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("limit error: %v", err)
+	}
+	if got := typed.msgf(limitErr.msgID, limitErr.msgArgs...); got != "zu viele Argumente: 4 (höchstens 3)" {
+		t.Errorf("localized limit error: %q", got)
+	}
+
+	// User catalogs: exact locale first, then base language, then English
+	app = newApp(&out).
+		RegisterMessages("de-DE", Messages{MsgCommands: "Kommandos:"}).
+		RegisterMessages("fr", Messages{MsgUsage: "Utilisation :"}).
+		SetLocale("de-DE")
+	if got := app.Message(MsgCommands); got != "Kommandos:" {
+		t.Fatalf("registered message: %q", got)
+	}
+	if got := app.Message(MsgUsage); got != "Verwendung:" {
+		t.Fatalf("base language fallback: %q", got)
+	}
+	app.SetLocale("fr")
+	if app.Message(MsgUsage) != "Utilisation :" || app.Message(MsgFlags) != "Flags:" {
+		t.Fatalf("english fallback: %q %q", app.Message(MsgUsage), app.Message(MsgFlags))
+	}
+
+	// Overrides apply in every locale; the English default is unchanged
+	out.Reset()
+	app = newApp(&out).SetMessage(MsgUnknownFlag, "no such option --%s")
+	err = app.RunWithArgs(context.Background(), []string{"--nope"})
+	if err == nil || !strings.Contains(err.Error(), "Error: no such option --nope") {
+		t.Fatalf("override: %v", err)
+	}
+	if got := New("t", "").ExitCodes().Table()[0].Description; got != "Successful termination" {
+		t.Fatalf("exit description: %q", got)
+	}
+	if got := New("t", "").SetLocale("de").ExitCodes().Table()[0].Description; got != "Erfolgreich beendet" {
+		t.Fatalf("localized exit description: %q", got)
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("CLICOLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "1")
+
+	var out strings.Builder
+	app := New("t", "demo").SetTheme(snapio.MonochromeTheme())
+	app.IO().WithOut(&out).ForceColorLevel(1)
+	app.BoolFlag("verbose", "Verbose output").Short('v')
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"\x1b[1;4mUsage:\x1b[0m", "\x1b[1m--verbose\x1b[0m, \x1b[1m-v\x1b[0m"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in:\n%q", want, out.String())
+		}
+	}
+
+	err := app.RunWithArgs(context.Background(), []string{"--nope"})
+	if err == nil || !strings.HasPrefix(err.Error(), "\x1b[1mError: unknown flag: --nope\x1b[0m") {
+		t.Fatalf("styled error: %q", err)
+	}
+
+	// NO_COLOR wins over CLICOLOR_FORCE; CLICOLOR=0 disables auto detection
+	t.Setenv("NO_COLOR", "1")
+	if app.IO().SupportsColor() {
+		t.Fatal("NO_COLOR must disable color")
+	}
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	t.Setenv("CLICOLOR", "0")
+	if app.IO().SupportsColor() {
+		t.Fatal("CLICOLOR=0 must disable color")
+	}
+
+	if theme, ok := snapio.ThemeByName("high-contrast", app.IO()); !ok || theme.ErrorStyle == nil {
+		t.Fatal("high-contrast theme missing")
+	}
+	if _, ok := snapio.ThemeByName("neon", app.IO()); ok {
+		t.Fatal("unknown theme resolved")
+	}
+}
+
+func TestHelpPager(t *testing.T) {
+	var out strings.Builder
+	app := New("t", "demo").HelpPager(true)
+	app.IO().WithOut(&out)
+	app.HelpTopic("env", "Environment")
+	app.Command("serve", "Run the server")
+	for _, args := range [][]string{{"--help"}, {"serve", "--help"}, {"help", "env"}} {
+		out.Reset()
+		if err := app.RunWithArgs(context.Background(), args); err != nil {
+			t.Fatal(err)
+		}
+		if out.Len() == 0 {
+			t.Fatalf("%v: help must be written directly when not on a terminal", args)
+		}
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	newApp := func() *App {
+		app := New("t", "demo")
+		app.Command("serve", "Serve").
+			IntFlag("port", "Port").Back().
+			Action(func(*Context) error { return nil })
+		return app
+	}
+
+	// The failing argument is recorded; for a consumed value it is the value
+	_, err := NewParser(newApp()).Parse([]string{"serve", "--port", "http"})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Index != 2 || parseErr.Token != "http" {
+		t.Fatalf("position: %+v", err)
+	}
+	_, err = NewParser(newApp()).Parse([]string{"serve", "--prot", "80"})
+	if !errors.As(err, &parseErr) || parseErr.Index != 1 || parseErr.Token != "--prot" {
+		t.Fatalf("position: %+v", err)
+	}
+
+	app := newApp()
+	app.ErrorHandler().ShowPosition(true)
+	err = app.RunWithArgs(context.Background(), []string{"serve", "--prot", "my value"})
+	want := "Error: unknown flag: --prot\n  serve --prot 'my value'\n        ^^^^^^"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got:\n%v\nwant:\n%s", err, want)
+	}
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) || cliErr.Context["token"] != "--prot" || cliErr.Context["index"] != 1 {
+		t.Fatalf("context: %+v", err)
+	}
+
+	// Long command lines are elided around the offending argument
+	args := []string{"serve", "a", "b", "c", "d", "e", "--nope", "f", "g", "h", "i"}
+	err = app.RunWithArgs(context.Background(), args)
+	want = "Error: unknown flag: --nope\n  ... c d e --nope f g h ...\n            ^^^^^^"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got:\n%v\nwant:\n%s", err, want)
+	}
+
+	// Secret values next to the failing argument are not echoed
+	app = newApp()
+	app.ErrorHandler().ShowPosition(true)
+	app.StringFlag("password", "Password").Secret()
+	err = app.RunWithArgs(context.Background(), []string{"--password", "hunter2", "serve", "--port", "x"})
+	if err == nil || strings.Contains(err.Error(), "hunter2") || !strings.Contains(err.Error(), "<redacted>") {
+		t.Fatalf("secret echoed: %v", err)
+	}
+	err = app.RunWithArgs(context.Background(), []string{"serve", "--password=hunter2", "--nope"})
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("secret echoed: %v", err)
+	}
+
+	// Errors not tied to an argument print no position
+	app = New("t", "demo")
+	app.ErrorHandler().ShowPosition(true)
+	app.Command("run", "Run").
+		StringArg("target", "Target").Required().Back().
+		Action(func(*Context) error { return nil })
+	err = app.RunWithArgs(context.Background(), []string{"run"})
+	if err == nil || strings.Contains(err.Error(), "^") {
+		t.Fatalf("unexpected position: %v", err)
+	}
+}
+
+func TestDebugTrace(t *testing.T) {
+	var errOut strings.Builder
+	app := New("t", "demo")
+	app.IO().WithErr(&errOut)
+	app.Use(middleware.Recovery())
+	app.Before(func(*Context) error { return nil })
+	app.Command("serve", "Serve").
+		IntFlag("port", "Port").Back().
+		After(func(*Context) error { return nil }).
+		Action(func(*Context) error { return nil })
+
+	// Off by default
+	if err := app.RunWithArgs(context.Background(), []string{"serve"}); err != nil || errOut.Len() != 0 {
+		t.Fatalf("unexpected trace: %v %q", err, errOut.String())
+	}
+
+	app.Debug(true)
+	if err := app.RunWithArgs(context.Background(), []string{"serve", "--port", "81"}); err != nil {
+		t.Fatal(err)
+	}
+	trace := errOut.String()
+	for _, want := range []string{
+		`start      t ["serve" "--port" "81"]`,
+		`arg[0] ["serve"] -> command-flags cmd=serve`,
+		`arg[1] ["--port" "81"] -> command-flags cmd=serve`,
+		"command    serve",
+		"--port (flag)",
+		"hook       app before done in",
+		"middleware middleware.Recovery",
+		"action     command action done in",
+		"hook       command after done in",
+		"done       run done in",
+		"io         stdin tty=",
+	} {
+		if !strings.Contains(trace, want) {
+			t.Fatalf("trace missing %q:\n%s", want, trace)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSpace(trace), "\n") {
+		if !strings.HasPrefix(line, "[snap ") {
+			t.Fatalf("trace lines must be single-line events, got %q", line)
+		}
+	}
+
+	// SNAP_DEBUG enables it too; failures are traced with the error
+	errOut.Reset()
+	t.Setenv("SNAP_DEBUG", "1")
+	app.Debug(false)
+	if err := app.RunWithArgs(context.Background(), []string{"serve", "--nope"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(errOut.String(), "parse      arguments failed in") {
+		t.Fatalf("trace missing parse failure:\n%s", errOut.String())
+	}
+	t.Setenv("SNAP_DEBUG", "0")
+	errOut.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"serve"}); err != nil || errOut.Len() != 0 {
+		t.Fatalf("SNAP_DEBUG=0 should disable: %q", errOut.String())
+	}
+}
+
+func TestCommandIOScope(t *testing.T) {
+	var out, errOut, jsonErr strings.Builder
+	app := New("t", "demo")
+	app.IO().WithOut(&out).WithErr(&errOut).ForceColor()
+
+	app.Command("export", "Emit JSON").
+		IO(func(io *snapio.IOManager) { io.NoColor() }).
+		Logger(func(l *snapio.Logger) { l.AllToStderr(true).WithFormat(snapio.LogFormatPlain) }).
+		Action(func(ctx *Context) error {
+			ctx.LogInfo("exporting")
+			fmt.Fprint(ctx.Stdout(), `{"ok":true}`)
+			if ctx.IO().SupportsColor() {
+				t.Error("command IO should have color disabled")
+			}
+			ctx.WithStderr(&jsonErr).LogWarning("scoped")
+			return nil
+		}).
+		Command("all", "Everything").
+		Action(func(ctx *Context) error {
+			ctx.LogInfo("inherited")
+			return nil
+		})
+	app.Command("status", "Show status").
+		Action(func(ctx *Context) error {
+			ctx.LogInfo("status")
+			return nil
+		})
+
+	if err := app.RunWithArgs(context.Background(), []string{"export"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != `{"ok":true}` {
+		t.Fatalf("stdout should hold only JSON, got %q", out.String())
+	}
+	if errOut.String() != "exporting\n" || jsonErr.String() != "scoped\n" {
+		t.Fatalf("stderr %q, scoped %q", errOut.String(), jsonErr.String())
+	}
+
+	// Subcommands inherit the settings
+	errOut.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"export", "all"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != `{"ok":true}` || errOut.String() != "inherited\n" {
+		t.Fatalf("subcommand: stdout %q, stderr %q", out.String(), errOut.String())
+	}
+
+	// Other commands and the app keep the original settings
+	out.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"status"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "status") || !app.IO().SupportsColor() {
+		t.Fatalf("app-wide IO changed: %q", out.String())
+	}
+}
+
+func TestConfig_MergeStrategies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	file := `{
+		"plugins": ["a", "b"],
+		"tags": ["file"],
+		"regions": ["eu"],
+		"hosts": ["h1", "h2"],
+		"servers": {"1": "patched"},
+		"labels": {"team": "core", "tier": "web"},
+		"extra": {"db": {"host": "file-db", "port": 5432}}
+	}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// A second file (e.g. user over system config) deep-merges nested maps
+	override := filepath.Join(t.TempDir(), "user.json")
+	if err := os.WriteFile(override, []byte(`{"extra": {"db": {"port": 6543}, "debug": true}}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	type C struct {
+		Plugins []string          `json:"plugins" env:"T_PLUGINS"`                              // default: replace
+		Tags    []string          `json:"tags" env:"T_TAGS" merge:"append"`                     // file + env
+		Regions []string          `json:"regions" flag:"region" env:"T_REGIONS" merge:"append"` // keyed by flag tag
+		Hosts   []string          `json:"hosts" env:"T_HOSTS" merge:"union"`                    // duplicates dropped
+		Servers []string          `json:"servers"`                                              // element addressing
+		Labels  map[string]string `json:"labels" env:"T_LABELS"`                                // deep-merged
+		Locked  map[string]int    `json:"locked" env:"T_LOCKED" merge:"replace"`                // highest source wins
+		Extra   map[string]any    `json:"extra"`                                                // nested maps
+		Limits  map[string]int    `json:"limits" env:"T_LIMITS" default:"cpu=1"`                // env string → map
+	}
+	t.Setenv("T_PLUGINS", "c")
+	t.Setenv("T_TAGS", "env1,env2")
+	t.Setenv("T_REGIONS", "us")
+	t.Setenv("T_HOSTS", "h2,h3")
+	t.Setenv("T_LABELS", "tier=api,owner=ops")
+	t.Setenv("T_LOCKED", "x=2")
+	t.Setenv("T_LIMITS", "mem=512")
+
+	var cfg C
+	_, err := Config("app", "").
+		FromDefaults(D{
+			"tags":    []string{"default"},
+			"servers": []string{"s0", "s1"},
+			"locked":  map[string]any{"x": 1, "y": 1},
+			"extra":   map[string]any{"db": map[string]any{"user": "root"}}, // replaced: file sets extra
+		}).
+		FromFile(path).
+		FromFile(override).
+		FromEnv().
+		Bind(&cfg).
+		Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	check := func(name string, got, want any) {
+		t.Helper()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %#v, want %#v", name, got, want)
+		}
+	}
+	check("plugins", cfg.Plugins, []string{"c"})
+	check("tags", cfg.Tags, []string{"file", "env1", "env2"}) // defaults are not appended to
+	check("regions", cfg.Regions, []string{"eu", "us"})
+	check("hosts", cfg.Hosts, []string{"h1", "h2", "h3"})
+	check("servers", cfg.Servers, []string{"s0", "patched"})
+	check("labels", cfg.Labels, map[string]string{"team": "core", "tier": "api", "owner": "ops"})
+	check("locked", cfg.Locked, map[string]int{"x": 2})
+	check("extra", cfg.Extra, map[string]any{"db": map[string]any{"host": "file-db", "port": float64(6543)}, "debug": true})
+	check("limits", cfg.Limits, map[string]int{"mem": 512})
+
+	// Out-of-range element addressing is an error
+	var bad struct {
+		Servers []string `json:"servers"`
+	}
+	_, err = Config("app", "").FromDefaults(D{"servers.3": "x"}).Bind(&bad).Build()
+	if err == nil || !strings.Contains(err.Error(), "index 3 out of range") {
+		t.Fatalf("expected index error, got %v", err)
+	}
+
+	// Unknown strategies are rejected rather than ignored
+	var typo struct {
+		Tags []string `json:"tags" merge:"apend"`
+	}
+	_, err = Config("app", "").FromDefaults(D{"tags": []string{"a"}}).Bind(&typo).Build()
+	if err == nil || !strings.Contains(err.Error(), `field 'tags': unknown merge strategy "apend"`) {
+		t.Fatalf("expected merge tag error, got %v", err)
+	}
+}
+
+func TestHelpDefaultFormatting(t *testing.T) {
+	var help strings.Builder
+	app := New("t", "")
+	app.IO().WithOut(&help)
+	app.DurationFlag("timeout", "Timeout").Default(time.Hour).Back()
+	app.DurationFlag("retry", "Retry delay").Default(30 * time.Second).DisplayDefault("30 seconds").Back()
+	app.IntFlag("port", "Port").Default(8080).Back()
+	app.Int64Flag("limit", "Row limit").Default(1000000).Back()
+	app.FloatFlag("ratio", "Ratio").Default(1e6).Back()
+	app.DurationSliceFlag("backoff", "Backoff").Default([]time.Duration{time.Second, 2 * time.Minute}).Back()
+
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	out := help.String()
+	for _, want := range []string{
+		"Timeout (default: 1h)", "Retry delay (default: 30 seconds)", "Port (default: 8080)",
+		"Row limit (default: 1,000,000)", "Ratio (default: 1,000,000)", "Backoff (default: 1s,2m)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in help:\n%s", want, out)
+		}
+	}
+}
+
+func TestEnumCaseInsensitiveAndAliases(t *testing.T) {
+	app := New("t", "")
+	app.EnumFlag("level", "Level", "debug", "info", "warn").CaseInsensitive().
+		WithAliases(map[string]string{"warning": "warn"}).FromEnv("T_LEVEL").Back()
+	app.EnumSliceFlag("only", "Only", "info", "warn").WithAliases(map[string]string{"w": "warn"}).Back()
+	app.EnumFlag("strict", "Strict", "info").Back()
+
+	for arg, want := range map[string]string{"INFO": "info", "warning": "warn", "WARNING": "warn", "debug": "debug"} {
+		res, err := NewParser(app).Parse([]string{"--level", arg})
+		if err != nil {
+			t.Fatalf("%s: %v", arg, err)
+		}
+		if got, _ := res.GetEnum("level"); got != want {
+			t.Fatalf("%s: got %q, want %q", arg, got, want)
+		}
+	}
+
+	res, err := NewParser(app).Parse([]string{"--only", "info,w"})
+	if err != nil {
+		t.Fatalf("slice: %v", err)
+	}
+	if got, _ := res.GetEnumSlice("only"); !reflect.DeepEqual(got, []string{"info", "warn"}) {
+		t.Fatalf("slice: %v", got)
+	}
+	if _, err := NewParser(app).Parse([]string{"--only", "W"}); err == nil {
+		t.Fatalf("aliases of a case-sensitive flag must match exactly")
+	}
+	if _, err := NewParser(app).Parse([]string{"--strict", "INFO"}); err == nil {
+		t.Fatalf("expected case-sensitive enum to reject INFO")
+	}
+
+	t.Setenv("T_LEVEL", "Debug")
+	if res, err = NewParser(app).Parse(nil); err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	if got, _ := res.GetEnum("level"); got != "debug" {
+		t.Fatalf("env: got %q", got)
+	}
+
+	bad := New("t", "")
+	bad.EnumFlag("level", "", "info").WithAliases(map[string]string{"warning": "warn"}).Back()
+	if err := bad.Validate(); err == nil || !strings.Contains(err.Error(), `alias "warning" of --level maps to "warn"`) {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestUsagePlaceholdersAndSynopsis(t *testing.T) {
+	var help strings.Builder
+	app := New("tool", "")
+	app.IO().WithOut(&help)
+	app.Command("copy", "Copy files").
+		StringFlag("output", "Output file").Placeholder("FILE").Back().
+		StringFlag("mode", "Mode").Metavar("MODE").Required().Back().
+		BoolFlag("force", "Overwrite").Back().
+		StringArg("src", "Source").Placeholder("SRC").Required().Back().
+		StringArg("dest", "Destination").Placeholder("DEST").Back()
+	app.Command("many", "Many flags").
+		BoolFlag("a", "").Back().BoolFlag("b", "").Back().BoolFlag("c", "").Back().BoolFlag("d", "").Back().
+		StringArg("name", "").Required().Back()
+
+	if err := app.RunWithArgs(context.Background(), []string{"copy", "--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	out := help.String()
+	for _, want := range []string{
+		"tool copy [--force] [--output FILE] --mode MODE SRC [DEST]\n",
+		"--output FILE", "  SRC     Source", "  [DEST]  Destination",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	help.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"many", "--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	if !strings.Contains(help.String(), "tool many [FLAGS] <name>\n") {
+		t.Errorf("expected collapsed flags:\n%s", help.String())
+	}
+}
+
+func TestFlagGroupDefaultFlag(t *testing.T) {
+	var help strings.Builder
+	app := New("x", "")
+	app.IO().WithOut(&help)
+	app.FlagGroup("output").ExactlyOne().DefaultFlag("table").
+		BoolFlag("json", "").Back().
+		BoolFlag("table", "").Back().
+		EndGroup()
+	app.Command("sync", "").
+		FlagGroup("mode").MutuallyExclusive().DefaultFlag("fast").
+		BoolFlag("fast", "").Back().BoolFlag("safe", "").Back().EndGroup()
+
+	res, err := NewParser(app).Parse(nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !res.MustGetBool("table", false) || res.Source("table") != ValueSourceDefault {
+		t.Fatalf("table=%v source=%v", res.MustGetBool("table", false), res.Source("table"))
+	}
+	if res, err = NewParser(app).Parse([]string{"--json"}); err != nil || res.MustGetBool("table", false) {
+		t.Fatalf("explicit member must win: %v", err)
+	}
+	if _, err = NewParser(app).Parse([]string{"--json", "--table"}); err == nil {
+		t.Fatalf("expected exactly-one violation")
+	}
+	if res, err = NewParser(app).Parse([]string{"sync"}); err != nil || !res.MustGetBool("fast", false) {
+		t.Fatalf("command group default: %v", err)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	if !strings.Contains(help.String(), "Note: --table is used when none of these flags is given") {
+		t.Fatalf("help output:\n%s", help.String())
+	}
+
+	bad := New("x", "")
+	bad.FlagGroup("tls").AllOrNone().DefaultFlag("cert").StringFlag("cert", "").Back().EndGroup()
+	bad.FlagGroup("fmt").ExactlyOne().DefaultFlag("name").StringFlag("name", "").Back().EndGroup()
+	err = bad.Validate()
+	for _, want := range []string{
+		`flag group "tls" needs a MutuallyExclusive, ExactlyOne or AtLeastOne constraint`,
+		`default flag --name of group "fmt" is not a bool flag`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in %v", want, err)
+		}
+	}
+}
+
+func TestCommandFlagGroupsParityAndOrdering(t *testing.T) {
+	var help strings.Builder
+	app := New("x", "")
+	app.IO().WithOut(&help)
+	cmd := app.Command("deploy", "")
+	cmd.FlagGroup("zeta").Order(-1).AtLeastOne().BoolFlag("z", "").Back().EndGroup()
+	cmd.FlagGroup("alpha").AllOrNone().StringFlag("cert", "").Back().StringFlag("key", "").Back().EndGroup()
+	cmd.FlagGroup("beta").RequiredGroup().BoolFlag("b", "").Back().EndGroup()
+	cmd.FlagGroup("internal").Hidden().MutuallyExclusive().
+		BoolFlag("trace-a", "").Back().BoolFlag("trace-b", "").Back().EndGroup()
+	cmd.FlagGroup("mode").ExactlyOne().BoolFlag("m", "").Back().EndGroup()
+
+	// Constraints behave as at app level, including for hidden groups
+	if _, err := NewParser(app).Parse([]string{"deploy", "--z", "--b", "--m", "--cert", "c"}); err == nil {
+		t.Fatalf("expected all-or-none violation")
+	}
+	if _, err := NewParser(app).Parse([]string{"deploy", "--z", "--b", "--m", "--trace-a", "--trace-b"}); err == nil {
+		t.Fatalf("expected hidden group constraint to apply")
+	}
+	if _, err := NewParser(app).Parse([]string{"deploy", "--z", "--b", "--m", "--trace-a"}); err != nil {
+		t.Fatalf("hidden group flags must parse: %v", err)
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"deploy", "--help"}); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	out := help.String()
+	if strings.Contains(out, "internal:") || strings.Contains(out, "trace-a") {
+		t.Fatalf("hidden group rendered:\n%s", out)
+	}
+	last := -1
+	for _, heading := range []string{"zeta:", "alpha:", "beta:", "mode:"} {
+		i := strings.Index(out, heading)
+		if i < last {
+			t.Fatalf("group %q out of order:\n%s", heading, out)
+		}
+		last = i
+	}
+}
+
+func TestRestArgsFlagModes(t *testing.T) {
+	app := New("d", "")
+	app.Command("run", "").
+		BoolFlag("rm", "").Back().
+		StringFlag("name", "").Back().
+		StringArg("image", "").Required().Back().
+		StopAtFirstPositional()
+	app.Command("exec", "").
+		BoolFlag("tty", "").Short('t').Back().
+		StringArg("host", "").Required().Back().
+		FlagsFirst()
+	app.Command("raw", "").BoolFlag("rm", "").Back().RestArgs()
+
+	res, err := NewParser(app).Parse([]string{"run", "--rm", "--name", "web", "nginx", "--port", "80", "--rm"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !res.MustGetBool("rm", false) || res.MustGetString("name", "") != "web" || res.MustGetArgString("image", "") != "nginx" {
+		t.Fatalf("run flags: %+v", res)
+	}
+	if !reflect.DeepEqual(res.RestArgs, []string{"--port", "80", "--rm"}) {
+		t.Fatalf("run rest: %q", res.RestArgs)
+	}
+
+	res, err = NewParser(app).Parse([]string{"exec", "host1", "-t", "uptime", "-t"})
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if !res.MustGetBool("tty", false) || !reflect.DeepEqual(res.RestArgs, []string{"uptime", "-t"}) {
+		t.Fatalf("exec: tty=%v rest=%q", res.MustGetBool("tty", false), res.RestArgs)
+	}
+	if _, err = NewParser(app).Parse([]string{"run", "--bogus", "img"}); err == nil {
+		t.Fatalf("unknown flags before the first positional must still fail")
+	}
+	if res, err = NewParser(app).Parse([]string{"run", "--", "--rm"}); err != nil || res.MustGetArgString("image", "") != "--rm" {
+		t.Fatalf("terminator: %v", err)
+	}
+
+	// Plain RestArgs keeps treating every token as positional
+	if res, err = NewParser(app).Parse([]string{"raw", "--rm", "x"}); err != nil || res.MustGetBool("rm", false) {
+		t.Fatalf("raw: %v", err)
+	}
+	if !reflect.DeepEqual(res.RestArgs, []string{"--rm", "x"}) {
+		t.Fatalf("raw rest: %q", res.RestArgs)
+	}
+}
+
+func TestPreParseThenRegister(t *testing.T) {
+	app := New("t", "")
+	app.StringFlag("plugin-dir", "").Global().Back()
+	app.BoolFlag("verbose", "").Short('v').Global().Back()
+	db := app.Command("db", "")
+
+	args := []string{"db", "--plugin-dir=/p", "migrate", "--steps=3", "-v", "--dry-run", "up"}
+	pre, err := app.PreParse(args)
+	if err != nil {
+		t.Fatalf("preparse: %v", err)
+	}
+	if pre.Command == nil || pre.Command.Name() != "db" {
+		t.Fatalf("command path: %v", pre.Command)
+	}
+	if dir, _ := pre.GetGlobalString("plugin-dir"); dir != "/p" || !pre.MustGetGlobalBool("verbose", false) {
+		t.Fatalf("globals: dir=%q", dir)
+	}
+	if !reflect.DeepEqual(pre.Args, []string{"migrate", "up"}) {
+		t.Fatalf("args: %q", pre.Args)
+	}
+
+	// The plugin found in --plugin-dir adds its subcommand, then the full parse runs
+	if _, err := NewParser(app).Parse(args); err == nil {
+		t.Fatalf("expected unknown command before registration")
+	}
+	db.Command("migrate", "").IntFlag("steps", "").Back().BoolFlag("dry-run", "").Back().StringArg("dir", "").Required().Back()
+	res, err := app.Parse(args)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if res.Command.Name() != "migrate" || res.MustGetInt("steps", 0) != 3 || !res.MustGetBool("dry-run", false) {
+		t.Fatalf("full parse: %+v", res)
+	}
+}
+
+func TestDoctorReportAndExitCode(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	t.Setenv("SNAP_DOCTOR_SET", "1")
+
+	app := New("t", "")
+	app.Wrap("snap-doctor-missing-binary").Back()
+	app.Doctor().
+		Env("SNAP_DOCTOR_SET").
+		TCP("api", ln.Addr().String(), time.Second).
+		BinaryVersion("go", []string{"version"}, ">=1.18", "<100").
+		Check("cache", func(*Context) CheckResult { return Warn("cold") }).
+		WrapperBinaries()
+
+	var buf strings.Builder
+	app.IO().WithOut(&buf)
+	err = app.RunWithArgs(context.Background(), []string{"doctor"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1 for the missing wrapper binary, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"env SNAP_DOCTOR_SET", "api", "go 1.", "cache", "snap-doctor-missing-binary not found", "3 passed, 1 warnings, 1 failed"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	_ = app.RunWithArgs(context.Background(), []string{"doctor", "--json"})
+	var reports []struct{ Name, Status, Message string }
+	if err := json.Unmarshal([]byte(buf.String()), &reports); err != nil {
+		t.Fatalf("json: %v\n%s", err, buf.String())
+	}
+	if len(reports) != 5 || reports[3].Status != "warn" || reports[4].Status != "fail" {
+		t.Fatalf("json reports: %+v", reports)
+	}
+
+	// Warnings alone exit successfully
+	ok := New("t", "")
+	ok.Doctor().Check("cache", func(*Context) CheckResult { return Warn("cold") })
+	ok.IO().WithOut(&strings.Builder{})
+	if err := ok.RunWithArgs(context.Background(), []string{"doctor"}); err != nil {
+		t.Fatalf("warnings only: %v", err)
+	}
+
+	for constraint, want := range map[string]bool{">=1.21": true, "<1.22": false, "1.22": false, "=1.22.5": true, ">v1": true, "<=1.22.5.0": true} {
+		if got, err := versionSatisfies("1.22.5", constraint); err != nil || got != want {
+			t.Fatalf("versionSatisfies(%q) = %v, %v", constraint, got, err)
+		}
+	}
+	if _, err := versionSatisfies("1.2", ">=x"); err == nil {
+		t.Fatalf("expected invalid constraint error")
+	}
+}
+
+func TestSearchCommand(t *testing.T) {
+	app := New("myapp", "").SearchCommand("find")
+	app.BoolFlag("verbose", "Verbose output").Back()
+	deploy := app.Command("deploy", "Deploy the application").Alias("ship")
+	deploy.BoolFlag("dry-run", "Print the plan only").Back()
+	deploy.Command("rollback", "Undo the last deployment")
+	app.Command("status", "Show deployment status")
+	app.Command("secret", "").Hidden()
+
+	var buf strings.Builder
+	app.IO().WithOut(&buf)
+	if err := app.RunWithArgs(context.Background(), []string{"find", "depl"}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "myapp deploy ") {
+		t.Fatalf("unexpected results:\n%s", buf.String())
+	}
+	// Name matches rank before description matches
+	if !strings.Contains(lines[len(lines)-1], "Show deployment status") {
+		t.Fatalf("description match should come last:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"find", "rollbak"}); err != nil {
+		t.Fatalf("typo: %v", err)
+	}
+	if !strings.Contains(buf.String(), "myapp deploy rollback") || strings.Contains(buf.String(), "status") {
+		t.Fatalf("typo results:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"find", "--", "--dry"}); err != nil || !strings.Contains(buf.String(), "myapp deploy --dry-run") {
+		t.Fatalf("flag results: %v\n%s", err, buf.String())
+	}
+	if err := app.RunWithArgs(context.Background(), []string{"find", "secret"}); err == nil {
+		t.Fatalf("hidden commands must not match")
+	}
+}
+
+func TestUsageFuncOverrides(t *testing.T) {
+	app := New("brand", "Branded tool").
+		UsageFunc(func(a *App, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "== brand ==\n%s", a.DefaultUsage(nil))
+			return err
+		})
+	app.ErrorHandler().ShowHelpOnError(true)
+	deploy := app.Command("deploy", "Deploy").
+		UsageFunc(func(cmd *Command, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "custom usage for %s\n", cmd.Name())
+			return err
+		})
+	deploy.Command("rollback", "Undo")
+	app.Command("status", "Status")
+
+	run := func(args ...string) string {
+		var buf strings.Builder
+		app.IO().WithOut(&buf)
+		_ = app.RunWithArgs(context.Background(), args)
+		return buf.String()
+	}
+	if out := run("--help"); !strings.HasPrefix(out, "== brand ==\n") || !strings.Contains(out, "deploy") {
+		t.Fatalf("app usage:\n%s", out)
+	}
+	for _, args := range [][]string{{"deploy", "-h"}, {"help", "deploy"}, {"deploy", "--bogus"}} {
+		if out := run(args...); !strings.Contains(out, "custom usage for deploy") {
+			t.Fatalf("%v:\n%s", args, out)
+		}
+	}
+	if out := run("deploy", "rollback", "--help"); !strings.Contains(out, "custom usage for rollback") {
+		t.Fatalf("subcommands inherit the usage func:\n%s", out)
+	}
+	if out := run("status", "--help"); strings.Contains(out, "custom") || strings.Contains(out, "==") {
+		t.Fatalf("other commands keep the built-in help:\n%s", out)
+	}
+
+	failing := New("t", "").UsageFunc(func(*App, io.Writer) error { return errors.New("boom") })
+	failing.IO().WithOut(&strings.Builder{})
+	if err := failing.RunWithArgs(context.Background(), []string{"--help"}); err == nil || err.Error() != "boom" {
+		t.Fatalf("usage errors are returned: %v", err)
+	}
+}
+
+func TestAutoCorrect(t *testing.T) {
+	ran := ""
+	app := New("t", "").AutoCorrect(AutoCorrectAlways)
+	app.Command("status", "").Action(func(*Context) error { ran = "status"; return nil })
+	app.Command("stats", "").Action(func(*Context) error { ran = "stats"; return nil })
+	server := app.Command("server", "")
+	server.Command("restart", "").Action(func(*Context) error { ran = "server restart"; return nil })
+
+	var stderr strings.Builder
+	app.IO().WithOut(&strings.Builder{}).WithErr(&stderr)
+	if err := app.RunWithArgs(context.Background(), []string{"servr", "restrat"}); err != nil {
+		t.Fatalf("always: %v", err)
+	}
+	if ran != "server restart" || !strings.Contains(stderr.String(), "Unknown command 'restrat', running 'restart' instead") {
+		t.Fatalf("ran %q, stderr:\n%s", ran, stderr.String())
+	}
+
+	// "stat" is close to both status and stats: never guess
+	ran = ""
+	if err := app.RunWithArgs(context.Background(), []string{"stat"}); err == nil || ran != "" {
+		t.Fatalf("ambiguous typo must error: %v, ran %q", err, ran)
+	}
+
+	// Prompt mode keeps the error when stdin is not a terminal
+	app.AutoCorrect(AutoCorrectPrompt)
+	if err := app.RunWithArgs(context.Background(), []string{"sttus"}); err == nil || ran != "" {
+		t.Fatalf("non-interactive prompt must error: %v, ran %q", err, ran)
+	}
+	stderr.Reset()
+	if !app.confirmCorrection(strings.NewReader("Y\n"), "status") || app.confirmCorrection(strings.NewReader("\n"), "status") {
+		t.Fatalf("confirmCorrection answers")
+	}
+	if !strings.Contains(stderr.String(), "Did you mean 'status'? [y/N] ") {
+		t.Fatalf("prompt: %q", stderr.String())
+	}
+}
+
+func TestIntrospection(t *testing.T) {
+	app := New("intro", "")
+	app.BoolFlag("verbose", "").Global().Back()
+	deployCmd := app.Command("deploy", "Deploy").
+		EnumFlag("env", "Target", "dev", "prod").Default("dev").FromEnv("DEPLOY_ENV").Back().
+		DurationFlag("timeout", "").Default(time.Minute).Back().
+		StringFlag("token", "").Hidden().Back().
+		StringArg("service", "").Required().Back()
+	deployCmd.Command("status", "Show status")
+	deployCmd.Command("rollback", "Roll back").Hidden()
+	app.Command("build", "Build")
+
+	var names []string
+	for _, cmd := range app.Commands() {
+		names = append(names, cmd.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"build", "deploy"}) {
+		t.Fatalf("commands = %v", names)
+	}
+	if flags := app.Flags(); !slices.Contains(flagNames(flags), "verbose") {
+		t.Fatalf("app flags = %v", flagNames(flags))
+	}
+
+	deploy := app.Commands()[1]
+	flags := deploy.Flags()
+	if !reflect.DeepEqual(flagNames(flags), []string{"env", "help", "timeout", "token"}) {
+		t.Fatalf("deploy flags = %v", flagNames(flags))
+	}
+	env, timeout, token := flags[0], flags[2], flags[3]
+	if env.Type != FlagTypeEnum || env.Default() != "dev" ||
+		!reflect.DeepEqual(env.EnvVars, []string{"DEPLOY_ENV"}) || !reflect.DeepEqual(env.EnumValues, []string{"dev", "prod"}) {
+		t.Fatalf("env flag = %+v, default %v", env, env.Default())
+	}
+	if timeout.Default() != time.Minute || timeout.IsHidden() || !token.IsHidden() {
+		t.Fatalf("timeout default %v, hidden %v/%v", timeout.Default(), timeout.IsHidden(), token.IsHidden())
+	}
+	if args := deploy.Args(); len(args) != 1 || args[0].Name != "service" || !args[0].IsRequired() {
+		t.Fatalf("deploy args = %v", args)
+	}
+
+	subs := deploy.Subcommands()
+	if len(subs) != 2 || subs[0].Name() != "rollback" || !subs[0].IsHidden() || subs[1].Name() != "status" || subs[1].IsHidden() {
+		t.Fatalf("subcommands = %v", subs)
+	}
+	if subs[1].Parent() != deploy || deploy.Parent() != nil {
+		t.Fatal("unexpected parent links")
+	}
+}
+
+func flagNames(flags []*Flag) []string {
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func TestParserFeed(t *testing.T) {
+	app := New("feed", "")
+	app.BoolFlag("verbose", "").Short('v').Global().Back()
+	app.Command("deploy", "").
+		IntFlag("port", "").Short('p').Default(80).Back().
+		StringSliceFlag("tag", "").Back().
+		StringArg("service", "").Required().Back()
+
+	p := NewParser(app)
+	feed := func(tokens ...string) (*ParseResult, error) {
+		for _, token := range tokens {
+			if err := p.Feed(token); err != nil {
+				return nil, err
+			}
+		}
+		return p.End()
+	}
+
+	res, err := feed("-v", "deploy", "--port", "8080", "--tag=a,b", "-p", "9090", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := res.GetInt("port")
+	tags, _ := res.GetStringSlice("tag")
+	service, _ := res.GetArgString("service")
+	verbose, _ := res.GetGlobalBool("verbose")
+	if res.Command.Name() != "deploy" || port != 9090 || !reflect.DeepEqual(tags, []string{"a", "b"}) ||
+		service != "api" || !verbose {
+		t.Fatalf("feed result: cmd=%s port=%d tags=%v service=%q verbose=%v", res.Command.Name(), port, tags, service, verbose)
+	}
+
+	// Same result as Parse on the whole command line
+	want, err := NewParser(app).Parse([]string{"deploy", "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPort, _ := want.GetInt("port")
+	res, err = feed("deploy", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port, _ := res.GetInt("port"); port != wantPort {
+		t.Fatalf("default port = %d, want %d", port, wantPort)
+	}
+
+	// Errors surface at the offending token and abandon the parse
+	_, err = feed("deploy", "--nope")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Type != ErrorTypeUnknownFlag || parseErr.Index != 1 || parseErr.Token != "--nope" {
+		t.Fatalf("unknown flag error = %#v", err)
+	}
+	if _, err := feed("deploy", "db"); err != nil {
+		t.Fatalf("parse after error: %v", err)
+	}
+
+	// A value flag at the end is reported by End
+	_, err = feed("deploy", "api", "--port")
+	if !errors.As(err, &parseErr) || parseErr.Type != ErrorTypeInvalidValue || parseErr.Index != 2 || parseErr.Token != "--port" {
+		t.Fatalf("missing value error = %#v", err)
+	}
+	// Required arguments are checked by End
+	if _, err := feed("deploy"); !errors.As(err, &parseErr) || parseErr.Type != ErrorTypeInvalidArgument {
+		t.Fatalf("missing argument error = %v", err)
+	}
+}
+
+func TestFlagInterpolation(t *testing.T) {
+	newApp := func() *App {
+		app := New("interp", "")
+		app.StringFlag("name", "").Default("app").Global().Back()
+		app.Command("serve", "").
+			StringFlag("host", "").Default("localhost").Back().
+			IntFlag("port", "").Default(8080).Back().
+			StringFlag("addr", "").Default("${flag:host}:${flag:port}").Interpolate().Back().
+			StringFlag("log-file", "").Default("{{ .name }}-{{.addr}}.log").Interpolate().Back().
+			StringSliceFlag("label", "").Default([]string{"app={{.name}}"}).Interpolate().Back().
+			StringFlag("raw", "").Default("{{.name}}").Back()
+		return app
+	}
+
+	res, err := NewParser(newApp()).Parse([]string{"--name", "api", "serve", "--port", "9090"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := res.GetString("addr")
+	logFile, _ := res.GetString("log-file")
+	labels, _ := res.GetStringSlice("label")
+	raw, _ := res.GetString("raw")
+	if addr != "localhost:9090" || logFile != "api-localhost:9090.log" || !reflect.DeepEqual(labels, []string{"app=api"}) || raw != "{{.name}}" {
+		t.Fatalf("addr=%q log-file=%q labels=%v raw=%q", addr, logFile, labels, raw)
+	}
+
+	// Provided values are interpolated too
+	res, err = NewParser(newApp()).Parse([]string{"serve", "--log-file=/var/log/{{.name}}.log", "--label", "host=${flag:host}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logFile, _ = res.GetString("log-file")
+	labels, _ = res.GetStringSlice("label")
+	if logFile != "/var/log/app.log" || !reflect.DeepEqual(labels, []string{"host=localhost"}) {
+		t.Fatalf("log-file=%q labels=%v", logFile, labels)
+	}
+
+	var parseErr *ParseError
+	_, err = NewParser(newApp()).Parse([]string{"serve", "--addr={{.log-file}}"})
+	if !errors.As(err, &parseErr) || parseErr.Message != "flag interpolation cycle: --addr -> --log-file -> --addr" {
+		t.Fatalf("cycle error = %v", err)
+	}
+	_, err = NewParser(newApp()).Parse([]string{"serve", "--addr=${flag:nope}"})
+	if !errors.As(err, &parseErr) || parseErr.Message != "--addr references unknown flag --nope" {
+		t.Fatalf("unknown flag error = %v", err)
+	}
+
+	bad := New("bad", "")
+	bad.IntFlag("n", "").Interpolate().Back()
+	if err := bad.Validate(); err == nil || !strings.Contains(err.Error(), "--n uses Interpolate") {
+		t.Fatalf("Validate = %v", err)
+	}
+}
+
+func TestConfig_ValidateTags(t *testing.T) {
+	type Redis struct {
+		Host string `json:"host" validate:"required"`
+		Port int    `json:"port" validate:"gte=1,lte=65535"`
+	}
+	type Cache struct {
+		Redis Redis         `json:"redis"`
+		TTL   time.Duration `json:"ttl" validate:"gt=0s"`
+	}
+	type TLS struct {
+		Cert string `json:"cert" validate:"required_with=Key"`
+		Key  string `json:"key" validate:"required_with=Cert"`
+	}
+	type Cfg struct {
+		Name  string   `json:"name" validate:"lte=8"`
+		Token string   `json:"token"`
+		Tags  []string `json:"tags" validate:"gte=1"`
+		Cache Cache    `json:"cache"`
+		TLS   TLS      `json:"tls"`
+	}
+
+	build := func(defaults D, hooks ...func(*ConfigBuilder)) error {
+		var cfg Cfg
+		cb := Config("app", "").FromDefaults(defaults).Bind(&cfg).RequireOneOf("token", "tls.cert")
+		for _, hook := range hooks {
+			hook(cb)
+		}
+		_, err := cb.Build()
+		return err
+	}
+
+	valid := D{"name": "svc", "tags": []string{"a"}, "cache.redis.host": "r", "cache.redis.port": 6379, "cache.ttl": time.Minute, "token": "t"}
+	if err := build(valid); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	err := build(D{"name": "much-too-long", "cache.redis.port": 70000, "tls.key": "k.pem"})
+	want := []string{
+		"name: length must be at most 8",
+		"tags: length must be at least 1",
+		"cache.redis.host: is required",
+		"cache.redis.port: must be between 1 and 65535",
+		"cache.ttl: must be greater than 0s",
+		"tls.cert: is required when key is set",
+		"one of token, tls.cert is required",
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Fatalf("errors =\n%v\nwant\n%s", err, strings.Join(want, "\n"))
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "name" {
+		t.Fatalf("first error = %#v", fieldErr)
+	}
+
+	// Struct validators: on the root, and on nested structs with their path
+	err = build(valid, func(cb *ConfigBuilder) {
+		cb.RegisterStructValidator(func(s any) error {
+			if s.(*Cfg).Name == "svc" {
+				return errors.New("name svc is reserved")
+			}
+			return nil
+		})
+		cb.RegisterStructValidator(func(s any) error {
+			r := s.(*Redis)
+			if r.Port == 6379 {
+				return errors.Join(&FieldError{Path: "port", Message: "use a non-default port"}, errors.New("redis is deprecated"))
+			}
+			return nil
+		}, Redis{})
+	})
+	want = []string{
+		"cache.redis.port: use a non-default port",
+		"cache.redis: redis is deprecated",
+		"name svc is reserved",
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Fatalf("struct validator errors =\n%v", err)
+	}
+}
+
+func TestConfig_ValidateTagsFromFlags(t *testing.T) {
+	var cfg struct {
+		Port int `flag:"port" default:"8080" validate:"gte=1,lte=65535"`
+	}
+	app, err := Config("app", "").FromFlags().Bind(&cfg).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Parse([]string{"--port", "8081"}); err != nil || cfg.Port != 8081 {
+		t.Fatalf("Parse = %v, port %d", err, cfg.Port)
+	}
+	_, err = app.Parse([]string{"--port", "0"})
+	if err == nil || !strings.Contains(err.Error(), "port: must be between 1 and 65535") {
+		t.Fatalf("Parse error = %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package snap
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// InvocationInfo describes a single run for usage reporting. It carries flag
+// names only, never flag values or positional arguments.
+type InvocationInfo struct {
+	App           string
+	Version       string
+	Command       string   // Space-separated command path ("" when no command ran)
+	Flags         []string // Sorted names of flags given on the command line
+	Duration      time.Duration
+	ExitCode      int
+	ErrorCategory string // "" on success, the CLI error type (e.g. "unknown_flag"), "exit" or "error"
+}
+
+// InvocationHook receives usage data after each run
+type InvocationHook func(InvocationInfo)
+
+// OnInvocation registers a hook fired after every run with the command path,
+// duration, exit code, error category and flag names used. Hooks are skipped
+// when the user opted out (see TelemetryEnabled).
+func (a *App) OnInvocation(hook InvocationHook) *App {
+	a.invocationHooks = append(a.invocationHooks, hook)
+	return a
+}
+
+// TelemetryOptOutEnv adds environment variables that disable invocation hooks
+// when set to a truthy value, in addition to DO_NOT_TRACK and <APP>_NO_TELEMETRY
+func (a *App) TelemetryOptOutEnv(names ...string) *App {
+	a.telemetryOptOut = append(a.telemetryOptOut, names...)
+	return a
+}
+
+// TelemetryEnabled reports whether invocation hooks will fire. Setting
+// DO_NOT_TRACK, <APP>_NO_TELEMETRY (app name upper-cased, dashes as
+// underscores) or any TelemetryOptOutEnv variable to anything but "", "0"
+// or "false" opts out.
+func (a *App) TelemetryEnabled() bool {
+	if len(a.invocationHooks) == 0 {
+		return false
+	}
+	appVar := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(a.name)) + "_NO_TELEMETRY"
+	for _, name := range append([]string{"DO_NOT_TRACK", appVar}, a.telemetryOptOut...) {
+		switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+		case "", "0", "false":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// reportInvocation fires the invocation hooks for a finished run
+func (a *App) reportInvocation(start time.Time, err error) {
+	if !a.TelemetryEnabled() {
+		return
+	}
+
+	info := InvocationInfo{
+		App:           a.name,
+		Version:       a.version,
+		Duration:      time.Since(start),
+		ExitCode:      a.exitCode(err),
+		ErrorCategory: errorCategory(err),
+	}
+	if result := a.currentResult; result != nil {
+		if result.Command != nil {
+			info.Command = a.commandPath(result.Command)
+		}
+		names, sources := result.flagSources()
+		for i, name := range names {
+			if sources[i] == ValueSourceFlag {
+				info.Flags = append(info.Flags, name)
+			}
+		}
+	}
+
+	for _, hook := range a.invocationHooks {
+		hook(info)
+	}
+}
+
+// errorCategory classifies err without exposing its message
+func errorCategory(err error) string {
+	if err == nil {
+		return ""
+	}
+	var cliErr *CLIError
+	if errors.As(err, &cliErr) {
+		return string(cliErr.Type)
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return "exit"
+	}
+	return "error"
+}
@@ -0,0 +1,38 @@
+package snap
+
+import (
+	snapio "github.com/dzonerzy/go-snap/io"
+)
+
+// SetTheme styles help headings, flag names, error messages and log output
+// with theme (see snapio.DefaultTheme, MonochromeTheme, HighContrastTheme).
+// Styles are only applied when the IOManager supports color, so NO_COLOR,
+// CLICOLOR=0 and redirected output stay plain.
+func (a *App) SetTheme(theme snapio.Theme) *App {
+	a.theme = &theme
+	a.Logger().WithTheme(theme)
+	a.invalidateHelp()
+	return a
+}
+
+// Theme returns the theme set with SetTheme, or the default theme for the
+// terminal's color level
+func (a *App) Theme() snapio.Theme {
+	if a.theme != nil {
+		return *a.theme
+	}
+	return snapio.DefaultTheme(a.IO())
+}
+
+// heading renders a help section header from the message catalog
+func (a *App) heading(id MessageID) string {
+	return a.styled(a.Theme().HeadingStyle, a.Message(id))
+}
+
+// styled applies style to text when the output supports color
+func (a *App) styled(style *snapio.Style, text string) string {
+	if a.porcelain {
+		return text
+	}
+	return style.Sprint(a.IO(), text)
+}
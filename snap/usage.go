@@ -0,0 +1,111 @@
+package snap
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxUsageFlags is how many optional flags the usage synopsis lists before
+// collapsing them into a single "[FLAGS]"
+const maxUsageFlags = 3
+
+// defaultPlaceholder names the value of a flag without a Placeholder
+const defaultPlaceholder = "value"
+
+// placeholder returns the name shown for the flag's value ("FILE", "value")
+func (f *Flag) placeholder() string {
+	if f.Placeholder != "" {
+		return f.Placeholder
+	}
+	return defaultPlaceholder
+}
+
+// valueUsage renders the value after the flag name: " FILE", "[=WHEN]" for
+// flags with an optional value, or nothing for switches
+func (f *Flag) valueUsage() string {
+	switch {
+	case f.optionalValue:
+		return "[=" + f.placeholder() + "]"
+	case f.RequiresValue():
+		return " " + f.placeholder()
+	}
+	return ""
+}
+
+// usage renders the flag for a synopsis: "--force", "--output FILE" or "--color[=WHEN]"
+func (f *Flag) usage() string {
+	return "--" + f.Name + f.valueUsage()
+}
+
+// usage renders the argument for usage lines and the Arguments section:
+// "<src>", "[dest]" and "<files>..." by name, or "SRC", "[DEST]" and
+// "FILES..." when a placeholder is set
+func (a *Arg) usage() string {
+	s := a.Placeholder
+	switch {
+	case s == "" && a.Required:
+		s = "<" + a.Name + ">"
+	case s == "":
+		s = "[" + a.Name + "]"
+	case !a.Required:
+		s = "[" + s + "]"
+	}
+	if a.Variadic {
+		s += "..."
+	}
+	return s
+}
+
+// usageFlags builds the flag part of a usage synopsis: required flags are
+// always listed, optional ones while there are at most maxUsageFlags of them
+// and as label (e.g. "[FLAGS]") beyond that. Hidden and built-in flags are left out.
+func usageFlags(flags map[string]*Flag, label string) string {
+	var required, optional []*Flag
+	for _, flag := range flags {
+		if flag.isHidden() || flag.descriptionID != "" {
+			continue
+		}
+		if flag.Required {
+			required = append(required, flag)
+		} else {
+			optional = append(optional, flag)
+		}
+	}
+	byName := func(list []*Flag) {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+	byName(required)
+	byName(optional)
+
+	parts := make([]string, 0, len(required)+min(len(optional), maxUsageFlags)+1)
+	if len(optional) > maxUsageFlags {
+		parts = append(parts, label)
+	} else {
+		for _, flag := range optional {
+			parts = append(parts, "["+flag.usage()+"]")
+		}
+	}
+	for _, flag := range required {
+		parts = append(parts, flag.usage())
+	}
+	return strings.Join(parts, " ")
+}
+
+// printUsageLine prints the synopsis after the "Usage:" heading
+func (a *App) printUsageLine(path string, flags map[string]*Flag, label string, args []*Arg, hasRestArgs bool, tail string) {
+	a.print("  ", path)
+	if synopsis := usageFlags(flags, label); synopsis != "" {
+		a.print(" ", synopsis)
+	}
+	if len(args) > 0 {
+		for _, arg := range args {
+			a.print(" ", arg.usage())
+		}
+	} else if hasRestArgs {
+		a.print(" [args...]")
+	}
+	if tail != "" {
+		a.print(" ", tail)
+	}
+	a.println()
+}
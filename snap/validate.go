@@ -0,0 +1,257 @@
+package snap
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Validate checks the CLI definition for configuration mistakes that would
+// otherwise surface only at run time (or never): duplicate flag names and
+// short forms between global and command scope, enum defaults outside their
+// allowed values, misplaced variadic or required arguments, flag groups
+// and argument constraints referencing hidden or unregistered flags, alias
+// collisions, and help topics hidden behind a command of the same name.
+// All problems are reported together as a joined error; nil means the definition is sound.
+func (a *App) Validate() error {
+	v := &definitionValidator{globals: make(map[string]*Flag)}
+	for name, flag := range a.flags {
+		if flag.Global {
+			v.globals[name] = flag
+		}
+	}
+
+	v.checkFlags("app", a.flags, a.shortFlags, nil)
+	v.checkArgs("app", a.args)
+	v.checkRestCount("app", a.restMin, a.restMax)
+	v.checkArgConstraints("app", a.args, a.argsOrFlag, a.flags)
+	v.checkGroups("app", a.flagGroups, a.flags)
+	v.checkCommands("", a.commands)
+
+	for _, topic := range sortedKeys(a.helpTopics) {
+		if a.commands[topic] != nil || findCommandAlias(a.commands, topic) != nil {
+			v.addf("app: help topic %q is shadowed by a command of the same name", topic)
+		}
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// MustValidate panics if Validate reports any problem; intended for tests and init code.
+func (a *App) MustValidate() *App {
+	if err := a.Validate(); err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// definitionValidator accumulates problems found while walking an app definition
+type definitionValidator struct {
+	globals map[string]*Flag // App flags declared with Global(), visible in every command
+	errs    []error
+}
+
+func (v *definitionValidator) addf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+// checkFlags validates one flag scope; globals are the app's Global() flags
+// visible from that scope (nil for the app scope itself)
+func (v *definitionValidator) checkFlags(scope string, flags map[string]*Flag, shorts map[rune]*Flag, globals map[string]*Flag) {
+	for _, name := range sortedKeys(flags) {
+		flag := flags[name]
+
+		if global, ok := globals[name]; ok && global != flag && name != "help" {
+			v.addf("%s: flag --%s shadows global flag --%s", scope, name, name)
+		}
+
+		//nolint:nestif // explicit nesting keeps the short-flag rules readable
+		if flag.Short != 0 {
+			if owner := shorts[flag.Short]; owner != nil && owner != flag && !isHelpFlag(owner, flag) {
+				v.addf("%s: short flag -%c is used by both --%s and --%s", scope, flag.Short, owner.Name, name)
+			}
+			for _, globalName := range sortedKeys(globals) {
+				global := globals[globalName]
+				if global.Short == flag.Short && global != flag && !isHelpFlag(global, flag) {
+					v.addf("%s: short flag -%c of --%s collides with global flag --%s", scope, flag.Short, name, global.Name)
+				}
+			}
+		}
+
+		for _, alias := range sortedKeys(flag.EnumAliases) {
+			if target := flag.EnumAliases[alias]; !slices.Contains(flag.EnumValues, target) {
+				v.addf("%s: alias %q of --%s maps to %q, which is not one of %v", scope, alias, name, target, flag.EnumValues)
+			}
+		}
+
+		if flag.interpolate && flag.Type != FlagTypeString && flag.Type != FlagTypeStringSlice {
+			v.addf("%s: --%s uses Interpolate, which needs a string or string slice flag", scope, name)
+		}
+
+		switch flag.Type { //nolint:exhaustive // only enum flags carry constrained defaults
+		case FlagTypeEnum:
+			if flag.DefaultEnum != "" && !slices.Contains(flag.EnumValues, flag.DefaultEnum) {
+				v.addf("%s: default %q of --%s is not one of %v", scope, flag.DefaultEnum, name, flag.EnumValues)
+			}
+		case FlagTypeEnumSlice:
+			for _, value := range flag.DefaultEnumSlice {
+				if !slices.Contains(flag.EnumValues, value) {
+					v.addf("%s: default %q of --%s is not one of %v", scope, value, name, flag.EnumValues)
+				}
+			}
+		}
+	}
+}
+
+// checkArgs validates positional argument ordering
+func (v *definitionValidator) checkArgs(scope string, args []*Arg) {
+	seenOptional := ""
+	for i, arg := range args {
+		if arg.Variadic && i != len(args)-1 {
+			v.addf("%s: variadic argument <%s> must be the last argument", scope, arg.Name)
+		}
+		if arg.Required && seenOptional != "" {
+			v.addf("%s: required argument <%s> follows optional argument [%s]", scope, arg.Name, seenOptional)
+		}
+		if !arg.Required && seenOptional == "" {
+			seenOptional = arg.Name
+		}
+		if (arg.minCount > 0 || arg.maxCount > 0) && !arg.Variadic {
+			v.addf("%s: argument <%s> sets MinCount or MaxCount but is not variadic", scope, arg.Name)
+		}
+		if arg.maxCount > 0 && arg.minCount > arg.maxCount {
+			v.addf("%s: argument <%s> has MinCount %d above MaxCount %d", scope, arg.Name, arg.minCount, arg.maxCount)
+		}
+		v.checkArgValues(scope, arg)
+	}
+}
+
+// checkArgValues validates EnumArg choices and MustExist
+func (v *definitionValidator) checkArgValues(scope string, arg *Arg) {
+	if arg.Completion == CompleteChoices && len(arg.EnumValues) == 0 {
+		v.addf("%s: enum argument <%s> has no values", scope, arg.Name)
+	}
+	if len(arg.EnumValues) > 0 && arg.DefaultString != "" && !slices.Contains(arg.EnumValues, arg.DefaultString) {
+		v.addf("%s: default %q of argument <%s> is not one of %v", scope, arg.DefaultString, arg.Name, arg.EnumValues)
+	}
+	if arg.mustExist && arg.Completion != CompleteFile && arg.Completion != CompleteDir {
+		v.addf("%s: argument <%s> uses MustExist but is not a FileArg or DirArg", scope, arg.Name)
+	}
+}
+
+// checkRestCount validates the MinRestArgs / MaxRestArgs bounds of a scope
+func (v *definitionValidator) checkRestCount(scope string, minCount, maxCount int) {
+	if maxCount > 0 && minCount > maxCount {
+		v.addf("%s: MinRestArgs %d is above MaxRestArgs %d", scope, minCount, maxCount)
+	}
+}
+
+// checkArgConstraints validates that ConflictsWithFlag and RequireArgsOrFlag
+// name flags visible in scope and optional arguments of it
+func (v *definitionValidator) checkArgConstraints(scope string, args []*Arg, reqs []argFlagRequirement, flags map[string]*Flag) {
+	known := func(name string) bool { return flags[name] != nil || v.globals[name] != nil }
+	for _, arg := range args {
+		for _, flag := range arg.conflictFlags {
+			if !known(flag) {
+				v.addf("%s: argument <%s> conflicts with unregistered flag --%s", scope, arg.Name, flag)
+			}
+		}
+	}
+	for _, req := range reqs {
+		i := slices.IndexFunc(args, func(arg *Arg) bool { return arg.Name == req.arg })
+		switch {
+		case i < 0:
+			v.addf("%s: RequireArgsOrFlag references unregistered argument <%s>", scope, req.arg)
+		case args[i].Required:
+			v.addf("%s: RequireArgsOrFlag needs argument <%s> to be optional", scope, req.arg)
+		}
+		if len(req.flags) == 0 {
+			v.addf("%s: RequireArgsOrFlag for argument <%s> names no flags", scope, req.arg)
+		}
+		for _, flag := range req.flags {
+			if !known(flag) {
+				v.addf("%s: RequireArgsOrFlag references unregistered flag --%s", scope, flag)
+			}
+		}
+	}
+}
+
+// checkGroupDefault validates that a group's DefaultFlag is one of its bool flags
+func (v *definitionValidator) checkGroupDefault(scope string, group *FlagGroup) {
+	if !group.selectsDefault() {
+		v.addf("%s: flag group %q needs a MutuallyExclusive, ExactlyOne or AtLeastOne constraint to select a default flag",
+			scope, group.Name)
+		return
+	}
+	for _, flag := range group.Flags {
+		if flag.Name == group.DefaultFlag {
+			if flag.Type != FlagTypeBool {
+				v.addf("%s: default flag --%s of group %q is not a bool flag", scope, flag.Name, group.Name)
+			}
+			return
+		}
+	}
+	v.addf("%s: default flag --%s is not in flag group %q", scope, group.DefaultFlag, group.Name)
+}
+
+// checkGroups validates that every grouped flag is registered in scope and visible
+func (v *definitionValidator) checkGroups(scope string, groups []*FlagGroup, flags map[string]*Flag) {
+	for _, group := range groups {
+		for _, flag := range group.Flags {
+			switch {
+			case flags[flag.Name] != flag:
+				v.addf("%s: flag group %q references unregistered flag --%s", scope, group.Name, flag.Name)
+			case flag.Hidden:
+				v.addf("%s: flag group %q references hidden flag --%s", scope, group.Name, flag.Name)
+			}
+		}
+		if group.DefaultFlag != "" {
+			v.checkGroupDefault(scope, group)
+		}
+	}
+}
+
+// checkCommands validates sibling names/aliases and recurses into each command
+func (v *definitionValidator) checkCommands(parent string, commands map[string]*Command) {
+	owners := make(map[string]string, len(commands))
+	for _, name := range sortedKeys(commands) {
+		owners[name] = name
+	}
+	for _, name := range sortedKeys(commands) {
+		cmd := commands[name]
+		path := parent + name
+
+		for _, alias := range cmd.Aliases {
+			if owner, taken := owners[alias]; taken && owner != name {
+				v.addf("command %s: alias %q collides with %q", path, alias, parent+owner)
+				continue
+			}
+			owners[alias] = name
+		}
+
+		scope := "command " + path
+		v.checkFlags(scope, cmd.flags, cmd.shortFlags, v.globals)
+		v.checkArgs(scope, cmd.args)
+		v.checkRestCount(scope, cmd.restMin, cmd.restMax)
+		v.checkArgConstraints(scope, cmd.args, cmd.argsOrFlag, cmd.flags)
+		v.checkGroups(scope, cmd.flagGroups, cmd.flags)
+		v.checkCommands(path+" ", cmd.subcommands)
+	}
+}
+
+// isHelpFlag reports whether one of two colliding flags is the built-in help
+// flag, which deliberately yields its short form to user flags
+func isHelpFlag(a, b *Flag) bool {
+	return a.Name == "help" || b.Name == "help"
+}
+
+// sortedKeys returns map keys in order so reports are deterministic
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
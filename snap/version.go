@@ -0,0 +1,141 @@
+package snap
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/template"
+)
+
+// BuildInfo describes the build that produced the binary. Fields are usually
+// injected at link time, e.g. -ldflags "-X main.commit=$(git rev-parse HEAD)".
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Date    string
+	BuiltBy string
+}
+
+// VersionData is the value passed to the version template
+type VersionData struct {
+	Name string
+	BuildInfo
+	GoVersion     string
+	OS            string
+	Arch          string
+	Module        string // Main module path from debug.ReadBuildInfo
+	ModuleVersion string // Main module version ("(devel)" for local builds)
+	Modified      bool   // Built from a dirty VCS tree (vcs.modified)
+}
+
+// DefaultVersionTemplate is the output of --version and the version command
+// once VersionInfo is set; empty fields are skipped.
+const DefaultVersionTemplate = `{{.Name}} {{.Version}}
+{{- if .Commit}}
+  Commit:    {{.Commit}}{{if .Modified}} (modified){{end}}
+{{- end}}
+{{- if .Date}}
+  Built:     {{.Date}}
+{{- end}}
+{{- if .BuiltBy}}
+  Built by:  {{.BuiltBy}}
+{{- end}}
+  Go:        {{.GoVersion}}
+  Platform:  {{.OS}}/{{.Arch}}
+{{- if .Module}}
+  Module:    {{.Module}}{{if .ModuleVersion}} {{.ModuleVersion}}{{end}}
+{{- end}}
+`
+
+// VersionInfo sets detailed build information. It enables --version with
+// the VersionTemplate output and registers a "version" command at run time
+// unless the app already defines one. Empty Version, Commit and Date fall
+// back to the module version and VCS stamps recorded by the Go toolchain.
+func (a *App) VersionInfo(info BuildInfo) *App {
+	a.buildInfo = &info
+	if info.Version != "" {
+		a.version = info.Version
+	} else if a.version == "" {
+		a.version = a.versionData().Version
+	}
+	a.versionFlag = true
+	a.invalidateHelp()
+	return a
+}
+
+// VersionTemplate overrides DefaultVersionTemplate (text/template syntax,
+// executed against VersionData)
+func (a *App) VersionTemplate(tmpl string) *App {
+	a.versionTemplate = tmpl
+	return a
+}
+
+// addVersionCommand registers the built-in "version" command for VersionInfo apps
+func (a *App) addVersionCommand() {
+	if a.buildInfo == nil {
+		return
+	}
+	if _, exists := a.commands["version"]; exists {
+		return
+	}
+	a.Command("version", "Show version information").
+		Action(func(*Context) error { return a.showVersion() })
+}
+
+// versionData collects the template data from BuildInfo and the runtime
+func (a *App) versionData() VersionData {
+	data := VersionData{
+		Name:      a.name,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if a.buildInfo != nil {
+		data.BuildInfo = *a.buildInfo
+	}
+	if data.Version == "" {
+		data.Version = a.version
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return data
+	}
+	data.Module = bi.Main.Path
+	data.ModuleVersion = bi.Main.Version
+	if data.Version == "" && bi.Main.Version != "(devel)" {
+		data.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if data.Commit == "" {
+				data.Commit = setting.Value
+			}
+		case "vcs.time":
+			if data.Date == "" {
+				data.Date = setting.Value
+			}
+		case "vcs.modified":
+			data.Modified = setting.Value == "true"
+		}
+	}
+	return data
+}
+
+// renderVersion executes the version template against versionData
+func (a *App) renderVersion() (string, error) {
+	text := a.versionTemplate
+	if text == "" {
+		text = DefaultVersionTemplate
+	}
+	tmpl, err := template.New("version").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, a.versionData()); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,82 @@
+package snap
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// cmdMetaChars are interpreted by cmd.exe even inside quoted arguments
+const cmdMetaChars = `()%!^"<>&|`
+
+// QuoteWindowsArg quotes arg for a CreateProcess command line so that
+// CommandLineToArgvW and the MSVC runtime parse it back unchanged. Unlike
+// syscall.EscapeArg it is available on every platform, e.g. for printing
+// dry-run command lines.
+func QuoteWindowsArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\v\"") {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+		if c == '\\' {
+			slashes++
+			continue
+		}
+		// Backslashes are literal unless they precede a quote
+		if c == '"' {
+			b.WriteString(strings.Repeat(`\`, 2*slashes+1))
+		} else {
+			b.WriteString(strings.Repeat(`\`, slashes))
+		}
+		slashes = 0
+		b.WriteByte(c)
+	}
+	// Double trailing backslashes so they do not escape the closing quote
+	b.WriteString(strings.Repeat(`\`, 2*slashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// QuoteCmdArg quotes arg for a command line interpreted by cmd.exe (batch
+// files): CreateProcess quoting first, then every cmd metacharacter is
+// escaped with ^ so "&", "|", "%VAR%" and friends stay literal.
+func QuoteCmdArg(arg string) string {
+	quoted := QuoteWindowsArg(arg)
+	var b strings.Builder
+	for i := 0; i < len(quoted); i++ {
+		if strings.IndexByte(cmdMetaChars, quoted[i]) >= 0 {
+			b.WriteByte('^')
+		}
+		b.WriteByte(quoted[i])
+	}
+	return b.String()
+}
+
+// WindowsCommandLine builds the command line Windows receives for running
+// name with args. Batch files (.bat, .cmd) are run through
+// "cmd.exe /d /s /c" with cmd escaping; everything else uses CreateProcess rules.
+func WindowsCommandLine(name string, args ...string) string {
+	quote := QuoteWindowsArg
+	if isBatchFile(name) {
+		quote = QuoteCmdArg
+	}
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, quote(name))
+	for _, arg := range args {
+		parts = append(parts, quote(arg))
+	}
+	line := strings.Join(parts, " ")
+	if isBatchFile(name) {
+		return `cmd.exe /d /s /c "` + line + `"`
+	}
+	return line
+}
+
+// isBatchFile reports whether path is a script run by cmd.exe
+func isBatchFile(path string) bool {
+	ext := filepath.Ext(path)
+	return strings.EqualFold(ext, ".bat") || strings.EqualFold(ext, ".cmd")
+}
@@ -2,12 +2,17 @@ package snap
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ExecResult provides information about wrapped command execution
@@ -18,6 +23,18 @@ type ExecResult struct {
 	Error    error
 }
 
+// DisallowedToolAction selects how a dynamic wrapper handles a rejected tool
+type DisallowedToolAction int
+
+const (
+	DisallowedToolError DisallowedToolAction = iota // Fail with a permission error (default)
+	DisallowedToolSkip                              // Do nothing and exit successfully
+)
+
+// ErrToolSkipped is returned by WrapperSpec.ResolveArgs when the dynamic tool
+// is rejected and OnDisallowedTool is DisallowedToolSkip
+var ErrToolSkipped = errors.New("tool skipped by wrapper policy")
+
 // wrapperMode selects how child output is handled
 type wrapperMode int
 
@@ -43,6 +60,7 @@ type WrapperSpec struct {
 	BeforeExec      func(*Context, []string) ([]string, error) // Runs before exec with final args
 	AfterExec       func(*Context, *ExecResult) error          // Runs after exec with result
 	Mode            wrapperMode
+	Stdin           func() (io.ReadCloser, error)
 	TeeOut          io.Writer
 	TeeErr          io.Writer
 	CaptureAlso     bool // when true in passthrough, also capture into ExecResult
@@ -53,6 +71,40 @@ type WrapperSpec struct {
 	LeadingFlags []string
 	AfterLeading []string
 	MapBool      map[string][]string // wrapper bool flag name -> child tokens
+	// Flag value -> child token mappings (MapEnumFlag) and InjectIf args
+	MapEnum     map[string]map[string]string
+	Conditional []ConditionalArgs
+	// WrapMany skipping, result caching and summary (see SkipIf, CacheKey, Summary)
+	SkipFn       func(binary string) bool
+	CacheKeyFn   func(binary string, args []string) string
+	CacheDir     string
+	PrintSummary bool
+	// Dynamic tool policy (see AllowTools, DenyTools, FilterArgs)
+	AllowedTools []string
+	DeniedTools  []string
+	ToolFilter   func(tool string, args []string) bool
+	OnDisallowed DisallowedToolAction
+	// Per-binary argument hook, after BeforeExec (see BeforeEach)
+	BeforeEach func(ctx *Context, binary string, args []string) ([]string, error)
+	// Label each output line of WrapMany binaries (see PrefixOutput)
+	PrefixOutput bool
+	// Hermetic resolution and pinning, keyed by the binary as given to Wrap
+	// or WrapMany (see ResolveFrom, RequireVersion, RequireSHA256)
+	SearchDirs  []string
+	Versions    map[string][]string // binary -> version constraints
+	VersionArgs []string
+	Checksums   map[string][]string // binary -> accepted hex SHA-256 sums
+	// Arguments printing the binary's help, parsed once for help output
+	// (see ForwardHelp and ForwardedFlags)
+	HelpArgs      []string
+	forwardedOnce sync.Once
+	forwarded     []ForwardedFlag
+}
+
+// ConditionalArgs are injected into the child argv when When returns true
+type ConditionalArgs struct {
+	When func(*Context) bool
+	Args []string
 }
 
 // WrapperBuilder provides a fluent API to configure a wrapper.
@@ -213,6 +265,26 @@ func (b *WrapperBuilder[P]) BeforeExec(fn func(*Context, []string) ([]string, er
 	return b
 }
 
+// BeforeEach sets a function run before each binary of WrapMany is executed,
+// after BeforeExec, so arguments can differ per binary:
+//
+//	WrapMany("go1.21", "go1.22").
+//	    BeforeEach(func(ctx *snap.Context, binary string, args []string) ([]string, error) {
+//	        if binary == "go1.22" {
+//	            return append([]string{"-tags", "go122"}, args...), nil
+//	        }
+//	        return args, nil
+//	    })
+//
+// binary is the name given to WrapMany (or Wrap), before PATH resolution.
+// An error skips that binary's execution like a failed run.
+func (b *WrapperBuilder[P]) BeforeEach(
+	fn func(ctx *Context, binary string, args []string) ([]string, error),
+) *WrapperBuilder[P] {
+	b.spec.BeforeEach = fn
+	return b
+}
+
 // AfterExec sets a function to run after the wrapped binary execution completes.
 // The function receives the execution result and can inspect/process it.
 // This is useful for logging, notifications, cleanup, or custom error handling.
@@ -221,6 +293,34 @@ func (b *WrapperBuilder[P]) AfterExec(fn func(*Context, *ExecResult) error) *Wra
 	return b
 }
 
+// StdinFromString feeds s to the child's stdin, e.g. a manifest for
+// "kubectl apply -f -". Every WrapMany binary receives its own copy.
+func (b *WrapperBuilder[P]) StdinFromString(s string) *WrapperBuilder[P] {
+	b.spec.Stdin = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(s)), nil }
+	return b
+}
+
+// StdinFromFile feeds the contents of path to the child's stdin. The file is
+// opened for each execution and closed when the child exits.
+func (b *WrapperBuilder[P]) StdinFromFile(path string) *WrapperBuilder[P] {
+	b.spec.Stdin = func() (io.ReadCloser, error) { return os.Open(path) }
+	return b
+}
+
+// StdinFromReader feeds r to the child's stdin. The reader is consumed by the
+// first execution, so prefer StdinFromString or StdinFromFile with WrapMany.
+func (b *WrapperBuilder[P]) StdinFromReader(r io.Reader) *WrapperBuilder[P] {
+	b.spec.Stdin = func() (io.ReadCloser, error) { return io.NopCloser(r), nil }
+	return b
+}
+
+// NoStdin gives the child an empty stdin instead of the app's, so tools that
+// read stdin when it is not a terminal do not block.
+func (b *WrapperBuilder[P]) NoStdin() *WrapperBuilder[P] {
+	b.spec.Stdin = func() (io.ReadCloser, error) { return nil, nil }
+	return b
+}
+
 // Parallel enables parallel execution for WrapMany(). By default, binaries are
 // executed sequentially. When enabled, all binaries run concurrently.
 func (b *WrapperBuilder[P]) Parallel() *WrapperBuilder[P] {
@@ -228,6 +328,20 @@ func (b *WrapperBuilder[P]) Parallel() *WrapperBuilder[P] {
 	return b
 }
 
+// PrefixOutput labels every line a WrapMany binary prints with its name,
+// color-coded when the terminal supports it, and writes whole lines only, so
+// the output of Parallel() runs stays readable:
+//
+//	go1.21 | ok  example.com/pkg  0.2s
+//	go1.22 | ok  example.com/pkg  0.3s
+//
+// It applies to Passthrough output and to what hooks print through the
+// context for that binary.
+func (b *WrapperBuilder[P]) PrefixOutput() *WrapperBuilder[P] {
+	b.spec.PrefixOutput = true
+	return b
+}
+
 // StopOnError controls whether execution stops on the first error (default: true).
 // When set to false, all binaries will be executed even if some fail.
 // Only applicable for WrapMany().
@@ -293,27 +407,33 @@ func (b *WrapperBuilder[P]) CaptureTo(out, err io.Writer) *WrapperBuilder[P] {
 }
 
 // AllowTools restricts dynamic wrapping (WrapDynamic) to the given tool base names.
-// When set, the dynamic tool must match one of the allowed names (by filepath.Base).
+// When set, the dynamic tool must match one of the allowed names (by filepath.Base,
+// ignoring a ".exe" suffix); other tools are handled as set by OnDisallowedTool.
 func (b *WrapperBuilder[P]) AllowTools(names ...string) *WrapperBuilder[P] {
-	// piggyback on TransformTool to enforce at runtime
-	prev := b.spec.TransformToolFn
-	b.spec.TransformToolFn = func(tool string, args []string) (string, []string, error) {
-		base := filepath.Base(tool)
-		ok := false
-		for _, n := range names {
-			if n == base {
-				ok = true
-				break
-			}
-		}
-		if !ok {
-			return tool, args, NewError(ErrorTypePermission, "tool not allowed: "+base)
-		}
-		if prev != nil {
-			return prev(tool, args)
-		}
-		return tool, args, nil
-	}
+	b.spec.AllowedTools = append(b.spec.AllowedTools, names...)
+	return b
+}
+
+// DenyTools blocks the given tool base names in dynamic wrapping (WrapDynamic),
+// handled as set by OnDisallowedTool. Deny wins over AllowTools.
+func (b *WrapperBuilder[P]) DenyTools(names ...string) *WrapperBuilder[P] {
+	b.spec.DeniedTools = append(b.spec.DeniedTools, names...)
+	return b
+}
+
+// FilterArgs decides per invocation whether a dynamic tool may run: fn receives
+// the tool base name and its arguments and returns false to disallow the call.
+// It runs after AllowTools/DenyTools and before TransformTool.
+func (b *WrapperBuilder[P]) FilterArgs(fn func(tool string, args []string) bool) *WrapperBuilder[P] {
+	b.spec.ToolFilter = fn
+	return b
+}
+
+// OnDisallowedTool selects what happens when AllowTools, DenyTools or
+// FilterArgs rejects a dynamic tool: DisallowedToolError (default) fails with
+// a permission error, DisallowedToolSkip exits successfully without running it.
+func (b *WrapperBuilder[P]) OnDisallowedTool(action DisallowedToolAction) *WrapperBuilder[P] {
+	b.spec.OnDisallowed = action
 	return b
 }
 
@@ -360,6 +480,25 @@ func (b *WrapperBuilder[P]) MapBoolFlag(wrapperFlag string, childTokens ...strin
 	return b
 }
 
+// MapEnumFlag maps values of a wrapper enum (or string) flag to child tokens,
+// inserted among leading flags like MapBoolFlag, e.g.
+// MapEnumFlag("format", map[string]string{"json": "--output=json"}). Each
+// value of an enum slice flag is mapped; unmapped values add nothing.
+func (b *WrapperBuilder[P]) MapEnumFlag(wrapperFlag string, values map[string]string) *WrapperBuilder[P] {
+	if b.spec.MapEnum == nil {
+		b.spec.MapEnum = make(map[string]map[string]string)
+	}
+	b.spec.MapEnum[wrapperFlag] = maps.Clone(values)
+	return b
+}
+
+// InjectIf injects args after InjectArgsPre when cond returns true for the
+// current run; placeholders are expanded as in InjectArgsPre.
+func (b *WrapperBuilder[P]) InjectIf(cond func(ctx *Context) bool, args ...string) *WrapperBuilder[P] {
+	b.spec.Conditional = append(b.spec.Conditional, ConditionalArgs{When: cond, Args: args})
+	return b
+}
+
 // run executes the wrapper with the given context and original args slice.
 func (w *WrapperSpec) run(ctx *Context, _ []string) error {
 	// Handle WrapMany - multiple binaries
@@ -371,18 +510,60 @@ func (w *WrapperSpec) run(ctx *Context, _ []string) error {
 	return w.runSingle(ctx, w.Binary)
 }
 
-//nolint:gocognit,gocyclo,cyclop,funlen // Wrapper execution covers resolution, arg building, env, and IO wiring.
-func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
+// Wrapper returns the app-level wrapper configured with Wrap, or nil
+func (a *App) Wrapper() *WrapperSpec {
+	return a.defaultWrapper
+}
+
+// ResolveWrapperArgs parses args like Run and returns the argv the selected
+// wrapper (the matched command's, else the app-level one) would execute,
+// without running hooks, actions or the binary. See WrapperSpec.ResolveArgs.
+func (a *App) ResolveWrapperArgs(args []string) ([]string, error) {
+	result, err := a.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	w := a.defaultWrapper
+	if result.Command != nil {
+		w = result.Command.wrapper
+	}
+	if w == nil {
+		return nil, NewError(ErrorTypeInvalidArgument, "no wrapper configured for this command")
+	}
+	return w.ResolveArgs(&Context{App: a, Result: result, ctx: context.Background(), metadata: make(map[string]any)})
+}
+
+// ResolveArgs returns the argv the wrapped binary would receive for ctx, after
+// injected args, forwarding, the leading-flag DSL, MapBoolFlag, TransformTool
+// and TransformArgs, without executing anything. BeforeExec is not run. Use it
+// to unit-test wrapper pipelines or to print dry-run output. A dynamic tool
+// skipped by the tool policy yields ErrToolSkipped.
+func (w *WrapperSpec) ResolveArgs(ctx *Context) ([]string, error) {
+	bin := w.Binary
+	if len(w.Binaries) > 0 {
+		bin = w.Binaries[0] // every binary of WrapMany receives the same argv
+	}
+	_, argv, err := w.resolve(ctx, bin)
+	return argv, err
+}
+
+// resolve determines the binary and final argv (before BeforeExec) for ctx
+//
+//nolint:gocognit // Arg building applies each DSL step in a fixed order.
+func (w *WrapperSpec) resolve(ctx *Context, bin string) (string, []string, error) {
 	// Resolve binary
 	if bin == "" && w.Dynamic {
 		// Dynamic shim requires first positional arg as tool - sanity check
 		if len(ctx.Args()) == 0 {
-			return NewError(ErrorTypeInvalidValue, "missing tool for dynamic wrapper")
+			return "", nil, NewError(ErrorTypeInvalidValue, "missing tool for dynamic wrapper")
 		}
 		bin = ctx.Args()[0]
+		if err := w.checkTool(bin, ctx.Args()[1:]); err != nil {
+			return "", nil, err
+		}
 	}
 	if bin == "" {
-		return NewError(ErrorTypeInvalidValue, "missing wrapper binary")
+		return "", nil, NewError(ErrorTypeInvalidValue, "missing wrapper binary")
 	}
 	if w.DiscoverOnPATH && !filepath.IsAbs(bin) {
 		if p, err := exec.LookPath(bin); err == nil {
@@ -392,7 +573,12 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 
 	// Build argv
 	argv := make([]string, 0, len(w.PreArgs)+len(w.PostArgs)+len(ctx.Args())+8)
-	pre := substituteTokens(w.PreArgs)
+	pre := w.expandTokens(ctx, w.PreArgs)
+	for _, cond := range w.Conditional {
+		if cond.When(ctx) {
+			pre = append(pre, w.expandTokens(ctx, cond.Args)...)
+		}
+	}
 	forwarded := make([]string, 0, len(ctx.Args()))
 	if w.ForwardArgs {
 		// For dynamic: forward tool args (skip tool path)
@@ -405,7 +591,7 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 		}
 	}
 	// DSL reordering for leading flags and after-leading tokens
-	if len(w.LeadingFlags) > 0 || len(w.AfterLeading) > 0 || len(w.MapBool) > 0 {
+	if len(w.LeadingFlags) > 0 || len(w.AfterLeading) > 0 || len(w.MapBool) > 0 || len(w.MapEnum) > 0 {
 		leading, rest := splitLeading(forwarded, w.LeadingFlags)
 		// mapped wrapper bool flags
 		if len(w.MapBool) > 0 {
@@ -415,21 +601,33 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 				}
 			}
 		}
+		// mapped wrapper enum/string flag values
+		if len(w.MapEnum) > 0 {
+			var mapped []string
+			for _, name := range sortedKeys(w.MapEnum) {
+				for _, value := range w.tokenValues(ctx, "FLAG:"+name) {
+					if child, ok := w.MapEnum[name][value]; ok {
+						mapped = append(mapped, child)
+					}
+				}
+			}
+			leading = append(mapped, leading...)
+		}
 		forwarded = make([]string, 0, len(leading)+len(w.AfterLeading)+len(rest))
 		forwarded = append(forwarded, leading...)
-		forwarded = append(forwarded, substituteTokens(w.AfterLeading)...)
+		forwarded = append(forwarded, w.expandTokens(ctx, w.AfterLeading)...)
 		forwarded = append(forwarded, rest...)
 	}
 	argv = append(argv, pre...)
 	argv = append(argv, forwarded...)
-	argv = append(argv, substituteTokens(w.PostArgs)...)
+	argv = append(argv, w.expandTokens(ctx, w.PostArgs)...)
 	// Dynamic tool transform (allows replacing tool path or its args)
 	if w.Dynamic && w.TransformToolFn != nil {
 		toolArgs := argv
 		var err error
 		bin, toolArgs, err = w.TransformToolFn(bin, toolArgs)
 		if err != nil {
-			return err
+			return "", nil, err
 		}
 		argv = toolArgs
 	}
@@ -437,21 +635,73 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 		var err error
 		argv, err = w.Transform(ctx, argv)
 		if err != nil {
-			return err
+			return "", nil, err
 		}
 	}
+	return bin, argv, nil
+}
+
+// checkTool applies the dynamic tool policy to tool and its arguments
+func (w *WrapperSpec) checkTool(tool string, args []string) error {
+	if len(w.AllowedTools) == 0 && len(w.DeniedTools) == 0 && w.ToolFilter == nil {
+		return nil
+	}
+	base := strings.TrimSuffix(filepath.Base(tool), ".exe")
+	switch {
+	case slices.Contains(w.DeniedTools, base):
+	case len(w.AllowedTools) > 0 && !slices.Contains(w.AllowedTools, base):
+	case w.ToolFilter != nil && !w.ToolFilter(base, args):
+	default:
+		return nil
+	}
+	if w.OnDisallowed == DisallowedToolSkip {
+		return ErrToolSkipped
+	}
+	return NewError(ErrorTypePermission, "tool not allowed: "+base)
+}
+
+//nolint:gocognit,gocyclo,cyclop,funlen // Wrapper execution covers resolution, env, and IO wiring.
+func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
+	name := bin
+	bin, argv, err := w.resolve(ctx, bin)
+	if errors.Is(err, ErrToolSkipped) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bin, err = w.checkBinary(ctx, name, bin); err != nil {
+		return err
+	}
 
 	// BeforeExec hook - final chance to modify args before execution
 	if w.BeforeExec != nil {
-		var err error
 		argv, err = w.BeforeExec(ctx, argv)
 		if err != nil {
 			return err
 		}
 	}
+	if w.BeforeEach != nil {
+		argv, err = w.BeforeEach(ctx, name, argv)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Result cache - replay a stored success instead of executing
+	cacheKey := ""
+	if w.CacheKeyFn != nil {
+		cacheKey = w.CacheKeyFn(name, argv)
+		if cacheKey != "" {
+			if res, ok := w.loadCache(ctx, cacheKey, bin, argv); ok {
+				return w.replayCache(ctx, res)
+			}
+		}
+	}
 
 	// Prepare command
 	cmd := exec.CommandContext(ctx.Context(), bin, argv...)
+	prepareCommand(cmd)
 	if w.WorkingDir != "" {
 		cmd.Dir = w.WorkingDir
 	}
@@ -467,13 +717,19 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 	}
 
 	// IO wiring
+	stdin, closeStdin, err := w.openStdin(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStdin()
+	cmd.Stdin = stdin
 	switch w.Mode {
 	case modePassthrough:
 		outW := ctx.Stdout()
 		errW := ctx.Stderr()
 		var outBuf, errBuf bytes.Buffer
 		//nolint:nestif // IO wiring needs explicit nested branches to avoid subtle bugs.
-		if w.CaptureAlso {
+		if w.CaptureAlso || cacheKey != "" {
 			// capture while streaming
 			mwOut := []io.Writer{outW}
 			if w.TeeOut != nil {
@@ -498,17 +754,21 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 		}
 		cmd.Stdout = outW
 		cmd.Stderr = errW
-		cmd.Stdin = ctx.Stdin()
-		runErr := cmd.Run()
+		runErr := execWrapped(ctx, cmd)
 
 		// Build result
 		var res *ExecResult
-		if w.CaptureAlso {
+		if w.CaptureAlso || cacheKey != "" {
 			res = &ExecResult{Stdout: outBuf.Bytes(), Stderr: errBuf.Bytes(), Error: runErr}
 			if ee := toExitError(runErr); ee != nil {
 				res.ExitCode = ee.Code
 			}
-			ctx.Set("__wrapper_result__", res)
+			if w.CaptureAlso {
+				ctx.Set("__wrapper_result__", res)
+			}
+			if cacheKey != "" {
+				w.storeCache(ctx, cacheKey, bin, argv, res)
+			}
 		} else {
 			// Create minimal result for AfterExec hook even without capture
 			res = &ExecResult{Error: runErr}
@@ -532,8 +792,7 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 		var outBuf, errBuf bytes.Buffer
 		cmd.Stdout = &outBuf
 		cmd.Stderr = &errBuf
-		cmd.Stdin = ctx.Stdin()
-		err := cmd.Run()
+		err := execWrapped(ctx, cmd)
 		res := &ExecResult{Stdout: outBuf.Bytes(), Stderr: errBuf.Bytes(), Error: err}
 		if ee := toExitError(err); ee != nil {
 			// Attach exit code
@@ -541,6 +800,9 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 		}
 		// Expose via context metadata
 		ctx.Set("__wrapper_result__", res)
+		if cacheKey != "" {
+			w.storeCache(ctx, cacheKey, bin, argv, res)
+		}
 
 		// AfterExec hook - process result after execution
 		if w.AfterExec != nil {
@@ -558,47 +820,111 @@ func (w *WrapperSpec) runSingle(ctx *Context, bin string) error {
 	}
 }
 
-// runMany executes multiple binaries sequentially or in parallel
+// openStdin returns the child's stdin for one execution and a func that
+// releases it: the reader set with Context.SetWrapperStdin, the spec's Stdin
+// source or the app's stdin (passed as is, so a terminal stays a terminal).
+// A nil reader leaves the child with an empty stdin.
+func (w *WrapperSpec) openStdin(ctx *Context) (io.Reader, func(), error) {
+	noop := func() {}
+	if r, ok := ctx.Get("__wrapper_stdin__").(io.Reader); ok {
+		return r, noop, nil
+	}
+	if w.Stdin == nil {
+		return ctx.Stdin(), noop, nil
+	}
+	rc, err := w.Stdin()
+	if err != nil || rc == nil {
+		return nil, noop, err
+	}
+	return rc, func() { _ = rc.Close() }, nil
+}
+
+// runMany executes multiple binaries sequentially or in parallel and records
+// the outcome of each for WrapperRuns (and Summary)
 func (w *WrapperSpec) runMany(ctx *Context) error {
 	// Store binaries list in context for Binaries() accessor
 	ctx.binaries = w.Binaries
 
+	var mux *outputMux
+	if w.PrefixOutput {
+		mux = newOutputMux(w.Binaries)
+	}
+	var runs []BinaryRun
+	var err error
 	if w.Parallel {
-		return w.runManyParallel(ctx)
+		runs, err = w.runManyParallel(ctx, mux)
+	} else {
+		runs, err = w.runManySequential(ctx, mux)
+	}
+	ctx.Set("__wrapper_runs__", runs)
+	if w.PrintSummary {
+		printSummary(ctx.Stderr(), runs)
+	}
+	return err
+}
+
+// runBinary runs one binary of WrapMany unless SkipIf excludes it, through
+// mux when its output is prefixed
+func (w *WrapperSpec) runBinary(ctx *Context, binary string, mux *outputMux) (BinaryRun, error) {
+	run := BinaryRun{Binary: binary}
+	if w.SkipFn != nil && w.SkipFn(binary) {
+		run.Status = BinarySkipped
+		return run, nil
+	}
+	if mux != nil {
+		var flush func()
+		ctx, flush = mux.scope(ctx)
+		defer flush()
+	}
+	ctx.Set("__wrapper_cached__", false)
+	start := time.Now()
+	err := w.runSingle(ctx, binary)
+	run.Duration = time.Since(start)
+	if cached, _ := ctx.Get("__wrapper_cached__").(bool); cached {
+		run.Status = BinaryCached
 	}
-	return w.runManySequential(ctx)
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		run.ExitCode = exitErr.Code
+	} else if err != nil {
+		run.ExitCode = 1
+	}
+	return run, err
 }
 
 // runManySequential executes binaries one by one
-func (w *WrapperSpec) runManySequential(ctx *Context) error {
-	for _, binary := range w.Binaries {
+func (w *WrapperSpec) runManySequential(ctx *Context, mux *outputMux) ([]BinaryRun, error) {
+	runs := make([]BinaryRun, 0, len(w.Binaries))
+	for i, binary := range w.Binaries {
 		// Set current binary in context for CurrentBinary() accessor
-		ctx.currentBinary = binary
+		ctx.currentBinary, ctx.binaryIndex = binary, i
 
 		// Execute this binary
-		err := w.runSingle(ctx, binary)
+		run, err := w.runBinary(ctx, binary, mux)
+		runs = append(runs, run)
 
 		// Handle error based on StopOnError setting
 		if err != nil && w.StopOnError {
-			return err
+			return runs, err
 		}
 		// Continue to next binary even if this one failed
 	}
-	return nil
+	return runs, nil
 }
 
 // runManyParallel executes binaries concurrently
-func (w *WrapperSpec) runManyParallel(ctx *Context) error {
+func (w *WrapperSpec) runManyParallel(ctx *Context, mux *outputMux) ([]BinaryRun, error) {
 	type result struct {
-		binary string
-		err    error
+		index int
+		run   BinaryRun
+		err   error
 	}
 
 	results := make(chan result, len(w.Binaries))
 
 	// Launch goroutines for each binary
-	for _, binary := range w.Binaries {
-		go func(bin string) {
+	for i, binary := range w.Binaries {
+		go func(index int, bin string) {
 			// Create a copy of context for this goroutine
 			goroutineCtx := &Context{
 				App:           ctx.App,
@@ -607,18 +933,24 @@ func (w *WrapperSpec) runManyParallel(ctx *Context) error {
 				parent:        ctx.parent,
 				metadata:      make(map[string]any),
 				currentBinary: bin,
+				binaryIndex:   index,
 				binaries:      w.Binaries,
+				io:            ctx.io,
+				logger:        ctx.logger,
+				captured:      ctx.captured,
 			}
 
-			err := w.runSingle(goroutineCtx, bin)
-			results <- result{binary: bin, err: err}
-		}(binary)
+			run, err := w.runBinary(goroutineCtx, bin, mux)
+			results <- result{index: index, run: run, err: err}
+		}(i, binary)
 	}
 
-	// Collect results
+	// Collect results in WrapMany order
+	runs := make([]BinaryRun, len(w.Binaries))
 	var firstErr error
 	for i := 0; i < len(w.Binaries); i++ {
 		res := <-results
+		runs[res.index] = res.run
 		if res.err != nil && firstErr == nil {
 			firstErr = res.err
 			// If StopOnError is true, we still wait for all to complete
@@ -627,9 +959,9 @@ func (w *WrapperSpec) runManyParallel(ctx *Context) error {
 	}
 
 	if firstErr != nil && w.StopOnError {
-		return firstErr
+		return runs, firstErr
 	}
-	return nil
+	return runs, nil
 }
 
 func toExitError(err error) *ExitError {
@@ -644,21 +976,6 @@ func toExitError(err error) *ExitError {
 	return &ExitError{Code: 1, Err: err}
 }
 
-func substituteTokens(args []string) []string {
-	if len(args) == 0 {
-		return args
-	}
-	out := make([]string, 0, len(args))
-	self, _ := os.Executable()
-	for _, a := range args {
-		if strings.Contains(a, "${SELF}") {
-			a = strings.ReplaceAll(a, "${SELF}", self)
-		}
-		out = append(out, a)
-	}
-	return out
-}
-
 func splitLeading(args []string, leadingSet []string) ([]string, []string) {
 	if len(leadingSet) == 0 {
 		return nil, args
@@ -0,0 +1,204 @@
+package snap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// BinaryStatus tells how a binary of a wrapper run was handled
+type BinaryStatus int
+
+// Binary outcomes reported by Context.WrapperRuns
+const (
+	BinaryExecuted BinaryStatus = iota // Ran (successfully or not, see ExitCode)
+	BinarySkipped                      // Excluded by SkipIf
+	BinaryCached                       // Replayed from the result cache
+)
+
+// String returns "executed", "skipped" or "cached"
+func (s BinaryStatus) String() string {
+	switch s {
+	case BinarySkipped:
+		return "skipped"
+	case BinaryCached:
+		return "cached"
+	default:
+		return "executed"
+	}
+}
+
+// BinaryRun is the outcome of one binary in a wrapper run
+type BinaryRun struct {
+	Binary   string
+	Status   BinaryStatus
+	ExitCode int
+	Duration time.Duration
+}
+
+// cachedResult is the on-disk form of a successful execution
+type cachedResult struct {
+	Binary string   `json:"binary"`
+	Args   []string `json:"args"`
+	Stdout []byte   `json:"stdout"`
+	Stderr []byte   `json:"stderr"`
+}
+
+// SkipIf excludes binaries of WrapMany for which fn returns true, e.g. Go
+// versions that are not installed. Skipped binaries are reported as
+// BinarySkipped and do not run hooks.
+func (b *WrapperBuilder[P]) SkipIf(fn func(binary string) bool) *WrapperBuilder[P] {
+	b.spec.SkipFn = fn
+	return b
+}
+
+// CacheKey enables result caching: fn derives a key from the binary and its
+// final argv (after BeforeExec), typically a hash of the inputs the command
+// depends on. When a successful result is stored under the key, its output is
+// replayed and AfterExec runs with it instead of executing the binary again.
+// An empty key disables caching for that execution; failures are never cached.
+func (b *WrapperBuilder[P]) CacheKey(fn func(binary string, args []string) string) *WrapperBuilder[P] {
+	b.spec.CacheKeyFn = fn
+	return b
+}
+
+// CacheDir sets where CacheKey results are stored (default: the "wrapper"
+// directory under App.CacheDir)
+func (b *WrapperBuilder[P]) CacheDir(dir string) *WrapperBuilder[P] {
+	b.spec.CacheDir = dir
+	return b
+}
+
+// Summary prints a per-binary report (executed, skipped or cached, with exit
+// code and duration) to stderr after a WrapMany run
+func (b *WrapperBuilder[P]) Summary() *WrapperBuilder[P] {
+	b.spec.PrintSummary = true
+	return b
+}
+
+// WrapperRuns returns the outcome of every binary of the last WrapMany run,
+// in the order of WrapMany
+func (c *Context) WrapperRuns() []BinaryRun {
+	runs, _ := c.Get("__wrapper_runs__").([]BinaryRun)
+	return runs
+}
+
+// cachePath returns the file holding the result of bin with argv under key,
+// or "" when there is no usable cache directory. The binary path and argv are
+// part of the hash so a key that ignores them cannot replay another command.
+func (w *WrapperSpec) cachePath(ctx *Context, key, bin string, argv []string) string {
+	dir := w.CacheDir
+	if dir == "" && ctx.App != nil {
+		if base := ctx.App.CacheDir(); base != "" {
+			dir = filepath.Join(base, "wrapper")
+		}
+	}
+	if dir == "" {
+		return ""
+	}
+	// NUL cannot occur in argv, so the fields cannot run into each other
+	h := sha256.New()
+	for _, field := range append([]string{key, bin}, argv...) {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+// loadCache returns the stored result of bin with argv under key, if any
+func (w *WrapperSpec) loadCache(ctx *Context, key, bin string, argv []string) (*ExecResult, bool) {
+	path := w.cachePath(ctx, key, bin, argv)
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedResult
+	if json.Unmarshal(data, &cached) != nil || cached.Binary != bin || !slices.Equal(cached.Args, argv) {
+		return nil, false
+	}
+	return &ExecResult{Stdout: cached.Stdout, Stderr: cached.Stderr}, true
+}
+
+// storeCache saves a successful result of bin with argv under key; cache
+// errors are ignored because the run itself succeeded. The file is written
+// under a unique name and renamed, so parallel runs never see a partial one.
+func (w *WrapperSpec) storeCache(ctx *Context, key, bin string, argv []string, res *ExecResult) {
+	path := w.cachePath(ctx, key, bin, argv)
+	if path == "" || res.Error != nil {
+		return
+	}
+	data, err := json.Marshal(cachedResult{Binary: bin, Args: argv, Stdout: res.Stdout, Stderr: res.Stderr})
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(path), 0o755) != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), path) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// replayCache writes a cached result like an execution in the current mode
+// would and runs AfterExec with it
+func (w *WrapperSpec) replayCache(ctx *Context, res *ExecResult) error {
+	ctx.Set("__wrapper_cached__", true)
+	if w.Mode == modePassthrough {
+		outW, errW := ctx.Stdout(), ctx.Stderr()
+		if w.TeeOut != nil {
+			outW = io.MultiWriter(outW, w.TeeOut)
+		}
+		if w.TeeErr != nil {
+			errW = io.MultiWriter(errW, w.TeeErr)
+		}
+		_, _ = outW.Write(res.Stdout)
+		_, _ = errW.Write(res.Stderr)
+	}
+	if w.Mode == modeCapture || w.CaptureAlso {
+		ctx.Set("__wrapper_result__", res)
+	}
+	if w.AfterExec != nil {
+		return w.AfterExec(ctx, res)
+	}
+	return nil
+}
+
+// printSummary writes the per-binary report of a WrapMany run
+func printSummary(w io.Writer, runs []BinaryRun) {
+	width := 0
+	for _, run := range runs {
+		width = max(width, len(run.Binary))
+	}
+	executed, skipped, cached := 0, 0, 0
+	for _, run := range runs {
+		switch run.Status {
+		case BinarySkipped:
+			skipped++
+			fmt.Fprintf(w, "  %-*s  skipped\n", width, run.Binary)
+		case BinaryCached:
+			cached++
+			fmt.Fprintf(w, "  %-*s  cached\n", width, run.Binary)
+		case BinaryExecuted:
+			executed++
+			fmt.Fprintf(w, "  %-*s  exit %d  %s\n", width, run.Binary, run.ExitCode, run.Duration.Round(time.Millisecond))
+		}
+	}
+	fmt.Fprintf(w, "%d executed, %d cached, %d skipped\n", executed, cached, skipped)
+}
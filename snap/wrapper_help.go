@@ -0,0 +1,191 @@
+package snap
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ForwardedFlag is a flag of the wrapped binary, parsed from its own help
+// output (see ForwardHelp)
+type ForwardedFlag struct {
+	Names       []string // Spellings as printed, e.g. "-v" and "--verbose"
+	Value       string   // Value placeholder, e.g. "FILE"; empty for switches
+	Description string
+}
+
+// forwardedFlagName matches a flag name as printed in help output
+var forwardedFlagName = regexp.MustCompile(`^--?[A-Za-z0-9?][A-Za-z0-9_.:?-]*$`)
+
+// ForwardHelp makes help for the wrapper's command (or the app, for an
+// app-level wrapper) list the wrapped binary's own flags under "Forwarded
+// Flags", parsed from the output of running it with args ("--help" when none
+// are given). The binary runs only when help is shown, once per process; a
+// binary that is missing or prints no recognizable flags adds no section.
+// Flags spelled like one of snap's own flags are left out, since snap would
+// consume them. Dynamic wrappers and WrapMany are not supported.
+func (b *WrapperBuilder[P]) ForwardHelp(args ...string) *WrapperBuilder[P] {
+	if len(args) == 0 {
+		args = []string{"--help"}
+	}
+	b.spec.HelpArgs = args
+	return b
+}
+
+// ForwardedFlags returns the flags of the wrapped binary parsed from its help
+// output, for help and completion; nil unless ForwardHelp is set
+func (w *WrapperSpec) ForwardedFlags() []ForwardedFlag {
+	if len(w.HelpArgs) == 0 || w.Binary == "" || w.Dynamic {
+		return nil
+	}
+	w.forwardedOnce.Do(func() {
+		path := w.Binary
+		if len(w.SearchDirs) > 0 {
+			found, ok := findInDirs(path, w.SearchDirs)
+			if !ok {
+				return
+			}
+			path = found
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defaultCheckTimeout)
+		defer cancel()
+		// Many tools print usage to stderr and exit non-zero, so any output counts
+		out, _ := exec.CommandContext(ctx, path, w.HelpArgs...).CombinedOutput()
+		w.forwarded = ParseHelpFlags(string(out))
+	})
+	return w.forwarded
+}
+
+// ParseHelpFlags extracts the flags from help text in the common layouts:
+// GNU style ("  -v, --verbose     be verbose", "--file=FILE  read FILE") and
+// Go flag package style ("  -race" followed by an indented description
+// line). Lines that do not start with a dash after indentation are ignored,
+// and a flag listed twice is kept once.
+func ParseHelpFlags(text string) []ForwardedFlag {
+	var flags []ForwardedFlag
+	seen := make(map[string]bool)
+	var last *ForwardedFlag
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		if !strings.HasPrefix(trimmed, "-") {
+			// A description on its own line belongs to the flag above it
+			if last != nil && last.Description == "" && trimmed != "" && len(trimmed) < len(line) {
+				last.Description = trimmed
+			}
+			last = nil
+			continue
+		}
+		flag, ok := parseHelpLine(trimmed)
+		if !ok || seen[flag.Names[0]] {
+			last = nil
+			continue
+		}
+		seen[flag.Names[0]] = true
+		flags = append(flags, flag)
+		last = &flags[len(flags)-1]
+	}
+	return flags
+}
+
+// parseHelpLine parses one help line starting with a dash: the flag names
+// and value up to the first run of two spaces or a tab, then the description
+func parseHelpLine(line string) (ForwardedFlag, bool) {
+	spec, description := line, ""
+	if i := strings.IndexByte(line, '\t'); i >= 0 {
+		spec, description = line[:i], line[i:]
+	}
+	if i := strings.Index(spec, "  "); i >= 0 {
+		spec, description = line[:i], line[i:]
+	}
+	var flag ForwardedFlag
+	flag.Description = strings.TrimSpace(description)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value := part, ""
+		if i := strings.IndexAny(part, "=[ "); i >= 0 {
+			name, value = part[:i], strings.TrimSpace(strings.TrimLeft(part[i:], "=[ "))
+			value = strings.TrimSuffix(value, "]")
+		}
+		if !forwardedFlagName.MatchString(name) {
+			return ForwardedFlag{}, false
+		}
+		flag.Names = append(flag.Names, name)
+		if value != "" {
+			flag.Value = value
+		}
+	}
+	return flag, len(flag.Names) > 0
+}
+
+// showForwardedFlags prints the Forwarded Flags section for w, leaving out
+// flags that snap parses itself (own lists the flags in scope)
+func (a *App) showForwardedFlags(w *WrapperSpec, own map[string]*Flag) {
+	if w == nil {
+		return
+	}
+	var shown []ForwardedFlag
+	for _, flag := range w.ForwardedFlags() {
+		if !a.shadowsFlag(flag, own) {
+			shown = append(shown, flag)
+		}
+	}
+	if len(shown) == 0 {
+		return
+	}
+
+	terms := make([]string, len(shown))
+	maxWidth := 0
+	for i, flag := range shown {
+		terms[i] = strings.Join(flag.Names, ", ")
+		if flag.Value != "" {
+			terms[i] += " " + flag.Value
+		}
+		maxWidth = max(maxWidth, 2+len(terms[i]))
+	}
+
+	a.println()
+	a.println(a.heading(MsgForwardedFlags))
+	flagStyle := a.Theme().FlagStyle
+	for i, flag := range shown {
+		a.print("  ", a.styled(flagStyle, terms[i]))
+		if flag.Description == "" {
+			a.println()
+			continue
+		}
+		a.print(strings.Repeat(" ", maxWidth-len(terms[i])))
+		a.println(a.wrapHelp(flag.Description, maxWidth+2))
+	}
+}
+
+// shadowsFlag reports whether one of the names of flag is also a flag snap
+// parses (own or global), so the wrapped binary never sees it
+func (a *App) shadowsFlag(flag ForwardedFlag, own map[string]*Flag) bool {
+	for _, name := range flag.Names {
+		if strings.HasPrefix(name, "--") {
+			long := name[2:]
+			if own[long] != nil || a.flags[long] != nil {
+				return true
+			}
+			continue
+		}
+		if len(name) != 2 {
+			continue
+		}
+		short := rune(name[1])
+		for _, flags := range []map[string]*Flag{own, a.flags} {
+			for _, f := range flags {
+				if f.Short == short {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
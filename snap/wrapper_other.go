@@ -0,0 +1,8 @@
+//go:build !windows
+
+package snap
+
+import "os/exec"
+
+// prepareCommand applies platform-specific process setup (Windows only)
+func prepareCommand(*exec.Cmd) {}
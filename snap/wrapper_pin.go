@@ -0,0 +1,177 @@
+package snap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Errors wrapped by the binary checks of a wrapper (see ResolveFrom,
+// RequireVersion, RequireSHA256); match them with errors.Is or map them with
+// ExitCodes().DefineError
+var (
+	ErrBinaryNotFound = errors.New("wrapped binary not found")
+	ErrBinaryVersion  = errors.New("wrapped binary has an unsupported version")
+	ErrBinaryChecksum = errors.New("wrapped binary checksum mismatch")
+)
+
+// ResolveFrom makes the wrapper look the binary up in dirs only, in order,
+// instead of PATH, so a stray binary earlier on PATH cannot be picked up. A
+// binary missing from every directory fails with ErrBinaryNotFound before
+// anything runs. Binaries given as paths are used as they are.
+func (b *WrapperBuilder[P]) ResolveFrom(dirs ...string) *WrapperBuilder[P] {
+	b.spec.SearchDirs = append(b.spec.SearchDirs, dirs...)
+	return b
+}
+
+// RequireVersion fails the run with ErrBinaryVersion unless the version that
+// binary (as given to Wrap or WrapMany) reports satisfies every constraint,
+// e.g. RequireVersion("go", ">=1.22"). The version is the first number in the
+// output of "binary --version", or of "binary version" when that fails; set
+// other arguments with VersionArgs. Constraints use the operators of
+// CheckBinaryVersion.
+func (b *WrapperBuilder[P]) RequireVersion(binary string, constraints ...string) *WrapperBuilder[P] {
+	if b.spec.Versions == nil {
+		b.spec.Versions = make(map[string][]string)
+	}
+	b.spec.Versions[binary] = append(b.spec.Versions[binary], constraints...)
+	return b
+}
+
+// VersionArgs sets the arguments that make the wrapped binaries print their
+// version for RequireVersion
+func (b *WrapperBuilder[P]) VersionArgs(args ...string) *WrapperBuilder[P] {
+	b.spec.VersionArgs = args
+	return b
+}
+
+// RequireSHA256 pins binary (as given to Wrap or WrapMany) to the files with
+// the given hex SHA-256 sums, e.g. one per platform; any other file fails the
+// run with ErrBinaryChecksum before it is executed.
+func (b *WrapperBuilder[P]) RequireSHA256(binary string, sums ...string) *WrapperBuilder[P] {
+	if b.spec.Checksums == nil {
+		b.spec.Checksums = make(map[string][]string)
+	}
+	for _, sum := range sums {
+		b.spec.Checksums[binary] = append(b.spec.Checksums[binary], strings.ToLower(strings.TrimSpace(sum)))
+	}
+	return b
+}
+
+// checkBinary resolves name (as given to Wrap or WrapMany, or the dynamic
+// tool) from SearchDirs when set and applies its version and checksum pins;
+// path is the binary resolve found
+func (w *WrapperSpec) checkBinary(ctx *Context, name, path string) (string, error) {
+	if name == "" {
+		name = path
+	}
+	if len(w.SearchDirs) > 0 {
+		found, ok := findInDirs(name, w.SearchDirs)
+		if !ok {
+			return "", fmt.Errorf("%w: %s is not in %s", ErrBinaryNotFound, name, strings.Join(w.SearchDirs, ", "))
+		}
+		path = found
+	}
+	if sums := w.Checksums[name]; len(sums) > 0 {
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %w", ErrBinaryNotFound, name, err)
+		}
+		if !slices.Contains(sums, sum) {
+			return "", fmt.Errorf("%w: %s (%s) has sha256 %s", ErrBinaryChecksum, name, path, sum)
+		}
+	}
+	if constraints := w.Versions[name]; len(constraints) > 0 {
+		version, err := w.binaryVersion(ctx, path)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s (%s): %w", ErrBinaryVersion, name, path, err)
+		}
+		for _, constraint := range constraints {
+			ok, err := versionSatisfies(version, constraint)
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				return "", fmt.Errorf("%w: %s (%s) is %s, need %s", ErrBinaryVersion, name, path, version, constraint)
+			}
+		}
+	}
+	return path, nil
+}
+
+// binaryVersion runs path with the version arguments and returns the first
+// version number it prints
+func (w *WrapperSpec) binaryVersion(ctx *Context, path string) (string, error) {
+	attempts := [][]string{{"--version"}, {"version"}}
+	if len(w.VersionArgs) > 0 {
+		attempts = [][]string{w.VersionArgs}
+	}
+	err := errors.New("no version in output")
+	for _, args := range attempts {
+		runCtx, cancel := context.WithTimeout(ctx.Context(), defaultCheckTimeout)
+		out, runErr := exec.CommandContext(runCtx, path, args...).CombinedOutput()
+		cancel()
+		if runErr != nil {
+			err = fmt.Errorf("%s %s: %w", filepath.Base(path), QuoteArgs(args), runErr)
+			continue
+		}
+		if version := versionPattern.FindString(string(out)); version != "" {
+			return version, nil
+		}
+	}
+	return "", err
+}
+
+// findInDirs returns the first executable file named name in dirs; names
+// containing a path separator are returned unchanged when they exist. On
+// Windows, names without extension also match .exe, .com, .bat and .cmd.
+func findInDirs(name string, dirs []string) (string, bool) {
+	if strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator) {
+		return name, isExecutable(name)
+	}
+	names := []string{name}
+	if runtime.GOOS == "windows" && filepath.Ext(name) == "" {
+		names = []string{name + ".exe", name + ".com", name + ".bat", name + ".cmd", name}
+	}
+	for _, dir := range dirs {
+		for _, n := range names {
+			if path := filepath.Join(dir, n); isExecutable(path) {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isExecutable reports whether path is a regular file the user may execute
+// (any regular file on Windows)
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,98 @@
+package snap
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	snapio "github.com/dzonerzy/go-snap/io"
+)
+
+// prefixColors label the binaries of PrefixOutput in turn
+var prefixColors = []snapio.ColorSpec{
+	snapio.Cyan, snapio.Yellow, snapio.Green, snapio.Magenta, snapio.Blue,
+	snapio.BrightCyan, snapio.BrightYellow, snapio.BrightGreen, snapio.BrightMagenta, snapio.BrightBlue,
+}
+
+// outputMux serializes the line-prefixed output of the binaries of one
+// WrapMany run (see PrefixOutput)
+type outputMux struct {
+	mu    sync.Mutex
+	width int // Widest label, so the separators line up
+}
+
+// newOutputMux returns the mux for binaries
+func newOutputMux(binaries []string) *outputMux {
+	m := &outputMux{}
+	for _, binary := range binaries {
+		m.width = max(m.width, len(prefixLabel(binary)))
+	}
+	return m
+}
+
+// prefixLabel is the name shown in front of a binary's lines
+func prefixLabel(binary string) string {
+	return filepath.Base(binary)
+}
+
+// scope returns a copy of ctx whose stdout and stderr prefix every line with
+// the label of its current binary, and a function flushing unterminated
+// last lines
+func (m *outputMux) scope(ctx *Context) (*Context, func()) {
+	label := prefixLabel(ctx.currentBinary)
+	label += strings.Repeat(" ", m.width-len(label))
+	color := prefixColors[max(ctx.CurrentBinaryIndex(), 0)%len(prefixColors)]
+	prefix := snapio.NewStyle().Fg(color).Sprint(ctx.IO(), label+" |") + " "
+
+	out := &prefixWriter{mux: m, w: ctx.Stdout(), prefix: prefix}
+	errOut := &prefixWriter{mux: m, w: ctx.Stderr(), prefix: prefix}
+	return ctx.WithStdout(out).WithStderr(errOut), func() {
+		_ = out.flush()
+		_ = errOut.flush()
+	}
+}
+
+// prefixWriter writes complete lines to w under the mux lock, each preceded
+// by prefix; a partial line waits for its newline or flush
+type prefixWriter struct {
+	mux    *outputMux
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+// Write buffers p and writes out every complete line
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		line := p.buf[:i+1]
+		p.buf = p.buf[i+1:]
+		if err := p.writeLine(line); err != nil {
+			return len(b), err
+		}
+	}
+}
+
+// flush writes a pending unterminated line, adding the newline
+func (p *prefixWriter) flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+// writeLine writes one prefixed line without interleaving with other binaries
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mux.mu.Lock()
+	defer p.mux.mu.Unlock()
+	_, err := p.w.Write(append([]byte(p.prefix), line...))
+	return err
+}
@@ -5,11 +5,17 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // Test command-level wrapper that injects pre-args and forwards positional args
@@ -736,6 +742,190 @@ func TestWrapManyContextAccessors(t *testing.T) {
 	}
 }
 
+// TestWrapManyBeforeEach tests per-binary arguments and the binary index
+func TestWrapManyBeforeEach(t *testing.T) {
+	var out bytes.Buffer
+	var seen []string
+	labels := []string{"first", "second"}
+
+	app := New("test", "test wrapper")
+	app.IO().WithOut(&out)
+	app.Command("multi", "run multiple").
+		WrapMany("/bin/echo", "/bin/echo").
+		BeforeExec(func(_ *Context, args []string) ([]string, error) {
+			return append(args, "!"), nil
+		}).
+		BeforeEach(func(ctx *Context, binary string, args []string) ([]string, error) {
+			i := ctx.CurrentBinaryIndex()
+			seen = append(seen, fmt.Sprintf("%s#%d", binary, i))
+			return append([]string{labels[i]}, args...), nil
+		}).
+		Back()
+
+	if err := app.RunWithArgs(context.Background(), []string{"multi", "x"}); err != nil {
+		t.Fatalf("RunWithArgs failed: %v", err)
+	}
+	if got := out.String(); got != "first x !\nsecond x !\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+	if !slices.Equal(seen, []string{"/bin/echo#0", "/bin/echo#1"}) {
+		t.Fatalf("unexpected calls: %v", seen)
+	}
+
+	var ctx Context
+	if ctx.CurrentBinaryIndex() != -1 {
+		t.Fatalf("index outside WrapMany must be -1")
+	}
+}
+
+// TestWrapManyPrefixOutput tests labeled, line-atomic output of parallel binaries
+func TestWrapManyPrefixOutput(t *testing.T) {
+	printf, err := exec.LookPath("printf")
+	if err != nil {
+		t.Skip("printf not available")
+	}
+	var out bytes.Buffer
+	app := New("test", "test wrapper")
+	app.IO().WithOut(&out)
+	app.Command("multi", "run multiple").
+		WrapMany("/bin/echo", printf).
+		Parallel().
+		PrefixOutput().
+		Back()
+
+	if err := app.RunWithArgs(context.Background(), []string{"multi", "one"}); err != nil {
+		t.Fatalf("RunWithArgs failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	slices.Sort(lines)
+	// printf prints no newline: its last line is completed on flush
+	if want := []string{"echo   | one", "printf | one"}; !slices.Equal(lines, want) {
+		t.Fatalf("got %q, want %q", lines, want)
+	}
+}
+
+// TestWrapperPinning tests ResolveFrom, RequireVersion and RequireSHA256
+func TestWrapperPinning(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts required")
+	}
+	dir := t.TempDir()
+	script := []byte("#!/bin/sh\nif [ \"$1\" = --version ]; then echo 'tool version 1.2.3'; else echo ran; fi\n")
+	//nolint:gosec // the test script must be executable
+	if err := os.WriteFile(filepath.Join(dir, "tool"), script, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := fileSHA256(filepath.Join(dir, "tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(configure func(*WrapperBuilder[*CommandBuilder]) *WrapperBuilder[*CommandBuilder]) (string, error) {
+		var out bytes.Buffer
+		app := New("test", "test wrapper")
+		app.IO().WithOut(&out)
+		configure(app.Command("run", "run tool").Wrap("tool").ResolveFrom(dir)).Back()
+		err := app.RunWithArgs(context.Background(), []string{"run"})
+		return out.String(), err
+	}
+
+	out, err := run(func(w *WrapperBuilder[*CommandBuilder]) *WrapperBuilder[*CommandBuilder] {
+		return w.RequireVersion("tool", ">=1.2", "<2").RequireSHA256("tool", strings.ToUpper(sum))
+	})
+	if err != nil || out != "ran\n" {
+		t.Fatalf("pinned run: got %q, %v", out, err)
+	}
+
+	_, err = run(func(w *WrapperBuilder[*CommandBuilder]) *WrapperBuilder[*CommandBuilder] {
+		return w.RequireVersion("tool", ">=2")
+	})
+	if !errors.Is(err, ErrBinaryVersion) || !strings.Contains(err.Error(), "is 1.2.3, need >=2") {
+		t.Fatalf("expected ErrBinaryVersion, got %v", err)
+	}
+
+	out, err = run(func(w *WrapperBuilder[*CommandBuilder]) *WrapperBuilder[*CommandBuilder] {
+		return w.RequireSHA256("tool", strings.Repeat("0", 64))
+	})
+	if !errors.Is(err, ErrBinaryChecksum) || out != "" {
+		t.Fatalf("expected ErrBinaryChecksum before running, got %q, %v", out, err)
+	}
+
+	var buf bytes.Buffer
+	app := New("test", "test wrapper")
+	app.IO().WithOut(&buf)
+	app.Command("run", "run tool").Wrap("tool").ResolveFrom(t.TempDir()).Back()
+	err = app.RunWithArgs(context.Background(), []string{"run"})
+	if !errors.Is(err, ErrBinaryNotFound) {
+		t.Fatalf("expected ErrBinaryNotFound, got %v", err)
+	}
+	if code := app.ExitCodes().resolve(err); code != 127 {
+		t.Fatalf("expected exit code 127, got %d", code)
+	}
+}
+
+// TestParseHelpFlags tests flag extraction from GNU and Go style help text
+func TestParseHelpFlags(t *testing.T) {
+	text := `Usage: tool [OPTION]... FILE
+Options:
+  -v, --verbose           explain what is being done
+      --color[=WHEN]      colorize the output
+  -o FILE                 write to FILE
+  -h, --help              display this help
+  -race
+    	enable data race detection
+  -p int
+    	build parallelism (default 4)
+  - not a flag
+  -v, --verbose           listed twice
+`
+	got := ParseHelpFlags(text)
+	want := []ForwardedFlag{
+		{Names: []string{"-v", "--verbose"}, Description: "explain what is being done"},
+		{Names: []string{"--color"}, Value: "WHEN", Description: "colorize the output"},
+		{Names: []string{"-o"}, Value: "FILE", Description: "write to FILE"},
+		{Names: []string{"-h", "--help"}, Description: "display this help"},
+		{Names: []string{"-race"}, Description: "enable data race detection"},
+		{Names: []string{"-p"}, Value: "int", Description: "build parallelism (default 4)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+}
+
+// TestWrapperForwardHelp tests the Forwarded Flags section of command help
+func TestWrapperForwardHelp(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts required")
+	}
+	dir := t.TempDir()
+	script := []byte("#!/bin/sh\necho 'Usage: tool [flags]'\n" +
+		"echo '  -n, --dry-run   show what would run'\necho '  -q, --quiet     say less'\nexit 2\n")
+	//nolint:gosec // the test script must be executable
+	if err := os.WriteFile(filepath.Join(dir, "tool"), script, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	app := New("test", "test wrapper")
+	app.IO().WithOut(&out)
+	app.Command("run", "run tool").
+		BoolFlag("quiet", "Less output").Back().
+		Wrap("tool").ResolveFrom(dir).ForwardHelp().Back()
+	if err := app.RunWithArgs(context.Background(), []string{"run", "--help"}); err != nil {
+		t.Fatalf("help failed: %v", err)
+	}
+	help := out.String()
+	if !strings.Contains(help, "Forwarded Flags:\n  -n, --dry-run  show what would run\n") {
+		t.Fatalf("missing forwarded flags:\n%s", help)
+	}
+	if strings.Contains(help, "say less") {
+		t.Fatalf("flag shadowed by --quiet should be left out:\n%s", help)
+	}
+	if flags := app.commands["run"].Wrapper().ForwardedFlags(); len(flags) != 2 {
+		t.Fatalf("expected 2 forwarded flags for completion, got %+v", flags)
+	}
+}
+
 // TestWrapManyParallel tests parallel execution of multiple binaries
 func TestWrapManyParallel(t *testing.T) {
 	var executed sync.Map
@@ -860,3 +1050,442 @@ func TestWrapper_DoubleDashConsumedInNormalMode(t *testing.T) {
 		t.Fatalf("expected 'hello', got %q", got)
 	}
 }
+
+func TestWindowsArgQuoting(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{``, `""`},
+		{`plain`, `plain`},
+		{`C:\Program Files\tool.exe`, `"C:\Program Files\tool.exe"`},
+		{`C:\dir\`, `C:\dir\`},
+		{`C:\my dir\`, `"C:\my dir\\"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`a\"b`, `"a\\\"b"`},
+		{`tab	sep`, "\"tab\tsep\""},
+	}
+	for _, c := range cases {
+		if got := QuoteWindowsArg(c.in); got != c.want {
+			t.Errorf("QuoteWindowsArg(%q) = %s, want %s", c.in, got, c.want)
+		}
+	}
+
+	if got := QuoteCmdArg(`a&b|c`); got != `a^&b^|c` {
+		t.Errorf("QuoteCmdArg = %s", got)
+	}
+	if got := QuoteCmdArg(`100% "done"`); got != `^"100^% \^"done\^"^"` {
+		t.Errorf("QuoteCmdArg = %s", got)
+	}
+
+	got := WindowsCommandLine(`C:\Program Files\tool.exe`, "--out", `C:\tmp dir\`, "x&y")
+	want := `"C:\Program Files\tool.exe" --out "C:\tmp dir\\" x&y`
+	if got != want {
+		t.Errorf("WindowsCommandLine = %s, want %s", got, want)
+	}
+	got = WindowsCommandLine(`C:\Tools\build.CMD`, "x&y", "%PATH%")
+	want = `cmd.exe /d /s /c "C:\Tools\build.CMD x^&y ^%PATH^%"`
+	if got != want {
+		t.Errorf("WindowsCommandLine(batch) = %s, want %s", got, want)
+	}
+}
+
+func TestWrapper_DebugTrace(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("wrapper tests use /bin/echo; skip on windows in unit environment")
+	}
+
+	app := New("wr", "test")
+	var out, errOut bytes.Buffer
+	app.IO().WithOut(&out).WithErr(&errOut)
+	app.Command("echo", "wrap /bin/echo").
+		Wrap("/bin/echo").
+		InjectArgsPre("wrapped:").
+		ForwardArgs().
+		TransformArgs(func(_ *Context, args []string) ([]string, error) { return append(args, "!"), nil }).
+		Passthrough().
+		Back()
+
+	if err := app.RunWithArgs(context.Background(), []string{"--snap-debug", "echo", "hi"}); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "wrapped: hi !" {
+		t.Fatalf("--snap-debug must not reach the wrapped command: %q", got)
+	}
+	trace := errOut.String()
+	for _, want := range []string{`exec /bin/echo wrapped: hi '!'`, "exit 0 in"} {
+		if !strings.Contains(trace, want) {
+			t.Fatalf("trace missing %q:\n%s", want, trace)
+		}
+	}
+}
+
+func TestWrapper_ResolveArgs(t *testing.T) {
+	app := New("wr", "test")
+	app.Wrap("/does/not/exist").
+		InjectArgsPre("pre").
+		InjectArgsPost("post").
+		ForwardUnknownFlags().
+		ForwardArgs().
+		LeadingFlags("-n").
+		InsertAfterLeadingFlags("[p]").
+		Back()
+	app.Command("build", "").
+		BoolFlag("race", "").Back().
+		Wrap("go").
+		InjectArgsPre("build").
+		ForwardUnknownFlags().
+		ForwardArgs().
+		MapBoolFlag("race", "-race").
+		TransformArgs(func(_ *Context, args []string) ([]string, error) { return append(args, "./..."), nil }).
+		BeforeExec(func(*Context, []string) ([]string, error) { return nil, errors.New("must not run") }).
+		Back()
+
+	// App-level wrapper: nothing is executed, so the binary need not exist
+	got, err := app.ResolveWrapperArgs([]string{"-n", "hello"})
+	if err != nil || strings.Join(got, " ") != "pre -n [p] hello post" {
+		t.Fatalf("app wrapper argv = %q, %v", got, err)
+	}
+
+	got, err = app.ResolveWrapperArgs([]string{"build", "--race", "-v"})
+	if err != nil || strings.Join(got, " ") != "build -race -v ./..." {
+		t.Fatalf("command wrapper argv = %q, %v", got, err)
+	}
+
+	// The same pipeline is reachable from a running command's context
+	result, err := app.Parse([]string{"build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = result.Command.Wrapper().ResolveArgs(&Context{App: app, Result: result})
+	if err != nil || strings.Join(got, " ") != "build ./..." {
+		t.Fatalf("ResolveArgs = %q, %v", got, err)
+	}
+	if app.Wrapper() == nil || app.Wrapper().Binary != "/does/not/exist" {
+		t.Fatalf("app wrapper accessor")
+	}
+}
+
+func TestCaptureOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+
+	var out, errOut bytes.Buffer
+	app := New("wr", "test").CaptureOutput(true)
+	app.IO().WithOut(&out).WithErr(&errOut)
+	var gotOut, gotErr string
+	app.After(func(ctx *Context) error {
+		gotOut, gotErr = string(ctx.CapturedStdout()), string(ctx.CapturedStderr())
+		return nil
+	})
+	app.Command("hello", "").
+		Action(func(ctx *Context) error {
+			fmt.Fprintln(ctx.Stdout(), "from action")
+			ctx.LogError("oops")
+			return nil
+		})
+	app.Command("sh", "").
+		Wrap("/bin/sh").
+		InjectArgsPre("-c", "echo child; echo child-err >&2").
+		Passthrough().
+		AfterExec(func(ctx *Context, _ *ExecResult) error {
+			if string(ctx.CapturedStdout()) != "child\n" {
+				t.Errorf("AfterExec capture = %q", ctx.CapturedStdout())
+			}
+			return nil
+		}).
+		Back()
+
+	if err := app.RunWithArgs(context.Background(), []string{"hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotOut != "from action\n" || !strings.Contains(gotErr, "oops") {
+		t.Fatalf("captured stdout %q, stderr %q", gotOut, gotErr)
+	}
+	if out.String() != gotOut || errOut.String() != gotErr {
+		t.Fatalf("output must still reach the real writers: %q %q", out.String(), errOut.String())
+	}
+
+	if err := app.RunWithArgs(context.Background(), []string{"sh"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotOut != "child\n" || gotErr != "child-err\n" {
+		t.Fatalf("wrapper capture: %q %q", gotOut, gotErr)
+	}
+
+	// Disabled by default
+	app.CaptureOutput(false)
+	if err := app.RunWithArgs(context.Background(), []string{"hello"}); err != nil || gotOut != "" {
+		t.Fatalf("capture should be off: %q", gotOut)
+	}
+}
+
+func TestWrapper_Stdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/cat")
+	}
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(manifest, []byte("kind: Pod\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := New("wr", "test")
+	app.Command("str", "").Wrap("/bin/cat").StdinFromString("from string\n").Back()
+	app.Command("file", "").Wrap("/bin/cat").StdinFromFile(manifest).Back()
+	app.Command("reader", "").Wrap("/bin/cat").StdinFromReader(strings.NewReader("from reader\n")).Back()
+	app.Command("none", "").Wrap("/bin/cat").NoStdin().Back()
+	app.Command("hook", "").Wrap("/bin/cat").NoStdin().
+		BeforeExec(func(ctx *Context, args []string) ([]string, error) {
+			ctx.SetWrapperStdin(strings.NewReader("from hook\n"))
+			return args, nil
+		}).Back()
+	app.Command("missing", "").Wrap("/bin/cat").StdinFromFile(filepath.Join(t.TempDir(), "nope")).Back()
+	app.IO().WithIn(strings.NewReader("app stdin\n"))
+
+	for cmd, want := range map[string]string{
+		"str": "from string\n", "file": "kind: Pod\n", "reader": "from reader\n", "none": "", "hook": "from hook\n",
+	} {
+		var out bytes.Buffer
+		app.IO().WithOut(&out)
+		if err := app.RunWithArgs(context.Background(), []string{cmd}); err != nil {
+			t.Fatalf("%s: %v", cmd, err)
+		}
+		if out.String() != want {
+			t.Fatalf("%s: got %q, want %q", cmd, out.String(), want)
+		}
+	}
+	if err := app.RunWithArgs(context.Background(), []string{"missing"}); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing stdin file: %v", err)
+	}
+}
+
+func TestWrapper_Dynamic_ToolPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("/bin/echo required")
+	}
+	newApp := func(configure func(*WrapperBuilder[*CommandBuilder])) (*App, *bytes.Buffer) {
+		app := New("wr", "test")
+		var out bytes.Buffer
+		app.IO().WithOut(&out)
+		b := app.Command("shim", "").WrapDynamic().ForwardUnknownFlags().Passthrough()
+		configure(b)
+		return app, &out
+	}
+	run := func(app *App, args ...string) error {
+		return app.RunWithArgs(context.Background(), append([]string{"shim"}, args...))
+	}
+
+	// DenyTools wins over AllowTools
+	app, _ := newApp(func(b *WrapperBuilder[*CommandBuilder]) { b.AllowTools("echo", "ls").DenyTools("ls") })
+	var cli *CLIError
+	if err := run(app, "/bin/ls"); !errors.As(err, &cli) || cli.Type != ErrorTypePermission {
+		t.Fatalf("denied tool: %v", err)
+	}
+
+	// Skipped tools exit successfully without running
+	app, out := newApp(func(b *WrapperBuilder[*CommandBuilder]) {
+		b.FilterArgs(func(tool string, args []string) bool {
+			return tool != "echo" || !slices.Contains(args, "secret")
+		}).OnDisallowedTool(DisallowedToolSkip)
+	})
+	if err := run(app, "/bin/echo", "secret"); err != nil || out.Len() != 0 {
+		t.Fatalf("skipped: %v, out %q", err, out.String())
+	}
+	if err := run(app, "/bin/echo", "public"); err != nil || strings.TrimSpace(out.String()) != "public" {
+		t.Fatalf("allowed: %v, out %q", err, out.String())
+	}
+	if _, err := app.ResolveWrapperArgs([]string{"shim", "/bin/echo", "secret"}); !errors.Is(err, ErrToolSkipped) {
+		t.Fatalf("ResolveArgs: %v", err)
+	}
+}
+
+func TestWrapper_TokenExpansion(t *testing.T) {
+	t.Setenv("SNAP_TOKEN_HOME", "/home/x")
+	app := New("wr", "test")
+	app.BoolFlag("verbose", "").Global().Back()
+	app.Command("build", "").
+		StringFlag("tag", "").Default("latest").Back().
+		StringSliceFlag("label", "").Back().
+		DurationFlag("timeout", "").Default(90*time.Second).Back().
+		StringArg("target", "").Required().Back().
+		StringSliceArg("files", "").Variadic().
+		Wrap("docker").
+		Env("SNAP_TOKEN_REGION", "eu").
+		InjectArgsPre("build", "--tag", "img:${FLAG:tag}", "--label", "${FLAG:label}", "--timeout=${FLAG:timeout}").
+		InjectArgsPost("--target", "${ARG:target}", "${ARG:files}", "labels=${FLAG:label}", "v=${FLAG:verbose}").
+		InjectArgsPost("${ENV:SNAP_TOKEN_HOME}", "${ENV:SNAP_TOKEN_REGION}", "${TMPDIR}", "${FLAG:missing}").
+		Back()
+
+	argv, err := app.ResolveWrapperArgs([]string{"build", "--label", "a,b", "app", "x.go", "y.go"})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := []string{
+		"build", "--tag", "img:latest", "--label", "a", "b", "--timeout=1m30s", "app", "x.go", "y.go", // forwarded
+		"--target", "app", "x.go", "y.go", "labels=a,b", "v=false",
+		"/home/x", "eu", os.TempDir(), "",
+	}
+	if !reflect.DeepEqual(argv, want) {
+		t.Fatalf("argv:\n got %q\nwant %q", argv, want)
+	}
+}
+
+func TestWrapper_InjectIfAndMapEnumFlag(t *testing.T) {
+	app := New("wr", "test")
+	app.Command("get", "").
+		EnumFlag("format", "", "json", "yaml", "table").Default("table").Back().
+		BoolFlag("all", "").Back().
+		StringFlag("namespace", "").Back().
+		Wrap("kubectl").
+		InjectArgsPre("get").
+		InjectIf(func(ctx *Context) bool { return ctx.MustBool("all", false) }, "--all-namespaces").
+		InjectIf(func(ctx *Context) bool { return ctx.IsSet("namespace") }, "-n", "${FLAG:namespace}").
+		MapEnumFlag("format", map[string]string{"json": "--output=json", "yaml": "--output=yaml"}).
+		Back()
+
+	cases := map[string][]string{
+		"get pods":                     {"get", "pods"},
+		"get --all --format json pods": {"get", "--all-namespaces", "--output=json", "pods"},
+		"get --namespace kube-system --format yaml pods": {"get", "-n", "kube-system", "--output=yaml", "pods"},
+	}
+	for line, want := range cases {
+		argv, err := app.ResolveWrapperArgs(strings.Fields(line))
+		if err != nil || !reflect.DeepEqual(argv, want) {
+			t.Fatalf("%s: got %q (%v), want %q", line, argv, err, want)
+		}
+	}
+}
+
+func TestWrapManySkipCacheAndSummary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/echo")
+	}
+	cacheDir := t.TempDir()
+	executed := 0
+	var runs []BinaryRun
+	app := New("test", "test wrapper")
+	app.Command("multi", "").
+		WrapMany("/bin/echo", "/bin/false", "/bin/missing").
+		InjectArgsPre("hello").
+		StopOnError(false).
+		SkipIf(func(binary string) bool { return binary == "/bin/missing" }).
+		CacheKey(func(binary string, args []string) string { return binary + " " + strings.Join(args, " ") }).
+		CacheDir(cacheDir).
+		Summary().
+		AfterExec(func(*Context, *ExecResult) error {
+			executed++
+			return nil
+		}).
+		Back().
+		After(func(ctx *Context) error { runs = ctx.WrapperRuns(); return nil })
+
+	var out, stderr bytes.Buffer
+	app.IO().WithOut(&out).WithErr(&stderr)
+	for i := 0; i < 2; i++ {
+		if err := app.RunWithArgs(context.Background(), []string{"multi"}); err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+	}
+
+	// echo runs once and is replayed from the cache; false is never cached
+	if out.String() != "hello\nhello\n" || executed != 4 {
+		t.Fatalf("out %q, AfterExec calls %d", out.String(), executed)
+	}
+	want := []BinaryStatus{BinaryCached, BinaryExecuted, BinarySkipped}
+	for i, run := range runs {
+		if run.Status != want[i] {
+			t.Fatalf("runs: %+v", runs)
+		}
+	}
+	if runs[1].ExitCode != 1 {
+		t.Fatalf("exit code: %+v", runs[1])
+	}
+	if !strings.Contains(stderr.String(), "/bin/echo     cached") || !strings.HasSuffix(stderr.String(), "1 executed, 1 cached, 1 skipped\n") {
+		t.Fatalf("summary:\n%s", stderr.String())
+	}
+}
+
+func TestWrapperCacheKeyedByArgs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/echo")
+	}
+	cacheDir := t.TempDir()
+	app := New("test", "test wrapper")
+	app.Command("echo", "").
+		Wrap("/bin/echo").
+		ForwardArgs().
+		CacheKey(func(string, []string) string { return "same" }).
+		CacheDir(cacheDir).
+		Passthrough().
+		Back()
+
+	var out bytes.Buffer
+	app.IO().WithOut(&out)
+	for _, args := range [][]string{{"echo", "a"}, {"echo", "b"}, {"echo", "a"}} {
+		if err := app.RunWithArgs(context.Background(), args); err != nil {
+			t.Fatalf("run %v: %v", args, err)
+		}
+	}
+	// A key ignoring argv must not replay the output of other arguments
+	if out.String() != "a\nb\na\n" {
+		t.Fatalf("out %q", out.String())
+	}
+	entries, _ := os.ReadDir(cacheDir)
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			t.Fatalf("temporary file left behind: %s", entry.Name())
+		}
+	}
+	if len(entries) != 2 {
+		t.Fatalf("cache entries: %d", len(entries))
+	}
+}
+
+// Test wrapper exec events, serialized across parallel executions
+func TestWrapper_Events(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/echo and /bin/false")
+	}
+	var mu sync.Mutex
+	inHandler := false
+	var events []Event
+	app := New("wr", "test").OnEvent(func(e Event) {
+		mu.Lock()
+		if inHandler {
+			t.Error("handlers called concurrently")
+		}
+		inHandler = true
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		inHandler = false
+		if e.Type == EventWrapperExecStarted || e.Type == EventWrapperExecFinished {
+			events = append(events, e)
+		}
+		mu.Unlock()
+	})
+	app.IO().WithOut(&bytes.Buffer{}).WithErr(&bytes.Buffer{})
+	app.Command("multi", "").
+		WrapMany("/bin/echo", "/bin/false").
+		Parallel().
+		Capture().
+		Back()
+
+	_ = app.RunWithArgs(context.Background(), []string{"multi"})
+	if len(events) != 4 {
+		t.Fatalf("events: %+v", events)
+	}
+	finished := map[string]Event{}
+	for _, e := range events {
+		if e.Command != "multi" || e.Context == nil {
+			t.Fatalf("event: %+v", e)
+		}
+		if e.Type == EventWrapperExecFinished {
+			finished[e.Binary] = e
+		}
+	}
+	if e := finished["/bin/echo"]; e.ExitCode != 0 || e.Err != nil {
+		t.Fatalf("echo finished: %+v", e)
+	}
+	if e := finished["/bin/false"]; e.ExitCode != 1 || e.Err == nil {
+		t.Fatalf("false finished: %+v", e)
+	}
+}
@@ -0,0 +1,147 @@
+package snap
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tokenPattern matches the placeholders expanded in injected wrapper args:
+// ${SELF}, ${TMPDIR}, ${FLAG:name}, ${ARG:name} and ${ENV:VAR}
+var tokenPattern = regexp.MustCompile(`\$\{(SELF|TMPDIR|(?:FLAG|ARG|ENV):[^}]+)\}`)
+
+// expandTokens resolves placeholders in injected args against ctx at exec
+// time. A token that is exactly one placeholder of a slice flag or variadic
+// argument expands to one arg per value; inside a longer token the values
+// are joined with commas. Unset flags, arguments and variables expand to "".
+func (w *WrapperSpec) expandTokens(ctx *Context, args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	out := make([]string, 0, len(args))
+	for _, a := range args {
+		if !strings.Contains(a, "${") {
+			out = append(out, a)
+			continue
+		}
+		if m := tokenPattern.FindStringSubmatchIndex(a); m != nil && m[0] == 0 && m[1] == len(a) {
+			out = append(out, w.tokenValues(ctx, a[m[2]:m[3]])...)
+			continue
+		}
+		out = append(out, tokenPattern.ReplaceAllStringFunc(a, func(token string) string {
+			return strings.Join(w.tokenValues(ctx, token[2:len(token)-1]), ",")
+		}))
+	}
+	return out
+}
+
+// tokenValues resolves a single placeholder name ("FLAG:tag") to its values
+func (w *WrapperSpec) tokenValues(ctx *Context, token string) []string {
+	kind, name, _ := strings.Cut(token, ":")
+	switch kind {
+	case "SELF":
+		self, _ := os.Executable()
+		return []string{self}
+	case "TMPDIR":
+		return []string{os.TempDir()}
+	case "ENV":
+		if v, ok := w.Env[name]; ok {
+			return []string{v}
+		}
+		return []string{os.Getenv(name)}
+	}
+	if ctx == nil || ctx.Result == nil {
+		return []string{""}
+	}
+	lookups := flagLookups
+	if kind == "ARG" {
+		lookups = argLookups
+	}
+	for _, lookup := range lookups {
+		if values, ok := lookup(ctx.Result, name); ok {
+			return values
+		}
+	}
+	return []string{""}
+}
+
+// valueLookup reads a flag or argument of one type as text
+type valueLookup func(r *ParseResult, name string) ([]string, bool)
+
+// scalar adapts a typed getter to a valueLookup
+func scalar[T any](get func(*ParseResult, string) (T, bool), format func(T) string) valueLookup {
+	return func(r *ParseResult, name string) ([]string, bool) {
+		v, ok := get(r, name)
+		if !ok {
+			return nil, false
+		}
+		return []string{format(v)}, true
+	}
+}
+
+// list adapts a typed slice getter to a valueLookup
+func list[T any](get func(*ParseResult, string) ([]T, bool), format func(T) string) valueLookup {
+	return func(r *ParseResult, name string) ([]string, bool) {
+		v, ok := get(r, name)
+		if !ok {
+			return nil, false
+		}
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = format(item)
+		}
+		return values, true
+	}
+}
+
+// Formatters for the flag value types
+var (
+	formatString   = func(s string) string { return s }
+	formatInt64    = func(n int64) string { return strconv.FormatInt(n, 10) }
+	formatUint64   = func(n uint64) string { return strconv.FormatUint(n, 10) }
+	formatFloat    = func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	formatDuration = time.Duration.String
+)
+
+// flagLookups tries every local and global flag type in turn
+var flagLookups = []valueLookup{
+	scalar((*ParseResult).GetString, formatString),
+	scalar((*ParseResult).GetEnum, formatString),
+	scalar((*ParseResult).GetBool, strconv.FormatBool),
+	scalar((*ParseResult).GetInt, strconv.Itoa),
+	scalar((*ParseResult).GetInt64, formatInt64),
+	scalar((*ParseResult).GetUint64, formatUint64),
+	scalar((*ParseResult).GetDuration, formatDuration),
+	scalar((*ParseResult).GetFloat, formatFloat),
+	list((*ParseResult).GetStringSlice, formatString),
+	list((*ParseResult).GetEnumSlice, formatString),
+	list((*ParseResult).GetIntSlice, strconv.Itoa),
+	list((*ParseResult).GetFloatSlice, formatFloat),
+	list((*ParseResult).GetDurationSlice, formatDuration),
+	scalar((*ParseResult).GetGlobalString, formatString),
+	scalar((*ParseResult).GetGlobalEnum, formatString),
+	scalar((*ParseResult).GetGlobalBool, strconv.FormatBool),
+	scalar((*ParseResult).GetGlobalInt, strconv.Itoa),
+	scalar((*ParseResult).GetGlobalInt64, formatInt64),
+	scalar((*ParseResult).GetGlobalUint64, formatUint64),
+	scalar((*ParseResult).GetGlobalDuration, formatDuration),
+	scalar((*ParseResult).GetGlobalFloat, formatFloat),
+	list((*ParseResult).GetGlobalStringSlice, formatString),
+	list((*ParseResult).GetGlobalEnumSlice, formatString),
+	list((*ParseResult).GetGlobalIntSlice, strconv.Itoa),
+	list((*ParseResult).GetGlobalFloatSlice, formatFloat),
+	list((*ParseResult).GetGlobalDurationSlice, formatDuration),
+}
+
+// argLookups tries every positional argument type in turn
+var argLookups = []valueLookup{
+	scalar((*ParseResult).GetArgString, formatString),
+	scalar((*ParseResult).GetArgInt, strconv.Itoa),
+	scalar((*ParseResult).GetArgBool, strconv.FormatBool),
+	scalar((*ParseResult).GetArgDuration, formatDuration),
+	scalar((*ParseResult).GetArgFloat, formatFloat),
+	list((*ParseResult).GetArgStringSlice, formatString),
+	list((*ParseResult).GetArgIntSlice, strconv.Itoa),
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package snap
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// prepareCommand runs batch files through cmd.exe with an explicitly escaped
+// command line; CreateProcess would otherwise hand the arguments to cmd.exe
+// unescaped, letting characters like & and | split the command
+func prepareCommand(cmd *exec.Cmd) {
+	if !isBatchFile(cmd.Path) {
+		return
+	}
+	comspec := os.Getenv("ComSpec")
+	if comspec == "" {
+		comspec = `C:\Windows\System32\cmd.exe`
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: WindowsCommandLine(cmd.Path, cmd.Args[1:]...)}
+	cmd.Path = comspec
+}
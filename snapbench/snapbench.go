@@ -0,0 +1,52 @@
+// Package snapbench provides test helpers that verify the go-snap parser
+// stays allocation-free for an application's own flags, commands and
+// arguments. Use it in tests of CLIs that add custom flag types, validators
+// or defaults to catch changes that reintroduce allocations on the hot path:
+//
+//	func TestParseAllocs(t *testing.T) {
+//		snapbench.AssertZeroAllocs(t, newApp(), []string{"serve", "--port", "8080"})
+//	}
+//
+// Measurements use a single reused snap.Parser, the way a long-running
+// process parses repeatedly; the first parse warms up its buffers and pools.
+package snapbench
+
+import (
+	"testing"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// Runs is the number of parses averaged by Allocs
+var Runs = 100
+
+// Allocs returns the average number of heap allocations of one parse of args
+// with app, and the error of the first parse if it fails
+func Allocs(app *snap.App, args []string) (float64, error) {
+	parser := snap.NewParser(app)
+	if _, err := parser.Parse(args); err != nil {
+		return 0, err
+	}
+	return testing.AllocsPerRun(Runs, func() {
+		_, _ = parser.Parse(args)
+	}), nil
+}
+
+// AssertZeroAllocs fails t when parsing args with app allocates or fails
+func AssertZeroAllocs(t testing.TB, app *snap.App, args []string) {
+	t.Helper()
+	AssertAllocBudget(t, app, args, 0)
+}
+
+// AssertAllocBudget fails t when parsing args with app allocates more than
+// budget times per parse on average, or when the parse fails
+func AssertAllocBudget(t testing.TB, app *snap.App, args []string, budget float64) {
+	t.Helper()
+	allocs, err := Allocs(app, args)
+	if err != nil {
+		t.Fatalf("snapbench: parse %q: %v", args, err)
+	}
+	if allocs > budget {
+		t.Errorf("snapbench: parse %q: %.2f allocs/op, budget %.2f", args, allocs, budget)
+	}
+}
@@ -0,0 +1,105 @@
+//nolint:testpackage // using package name 'snapbench' to exercise the helpers directly
+package snapbench
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// TestAllocBudgets pins the zero-allocation guarantee for the parser paths
+// beyond simple flags
+func TestAllocBudgets(t *testing.T) {
+	t.Setenv("SNAPBENCH_HOST", "example.com")
+	t.Setenv("SNAPBENCH_PORT", "8080")
+
+	tests := []struct {
+		name  string
+		build func() *snap.App
+		args  []string
+	}{
+		{"flags", func() *snap.App {
+			return snap.New("bench", "").
+				IntFlag("port", "").Default(8080).Back().
+				BoolFlag("verbose", "").Short('v').Back()
+		}, []string{"--port", "9090", "-v"}},
+		{"positional args", func() *snap.App {
+			app := snap.New("bench", "")
+			app.StringArg("src", "").Required().Back()
+			app.IntArg("count", "").Default(1)
+			return app
+		}, []string{"file.txt", "3"}},
+		{"variadic args", func() *snap.App {
+			app := snap.New("bench", "")
+			app.StringSliceArg("files", "").Variadic()
+			return app
+		}, []string{"a.go", "b.go", "c.go"}},
+		{"slice flags", func() *snap.App {
+			return snap.New("bench", "").
+				StringSliceFlag("tag", "").Back().
+				IntSliceFlag("port", "").Back()
+		}, []string{"--tag=a,b", "--tag", "c", "--port", "80,443"}},
+		{"env fallback", func() *snap.App {
+			return snap.New("bench", "").
+				StringFlag("host", "").FromEnv("SNAPBENCH_HOST").Back().
+				IntFlag("port", "").FromEnv("SNAPBENCH_PORT").Back()
+		}, nil},
+		{"commands", func() *snap.App {
+			app := snap.New("bench", "").BoolFlag("debug", "").Global().Back()
+			app.Command("serve", "").IntFlag("port", "").Default(8080).Back()
+			return app
+		}, []string{"--debug", "serve", "--port=9090"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AssertZeroAllocs(t, tt.build(), tt.args)
+		})
+	}
+}
+
+// recorder captures failures reported by the helpers
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestAssertReportsAllocations(t *testing.T) {
+	// Computed defaults are evaluated on every parse
+	app := snap.New("bench", "").
+		StringFlag("name", "").DefaultFunc(func() string { return strings.Repeat("x", 64) }).Back()
+
+	allocs, err := Allocs(app, nil)
+	if err != nil || allocs == 0 {
+		t.Fatalf("Allocs = %v, %v; want allocations from the default", allocs, err)
+	}
+
+	r := &recorder{TB: t}
+	AssertZeroAllocs(r, app, nil)
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "allocs/op, budget 0.00") {
+		t.Fatalf("failures = %q", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	AssertAllocBudget(r, app, nil, allocs)
+	if len(r.failures) != 0 {
+		t.Fatalf("failures within budget = %q", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	AssertZeroAllocs(r, app, []string{"--unknown"})
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "unknown flag") {
+		t.Fatalf("parse failure = %q", r.failures)
+	}
+}
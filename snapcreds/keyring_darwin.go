@@ -0,0 +1,79 @@
+package snapcreds
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errSecItemNotFound is the exit code of the security tool for missing items
+const errSecItemNotFound = 44
+
+// systemBackend uses the login keychain through the security tool
+type systemBackend struct{}
+
+func (systemBackend) Store(service, name, secret string) error {
+	if err := checkKey(service, name); err != nil {
+		return err
+	}
+	// security reads -w from the terminal, not from stdin, so the command goes
+	// through its interactive mode to keep the secret off the argument list;
+	// -U updates an existing item
+	line, err := securityCommand("add-generic-password", "-U", "-s", service, "-a", name, "-w", secret)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(line)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	// Interactive mode reports a failed command on stderr, not in its exit code
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("security: %s", msg)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("security: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("security is required to access the credential store: %w", err)
+	}
+	return nil
+}
+
+// securityCommand quotes args as one line for security -i
+func securityCommand(args ...string) (string, error) {
+	var b strings.Builder
+	for i, arg := range args {
+		if strings.ContainsAny(arg, "\r\n") {
+			return "", errors.New("keychain values must not contain line breaks")
+		}
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteByte('"')
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg))
+		b.WriteByte('"')
+	}
+	b.WriteByte('\n')
+	return b.String(), nil
+}
+
+func (systemBackend) Load(service, name string) (string, error) {
+	secret, code, err := runTool("", "security", "find-generic-password", "-s", service, "-a", name, "-w")
+	if code == errSecItemNotFound {
+		return "", ErrNotFound
+	}
+	return secret, err
+}
+
+func (systemBackend) Delete(service, name string) error {
+	_, code, err := runTool("", "security", "delete-generic-password", "-s", service, "-a", name)
+	if code == errSecItemNotFound {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+//go:build !darwin && !windows
+
+package snapcreds
+
+import "errors"
+
+// systemBackend uses the Secret Service through secret-tool (libsecret)
+type systemBackend struct{}
+
+func (systemBackend) Store(service, name, secret string) error {
+	if err := checkKey(service, name); err != nil {
+		return err
+	}
+	_, _, err := runTool(secret, "secret-tool", "store", "--label="+service+": "+name,
+		"service", service, "account", name)
+	return err
+}
+
+func (systemBackend) Load(service, name string) (string, error) {
+	secret, code, err := runTool("", "secret-tool", "lookup", "service", service, "account", name)
+	// secret-tool exits 1 without output when nothing matches; with a message
+	// on stderr the lookup itself failed (no Secret Service, locked, ...)
+	if code == 1 && errors.Is(err, errNoOutput) {
+		return "", ErrNotFound
+	}
+	return secret, err
+}
+
+func (s systemBackend) Delete(service, name string) error {
+	// secret-tool clear succeeds whether or not anything matched
+	if _, err := s.Load(service, name); err != nil {
+		return err
+	}
+	_, _, err := runTool("", "secret-tool", "clear", "service", service, "account", name)
+	return err
+}
@@ -0,0 +1,94 @@
+// Package snapcreds stores named secrets in the OS credential store: the
+// macOS Keychain, the Windows Credential Manager or, on Linux and other
+// Unix systems, the Secret Service (GNOME Keyring, KWallet) via secret-tool.
+//
+//	if err := snapcreds.Store("myapp", "registry", token); err != nil {
+//		return err
+//	}
+//	token, err := snapcreds.Load("myapp", "registry")
+//	if errors.Is(err, snapcreds.ErrNotFound) {
+//		// not logged in
+//	}
+//
+// Secrets are addressed by a service (usually the app name) and a name. A
+// Backend replaces the OS store, e.g. Memory() in tests.
+package snapcreds
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotFound is returned by Load and Delete when no secret is stored
+var ErrNotFound = errors.New("credential not found")
+
+// Backend is a store of secrets addressed by service and name
+type Backend interface {
+	Store(service, name, secret string) error
+	Load(service, name string) (string, error)
+	Delete(service, name string) error
+}
+
+// System returns the OS credential store
+func System() Backend { return systemBackend{} }
+
+// Store saves secret as name of service in the OS credential store,
+// replacing any previous value
+func Store(service, name, secret string) error { return System().Store(service, name, secret) }
+
+// Load returns the secret stored as name of service in the OS credential store
+func Load(service, name string) (string, error) { return System().Load(service, name) }
+
+// Delete removes the secret stored as name of service from the OS credential store
+func Delete(service, name string) error { return System().Delete(service, name) }
+
+// checkKey rejects empty services and names, which the OS stores treat as wildcards
+func checkKey(service, name string) error {
+	if service == "" || name == "" {
+		return errors.New("credential service and name must not be empty")
+	}
+	return nil
+}
+
+// memoryBackend keeps secrets in process memory
+type memoryBackend struct {
+	mu      sync.Mutex
+	secrets map[[2]string]string
+}
+
+// Memory returns an empty in-process Backend, for tests and for platforms
+// without a credential store
+func Memory() Backend {
+	return &memoryBackend{secrets: make(map[[2]string]string)}
+}
+
+func (m *memoryBackend) Store(service, name, secret string) error {
+	if err := checkKey(service, name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[[2]string{service, name}] = secret
+	return nil
+}
+
+func (m *memoryBackend) Load(service, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	secret, ok := m.secrets[[2]string{service, name}]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (m *memoryBackend) Delete(service, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := [2]string{service, name}
+	if _, ok := m.secrets[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.secrets, key)
+	return nil
+}
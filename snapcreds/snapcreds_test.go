@@ -0,0 +1,34 @@
+package snapcreds
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	b := Memory()
+	if _, err := b.Load("app", "registry"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load before Store = %v", err)
+	}
+	if err := b.Store("app", "registry", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Store("app", "registry", "rotated"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.Load("app", "registry"); err != nil || got != "rotated" {
+		t.Fatalf("Load = %q, %v", got, err)
+	}
+	if _, err := b.Load("other", "registry"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load of another service = %v", err)
+	}
+	if err := b.Delete("app", "registry"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("app", "registry"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete = %v", err)
+	}
+	if err := b.Store("", "registry", "x"); err == nil {
+		t.Fatal("Store accepted an empty service")
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package snapcreds
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errNoOutput marks a helper that failed without writing anything
+var errNoOutput = errors.New("failed without output")
+
+// runTool runs a credential helper with stdin as input and returns its
+// output without the trailing newline and its exit code (-1 if it did not run)
+func runTool(stdin string, name string, args ...string) (string, int, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err == nil {
+		return strings.TrimSuffix(string(out), "\n"), 0, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" && len(out) == 0 {
+			return "", exitErr.ExitCode(), fmt.Errorf("%s: %w (%s)", name, errNoOutput, exitErr)
+		}
+		if msg == "" {
+			msg = exitErr.Error()
+		}
+		return "", exitErr.ExitCode(), fmt.Errorf("%s: %s", name, msg)
+	}
+	return "", -1, fmt.Errorf("%s is required to access the credential store: %w", name, err)
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package snapcreds
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunToolErrors(t *testing.T) {
+	// A silent exit 1 is secret-tool's "nothing matched"
+	if _, code, err := runTool("", "sh", "-c", "exit 1"); code != 1 || !errors.Is(err, errNoOutput) {
+		t.Fatalf("silent failure = %d, %v", code, err)
+	}
+	// Anything on stderr is a real failure and is reported
+	_, code, err := runTool("", "sh", "-c", "echo locked >&2; exit 1")
+	if code != 1 || errors.Is(err, errNoOutput) || !strings.Contains(err.Error(), "sh: locked") {
+		t.Fatalf("failure with stderr = %d, %v", code, err)
+	}
+	if out, _, err := runTool("s3cr3t", "cat"); err != nil || out != "s3cr3t" {
+		t.Fatalf("stdin = %q, %v", out, err)
+	}
+}
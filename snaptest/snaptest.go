@@ -0,0 +1,115 @@
+// Package snaptest runs go-snap applications in tests under a scripted
+// environment: environment variables, working directory and stdin are set
+// for one invocation and restored afterwards, and stdout and stderr are
+// recorded:
+//
+//	func TestDeploy(t *testing.T) {
+//		res := snaptest.Env{
+//			Vars:  map[string]string{"MYAPP_TOKEN": "t0k3n"},
+//			Dir:   t.TempDir(),
+//			Stdin: "yes\n",
+//		}.Run(t, newApp(), "deploy", "--env", "prod")
+//		if res.ExitCode != 0 || !strings.Contains(res.Stdout, "deployed") {
+//			t.Fatalf("exit %d: %v\n%s", res.ExitCode, res.Err, res.Output.Plain())
+//		}
+//	}
+//
+// Environment variables and the working directory are process-wide, so
+// tests using Env must not run in parallel.
+package snaptest
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	snapio "github.com/dzonerzy/go-snap/io"
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// Env is the environment of one app invocation
+type Env struct {
+	Vars  map[string]string // Environment variables set for the run
+	Unset []string          // Environment variables removed for the run
+	Dir   string            // Working directory for the run ("" keeps the current one)
+	Stdin string            // Input read by the app
+}
+
+// Result is the outcome of an invocation run by Env.Run
+type Result struct {
+	Err      error  // Error returned by the app
+	ExitCode int    // Exit code the error maps to (see snap.App.ExitCode)
+	Stdout   string // Recorded stdout, with ANSI codes
+	Stderr   string // Recorded stderr, with ANSI codes
+	Output   *snapio.Recorder
+}
+
+// Run invokes app with args in the environment and restores the previous
+// environment, working directory and app IO streams before returning.
+// Failing to apply the environment fails the test.
+func (e Env) Run(tb testing.TB, app *snap.App, args ...string) *Result {
+	tb.Helper()
+	restore, err := e.apply()
+	defer restore()
+	if err != nil {
+		tb.Fatalf("snaptest: %v", err)
+	}
+
+	m := app.IO()
+	in, out, errOut := m.In(), m.Out(), m.Err()
+	defer func() { m.WithIn(in).WithOut(out).WithErr(errOut) }()
+	rec := snapio.NewRecorder()
+	m.WithIn(strings.NewReader(e.Stdin)).WithOut(rec.Out()).WithErr(rec.Err())
+
+	runErr := app.RunWithArgs(context.Background(), args)
+	return &Result{
+		Err:      runErr,
+		ExitCode: app.ExitCode(runErr),
+		Stdout:   rec.Stdout(),
+		Stderr:   rec.Stderr(),
+		Output:   rec,
+	}
+}
+
+// apply sets up the environment, returning a function undoing what was
+// applied (also when an error stopped it halfway)
+func (e Env) apply() (func(), error) {
+	var undo []func()
+	restore := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+	keep := func(name string) {
+		if old, ok := os.LookupEnv(name); ok {
+			undo = append(undo, func() { _ = os.Setenv(name, old) })
+		} else {
+			undo = append(undo, func() { _ = os.Unsetenv(name) })
+		}
+	}
+
+	for name, value := range e.Vars {
+		keep(name)
+		if err := os.Setenv(name, value); err != nil {
+			return restore, err
+		}
+	}
+	for _, name := range e.Unset {
+		keep(name)
+		if err := os.Unsetenv(name); err != nil {
+			return restore, err
+		}
+	}
+	if e.Dir != "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return restore, err
+		}
+		if err := os.Chdir(e.Dir); err != nil {
+			return restore, err
+		}
+		undo = append(undo, func() { _ = os.Chdir(wd) })
+	}
+	return restore, nil
+}
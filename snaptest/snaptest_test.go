@@ -0,0 +1,54 @@
+//nolint:testpackage // using package name 'snaptest' to exercise the helpers directly
+package snaptest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func TestEnvRun(t *testing.T) {
+	t.Setenv("SNAPTEST_KEEP", "orig")
+	wd, _ := os.Getwd()
+	dir := t.TempDir()
+
+	app := snap.New("t", "")
+	stdout := app.IO().Out()
+	app.StringFlag("token", "").FromEnv("SNAPTEST_TOKEN").Back().
+		Action(func(ctx *snap.Context) error {
+			cwd, _ := os.Getwd()
+			answer, _ := bufio.NewReader(ctx.Stdin()).ReadString('\n')
+			_, kept := os.LookupEnv("SNAPTEST_KEEP")
+			fmt.Fprintf(ctx.Stdout(), "token=%s cwd=%s answer=%s kept=%v\n",
+				ctx.MustString("token", ""), cwd, strings.TrimSpace(answer), kept)
+			ctx.Logger().Error("done")
+			return &snap.ExitError{Code: 3, Err: errors.New("stop")}
+		})
+
+	res := Env{
+		Vars:  map[string]string{"SNAPTEST_TOKEN": "t0k"},
+		Unset: []string{"SNAPTEST_KEEP"},
+		Dir:   dir,
+		Stdin: "yes\n",
+	}.Run(t, app)
+
+	want := fmt.Sprintf("token=t0k cwd=%s answer=yes kept=false\n", dir)
+	if res.Stdout != want || !strings.Contains(res.Stderr, "done") || res.ExitCode != 3 || res.Err == nil {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	if _, ok := os.LookupEnv("SNAPTEST_TOKEN"); ok || os.Getenv("SNAPTEST_KEEP") != "orig" {
+		t.Fatal("environment not restored")
+	}
+	if cwd, _ := os.Getwd(); cwd != wd {
+		t.Fatalf("working directory not restored: %s", cwd)
+	}
+	if app.IO().Out() != stdout {
+		t.Fatal("app output not restored")
+	}
+}